@@ -66,7 +66,8 @@ func evalCEL(w io.Writer, expressionPath, httpPath string) error {
 		return fmt.Errorf("error reading HTTP file: %w", err)
 	}
 
-	evalContext, err := makeEvalContext(body, r.Header, r.URL.String(), map[string]interface{}{})
+	extensions := map[string]interface{}{}
+	evalContext, err := makeEvalContext(body, r.Header, r.URL.String(), extensions)
 	if err != nil {
 		return fmt.Errorf("error making eval context: %w", err)
 	}
@@ -74,6 +75,7 @@ func evalCEL(w io.Writer, expressionPath, httpPath string) error {
 	mapStrDyn := decls.NewMapType(decls.String, decls.Dyn)
 	env, err := cel.NewEnv(
 		triggerscel.Triggers(context.Background(), "default", secretGetter{}),
+		triggerscel.Extensions(extensions),
 		celext.Strings(),
 		celext.Encoders(),
 		cel.Declarations(
@@ -17,8 +17,11 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"log"
 	"os"
 
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
 	"github.com/tektoncd/triggers/pkg/reconciler/clusterinterceptor"
 	elresources "github.com/tektoncd/triggers/pkg/reconciler/eventlistener/resources"
 	"github.com/tektoncd/triggers/pkg/reconciler/interceptor"
@@ -44,6 +47,7 @@ var (
 	setSecurityContext    = flag.Bool("el-security-context", elresources.DefaultSetSecurityContext, "Add a security context to the event listener deployment.")
 	setEventListenerEvent = flag.String("el-events", elresources.DefaultEventListenerEvent, "Enable events for event listener deployment.")
 	readTimeOut           = flag.Int64("el-readtimeout", elresources.DefaultReadTimeout, "The read timeout for EventListener Server.")
+	readHeaderTimeOut     = flag.Int64("el-readheadertimeout", elresources.DefaultReadHeaderTimeout, "The timeout for reading the request headers for EventListener Server.")
 	writeTimeOut          = flag.Int64("el-writetimeout", elresources.DefaultWriteTimeout, "The write timeout for EventListener Server.")
 	idleTimeOut           = flag.Int64("el-idletimeout", elresources.DefaultIdleTimeout, "The idle timeout for EventListener Server.")
 	timeOutHandler        = flag.Int64("el-timeouthandler", elresources.DefaultTimeOutHandler, "The timeout for Timeout Handler of EventListener Server.")
@@ -57,6 +61,18 @@ var (
 		"The HTTP Client read timeout for EventListener Server.")
 	httpClientExpectContinueTimeout = flag.Int64("el-httpclient-expectcontinuetimeout", elresources.DefaultHTTPClientExpectContinueTimeout,
 		"The HTTP Client read timeout for EventListener Server.")
+	resourceClientQPS = flag.Float64("el-resource-client-qps", elresources.DefaultResourceClientQPS,
+		"The QPS of the clients of the EventListener creating resources.")
+	resourceClientBurst = flag.Int("el-resource-client-burst", elresources.DefaultResourceClientBurst,
+		"The burst of the clients of the EventListener creating resources.")
+	resourceClientMaxIdleConns = flag.Int("el-resource-client-max-idle-conns", elresources.DefaultResourceClientMaxIdleConns,
+		"The maximum number of idle connections to the API server kept by the clients of the EventListener creating resources.")
+	tlsMinVersion = flag.String("el-tls-min-version", elresources.DefaultTLSMinVersion,
+		"The minimum TLS version, 1.2 or 1.3, of the EventListener server and of its clients of interceptors.")
+	tlsCipherSuites = flag.String("el-tls-cipher-suites", elresources.DefaultTLSCipherSuites,
+		"The comma separated TLS 1.2 cipher suites of the EventListener server and of its clients of interceptors.")
+	logFormat = flag.String("el-log-format", elresources.DefaultLogFormat,
+		"The format of the EventListener logs: empty, for the format of the logging config, or json for JSON with stable correlation fields.")
 	periodSeconds    = flag.Int("period-seconds", elresources.DefaultPeriodSeconds, "The Period Seconds for the EventListener Liveness and Readiness Probes.")
 	failureThreshold = flag.Int("failure-threshold", elresources.DefaultFailureThreshold, "The Failure Threshold for the EventListener Liveness and Readiness Probes.")
 
@@ -66,6 +82,12 @@ var (
 
 func main() {
 	cfg := injection.ParseAndGetRESTConfigOrDie()
+	if err := validateTLSFlags(); err != nil {
+		log.Fatal(err.Error())
+	}
+	if err := triggers.ValidateLogFormat(*logFormat); err != nil {
+		log.Fatalf("invalid -el-log-format flag: %v", err)
+	}
 
 	c := elresources.Config{
 		Image:                           image,
@@ -73,6 +95,7 @@ func main() {
 		SetSecurityContext:              setSecurityContext,
 		SetEventListenerEvent:           setEventListenerEvent,
 		ReadTimeOut:                     readTimeOut,
+		ReadHeaderTimeOut:               readHeaderTimeOut,
 		WriteTimeOut:                    writeTimeOut,
 		IdleTimeOut:                     idleTimeOut,
 		TimeOutHandler:                  timeOutHandler,
@@ -81,6 +104,12 @@ func main() {
 		HTTPClientTLSHandshakeTimeout:   httpClientTLSHandshakeTimeout,
 		HTTPClientResponseHeaderTimeout: httpClientResponseHeaderTimeout,
 		HTTPClientExpectContinueTimeout: httpClientExpectContinueTimeout,
+		ResourceClientQPS:               resourceClientQPS,
+		ResourceClientBurst:             resourceClientBurst,
+		ResourceClientMaxIdleConns:      resourceClientMaxIdleConns,
+		TLSMinVersion:                   tlsMinVersion,
+		TLSCipherSuites:                 tlsCipherSuites,
+		LogFormat:                       logFormat,
 		PeriodSeconds:                   periodSeconds,
 		FailureThreshold:                failureThreshold,
 
@@ -99,3 +128,22 @@ func main() {
 		interceptor.NewController(),
 	)
 }
+
+// validateTLSFlags checks the TLS flags of EventListeners, so that a misconfiguration fails the controller
+// rather than the EventListeners. A cipher suites flag that is set must not be empty.
+func validateTLSFlags() error {
+	if *tlsMinVersion != "" {
+		if _, err := triggers.ParseTLSVersion(*tlsMinVersion); err != nil {
+			return fmt.Errorf("invalid -el-tls-min-version flag: %w", err)
+		}
+	}
+	var err error
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "el-tls-cipher-suites" {
+			if _, perr := triggers.ParseTLSCipherSuites(*tlsCipherSuites); perr != nil {
+				err = fmt.Errorf("invalid -el-tls-cipher-suites flag: %w", perr)
+			}
+		}
+	})
+	return err
+}
@@ -24,6 +24,7 @@ import (
 	dynamicClientset "github.com/tektoncd/triggers/pkg/client/dynamic/clientset"
 	"github.com/tektoncd/triggers/pkg/client/dynamic/clientset/tekton"
 	"github.com/tektoncd/triggers/pkg/sink"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	evadapter "knative.dev/eventing/pkg/adapter/v2"
 	"knative.dev/pkg/injection"
@@ -41,7 +42,18 @@ func main() {
 
 	cfg := injection.ParseAndGetRESTConfigOrDie()
 
-	dc := dynamic.NewForConfigOrDie(cfg)
+	sinkArgs, err := sink.GetArgs()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	// The dynamic and discovery clients create the resources, which is most of the load of busy
+	// EventListeners on the API server.
+	resourceCfg, err := sink.ResourceClientConfig(cfg, sinkArgs)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	dc := dynamic.NewForConfigOrDie(resourceCfg)
 	dc = dynamicClientset.New(tekton.WithClient(dc))
 	ctx = context.WithValue(ctx, dynamicclient.Key{}, dc)
 
@@ -49,11 +61,12 @@ func main() {
 	// dynamic client we've set up above.
 	ctx = injection.Dynamic.SetupDynamic(ctx)
 
-	sinkArgs, err := sink.GetArgs()
+	sinkClients, err := sink.ConfigureClients(ctx, cfg)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
-	sinkClients, err := sink.ConfigureClients(ctx, cfg)
+	sinkClients.ResourceConfig = resourceCfg
+	sinkClients.DiscoveryClient, err = discovery.NewDiscoveryClientForConfig(resourceCfg)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
@@ -19,6 +19,7 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"flag"
 	"fmt"
 	"log"
 	"net"
@@ -27,13 +28,19 @@ import (
 
 	triggersclientset "github.com/tektoncd/triggers/pkg/client/clientset/versioned"
 	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/pkg/interceptors/cel"
+	"github.com/tektoncd/triggers/pkg/interceptors/dedup"
 	"github.com/tektoncd/triggers/pkg/interceptors/server"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	cminformer "knative.dev/pkg/configmap/informer"
 	"knative.dev/pkg/injection"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/signals"
+	"knative.dev/pkg/system"
 )
 
 const (
@@ -44,6 +51,19 @@ const (
 	idleTimeout  = 60 * time.Second
 )
 
+var (
+	secretCacheTTL = flag.Duration("secret-cache-ttl", interceptors.DefaultSecretCacheTTL,
+		"How long the secrets referenced by interceptors are cached before being read again. 0 disables the cache.")
+	dnsTimeout = flag.Duration("dns-timeout", cel.DefaultDNSTimeout,
+		"The time budget of the DNS lookups of a CEL function call.")
+	dnsCacheTTL = flag.Duration("dns-cache-ttl", cel.DefaultDNSCacheTTL,
+		"How long the results of the DNS lookups of CEL functions are cached. 0 disables the cache.")
+	dnsFailOpen = flag.Bool("dns-fail-open", false,
+		"Whether the matchesHost CEL function returns true rather than false when a DNS lookup fails.")
+	dedupCollectInterval = flag.Duration("dedup-collect-interval", dedup.DefaultCollectInterval,
+		"How often the expired Leases of the dedup interceptor are deleted.")
+)
+
 func main() {
 	// set up signals so we handle the first shutdown signal gracefully
 	ctx := signals.NewContext()
@@ -64,13 +84,41 @@ func main() {
 		}
 	}()
 
-	service, err := server.NewWithCoreInterceptors(interceptors.DefaultSecretGetter(kubeclient.Get(ctx).CoreV1()), logger)
+	if *secretCacheTTL < 0 {
+		logger.Fatalf("invalid -secret-cache-ttl %s: must not be negative", *secretCacheTTL)
+	}
+	if *dnsTimeout <= 0 {
+		logger.Fatalf("invalid -dns-timeout %s: must be positive", *dnsTimeout)
+	}
+	if *dnsCacheTTL < 0 {
+		logger.Fatalf("invalid -dns-cache-ttl %s: must not be negative", *dnsCacheTTL)
+	}
+	if *dedupCollectInterval <= 0 {
+		logger.Fatalf("invalid -dedup-collect-interval %s: must be positive", *dedupCollectInterval)
+	}
+	sg := interceptors.NewSecretGetter(kubeclient.Get(ctx).CoreV1(), *secretCacheTTL)
+	service, err := server.NewWithCoreInterceptors(sg, logger)
 	if err != nil {
 		logger.Errorf("failed to initialize core interceptors: %s", err)
 		return
 	}
+	// The dedup interceptor claims keys in Leases shared by all replicas.
+	leaseStore := dedup.NewLeaseStore(kubeclient.Get(ctx).CoordinationV1(), system.Namespace())
+	go leaseStore.CollectExpired(ctx, *dedupCollectInterval, logger)
+	service.RegisterInterceptor("dedup", dedup.NewInterceptor(sg, leaseStore))
+	service.RegisterInterceptor("cel", &cel.Interceptor{
+		SecretGetter: sg,
+		Resolver:     cel.NewResolver(net.DefaultResolver, *dnsTimeout, *dnsCacheTTL, *dnsFailOpen),
+	})
 	startInformer()
 
+	// The clusterInfo CEL function returns the metadata of the cluster from a ConfigMap, which is optional.
+	cmw := cminformer.NewInformedWatcher(kubeclient.Get(ctx), system.Namespace())
+	cmw.WatchWithDefault(corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: cel.ClusterInfoConfigName}}, cel.DefaultClusterInfo.UpdateFromConfigMap)
+	if err := cmw.Start(ctx.Done()); err != nil {
+		logger.Fatalf("failed to watch the %s ConfigMap: %s", cel.ClusterInfoConfigName, err)
+	}
+
 	mux := http.NewServeMux()
 	mux.Handle("/", service)
 	mux.HandleFunc("/ready", handler)
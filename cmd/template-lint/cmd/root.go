@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/template"
+	"k8s.io/apimachinery/pkg/runtime/serializer/streaming"
+	discoveryclient "k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var (
+	rootCmd = &cobra.Command{
+		Use:   "template-lint",
+		Short: "Tekton TriggerTemplate linter",
+		Run:   rootRun,
+	}
+
+	templatePath string
+	kubeconfig   string
+)
+
+func init() {
+	rootCmd.Flags().StringVarP(&templatePath, "template", "t", "", "Path to trigger templates")
+	rootCmd.Flags().StringVarP(&kubeconfig, "kubeconfig", "k", "", "Path to a kubeconfig file, to check that the resources resolve to API resources of the cluster")
+	if err := rootCmd.MarkFlagRequired("template"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+func rootRun(cmd *cobra.Command, args []string) {
+	var c discoveryclient.ServerResourcesInterface
+	if kubeconfig != "" {
+		config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+		if err != nil {
+			log.Fatalf("error building kubeconfig: %v", err)
+		}
+		c, err = discoveryclient.NewDiscoveryClientForConfig(config)
+		if err != nil {
+			log.Fatalf("error creating discovery client: %v", err)
+		}
+	}
+	errs, err := lintTemplates(os.Stdout, templatePath, c)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if errs > 0 {
+		log.Fatalf("found %d errors", errs)
+	}
+}
+
+// lintTemplates writes the findings of the trigger templates at path to w, and returns the number of
+// findings with the error severity.
+func lintTemplates(w io.Writer, path string, c discoveryclient.ServerResourcesInterface) (int, error) {
+	templates, err := readTemplates(path)
+	if err != nil {
+		return 0, fmt.Errorf("error reading trigger templates: %w", err)
+	}
+
+	errs := 0
+	for _, tt := range templates {
+		for _, f := range template.LintTriggerTemplate(tt, c) {
+			if f.Severity == template.SeverityError {
+				errs++
+			}
+			if _, err := fmt.Fprintf(w, "%s: %s\n", tt.Name, f); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return errs, nil
+}
+
+func readTemplates(path string) ([]*v1beta1.TriggerTemplate, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading template file: %w", err)
+	}
+	defer f.Close()
+
+	var list []*v1beta1.TriggerTemplate
+	decoder := streaming.NewDecoder(f, scheme.Codecs.UniversalDecoder())
+	for {
+		tt := new(v1beta1.TriggerTemplate)
+		if _, _, err := decoder.Decode(nil, tt); err != nil {
+			if err == io.EOF {
+				return list, nil
+			}
+			return nil, fmt.Errorf("error decoding trigger templates: %w", err)
+		}
+		list = append(list, tt)
+	}
+}
+
+// Execute runs the command.
+func Execute() error {
+	return rootCmd.Execute()
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLintTemplates(t *testing.T) {
+	out := new(bytes.Buffer)
+	errs, err := lintTemplates(out, "../testdata/triggertemplate.yaml", nil)
+	if err != nil {
+		t.Fatalf("lintTemplates: %v", err)
+	}
+	if errs != 2 {
+		t.Errorf("lintTemplates() = %d, want 2 errors", errs)
+	}
+
+	want := `pipeline-template: error: spec.resourcetemplates[0].spec.params[0].value: unbalanced $( in "$(tt.params.gitrevision"
+pipeline-template: error: spec.resourcetemplates[0].spec.params[1].value: undeclared param $(tt.params.gitrepository)
+pipeline-template: warning: spec.resourcetemplates[0].metadata.generateName: generateName is ignored since name is set
+pipeline-template: warning: spec.params[0]: param gitrevision is not used by any resource template
+pipeline-template: warning: spec.params[1]: param gitrepositoryurl is not used by any resource template
+`
+	if diff := cmp.Diff(want, out.String()); diff != "" {
+		t.Errorf("-want +got: %s", diff)
+	}
+}
@@ -129,7 +129,7 @@ func trigger(triggerFile, httpPath, action, kubeconfig string, writer io.Writer)
 			}
 		case "create":
 			{
-				err := r.CreateResources(tri.Namespace, "", resources, tri.Name, eventID, eventLog)
+				err := r.CreateResources(tri.Namespace, "", tri.Spec.Finalizer, resources, tri.Name, eventID, eventLog)
 				if err != nil {
 					return fmt.Errorf("fail to create resources: %w", err)
 				}
@@ -212,7 +212,12 @@ func processTriggerSpec(kubeClient kubernetes.Interface, client triggersclientse
 		tri.Namespace = "default"
 	}
 
-	rt, err := template.ResolveTrigger(*tri,
+	extensions := map[string]interface{}{}
+	if iresp != nil && iresp.Extensions != nil {
+		extensions = iresp.Extensions
+	}
+	routed, _ := template.RouteTrigger(*tri, extensions)
+	rt, err := template.ResolveTrigger(routed,
 		func(name string) (*triggersv1.TriggerBinding, error) {
 			return client.TriggersV1beta1().TriggerBindings(tri.Namespace).Get(context.Background(), name, metav1.GetOptions{})
 		},
@@ -226,18 +231,23 @@ func processTriggerSpec(kubeClient kubernetes.Interface, client triggersclientse
 		log.Error("Failed to resolve Trigger: ", err)
 		return nil, err
 	}
-	extensions := map[string]interface{}{}
-	if iresp != nil && iresp.Extensions != nil {
-		extensions = iresp.Extensions
-	}
-	params, err := template.ResolveParams(rt, finalPayload, header, extensions, template.NewTriggerContext(eventID))
+	params, err := template.ResolveParams(rt, finalPayload, header, request.URL.Query(), extensions, template.NewTriggerContext(eventID))
 	if err != nil {
 		log.Error("Failed to resolve parameters", err)
 		return nil, err
 	}
 	log.Infof("ResolvedParams : %+v", params)
 
-	resources := template.ResolveResources(rt.TriggerTemplate, params)
+	resources, err := template.ResolveResources(rt.TriggerTemplate, params)
+	if err != nil {
+		log.Error("Failed to resolve resources", err)
+		return nil, err
+	}
+	resources, err = template.ApplyOverlays(resources, tri.Spec.Overlays, params, rt.TriggerTemplate.Spec.Params)
+	if err != nil {
+		log.Error("Failed to apply overlays", err)
+		return nil, err
+	}
 
 	return resources, nil
 }
@@ -24,6 +24,10 @@ import (
 	"github.com/tektoncd/triggers/pkg/apis/triggers/contexts"
 	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	triggersclient "github.com/tektoncd/triggers/pkg/client/injection/client"
+	"github.com/tektoncd/triggers/pkg/interceptors/cel"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
@@ -84,6 +88,16 @@ func NewValidationAdmissionController(ctx context.Context, cmw configmap.Watcher
 	// Decorate contexts with the current state of the config.
 	store := defaultconfig.NewStore(logging.FromContext(ctx).Named("config-store"))
 	store.WatchConfigs(cmw)
+	// Look up the ClusterInterceptors referenced by Triggers, so that references
+	// to missing ones are rejected at admission.
+	client := triggersclient.Get(ctx)
+	clusterInterceptorExists := func(ctx context.Context, name string) (bool, error) {
+		_, err := client.TriggersV1alpha1().ClusterInterceptors().Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return err == nil, err
+	}
 	return validation.NewAdmissionController(ctx,
 
 		// Name of the resource webhook.
@@ -97,6 +111,8 @@ func NewValidationAdmissionController(ctx context.Context, cmw configmap.Watcher
 
 		// A function that infuses the context passed to Validate/SetDefaults with custom metadata.
 		func(ctx context.Context) context.Context {
+			ctx = contexts.WithClusterInterceptorExists(ctx, clusterInterceptorExists)
+			ctx = contexts.WithCELCheck(ctx, cel.Check)
 			return contexts.WithUpgradeViaDefaulting(store.ToContext(ctx))
 		},
 
@@ -115,8 +131,9 @@ func NewConfigValidationController(ctx context.Context, cmw configmap.Watcher) *
 		"/config-validation",
 
 		configmap.Constructors{
-			logging.ConfigMapName():               logging.NewConfigFromConfigMap,
-			defaultconfig.GetDefaultsConfigName(): defaultconfig.NewDefaultsFromConfigMap,
+			logging.ConfigMapName():                     logging.NewConfigFromConfigMap,
+			defaultconfig.GetDefaultsConfigName():       defaultconfig.NewDefaultsFromConfigMap,
+			defaultconfig.GetClientProfilesConfigName(): defaultconfig.NewClientProfilesFromConfigMap,
 		},
 	)
 }
@@ -21,9 +21,12 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -36,20 +39,30 @@ import (
 	triggertemplatesinformer "github.com/tektoncd/triggers/pkg/client/injection/informers/triggers/v1beta1/triggertemplate"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/tektoncd/triggers/pkg/apis/config"
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/pkg/resources"
 	"github.com/tektoncd/triggers/pkg/sink"
 	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes/scheme"
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	"knative.dev/eventing/pkg/adapter/v2"
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/injection/clients/dynamicclient"
 	"knative.dev/pkg/logging"
+	"knative.dev/pkg/system"
 )
 
 type envConfig struct {
@@ -127,8 +140,12 @@ func (s *sinker) getHTTPClient() (*http.Client, error) {
 	}()
 
 	tlsConfig = &tls.Config{
-		RootCAs:    certPool,
-		MinVersion: tls.VersionTLS13, // Added MinVersion to avoid  G402: TLS MinVersion too low. (gosec)
+		RootCAs:      certPool,
+		MinVersion:   tls.VersionTLS13, // Added MinVersion to avoid  G402: TLS MinVersion too low. (gosec)
+		CipherSuites: s.Args.TLSCipherSuites,
+	}
+	if s.Args.TLSMinVersion != 0 {
+		tlsConfig.MinVersion = s.Args.TLSMinVersion
 	}
 	return &http.Client{
 		Transport: &http.Transport{
@@ -203,11 +220,47 @@ func (s *sinker) getCertFromInterceptor(certPool *x509.CertPool) error {
 	return nil
 }
 
+// loadResourcePolicies compiles the policies of the ConfigMaps named by the resource-policies flag.
+func (s *sinker) loadResourcePolicies(ctx context.Context) (*resources.PolicySet, error) {
+	var policies []resources.Policy
+	for _, name := range s.Args.ResourcePolicies {
+		cm, err := kubeclient.Get(ctx).CoreV1().ConfigMaps(s.Args.ElNamespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the resource policies ConfigMap %s: %w", name, err)
+		}
+		p, err := resources.ParsePolicies(cm)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, p...)
+	}
+	return resources.NewPolicySet(policies)
+}
+
+// loadClientProfiles reads the client profiles ConfigMap of Triggers. There are no profiles if it doesn't exist,
+// or if the service account of the EventListener isn't allowed to read it.
+func (s *sinker) loadClientProfiles(ctx context.Context) (*config.ClientProfiles, error) {
+	name := config.GetClientProfilesConfigName()
+	cm, err := kubeclient.Get(ctx).CoreV1().ConfigMaps(system.Namespace()).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case kerrors.IsNotFound(err):
+		return nil, nil
+	case kerrors.IsForbidden(err):
+		s.Logger.Warnf("Not using client profiles, the service account is not allowed to read the ConfigMap %s/%s: %v", system.Namespace(), name, err)
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get the client profiles ConfigMap %s: %w", name, err)
+	}
+	return config.NewClientProfilesFromConfigMap(cm)
+}
+
 func (s *sinker) Start(ctx context.Context) error {
 	clientObj, err := s.getHTTPClient()
 	if err != nil {
 		return err
 	}
+	// Surface the warnings about the fields of created resources, among others, in the sink logs.
+	rest.SetDefaultWarningHandler(sink.NewWarningLogger(s.Logger))
 	// Create EventListener Sink
 	r := sink.Sink{
 		KubeClientSet:          kubeclient.Get(ctx),
@@ -222,7 +275,26 @@ func (s *sinker) Start(ctx context.Context) error {
 		Logger:                 s.Logger,
 		Recorder:               s.Recorder,
 		CloudEventURI:          s.Args.CloudEventURI,
-		Auth:                   sink.DefaultAuthOverride{},
+		InterceptorTimeout:     s.Args.InterceptorTimeout,
+		GuardSecretGetter:      interceptors.DefaultSecretGetter(kubeclient.Get(ctx).CoreV1()),
+		CreateTimeout:          s.Args.CreateTimeout,
+		ProvenanceLabels:       s.Args.ProvenanceLabels,
+		FieldValidation:        s.Args.FieldValidation,
+		LabelSanitization:      s.Args.LabelSanitization,
+		LogFormat:              s.Args.LogFormat,
+		TrustedProxies:         s.Args.TrustedProxies,
+		TrustedProxyHeader:     s.Args.TrustedProxyHeader,
+		Synchronous:            s.Args.Synchronous,
+		MultiStatus:            s.Args.MultiStatus,
+		NoMatchPolicy:          s.Args.NoMatchPolicy,
+		NoMatchLogLevel:        s.Args.NoMatchLogLevel,
+		RollbackOnFailure:      s.Args.RollbackOnFailure,
+		CreateBestEffort:       s.Args.CreateBestEffort,
+		BatchSize:              s.Args.BatchSize,
+		BasePath:               s.Args.BasePath,
+		AllowedMethods:         s.Args.AllowedMethods,
+		AllowedContentTypes:    s.Args.AllowedContentTypes,
+		Auth:                   sink.DefaultAuthOverride{Config: s.Clients.ResourceConfig},
 		WGProcessTriggers:      &sync.WaitGroup{},
 		EventRecorder:          s.createRecorder(s.injCtx, "EventListener"),
 
@@ -235,10 +307,203 @@ func (s *sinker) Start(ctx context.Context) error {
 		ClusterInterceptorLister:    clusterinterceptorsinformer.Get(s.injCtx).Lister(),
 		InterceptorLister:           interceptorsinformer.Get(s.injCtx).Lister(),
 	}
+	if s.Args.ProvenanceLabels != nil {
+		// Make it visible that selecting created resources by the missing labels stops working.
+		names := make([]string, 0, len(s.Args.ProvenanceLabels))
+		for _, k := range s.Args.ProvenanceLabels {
+			names = append(names, triggers.GroupName+k)
+		}
+		if len(names) == 0 {
+			s.Logger.Warn("Provenance labels are disabled: created resources can't be selected by EventListener, event ID or Trigger")
+		} else {
+			s.Logger.Warnf("Only adding the provenance labels %s to created resources", strings.Join(names, ", "))
+		}
+	}
+	if s.Args.BackpressureMaxInFlight > 0 {
+		r.Backpressure = &sink.Backpressure{
+			MaxInFlight: s.Args.BackpressureMaxInFlight,
+			RetryAfter:  time.Duration(s.Args.BackpressureRetryAfter) * time.Second,
+		}
+	}
+	if s.Args.EventWorkers > 0 {
+		r.EventPool = &sink.EventPool{
+			Workers:    s.Args.EventWorkers,
+			QueueSize:  s.Args.EventQueueSize,
+			Overflow:   s.Args.EventOverflow,
+			RetryAfter: time.Duration(s.Args.BackpressureRetryAfter) * time.Second,
+		}
+	}
+	// The exceeded creation limits are reported in the status even if the recent activity isn't, at most
+	// once per window.
+	if s.Args.ActivityInterval > 0 || s.Args.CreationLimit > 0 {
+		replica, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("failed to get the name of the replica to report its activity: %w", err)
+		}
+		r.Activity = &sink.Activity{
+			TriggersClient:         s.Clients.TriggersClient,
+			EventListenerName:      s.Args.ElName,
+			EventListenerNamespace: s.Args.ElNamespace,
+			Interval:               s.Args.ActivityInterval,
+			Replica:                replica,
+			CreationLimitsOnly:     s.Args.ActivityInterval == 0,
+			Logger:                 s.Logger,
+		}
+		if r.Activity.CreationLimitsOnly {
+			r.Activity.Interval = s.Args.CreationLimitWindow
+		}
+	}
+	if s.Args.AccessLog {
+		r.AccessLog = &sink.AccessLog{
+			Logger:             sink.NewAccessLogger(os.Stdout),
+			Fields:             s.Args.AccessLogFields,
+			SampleRate:         s.Args.AccessLogSampleRate,
+			TrustedProxies:     s.Args.TrustedProxies,
+			TrustedProxyHeader: s.Args.TrustedProxyHeader,
+		}
+	}
+	if s.Args.DebugTraceSecret != "" || s.Args.DebugTraceSampleRate > 0 {
+		r.DebugTrace = &sink.DebugTrace{
+			Logger:       sink.NewDebugTraceLogger(os.Stdout),
+			SecretName:   s.Args.DebugTraceSecret,
+			SecretGetter: interceptors.DefaultSecretGetter(r.KubeClientSet.CoreV1()),
+			SampleRate:   s.Args.DebugTraceSampleRate,
+		}
+		if s.Args.DebugTraceSampleRate > 0 {
+			s.Logger.Warnf("Writing a debug trace, including the payload, of %v of the events", s.Args.DebugTraceSampleRate)
+		}
+	}
+	r.InterceptorMetricNames = sink.NewMetricNames(s.Args.InterceptorMetricsNames)
+	if s.Args.ResourceMetricsNamespaces >= 0 {
+		r.ResourceMetricNamespaces = sink.NewMetricNames(s.Args.ResourceMetricsNamespaces)
+	}
+	if len(s.Args.EventIDHeaders) > 0 || s.Args.EventIDExpression != "" {
+		r.EventIDSource = &sink.EventIDSource{
+			Headers:      s.Args.EventIDHeaders,
+			Expression:   s.Args.EventIDExpression,
+			SecretGetter: interceptors.DefaultSecretGetter(r.KubeClientSet.CoreV1()),
+		}
+	}
+	if s.Args.AuditSink != "" {
+		w, err := resources.NewAuditWriter(s.Args.AuditSink, nil)
+		if err != nil {
+			return err
+		}
+		r.Creator = &resources.AuditingCreator{
+			Writer:                 w,
+			EventListenerNamespace: s.Args.ElNamespace,
+			SkipCreate:             s.Args.AuditOnly,
+			BestEffort:             s.Args.AuditBestEffort,
+		}
+		if s.Args.AuditOnly {
+			s.Logger.Warnf("Resources are only written to the audit sink %s instead of being created", s.Args.AuditSink)
+		}
+	}
+	if s.Args.MutationHookURL != "" {
+		r.Mutation = &resources.Mutation{
+			Hook:     &resources.HTTPMutationHook{URL: s.Args.MutationHookURL},
+			Timeout:  s.Args.MutationHookTimeout,
+			FailOpen: s.Args.MutationHookFailOpen,
+			Logger:   s.Logger,
+		}
+	}
+	if len(s.Args.ResourcePolicies) > 0 {
+		policies, err := s.loadResourcePolicies(ctx)
+		if err != nil {
+			return err
+		}
+		// The policies are checked first, so that the resources violating them aren't audited either.
+		r.Creator = &resources.ValidatingCreator{Creator: r.Creator, Policies: policies}
+	}
+	profiles, err := s.loadClientProfiles(ctx)
+	if err != nil {
+		return err
+	}
+	if profiles != nil && len(profiles.Profiles) > 0 {
+		r.ClientProfiles = sink.NewClientProfiles(profiles, s.Clients.ResourceConfig, interceptors.DefaultSecretGetter(r.KubeClientSet.CoreV1()), s.Args.ElNamespace)
+	}
+	if len(s.Args.PayloadParsers) > 0 {
+		parsers, err := sink.PayloadParsersFor(s.Args.PayloadParsers)
+		if err != nil {
+			return err
+		}
+		r.PayloadParsers = parsers
+	}
+	if s.Args.SelfTestSecret != "" {
+		r.SelfTest = &sink.SelfTest{
+			SecretName:   s.Args.SelfTestSecret,
+			SecretGetter: interceptors.DefaultSecretGetter(r.KubeClientSet.CoreV1()),
+		}
+	}
+	if s.Args.ConfigEndpointSecret != "" {
+		r.ConfigEndpoint = &sink.ConfigEndpoint{
+			SecretName:   s.Args.ConfigEndpointSecret,
+			SecretGetter: interceptors.DefaultSecretGetter(r.KubeClientSet.CoreV1()),
+		}
+	}
+	if s.Args.CallbackURL != "" {
+		r.Callback = &sink.Callback{
+			URL:          s.Args.CallbackURL,
+			Payload:      json.RawMessage(s.Args.CallbackPayload),
+			SecretName:   s.Args.CallbackSecret,
+			SecretGetter: interceptors.DefaultSecretGetter(r.KubeClientSet.CoreV1()),
+			Timeout:      s.Args.CallbackTimeout,
+			Retries:      s.Args.CallbackRetries,
+		}
+	}
+	if s.Args.ResourceEventsCreated || s.Args.ResourceEventsFailed {
+		uri := s.Args.ResourceEventsURI
+		if uri == "" {
+			uri = s.Args.CloudEventURI
+		}
+		if uri == "" {
+			s.Logger.Warn("No CloudEvents are sent for resources: neither a resource events URI nor a cloudEventURI is set")
+		} else {
+			r.ResourceEvents = &sink.ResourceEvents{
+				TargetURI: uri,
+				Source:    s.Args.ResourceEventsSource,
+				Created:   s.Args.ResourceEventsCreated,
+				Failed:    s.Args.ResourceEventsFailed,
+			}
+		}
+	}
+	if s.Args.QuotaRetryWindow > 0 {
+		r.QuotaRetry = &sink.QuotaRetry{
+			Window:    s.Args.QuotaRetryWindow,
+			MaxQueued: s.Args.QuotaRetryMaxQueued,
+		}
+		go r.QuotaRetry.Run(ctx)
+		if s.Args.QuotaRetryPersist {
+			// The replicas are told apart by the names of their pods, which are their host names.
+			replica, err := os.Hostname()
+			if err != nil {
+				return fmt.Errorf("failed to get the name of the replica to persist its quota retry queue: %w", err)
+			}
+			store := sink.NewSecretQuotaRetryStore(r.KubeClientSet, s.Args.ElNamespace, s.Args.ElName, replica)
+			go store.Hold(ctx, s.Logger)
+			r.QuotaRetry.Store = store
+		}
+	}
+	if s.Args.CreationLimit > 0 {
+		r.CreationLimit = &sink.CreationLimit{
+			Max:        s.Args.CreationLimit,
+			Window:     s.Args.CreationLimitWindow,
+			PerTrigger: s.Args.CreationLimitPerTrigger,
+		}
+	}
+	r.MaxCreatesPerEvent = s.Args.MaxCreatesPerEvent
+
+	// The EventListener is not ready until it can reach the discovery API, which it needs to create resources.
+	r.Discovery = &sink.DiscoveryReadiness{
+		Client:         s.Clients.DiscoveryClient,
+		GracePeriod:    s.Args.DiscoveryGracePeriod,
+		InitialBackoff: s.Args.DiscoveryBackoff,
+		Logger:         s.Logger,
+	}
 
 	mux := http.NewServeMux()
 	eventHandler := http.HandlerFunc(r.HandleEvent)
-	metricsRecorder := &sink.MetricsHandler{Handler: r.IsValidPayload(eventHandler)}
+	metricsRecorder := &sink.MetricsHandler{Handler: r.WithAccessLog(r.FilterRequests(r.WithBackpressure(r.IsValidPayload(r.WithDebugTrace(eventHandler)))))}
 
 	mux.HandleFunc("/", metricsRecorder.Intercept(r.NewMetricsRecorderInterceptor()))
 
@@ -248,33 +513,123 @@ func (s *sinker) Start(ctx context.Context) error {
 		w.WriteHeader(200)
 		fmt.Fprint(w, "ok")
 	})
+	mux.HandleFunc(sink.ReadinessPath, r.Discovery.HandleReadiness)
+
+	if r.SelfTest != nil {
+		mux.HandleFunc(sink.SelfTestPath, r.HandleSelfTest)
+	}
+	if r.ConfigEndpoint != nil {
+		mux.HandleFunc(sink.ConfigPath, r.HandleConfig)
+	}
+	if r.BatchSize > 0 {
+		batchHandler := &sink.MetricsHandler{Handler: r.WithAccessLog(r.FilterRequests(r.WithBackpressure(http.HandlerFunc(r.HandleBatch))))}
+		mux.HandleFunc(sink.BatchPath, batchHandler.Intercept(r.NewMetricsRecorderInterceptor()))
+	}
 
 	srv := &http.Server{
 		Addr:              fmt.Sprintf(":%s", s.Args.Port),
-		ReadHeaderTimeout: s.Args.ELReadTimeOut * time.Second,
+		ReadHeaderTimeout: s.Args.ELReadHeaderTimeOut * time.Second,
 		ReadTimeout:       s.Args.ELReadTimeOut * time.Second,
 		WriteTimeout:      s.Args.ELWriteTimeOut * time.Second,
 		IdleTimeout:       s.Args.ELIdleTimeOut * time.Second,
-		Handler: http.TimeoutHandler(mux,
+		Handler: http.TimeoutHandler(r.WithBasePath(mux),
 			s.Args.ELTimeOutHandler*time.Second, "EventListener Timeout!\n"),
 	}
 
-	if s.Args.Cert == "" && s.Args.Key == "" {
-		if err := srv.ListenAndServe(); err != nil {
+	// Exit if discovery isn't available within the grace period, so that the EventListener is restarted.
+	discoveryErr := make(chan error, 1)
+	go func() {
+		if err := r.Discovery.WaitForDiscovery(ctx); err != nil {
+			discoveryErr <- err
+			srv.Close()
+			return
+		}
+		// The persisted creates are replayed once the resources they create can be discovered.
+		r.ReplayQuotaRetries()
+	}()
+	serveErr := func(err error) error {
+		select {
+		case err := <-discoveryErr:
 			return err
+		default:
+			return err
+		}
+	}
+
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	if len(s.Args.ProxyProtocolUpstreams) > 0 {
+		// The header is read before the TLS handshake and the request, within the read header timeout.
+		ln = &sink.ProxyProtocolListener{
+			Listener:      ln,
+			Upstreams:     s.Args.ProxyProtocolUpstreams,
+			HeaderTimeout: srv.ReadHeaderTimeout,
+			Logger:        s.Logger,
+		}
+	}
+
+	if s.Args.Cert == "" && s.Args.Key == "" {
+		if s.Args.H2C {
+			// Requests that are not HTTP/2 are passed on to the handler as HTTP/1.1 requests.
+			srv.Handler = h2c.NewHandler(srv.Handler, &http2.Server{IdleTimeout: srv.IdleTimeout})
+		}
+		if err := srv.Serve(ln); err != nil {
+			return serveErr(err)
 		}
 	} else {
-		if err := srv.ListenAndServeTLS(s.Args.Cert, s.Args.Key); err != nil {
+		tlsConfig, err := serverTLSConfig(s.Args)
+		if err != nil {
+			ln.Close()
 			return err
 		}
+		srv.TLSConfig = tlsConfig
+		if err := srv.ServeTLS(ln, s.Args.Cert, s.Args.Key); err != nil {
+			return serveErr(err)
+		}
 	}
 	return nil
 }
 
+// serverTLSConfig returns the TLS configuration of the EventListener server when it verifies client
+// certificates against the CA bundle in args.ClientCA, or restricts the TLS version or cipher suites.
+// Certificates are verified if given rather than required so that the probes of the kubelet, which
+// does not present one, keep working.
+func serverTLSConfig(args sink.Args) (*tls.Config, error) {
+	if args.ClientCA == "" && args.TLSMinVersion == 0 && args.TLSCipherSuites == nil {
+		return nil, nil
+	}
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		CipherSuites: args.TLSCipherSuites,
+	}
+	if args.TLSMinVersion != 0 {
+		cfg.MinVersion = args.TLSMinVersion
+	}
+	if args.ClientCA == "" {
+		return cfg, nil
+	}
+	caCert, err := os.ReadFile(args.ClientCA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA bundle: %w", err)
+	}
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("no certificates found in client CA bundle %s", args.ClientCA)
+	}
+	cfg.ClientCAs = certPool
+	cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	return cfg, nil
+}
+
 func New(sinkArgs sink.Args, sinkClients sink.Clients, recorder *sink.Recorder) adapter.AdapterConstructor {
 	return func(ctx context.Context, processed adapter.EnvConfigAccessor, ceClient cloudevents.Client) adapter.Adapter {
 		env := processed.(*envConfig)
 		logger := logging.FromContext(ctx)
+		if sinkArgs.LogFormat == triggers.JSONLogFormat {
+			logger = sink.NewJSONLogger(logger, os.Stdout)
+		}
 
 		return &sinker{
 			Logger:    logger,
@@ -17,19 +17,38 @@ limitations under the License.
 package adapter
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
 	"log"
+	"math/big"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/triggers/pkg/apis/config"
 	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 	faketriggersclient "github.com/tektoncd/triggers/pkg/client/injection/client/fake"
 	fakeClusterInterceptorinformer "github.com/tektoncd/triggers/pkg/client/injection/informers/triggers/v1alpha1/clusterinterceptor/fake"
 	"github.com/tektoncd/triggers/pkg/sink"
 	pkgtesting "github.com/tektoncd/triggers/test"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ktesting "k8s.io/client-go/testing"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
 	"knative.dev/pkg/logging"
+	"knative.dev/pkg/system"
 )
 
 func TestGetHTTPClientEmptyCaBundle(t *testing.T) {
@@ -111,3 +130,111 @@ func TestGetHTTPClient(t *testing.T) {
 		t.Errorf("Diff: -want +got: %s", cmp.Diff(c, http.Client{}))
 	}
 }
+
+func TestServerTLSConfig(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "client-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := serverTLSConfig(sink.Args{ClientCA: caFile})
+	if err != nil {
+		t.Fatalf("serverTLSConfig() unexpected error: %v", err)
+	}
+	if cfg.ClientAuth != tls.VerifyClientCertIfGiven {
+		t.Errorf("serverTLSConfig() ClientAuth = %v, want %v", cfg.ClientAuth, tls.VerifyClientCertIfGiven)
+	}
+	if cfg.ClientCAs == nil {
+		t.Errorf("serverTLSConfig() expected a client CA pool")
+	}
+
+	if cfg.MinVersion != tls.VersionTLS12 || cfg.CipherSuites != nil {
+		t.Errorf("serverTLSConfig() MinVersion = %x and CipherSuites = %v, want TLS 1.2 and the defaults", cfg.MinVersion, cfg.CipherSuites)
+	}
+
+	if cfg, err := serverTLSConfig(sink.Args{}); cfg != nil || err != nil {
+		t.Errorf("serverTLSConfig() without a client CA = %v, %v, want nil, nil", cfg, err)
+	}
+
+	suites := []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}
+	cfg, err = serverTLSConfig(sink.Args{TLSMinVersion: tls.VersionTLS13, TLSCipherSuites: suites})
+	if err != nil {
+		t.Fatalf("serverTLSConfig() unexpected error: %v", err)
+	}
+	if cfg.MinVersion != tls.VersionTLS13 || len(cfg.CipherSuites) != 1 || cfg.ClientCAs != nil {
+		t.Errorf("serverTLSConfig() = %+v, want TLS 1.3, the cipher suites and no client CA", cfg)
+	}
+
+	empty := filepath.Join(dir, "empty.crt")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := serverTLSConfig(sink.Args{ClientCA: empty}); err == nil || !strings.Contains(err.Error(), "no certificates found") {
+		t.Errorf("serverTLSConfig() expected an error for a bundle without certificates, got %v", err)
+	}
+	if _, err := serverTLSConfig(sink.Args{ClientCA: filepath.Join(dir, "missing.crt")}); err == nil {
+		t.Error("serverTLSConfig() expected an error for a missing bundle")
+	}
+}
+
+func TestLoadClientProfiles(t *testing.T) {
+	profiles := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: config.GetClientProfilesConfigName(), Namespace: system.Namespace()},
+		Data:       map[string]string{"bulk": "allowedNamespaces: [\"*\"]\nqps: 50\nburst: 100"},
+	}
+	for _, tc := range []struct {
+		name    string
+		objects []runtime.Object
+		err     error
+		want    *config.ClientProfiles
+		wantErr bool
+	}{{
+		name:    "profiles",
+		objects: []runtime.Object{profiles},
+		want:    &config.ClientProfiles{Profiles: map[string]config.ClientProfile{"bulk": {AllowedNamespaces: []string{"*"}, QPS: 50, Burst: 100}}},
+	}, {
+		name: "no ConfigMap",
+	}, {
+		name: "not allowed to read the ConfigMap",
+		err:  kerrors.NewForbidden(corev1.Resource("configmaps"), profiles.Name, errors.New("rbac")),
+	}, {
+		name:    "failed to read the ConfigMap",
+		err:     kerrors.NewServiceUnavailable("try again"),
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := pkgtesting.SetupFakeContext(t)
+			ctx, kubeClient := fakekubeclient.With(ctx, tc.objects...)
+			if tc.err != nil {
+				kubeClient.PrependReactor("get", "configmaps", func(ktesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tc.err
+				})
+			}
+			s := sinker{Logger: logging.FromContext(ctx)}
+			got, err := s.loadClientProfiles(ctx)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("loadClientProfiles() = %v, want error %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("loadClientProfiles() -want +got: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// ClientProfile configures the clients creating the resources of the triggers that reference it.
+// +k8s:deepcopy-gen=true
+type ClientProfile struct {
+	// AllowedNamespaces are the namespaces whose triggers may reference the profile, or * for all
+	// namespaces. The profile may create resources with more privileges than the triggers creating
+	// them, so it must be granted to namespaces explicitly.
+	AllowedNamespaces []string `json:"allowedNamespaces"`
+	// Kubeconfig, if set, is the key of a Secret in the namespace of the EventListener holding a
+	// kubeconfig, whose current context is the cluster the resources are created in.
+	Kubeconfig *corev1.SecretKeySelector `json:"kubeconfig,omitempty"`
+	// Impersonate, if set, is the user the clients impersonate. The service account of a trigger
+	// takes precedence.
+	Impersonate *ClientImpersonation `json:"impersonate,omitempty"`
+	// QPS and Burst, if set, are the rate limits of the clients. Burst must be set with QPS.
+	QPS   float32 `json:"qps,omitempty"`
+	Burst int     `json:"burst,omitempty"`
+}
+
+// ClientImpersonation is the user, and its groups, impersonated by the clients of a profile.
+// +k8s:deepcopy-gen=true
+type ClientImpersonation struct {
+	User   string   `json:"user"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+// ClientProfiles holds the client profiles that triggers reference by name.
+// +k8s:deepcopy-gen=true
+type ClientProfiles struct {
+	Profiles map[string]ClientProfile
+}
+
+// GetClientProfilesConfigName returns the name of the configmap containing the
+// client profiles.
+func GetClientProfilesConfigName() string {
+	if e := os.Getenv("CONFIG_CLIENT_PROFILES_NAME"); e != "" {
+		return e
+	}
+	return "config-client-profiles-triggers"
+}
+
+// Has returns true if a profile has the name.
+func (cfg *ClientProfiles) Has(name string) bool {
+	if cfg == nil {
+		return false
+	}
+	_, ok := cfg.Profiles[name]
+	return ok
+}
+
+// AllowedIn returns true if the triggers in namespace may reference the profile with the name.
+func (cfg *ClientProfiles) AllowedIn(name, namespace string) bool {
+	if cfg == nil {
+		return false
+	}
+	return cfg.Profiles[name].Allows(namespace)
+}
+
+// Allows returns true if the triggers in namespace may reference the profile.
+func (p ClientProfile) Allows(namespace string) bool {
+	for _, ns := range p.AllowedNamespaces {
+		if ns == "*" || ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// NewClientProfilesFromMap returns a Config given a map corresponding to a ConfigMap.
+// Each key is the name of a profile, and its value the YAML of the profile. Keys
+// starting with an underscore, such as _example, are ignored.
+func NewClientProfilesFromMap(cfgMap map[string]string) (*ClientProfiles, error) {
+	profiles := ClientProfiles{Profiles: map[string]ClientProfile{}}
+	for name, value := range cfgMap {
+		if strings.HasPrefix(name, "_") {
+			continue
+		}
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid client profile name %q: %s", name, strings.Join(errs, ", "))
+		}
+		var p ClientProfile
+		if err := yaml.UnmarshalStrict([]byte(value), &p); err != nil {
+			return nil, fmt.Errorf("invalid client profile %q: %w", name, err)
+		}
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("invalid client profile %q: %w", name, err)
+		}
+		profiles.Profiles[name] = p
+	}
+	return &profiles, nil
+}
+
+func (p ClientProfile) validate() error {
+	if len(p.AllowedNamespaces) == 0 {
+		return fmt.Errorf("allowedNamespaces must list the namespaces whose triggers may reference the profile, or *")
+	}
+	for _, ns := range p.AllowedNamespaces {
+		if errs := validation.IsDNS1123Label(ns); ns != "*" && len(errs) > 0 {
+			return fmt.Errorf("invalid allowed namespace %q: %s", ns, strings.Join(errs, ", "))
+		}
+	}
+	if p.Kubeconfig != nil && (p.Kubeconfig.Name == "" || p.Kubeconfig.Key == "") {
+		return fmt.Errorf("kubeconfig must have a name and a key")
+	}
+	if p.Impersonate != nil && p.Impersonate.User == "" {
+		return fmt.Errorf("impersonate must have a user")
+	}
+	if p.QPS < 0 || p.Burst < 0 {
+		return fmt.Errorf("qps and burst must not be negative")
+	}
+	if p.QPS > 0 && p.Burst == 0 {
+		return fmt.Errorf("burst must be set when qps is")
+	}
+	return nil
+}
+
+// NewClientProfilesFromConfigMap returns a Config for the given configmap
+func NewClientProfilesFromConfigMap(config *corev1.ConfigMap) (*ClientProfiles, error) {
+	return NewClientProfilesFromMap(config.Data)
+}
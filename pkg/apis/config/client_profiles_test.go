@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/triggers/pkg/apis/config"
+	"github.com/tektoncd/triggers/test"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNewClientProfilesFromConfigMap(t *testing.T) {
+	cm := test.ConfigMapFromTestFile(t, config.GetClientProfilesConfigName())
+	got, err := config.NewClientProfilesFromConfigMap(cm)
+	if err != nil {
+		t.Fatalf("NewClientProfilesFromConfigMap() = %v", err)
+	}
+	want := &config.ClientProfiles{Profiles: map[string]config.ClientProfile{
+		"remote": {
+			AllowedNamespaces: []string{"ci"},
+			Kubeconfig:        &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "remote-cluster"}, Key: "kubeconfig"},
+		},
+		"deployer": {
+			AllowedNamespaces: []string{"ci", "deploy"},
+			Impersonate:       &config.ClientImpersonation{User: "deployer", Groups: []string{"deployers"}},
+		},
+		"bulk": {AllowedNamespaces: []string{"*"}, QPS: 200, Burst: 400},
+	}}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("NewClientProfilesFromConfigMap() (-want, +got): %s", d)
+	}
+	if !got.Has("bulk") || got.Has("_example") {
+		t.Errorf("Has() = %t, %t, want bulk and not _example", got.Has("bulk"), got.Has("_example"))
+	}
+	for _, tc := range []struct {
+		profile, namespace string
+		want               bool
+	}{
+		{profile: "remote", namespace: "ci", want: true},
+		{profile: "remote", namespace: "deploy", want: false},
+		{profile: "bulk", namespace: "deploy", want: true},
+		{profile: "missing", namespace: "ci", want: false},
+	} {
+		if allowed := got.AllowedIn(tc.profile, tc.namespace); allowed != tc.want {
+			t.Errorf("AllowedIn(%s, %s) = %t, want %t", tc.profile, tc.namespace, allowed, tc.want)
+		}
+	}
+}
+
+func TestNewClientProfilesFromConfigMap_Invalid(t *testing.T) {
+	if _, err := config.NewClientProfilesFromConfigMap(test.ConfigMapFromTestFile(t, "config-client-profiles-invalid")); err == nil {
+		t.Error("NewClientProfilesFromConfigMap() was expected to return an error")
+	}
+
+	for _, tc := range []struct {
+		name    string
+		data    map[string]string
+		wantErr string
+	}{{
+		name:    "invalid name",
+		data:    map[string]string{"Remote": "allowedNamespaces: [ci]\nqps: 5\nburst: 10"},
+		wantErr: `invalid client profile name "Remote"`,
+	}, {
+		name:    "unknown field",
+		data:    map[string]string{"remote": "allowedNamespaces: [ci]\nkubeconfig: {name: remote, key: config}\ntimeout: 5s"},
+		wantErr: `invalid client profile "remote"`,
+	}, {
+		name:    "kubeconfig without a key",
+		data:    map[string]string{"remote": "allowedNamespaces: [ci]\nkubeconfig: {name: remote}"},
+		wantErr: "kubeconfig must have a name and a key",
+	}, {
+		name:    "impersonation without a user",
+		data:    map[string]string{"deployer": "allowedNamespaces: [ci]\nimpersonate: {groups: [deployers]}"},
+		wantErr: "impersonate must have a user",
+	}, {
+		name:    "negative burst",
+		data:    map[string]string{"bulk": "allowedNamespaces: [ci]\nburst: -1"},
+		wantErr: "qps and burst must not be negative",
+	}, {
+		name:    "no allowed namespaces",
+		data:    map[string]string{"bulk": "qps: 5\nburst: 10"},
+		wantErr: "allowedNamespaces must list the namespaces",
+	}, {
+		name:    "invalid allowed namespace",
+		data:    map[string]string{"bulk": "allowedNamespaces: [CI]\nqps: 5\nburst: 10"},
+		wantErr: `invalid allowed namespace "CI"`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := config.NewClientProfilesFromMap(tc.data)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("NewClientProfilesFromMap() = %v, want an error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -27,8 +27,9 @@ type cfgKey struct{}
 // Config holds the collection of configurations that we attach to contexts.
 // +k8s:deepcopy-gen=false
 type Config struct {
-	Defaults     *Defaults
-	FeatureFlags *FeatureFlags
+	Defaults       *Defaults
+	FeatureFlags   *FeatureFlags
+	ClientProfiles *ClientProfiles
 }
 
 // FromContext extracts a Config from the provided context.
@@ -48,9 +49,11 @@ func FromContextOrDefaults(ctx context.Context) *Config {
 	}
 	defaults, _ := NewDefaultsFromMap(map[string]string{})
 	featureFlags, _ := NewFeatureFlagsFromMap(map[string]string{})
+	clientProfiles, _ := NewClientProfilesFromMap(map[string]string{})
 	return &Config{
-		Defaults:     defaults,
-		FeatureFlags: featureFlags,
+		Defaults:       defaults,
+		FeatureFlags:   featureFlags,
+		ClientProfiles: clientProfiles,
 	}
 }
 
@@ -73,8 +76,9 @@ func NewStore(logger configmap.Logger, onAfterStore ...func(name string, value i
 			"defaults/features/artifacts",
 			logger,
 			configmap.Constructors{
-				GetFeatureFlagsConfigName(): NewFeatureFlagsFromConfigMap,
-				GetDefaultsConfigName():     NewDefaultsFromConfigMap,
+				GetFeatureFlagsConfigName():   NewFeatureFlagsFromConfigMap,
+				GetDefaultsConfigName():       NewDefaultsFromConfigMap,
+				GetClientProfilesConfigName(): NewClientProfilesFromConfigMap,
 			},
 			onAfterStore...,
 		),
@@ -98,9 +102,14 @@ func (s *Store) Load() *Config {
 	if featureFlags == nil {
 		featureFlags, _ = NewFeatureFlagsFromMap(map[string]string{})
 	}
+	clientProfiles := s.UntypedLoad(GetClientProfilesConfigName())
+	if clientProfiles == nil {
+		clientProfiles, _ = NewClientProfilesFromMap(map[string]string{})
+	}
 
 	return &Config{
-		Defaults:     defaults.(*Defaults).DeepCopy(),
-		FeatureFlags: featureFlags.(*FeatureFlags).DeepCopy(),
+		Defaults:       defaults.(*Defaults).DeepCopy(),
+		FeatureFlags:   featureFlags.(*FeatureFlags).DeepCopy(),
+		ClientProfiles: clientProfiles.(*ClientProfiles).DeepCopy(),
 	}
 }
@@ -34,13 +34,18 @@ func TestStoreLoadWithContext(t *testing.T) {
 
 	expectedFeatureFlag, _ := config.NewFeatureFlagsFromConfigMap(defaultConfig)
 
+	clientProfilesConfig := test.ConfigMapFromTestFile(t, "config-client-profiles-triggers")
+	expectedClientProfiles, _ := config.NewClientProfilesFromConfigMap(clientProfilesConfig)
+
 	expected := &config.Config{
-		Defaults:     expectedDefaults,
-		FeatureFlags: expectedFeatureFlag,
+		Defaults:       expectedDefaults,
+		FeatureFlags:   expectedFeatureFlag,
+		ClientProfiles: expectedClientProfiles,
 	}
 
 	store := config.NewStore(logtesting.TestLogger(t))
 	store.OnConfigChanged(defaultConfig)
+	store.OnConfigChanged(clientProfilesConfig)
 
 	cfg := config.FromContext(store.ToContext(context.Background()))
 
@@ -56,6 +61,8 @@ func TestFromContextOrDefaults(t *testing.T) {
 	featureFlagsCM := test.ConfigMapFromTestFile(t, "feature-flags-triggers")
 	featureFlags, _ := config.NewFeatureFlagsFromConfigMap(featureFlagsCM)
 
+	clientProfiles, _ := config.NewClientProfilesFromMap(map[string]string{})
+
 	for _, tc := range []struct {
 		name string
 		in   context.Context
@@ -64,8 +71,9 @@ func TestFromContextOrDefaults(t *testing.T) {
 		name: "sets to default when context has no config",
 		in:   context.Background(),
 		want: &config.Config{
-			Defaults:     defaults,
-			FeatureFlags: featureFlags,
+			Defaults:       defaults,
+			FeatureFlags:   featureFlags,
+			ClientProfiles: clientProfiles,
 		},
 	}, {
 		name: "uses Config from context if present",
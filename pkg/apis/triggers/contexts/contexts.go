@@ -36,3 +36,42 @@ func WithUpgradeViaDefaulting(ctx context.Context) context.Context {
 func IsUpgradeViaDefaulting(ctx context.Context) bool {
 	return ctx.Value(upgradeViaDefaultingKey{}) != nil
 }
+
+// clusterInterceptorExistsKey is used as the key in a context.Context for the
+// ClusterInterceptorExistsFunc used during validation.
+type clusterInterceptorExistsKey struct{}
+
+// ClusterInterceptorExistsFunc reports whether the ClusterInterceptor with the given name exists.
+type ClusterInterceptorExistsFunc func(ctx context.Context, name string) (bool, error)
+
+// WithClusterInterceptorExists sets the function used by validation to check that the
+// ClusterInterceptors referenced by Triggers exist.
+func WithClusterInterceptorExists(ctx context.Context, f ClusterInterceptorExistsFunc) context.Context {
+	return context.WithValue(ctx, clusterInterceptorExistsKey{}, f)
+}
+
+// GetClusterInterceptorExists returns the function set by WithClusterInterceptorExists, or nil
+// if it isn't set.
+func GetClusterInterceptorExists(ctx context.Context) ClusterInterceptorExistsFunc {
+	f, _ := ctx.Value(clusterInterceptorExistsKey{}).(ClusterInterceptorExistsFunc)
+	return f
+}
+
+// celCheckKey is used as the key in a context.Context for the CELCheckFunc used during validation.
+type celCheckKey struct{}
+
+// CELCheckFunc compiles and type checks a CEL expression without evaluating it. Its errors have the positions
+// of the issues in the expression.
+type CELCheckFunc func(expression string) error
+
+// WithCELCheck sets the function used by validation to check the filter and overlay expressions of the CEL
+// interceptors of Triggers, and the when expressions of all their interceptors.
+func WithCELCheck(ctx context.Context, f CELCheckFunc) context.Context {
+	return context.WithValue(ctx, celCheckKey{}, f)
+}
+
+// GetCELCheck returns the function set by WithCELCheck, or nil if it isn't set.
+func GetCELCheck(ctx context.Context) CELCheckFunc {
+	f, _ := ctx.Value(celCheckKey{}).(CELCheckFunc)
+	return f
+}
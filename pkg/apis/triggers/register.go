@@ -15,4 +15,12 @@ const (
 
 	// TriggerGroupLabelKey is used as a label identifier for a TriggerGroup
 	TriggerGroupLabelKey = "/triggergroup"
+
+	// ResourceTemplateLabelKey is used as the label identifier for the index of the resource template
+	// chosen by the selection of a TriggerTemplate
+	ResourceTemplateLabelKey = "/resource-template"
+
+	// TTLAfterCompletionAnnotationKey is the default annotation identifier for the time to live, after they
+	// complete, of the resources created by a trigger with a resourceTTL
+	TTLAfterCompletionAnnotationKey = "/ttl-after-completion"
 )
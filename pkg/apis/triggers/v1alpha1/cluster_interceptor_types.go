@@ -51,6 +51,11 @@ type ClusterInterceptor struct {
 // ClusterInterceptorSpec describes the Spec for an ClusterInterceptor
 type ClusterInterceptorSpec struct {
 	ClientConfig ClientConfig `json:"clientConfig"`
+	// Params are the default params passed to the interceptor. Params with the same name set by
+	// a Trigger override them.
+	// +optional
+	// +listType=atomic
+	Params []InterceptorParams `json:"params,omitempty"`
 }
 
 // ClusterInterceptorStatus holds the status of the ClusterInterceptor
@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	"context"
+	"fmt"
 
 	"knative.dev/pkg/apis"
 )
@@ -42,5 +43,22 @@ func (s *ClusterInterceptorSpec) validate(ctx context.Context) (errs *apis.Field
 			errs = errs.Also(apis.ErrMissingField("spec.clientConfig.service.name"))
 		}
 	}
+	return errs.Also(validateDefaultParams(s.Params).ViaField("spec"))
+}
+
+// validateDefaultParams checks that the default params of an interceptor are named, and that
+// each name is only used once.
+func validateDefaultParams(params []InterceptorParams) (errs *apis.FieldError) {
+	names := map[string]struct{}{}
+	for i, p := range params {
+		if p.Name == "" {
+			errs = errs.Also(apis.ErrMissingField(fmt.Sprintf("params[%d].name", i)))
+			continue
+		}
+		if _, ok := names[p.Name]; ok {
+			errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("duplicate param name %q", p.Name), fmt.Sprintf("params[%d].name", i)))
+		}
+		names[p.Name] = struct{}{}
+	}
 	return errs
 }
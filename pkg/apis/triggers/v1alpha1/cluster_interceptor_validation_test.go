@@ -8,6 +8,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 )
@@ -90,6 +91,42 @@ func TestClusterInterceptorValidate(t *testing.T) {
 			},
 		},
 		want: apis.ErrMissingField("spec.clientConfig.service.name"),
+	}, {
+		name: "param missing name",
+		clusterInterceptor: triggersv1.ClusterInterceptor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "github",
+			},
+			Spec: triggersv1.ClusterInterceptorSpec{
+				ClientConfig: triggersv1.ClientConfig{
+					URL: &apis.URL{Scheme: "http", Host: "some.host"},
+				},
+				Params: []triggersv1.InterceptorParams{{
+					Value: apiextensionsv1.JSON{Raw: []byte(`"bar"`)},
+				}},
+			},
+		},
+		want: apis.ErrMissingField("spec.params[0].name"),
+	}, {
+		name: "duplicate param names",
+		clusterInterceptor: triggersv1.ClusterInterceptor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "github",
+			},
+			Spec: triggersv1.ClusterInterceptorSpec{
+				ClientConfig: triggersv1.ClientConfig{
+					URL: &apis.URL{Scheme: "http", Host: "some.host"},
+				},
+				Params: []triggersv1.InterceptorParams{{
+					Name:  "foo",
+					Value: apiextensionsv1.JSON{Raw: []byte(`"bar"`)},
+				}, {
+					Name:  "foo",
+					Value: apiextensionsv1.JSON{Raw: []byte(`"baz"`)},
+				}},
+			},
+		},
+		want: apis.ErrGeneric(`duplicate param name "foo"`, "spec.params[1].name"),
 	}}
 
 	for _, tc := range tests {
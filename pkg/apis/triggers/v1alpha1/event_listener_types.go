@@ -113,6 +113,10 @@ type EventInterceptor = TriggerInterceptor
 type SecretRef struct {
 	SecretKey  string `json:"secretKey,omitempty"`
 	SecretName string `json:"secretName,omitempty"`
+	// Namespace is the namespace of the secret, if it isn't the namespace of the trigger. The secret must
+	// list the namespace of the trigger in its triggers.tekton.dev/allowed-trigger-namespaces annotation.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // EventListenerBinding refers to a particular TriggerBinding or ClusterTriggerBindingresource.
@@ -36,6 +36,11 @@ type Interceptor struct {
 // InterceptorSpec describes the Spec for an Interceptor
 type InterceptorSpec struct {
 	ClientConfig ClientConfig `json:"clientConfig"`
+	// Params are the default params passed to the interceptor. Params with the same name set by
+	// a Trigger override them.
+	// +optional
+	// +listType=atomic
+	Params []InterceptorParams `json:"params,omitempty"`
 }
 
 // InterceptorStatus holds the status of the Interceptor
@@ -42,5 +42,5 @@ func (s *InterceptorSpec) validate(ctx context.Context) (errs *apis.FieldError)
 			errs = errs.Also(apis.ErrMissingField("spec.clientConfig.service.name"))
 		}
 	}
-	return errs
+	return errs.Also(validateDefaultParams(s.Params).ViaField("spec"))
 }
@@ -188,6 +188,13 @@ func (in *ClusterInterceptorList) DeepCopyObject() runtime.Object {
 func (in *ClusterInterceptorSpec) DeepCopyInto(out *ClusterInterceptorSpec) {
 	*out = *in
 	in.ClientConfig.DeepCopyInto(&out.ClientConfig)
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make([]InterceptorParams, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -616,6 +623,13 @@ func (in *InterceptorRef) DeepCopy() *InterceptorRef {
 func (in *InterceptorSpec) DeepCopyInto(out *InterceptorSpec) {
 	*out = *in
 	in.ClientConfig.DeepCopyInto(&out.ClientConfig)
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make([]InterceptorParams, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -17,6 +17,8 @@ limitations under the License.
 package v1beta1
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -67,6 +69,142 @@ type EventListenerSpec struct {
 	LabelSelector     *metav1.LabelSelector       `json:"labelSelector,omitempty"`
 	Resources         Resources                   `json:"resources,omitempty"`
 	CloudEventURI     string                      `json:"cloudEventURI,omitempty"`
+	// ResourceKinds optionally restricts the kinds of resources the Triggers
+	// of the EventListener can create, independently of the permissions of
+	// their service accounts
+	// +optional
+	ResourceKinds *ResourceKindPolicy `json:"resourceKinds,omitempty"`
+	// CreateStrategies optionally select how the resources of some kinds are
+	// created, e.g. applied rather than created. The first strategy matching
+	// the kind of a resource is used, and the resources of the other kinds
+	// are created
+	// +listType=atomic
+	// +optional
+	CreateStrategies []ResourceCreateStrategy `json:"createStrategies,omitempty"`
+	// JSONNumbers selects how the numbers of the JSON bodies and extensions
+	// of the events are decoded: float, the default, or exact
+	// +optional
+	JSONNumbers JSONNumbers `json:"jsonNumbers,omitempty"`
+}
+
+// ResourceKindPolicy restricts the kinds of resources an EventListener can
+// create. A kind is allowed if it matches one of Allowed, or Allowed is
+// empty, and it matches none of Denied.
+type ResourceKindPolicy struct {
+	// Allowed are the kinds of resources the EventListener can create. All
+	// kinds are allowed if empty
+	// +listType=atomic
+	// +optional
+	Allowed []ResourceKind `json:"allowed,omitempty"`
+	// Denied are the kinds of resources the EventListener can't create, even
+	// if they are allowed
+	// +listType=atomic
+	// +optional
+	Denied []ResourceKind `json:"denied,omitempty"`
+}
+
+// ResourceKind matches the kinds of resources of an API group.
+type ResourceKind struct {
+	// Group is the API group of the kind, empty for the core API group
+	// +optional
+	Group string `json:"group,omitempty"`
+	// Version is the version of the API group. All versions match if empty
+	// +optional
+	Version string `json:"version,omitempty"`
+	// Kind is the kind, or * for all the kinds of the API group
+	Kind string `json:"kind"`
+}
+
+// Matches returns whether the kind matches gvk.
+func (k ResourceKind) Matches(gvk schema.GroupVersionKind) bool {
+	return k.Group == gvk.Group && (k.Version == "" || k.Version == gvk.Version) && (k.Kind == "*" || k.Kind == gvk.Kind)
+}
+
+// Allows returns whether the policy allows creating resources of the kind gvk.
+// A nil policy allows all kinds.
+func (p *ResourceKindPolicy) Allows(gvk schema.GroupVersionKind) bool {
+	if p == nil {
+		return true
+	}
+	for _, k := range p.Denied {
+		if k.Matches(gvk) {
+			return false
+		}
+	}
+	if len(p.Allowed) == 0 {
+		return true
+	}
+	for _, k := range p.Allowed {
+		if k.Matches(gvk) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateStrategy is how the resources of a kind are created.
+type CreateStrategy string
+
+const (
+	// CreateStrategyCreate creates the resources, and fails if they already
+	// exist. It is the default strategy.
+	CreateStrategyCreate CreateStrategy = "create"
+	// CreateStrategyApply applies the resources with a server-side apply,
+	// which creates them or updates the fields the EventListener set on them.
+	CreateStrategyApply CreateStrategy = "apply"
+	// CreateStrategyPatch creates the resources, or patches them with a
+	// strategic merge patch if they already exist.
+	CreateStrategyPatch CreateStrategy = "patch"
+	// CreateStrategyCreateOrUpdate creates the resources, or replaces them if
+	// they already exist.
+	CreateStrategyCreateOrUpdate CreateStrategy = "createOrUpdate"
+)
+
+// ResourceCreateStrategy selects the create strategy of the kinds of
+// resources it matches.
+type ResourceCreateStrategy struct {
+	ResourceKind `json:",inline"`
+	// Strategy is how the resources are created: create, apply, patch or
+	// createOrUpdate
+	Strategy CreateStrategy `json:"strategy"`
+}
+
+// CreateStrategyFor returns the strategy of the first of strategies that
+// matches gvk, or CreateStrategyCreate if none does.
+func CreateStrategyFor(strategies []ResourceCreateStrategy, gvk schema.GroupVersionKind) CreateStrategy {
+	for _, s := range strategies {
+		if s.Matches(gvk) {
+			return s.Strategy
+		}
+	}
+	return CreateStrategyCreate
+}
+
+// JSONNumbers is how the numbers of the JSON values of the events are
+// decoded.
+type JSONNumbers string
+
+const (
+	// JSONNumbersFloat decodes the numbers to 64-bit floating point numbers,
+	// which can't represent all the integers above 2^53 exactly. It is the
+	// default.
+	JSONNumbersFloat JSONNumbers = "float"
+	// JSONNumbersExact keeps the numbers as they are written, so that large
+	// integers, e.g. 64-bit IDs, are bound to params without losing
+	// precision.
+	JSONNumbersExact JSONNumbers = "exact"
+)
+
+// Unmarshal decodes the JSON value data into v like json.Unmarshal, with the
+// numbers decoded to json.Number rather than float64 if n is
+// JSONNumbersExact.
+func (n JSONNumbers) Unmarshal(data []byte, v interface{}) error {
+	if n != JSONNumbersExact || !json.Valid(data) {
+		return json.Unmarshal(data, v)
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	return d.Decode(v)
 }
 
 type Resources struct {
@@ -106,6 +244,55 @@ type EventListenerTrigger struct {
 	// multi-tenant model based scenarios
 	// +optional
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// Finalizer is optionally added to the resources created by the trigger,
+	// in addition to the finalizers specified in their templates,
+	// so that a controller can clean up after them when they are deleted
+	// +optional
+	Finalizer string `json:"finalizer,omitempty"`
+	// PromotedExtensions are the keys of the extensions added by the interceptors
+	// that bindings can also read as top-level fields of the event, e.g. $(foo)
+	// for $(extensions.foo)
+	// +listType=atomic
+	// +optional
+	PromotedExtensions []string `json:"promotedExtensions,omitempty"`
+	// DefaultNamespace is the namespace of the resources created by the trigger
+	// whose templates don't specify one, instead of the namespace of the trigger
+	// +optional
+	DefaultNamespace string `json:"defaultNamespace,omitempty"`
+	// NamespaceParam is the name of a param whose value is the namespace of the
+	// resources created by the trigger whose templates don't specify one, if
+	// DefaultNamespace is not set and the value is not empty
+	// +optional
+	NamespaceParam string `json:"namespaceParam,omitempty"`
+	// DefaultAPIVersion is the apiVersion of the resources created by the
+	// trigger whose templates specify neither an apiVersion nor a kind
+	// +optional
+	DefaultAPIVersion string `json:"defaultAPIVersion,omitempty"`
+	// DefaultKind is the kind of the resources created by the trigger whose
+	// templates specify neither an apiVersion nor a kind
+	// +optional
+	DefaultKind string `json:"defaultKind,omitempty"`
+	// ResourceLabels are optionally added to the resources created by the
+	// trigger, unless their templates specify labels with the same keys
+	// +optional
+	ResourceLabels map[string]string `json:"resourceLabels,omitempty"`
+	// ResourceAnnotations are optionally added to the resources created by the
+	// trigger, unless their templates specify annotations with the same keys
+	// +optional
+	ResourceAnnotations map[string]string `json:"resourceAnnotations,omitempty"`
+	// Overlays set fields of the resources created by the trigger to the typed
+	// values of params, after the params are replaced in their templates
+	// +listType=atomic
+	// +optional
+	Overlays []TriggerOverlay `json:"overlays,omitempty"`
+	// ResourceTTL optionally annotates the resources created by the trigger with
+	// their time to live after completion, for a cleanup controller to delete them
+	// +optional
+	ResourceTTL *ResourceTTL `json:"resourceTTL,omitempty"`
+	// ClientProfile optionally names the client profile, defined in the client
+	// profiles ConfigMap of Triggers, of the clients creating the resources
+	// +optional
+	ClientProfile string `json:"clientProfile,omitempty"`
 }
 
 // EventListenerTriggerGroup defines a group of Triggers that share a common set of interceptors
@@ -131,6 +318,10 @@ type EventInterceptor = TriggerInterceptor
 type SecretRef struct {
 	SecretKey  string `json:"secretKey,omitempty"`
 	SecretName string `json:"secretName,omitempty"`
+	// Namespace is the namespace of the secret, if it isn't the namespace of the trigger. The secret must
+	// list the namespace of the trigger in its triggers.tekton.dev/allowed-trigger-namespaces annotation.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // EventListenerBinding refers to a particular TriggerBinding or ClusterTriggerBinding resource.
@@ -160,6 +351,25 @@ type EventListenerStatus struct {
 
 	// Configuration stores configuration for the EventListener service
 	Configuration EventListenerConfig `json:"configuration"`
+
+	// RecentActivity lists the resources most recently created by the EventListener, newest first
+	// +optional
+	// +listType=atomic
+	RecentActivity []EventListenerActivity `json:"recentActivity,omitempty"`
+}
+
+// EventListenerActivity records a resource created by the EventListener
+type EventListenerActivity struct {
+	// Time is when the resource was created
+	Time metav1.Time `json:"time"`
+	// Trigger is the name of the Trigger that created the resource
+	Trigger string `json:"trigger"`
+	// EventID is the ID of the event that fired the Trigger
+	EventID string `json:"eventID"`
+	// Kind is the kind of the created resource
+	Kind string `json:"kind"`
+	// Name is the name of the created resource
+	Name string `json:"name"`
 }
 
 // EventListenerConfig stores configuration for resources generated by the
@@ -187,8 +397,16 @@ const (
 	// DeploymentExists is the ConditionType set on the EventListener, which
 	// specifies Deployment existence.
 	DeploymentExists apis.ConditionType = "Deployment"
+	// SecretsExist is the ConditionType set on the EventListener, which
+	// specifies the existence of the secrets referenced by its interceptors.
+	SecretsExist apis.ConditionType = "Secrets"
 )
 
+// CreationLimited is the ConditionType set on the EventListener by its replicas
+// when they skip the creation of the resources of a Trigger to stay within
+// their creation limit. It doesn't affect the readiness of the EventListener.
+const CreationLimited apis.ConditionType = "CreationLimited"
+
 // Check that EventListener may be validated and defaulted.
 // TriggerBindingKind defines the type of TriggerBinding used by the EventListener.
 type TriggerBindingKind string
@@ -229,6 +447,7 @@ func (els *EventListenerStatus) SetReadyCondition() {
 	for _, ct := range []apis.ConditionType{
 		ServiceExists,
 		DeploymentExists,
+		SecretsExist,
 		apis.ConditionType(appsv1.DeploymentProgressing),
 		apis.ConditionType(appsv1.DeploymentAvailable)} {
 		if sc := els.GetCondition(ct); sc != nil {
@@ -284,6 +503,14 @@ func (els *EventListenerStatus) SetConditionsForDynamicObjects(conditions v1beta
 		})
 	}
 
+	if sc := els.GetCondition(SecretsExist); sc != nil && sc.Status != corev1.ConditionTrue {
+		els.SetCondition(&apis.Condition{
+			Type:    apis.ConditionReady,
+			Status:  corev1.ConditionFalse,
+			Message: fmt.Sprintf("Condition %s has status: %s with message: %s", sc.Type, sc.Status, sc.Message),
+		})
+		return
+	}
 	els.SetCondition(&apis.Condition{
 		Type:    apis.ConditionReady,
 		Status:  corev1.ConditionTrue,
@@ -309,6 +536,48 @@ func (els *EventListenerStatus) SetExistsCondition(cond apis.ConditionType, err
 	}
 }
 
+// SetSecretsCondition sets the SecretsExist condition on the EventListener from
+// the error of looking up the secrets referenced by its interceptors, or clears
+// it if its interceptors don't reference any secrets.
+func (els *EventListenerStatus) SetSecretsCondition(referenced bool, err error) {
+	switch {
+	case !referenced:
+		_ = eventListenerCondSet.Manage(els).ClearCondition(SecretsExist)
+	case err != nil:
+		els.SetCondition(&apis.Condition{
+			Type:    SecretsExist,
+			Status:  corev1.ConditionFalse,
+			Reason:  "SecretsMissing",
+			Message: err.Error(),
+		})
+	default:
+		els.SetCondition(&apis.Condition{
+			Type:    SecretsExist,
+			Status:  corev1.ConditionTrue,
+			Message: "Referenced secrets exist",
+		})
+	}
+}
+
+// SetCreationLimitedCondition sets the CreationLimited condition on the
+// EventListener, recording that the replica skipped the creation of the
+// resources of the Trigger at the given time, which becomes the last transition
+// time of the condition. This is a local change and needs to be persisted to the
+// K8s API elsewhere.
+func (els *EventListenerStatus) SetCreationLimitedCondition(trigger, replica string, at metav1.Time) {
+	els.SetCondition(&apis.Condition{
+		Type:    CreationLimited,
+		Status:  corev1.ConditionTrue,
+		Reason:  "CreationLimitExceeded",
+		Message: fmt.Sprintf("Replica %s skipped the creation of the resources of Trigger %s to stay within its creation limit", replica, trigger),
+	})
+	for i := range els.Conditions {
+		if els.Conditions[i].Type == CreationLimited {
+			els.Conditions[i].LastTransitionTime = apis.VolatileTime{Inner: at}
+		}
+	}
+}
+
 // InitializeConditions will set all conditions in eventListenerCondSet to false
 // for the EventListener. This does not use the InitializeCondition() provided
 // by the conditionsImpl to avoid setting the happy condition. This is a local
@@ -17,14 +17,19 @@ limitations under the License.
 package v1beta1
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	"knative.dev/pkg/apis/duck/v1beta1"
@@ -300,6 +305,82 @@ func TestSetConditionsForDynamicObjects(t *testing.T) {
 	}
 }
 
+func TestSetConditionsForDynamicObjects_MissingSecrets(t *testing.T) {
+	var status EventListenerStatus
+	status.SetSecretsCondition(true, errors.New(`secret "github" does not exist`))
+	status.SetConditionsForDynamicObjects(v1beta1.Conditions{{
+		Type:   apis.ConditionReady,
+		Status: corev1.ConditionTrue,
+	}})
+	want := &apis.Condition{
+		Type:    apis.ConditionReady,
+		Status:  corev1.ConditionFalse,
+		Message: `Condition Secrets has status: False with message: secret "github" does not exist`,
+	}
+	if diff := cmp.Diff(want, status.GetCondition(apis.ConditionReady), cmpopts.IgnoreFields(
+		apis.Condition{}, "LastTransitionTime.Inner.Time")); diff != "" {
+		t.Fatalf("SetConditionsForDynamicObjects() error. Diff (-want/+got) : %s", diff)
+	}
+}
+
+func TestSetSecretsCondition(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		referenced bool
+		err        error
+		want       *apis.Condition
+	}{{
+		name:       "secrets exist",
+		referenced: true,
+		want: &apis.Condition{
+			Type:    SecretsExist,
+			Status:  corev1.ConditionTrue,
+			Message: "Referenced secrets exist",
+		},
+	}, {
+		name:       "secrets missing",
+		referenced: true,
+		err:        errors.New(`secret "github" does not exist`),
+		want: &apis.Condition{
+			Type:    SecretsExist,
+			Status:  corev1.ConditionFalse,
+			Reason:  "SecretsMissing",
+			Message: `secret "github" does not exist`,
+		},
+	}, {
+		name: "no secrets referenced",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			els := EventListenerStatus{}
+			els.SetSecretsCondition(true, errors.New("stale"))
+			els.SetSecretsCondition(tc.referenced, tc.err)
+			if diff := cmp.Diff(tc.want, els.GetCondition(SecretsExist), cmpopts.IgnoreFields(
+				apis.Condition{}, "LastTransitionTime.Inner.Time")); diff != "" {
+				t.Errorf("SetSecretsCondition() mismatch. -want/+got: %s", diff)
+			}
+		})
+	}
+}
+
+func TestSetCreationLimitedCondition(t *testing.T) {
+	first := metav1.NewTime(time.Unix(1650000000, 0))
+	last := metav1.NewTime(first.Add(time.Minute))
+	els := EventListenerStatus{}
+	els.SetCreationLimitedCondition("git-push", "el-example-1", first)
+	// The condition records the last time the limit was exceeded, even if its message doesn't change.
+	els.SetCreationLimitedCondition("git-push", "el-example-1", last)
+	want := &apis.Condition{
+		Type:               CreationLimited,
+		Status:             corev1.ConditionTrue,
+		Reason:             "CreationLimitExceeded",
+		Message:            "Replica el-example-1 skipped the creation of the resources of Trigger git-push to stay within its creation limit",
+		LastTransitionTime: apis.VolatileTime{Inner: last},
+	}
+	if diff := cmp.Diff(want, els.GetCondition(CreationLimited)); diff != "" {
+		t.Errorf("SetCreationLimitedCondition() mismatch. -want/+got: %s", diff)
+	}
+}
+
 func TestEventListenerStatus_SetReadyCondition(t *testing.T) {
 	for _, tc := range []struct {
 		name    string
@@ -413,3 +494,106 @@ func TestEventListenerStatus_SetReadyCondition(t *testing.T) {
 		})
 	}
 }
+
+func TestResourceKindPolicy_Allows(t *testing.T) {
+	pipelineRun := schema.GroupVersionKind{Group: "tekton.dev", Version: "v1beta1", Kind: "PipelineRun"}
+	pipelineResource := schema.GroupVersionKind{Group: "tekton.dev", Version: "v1alpha1", Kind: "PipelineResource"}
+	secret := schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+	for _, tc := range []struct {
+		name   string
+		policy *ResourceKindPolicy
+		want   map[schema.GroupVersionKind]bool
+	}{{
+		name: "nil policy",
+		want: map[schema.GroupVersionKind]bool{pipelineRun: true, pipelineResource: true, secret: true},
+	}, {
+		name:   "allowed kinds",
+		policy: &ResourceKindPolicy{Allowed: []ResourceKind{{Group: "tekton.dev", Kind: "PipelineRun"}}},
+		want:   map[schema.GroupVersionKind]bool{pipelineRun: true, pipelineResource: false, secret: false},
+	}, {
+		name:   "allowed group",
+		policy: &ResourceKindPolicy{Allowed: []ResourceKind{{Group: "tekton.dev", Kind: "*"}}},
+		want:   map[schema.GroupVersionKind]bool{pipelineRun: true, pipelineResource: true, secret: false},
+	}, {
+		name:   "allowed version",
+		policy: &ResourceKindPolicy{Allowed: []ResourceKind{{Group: "tekton.dev", Version: "v1alpha1", Kind: "*"}}},
+		want:   map[schema.GroupVersionKind]bool{pipelineRun: false, pipelineResource: true, secret: false},
+	}, {
+		name:   "denied kinds",
+		policy: &ResourceKindPolicy{Denied: []ResourceKind{{Kind: "Secret"}}},
+		want:   map[schema.GroupVersionKind]bool{pipelineRun: true, pipelineResource: true, secret: false},
+	}, {
+		name: "denied wins",
+		policy: &ResourceKindPolicy{
+			Allowed: []ResourceKind{{Group: "tekton.dev", Kind: "*"}},
+			Denied:  []ResourceKind{{Group: "tekton.dev", Kind: "PipelineResource"}},
+		},
+		want: map[schema.GroupVersionKind]bool{pipelineRun: true, pipelineResource: false, secret: false},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			for gvk, want := range tc.want {
+				if got := tc.policy.Allows(gvk); got != want {
+					t.Errorf("Allows(%s) = %t, want %t", gvk, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateStrategyFor(t *testing.T) {
+	strategies := []ResourceCreateStrategy{
+		{ResourceKind: ResourceKind{Version: "v1", Kind: "ConfigMap"}, Strategy: CreateStrategyApply},
+		{ResourceKind: ResourceKind{Group: "tekton.dev", Kind: "PipelineRun"}, Strategy: CreateStrategyCreate},
+		{ResourceKind: ResourceKind{Group: "tekton.dev", Kind: "*"}, Strategy: CreateStrategyCreateOrUpdate},
+	}
+	for _, tc := range []struct {
+		gvk  schema.GroupVersionKind
+		want CreateStrategy
+	}{{
+		gvk:  schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+		want: CreateStrategyApply,
+	}, {
+		gvk:  schema.GroupVersionKind{Group: "tekton.dev", Version: "v1beta1", Kind: "PipelineRun"},
+		want: CreateStrategyCreate,
+	}, {
+		gvk:  schema.GroupVersionKind{Group: "tekton.dev", Version: "v1beta1", Kind: "Pipeline"},
+		want: CreateStrategyCreateOrUpdate,
+	}, {
+		gvk:  schema.GroupVersionKind{Version: "v1", Kind: "Secret"},
+		want: CreateStrategyCreate,
+	}} {
+		if got := CreateStrategyFor(strategies, tc.gvk); got != tc.want {
+			t.Errorf("CreateStrategyFor(%s) = %s, want %s", tc.gvk, got, tc.want)
+		}
+	}
+}
+
+func TestJSONNumbers_Unmarshal(t *testing.T) {
+	data := []byte(`{"id": 1234567890123456789, "ratio": 0.5}`)
+	for _, tc := range []struct {
+		numbers JSONNumbers
+		want    map[string]interface{}
+	}{{
+		want: map[string]interface{}{"id": float64(1234567890123456789), "ratio": 0.5},
+	}, {
+		numbers: JSONNumbersFloat,
+		want:    map[string]interface{}{"id": float64(1234567890123456789), "ratio": 0.5},
+	}, {
+		numbers: JSONNumbersExact,
+		want:    map[string]interface{}{"id": json.Number("1234567890123456789"), "ratio": json.Number("0.5")},
+	}} {
+		var got map[string]interface{}
+		if err := tc.numbers.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unmarshal() with %q numbers returned error: %v", tc.numbers, err)
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("Unmarshal() with %q numbers: -want +got: %s", tc.numbers, diff)
+		}
+	}
+
+	// Like json.Unmarshal, trailing data is an error.
+	var v interface{}
+	if err := JSONNumbersExact.Unmarshal([]byte(`{} {}`), &v); err == nil {
+		t.Error("Unmarshal() of trailing data returned no error")
+	}
+}
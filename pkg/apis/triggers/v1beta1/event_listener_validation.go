@@ -21,6 +21,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/tektoncd/triggers/pkg/apis/triggers"
 	corev1 "k8s.io/api/core/v1"
@@ -53,7 +55,7 @@ func (e *EventListener) Validate(ctx context.Context) *apis.FieldError {
 		errs = errs.Also(triggers.ValidateAnnotations(e.GetObjectMeta().GetAnnotations()))
 	}
 
-	return errs.Also(e.Spec.validate(ctx))
+	return errs.Also(e.Spec.validate(apis.WithinParent(ctx, e.ObjectMeta)))
 }
 
 func (s *EventListenerSpec) validate(ctx context.Context) (errs *apis.FieldError) {
@@ -67,7 +69,7 @@ func (s *EventListenerSpec) validate(ctx context.Context) (errs *apis.FieldError
 
 	// Both Kubernetes and Custom resource can't be present at the same time
 	if s.Resources.KubernetesResource != nil && s.Resources.CustomResource != nil {
-		return apis.ErrMultipleOneOf("spec.resources.kubernetesResource", "spec.resources.customResource")
+		errs = errs.Also(apis.ErrMultipleOneOf("spec.resources.kubernetesResource", "spec.resources.customResource"))
 	}
 
 	if s.Resources.KubernetesResource != nil {
@@ -84,6 +86,68 @@ func (s *EventListenerSpec) validate(ctx context.Context) (errs *apis.FieldError
 		}
 	}
 
+	if s.ResourceKinds != nil {
+		errs = errs.Also(s.ResourceKinds.validate().ViaField("spec.resourceKinds"))
+	}
+
+	for i, cs := range s.CreateStrategies {
+		errs = errs.Also(cs.validate().ViaFieldIndex("spec.createStrategies", i))
+	}
+
+	switch s.JSONNumbers {
+	case "", JSONNumbersFloat, JSONNumbersExact:
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("jsonNumbers %q must be %s or %s", s.JSONNumbers, JSONNumbersFloat, JSONNumbersExact), "spec.jsonNumbers"))
+	}
+
+	return errs
+}
+
+func (p *ResourceKindPolicy) validate() (errs *apis.FieldError) {
+	if len(p.Allowed) == 0 && len(p.Denied) == 0 {
+		return apis.ErrMissingOneOf("allowed", "denied")
+	}
+	for i, k := range p.Allowed {
+		errs = errs.Also(k.validate().ViaFieldIndex("allowed", i))
+	}
+	for i, k := range p.Denied {
+		errs = errs.Also(k.validate().ViaFieldIndex("denied", i))
+	}
+	return errs
+}
+
+func (s ResourceCreateStrategy) validate() (errs *apis.FieldError) {
+	errs = s.ResourceKind.validate()
+	switch s.Strategy {
+	case CreateStrategyCreate, CreateStrategyApply, CreateStrategyPatch, CreateStrategyCreateOrUpdate:
+	case "":
+		errs = errs.Also(apis.ErrMissingField("strategy"))
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("strategy %q must be %s, %s, %s or %s", s.Strategy, CreateStrategyCreate, CreateStrategyApply, CreateStrategyPatch, CreateStrategyCreateOrUpdate), "strategy"))
+	}
+	return errs
+}
+
+// kindRegexp matches the names of kinds, e.g. PipelineRun.
+var kindRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+
+func (k ResourceKind) validate() (errs *apis.FieldError) {
+	if k.Group != "" {
+		if msgs := validation.IsDNS1123Subdomain(k.Group); len(msgs) > 0 {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("group %q must be an API group: %s", k.Group, strings.Join(msgs, ", ")), "group"))
+		}
+	}
+	if k.Version != "" {
+		if msgs := validation.IsDNS1035Label(k.Version); len(msgs) > 0 {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("version %q must be an API version: %s", k.Version, strings.Join(msgs, ", ")), "version"))
+		}
+	}
+	switch {
+	case k.Kind == "":
+		errs = errs.Also(apis.ErrMissingField("kind"))
+	case k.Kind != "*" && !kindRegexp.MatchString(k.Kind):
+		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("kind %q must be a kind, e.g. PipelineRun, or *", k.Kind), "kind"))
+	}
 	return errs
 }
 
@@ -147,8 +211,11 @@ func validateKubernetesObject(orig *KubernetesResource) (errs *apis.FieldError)
 
 func validateEnv(envVars []corev1.EnvVar) (errs *apis.FieldError) {
 	var (
-		count    = 0
-		envValue string
+		count         = 0
+		envValue      string
+		clientCA      *corev1.EnvVar
+		clientCAIndex int
+		certSecret    string
 	)
 	for i, env := range envVars {
 		errs = errs.Also(validateEnvVar(env).ViaIndex(i))
@@ -156,13 +223,35 @@ func validateEnv(envVars []corev1.EnvVar) (errs *apis.FieldError) {
 			count++
 			envValue = env.Name
 		}
+		if env.Name == "TLS_CERT" && env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+			certSecret = env.ValueFrom.SecretKeyRef.Name
+		}
+		if env.Name == "TLS_CLIENT_CA" {
+			clientCA, clientCAIndex = &envVars[i], i
+		}
 	}
 	// This is to make sure both TLS_CERT and TLS_KEY is set for tls connection
 	if count == 1 {
 		errs = errs.Also(&apis.FieldError{
 			Message: fmt.Sprintf("Expected env's are TLS_CERT and TLS_KEY, but got only one env %s", envValue),
+			Paths:   []string{apis.CurrentField},
 		})
 	}
+	// Client certificates can only be verified if the EventListener terminates TLS
+	switch {
+	case clientCA == nil:
+	case count != 2:
+		errs = errs.Also(&apis.FieldError{
+			Message: "TLS_CLIENT_CA requires the TLS_CERT and TLS_KEY envs to be set",
+			Paths:   []string{apis.CurrentField},
+		})
+	// The client CA is read from the secret of the certificate, which is the only one mounted
+	case clientCA.ValueFrom == nil || clientCA.ValueFrom.SecretKeyRef == nil || clientCA.ValueFrom.SecretKeyRef.Name != certSecret:
+		errs = errs.Also((&apis.FieldError{
+			Message: "TLS_CLIENT_CA must be a secretKeyRef to a key of the secret of TLS_CERT",
+			Paths:   []string{"valueFrom.secretKeyRef"},
+		}).ViaIndex(clientCAIndex))
+	}
 	return errs
 }
 
@@ -304,9 +393,9 @@ func (t *EventListenerTrigger) validate(ctx context.Context) (errs *apis.FieldEr
 
 	// Validate optional Interceptors
 	for i, interceptor := range t.Interceptors {
-		// No continuation if provided interceptor is nil.
 		if interceptor == nil {
-			return errs.Also(apis.ErrInvalidValue(fmt.Sprintf("interceptor '%v' must be a valid value", interceptor), fmt.Sprintf("interceptors[%d]", i)))
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("interceptor '%v' must be a valid value", interceptor), fmt.Sprintf("interceptors[%d]", i)))
+			continue
 		}
 		errs = errs.Also(interceptor.validate(ctx).ViaField(fmt.Sprintf("interceptors[%d]", i)))
 	}
@@ -317,5 +406,19 @@ func (t *EventListenerTrigger) validate(ctx context.Context) (errs *apis.FieldEr
 		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("trigger name '%s' must be a valid label value", t.Name), "name"))
 	}
 
-	return errs
+	return errs.Also(validateFinalizer(t.Finalizer)).Also(validatePromotedExtensions(t.PromotedExtensions)).
+		Also(validateDefaultNamespace(t.DefaultNamespace, t.NamespaceParam)).
+		Also(validateDefaultResourceType(t.DefaultAPIVersion, t.DefaultKind)).
+		Also(validateResourceMetadata(t.ResourceLabels, t.ResourceAnnotations)).
+		Also(validateOverlays(t.Overlays, templateSpec(t.Template))).
+		Also(validateResourceTTL(t.ResourceTTL)).
+		Also(validateClientProfile(ctx, t.ClientProfile))
+}
+
+// templateSpec returns the embedded spec of the template, if any.
+func templateSpec(t *TriggerSpecTemplate) *TriggerTemplateSpec {
+	if t == nil {
+		return nil
+	}
+	return t.Spec
 }
@@ -446,6 +446,56 @@ func Test_EventListenerValidate(t *testing.T) {
 				},
 			},
 		},
+	}, {
+		name: "Valid EventListener with env for TLS connection with client certificates",
+		el: &triggersv1beta1.EventListener{
+			ObjectMeta: myObjectMeta,
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Template: &triggersv1beta1.EventListenerTemplate{
+						Ref: ptr.String("tt"),
+					},
+				}},
+				Resources: triggersv1beta1.Resources{
+					KubernetesResource: &triggersv1beta1.KubernetesResource{
+						WithPodSpec: duckv1.WithPodSpec{
+							Template: duckv1.PodSpecable{
+								Spec: corev1.PodSpec{
+									ServiceAccountName: "k8sresource",
+									Containers: []corev1.Container{{
+										Env: []corev1.EnvVar{{
+											Name: "TLS_CERT",
+											ValueFrom: &corev1.EnvVarSource{
+												SecretKeyRef: &corev1.SecretKeySelector{
+													LocalObjectReference: corev1.LocalObjectReference{Name: "secret-name"},
+													Key:                  "tls.crt",
+												},
+											},
+										}, {
+											Name: "TLS_KEY",
+											ValueFrom: &corev1.EnvVarSource{
+												SecretKeyRef: &corev1.SecretKeySelector{
+													LocalObjectReference: corev1.LocalObjectReference{Name: "secret-name"},
+													Key:                  "tls.key",
+												},
+											},
+										}, {
+											Name: "TLS_CLIENT_CA",
+											ValueFrom: &corev1.EnvVarSource{
+												SecretKeyRef: &corev1.SecretKeySelector{
+													LocalObjectReference: corev1.LocalObjectReference{Name: "secret-name"},
+													Key:                  "ca.crt",
+												},
+											},
+										}},
+									}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 	}, {
 		name: "Valid EventListener with custom resources",
 		el: &triggersv1beta1.EventListener{
@@ -525,7 +575,64 @@ func Test_EventListenerValidate(t *testing.T) {
 					},
 				}},
 			},
-		}}}
+		},
+	}, {
+		name: "valid resource kinds",
+		el: &triggersv1beta1.EventListener{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "name",
+				Namespace: "namespace",
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Template: &triggersv1beta1.EventListenerTemplate{
+						Ref: ptr.String("tt"),
+					},
+				}},
+				ResourceKinds: &triggersv1beta1.ResourceKindPolicy{
+					Allowed: []triggersv1beta1.ResourceKind{{Group: "tekton.dev", Kind: "*"}, {Version: "v1", Kind: "ConfigMap"}},
+					Denied:  []triggersv1beta1.ResourceKind{{Group: "tekton.dev", Version: "v1alpha1", Kind: "PipelineResource"}},
+				},
+			},
+		},
+	}, {
+		name: "valid create strategies",
+		el: &triggersv1beta1.EventListener{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "name",
+				Namespace: "namespace",
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Template: &triggersv1beta1.EventListenerTemplate{
+						Ref: ptr.String("tt"),
+					},
+				}},
+				CreateStrategies: []triggersv1beta1.ResourceCreateStrategy{
+					{ResourceKind: triggersv1beta1.ResourceKind{Version: "v1", Kind: "ConfigMap"}, Strategy: triggersv1beta1.CreateStrategyApply},
+					{ResourceKind: triggersv1beta1.ResourceKind{Group: "example.dev", Kind: "*"}, Strategy: triggersv1beta1.CreateStrategyPatch},
+					{ResourceKind: triggersv1beta1.ResourceKind{Group: "tekton.dev", Kind: "Pipeline"}, Strategy: triggersv1beta1.CreateStrategyCreateOrUpdate},
+					{ResourceKind: triggersv1beta1.ResourceKind{Group: "tekton.dev", Kind: "PipelineRun"}, Strategy: triggersv1beta1.CreateStrategyCreate},
+				},
+			},
+		},
+	}, {
+		name: "exact JSON numbers",
+		el: &triggersv1beta1.EventListener{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "name",
+				Namespace: "namespace",
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Template: &triggersv1beta1.EventListenerTemplate{
+						Ref: ptr.String("tt"),
+					},
+				}},
+				JSONNumbers: triggersv1beta1.JSONNumbersExact,
+			},
+		},
+	}}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -600,7 +707,7 @@ func TestEventListenerValidate_error(t *testing.T) {
 				}},
 			},
 		},
-		wantErr: apis.ErrInvalidValue(`tekton.dev/payload-validation annotation must have value 'true' or 'false'`, "metadata.annotations"),
+		wantErr: apis.ErrInvalidValue(`tekton.dev/payload-validation annotation must have value 'true' or 'false'`, "metadata.annotations[tekton.dev/payload-validation]"),
 	}, {
 		name: "TriggerBinding with no ref or spec or name",
 		el: &triggersv1beta1.EventListener{
@@ -974,6 +1081,112 @@ func TestEventListenerValidate_error(t *testing.T) {
 			},
 		},
 		wantErr: apis.ErrInvalidValue(`trigger name '1234567890123456789012345678901234567890123456789012345678901234' must be a valid label value`, "spec.triggers[0].name"),
+	}, {
+		name: "Trigger finalizer without domain",
+		el: &triggersv1beta1.EventListener{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "name",
+				Namespace: "namespace",
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Template:  &triggersv1beta1.EventListenerTemplate{Ref: ptr.String("tt"), APIVersion: "v1beta1"},
+					Finalizer: "cleanup",
+				}},
+			},
+		},
+		wantErr: apis.ErrInvalidValue(`finalizer "cleanup" must be domain-qualified, e.g. example.com/cleanup`, "spec.triggers[0].finalizer"),
+	}, {
+		name: "Trigger promoted extension colliding with body",
+		el: &triggersv1beta1.EventListener{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "name",
+				Namespace: "namespace",
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Template:           &triggersv1beta1.EventListenerTemplate{Ref: ptr.String("tt"), APIVersion: "v1beta1"},
+					PromotedExtensions: []string{"sha", "body"},
+				}},
+			},
+		},
+		wantErr: apis.ErrInvalidValue(`promoted extension "body" collides with the body field of events`, "spec.triggers[0].promotedExtensions[1]"),
+	}, {
+		name: "Trigger with invalid default namespace",
+		el: &triggersv1beta1.EventListener{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "name",
+				Namespace: "namespace",
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Template:         &triggersv1beta1.EventListenerTemplate{Ref: ptr.String("tt"), APIVersion: "v1beta1"},
+					DefaultNamespace: "team.a",
+				}},
+			},
+		},
+		wantErr: apis.ErrInvalidValue(`default namespace "team.a" must be a valid namespace name: a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`, "spec.triggers[0].defaultNamespace"),
+	}, {
+		name: "Trigger with a default resource type that is not allowed",
+		el: &triggersv1beta1.EventListener{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "name",
+				Namespace: "namespace",
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Template:          &triggersv1beta1.EventListenerTemplate{Ref: ptr.String("tt"), APIVersion: "v1beta1"},
+					DefaultAPIVersion: "v1",
+					DefaultKind:       "Pod",
+				}},
+			},
+		},
+		wantErr: apis.ErrInvalidValue("default apiVersion v1 and kind Pod are not an allowed resource type", "spec.triggers[0].defaultKind"),
+	}, {
+		name: "Trigger with reserved resource label key",
+		el: &triggersv1beta1.EventListener{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "name",
+				Namespace: "namespace",
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Template:       &triggersv1beta1.EventListenerTemplate{Ref: ptr.String("tt"), APIVersion: "v1beta1"},
+					ResourceLabels: map[string]string{"triggers.tekton.dev/eventid": "1234"},
+				}},
+			},
+		},
+		wantErr: apis.ErrInvalidKeyName("triggers.tekton.dev/eventid", "spec.triggers[0].resourceLabels", "the triggers.tekton.dev domain is reserved"),
+	}, {
+		name: "Trigger with invalid overlay path",
+		el: &triggersv1beta1.EventListener{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "name",
+				Namespace: "namespace",
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Template: &triggersv1beta1.EventListenerTemplate{Ref: ptr.String("tt"), APIVersion: "v1beta1"},
+					Overlays: []triggersv1beta1.TriggerOverlay{{Path: "spec/timeout", Param: "timeout"}},
+				}},
+			},
+		},
+		wantErr: apis.ErrInvalidValue(`overlay path "spec/timeout" must be a JSON pointer: must start with /`, "spec.triggers[0].overlays[0].path"),
+	}, {
+		name: "Trigger with a resource TTL without a duration or a param",
+		el: &triggersv1beta1.EventListener{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "name",
+				Namespace: "namespace",
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Template:    &triggersv1beta1.EventListenerTemplate{Ref: ptr.String("tt"), APIVersion: "v1beta1"},
+					ResourceTTL: &triggersv1beta1.ResourceTTL{},
+				}},
+			},
+		},
+		wantErr: apis.ErrMissingOneOf("spec.triggers[0].resourceTTL.after", "spec.triggers[0].resourceTTL.param"),
 	}, {
 		name: "user specify invalid replicas",
 		el: &triggersv1beta1.EventListener{
@@ -1137,7 +1350,146 @@ func TestEventListenerValidate_error(t *testing.T) {
 				}},
 			},
 		},
-		wantErr: apis.ErrGeneric("Expected env's are TLS_CERT and TLS_KEY, but got only one env TLS_CERT"),
+		wantErr: apis.ErrGeneric("Expected env's are TLS_CERT and TLS_KEY, but got only one env TLS_CERT", "spec.resources.kubernetesResource.spec.template.spec.containers[0].env"),
+	}, {
+		name: "user specifies a client CA without TLS",
+		el: &triggersv1beta1.EventListener{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "name",
+				Namespace: "namespace",
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Resources: triggersv1beta1.Resources{
+					KubernetesResource: &triggersv1beta1.KubernetesResource{
+						WithPodSpec: duckv1.WithPodSpec{
+							Template: duckv1.PodSpecable{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{{
+										Env: []corev1.EnvVar{{
+											Name: "TLS_CLIENT_CA",
+											ValueFrom: &corev1.EnvVarSource{
+												SecretKeyRef: &corev1.SecretKeySelector{
+													LocalObjectReference: corev1.LocalObjectReference{
+														Name: "secret-name",
+													},
+													Key: "ca.crt",
+												},
+											},
+										}},
+									}},
+								},
+							},
+						},
+					},
+				},
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Template: &triggersv1beta1.EventListenerTemplate{
+						Ref: ptr.String("tt"),
+					},
+				}},
+			},
+		},
+		wantErr: apis.ErrGeneric("TLS_CLIENT_CA requires the TLS_CERT and TLS_KEY envs to be set", "spec.resources.kubernetesResource.spec.template.spec.containers[0].env"),
+	}, {
+		name: "user specifies a client CA without a secretKeyRef",
+		el: &triggersv1beta1.EventListener{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "name",
+				Namespace: "namespace",
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Resources: triggersv1beta1.Resources{
+					KubernetesResource: &triggersv1beta1.KubernetesResource{
+						WithPodSpec: duckv1.WithPodSpec{
+							Template: duckv1.PodSpecable{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{{
+										Env: []corev1.EnvVar{{
+											Name: "TLS_CERT",
+											ValueFrom: &corev1.EnvVarSource{
+												SecretKeyRef: &corev1.SecretKeySelector{
+													LocalObjectReference: corev1.LocalObjectReference{Name: "secret-name"},
+													Key:                  "tls.crt",
+												},
+											},
+										}, {
+											Name: "TLS_KEY",
+											ValueFrom: &corev1.EnvVarSource{
+												SecretKeyRef: &corev1.SecretKeySelector{
+													LocalObjectReference: corev1.LocalObjectReference{Name: "secret-name"},
+													Key:                  "tls.key",
+												},
+											},
+										}, {
+											Name: "TLS_CLIENT_CA",
+										}},
+									}},
+								},
+							},
+						},
+					},
+				},
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Template: &triggersv1beta1.EventListenerTemplate{
+						Ref: ptr.String("tt"),
+					},
+				}},
+			},
+		},
+		wantErr: apis.ErrGeneric("TLS_CLIENT_CA must be a secretKeyRef to a key of the secret of TLS_CERT", "spec.resources.kubernetesResource.spec.template.spec.containers[0].env[2].valueFrom.secretKeyRef"),
+	}, {
+		name: "user specifies a client CA in another secret",
+		el: &triggersv1beta1.EventListener{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "name",
+				Namespace: "namespace",
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Resources: triggersv1beta1.Resources{
+					KubernetesResource: &triggersv1beta1.KubernetesResource{
+						WithPodSpec: duckv1.WithPodSpec{
+							Template: duckv1.PodSpecable{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{{
+										Env: []corev1.EnvVar{{
+											Name: "TLS_CERT",
+											ValueFrom: &corev1.EnvVarSource{
+												SecretKeyRef: &corev1.SecretKeySelector{
+													LocalObjectReference: corev1.LocalObjectReference{Name: "secret-name"},
+													Key:                  "tls.crt",
+												},
+											},
+										}, {
+											Name: "TLS_KEY",
+											ValueFrom: &corev1.EnvVarSource{
+												SecretKeyRef: &corev1.SecretKeySelector{
+													LocalObjectReference: corev1.LocalObjectReference{Name: "secret-name"},
+													Key:                  "tls.key",
+												},
+											},
+										}, {
+											Name: "TLS_CLIENT_CA",
+											ValueFrom: &corev1.EnvVarSource{
+												SecretKeyRef: &corev1.SecretKeySelector{
+													LocalObjectReference: corev1.LocalObjectReference{Name: "other-secret"},
+													Key:                  "ca.crt",
+												},
+											},
+										}},
+									}},
+								},
+							},
+						},
+					},
+				},
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Template: &triggersv1beta1.EventListenerTemplate{
+						Ref: ptr.String("tt"),
+					},
+				}},
+			},
+		},
+		wantErr: apis.ErrGeneric("TLS_CLIENT_CA must be a secretKeyRef to a key of the secret of TLS_CERT", "spec.resources.kubernetesResource.spec.template.spec.containers[0].env[2].valueFrom.secretKeyRef"),
 	}, {
 		name: "user specify both kubernetes and custom resources",
 		el: &triggersv1beta1.EventListener{
@@ -1163,7 +1515,12 @@ func TestEventListenerValidate_error(t *testing.T) {
 				},
 			},
 		},
-		wantErr: apis.ErrMultipleOneOf("spec.resources.customResource", "spec.resources.kubernetesResource"),
+		wantErr: func() *apis.FieldError {
+			var errs *apis.FieldError
+			errs = errs.Also(apis.ErrMultipleOneOf("spec.resources.customResource", "spec.resources.kubernetesResource"))
+			errs = errs.Also(apis.ErrMissingOneOf("spec.triggers[0].template", "spec.triggers[0].triggerRef"))
+			return errs
+		}(),
 	}, {
 		name: "user specify multiple containers, unsupported podspec and container field in custom resources",
 		el: &triggersv1beta1.EventListener{
@@ -1299,6 +1656,118 @@ func TestEventListenerValidate_error(t *testing.T) {
 				Message: "invalid value: interceptor '<nil>' must be a valid value",
 				Paths:   []string{"spec.triggers[0].interceptors[1]"},
 			},
+		}, {
+			name: "errors after a nil interceptor are reported",
+			el: &triggersv1beta1.EventListener{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "name",
+					Namespace: "namespace",
+				},
+				Spec: triggersv1beta1.EventListenerSpec{
+					Triggers: []triggersv1beta1.EventListenerTrigger{{
+						Template: &triggersv1beta1.EventListenerTemplate{
+							Ref: ptr.String("tt"),
+						},
+						Name: "test",
+						Interceptors: []*triggersv1beta1.EventInterceptor{
+							nil,
+							{},
+						},
+					}},
+				},
+			},
+			wantErr: func() *apis.FieldError {
+				var errs *apis.FieldError
+				errs = errs.Also(apis.ErrInvalidValue("interceptor '<nil>' must be a valid value", "spec.triggers[0].interceptors[0]"))
+				errs = errs.Also(apis.ErrMissingField("spec.triggers[0].interceptors[1].interceptor"))
+				return errs
+			}(),
+		}, {
+			name: "empty resource kinds",
+			el: &triggersv1beta1.EventListener{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "name",
+					Namespace: "namespace",
+				},
+				Spec: triggersv1beta1.EventListenerSpec{
+					Triggers: []triggersv1beta1.EventListenerTrigger{{
+						Template: &triggersv1beta1.EventListenerTemplate{
+							Ref: ptr.String("tt"),
+						},
+					}},
+					ResourceKinds: &triggersv1beta1.ResourceKindPolicy{},
+				},
+			},
+			wantErr: apis.ErrMissingOneOf("spec.resourceKinds.allowed", "spec.resourceKinds.denied"),
+		}, {
+			name: "invalid resource kinds",
+			el: &triggersv1beta1.EventListener{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "name",
+					Namespace: "namespace",
+				},
+				Spec: triggersv1beta1.EventListenerSpec{
+					Triggers: []triggersv1beta1.EventListenerTrigger{{
+						Template: &triggersv1beta1.EventListenerTemplate{
+							Ref: ptr.String("tt"),
+						},
+					}},
+					ResourceKinds: &triggersv1beta1.ResourceKindPolicy{
+						Allowed: []triggersv1beta1.ResourceKind{{Group: "tekton.dev"}},
+						Denied:  []triggersv1beta1.ResourceKind{{Group: "Tekton_Dev", Version: "v1", Kind: "Pipeline-Run"}},
+					},
+				},
+			},
+			wantErr: func() *apis.FieldError {
+				var errs *apis.FieldError
+				errs = errs.Also(apis.ErrMissingField("spec.resourceKinds.allowed[0].kind"))
+				errs = errs.Also(apis.ErrInvalidValue(`group "Tekton_Dev" must be an API group: a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`, "spec.resourceKinds.denied[0].group"))
+				errs = errs.Also(apis.ErrInvalidValue(`kind "Pipeline-Run" must be a kind, e.g. PipelineRun, or *`, "spec.resourceKinds.denied[0].kind"))
+				return errs
+			}(),
+		}, {
+			name: "invalid create strategies",
+			el: &triggersv1beta1.EventListener{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "name",
+					Namespace: "namespace",
+				},
+				Spec: triggersv1beta1.EventListenerSpec{
+					Triggers: []triggersv1beta1.EventListenerTrigger{{
+						Template: &triggersv1beta1.EventListenerTemplate{
+							Ref: ptr.String("tt"),
+						},
+					}},
+					CreateStrategies: []triggersv1beta1.ResourceCreateStrategy{
+						{ResourceKind: triggersv1beta1.ResourceKind{Version: "v1", Kind: "ConfigMap"}},
+						{ResourceKind: triggersv1beta1.ResourceKind{Group: "tekton.dev"}, Strategy: "replace"},
+					},
+				},
+			},
+			wantErr: func() *apis.FieldError {
+				var errs *apis.FieldError
+				errs = errs.Also(apis.ErrMissingField("spec.createStrategies[0].strategy"))
+				errs = errs.Also(apis.ErrMissingField("spec.createStrategies[1].kind"))
+				errs = errs.Also(apis.ErrInvalidValue(`strategy "replace" must be create, apply, patch or createOrUpdate`, "spec.createStrategies[1].strategy"))
+				return errs
+			}(),
+		}, {
+			name: "invalid JSON numbers",
+			el: &triggersv1beta1.EventListener{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "name",
+					Namespace: "namespace",
+				},
+				Spec: triggersv1beta1.EventListenerSpec{
+					Triggers: []triggersv1beta1.EventListenerTrigger{{
+						Template: &triggersv1beta1.EventListenerTemplate{
+							Ref: ptr.String("tt"),
+						},
+					}},
+					JSONNumbers: "string",
+				},
+			},
+			wantErr: apis.ErrInvalidValue(`jsonNumbers "string" must be float or exact`, "spec.jsonNumbers"),
 		}}
 
 	for _, tc := range tests {
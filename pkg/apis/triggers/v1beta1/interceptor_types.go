@@ -26,6 +26,12 @@ type InterceptorRequest struct {
 	// string which means that we will lose the spaces any time we marshal this struct.
 	Body string `json:"body,omitempty"`
 
+	// RawBody is the body of the incoming HTTP event as it was sent, if the payload parsers of the
+	// EventListener converted it into Body, e.g. from a form. Interceptors verifying a signature of the
+	// body must verify it over RawBody when it is set.
+	// +optional
+	RawBody string `json:"raw_body,omitempty"`
+
 	// Header are the headers for the incoming HTTP event
 	Header map[string][]string `json:"header,omitempty"`
 
@@ -46,6 +52,29 @@ type TriggerContext struct {
 	EventID string `json:"event_id,omitempty"`
 	// TriggerID is of the form namespace/$ns/triggers/$name
 	TriggerID string `json:"trigger_id,omitempty"`
+	// ClientCert is the verified certificate presented by the sender of the event, if
+	// the EventListener terminates TLS and is configured to verify client certificates
+	ClientCert *ClientCertificate `json:"client_cert,omitempty"`
+	// ClientIP is the IP address of the sender of the event, as seen by the EventListener
+	ClientIP string `json:"client_ip,omitempty"`
+	// JSONNumbers is how the EventListener decodes the numbers of the JSON body and
+	// extensions, so that the interceptors can decode them in the same way
+	JSONNumbers JSONNumbers `json:"json_numbers,omitempty"`
+}
+
+// ClientCertificate contains the identity of a sender that authenticated to the
+// EventListener with a verified client certificate.
+type ClientCertificate struct {
+	// Subject is the distinguished name of the certificate subject e.g. CN=ci-prod,O=Example
+	Subject string `json:"subject,omitempty"`
+	// CommonName is the common name of the certificate subject
+	CommonName string `json:"common_name,omitempty"`
+	// DNSNames are the DNS names in the subject alternative name extension
+	DNSNames []string `json:"dns_names,omitempty"`
+	// URIs are the URIs in the subject alternative name extension
+	URIs []string `json:"uris,omitempty"`
+	// EmailAddresses are the email addresses in the subject alternative name extension
+	EmailAddresses []string `json:"email_addresses,omitempty"`
 }
 
 // Do not generate Deepcopy(). See #827
@@ -57,6 +86,10 @@ type InterceptorResponse struct {
 	Continue bool `json:"continue"` // Don't add omitempty -- it  will remove the continue field when the value is false.
 	// Status is an Error status containing details on any interceptor processing errors
 	Status Status `json:"status"`
+	// ResponseHeaders are headers that a synchronous EventListener adds to its response to
+	// the sender of the event, e.g. rate limit hints. Their names must start with X- and
+	// must not start with X-Tekton-
+	ResponseHeaders map[string]string `json:"responseHeaders,omitempty"`
 }
 
 type Status struct {
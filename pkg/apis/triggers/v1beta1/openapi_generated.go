@@ -37,6 +37,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ClusterTriggerBindingList":    schema_pkg_apis_triggers_v1beta1_ClusterTriggerBindingList(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.CustomResource":               schema_pkg_apis_triggers_v1beta1_CustomResource(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.EventListener":                schema_pkg_apis_triggers_v1beta1_EventListener(ref),
+		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.EventListenerActivity":        schema_pkg_apis_triggers_v1beta1_EventListenerActivity(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.EventListenerConfig":          schema_pkg_apis_triggers_v1beta1_EventListenerConfig(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.EventListenerList":            schema_pkg_apis_triggers_v1beta1_EventListenerList(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.EventListenerSpec":            schema_pkg_apis_triggers_v1beta1_EventListenerSpec(ref),
@@ -46,6 +47,10 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.EventListenerTriggerSelector": schema_pkg_apis_triggers_v1beta1_EventListenerTriggerSelector(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.GitHubInterceptor":            schema_pkg_apis_triggers_v1beta1_GitHubInterceptor(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.GitLabInterceptor":            schema_pkg_apis_triggers_v1beta1_GitLabInterceptor(ref),
+		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.GithubAddChangedFiles":        schema_pkg_apis_triggers_v1beta1_GithubAddChangedFiles(ref),
+		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.GithubExpandCommits":          schema_pkg_apis_triggers_v1beta1_GithubExpandCommits(ref),
+		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.GithubVerifyCommits":          schema_pkg_apis_triggers_v1beta1_GithubVerifyCommits(ref),
+		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.GoTemplateRef":                schema_pkg_apis_triggers_v1beta1_GoTemplateRef(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.InterceptorParams":            schema_pkg_apis_triggers_v1beta1_InterceptorParams(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.InterceptorRef":               schema_pkg_apis_triggers_v1beta1_InterceptorRef(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.InterceptorRequest":           schema_pkg_apis_triggers_v1beta1_InterceptorRequest(ref),
@@ -54,6 +59,11 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.NamespaceSelector":            schema_pkg_apis_triggers_v1beta1_NamespaceSelector(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.Param":                        schema_pkg_apis_triggers_v1beta1_Param(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ParamSpec":                    schema_pkg_apis_triggers_v1beta1_ParamSpec(ref),
+		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ResourceCreateStrategy":       schema_pkg_apis_triggers_v1beta1_ResourceCreateStrategy(ref),
+		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ResourceKind":                 schema_pkg_apis_triggers_v1beta1_ResourceKind(ref),
+		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ResourceKindPolicy":           schema_pkg_apis_triggers_v1beta1_ResourceKindPolicy(ref),
+		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ResourceTTL":                  schema_pkg_apis_triggers_v1beta1_ResourceTTL(ref),
+		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ResourceTemplateSelection":    schema_pkg_apis_triggers_v1beta1_ResourceTemplateSelection(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.Resources":                    schema_pkg_apis_triggers_v1beta1_Resources(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.SecretRef":                    schema_pkg_apis_triggers_v1beta1_SecretRef(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.Status":                       schema_pkg_apis_triggers_v1beta1_Status(ref),
@@ -66,12 +76,15 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerContext":               schema_pkg_apis_triggers_v1beta1_TriggerContext(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerInterceptor":           schema_pkg_apis_triggers_v1beta1_TriggerInterceptor(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerList":                  schema_pkg_apis_triggers_v1beta1_TriggerList(ref),
+		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerOverlay":               schema_pkg_apis_triggers_v1beta1_TriggerOverlay(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerResourceTemplate":      schema_pkg_apis_triggers_v1beta1_TriggerResourceTemplate(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerSpec":                  schema_pkg_apis_triggers_v1beta1_TriggerSpec(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerSpecBinding":           schema_pkg_apis_triggers_v1beta1_TriggerSpecBinding(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerSpecTemplate":          schema_pkg_apis_triggers_v1beta1_TriggerSpecTemplate(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerTemplate":              schema_pkg_apis_triggers_v1beta1_TriggerTemplate(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerTemplateList":          schema_pkg_apis_triggers_v1beta1_TriggerTemplateList(ref),
+		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerTemplateRoute":         schema_pkg_apis_triggers_v1beta1_TriggerTemplateRoute(ref),
+		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerTemplateRouting":       schema_pkg_apis_triggers_v1beta1_TriggerTemplateRouting(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerTemplateSpec":          schema_pkg_apis_triggers_v1beta1_TriggerTemplateSpec(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerTemplateStatus":        schema_pkg_apis_triggers_v1beta1_TriggerTemplateStatus(ref),
 		"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.WebhookInterceptor":           schema_pkg_apis_triggers_v1beta1_WebhookInterceptor(ref),
@@ -90,6 +103,25 @@ func schema_pkg_apis_triggers_v1beta1_BitbucketInterceptor(ref common.ReferenceC
 							Ref: ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.SecretRef"),
 						},
 					},
+					"additionalSecretRefs": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "AdditionalSecretRefs are secrets that are accepted in addition to SecretRef, so that the webhook secret can be rotated without rejecting events.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.SecretRef"),
+									},
+								},
+							},
+						},
+					},
 					"eventTypes": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
@@ -336,6 +368,60 @@ func schema_pkg_apis_triggers_v1beta1_EventListener(ref common.ReferenceCallback
 	}
 }
 
+func schema_pkg_apis_triggers_v1beta1_EventListenerActivity(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "EventListenerActivity records a resource created by the EventListener",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"time": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time is when the resource was created",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"trigger": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Trigger is the name of the Trigger that created the resource",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"eventID": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EventID is the ID of the event that fired the Trigger",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is the kind of the created resource",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name is the name of the created resource",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"time", "trigger", "eventID", "kind", "name"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema_pkg_apis_triggers_v1beta1_EventListenerConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -480,11 +566,43 @@ func schema_pkg_apis_triggers_v1beta1_EventListenerSpec(ref common.ReferenceCall
 							Format: "",
 						},
 					},
+					"resourceKinds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ResourceKinds optionally restricts the kinds of resources the Triggers of the EventListener can create, independently of the permissions of their service accounts",
+							Ref:         ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ResourceKindPolicy"),
+						},
+					},
+					"createStrategies": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "CreateStrategies optionally select how the resources of some kinds are created, e.g. applied rather than created. The first strategy matching the kind of a resource is used, and the resources of the other kinds are created",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ResourceCreateStrategy"),
+									},
+								},
+							},
+						},
+					},
+					"jsonNumbers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "JSONNumbers selects how the numbers of the JSON bodies and extensions of the events are decoded: float, the default, or exact",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.EventListenerTrigger", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.EventListenerTriggerGroup", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.NamespaceSelector", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.Resources", "k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
+			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.EventListenerTrigger", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.EventListenerTriggerGroup", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.NamespaceSelector", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ResourceCreateStrategy", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ResourceKindPolicy", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.Resources", "k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
 	}
 }
 
@@ -550,12 +668,31 @@ func schema_pkg_apis_triggers_v1beta1_EventListenerStatus(ref common.ReferenceCa
 							Ref:         ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.EventListenerConfig"),
 						},
 					},
+					"recentActivity": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "RecentActivity lists the resources most recently created by the EventListener, newest first",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.EventListenerActivity"),
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"configuration"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.EventListenerConfig", "knative.dev/pkg/apis.Condition", "knative.dev/pkg/apis/duck/v1beta1.Addressable"},
+			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.EventListenerActivity", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.EventListenerConfig", "knative.dev/pkg/apis.Condition", "knative.dev/pkg/apis/duck/v1beta1.Addressable"},
 	}
 }
 
@@ -624,11 +761,130 @@ func schema_pkg_apis_triggers_v1beta1_EventListenerTrigger(ref common.ReferenceC
 							Format:      "",
 						},
 					},
+					"finalizer": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Finalizer is optionally added to the resources created by the trigger, in addition to the finalizers specified in their templates, so that a controller can clean up after them when they are deleted",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"promotedExtensions": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "PromotedExtensions are the keys of the extensions added by the interceptors that bindings can also read as top-level fields of the event, e.g. $(foo) for $(extensions.foo)",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"defaultNamespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DefaultNamespace is the namespace of the resources created by the trigger whose templates don't specify one, instead of the namespace of the trigger",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"namespaceParam": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NamespaceParam is the name of a param whose value is the namespace of the resources created by the trigger whose templates don't specify one, if DefaultNamespace is not set and the value is not empty",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"defaultAPIVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DefaultAPIVersion is the apiVersion of the resources created by the trigger whose templates specify neither an apiVersion nor a kind",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"defaultKind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DefaultKind is the kind of the resources created by the trigger whose templates specify neither an apiVersion nor a kind",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resourceLabels": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ResourceLabels are optionally added to the resources created by the trigger, unless their templates specify labels with the same keys",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"resourceAnnotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ResourceAnnotations are optionally added to the resources created by the trigger, unless their templates specify annotations with the same keys",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"overlays": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Overlays set fields of the resources created by the trigger to the typed values of params, after the params are replaced in their templates",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerOverlay"),
+									},
+								},
+							},
+						},
+					},
+					"resourceTTL": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ResourceTTL optionally annotates the resources created by the trigger with their time to live after completion, for a cleanup controller to delete them",
+							Ref:         ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ResourceTTL"),
+						},
+					},
+					"clientProfile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ClientProfile optionally names the client profile, defined in the client profiles ConfigMap of Triggers, of the clients creating the resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerInterceptor", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerSpecBinding", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerSpecTemplate"},
+			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ResourceTTL", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerInterceptor", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerOverlay", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerSpecBinding", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerSpecTemplate"},
 	}
 }
 
@@ -716,6 +972,25 @@ func schema_pkg_apis_triggers_v1beta1_GitHubInterceptor(ref common.ReferenceCall
 							Ref: ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.SecretRef"),
 						},
 					},
+					"additionalSecretRefs": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "AdditionalSecretRefs are secrets that are accepted in addition to SecretRef, so that the webhook secret can be rotated without rejecting events.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.SecretRef"),
+									},
+								},
+							},
+						},
+					},
 					"eventTypes": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
@@ -735,11 +1010,39 @@ func schema_pkg_apis_triggers_v1beta1_GitHubInterceptor(ref common.ReferenceCall
 							},
 						},
 					},
+					"enterpriseHost": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EnterpriseHost is the GitHub Enterprise host whose API is called, e.g. github.example.com. Events whose X-GitHub-Enterprise-Host header names another host are rejected. Defaults to the public GitHub API.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"addChangedFiles": {
+						SchemaProps: spec.SchemaProps{
+							Description: "AddChangedFiles fetches the files changed by pull_request and push events from the GitHub API and adds them to the changed_files extension.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.GithubAddChangedFiles"),
+						},
+					},
+					"verifyCommits": {
+						SchemaProps: spec.SchemaProps{
+							Description: "VerifyCommits fetches the head commit of pull_request and push events from the GitHub API and adds its author, committer and signature verification status to the commit extension.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.GithubVerifyCommits"),
+						},
+					},
+					"expandCommits": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpandCommits lists all the commits of pull_request and push events, following the pages of the GitHub API, and adds them to the commits extension.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.GithubExpandCommits"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.SecretRef"},
+			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.GithubAddChangedFiles", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.GithubExpandCommits", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.GithubVerifyCommits", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.SecretRef"},
 	}
 }
 
@@ -755,6 +1058,25 @@ func schema_pkg_apis_triggers_v1beta1_GitLabInterceptor(ref common.ReferenceCall
 							Ref: ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.SecretRef"),
 						},
 					},
+					"additionalSecretRefs": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "AdditionalSecretRefs are secrets that are accepted in addition to SecretRef, so that the webhook secret can be rotated without rejecting events.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.SecretRef"),
+									},
+								},
+							},
+						},
+					},
 					"eventTypes": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
@@ -782,32 +1104,171 @@ func schema_pkg_apis_triggers_v1beta1_GitLabInterceptor(ref common.ReferenceCall
 	}
 }
 
-func schema_pkg_apis_triggers_v1beta1_InterceptorParams(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_triggers_v1beta1_GithubAddChangedFiles(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "InterceptorParams defines a key-value pair that can be passed on an interceptor",
+				Description: "GithubAddChangedFiles configures the retrieval of the files changed by an event.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"name": {
+					"enabled": {
 						SchemaProps: spec.SchemaProps{
-							Default: "",
-							Type:    []string{"string"},
-							Format:  "",
+							Type:   []string{"boolean"},
+							Format: "",
 						},
 					},
-					"value": {
+					"personalAccessToken": {
 						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref("k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1.JSON"),
+							Description: "PersonalAccessToken is the token used to call the GitHub API, required for private repositories.",
+							Ref:         ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.SecretRef"),
 						},
 					},
 				},
-				Required: []string{"name", "value"},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1.JSON"},
+			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.SecretRef"},
+	}
+}
+
+func schema_pkg_apis_triggers_v1beta1_GithubExpandCommits(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GithubExpandCommits configures the listing of all the commits of an event.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enabled": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"personalAccessToken": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PersonalAccessToken is the token used to call the GitHub API, required for private repositories.",
+							Ref:         ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.SecretRef"),
+						},
+					},
+					"maxCommits": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxCommits bounds the number of commits listed. Defaults to 250.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"maxRateLimitWait": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxRateLimitWait is the longest the interceptor waits for the rate limit of the GitHub API to reset before retrying a page, as a duration e.g. \"30s\". Defaults to 10s.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.SecretRef"},
+	}
+}
+
+func schema_pkg_apis_triggers_v1beta1_GithubVerifyCommits(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GithubVerifyCommits configures the verification of the signature of the head commit of an event.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enabled": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"personalAccessToken": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PersonalAccessToken is the token used to call the GitHub API, required for private repositories.",
+							Ref:         ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.SecretRef"),
+						},
+					},
+					"requireVerified": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RequireVerified rejects the events whose head commit doesn't have a signature verified by GitHub.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"timeout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timeout bounds the call to the GitHub API, as a duration e.g. \"5s\". Defaults to 10s.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.SecretRef"},
+	}
+}
+
+func schema_pkg_apis_triggers_v1beta1_GoTemplateRef(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GoTemplateRef references a Go template in a ConfigMap in the namespace of the TriggerTemplate, which renders one or more YAML or JSON documents that each describe a resource to create",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"configMapName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConfigMapName is the name of the ConfigMap",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"key": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Key is the key of the template in the ConfigMap",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"configMapName", "key"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_triggers_v1beta1_InterceptorParams(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "InterceptorParams defines a key-value pair that can be passed on an interceptor",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
+						},
+					},
+					"value": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1.JSON"),
+						},
+					},
+				},
+				Required: []string{"name", "value"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1.JSON"},
 	}
 }
 
@@ -859,6 +1320,13 @@ func schema_pkg_apis_triggers_v1beta1_InterceptorRequest(ref common.ReferenceCal
 							Format:      "",
 						},
 					},
+					"raw_body": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RawBody is the body of the incoming HTTP event as it was sent, if the payload parsers of the EventListener converted it into Body, e.g. from a form. Interceptors verifying a signature of the body must verify it over RawBody when it is set.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"header": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Header are the headers for the incoming HTTP event",
@@ -964,6 +1432,22 @@ func schema_pkg_apis_triggers_v1beta1_InterceptorResponse(ref common.ReferenceCa
 							Ref:         ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.Status"),
 						},
 					},
+					"responseHeaders": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ResponseHeaders are headers that a synchronous EventListener adds to its response to the sender of the event, e.g. rate limit hints. Their names must start with X- and must not start with X-Tekton-",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"continue", "status"},
 			},
@@ -1066,43 +1550,259 @@ func schema_pkg_apis_triggers_v1beta1_Param(ref common.ReferenceCallback) common
 						},
 					},
 				},
-				Required: []string{"name", "value"},
+				Required: []string{"name", "value"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_triggers_v1beta1_ParamSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ParamSpec defines an arbitrary named  input whose value can be supplied by a `Param`.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name declares the name by which a parameter is referenced.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"description": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Description is a user-facing description of the parameter that may be used to populate a UI.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"default": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Default is the value a parameter takes if no input value via a Param is supplied.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type is the type of the parameter, string or array. The values of array parameters are JSON arrays, which collect all the nodes matched by the JSONPath expression of their binding.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_triggers_v1beta1_ResourceCreateStrategy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ResourceCreateStrategy selects the create strategy of the kinds of resources it matches.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"group": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Group is the API group of the kind, empty for the core API group",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"version": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Version is the version of the API group. All versions match if empty",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is the kind, or * for all the kinds of the API group",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"strategy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Strategy is how the resources are created: create, apply, patch or createOrUpdate",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"kind", "strategy"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_triggers_v1beta1_ResourceKind(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ResourceKind matches the kinds of resources of an API group.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"group": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Group is the API group of the kind, empty for the core API group",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"version": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Version is the version of the API group. All versions match if empty",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is the kind, or * for all the kinds of the API group",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"kind"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_triggers_v1beta1_ResourceKindPolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ResourceKindPolicy restricts the kinds of resources an EventListener can create. A kind is allowed if it matches one of Allowed, or Allowed is empty, and it matches none of Denied.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"allowed": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Allowed are the kinds of resources the EventListener can create. All kinds are allowed if empty",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ResourceKind"),
+									},
+								},
+							},
+						},
+					},
+					"denied": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Denied are the kinds of resources the EventListener can't create, even if they are allowed",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ResourceKind"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ResourceKind"},
+	}
+}
+
+func schema_pkg_apis_triggers_v1beta1_ResourceTTL(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ResourceTTL is the time to live of the resources created by a trigger once they complete. It is set as the value of an annotation of the resources, e.g. 24h0m0s, which a cleanup controller reads to delete the resources that have completed for longer. The resources whose templates set ownerReferences don't get the annotation, since they are deleted with their owners.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"after": {
+						SchemaProps: spec.SchemaProps{
+							Description: "After is the time to live of the resources, e.g. 24h",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"param": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Param is the name of a param whose value is the time to live of the resources instead of After, if the value is not empty",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"annotation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Annotation is the key of the annotation, triggers.tekton.dev/ttl-after-completion by default",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
 			},
 		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
 	}
 }
 
-func schema_pkg_apis_triggers_v1beta1_ParamSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_triggers_v1beta1_ResourceTemplateSelection(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "ParamSpec defines an arbitrary named  input whose value can be supplied by a `Param`.",
+				Description: "ResourceTemplateSelection chooses one of the resource templates of a TriggerTemplate per event, e.g. to split the events between two variants of a pipeline",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"name": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Name declares the name by which a parameter is referenced.",
-							Default:     "",
-							Type:        []string{"string"},
-							Format:      "",
+					"weights": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
 						},
-					},
-					"description": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Description is a user-facing description of the parameter that may be used to populate a UI.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Weights are the relative weights of the resource templates, in order: a template is chosen with a probability of its weight divided by the sum of the weights. A template with a weight of 0 is never chosen.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: 0,
+										Type:    []string{"integer"},
+										Format:  "int32",
+									},
+								},
+							},
 						},
 					},
-					"default": {
+					"key": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Default is the value a parameter takes if no input value via a Param is supplied.",
+							Description: "Key is optionally the name of a param whose value chooses the template instead of chance, so that the events with the same value, e.g. the URL of a pull request, always get the same template while the weights are unchanged",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
 				},
-				Required: []string{"name"},
+				Required: []string{"weights"},
 			},
 		},
 	}
@@ -1151,6 +1851,13 @@ func schema_pkg_apis_triggers_v1beta1_SecretRef(ref common.ReferenceCallback) co
 							Format: "",
 						},
 					},
+					"namespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Namespace is the namespace of the secret, if it isn't the namespace of the trigger. The secret must list the namespace of the trigger in its triggers.tekton.dev/allowed-trigger-namespaces annotation.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
@@ -1461,6 +2168,13 @@ func schema_pkg_apis_triggers_v1beta1_TriggerInterceptor(ref common.ReferenceCal
 							},
 						},
 					},
+					"when": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When is a CEL expression evaluated against the event as modified by the previous interceptors. If it evaluates to false, the interceptor is skipped and the event continues down the chain.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"webhook": {
 						SchemaProps: spec.SchemaProps{
 							Description: "WebhookInterceptor refers to an old style webhook interceptor service",
@@ -1525,6 +2239,50 @@ func schema_pkg_apis_triggers_v1beta1_TriggerList(ref common.ReferenceCallback)
 	}
 }
 
+func schema_pkg_apis_triggers_v1beta1_TriggerOverlay(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TriggerOverlay sets the field at a JSON pointer of the resources created by a trigger to the value of a param, converted to a JSON value of the given type.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Path is the JSON pointer of the field, e.g. /spec/timeout. Its parent must exist in the resources.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"param": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Param is the name of the param whose value is set.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type is the type of the value: string, number, boolean, array or object. Defaults to array for array params and to string for the others.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resourceIndex": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ResourceIndex is the index of the created resource to patch, in the order of their templates. Defaults to all of them.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"path", "param"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_triggers_v1beta1_TriggerResourceTemplate(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -1596,12 +2354,131 @@ func schema_pkg_apis_triggers_v1beta1_TriggerSpec(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
+					"finalizer": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Finalizer is optionally added to the resources created by the trigger, in addition to the finalizers specified in their templates, so that a controller can clean up after them when they are deleted",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"promotedExtensions": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "PromotedExtensions are the keys of the extensions added by the interceptors that bindings can also read as top-level fields of the event, e.g. $(foo) for $(extensions.foo)",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"defaultNamespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DefaultNamespace is the namespace of the resources created by the trigger whose templates don't specify one, instead of the namespace of the trigger",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"namespaceParam": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NamespaceParam is the name of a param whose value is the namespace of the resources created by the trigger whose templates don't specify one, if DefaultNamespace is not set and the value is not empty",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"defaultAPIVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DefaultAPIVersion is the apiVersion of the resources created by the trigger whose templates specify neither an apiVersion nor a kind",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"defaultKind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DefaultKind is the kind of the resources created by the trigger whose templates specify neither an apiVersion nor a kind",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resourceLabels": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ResourceLabels are optionally added to the resources created by the trigger, unless their templates specify labels with the same keys",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"resourceAnnotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ResourceAnnotations are optionally added to the resources created by the trigger, unless their templates specify annotations with the same keys",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"overlays": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Overlays set fields of the resources created by the trigger to the typed values of params, after the params are replaced in their templates",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerOverlay"),
+									},
+								},
+							},
+						},
+					},
+					"resourceTTL": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ResourceTTL optionally annotates the resources created by the trigger with their time to live after completion, for a cleanup controller to delete them",
+							Ref:         ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ResourceTTL"),
+						},
+					},
+					"clientProfile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ClientProfile optionally names the client profile, defined in the client profiles ConfigMap of Triggers, of the clients creating the resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"bindings", "template"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerInterceptor", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerSpecBinding", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerSpecTemplate"},
+			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ResourceTTL", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerInterceptor", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerOverlay", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerSpecBinding", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerSpecTemplate"},
 	}
 }
 
@@ -1646,6 +2523,13 @@ func schema_pkg_apis_triggers_v1beta1_TriggerSpecBinding(ref common.ReferenceCal
 							Format:      "",
 						},
 					},
+					"override": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Override allows the params of this binding to replace the params of the same name of the earlier bindings, which is otherwise an error.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
@@ -1675,11 +2559,17 @@ func schema_pkg_apis_triggers_v1beta1_TriggerSpecTemplate(ref common.ReferenceCa
 							Ref: ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerTemplateSpec"),
 						},
 					},
+					"routing": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Routing optionally selects another TriggerTemplate per event from the value of an extension added by the interceptors. Ref or Spec is the template of the events that no route matches",
+							Ref:         ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerTemplateRouting"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerTemplateSpec"},
+			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerTemplateRouting", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerTemplateSpec"},
 	}
 }
 
@@ -1780,6 +2670,79 @@ func schema_pkg_apis_triggers_v1beta1_TriggerTemplateList(ref common.ReferenceCa
 	}
 }
 
+func schema_pkg_apis_triggers_v1beta1_TriggerTemplateRoute(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TriggerTemplateRoute is the TriggerTemplate of the events with a value of the routing extension.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"value": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Value is the value of the extension. Numbers and booleans match their string form, e.g. 1 or true",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"ref": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Ref is the name of the TriggerTemplate, in the namespace of the trigger",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"value", "ref"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_triggers_v1beta1_TriggerTemplateRouting(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TriggerTemplateRouting selects the TriggerTemplate of a trigger per event, so that one trigger, whose interceptors classify the events once, creates different resources for each class of events.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"extension": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Extension is the key of the extension added by the interceptors whose value selects the route, e.g. type for $(extensions.type)",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"routes": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Routes are the TriggerTemplates of the values of the extension",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerTemplateRoute"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"extension", "routes"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerTemplateRoute"},
+	}
+}
+
 func schema_pkg_apis_triggers_v1beta1_TriggerTemplateSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -1823,11 +2786,36 @@ func schema_pkg_apis_triggers_v1beta1_TriggerTemplateSpec(ref common.ReferenceCa
 							},
 						},
 					},
+					"selection": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Selection optionally creates the resources of a single resource template per event, chosen by weight, instead of the resources of all of them",
+							Ref:         ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ResourceTemplateSelection"),
+						},
+					},
+					"goTemplates": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "GoTemplates are Go templates held in ConfigMaps, such as pipeline definitions maintained outside of Triggers, that are rendered with the params and the event into more resource templates, created after the resources of ResourceTemplates",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.GoTemplateRef"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ParamSpec", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerResourceTemplate"},
+			"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.GoTemplateRef", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ParamSpec", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.ResourceTemplateSelection", "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1.TriggerResourceTemplate"},
 	}
 }
 
@@ -12,8 +12,24 @@ type ParamSpec struct {
 	// Default is the value a parameter takes if no input value via a Param is supplied.
 	// +optional
 	Default *string `json:"default,omitempty"`
+	// Type is the type of the parameter, string or array. The values of array
+	// parameters are JSON arrays, which collect all the nodes matched by the
+	// JSONPath expression of their binding.
+	// +optional
+	Type ParamType `json:"type,omitempty"`
 }
 
+// ParamType is the type of a ParamSpec.
+type ParamType string
+
+const (
+	// ParamTypeString is the type of parameters with a string value. It is the
+	// default type.
+	ParamTypeString ParamType = "string"
+	// ParamTypeArray is the type of parameters with a JSON array value.
+	ParamTypeArray ParamType = "array"
+)
+
 // Param defines a string value to be used for a ParamSpec with the same name.
 type Param struct {
 	Name  string `json:"name"`
@@ -19,6 +19,7 @@ package v1beta1
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/tektoncd/pipeline/pkg/apis/validate"
@@ -57,6 +58,9 @@ func validateParams(params []Param) *apis.FieldError {
 		if errs != nil {
 			return errs
 		}
+		if errs := validateContextReferences(param.Value).ViaField(fmt.Sprintf("[%d].value", i)); errs != nil {
+			return errs
+		}
 	}
 	return nil
 }
@@ -96,3 +100,23 @@ func validateParamValue(in string) *apis.FieldError {
 	return nil
 
 }
+
+// contextFields are the fields of the context of events, which bindings read as e.g.
+// $(context.eventListener.name). The context is reserved for these fields.
+var contextFields = sets.NewString("eventID", "eventListener", "eventListener.name", "eventListener.namespace", "eventListener.uid")
+
+// contextReferenceRegex matches the references to the context of events in binding values, capturing
+// the path of the referenced field up to the end of the expression or its first transform.
+var contextReferenceRegex = regexp.MustCompile(`\$\(\s*context\b([^)|]*)`)
+
+// validateContextReferences checks that the binding value only references known fields of the
+// context of events.
+func validateContextReferences(in string) (errs *apis.FieldError) {
+	for _, m := range contextReferenceRegex.FindAllStringSubmatch(in, -1) {
+		field := strings.TrimPrefix(strings.TrimSpace(m[1]), ".")
+		if !contextFields.Has(field) {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("unknown context field %q, must be one of %s", field, strings.Join(contextFields.List(), ", ")), ""))
+		}
+	}
+	return errs
+}
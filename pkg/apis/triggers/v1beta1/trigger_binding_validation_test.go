@@ -110,6 +110,29 @@ func Test_TriggerBindingValidate(t *testing.T) {
 				}},
 			},
 		},
+	}, {
+		name: "context fields",
+		tb: &v1beta1.TriggerBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "name",
+				Namespace: "namespace",
+			},
+			Spec: v1beta1.TriggerBindingSpec{
+				Params: []v1beta1.Param{{
+					Name:  "param1",
+					Value: "$(context.eventID)",
+				}, {
+					Name:  "param2",
+					Value: "$(context.eventListener.namespace)/$(context.eventListener.name)",
+				}, {
+					Name:  "param3",
+					Value: "$(context.eventListener.uid | trim)",
+				}, {
+					Name:  "param4",
+					Value: "$(context.eventListener)",
+				}},
+			},
+		},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -203,6 +226,24 @@ func Test_TriggerBindingValidate_error(t *testing.T) {
 			},
 		},
 		errMsg: "invalid value: $($($(body.param1))): spec.params[0].value",
+	}, {
+		name: "unknown context field",
+		tb: &v1beta1.TriggerBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "name",
+				Namespace: "namespace",
+			},
+			Spec: v1beta1.TriggerBindingSpec{
+				Params: []v1beta1.Param{{
+					Name:  "param1",
+					Value: "$(body.foo)",
+				}, {
+					Name:  "param2",
+					Value: "$(context.eventListener.labels)",
+				}},
+			},
+		},
+		errMsg: `invalid value: unknown context field "eventListener.labels", must be one of eventID, eventListener, eventListener.name, eventListener.namespace, eventListener.uid: spec.params[1].value`,
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -17,8 +17,16 @@ limitations under the License.
 package v1beta1
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
 	"knative.dev/pkg/apis"
 )
 
@@ -32,13 +40,84 @@ type TriggerTemplateSpec struct {
 	Params []ParamSpec `json:"params,omitempty"`
 	// +listType=atomic
 	ResourceTemplates []TriggerResourceTemplate `json:"resourcetemplates,omitempty"`
+	// Selection optionally creates the resources of a single resource template per event, chosen by
+	// weight, instead of the resources of all of them
+	// +optional
+	Selection *ResourceTemplateSelection `json:"selection,omitempty"`
+	// GoTemplates are Go templates held in ConfigMaps, such as pipeline definitions maintained outside of
+	// Triggers, that are rendered with the params and the event into more resource templates, created
+	// after the resources of ResourceTemplates
+	// +listType=atomic
+	// +optional
+	GoTemplates []GoTemplateRef `json:"goTemplates,omitempty"`
+}
+
+// GoTemplateRef references a Go template in a ConfigMap in the namespace of the TriggerTemplate, which
+// renders one or more YAML or JSON documents that each describe a resource to create
+type GoTemplateRef struct {
+	// ConfigMapName is the name of the ConfigMap
+	ConfigMapName string `json:"configMapName"`
+	// Key is the key of the template in the ConfigMap
+	Key string `json:"key"`
 }
 
-// TriggerResourceTemplate describes a resource to create
+// ResourceTemplateSelection chooses one of the resource templates of a TriggerTemplate per event, e.g. to
+// split the events between two variants of a pipeline
+type ResourceTemplateSelection struct {
+	// Weights are the relative weights of the resource templates, in order: a template is chosen with a
+	// probability of its weight divided by the sum of the weights. A template with a weight of 0 is never
+	// chosen.
+	// +listType=atomic
+	Weights []int32 `json:"weights"`
+	// Key is optionally the name of a param whose value chooses the template instead of chance, so that
+	// the events with the same value, e.g. the URL of a pull request, always get the same template while
+	// the weights are unchanged
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// TriggerResourceTemplate describes a resource to create, or a string holding several YAML or JSON
+// documents that each describe a resource to create
 type TriggerResourceTemplate struct {
 	runtime.RawExtension `json:",inline"`
 }
 
+// Documents returns the resource templates of the template, converted to JSON: the template itself if
+// it is an object, or else the documents of the string, which may be a multi-document YAML block or a
+// sequence of JSON objects, in order. Empty documents are skipped.
+func (trt TriggerResourceTemplate) Documents() ([]json.RawMessage, error) {
+	raw := bytes.TrimSpace(trt.Raw)
+	if len(raw) == 0 || raw[0] != '"' {
+		return []json.RawMessage{trt.Raw}, nil
+	}
+	var block string
+	if err := json.Unmarshal(raw, &block); err != nil {
+		return nil, err
+	}
+	d := yaml.NewYAMLOrJSONDecoder(strings.NewReader(block), 4096)
+	var docs []json.RawMessage
+	for i := 0; ; i++ {
+		var doc json.RawMessage
+		if err := d.Decode(&doc); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse document %d: %w", i, err)
+		}
+		doc = bytes.TrimSpace(doc)
+		if len(doc) == 0 || string(doc) == "null" {
+			continue
+		}
+		if doc[0] != '{' {
+			return nil, fmt.Errorf("document %d is not an object", i)
+		}
+		docs = append(docs, doc)
+	}
+	if len(docs) == 0 {
+		return nil, errors.New("no documents in the resource template")
+	}
+	return docs, nil
+}
+
 // TriggerTemplateStatus describes the desired state of TriggerTemplate
 type TriggerTemplateStatus struct{}
 
@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func stringTemplate(t *testing.T, s string) TriggerResourceTemplate {
+	t.Helper()
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return TriggerResourceTemplate{RawExtension: runtime.RawExtension{Raw: b}}
+}
+
+func TestTriggerResourceTemplate_Documents(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		trt  TriggerResourceTemplate
+		want []string
+	}{{
+		name: "object",
+		trt:  TriggerResourceTemplate{RawExtension: runtime.RawExtension{Raw: []byte(`{"kind":"ConfigMap"}`)}},
+		want: []string{`{"kind":"ConfigMap"}`},
+	}, {
+		name: "multi-document YAML",
+		trt: stringTemplate(t, `---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: workspace-$(uid)
+---
+apiVersion: tekton.dev/v1beta1
+kind: PipelineRun
+metadata:
+  generateName: build-
+spec:
+  params:
+  - name: revision
+    value: $(tt.params.revision)
+---
+`),
+		want: []string{
+			`{"apiVersion":"v1","kind":"PersistentVolumeClaim","metadata":{"name":"workspace-$(uid)"}}`,
+			`{"apiVersion":"tekton.dev/v1beta1","kind":"PipelineRun","metadata":{"generateName":"build-"},"spec":{"params":[{"name":"revision","value":"$(tt.params.revision)"}]}}`,
+		},
+	}, {
+		name: "JSON lines",
+		trt:  stringTemplate(t, "{\"kind\": \"ConfigMap\"}\n{\"kind\": \"Secret\"}\n"),
+		want: []string{`{"kind": "ConfigMap"}`, `{"kind": "Secret"}`},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			docs, err := tc.trt.Documents()
+			if err != nil {
+				t.Fatalf("Documents() returned error: %v", err)
+			}
+			got := make([]string, len(docs))
+			for i := range docs {
+				got[i] = string(docs[i])
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Documents() -want +got: %s", diff)
+			}
+		})
+	}
+}
+
+func TestTriggerResourceTemplate_Documents_Error(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		block   string
+		wantErr string
+	}{{
+		name:    "invalid YAML",
+		block:   "kind: ConfigMap\n---\nkind: [Secret\n",
+		wantErr: "failed to parse document 1",
+	}, {
+		name:    "invalid JSON",
+		block:   `{"kind": "ConfigMap"} {"kind": `,
+		wantErr: "failed to parse document 1",
+	}, {
+		name:    "not an object",
+		block:   "kind: ConfigMap\n---\n- kind: Secret\n",
+		wantErr: "document 1 is not an object",
+	}, {
+		name:    "no documents",
+		block:   "---\n",
+		wantErr: "no documents in the resource template",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := stringTemplate(t, tc.block).Documents()
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Documents() returned error %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
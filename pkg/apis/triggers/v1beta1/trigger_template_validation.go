@@ -18,6 +18,7 @@ package v1beta1
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
@@ -25,14 +26,20 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/validate"
 	"github.com/tektoncd/triggers/pkg/apis/config"
 	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 )
 
 // paramsRegexp captures TriggerTemplate parameter names $(tt.params.NAME)
 var paramsRegexp = regexp.MustCompile(`\$\(tt.params.(?P<var>[_a-zA-Z][_a-zA-Z0-9.-]*)\)`)
 
+// funcParamsRegexp captures TriggerTemplate parameter names used as template function
+// arguments e.g. $(upper(tt.params.NAME)) or $(default(tt.params.NAME, 'value'))
+var funcParamsRegexp = regexp.MustCompile(`\$\([a-z]+\(tt.params.(?P<var>[_a-zA-Z][_a-zA-Z0-9.-]*)[,)]`)
+
 // Validate validates a TriggerTemplate.
 func (t *TriggerTemplate) Validate(ctx context.Context) *apis.FieldError {
 	if apis.IsInDelete(ctx) {
@@ -48,38 +55,127 @@ func (s *TriggerTemplateSpec) validate(ctx context.Context) (errs *apis.FieldErr
 	if equality.Semantic.DeepEqual(s, &TriggerTemplateSpec{}) {
 		errs = errs.Also(apis.ErrMissingField(apis.CurrentField))
 	}
-	if len(s.ResourceTemplates) == 0 {
+	if len(s.ResourceTemplates) == 0 && len(s.GoTemplates) == 0 {
 		errs = errs.Also(apis.ErrMissingField("resourcetemplates"))
 	}
 	errs = errs.Also(validateResourceTemplates(s.ResourceTemplates).ViaField("resourcetemplates"))
 	errs = errs.Also(verifyParamDeclarations(s.Params, s.ResourceTemplates).ViaField("resourcetemplates"))
+	errs = errs.Also(validateParamTypes(s.Params).ViaField("params"))
+	if s.Selection != nil {
+		errs = errs.Also(s.Selection.validate(s.Params, len(s.ResourceTemplates)).ViaField("selection"))
+	}
+	for i, ref := range s.GoTemplates {
+		errs = errs.Also(ref.validate().ViaFieldIndex("goTemplates", i))
+	}
+	return errs
+}
+
+// validate validates that the ConfigMap name and the key of the reference are valid.
+func (r GoTemplateRef) validate() (errs *apis.FieldError) {
+	if r.ConfigMapName == "" {
+		errs = errs.Also(apis.ErrMissingField("configMapName"))
+	} else if msgs := validation.IsDNS1123Subdomain(r.ConfigMapName); len(msgs) > 0 {
+		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s: %s", r.ConfigMapName, strings.Join(msgs, ", ")), "configMapName"))
+	}
+	if r.Key == "" {
+		errs = errs.Also(apis.ErrMissingField("key"))
+	} else if msgs := validation.IsConfigMapKey(r.Key); len(msgs) > 0 {
+		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s: %s", r.Key, strings.Join(msgs, ", ")), "key"))
+	}
+	return errs
+}
+
+// validate validates that the selection has a weight for each of the templates, that one of them is
+// positive, and that its key is a declared param.
+func (s *ResourceTemplateSelection) validate(params []ParamSpec, templates int) (errs *apis.FieldError) {
+	if len(s.Weights) != templates {
+		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%d weights for %d resource templates", len(s.Weights), templates), "weights"))
+	}
+	var total int64
+	for i, w := range s.Weights {
+		if w < 0 {
+			errs = errs.Also(apis.ErrInvalidValue(w, apis.CurrentField).ViaFieldIndex("weights", i))
+		}
+		total += int64(w)
+	}
+	if total <= 0 && len(s.Weights) > 0 {
+		errs = errs.Also(apis.ErrInvalidValue("at least one weight must be positive", "weights"))
+	}
+	if s.Key != "" {
+		declared := false
+		for _, p := range params {
+			declared = declared || p.Name == s.Key
+		}
+		if !declared {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("undeclared param '%s'", s.Key), "key"))
+		}
+	}
+	return errs
+}
+
+// validateParamTypes validates the types of the params, and that the defaults of array params are JSON
+// arrays.
+func validateParamTypes(params []ParamSpec) (errs *apis.FieldError) {
+	for i, p := range params {
+		switch p.Type {
+		case "", ParamTypeString:
+		case ParamTypeArray:
+			var a []interface{}
+			if p.Default != nil && json.Unmarshal([]byte(*p.Default), &a) != nil {
+				errs = errs.Also(apis.ErrInvalidValue("the default of an array param must be a JSON array", "default").ViaIndex(i))
+			}
+		default:
+			errs = errs.Also(apis.ErrInvalidValue(p.Type, "type").ViaIndex(i))
+		}
+	}
 	return errs
 }
 
 func validateResourceTemplates(templates []TriggerResourceTemplate) (errs *apis.FieldError) {
 	for i, trt := range templates {
-		if err := config.EnsureAllowedType(trt.RawExtension); err != nil {
-			if runtime.IsMissingVersion(err) {
-				errs = errs.Also(apis.ErrMissingField(fmt.Sprintf("[%d].apiVersion", i)))
-			}
-			if runtime.IsMissingKind(err) {
-				errs = errs.Also(apis.ErrMissingField(fmt.Sprintf("[%d].kind", i)))
+		docs, err := trt.Documents()
+		if err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(err.Error(), fmt.Sprintf("[%d]", i)))
+			continue
+		}
+		for j, doc := range docs {
+			path := fmt.Sprintf("[%d]", i)
+			if len(docs) > 1 {
+				path = fmt.Sprintf("[%d][%d]", i, j)
 			}
-			if runtime.IsNotRegisteredError(err) {
-				errStr := err.Error()
-				if inSchemeIdx := strings.Index(errStr, " in scheme"); inSchemeIdx > -1 {
-					// not registered error messages currently include the scheme variable location in your file,
-					// which can of course change if you move the location of the variable in your file.
-					// So will filter it out here to facilitate our unit testing, as the scheme location is not
-					// useful for our purposes.
-					errStr = errStr[:inSchemeIdx]
-				}
-				errs = errs.Also(apis.ErrInvalidValue(
-					errStr,
-					fmt.Sprintf("[%d]", i)))
+			errs = errs.Also(validateResourceTemplate(runtime.RawExtension{Raw: doc}, path))
+		}
+	}
+	return errs
+}
+
+// validateResourceTemplate validates the apiVersion and kind of the resource template at path, which is
+// the index of the template, followed by the index of the document for multi-document templates. A
+// template without both is typed by the default apiVersion and kind of the triggers using it.
+func validateResourceTemplate(rt runtime.RawExtension, path string) (errs *apis.FieldError) {
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(rt.Raw, &typeMeta); err == nil && typeMeta.APIVersion == "" && typeMeta.Kind == "" {
+		return nil
+	}
+	if err := config.EnsureAllowedType(rt); err != nil {
+		if runtime.IsMissingVersion(err) {
+			errs = errs.Also(apis.ErrMissingField(path + ".apiVersion"))
+		}
+		if runtime.IsMissingKind(err) {
+			errs = errs.Also(apis.ErrMissingField(path + ".kind"))
+		}
+		if runtime.IsNotRegisteredError(err) {
+			errStr := err.Error()
+			if inSchemeIdx := strings.Index(errStr, " in scheme"); inSchemeIdx > -1 {
+				// not registered error messages currently include the scheme variable location in your file,
+				// which can of course change if you move the location of the variable in your file.
+				// So will filter it out here to facilitate our unit testing, as the scheme location is not
+				// useful for our purposes.
+				errStr = errStr[:inSchemeIdx]
 			}
-			// we allow structural errors because of param substitution
+			errs = errs.Also(apis.ErrInvalidValue(errStr, path))
 		}
+		// we allow structural errors because of param substitution
 	}
 	return errs
 }
@@ -93,6 +189,7 @@ func verifyParamDeclarations(params []ParamSpec, templates []TriggerResourceTemp
 	for i, template := range templates {
 		// Get all params in the template $(tt.params.NAME)
 		templateParams := paramsRegexp.FindAllSubmatch(template.RawExtension.Raw, -1)
+		templateParams = append(templateParams, funcParamsRegexp.FindAllSubmatch(template.RawExtension.Raw, -1)...)
 		for _, templateParam := range templateParams {
 			templateParamName := string(templateParam[1])
 			if !declaredParamNames.Has(templateParamName) {
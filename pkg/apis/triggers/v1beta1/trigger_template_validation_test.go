@@ -70,6 +70,26 @@ func paramResourceTemplate(t *testing.T) runtime.RawExtension {
 	})
 }
 
+func funcParamResourceTemplate(t *testing.T) runtime.RawExtension {
+	return test.RawExtension(t, pipelinev1alpha1.PipelineRun{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "tekton.dev/v1beta1",
+			Kind:       "PipelineRun",
+		},
+		Spec: pipelinev1alpha1.PipelineRunSpec{
+			Params: []pipelinev1alpha1.Param{
+				{
+					Name: "message",
+					Value: pipelinev1alpha1.ArrayOrString{
+						Type:      pipelinev1alpha1.ParamTypeString,
+						StringVal: "$(upper(tt.params.foo))",
+					},
+				},
+			},
+		},
+	})
+}
+
 func invalidParamResourceTemplate(t *testing.T) runtime.RawExtension {
 	return test.RawExtension(t, pipelinev1alpha1.PipelineRun{
 		TypeMeta: metav1.TypeMeta{
@@ -194,6 +214,20 @@ func TestTriggerTemplate_Validate(t *testing.T) {
 			Message: "missing field(s)",
 			Paths:   []string{"spec.resourcetemplates"},
 		},
+	}, {
+		name: "untyped resource template",
+		template: &v1beta1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt",
+				Namespace: "foo",
+			},
+			Spec: v1beta1.TriggerTemplateSpec{
+				ResourceTemplates: []v1beta1.TriggerResourceTemplate{{
+					RawExtension: runtime.RawExtension{Raw: []byte(`{"metadata":{"generateName":"build-"},"spec":{"pipelineRef":{"name":"build"}}}`)},
+				}},
+			},
+		},
+		want: nil,
 	}, {
 		name: "resource template missing kind",
 		template: &v1beta1.TriggerTemplate{
@@ -300,6 +334,54 @@ func TestTriggerTemplate_Validate(t *testing.T) {
 			Message: `invalid value: no kind "tekton.dev/v1beta1" is registered for version "foo"`,
 			Paths:   []string{"spec.resourcetemplates[0]"},
 		},
+	}, {
+		name: "valid multi-document template",
+		template: &v1beta1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt",
+				Namespace: "foo",
+			},
+			Spec: v1beta1.TriggerTemplateSpec{
+				ResourceTemplates: []v1beta1.TriggerResourceTemplate{{
+					RawExtension: runtime.RawExtension{Raw: []byte(`"apiVersion: tekton.dev/v1beta1\nkind: PipelineRun\n---\napiVersion: tekton.dev/v1beta1\nkind: TaskRun\n"`)},
+				}},
+			},
+		},
+		want: nil,
+	}, {
+		name: "multi-document template document missing kind",
+		template: &v1beta1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt",
+				Namespace: "foo",
+			},
+			Spec: v1beta1.TriggerTemplateSpec{
+				ResourceTemplates: []v1beta1.TriggerResourceTemplate{{
+					RawExtension: runtime.RawExtension{Raw: []byte(`"apiVersion: tekton.dev/v1beta1\nkind: PipelineRun\n---\napiVersion: tekton.dev/v1beta1\n"`)},
+				}},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"spec.resourcetemplates[0][1].kind"},
+		},
+	}, {
+		name: "multi-document template with invalid YAML",
+		template: &v1beta1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt",
+				Namespace: "foo",
+			},
+			Spec: v1beta1.TriggerTemplateSpec{
+				ResourceTemplates: []v1beta1.TriggerResourceTemplate{{
+					RawExtension: runtime.RawExtension{Raw: []byte(`"apiVersion: tekton.dev/v1beta1\nkind: PipelineRun\n---\nkind: [TaskRun\n"`)},
+				}},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "invalid value: failed to parse document 1: error converting YAML to JSON: yaml: line 1: did not find expected ',' or ']'",
+			Paths:   []string{"spec.resourcetemplates[0]"},
+		},
 	}, {
 		name: "tt.params used in resource template are declared",
 		template: &v1beta1.TriggerTemplate{
@@ -337,6 +419,41 @@ func TestTriggerTemplate_Validate(t *testing.T) {
 			Paths:   []string{"spec.resourcetemplates[0]"},
 			Details: "'$(tt.params.foo)' must be declared in spec.params",
 		},
+	}, {
+		name: "tt.params used in template functions are not declared",
+		template: &v1beta1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt",
+				Namespace: "foo",
+			},
+			Spec: v1beta1.TriggerTemplateSpec{
+				ResourceTemplates: []v1beta1.TriggerResourceTemplate{{
+					RawExtension: funcParamResourceTemplate(t),
+				}},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "invalid value: undeclared param '$(tt.params.foo)'",
+			Paths:   []string{"spec.resourcetemplates[0]"},
+			Details: "'$(tt.params.foo)' must be declared in spec.params",
+		},
+	}, {
+		name: "tt.params used in template functions are declared",
+		template: &v1beta1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt",
+				Namespace: "foo",
+			},
+			Spec: v1beta1.TriggerTemplateSpec{
+				Params: []v1beta1.ParamSpec{{
+					Name: "foo",
+				}},
+				ResourceTemplates: []v1beta1.TriggerResourceTemplate{{
+					RawExtension: funcParamResourceTemplate(t),
+				}},
+			},
+		},
+		want: nil,
 	}, {
 		name: "invalid params used in resource template are not declared",
 		template: &v1beta1.TriggerTemplate{
@@ -379,6 +496,220 @@ func TestTriggerTemplate_Validate(t *testing.T) {
 			},
 		},
 		want: apis.ErrMissingField("spec", "spec.resourcetemplates"),
+	}, {
+		name: "valid array param",
+		template: &v1beta1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt",
+				Namespace: "foo",
+			},
+			Spec: v1beta1.TriggerTemplateSpec{
+				Params: []v1beta1.ParamSpec{{
+					Name:    "foo",
+					Type:    v1beta1.ParamTypeArray,
+					Default: ptr.String(`["a", "b"]`),
+				}},
+				ResourceTemplates: []v1beta1.TriggerResourceTemplate{{
+					RawExtension: paramResourceTemplate(t),
+				}},
+			},
+		},
+		want: nil,
+	}, {
+		name: "invalid param type",
+		template: &v1beta1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt",
+				Namespace: "foo",
+			},
+			Spec: v1beta1.TriggerTemplateSpec{
+				Params: []v1beta1.ParamSpec{{
+					Name: "foo",
+					Type: "object",
+				}},
+				ResourceTemplates: []v1beta1.TriggerResourceTemplate{{
+					RawExtension: paramResourceTemplate(t),
+				}},
+			},
+		},
+		want: apis.ErrInvalidValue("object", "spec.params[0].type"),
+	}, {
+		name: "array param with a default that is not an array",
+		template: &v1beta1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt",
+				Namespace: "foo",
+			},
+			Spec: v1beta1.TriggerTemplateSpec{
+				Params: []v1beta1.ParamSpec{{
+					Name:    "foo",
+					Type:    v1beta1.ParamTypeArray,
+					Default: ptr.String("a"),
+				}},
+				ResourceTemplates: []v1beta1.TriggerResourceTemplate{{
+					RawExtension: paramResourceTemplate(t),
+				}},
+			},
+		},
+		want: apis.ErrInvalidValue("the default of an array param must be a JSON array", "spec.params[0].default"),
+	}, {
+		name: "valid selection",
+		template: &v1beta1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt",
+				Namespace: "foo",
+			},
+			Spec: v1beta1.TriggerTemplateSpec{
+				Params: []v1beta1.ParamSpec{{
+					Name: "foo",
+				}},
+				ResourceTemplates: []v1beta1.TriggerResourceTemplate{{
+					RawExtension: paramResourceTemplate(t),
+				}, {
+					RawExtension: simpleResourceTemplate(t),
+				}},
+				Selection: &v1beta1.ResourceTemplateSelection{
+					Weights: []int32{90, 10},
+					Key:     "foo",
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "selection with a weight per resource template",
+		template: &v1beta1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt",
+				Namespace: "foo",
+			},
+			Spec: v1beta1.TriggerTemplateSpec{
+				Params: []v1beta1.ParamSpec{{
+					Name: "foo",
+				}},
+				ResourceTemplates: []v1beta1.TriggerResourceTemplate{{
+					RawExtension: paramResourceTemplate(t),
+				}, {
+					RawExtension: simpleResourceTemplate(t),
+				}},
+				Selection: &v1beta1.ResourceTemplateSelection{
+					Weights: []int32{1, 2, 3},
+				},
+			},
+		},
+		want: apis.ErrInvalidValue("3 weights for 2 resource templates", "spec.selection.weights"),
+	}, {
+		name: "selection with a negative weight",
+		template: &v1beta1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt",
+				Namespace: "foo",
+			},
+			Spec: v1beta1.TriggerTemplateSpec{
+				Params: []v1beta1.ParamSpec{{
+					Name: "foo",
+				}},
+				ResourceTemplates: []v1beta1.TriggerResourceTemplate{{
+					RawExtension: paramResourceTemplate(t),
+				}, {
+					RawExtension: simpleResourceTemplate(t),
+				}},
+				Selection: &v1beta1.ResourceTemplateSelection{
+					Weights: []int32{2, -1},
+				},
+			},
+		},
+		want: apis.ErrInvalidValue(-1, "spec.selection.weights[1]"),
+	}, {
+		name: "selection without positive weights",
+		template: &v1beta1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt",
+				Namespace: "foo",
+			},
+			Spec: v1beta1.TriggerTemplateSpec{
+				Params: []v1beta1.ParamSpec{{
+					Name: "foo",
+				}},
+				ResourceTemplates: []v1beta1.TriggerResourceTemplate{{
+					RawExtension: paramResourceTemplate(t),
+				}, {
+					RawExtension: simpleResourceTemplate(t),
+				}},
+				Selection: &v1beta1.ResourceTemplateSelection{
+					Weights: []int32{0, 0},
+				},
+			},
+		},
+		want: apis.ErrInvalidValue("at least one weight must be positive", "spec.selection.weights"),
+	}, {
+		name: "selection with an undeclared key",
+		template: &v1beta1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt",
+				Namespace: "foo",
+			},
+			Spec: v1beta1.TriggerTemplateSpec{
+				Params: []v1beta1.ParamSpec{{
+					Name: "foo",
+				}},
+				ResourceTemplates: []v1beta1.TriggerResourceTemplate{{
+					RawExtension: paramResourceTemplate(t),
+				}, {
+					RawExtension: simpleResourceTemplate(t),
+				}},
+				Selection: &v1beta1.ResourceTemplateSelection{
+					Weights: []int32{1, 1},
+					Key:     "bar",
+				},
+			},
+		},
+		want: apis.ErrInvalidValue("undeclared param 'bar'", "spec.selection.key"),
+	}, {
+		name: "valid Go templates without resource templates",
+		template: &v1beta1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt",
+				Namespace: "foo",
+			},
+			Spec: v1beta1.TriggerTemplateSpec{
+				GoTemplates: []v1beta1.GoTemplateRef{{
+					ConfigMapName: "pipelines",
+					Key:           "build.yaml",
+				}},
+			},
+		},
+	}, {
+		name: "Go template without a key",
+		template: &v1beta1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt",
+				Namespace: "foo",
+			},
+			Spec: v1beta1.TriggerTemplateSpec{
+				GoTemplates: []v1beta1.GoTemplateRef{{
+					ConfigMapName: "pipelines",
+				}},
+			},
+		},
+		want: apis.ErrMissingField("spec.goTemplates[0].key"),
+	}, {
+		name: "Go template with an invalid ConfigMap name",
+		template: &v1beta1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt",
+				Namespace: "foo",
+			},
+			Spec: v1beta1.TriggerTemplateSpec{
+				ResourceTemplates: []v1beta1.TriggerResourceTemplate{{
+					RawExtension: simpleResourceTemplate(t),
+				}},
+				GoTemplates: []v1beta1.GoTemplateRef{{
+					ConfigMapName: "Pipelines",
+					Key:           "build.yaml",
+				}},
+			},
+		},
+		want: apis.ErrInvalidValue("Pipelines: a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')", "spec.goTemplates[0].configMapName"),
 	}}
 
 	for _, tc := range tcs {
@@ -40,12 +40,136 @@ type TriggerSpec struct {
 	// as the Trigger itself
 	// +optional
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// Finalizer is optionally added to the resources created by the trigger,
+	// in addition to the finalizers specified in their templates,
+	// so that a controller can clean up after them when they are deleted
+	// +optional
+	Finalizer string `json:"finalizer,omitempty"`
+	// PromotedExtensions are the keys of the extensions added by the interceptors
+	// that bindings can also read as top-level fields of the event, e.g. $(foo)
+	// for $(extensions.foo)
+	// +listType=atomic
+	// +optional
+	PromotedExtensions []string `json:"promotedExtensions,omitempty"`
+	// DefaultNamespace is the namespace of the resources created by the trigger
+	// whose templates don't specify one, instead of the namespace of the trigger
+	// +optional
+	DefaultNamespace string `json:"defaultNamespace,omitempty"`
+	// NamespaceParam is the name of a param whose value is the namespace of the
+	// resources created by the trigger whose templates don't specify one, if
+	// DefaultNamespace is not set and the value is not empty
+	// +optional
+	NamespaceParam string `json:"namespaceParam,omitempty"`
+	// DefaultAPIVersion is the apiVersion of the resources created by the
+	// trigger whose templates specify neither an apiVersion nor a kind
+	// +optional
+	DefaultAPIVersion string `json:"defaultAPIVersion,omitempty"`
+	// DefaultKind is the kind of the resources created by the trigger whose
+	// templates specify neither an apiVersion nor a kind
+	// +optional
+	DefaultKind string `json:"defaultKind,omitempty"`
+	// ResourceLabels are optionally added to the resources created by the
+	// trigger, unless their templates specify labels with the same keys
+	// +optional
+	ResourceLabels map[string]string `json:"resourceLabels,omitempty"`
+	// ResourceAnnotations are optionally added to the resources created by the
+	// trigger, unless their templates specify annotations with the same keys
+	// +optional
+	ResourceAnnotations map[string]string `json:"resourceAnnotations,omitempty"`
+	// Overlays set fields of the resources created by the trigger to the typed
+	// values of params, after the params are replaced in their templates
+	// +listType=atomic
+	// +optional
+	Overlays []TriggerOverlay `json:"overlays,omitempty"`
+	// ResourceTTL optionally annotates the resources created by the trigger with
+	// their time to live after completion, for a cleanup controller to delete them
+	// +optional
+	ResourceTTL *ResourceTTL `json:"resourceTTL,omitempty"`
+	// ClientProfile optionally names the client profile, defined in the client
+	// profiles ConfigMap of Triggers, of the clients creating the resources
+	// +optional
+	ClientProfile string `json:"clientProfile,omitempty"`
+}
+
+// TriggerOverlay sets the field at a JSON pointer of the resources created by a
+// trigger to the value of a param, converted to a JSON value of the given type.
+type TriggerOverlay struct {
+	// Path is the JSON pointer of the field, e.g. /spec/timeout. Its parent
+	// must exist in the resources.
+	Path string `json:"path"`
+	// Param is the name of the param whose value is set.
+	Param string `json:"param"`
+	// Type is the type of the value: string, number, boolean, array or object.
+	// Defaults to array for array params and to string for the others.
+	// +optional
+	Type OverlayType `json:"type,omitempty"`
+	// ResourceIndex is the index of the created resource to patch, in the
+	// order of their templates. Defaults to all of them.
+	// +optional
+	ResourceIndex *int `json:"resourceIndex,omitempty"`
+}
+
+// OverlayType is the JSON type of the value of a TriggerOverlay.
+type OverlayType string
+
+const (
+	OverlayTypeString  OverlayType = "string"
+	OverlayTypeNumber  OverlayType = "number"
+	OverlayTypeBoolean OverlayType = "boolean"
+	OverlayTypeArray   OverlayType = "array"
+	OverlayTypeObject  OverlayType = "object"
+)
+
+// ResourceTTL is the time to live of the resources created by a trigger once
+// they complete. It is set as the value of an annotation of the resources, e.g.
+// 24h0m0s, which a cleanup controller reads to delete the resources that have
+// completed for longer. The resources whose templates set ownerReferences don't
+// get the annotation, since they are deleted with their owners.
+type ResourceTTL struct {
+	// After is the time to live of the resources, e.g. 24h
+	// +optional
+	After *metav1.Duration `json:"after,omitempty"`
+	// Param is the name of a param whose value is the time to live of the
+	// resources instead of After, if the value is not empty
+	// +optional
+	Param string `json:"param,omitempty"`
+	// Annotation is the key of the annotation, triggers.tekton.dev/ttl-after-completion
+	// by default
+	// +optional
+	Annotation string `json:"annotation,omitempty"`
 }
 
 type TriggerSpecTemplate struct {
 	Ref        *string              `json:"ref,omitempty"`
 	APIVersion string               `json:"apiversion,omitempty"`
 	Spec       *TriggerTemplateSpec `json:"spec,omitempty"`
+	// Routing optionally selects another TriggerTemplate per event from the
+	// value of an extension added by the interceptors. Ref or Spec is the
+	// template of the events that no route matches
+	// +optional
+	Routing *TriggerTemplateRouting `json:"routing,omitempty"`
+}
+
+// TriggerTemplateRouting selects the TriggerTemplate of a trigger per event, so
+// that one trigger, whose interceptors classify the events once, creates
+// different resources for each class of events.
+type TriggerTemplateRouting struct {
+	// Extension is the key of the extension added by the interceptors whose
+	// value selects the route, e.g. type for $(extensions.type)
+	Extension string `json:"extension"`
+	// Routes are the TriggerTemplates of the values of the extension
+	// +listType=atomic
+	Routes []TriggerTemplateRoute `json:"routes"`
+}
+
+// TriggerTemplateRoute is the TriggerTemplate of the events with a value of the
+// routing extension.
+type TriggerTemplateRoute struct {
+	// Value is the value of the extension. Numbers and booleans match their
+	// string form, e.g. 1 or true
+	Value string `json:"value"`
+	// Ref is the name of the TriggerTemplate, in the namespace of the trigger
+	Ref string `json:"ref"`
 }
 
 type TriggerSpecBinding struct {
@@ -66,6 +190,11 @@ type TriggerSpecBinding struct {
 
 	// APIVersion of the binding ref
 	APIVersion string `json:"apiversion,omitempty"`
+
+	// Override allows the params of this binding to replace the params of the same name
+	// of the earlier bindings, which is otherwise an error.
+	// +optional
+	Override bool `json:"override,omitempty"`
 }
 
 // +genclient
@@ -93,6 +222,10 @@ type TriggerInterceptor struct {
 	// Params are the params to send to the interceptor
 	// +listType=atomic
 	Params []InterceptorParams `json:"params,omitempty"`
+	// When is a CEL expression evaluated against the event as modified by the previous interceptors. If it
+	// evaluates to false, the interceptor is skipped and the event continues down the chain.
+	// +optional
+	When string `json:"when,omitempty"`
 
 	// WebhookInterceptor refers to an old style webhook interceptor service
 	Webhook *WebhookInterceptor `json:"webhook,omitempty"`
@@ -158,6 +291,11 @@ type WebhookInterceptor struct {
 // BitbucketInterceptor provides a webhook to intercept and pre-process events
 type BitbucketInterceptor struct {
 	SecretRef *SecretRef `json:"secretRef,omitempty"`
+	// AdditionalSecretRefs are secrets that are accepted in addition to SecretRef,
+	// so that the webhook secret can be rotated without rejecting events.
+	// +optional
+	// +listType=atomic
+	AdditionalSecretRefs []SecretRef `json:"additionalSecretRefs,omitempty"`
 	// +listType=atomic
 	EventTypes []string `json:"eventTypes,omitempty"`
 }
@@ -165,13 +303,85 @@ type BitbucketInterceptor struct {
 // GitHubInterceptor provides a webhook to intercept and pre-process events
 type GitHubInterceptor struct {
 	SecretRef *SecretRef `json:"secretRef,omitempty"`
+	// AdditionalSecretRefs are secrets that are accepted in addition to SecretRef,
+	// so that the webhook secret can be rotated without rejecting events.
+	// +optional
+	// +listType=atomic
+	AdditionalSecretRefs []SecretRef `json:"additionalSecretRefs,omitempty"`
 	// +listType=atomic
 	EventTypes []string `json:"eventTypes,omitempty"`
+	// EnterpriseHost is the GitHub Enterprise host whose API is called, e.g.
+	// github.example.com. Events whose X-GitHub-Enterprise-Host header names
+	// another host are rejected. Defaults to the public GitHub API.
+	// +optional
+	EnterpriseHost string `json:"enterpriseHost,omitempty"`
+	// AddChangedFiles fetches the files changed by pull_request and push events
+	// from the GitHub API and adds them to the changed_files extension.
+	// +optional
+	AddChangedFiles GithubAddChangedFiles `json:"addChangedFiles,omitempty"`
+	// VerifyCommits fetches the head commit of pull_request and push events from
+	// the GitHub API and adds its author, committer and signature verification
+	// status to the commit extension.
+	// +optional
+	VerifyCommits GithubVerifyCommits `json:"verifyCommits,omitempty"`
+	// ExpandCommits lists all the commits of pull_request and push events,
+	// following the pages of the GitHub API, and adds them to the commits
+	// extension.
+	// +optional
+	ExpandCommits GithubExpandCommits `json:"expandCommits,omitempty"`
+}
+
+// GithubAddChangedFiles configures the retrieval of the files changed by an event.
+type GithubAddChangedFiles struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// PersonalAccessToken is the token used to call the GitHub API, required for
+	// private repositories.
+	// +optional
+	PersonalAccessToken *SecretRef `json:"personalAccessToken,omitempty"`
+}
+
+// GithubVerifyCommits configures the verification of the signature of the head commit of an event.
+type GithubVerifyCommits struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// PersonalAccessToken is the token used to call the GitHub API, required for
+	// private repositories.
+	// +optional
+	PersonalAccessToken *SecretRef `json:"personalAccessToken,omitempty"`
+	// RequireVerified rejects the events whose head commit doesn't have a
+	// signature verified by GitHub.
+	// +optional
+	RequireVerified bool `json:"requireVerified,omitempty"`
+	// Timeout bounds the call to the GitHub API, as a duration e.g. "5s".
+	// Defaults to 10s.
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// GithubExpandCommits configures the listing of all the commits of an event.
+type GithubExpandCommits struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// PersonalAccessToken is the token used to call the GitHub API, required for
+	// private repositories.
+	// +optional
+	PersonalAccessToken *SecretRef `json:"personalAccessToken,omitempty"`
+	// MaxCommits bounds the number of commits listed. Defaults to 250.
+	// +optional
+	MaxCommits int `json:"maxCommits,omitempty"`
+	// MaxRateLimitWait is the longest the interceptor waits for the rate limit
+	// of the GitHub API to reset before retrying a page, as a duration e.g.
+	// "30s". Defaults to 10s.
+	// +optional
+	MaxRateLimitWait string `json:"maxRateLimitWait,omitempty"`
 }
 
 // GitLabInterceptor provides a webhook to intercept and pre-process events
 type GitLabInterceptor struct {
 	SecretRef *SecretRef `json:"secretRef,omitempty"`
+	// AdditionalSecretRefs are secrets that are accepted in addition to SecretRef,
+	// so that the webhook secret can be rotated without rejecting events.
+	// +optional
+	// +listType=atomic
+	AdditionalSecretRefs []SecretRef `json:"additionalSecretRefs,omitempty"`
 	// +listType=atomic
 	EventTypes []string `json:"eventTypes,omitempty"`
 }
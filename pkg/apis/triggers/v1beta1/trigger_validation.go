@@ -18,11 +18,21 @@ package v1beta1
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strings"
 
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/apis/validate"
+	"github.com/tektoncd/triggers/pkg/apis/config"
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/contexts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 )
 
@@ -33,7 +43,7 @@ func (t *Trigger) Validate(ctx context.Context) *apis.FieldError {
 	}
 
 	errs := validate.ObjectMetadata(t.GetObjectMeta()).ViaField("metadata")
-	return errs.Also(t.Spec.validate(ctx).ViaField("spec"))
+	return errs.Also(t.Spec.validate(apis.WithinParent(ctx, t.ObjectMeta)).ViaField("spec"))
 }
 
 func (t *TriggerSpec) validate(ctx context.Context) *apis.FieldError {
@@ -44,12 +54,256 @@ func (t *TriggerSpec) validate(ctx context.Context) *apis.FieldError {
 
 	// Validate optional Interceptors
 	for i, interceptor := range t.Interceptors {
+		if interceptor == nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("interceptor '%v' must be a valid value", interceptor), fmt.Sprintf("interceptors[%d]", i)))
+			continue
+		}
 		errs = errs.Also(interceptor.validate(ctx).ViaField(fmt.Sprintf("interceptors[%d]", i)))
 	}
 
+	return errs.Also(validateFinalizer(t.Finalizer)).Also(validatePromotedExtensions(t.PromotedExtensions)).
+		Also(validateDefaultNamespace(t.DefaultNamespace, t.NamespaceParam)).
+		Also(validateDefaultResourceType(t.DefaultAPIVersion, t.DefaultKind)).
+		Also(validateResourceMetadata(t.ResourceLabels, t.ResourceAnnotations)).
+		Also(validateOverlays(t.Overlays, t.Template.Spec)).
+		Also(validateResourceTTL(t.ResourceTTL)).
+		Also(validateClientProfile(ctx, t.ClientProfile))
+}
+
+// validateFinalizer checks that the optional finalizer is a domain-qualified name, as Kubernetes requires
+// for finalizers that are not its own.
+func validateFinalizer(finalizer string) *apis.FieldError {
+	if finalizer == "" {
+		return nil
+	}
+	if msgs := validation.IsQualifiedName(finalizer); len(msgs) > 0 {
+		return apis.ErrInvalidValue(fmt.Sprintf("finalizer %q must be a qualified name: %s", finalizer, strings.Join(msgs, ", ")), "finalizer")
+	}
+	if !strings.Contains(finalizer, "/") {
+		return apis.ErrInvalidValue(fmt.Sprintf("finalizer %q must be domain-qualified, e.g. example.com/cleanup", finalizer), "finalizer")
+	}
+	return nil
+}
+
+// namespaceParamRegex matches the names of the params that can be referred to in
+// templates, which the namespace param must be.
+var namespaceParamRegex = regexp.MustCompile(`^[_a-zA-Z][_a-zA-Z0-9.-]*$`)
+
+// validateDefaultNamespace checks that the optional default namespace is a valid
+// namespace name and that the optional namespace param is a valid param name.
+func validateDefaultNamespace(namespace, param string) (errs *apis.FieldError) {
+	if namespace != "" {
+		if msgs := validation.IsDNS1123Label(namespace); len(msgs) > 0 {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("default namespace %q must be a valid namespace name: %s", namespace, strings.Join(msgs, ", ")), "defaultNamespace"))
+		}
+	}
+	if param != "" && !namespaceParamRegex.MatchString(param) {
+		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("namespace param %q must be a valid param name", param), "namespaceParam"))
+	}
 	return errs
 }
 
+// validateDefaultResourceType checks that the optional default apiVersion and kind are set together, and
+// that they are one of the types the resource templates are allowed to create.
+func validateDefaultResourceType(apiVersion, kind string) *apis.FieldError {
+	switch {
+	case apiVersion == "" && kind == "":
+		return nil
+	case apiVersion == "":
+		return apis.ErrMissingField("defaultAPIVersion")
+	case kind == "":
+		return apis.ErrMissingField("defaultKind")
+	}
+	raw, err := json.Marshal(metav1.TypeMeta{APIVersion: apiVersion, Kind: kind})
+	if err != nil {
+		return apis.ErrInvalidValue(err.Error(), "defaultKind")
+	}
+	if err := config.EnsureAllowedType(runtime.RawExtension{Raw: raw}); runtime.IsNotRegisteredError(err) {
+		return apis.ErrInvalidValue(fmt.Sprintf("default apiVersion %s and kind %s are not an allowed resource type", apiVersion, kind), "defaultKind")
+	}
+	return nil
+}
+
+// validateOverlays checks that the paths of the overlays are JSON pointers, and that their params are
+// valid param names declared by the embedded template, if the trigger has one.
+func validateOverlays(overlays []TriggerOverlay, spec *TriggerTemplateSpec) (errs *apis.FieldError) {
+	for i, o := range overlays {
+		if err := validateJSONPointer(o.Path); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("overlay path %q must be a JSON pointer: %v", o.Path, err), "path").ViaFieldIndex("overlays", i))
+		}
+		switch {
+		case o.Param == "":
+			errs = errs.Also(apis.ErrMissingField("param").ViaFieldIndex("overlays", i))
+		case !namespaceParamRegex.MatchString(o.Param):
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("overlay param %q must be a valid param name", o.Param), "param").ViaFieldIndex("overlays", i))
+		case spec != nil && !declaresParam(spec.Params, o.Param):
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("undeclared param '%s'", o.Param), "param").ViaFieldIndex("overlays", i))
+		}
+		switch o.Type {
+		case "", OverlayTypeString, OverlayTypeNumber, OverlayTypeBoolean, OverlayTypeArray, OverlayTypeObject:
+		default:
+			errs = errs.Also(apis.ErrInvalidValue(o.Type, "type").ViaFieldIndex("overlays", i))
+		}
+		if o.ResourceIndex != nil && *o.ResourceIndex < 0 {
+			errs = errs.Also(apis.ErrInvalidValue(*o.ResourceIndex, "resourceIndex").ViaFieldIndex("overlays", i))
+		}
+	}
+	return errs
+}
+
+// validateResourceTTL checks that the optional resource TTL has a positive duration or a param with a valid
+// param name, and that its annotation is a valid annotation key, which can only be in the triggers.tekton.dev
+// domain if it is the default one.
+func validateResourceTTL(ttl *ResourceTTL) (errs *apis.FieldError) {
+	if ttl == nil {
+		return nil
+	}
+	if ttl.After == nil && ttl.Param == "" {
+		errs = errs.Also(apis.ErrMissingOneOf("after", "param").ViaField("resourceTTL"))
+	}
+	if ttl.After != nil && ttl.After.Duration <= 0 {
+		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("resource TTL %s must be positive", ttl.After.Duration), "resourceTTL.after"))
+	}
+	if ttl.Param != "" && !namespaceParamRegex.MatchString(ttl.Param) {
+		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("resource TTL param %q must be a valid param name", ttl.Param), "resourceTTL.param"))
+	}
+	if ttl.Annotation != "" && ttl.Annotation != triggers.GroupName+triggers.TTLAfterCompletionAnnotationKey {
+		if msgs := validation.IsQualifiedName(strings.ToLower(ttl.Annotation)); len(msgs) > 0 {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("resource TTL annotation %q must be a valid annotation key: %s", ttl.Annotation, strings.Join(msgs, ", ")), "resourceTTL.annotation"))
+		} else if isReservedKey(ttl.Annotation) {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("resource TTL annotation %q must not be in the reserved %s domain", ttl.Annotation, triggers.GroupName), "resourceTTL.annotation"))
+		}
+	}
+	return errs
+}
+
+// validateClientProfile checks that the optional client profile is a valid profile name and, when the
+// configuration of Triggers is in the context as it is in the webhook, that the profile is defined and
+// allowed in the namespace of the Trigger or EventListener.
+func validateClientProfile(ctx context.Context, name string) *apis.FieldError {
+	if name == "" {
+		return nil
+	}
+	if msgs := validation.IsDNS1123Label(name); len(msgs) > 0 {
+		return apis.ErrInvalidValue(fmt.Sprintf("client profile %q must be a valid profile name: %s", name, strings.Join(msgs, ", ")), "clientProfile")
+	}
+	cfg := config.FromContext(ctx)
+	if cfg == nil {
+		return nil
+	}
+	if !cfg.ClientProfiles.Has(name) {
+		return apis.ErrInvalidValue(fmt.Sprintf("client profile %q is not defined in ConfigMap %s", name, config.GetClientProfilesConfigName()), "clientProfile")
+	}
+	if ns := apis.ParentMeta(ctx).Namespace; !cfg.ClientProfiles.AllowedIn(name, ns) {
+		return apis.ErrInvalidValue(fmt.Sprintf("client profile %q is not allowed in namespace %s", name, ns), "clientProfile")
+	}
+	return nil
+}
+
+// validateJSONPointer checks that path is a JSON pointer as defined by RFC 6901, which refers to a field
+// of a resource rather than the whole document.
+func validateJSONPointer(path string) error {
+	if !strings.HasPrefix(path, "/") {
+		return errors.New("must start with /")
+	}
+	for i := 0; i < len(path); i++ {
+		if path[i] == '~' && (i+1 == len(path) || (path[i+1] != '0' && path[i+1] != '1')) {
+			return errors.New("~ must be escaped as ~0")
+		}
+	}
+	return nil
+}
+
+func declaresParam(params []ParamSpec, name string) bool {
+	for _, p := range params {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// totalAnnotationSizeLimit is the largest total size of the annotations of a resource that the API
+// server accepts.
+const totalAnnotationSizeLimit = 256 * 1024
+
+// validateResourceMetadata checks that the optional resource labels and annotations
+// are valid labels and annotations. Their keys can't be in the triggers.tekton.dev
+// domain, which is reserved for the provenance labels and the annotation directives
+// of resource templates.
+func validateResourceMetadata(labels, annotations map[string]string) (errs *apis.FieldError) {
+	for k, v := range labels {
+		if msgs := validation.IsQualifiedName(k); len(msgs) > 0 {
+			errs = errs.Also(apis.ErrInvalidKeyName(k, "resourceLabels", msgs...))
+		} else if isReservedKey(k) {
+			errs = errs.Also(apis.ErrInvalidKeyName(k, "resourceLabels", fmt.Sprintf("the %s domain is reserved", triggers.GroupName)))
+		}
+		if msgs := validation.IsValidLabelValue(v); len(msgs) > 0 {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("resource label value %q must be a valid label value: %s", v, strings.Join(msgs, ", ")), fmt.Sprintf("resourceLabels[%s]", k)))
+		}
+	}
+	var size int
+	for k, v := range annotations {
+		if msgs := validation.IsQualifiedName(strings.ToLower(k)); len(msgs) > 0 {
+			errs = errs.Also(apis.ErrInvalidKeyName(k, "resourceAnnotations", msgs...))
+		} else if isReservedKey(k) {
+			errs = errs.Also(apis.ErrInvalidKeyName(k, "resourceAnnotations", fmt.Sprintf("the %s domain is reserved", triggers.GroupName)))
+		}
+		size += len(k) + len(v)
+	}
+	if size > totalAnnotationSizeLimit {
+		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("resource annotations must not be larger than %d bytes in total", totalAnnotationSizeLimit), "resourceAnnotations"))
+	}
+	return errs
+}
+
+// isReservedKey returns true if the label or annotation key is in the triggers.tekton.dev
+// domain or one of its subdomains.
+func isReservedKey(key string) bool {
+	i := strings.Index(key, "/")
+	if i < 0 {
+		return false
+	}
+	domain := strings.ToLower(key[:i])
+	return domain == triggers.GroupName || strings.HasSuffix(domain, "."+triggers.GroupName)
+}
+
+// eventFields are the top-level fields of the events that bindings read, which
+// promoted extensions can't shadow.
+var eventFields = []string{"body", "header", "query", "cookie", "extensions", "context"}
+
+// promotedExtensionRegex matches the extension keys that can be read as a
+// top-level field of the event without quoting, e.g. $(pull_request_id).
+var promotedExtensionRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// validatePromotedExtensions checks that the promoted extension keys are valid
+// field names that don't collide with the fields of events or with each other.
+func validatePromotedExtensions(keys []string) (errs *apis.FieldError) {
+	seen := make(map[string]bool, len(keys))
+	for i, key := range keys {
+		path := fmt.Sprintf("promotedExtensions[%d]", i)
+		switch {
+		case !promotedExtensionRegex.MatchString(key):
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("promoted extension %q must start with a letter or underscore and contain only letters, digits, underscores and dashes", key), path))
+		case isEventField(key):
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("promoted extension %q collides with the %s field of events", key, key), path))
+		case seen[key]:
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("duplicate promoted extension %q", key), path))
+		}
+		seen[key] = true
+	}
+	return errs
+}
+
+func isEventField(key string) bool {
+	for _, f := range eventFields {
+		if key == f {
+			return true
+		}
+	}
+	return false
+}
+
 func (t TriggerSpecTemplate) validate(ctx context.Context) (errs *apis.FieldError) {
 	// Optional explicit match
 	if t.APIVersion != "" {
@@ -68,12 +322,37 @@ func (t TriggerSpecTemplate) validate(ctx context.Context) (errs *apis.FieldErro
 	case t.Ref == nil || *t.Ref == "":
 		errs = errs.Also(apis.ErrMissingField("template.ref"))
 	}
+	if t.Routing != nil {
+		errs = errs.Also(t.Routing.validate().ViaField("template.routing"))
+	}
+	return errs
+}
+
+func (r *TriggerTemplateRouting) validate() (errs *apis.FieldError) {
+	if r.Extension == "" {
+		errs = errs.Also(apis.ErrMissingField("extension"))
+	}
+	if len(r.Routes) == 0 {
+		errs = errs.Also(apis.ErrMissingField("routes"))
+	}
+	values := make(map[string]bool, len(r.Routes))
+	for i, route := range r.Routes {
+		if values[route.Value] {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("value %q is routed more than once", route.Value), "value").ViaFieldIndex("routes", i))
+		}
+		values[route.Value] = true
+		if route.Ref == "" {
+			errs = errs.Also(apis.ErrMissingField("ref").ViaFieldIndex("routes", i))
+		}
+	}
 	return errs
 }
 
 func (t triggerSpecBindingArray) validate(ctx context.Context) (errs *apis.FieldError) {
 	for i, b := range t {
 		switch {
+		case b == nil:
+			errs = errs.Also(apis.ErrMissingField(fmt.Sprintf("bindings[%d]", i)))
 		case b.Ref != "":
 			switch {
 			case b.Name != "": // Cannot specify both Ref and Name
@@ -84,6 +363,8 @@ func (t triggerSpecBindingArray) validate(ctx context.Context) (errs *apis.Field
 		case b.Name != "":
 			if b.Value == nil { // Value is mandatory if Name is specified
 				errs = errs.Also(apis.ErrMissingField(fmt.Sprintf("bindings[%d].value", i)))
+			} else {
+				errs = errs.Also(validateContextReferences(*b.Value).ViaField(fmt.Sprintf("bindings[%d].value", i)))
 			}
 		default:
 			errs = errs.Also(apis.ErrMissingOneOf(fmt.Sprintf("bindings[%d].ref", i), fmt.Sprintf("bindings[%d].spec", i), fmt.Sprintf("bindings[%d].name", i)))
@@ -96,6 +377,18 @@ func (i *TriggerInterceptor) validate(ctx context.Context) (errs *apis.FieldErro
 	if i.Webhook == nil {
 		if i.Ref.Name == "" { // Check to see if Interceptor referenced using Ref
 			errs = errs.Also(apis.ErrMissingField("interceptor"))
+		} else if i.Ref.Kind == "" || i.Ref.Kind == ClusterInterceptorKind {
+			errs = errs.Also(validateClusterInterceptorExists(ctx, i.Ref.Name))
+			if i.Ref.Name == "cel" {
+				errs = errs.Also(validateCELExpressions(ctx, i.Params))
+			}
+		}
+	}
+	if i.When != "" {
+		if check := contexts.GetCELCheck(ctx); check != nil {
+			if err := check(i.When); err != nil {
+				errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("when expression %q doesn't compile: %v", i.When, err), "when"))
+			}
 		}
 	}
 
@@ -135,3 +428,53 @@ func (i *TriggerInterceptor) validate(ctx context.Context) (errs *apis.FieldErro
 	}
 	return errs
 }
+
+// validateCELExpressions checks that the filter and overlay expressions in the params of a CEL interceptor
+// compile and type check, if the context can check them, so that their mistakes are reported when the Trigger
+// is applied rather than when it processes events.
+func validateCELExpressions(ctx context.Context, params []InterceptorParams) (errs *apis.FieldError) {
+	check := contexts.GetCELCheck(ctx)
+	if check == nil {
+		return nil
+	}
+	for i, p := range params {
+		field := fmt.Sprintf("params[%d].value", i)
+		switch p.Name {
+		case "filter":
+			var filter string
+			if err := json.Unmarshal(p.Value.Raw, &filter); err != nil {
+				errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("CEL filter must be a string: %v", err), field))
+			} else if filter != "" {
+				if err := check(filter); err != nil {
+					errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("CEL filter %q doesn't compile: %v", filter, err), field))
+				}
+			}
+		case "overlays":
+			var overlays []CELOverlay
+			if err := json.Unmarshal(p.Value.Raw, &overlays); err != nil {
+				errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("CEL overlays must be a list of keys and expressions: %v", err), field))
+				continue
+			}
+			for j, o := range overlays {
+				if err := check(o.Expression); err != nil {
+					errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("CEL overlay %s expression %q doesn't compile: %v", o.Key, o.Expression, err), fmt.Sprintf("%s[%d].expression", field, j)))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// validateClusterInterceptorExists checks that the referenced ClusterInterceptor exists, if the
+// context can look it up. Failed lookups are ignored so that Triggers can still be admitted while
+// the API server is unavailable to the webhook.
+func validateClusterInterceptorExists(ctx context.Context, name string) *apis.FieldError {
+	exists := contexts.GetClusterInterceptorExists(ctx)
+	if exists == nil {
+		return nil
+	}
+	if ok, err := exists(ctx, name); err == nil && !ok {
+		return apis.ErrGeneric(fmt.Sprintf("ClusterInterceptor %q does not exist", name), "ref.name")
+	}
+	return nil
+}
@@ -18,10 +18,15 @@ package v1beta1_test
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/triggers/pkg/apis/config"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/contexts"
 	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
 	"github.com/tektoncd/triggers/test"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -144,6 +149,9 @@ func Test_TriggerValidate(t *testing.T) {
 				}, {
 					Name:  "param2",
 					Value: ptr.String("val2"),
+				}, {
+					Name:  "el",
+					Value: ptr.String("$(context.eventListener.namespace)/$(context.eventListener.name | lower)"),
 				}, {
 					Ref:  "ref-to-another-binding",
 					Kind: v1beta1.NamespacedTriggerBindingKind,
@@ -201,6 +209,131 @@ func Test_TriggerValidate(t *testing.T) {
 				},
 			},
 		},
+	}, {
+		name: "Valid Trigger with finalizer",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "namespace",
+				Name:      "name",
+			},
+			Spec: v1beta1.TriggerSpec{
+				Template: v1beta1.TriggerSpecTemplate{
+					Ref: ptr.String("tt"),
+				},
+				Finalizer: "example.com/cleanup",
+			},
+		},
+	}, {
+		name: "Valid Trigger with a resource TTL",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "namespace",
+				Name:      "name",
+			},
+			Spec: v1beta1.TriggerSpec{
+				Template: v1beta1.TriggerSpecTemplate{
+					Ref: ptr.String("tt"),
+				},
+				ResourceTTL: &v1beta1.ResourceTTL{
+					After:      &metav1.Duration{Duration: 24 * time.Hour},
+					Param:      "ttl",
+					Annotation: "example.com/ttl",
+				},
+			},
+		},
+	}, {
+		name: "Valid Trigger with promoted extensions",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "namespace",
+				Name:      "name",
+			},
+			Spec: v1beta1.TriggerSpec{
+				Template: v1beta1.TriggerSpecTemplate{
+					Ref: ptr.String("tt"),
+				},
+				PromotedExtensions: []string{"pull_request", "short-sha"},
+			},
+		},
+	}, {
+		name: "Valid Trigger with a default namespace",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "namespace",
+				Name:      "name",
+			},
+			Spec: v1beta1.TriggerSpec{
+				Template: v1beta1.TriggerSpecTemplate{
+					Ref: ptr.String("tt"),
+				},
+				DefaultNamespace: "team-a",
+				NamespaceParam:   "team.namespace",
+			},
+		},
+	}, {
+		name: "Valid Trigger with a default resource type",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "namespace",
+				Name:      "name",
+			},
+			Spec: v1beta1.TriggerSpec{
+				Template: v1beta1.TriggerSpecTemplate{
+					Ref: ptr.String("tt"),
+				},
+				DefaultAPIVersion: "tekton.dev/v1beta1",
+				DefaultKind:       "PipelineRun",
+			},
+		},
+	}, {
+		name: "Valid Trigger with resource labels and annotations",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "namespace",
+				Name:      "name",
+			},
+			Spec: v1beta1.TriggerSpec{
+				Template: v1beta1.TriggerSpecTemplate{
+					Ref: ptr.String("tt"),
+				},
+				ResourceLabels:      map[string]string{"example.com/team": "platform", "cost-center": "cc-1234"},
+				ResourceAnnotations: map[string]string{"example.com/notify": "#builds, on failure"},
+			},
+		},
+	}, {
+		name: "Trigger with overlays",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template: v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				Overlays: []v1beta1.TriggerOverlay{{
+					Path:  "/spec/params/0/value",
+					Param: "replicas",
+					Type:  v1beta1.OverlayTypeNumber,
+				}, {
+					Path:          "/metadata/annotations/example.com~1tags",
+					Param:         "tags",
+					ResourceIndex: new(int),
+				}},
+			},
+		},
+	}, {
+		name: "Trigger with template routing",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template: v1beta1.TriggerSpecTemplate{
+					Ref: ptr.String("tt"),
+					Routing: &v1beta1.TriggerTemplateRouting{
+						Extension: "type",
+						Routes: []v1beta1.TriggerTemplateRoute{
+							{Value: "deploy", Ref: "deploy-tt"},
+							{Value: "test", Ref: "test-tt"},
+						},
+					},
+				},
+			},
+		},
 	}, {
 		name: "Trigger with embedded Template",
 		tr: &v1beta1.Trigger{
@@ -407,6 +540,303 @@ func TestTriggerValidate_error(t *testing.T) {
 				Template: v1beta1.TriggerSpecTemplate{},
 			},
 		},
+	}, {
+		name: "Finalizer without domain",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:  v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				Finalizer: "cleanup",
+			},
+		},
+	}, {
+		name: "Invalid finalizer",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:  v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				Finalizer: "example.com/clean up",
+			},
+		},
+	}, {
+		name: "Resource TTL without a duration or a param",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:    v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				ResourceTTL: &v1beta1.ResourceTTL{Annotation: "example.com/ttl"},
+			},
+		},
+	}, {
+		name: "Negative resource TTL",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:    v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				ResourceTTL: &v1beta1.ResourceTTL{After: &metav1.Duration{Duration: -time.Hour}},
+			},
+		},
+	}, {
+		name: "Invalid resource TTL param",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:    v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				ResourceTTL: &v1beta1.ResourceTTL{Param: "$(tt.params.ttl)"},
+			},
+		},
+	}, {
+		name: "Resource TTL annotation in the reserved domain",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:    v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				ResourceTTL: &v1beta1.ResourceTTL{Param: "ttl", Annotation: "triggers.tekton.dev/ttl"},
+			},
+		},
+	}, {
+		name: "Promoted extension colliding with body",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:           v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				PromotedExtensions: []string{"body"},
+			},
+		},
+	}, {
+		name: "Promoted extension colliding with header",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:           v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				PromotedExtensions: []string{"header"},
+			},
+		},
+	}, {
+		name: "Promoted extension colliding with cookie",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:           v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				PromotedExtensions: []string{"cookie"},
+			},
+		},
+	}, {
+		name: "Invalid promoted extension",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:           v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				PromotedExtensions: []string{"pr.number"},
+			},
+		},
+	}, {
+		name: "Duplicate promoted extension",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:           v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				PromotedExtensions: []string{"sha", "sha"},
+			},
+		},
+	}, {
+		name: "Invalid default namespace",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:         v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				DefaultNamespace: "Team_A",
+			},
+		},
+	}, {
+		name: "Default kind without a default apiVersion",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:    v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				DefaultKind: "PipelineRun",
+			},
+		},
+	}, {
+		name: "Default resource type not allowed",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:          v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				DefaultAPIVersion: "tekton.dev/v1beta1",
+				DefaultKind:       "Pod",
+			},
+		},
+	}, {
+		name: "Invalid namespace param",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:       v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				NamespaceParam: "$(tt.params.namespace)",
+			},
+		},
+	}, {
+		name: "Invalid resource label key",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:       v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				ResourceLabels: map[string]string{"team name": "platform"},
+			},
+		},
+	}, {
+		name: "Invalid resource label value",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:       v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				ResourceLabels: map[string]string{"team": "platform team"},
+			},
+		},
+	}, {
+		name: "Reserved resource label key",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:       v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				ResourceLabels: map[string]string{"triggers.tekton.dev/trigger": "other"},
+			},
+		},
+	}, {
+		name: "Reserved resource annotation key",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:            v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				ResourceAnnotations: map[string]string{"Triggers.Tekton.dev/patch-strategy": "merge"},
+			},
+		},
+	}, {
+		name: "Too large resource annotations",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template:            v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				ResourceAnnotations: map[string]string{"example.com/notes": strings.Repeat("a", 256*1024)},
+			},
+		},
+	}, {
+		name: "Overlay path not a JSON pointer",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template: v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				Overlays: []v1beta1.TriggerOverlay{{Path: "spec.timeout", Param: "timeout"}},
+			},
+		},
+	}, {
+		name: "Overlay path with invalid escape",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template: v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				Overlays: []v1beta1.TriggerOverlay{{Path: "/metadata/annotations/a~2b", Param: "a"}},
+			},
+		},
+	}, {
+		name: "Overlay with unknown type",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template: v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				Overlays: []v1beta1.TriggerOverlay{{Path: "/spec/timeout", Param: "timeout", Type: "duration"}},
+			},
+		},
+	}, {
+		name: "Overlay without param",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template: v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+				Overlays: []v1beta1.TriggerOverlay{{Path: "/spec/timeout"}},
+			},
+		},
+	}, {
+		name: "Overlay with undeclared param of embedded template",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template: v1beta1.TriggerSpecTemplate{
+					Spec: &v1beta1.TriggerTemplateSpec{
+						Params: []v1beta1.ParamSpec{{Name: "tparam"}},
+						ResourceTemplates: []v1beta1.TriggerResourceTemplate{{
+							RawExtension: test.RawExtension(t, pipelinev1.PipelineRun{
+								TypeMeta: metav1.TypeMeta{
+									APIVersion: "tekton.dev/v1beta1",
+									Kind:       "PipelineRun",
+								},
+							}),
+						}},
+					},
+				},
+				Overlays: []v1beta1.TriggerOverlay{{Path: "/spec/timeout", Param: "other"}},
+			},
+		},
+	}, {
+		name: "Embedded TriggerBinding with unknown context field",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Bindings: []*v1beta1.TriggerSpecBinding{{
+					Name:  "el",
+					Value: ptr.String("$(context.eventListenerName)"),
+				}},
+				Template: v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+			},
+		},
+	}, {
+		name: "Template routing without extension",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template: v1beta1.TriggerSpecTemplate{
+					Ref:     ptr.String("tt"),
+					Routing: &v1beta1.TriggerTemplateRouting{Routes: []v1beta1.TriggerTemplateRoute{{Value: "deploy", Ref: "deploy-tt"}}},
+				},
+			},
+		},
+	}, {
+		name: "Template routing without routes",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template: v1beta1.TriggerSpecTemplate{
+					Ref:     ptr.String("tt"),
+					Routing: &v1beta1.TriggerTemplateRouting{Extension: "type"},
+				},
+			},
+		},
+	}, {
+		name: "Template route without ref",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template: v1beta1.TriggerSpecTemplate{
+					Ref:     ptr.String("tt"),
+					Routing: &v1beta1.TriggerTemplateRouting{Extension: "type", Routes: []v1beta1.TriggerTemplateRoute{{Value: "deploy"}}},
+				},
+			},
+		},
+	}, {
+		name: "Template routes with the same value",
+		tr: &v1beta1.Trigger{
+			ObjectMeta: metav1.ObjectMeta{Name: "name"},
+			Spec: v1beta1.TriggerSpec{
+				Template: v1beta1.TriggerSpecTemplate{
+					Ref: ptr.String("tt"),
+					Routing: &v1beta1.TriggerTemplateRouting{Extension: "type", Routes: []v1beta1.TriggerTemplateRoute{
+						{Value: "deploy", Ref: "deploy-tt"},
+						{Value: "deploy", Ref: "other-tt"},
+					}},
+				},
+			},
+		},
 	}, {
 		name: "Trigger template with invalid spec",
 		tr: &v1beta1.Trigger{
@@ -429,3 +859,209 @@ func TestTriggerValidate_error(t *testing.T) {
 		})
 	}
 }
+
+func TestTriggerValidate_multipleErrors(t *testing.T) {
+	tr := &v1beta1.Trigger{
+		ObjectMeta: metav1.ObjectMeta{Name: "name", Namespace: "namespace"},
+		Spec: v1beta1.TriggerSpec{
+			Bindings: []*v1beta1.TriggerSpecBinding{
+				nil,
+				{Ref: "tb", Kind: "BadKind"},
+			},
+			Template:     v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+			Interceptors: []*v1beta1.TriggerInterceptor{nil},
+		},
+	}
+
+	err := tr.Validate(context.Background())
+	if err == nil {
+		t.Fatal("Trigger.Validate() expected error, but get none")
+	}
+	want := []string{
+		"spec.bindings[0]",
+		"spec.bindings[1].kind",
+		"spec.interceptors[0]",
+	}
+	for _, p := range want {
+		if !strings.Contains(err.Error(), p) {
+			t.Errorf("Trigger.Validate() missing error for %s, got: %v", p, err)
+		}
+	}
+}
+
+func TestTriggerValidate_celExpressions(t *testing.T) {
+	check := func(expr string) error {
+		if strings.Contains(expr, "==") {
+			return nil
+		}
+		return errors.New("ERROR: <input>:1:1: undeclared reference")
+	}
+	celInterceptor := func(params ...v1beta1.InterceptorParams) []*v1beta1.TriggerInterceptor {
+		return []*v1beta1.TriggerInterceptor{{Ref: v1beta1.InterceptorRef{Name: "cel"}, Params: params}}
+	}
+	for _, tc := range []struct {
+		name         string
+		interceptors []*v1beta1.TriggerInterceptor
+		want         *apis.FieldError
+	}{{
+		name: "valid expressions",
+		interceptors: celInterceptor(
+			v1beta1.InterceptorParams{Name: "filter", Value: test.ToV1JSON(t, "body.action == 'push'")},
+			v1beta1.InterceptorParams{Name: "overlays", Value: test.ToV1JSON(t, []v1beta1.CELOverlay{{Key: "push", Expression: "body.action == 'push'"}})},
+		),
+	}, {
+		name:         "invalid filter",
+		interceptors: celInterceptor(v1beta1.InterceptorParams{Name: "filter", Value: test.ToV1JSON(t, "body.action.nope()")}),
+		want:         apis.ErrInvalidValue(`CEL filter "body.action.nope()" doesn't compile: ERROR: <input>:1:1: undeclared reference`, "spec.interceptors[0].params[0].value"),
+	}, {
+		name: "invalid overlay",
+		interceptors: celInterceptor(v1beta1.InterceptorParams{Name: "overlays", Value: test.ToV1JSON(t, []v1beta1.CELOverlay{
+			{Key: "push", Expression: "body.action == 'push'"},
+			{Key: "sha", Expression: "body.sha.nope()"},
+		})}),
+		want: apis.ErrInvalidValue(`CEL overlay sha expression "body.sha.nope()" doesn't compile: ERROR: <input>:1:1: undeclared reference`, "spec.interceptors[0].params[0].value[1].expression"),
+	}, {
+		name:         "filter that isn't a string",
+		interceptors: celInterceptor(v1beta1.InterceptorParams{Name: "filter", Value: test.ToV1JSON(t, 42)}),
+		want:         apis.ErrInvalidValue("CEL filter must be a string: json: cannot unmarshal number into Go value of type string", "spec.interceptors[0].params[0].value"),
+	}, {
+		name: "other interceptors are not checked",
+		interceptors: []*v1beta1.TriggerInterceptor{{
+			Ref:    v1beta1.InterceptorRef{Name: "github"},
+			Params: []v1beta1.InterceptorParams{{Name: "filter", Value: test.ToV1JSON(t, "body.action.nope()")}},
+		}},
+	}, {
+		name: "valid when expression",
+		interceptors: []*v1beta1.TriggerInterceptor{{
+			Ref:  v1beta1.InterceptorRef{Name: "github"},
+			When: "header.canonical('X-GitHub-Event') == 'push'",
+		}},
+	}, {
+		name: "invalid when expression",
+		interceptors: []*v1beta1.TriggerInterceptor{{
+			Ref:  v1beta1.InterceptorRef{Name: "github"},
+			When: "body.action.nope()",
+		}},
+		want: apis.ErrInvalidValue(`when expression "body.action.nope()" doesn't compile: ERROR: <input>:1:1: undeclared reference`, "spec.interceptors[0].when"),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := &v1beta1.Trigger{
+				ObjectMeta: metav1.ObjectMeta{Name: "name", Namespace: "namespace"},
+				Spec: v1beta1.TriggerSpec{
+					Template:     v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+					Interceptors: tc.interceptors,
+				},
+			}
+			got := tr.Validate(contexts.WithCELCheck(context.Background(), check))
+			if diff := cmp.Diff(tc.want.Error(), got.Error()); diff != "" {
+				t.Errorf("Trigger.Validate() (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestTriggerValidate_clusterInterceptorExists(t *testing.T) {
+	exists := func(ctx context.Context, name string) (bool, error) {
+		switch name {
+		case "cel":
+			return true, nil
+		case "unreachable":
+			return false, errors.New("connection refused")
+		}
+		return false, nil
+	}
+	for _, tc := range []struct {
+		name         string
+		interceptors []*v1beta1.TriggerInterceptor
+		want         *apis.FieldError
+	}{{
+		name: "existing ClusterInterceptor",
+		interceptors: []*v1beta1.TriggerInterceptor{{
+			Ref: v1beta1.InterceptorRef{Name: "cel", Kind: v1beta1.ClusterInterceptorKind},
+		}},
+	}, {
+		name: "missing ClusterInterceptor",
+		interceptors: []*v1beta1.TriggerInterceptor{{
+			Ref: v1beta1.InterceptorRef{Name: "cel"},
+		}, {
+			Ref: v1beta1.InterceptorRef{Name: "missing"},
+		}},
+		want: apis.ErrGeneric(`ClusterInterceptor "missing" does not exist`, "spec.interceptors[1].ref.name"),
+	}, {
+		name: "failed lookups are ignored",
+		interceptors: []*v1beta1.TriggerInterceptor{{
+			Ref: v1beta1.InterceptorRef{Name: "unreachable", Kind: v1beta1.ClusterInterceptorKind},
+		}},
+	}, {
+		name: "namespaced interceptors are not looked up",
+		interceptors: []*v1beta1.TriggerInterceptor{{
+			Ref: v1beta1.InterceptorRef{Name: "missing", Kind: v1beta1.NamespacedInterceptorKind},
+		}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := &v1beta1.Trigger{
+				ObjectMeta: metav1.ObjectMeta{Name: "name", Namespace: "namespace"},
+				Spec: v1beta1.TriggerSpec{
+					Template:     v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+					Interceptors: tc.interceptors,
+				},
+			}
+			got := tr.Validate(contexts.WithClusterInterceptorExists(context.Background(), exists))
+			if diff := cmp.Diff(tc.want.Error(), got.Error()); diff != "" {
+				t.Errorf("Trigger.Validate() (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestTriggerValidate_clientProfile(t *testing.T) {
+	withProfiles := config.ToContext(context.Background(), &config.Config{
+		ClientProfiles: &config.ClientProfiles{Profiles: map[string]config.ClientProfile{
+			"remote":   {AllowedNamespaces: []string{"namespace"}},
+			"deployer": {AllowedNamespaces: []string{"deploy"}},
+		}},
+	})
+	for _, tc := range []struct {
+		name    string
+		ctx     context.Context
+		profile string
+		want    *apis.FieldError
+	}{{
+		name:    "defined profile",
+		ctx:     withProfiles,
+		profile: "remote",
+	}, {
+		name:    "undefined profile",
+		ctx:     withProfiles,
+		profile: "missing",
+		want:    apis.ErrInvalidValue(`client profile "missing" is not defined in ConfigMap config-client-profiles-triggers`, "spec.clientProfile"),
+	}, {
+		name:    "profile not allowed in the namespace",
+		ctx:     withProfiles,
+		profile: "deployer",
+		want:    apis.ErrInvalidValue(`client profile "deployer" is not allowed in namespace namespace`, "spec.clientProfile"),
+	}, {
+		name:    "profiles are not checked without a configuration",
+		ctx:     context.Background(),
+		profile: "missing",
+	}, {
+		name:    "invalid profile name",
+		ctx:     context.Background(),
+		profile: "Remote",
+		want:    apis.ErrInvalidValue(`client profile "Remote" must be a valid profile name: a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`, "spec.clientProfile"),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := &v1beta1.Trigger{
+				ObjectMeta: metav1.ObjectMeta{Name: "name", Namespace: "namespace"},
+				Spec: v1beta1.TriggerSpec{
+					Template:      v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+					ClientProfile: tc.profile,
+				},
+			}
+			got := tr.Validate(tc.ctx)
+			if diff := cmp.Diff(tc.want.Error(), got.Error()); diff != "" {
+				t.Errorf("Trigger.Validate() (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -37,6 +37,11 @@ func (in *BitbucketInterceptor) DeepCopyInto(out *BitbucketInterceptor) {
 		*out = new(SecretRef)
 		**out = **in
 	}
+	if in.AdditionalSecretRefs != nil {
+		in, out := &in.AdditionalSecretRefs, &out.AdditionalSecretRefs
+		*out = make([]SecretRef, len(*in))
+		copy(*out, *in)
+	}
 	if in.EventTypes != nil {
 		in, out := &in.EventTypes, &out.EventTypes
 		*out = make([]string, len(*in))
@@ -92,6 +97,37 @@ func (in *CELOverlay) DeepCopy() *CELOverlay {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientCertificate) DeepCopyInto(out *ClientCertificate) {
+	*out = *in
+	if in.DNSNames != nil {
+		in, out := &in.DNSNames, &out.DNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.URIs != nil {
+		in, out := &in.URIs, &out.URIs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EmailAddresses != nil {
+		in, out := &in.EmailAddresses, &out.EmailAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientCertificate.
+func (in *ClientCertificate) DeepCopy() *ClientCertificate {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientCertificate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterTriggerBinding) DeepCopyInto(out *ClusterTriggerBinding) {
 	*out = *in
@@ -198,6 +234,23 @@ func (in *EventListener) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EventListenerActivity) DeepCopyInto(out *EventListenerActivity) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventListenerActivity.
+func (in *EventListenerActivity) DeepCopy() *EventListenerActivity {
+	if in == nil {
+		return nil
+	}
+	out := new(EventListenerActivity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EventListenerConfig) DeepCopyInto(out *EventListenerConfig) {
 	*out = *in
@@ -271,6 +324,16 @@ func (in *EventListenerSpec) DeepCopyInto(out *EventListenerSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.Resources.DeepCopyInto(&out.Resources)
+	if in.ResourceKinds != nil {
+		in, out := &in.ResourceKinds, &out.ResourceKinds
+		*out = new(ResourceKindPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CreateStrategies != nil {
+		in, out := &in.CreateStrategies, &out.CreateStrategies
+		*out = make([]ResourceCreateStrategy, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -290,6 +353,13 @@ func (in *EventListenerStatus) DeepCopyInto(out *EventListenerStatus) {
 	in.Status.DeepCopyInto(&out.Status)
 	in.AddressStatus.DeepCopyInto(&out.AddressStatus)
 	out.Configuration = in.Configuration
+	if in.RecentActivity != nil {
+		in, out := &in.RecentActivity, &out.RecentActivity
+		*out = make([]EventListenerActivity, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -333,6 +403,37 @@ func (in *EventListenerTrigger) DeepCopyInto(out *EventListenerTrigger) {
 			}
 		}
 	}
+	if in.PromotedExtensions != nil {
+		in, out := &in.PromotedExtensions, &out.PromotedExtensions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceLabels != nil {
+		in, out := &in.ResourceLabels, &out.ResourceLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ResourceAnnotations != nil {
+		in, out := &in.ResourceAnnotations, &out.ResourceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Overlays != nil {
+		in, out := &in.Overlays, &out.Overlays
+		*out = make([]TriggerOverlay, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResourceTTL != nil {
+		in, out := &in.ResourceTTL, &out.ResourceTTL
+		*out = new(ResourceTTL)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -404,11 +505,19 @@ func (in *GitHubInterceptor) DeepCopyInto(out *GitHubInterceptor) {
 		*out = new(SecretRef)
 		**out = **in
 	}
+	if in.AdditionalSecretRefs != nil {
+		in, out := &in.AdditionalSecretRefs, &out.AdditionalSecretRefs
+		*out = make([]SecretRef, len(*in))
+		copy(*out, *in)
+	}
 	if in.EventTypes != nil {
 		in, out := &in.EventTypes, &out.EventTypes
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	in.AddChangedFiles.DeepCopyInto(&out.AddChangedFiles)
+	in.VerifyCommits.DeepCopyInto(&out.VerifyCommits)
+	in.ExpandCommits.DeepCopyInto(&out.ExpandCommits)
 	return
 }
 
@@ -430,6 +539,11 @@ func (in *GitLabInterceptor) DeepCopyInto(out *GitLabInterceptor) {
 		*out = new(SecretRef)
 		**out = **in
 	}
+	if in.AdditionalSecretRefs != nil {
+		in, out := &in.AdditionalSecretRefs, &out.AdditionalSecretRefs
+		*out = make([]SecretRef, len(*in))
+		copy(*out, *in)
+	}
 	if in.EventTypes != nil {
 		in, out := &in.EventTypes, &out.EventTypes
 		*out = make([]string, len(*in))
@@ -448,6 +562,85 @@ func (in *GitLabInterceptor) DeepCopy() *GitLabInterceptor {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubAddChangedFiles) DeepCopyInto(out *GithubAddChangedFiles) {
+	*out = *in
+	if in.PersonalAccessToken != nil {
+		in, out := &in.PersonalAccessToken, &out.PersonalAccessToken
+		*out = new(SecretRef)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubAddChangedFiles.
+func (in *GithubAddChangedFiles) DeepCopy() *GithubAddChangedFiles {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubAddChangedFiles)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubExpandCommits) DeepCopyInto(out *GithubExpandCommits) {
+	*out = *in
+	if in.PersonalAccessToken != nil {
+		in, out := &in.PersonalAccessToken, &out.PersonalAccessToken
+		*out = new(SecretRef)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubExpandCommits.
+func (in *GithubExpandCommits) DeepCopy() *GithubExpandCommits {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubExpandCommits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GithubVerifyCommits) DeepCopyInto(out *GithubVerifyCommits) {
+	*out = *in
+	if in.PersonalAccessToken != nil {
+		in, out := &in.PersonalAccessToken, &out.PersonalAccessToken
+		*out = new(SecretRef)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubVerifyCommits.
+func (in *GithubVerifyCommits) DeepCopy() *GithubVerifyCommits {
+	if in == nil {
+		return nil
+	}
+	out := new(GithubVerifyCommits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoTemplateRef) DeepCopyInto(out *GoTemplateRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoTemplateRef.
+func (in *GoTemplateRef) DeepCopy() *GoTemplateRef {
+	if in == nil {
+		return nil
+	}
+	out := new(GoTemplateRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InterceptorParams) DeepCopyInto(out *InterceptorParams) {
 	*out = *in
@@ -566,6 +759,107 @@ func (in *ParamSpec) DeepCopy() *ParamSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceCreateStrategy) DeepCopyInto(out *ResourceCreateStrategy) {
+	*out = *in
+	out.ResourceKind = in.ResourceKind
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceCreateStrategy.
+func (in *ResourceCreateStrategy) DeepCopy() *ResourceCreateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceCreateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceKind) DeepCopyInto(out *ResourceKind) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceKind.
+func (in *ResourceKind) DeepCopy() *ResourceKind {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceKind)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceKindPolicy) DeepCopyInto(out *ResourceKindPolicy) {
+	*out = *in
+	if in.Allowed != nil {
+		in, out := &in.Allowed, &out.Allowed
+		*out = make([]ResourceKind, len(*in))
+		copy(*out, *in)
+	}
+	if in.Denied != nil {
+		in, out := &in.Denied, &out.Denied
+		*out = make([]ResourceKind, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceKindPolicy.
+func (in *ResourceKindPolicy) DeepCopy() *ResourceKindPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceKindPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceTTL) DeepCopyInto(out *ResourceTTL) {
+	*out = *in
+	if in.After != nil {
+		in, out := &in.After, &out.After
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTTL.
+func (in *ResourceTTL) DeepCopy() *ResourceTTL {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceTTL)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceTemplateSelection) DeepCopyInto(out *ResourceTemplateSelection) {
+	*out = *in
+	if in.Weights != nil {
+		in, out := &in.Weights, &out.Weights
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateSelection.
+func (in *ResourceTemplateSelection) DeepCopy() *ResourceTemplateSelection {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceTemplateSelection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Resources) DeepCopyInto(out *Resources) {
 	*out = *in
@@ -769,6 +1063,11 @@ func (in *TriggerBindingStatus) DeepCopy() *TriggerBindingStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TriggerContext) DeepCopyInto(out *TriggerContext) {
 	*out = *in
+	if in.ClientCert != nil {
+		in, out := &in.ClientCert, &out.ClientCert
+		*out = new(ClientCertificate)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -849,6 +1148,27 @@ func (in *TriggerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerOverlay) DeepCopyInto(out *TriggerOverlay) {
+	*out = *in
+	if in.ResourceIndex != nil {
+		in, out := &in.ResourceIndex, &out.ResourceIndex
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerOverlay.
+func (in *TriggerOverlay) DeepCopy() *TriggerOverlay {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerOverlay)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TriggerResourceTemplate) DeepCopyInto(out *TriggerResourceTemplate) {
 	*out = *in
@@ -892,6 +1212,37 @@ func (in *TriggerSpec) DeepCopyInto(out *TriggerSpec) {
 			}
 		}
 	}
+	if in.PromotedExtensions != nil {
+		in, out := &in.PromotedExtensions, &out.PromotedExtensions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceLabels != nil {
+		in, out := &in.ResourceLabels, &out.ResourceLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ResourceAnnotations != nil {
+		in, out := &in.ResourceAnnotations, &out.ResourceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Overlays != nil {
+		in, out := &in.Overlays, &out.Overlays
+		*out = make([]TriggerOverlay, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResourceTTL != nil {
+		in, out := &in.ResourceTTL, &out.ResourceTTL
+		*out = new(ResourceTTL)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -939,6 +1290,11 @@ func (in *TriggerSpecTemplate) DeepCopyInto(out *TriggerSpecTemplate) {
 		*out = new(TriggerTemplateSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Routing != nil {
+		in, out := &in.Routing, &out.Routing
+		*out = new(TriggerTemplateRouting)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1013,6 +1369,43 @@ func (in *TriggerTemplateList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerTemplateRoute) DeepCopyInto(out *TriggerTemplateRoute) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerTemplateRoute.
+func (in *TriggerTemplateRoute) DeepCopy() *TriggerTemplateRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerTemplateRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerTemplateRouting) DeepCopyInto(out *TriggerTemplateRouting) {
+	*out = *in
+	if in.Routes != nil {
+		in, out := &in.Routes, &out.Routes
+		*out = make([]TriggerTemplateRoute, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerTemplateRouting.
+func (in *TriggerTemplateRouting) DeepCopy() *TriggerTemplateRouting {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerTemplateRouting)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TriggerTemplateSpec) DeepCopyInto(out *TriggerTemplateSpec) {
 	*out = *in
@@ -1030,6 +1423,16 @@ func (in *TriggerTemplateSpec) DeepCopyInto(out *TriggerTemplateSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Selection != nil {
+		in, out := &in.Selection, &out.Selection
+		*out = new(ResourceTemplateSelection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GoTemplates != nil {
+		in, out := &in.GoTemplates, &out.GoTemplates
+		*out = make([]GoTemplateRef, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
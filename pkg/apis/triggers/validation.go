@@ -17,21 +17,927 @@ limitations under the License.
 package triggers
 
 import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"golang.org/x/net/http/httpguts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 )
 
 const (
 	PayloadValidationAnnotation = "tekton.dev/payload-validation"
+	// BackpressureMaxInFlightAnnotation is the number of concurrent resource creations above which
+	// the EventListener rejects events with 503 Service Unavailable. Backpressure is disabled if unset.
+	BackpressureMaxInFlightAnnotation = "tekton.dev/backpressure-max-in-flight"
+	// BackpressureRetryAfterAnnotation is the number of seconds senders are asked to wait before
+	// retrying an event rejected due to backpressure.
+	BackpressureRetryAfterAnnotation = "tekton.dev/backpressure-retry-after"
+	// InterceptorTimeoutAnnotation is the total time budget, as a duration e.g. "10s", for executing
+	// the interceptor chain of a trigger.
+	InterceptorTimeoutAnnotation = "tekton.dev/interceptor-timeout"
+	// AllowedMethodsAnnotation is a comma separated list of the HTTP methods accepted by the EventListener.
+	AllowedMethodsAnnotation = "tekton.dev/allowed-methods"
+	// AllowedContentTypesAnnotation is a comma separated list of the media types, optionally with
+	// wildcards e.g. application/*+json, accepted by the EventListener when payload validation is enabled.
+	AllowedContentTypesAnnotation = "tekton.dev/allowed-content-types"
+	// CreationLimitAnnotation is the number of resources each replica of the EventListener can create
+	// per creation limit window. Triggers that would exceed it skip resource creation. The limit is
+	// disabled if unset.
+	CreationLimitAnnotation = "tekton.dev/creation-limit"
+	// CreationLimitWindowAnnotation is the length of the creation limit window, as a duration e.g. "1m".
+	CreationLimitWindowAnnotation = "tekton.dev/creation-limit-window"
+	// CreationLimitPerTriggerAnnotation, if "true", applies the creation limit to each trigger separately
+	// instead of to all triggers of the EventListener combined.
+	CreationLimitPerTriggerAnnotation = "tekton.dev/creation-limit-per-trigger"
+	// MaxCreatesPerEventAnnotation is the number of resources the EventListener can create for a single
+	// event, across all of its triggers. Triggers that would exceed it create none of their resources.
+	// Defaults to 100.
+	MaxCreatesPerEventAnnotation = "tekton.dev/max-creates-per-event"
+	// CreateTimeoutAnnotation is the time, as a duration e.g. "30s", after which the creation of a
+	// resource is abandoned. Defaults to two minutes.
+	CreateTimeoutAnnotation = "tekton.dev/create-timeout"
+	// QuotaRetryWindowAnnotation is how long, as a duration e.g. "5m", the EventListener retries the
+	// creates that exceed a ResourceQuota before dropping them. They are not retried if unset.
+	QuotaRetryWindowAnnotation = "tekton.dev/quota-retry-window"
+	// QuotaRetryMaxQueuedAnnotation is the number of creates that the EventListener can retry at a time.
+	// Defaults to 100.
+	QuotaRetryMaxQueuedAnnotation = "tekton.dev/quota-retry-max-queued"
+	// QuotaRetryPersistAnnotation, if "true", makes the EventListener persist the creates it retries because
+	// they exceed a ResourceQuota in Secrets, and replay them when it restarts.
+	QuotaRetryPersistAnnotation = "tekton.dev/quota-retry-persist"
+	// SinkPortAnnotation is the port the EventListener container listens on. Defaults to 8080.
+	SinkPortAnnotation = "tekton.dev/sink-port"
+	// H2CAnnotation, if "true", lets the EventListener serve HTTP/2 over cleartext connections in
+	// addition to HTTP/1.1.
+	H2CAnnotation = "tekton.dev/h2c"
+	// ActivityIntervalAnnotation is the minimum time, as a duration e.g. "30s", between two updates of
+	// the recent activity in the EventListener's status. Recording recent activity is disabled if unset.
+	ActivityIntervalAnnotation = "tekton.dev/activity-interval"
+	// ProvenanceLabelsAnnotation is a comma separated list of the provenance labels the EventListener
+	// adds to the resources it creates: "eventlistener", "eventid" and "trigger", or "none" to add
+	// none of them. All three are added if unset.
+	ProvenanceLabelsAnnotation = "tekton.dev/provenance-labels"
+	// AccessLogAnnotation, if "true", makes the EventListener write an access log entry for each
+	// incoming request, separately from its application logs.
+	AccessLogAnnotation = "tekton.dev/access-log"
+	// AccessLogFieldsAnnotation is a comma separated list of the fields of the access log entries.
+	// Defaults to DefaultAccessLogFields, which leaves out the payload.
+	AccessLogFieldsAnnotation = "tekton.dev/access-log-fields"
+	// AccessLogSampleRateAnnotation is the fraction, between 0 and 1, of the successfully handled
+	// requests that get an access log entry. Rejected requests are always logged. Defaults to 1.
+	AccessLogSampleRateAnnotation = "tekton.dev/access-log-sample-rate"
+	// BasePathAnnotation is the path prefix under which a reverse proxy exposes the EventListener, e.g.
+	// "/webhooks/tekton". The prefix is stripped from the requests that still carry it, and kept in the
+	// request URL passed to interceptors.
+	BasePathAnnotation = "tekton.dev/base-path"
+	// EventIDHeadersAnnotation is a comma separated list of headers, e.g. "X-GitHub-Delivery", holding the
+	// event ID of requests. The first one present in a request is used instead of a generated UUID.
+	EventIDHeadersAnnotation = "tekton.dev/event-id-headers"
+	// EventIDExpressionAnnotation is a CEL expression returning the event ID of requests that have none
+	// of the EventIDHeadersAnnotation headers, evaluated against their body and headers like a CEL
+	// interceptor filter.
+	EventIDExpressionAnnotation = "tekton.dev/event-id-expression"
+	// TrustedProxiesAnnotation is a comma separated list of the CIDRs or IP addresses of the proxies, e.g. load
+	// balancers, in front of the EventListener. The client IP of the requests they forward is taken from the
+	// TrustedProxyHeaderAnnotation header rather than from the connection. Forwarding headers are ignored if unset.
+	TrustedProxiesAnnotation = "tekton.dev/trusted-proxies"
+	// TrustedProxyHeaderAnnotation is the forwarding header that the TrustedProxiesAnnotation proxies set:
+	// "X-Forwarded-For" or "Forwarded". Only this header is read, since the proxies pass the other one on as the
+	// sender set it. Defaults to "X-Forwarded-For".
+	TrustedProxyHeaderAnnotation = "tekton.dev/trusted-proxy-header"
+	// ProxyProtocolUpstreamsAnnotation is a comma separated list of the CIDRs or IP addresses of the L4 load
+	// balancers in front of the EventListener that send a PROXY protocol (v1 or v2) header at the start of their
+	// connections. The client address of these connections is taken from the header. The connections from other
+	// peers have no header. The PROXY protocol is disabled if unset.
+	ProxyProtocolUpstreamsAnnotation = "tekton.dev/proxy-protocol-upstreams"
+	// FieldValidationAnnotation is how the API server handles unknown and duplicate fields in the
+	// resources the EventListener creates: "Ignore" drops them, "Warn" drops them and logs the warnings
+	// returned by the API server, and "Strict" fails the creation. Defaults to "Warn".
+	FieldValidationAnnotation = "tekton.dev/field-validation"
+	// LabelSanitizationAnnotation is how the EventListener sanitizes the label values of the resources it
+	// creates that are not valid, e.g. values of params or long event IDs: "truncate", "hash" or "reject".
+	// Defaults to "hash".
+	LabelSanitizationAnnotation = "tekton.dev/label-sanitization"
+	// SynchronousAnnotation, if "true", makes the EventListener respond once the Triggers of an event are
+	// processed, with a status code reflecting their outcome, e.g. 201 if resources were created, rather
+	// than with 202 once they are dispatched.
+	SynchronousAnnotation = "tekton.dev/synchronous"
+	// MultiStatusAnnotation, if "true", makes a synchronous EventListener respond with the outcome of each
+	// Trigger and of each of its resources, with 207 if some Triggers failed while resources were created.
+	MultiStatusAnnotation = "tekton.dev/multi-status"
+	// RollbackOnFailureAnnotation, if "true", makes the EventListener delete the resources a Trigger created
+	// for an event when it fails to create the next ones, so that they are created all or none.
+	RollbackOnFailureAnnotation = "tekton.dev/rollback-on-failure"
+	// CreateFailurePolicyAnnotation is whether a Trigger stops at the first resource it fails to create,
+	// "fail-fast", the default, or still creates the resources of the next templates, "best-effort".
+	CreateFailurePolicyAnnotation = "tekton.dev/create-failure-policy"
+	// AuditSinkAnnotation is the URL of the sink the EventListener writes an audit record of each resource
+	// it creates to: a directory, e.g. file:///audit, or an S3-compatible bucket, e.g. s3://bucket/prefix.
+	AuditSinkAnnotation = "tekton.dev/audit-sink"
+	// AuditModeAnnotation is whether the resources are created in the cluster alongside their audit records,
+	// the default, or only written to the audit sink instead.
+	AuditModeAnnotation = "tekton.dev/audit-mode"
+	// AuditFailurePolicyAnnotation is whether failing to write an audit record fails the creation of the
+	// resource, the default, or is only logged.
+	AuditFailurePolicyAnnotation = "tekton.dev/audit-failure-policy"
+	// PayloadParsersAnnotation is a comma separated list of the optional payload parsers the EventListener
+	// applies to request bodies in addition to the JSON one: "form" to convert URL encoded forms into JSON
+	// objects, "gzip" to decompress gzip bodies, and "protobuf" to pass binary protobuf bodies on as base64
+	// JSON strings.
+	PayloadParsersAnnotation = "tekton.dev/payload-parsers"
+	// SelfTestSecretAnnotation is the name of a secret in the namespace of the EventListener whose "token"
+	// key is the bearer token of the requests to its self-test endpoint, which checks that it can create the
+	// kinds of resources of its Triggers. The endpoint is disabled if unset.
+	SelfTestSecretAnnotation = "tekton.dev/self-test-secret"
+	// ConfigSecretAnnotation is the name of a secret in the namespace of the EventListener whose "token" key is
+	// the bearer token of the requests to its config endpoint, which returns the configuration of its Triggers
+	// as it resolves them, with their credentials redacted. The endpoint is disabled if unset.
+	ConfigSecretAnnotation = "tekton.dev/config-secret"
+	// BatchSizeAnnotation is the maximum number of events of the requests to the batch endpoint of the
+	// EventListener, which accepts a JSON array of events. The endpoint is disabled if unset.
+	BatchSizeAnnotation = "tekton.dev/batch-size"
+	// CallbackURLAnnotation is the http or https URL the EventListener POSTs a notification to, without
+	// waiting for it, each time a Trigger creates resources. Failed notifications are logged and counted,
+	// but don't fail the event. Callbacks are disabled if unset.
+	CallbackURLAnnotation = "tekton.dev/callback-url"
+	// CallbackPayloadAnnotation is the JSON template of the notifications, whose strings can reference the
+	// event and the created resources with $() JSONPath expressions. Defaults to the event ID, the
+	// EventListener, the Trigger and the created resources.
+	CallbackPayloadAnnotation = "tekton.dev/callback-payload"
+	// CallbackSecretAnnotation is the name of a secret in the namespace of the EventListener whose "token"
+	// key is sent as the bearer token of the notifications.
+	CallbackSecretAnnotation = "tekton.dev/callback-secret"
+	// CallbackTimeoutAnnotation is the timeout of each attempt to send a notification. Defaults to 10s.
+	CallbackTimeoutAnnotation = "tekton.dev/callback-timeout"
+	// CallbackRetriesAnnotation is the number of times a notification that failed is sent again, with an
+	// exponential backoff. Defaults to 3.
+	CallbackRetriesAnnotation = "tekton.dev/callback-retries"
+	// ResourcePoliciesAnnotation is a comma separated list of ConfigMaps in the namespace of the EventListener
+	// holding policies, CEL constraints that the resources the EventListener creates are checked against
+	// before they are sent to the API server.
+	ResourcePoliciesAnnotation = "tekton.dev/resource-policies"
+	// MutationHookURLAnnotation is the http or https URL the EventListener POSTs each resource to before
+	// creating it, and which responds with the resource to create instead, e.g. with org-wide defaults
+	// injected. The hook is disabled if unset.
+	MutationHookURLAnnotation = "tekton.dev/mutation-hook-url"
+	// MutationHookTimeoutAnnotation is the timeout of each call to the mutation hook. Defaults to 10s.
+	MutationHookTimeoutAnnotation = "tekton.dev/mutation-hook-timeout"
+	// MutationHookFailurePolicyAnnotation is whether a failed call to the mutation hook fails the creation
+	// of the resource, "fail-closed", the default, or creates it unmodified, "fail-open".
+	MutationHookFailurePolicyAnnotation = "tekton.dev/mutation-hook-failure-policy"
+	// DebugTraceSecretAnnotation is the name of a secret in the namespace of the EventListener whose "token"
+	// key, sent in the Tekton-Debug-Trace header of a request, makes the EventListener log a debug trace of the
+	// processing of its event: its payload, the requests and responses of the interceptors, the resolved params
+	// and the rendered resources. Requests can't ask for a trace if unset.
+	DebugTraceSecretAnnotation = "tekton.dev/debug-trace-secret"
+	// DebugTraceSampleRateAnnotation is the fraction, between 0 and 1, of the events that get a debug trace
+	// without asking for it. Defaults to 0.
+	DebugTraceSampleRateAnnotation = "tekton.dev/debug-trace-sample-rate"
+	// InterceptorMetricsNamesAnnotation is the number of distinct interceptor names that tag the interceptor
+	// metrics, which bounds their cardinality. The interceptors seen once the limit is reached are tagged with
+	// the name "other". Defaults to 20.
+	InterceptorMetricsNamesAnnotation = "tekton.dev/interceptor-metrics-names"
+	// ResourceMetricsNamespacesAnnotation is the number of distinct namespaces that tag the metric of the
+	// created resources, which bounds its cardinality. The namespaces seen once the limit is reached are
+	// tagged "other", so "0" aggregates all of them. All the namespaces are tagged if unset.
+	ResourceMetricsNamespacesAnnotation = "tekton.dev/resource-metrics-namespaces"
+	// NoMatchPolicyAnnotation is how the EventListener responds to the events that match no Triggers:
+	// "ignore" responds with 202 Accepted, the default, and "error" responds with 422 Unprocessable Entity,
+	// which surfaces misconfigurations such as a renamed event type to the senders.
+	NoMatchPolicyAnnotation = "tekton.dev/no-match-policy"
+	// NoMatchLogLevelAnnotation is the level at which the EventListener logs the events that match no
+	// Triggers: "debug", "info", "warn" or "error". Defaults to "info".
+	NoMatchLogLevelAnnotation = "tekton.dev/no-match-log-level"
+	// DiscoveryGracePeriodAnnotation is how long, as a duration e.g. "2m", the EventListener waits for the
+	// discovery API of the API server to be available at startup before exiting. Defaults to two minutes.
+	DiscoveryGracePeriodAnnotation = "tekton.dev/discovery-grace-period"
+	// DiscoveryBackoffAnnotation is the delay, as a duration e.g. "1s", before the EventListener tries to
+	// reach an unavailable discovery API again, doubled after each failed attempt up to 30 seconds.
+	// Defaults to one second.
+	DiscoveryBackoffAnnotation = "tekton.dev/discovery-backoff"
+	// ResourceEventsAnnotation is a comma separated list of the outcomes of resource creations, "created"
+	// and "failed", for which the EventListener sends a CloudEvent describing the resource, of type
+	// dev.tekton.triggers.resource.created or dev.tekton.triggers.resource.failed. No CloudEvents are sent
+	// for resources if unset.
+	ResourceEventsAnnotation = "tekton.dev/resource-events"
+	// ResourceEventsURIAnnotation is the http or https URL of the sink of the CloudEvents of resources.
+	// Defaults to the cloudEventURI of the EventListener.
+	ResourceEventsURIAnnotation = "tekton.dev/resource-events-uri"
+	// ResourceEventsSourceAnnotation is the source of the CloudEvents of resources, a URI reference.
+	// Defaults to the path of the EventListener.
+	ResourceEventsSourceAnnotation = "tekton.dev/resource-events-source"
+	// EventWorkersAnnotation is the number of events the EventListener processes at a time, from their receipt
+	// until all of their Triggers are processed. The number of events is not bounded if unset.
+	EventWorkersAnnotation = "tekton.dev/event-workers"
+	// EventQueueSizeAnnotation is the number of events that wait for a worker while all the event workers are
+	// busy. Defaults to 0.
+	EventQueueSizeAnnotation = "tekton.dev/event-queue-size"
+	// EventOverflowAnnotation is what happens to the events that don't fit in the queue of the event workers:
+	// "reject" responds with 429 Too Many Requests and a Retry-After header, the default, and "wait" makes them
+	// wait for a worker as well.
+	EventOverflowAnnotation = "tekton.dev/event-overflow"
 )
 
+// MaxBatchSize is the largest value of the BatchSizeAnnotation.
+const MaxBatchSize = 1000
+
+// MaxCallbackRetries is the largest value of the CallbackRetriesAnnotation.
+const MaxCallbackRetries = 10
+
+// EventListenerMetricsPort is the port the EventListener container serves its metrics on, which the
+// SinkPortAnnotation can't be set to.
+const EventListenerMetricsPort = 9000
+
+// ValidateCallbackURL checks that value, the value of the CallbackURLAnnotation, is an http or https URL.
+func ValidateCallbackURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid callback URL %q: %w", value, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("callback URL %q must be an http or https URL", value)
+	}
+	return nil
+}
+
+const (
+	// ResourceEventCreated selects the CloudEvents of the created resources.
+	ResourceEventCreated = "created"
+	// ResourceEventFailed selects the CloudEvents of the resources that could not be created.
+	ResourceEventFailed = "failed"
+)
+
+// ParseResourceEvents returns whether the value of the ResourceEventsAnnotation selects the CloudEvents
+// of the created resources, and of the resources that could not be created.
+func ParseResourceEvents(value string) (created, failed bool, err error) {
+	for _, name := range strings.Split(value, ",") {
+		switch strings.TrimSpace(name) {
+		case ResourceEventCreated:
+			created = true
+		case ResourceEventFailed:
+			failed = true
+		default:
+			return false, false, fmt.Errorf("unknown resource event %q: must be %s or %s", strings.TrimSpace(name), ResourceEventCreated, ResourceEventFailed)
+		}
+	}
+	return created, failed, nil
+}
+
+// ValidateResourceEventsURI checks that value, the value of the ResourceEventsURIAnnotation, is an http or
+// https URL.
+func ValidateResourceEventsURI(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid resource events URI %q: %w", value, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("resource events URI %q must be an http or https URL", value)
+	}
+	return nil
+}
+
+// ValidateResourceEventsSource checks that value, the value of the ResourceEventsSourceAnnotation, is a
+// non-empty URI reference, as required of the source of CloudEvents.
+func ValidateResourceEventsSource(value string) error {
+	if value == "" {
+		return errors.New("must not be empty")
+	}
+	if _, err := url.Parse(value); err != nil {
+		return fmt.Errorf("must be a URI reference: %w", err)
+	}
+	return nil
+}
+
+// ValidateMutationHookURL checks that value, the value of the MutationHookURLAnnotation, is an http or
+// https URL.
+func ValidateMutationHookURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid mutation hook URL %q: %w", value, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("mutation hook URL %q must be an http or https URL", value)
+	}
+	return nil
+}
+
+const (
+	// PayloadParserForm converts application/x-www-form-urlencoded bodies into JSON objects.
+	PayloadParserForm = "form"
+	// PayloadParserGzip decompresses the bodies with a gzip Content-Encoding.
+	PayloadParserGzip = "gzip"
+	// PayloadParserProtobuf converts binary protobuf bodies into JSON strings holding their base64 encoding.
+	PayloadParserProtobuf = "protobuf"
+)
+
+const (
+	// AuditModeAlongside creates the resources in the cluster and writes their audit records.
+	AuditModeAlongside = "alongside"
+	// AuditModeInstead only writes the audit records of the resources, without creating them.
+	AuditModeInstead = "instead"
+
+	// AuditFailureFatal fails the creation of a resource whose audit record can't be written.
+	AuditFailureFatal = "fatal"
+	// AuditFailureBestEffort logs the failures to write audit records.
+	AuditFailureBestEffort = "best-effort"
+
+	// CreateFailureFailFast stops creating the resources of a Trigger at the first one that fails.
+	CreateFailureFailFast = "fail-fast"
+	// CreateFailureBestEffort creates the resources of all the templates of a Trigger, whether or not the
+	// previous ones failed.
+	CreateFailureBestEffort = "best-effort"
+
+	// MutationHookFailClosed fails the creation of a resource if the mutation hook fails.
+	MutationHookFailClosed = "fail-closed"
+	// MutationHookFailOpen logs the failures of the mutation hook and creates the resources unmodified.
+	MutationHookFailOpen = "fail-open"
+)
+
+// ValidateAuditSink checks that value, the value of the AuditSinkAnnotation, is a file URL with an
+// absolute path or an s3 URL with a bucket.
+func ValidateAuditSink(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid audit sink %q: %w", value, err)
+	}
+	switch u.Scheme {
+	case "file":
+		if u.Host != "" || !strings.HasPrefix(u.Path, "/") {
+			return fmt.Errorf("audit sink %q must be a file URL with an absolute path, e.g. file:///audit", value)
+		}
+	case "s3":
+		if u.Host == "" {
+			return fmt.Errorf("audit sink %q must name a bucket, e.g. s3://bucket/prefix", value)
+		}
+		if endpoint := u.Query().Get("endpoint"); endpoint != "" {
+			if e, err := url.Parse(endpoint); err != nil || (e.Scheme != "https" && e.Scheme != "http") || e.Host == "" {
+				return fmt.Errorf("endpoint of audit sink %q must be an http or https URL", value)
+			}
+		}
+	default:
+		return fmt.Errorf("audit sink %q must be a file or s3 URL", value)
+	}
+	return nil
+}
+
+// ValidateAuditMode checks that value is the value of the AuditModeAnnotation.
+func ValidateAuditMode(value string) error {
+	if value != AuditModeAlongside && value != AuditModeInstead {
+		return fmt.Errorf("must be %s or %s", AuditModeAlongside, AuditModeInstead)
+	}
+	return nil
+}
+
+// ValidateAuditFailurePolicy checks that value is the value of the AuditFailurePolicyAnnotation.
+func ValidateAuditFailurePolicy(value string) error {
+	if value != AuditFailureFatal && value != AuditFailureBestEffort {
+		return fmt.Errorf("must be %s or %s", AuditFailureFatal, AuditFailureBestEffort)
+	}
+	return nil
+}
+
+// ValidateMutationHookFailurePolicy checks that value is the value of the MutationHookFailurePolicyAnnotation.
+func ValidateMutationHookFailurePolicy(value string) error {
+	if value != MutationHookFailClosed && value != MutationHookFailOpen {
+		return fmt.Errorf("must be %s or %s", MutationHookFailClosed, MutationHookFailOpen)
+	}
+	return nil
+}
+
+// ValidateCreateFailurePolicy checks that value is the value of the CreateFailurePolicyAnnotation.
+func ValidateCreateFailurePolicy(value string) error {
+	if value != CreateFailureFailFast && value != CreateFailureBestEffort {
+		return fmt.Errorf("must be %s or %s", CreateFailureFailFast, CreateFailureBestEffort)
+	}
+	return nil
+}
+
+const (
+	// EventOverflowReject rejects the events that don't fit in the queue of the event workers.
+	EventOverflowReject = "reject"
+	// EventOverflowWait makes the events that don't fit in the queue of the event workers wait for a worker.
+	EventOverflowWait = "wait"
+)
+
+// ValidateEventOverflow checks that value is the value of the EventOverflowAnnotation.
+func ValidateEventOverflow(value string) error {
+	if value != EventOverflowReject && value != EventOverflowWait {
+		return fmt.Errorf("must be %s or %s", EventOverflowReject, EventOverflowWait)
+	}
+	return nil
+}
+
+const (
+	// NoMatchIgnore accepts the events that match no Triggers.
+	NoMatchIgnore = "ignore"
+	// NoMatchError rejects the events that match no Triggers.
+	NoMatchError = "error"
+)
+
+// ValidateNoMatchPolicy checks that value is the value of the NoMatchPolicyAnnotation.
+func ValidateNoMatchPolicy(value string) error {
+	if value != NoMatchIgnore && value != NoMatchError {
+		return fmt.Errorf("must be %s or %s", NoMatchIgnore, NoMatchError)
+	}
+	return nil
+}
+
+// ValidateNoMatchLogLevel checks that value is the value of the NoMatchLogLevelAnnotation.
+func ValidateNoMatchLogLevel(value string) error {
+	switch value {
+	case "debug", "info", "warn", "error":
+		return nil
+	}
+	return fmt.Errorf("must be one of debug, info, warn or error")
+}
+
+// ParsePayloadParsers returns the names of the optional payload parsers selected by the value of the
+// PayloadParsersAnnotation.
+func ParsePayloadParsers(value string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name != PayloadParserForm && name != PayloadParserGzip && name != PayloadParserProtobuf {
+			return nil, fmt.Errorf("unknown payload parser %q: must be %s, %s or %s", name, PayloadParserForm, PayloadParserGzip, PayloadParserProtobuf)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// AccessLogPayloadField is the access log field with the payload of the request. It is left out by
+// default given its sensitivity.
+const AccessLogPayloadField = "payload"
+
+// DefaultAccessLogFields are the fields of the access log entries if the AccessLogFieldsAnnotation is unset.
+var DefaultAccessLogFields = []string{"timestamp", "sourceIP", "forwardedFor", "method", "path", "userAgent", "eventID", "triggers", "triggerGroups", "firedTriggers", "status", "duration"}
+
+// ParseAccessLogFields returns the access log fields selected by the value of the AccessLogFieldsAnnotation.
+func ParseAccessLogFields(value string) ([]string, error) {
+	var fields []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if !isAccessLogField(name) {
+			return nil, fmt.Errorf("unknown access log field %q: must be one of %s or %s", name, strings.Join(DefaultAccessLogFields, ", "), AccessLogPayloadField)
+		}
+		fields = append(fields, name)
+	}
+	return fields, nil
+}
+
+// ValidateBasePath checks that value, the value of the BasePathAnnotation, is an absolute URL path
+// without query or fragment.
+func ValidateBasePath(value string) error {
+	if !strings.HasPrefix(value, "/") || strings.HasPrefix(value, "//") {
+		return fmt.Errorf("base path %q must start with a single /", value)
+	}
+	if strings.ContainsAny(value, "?#") {
+		return fmt.Errorf("base path %q must not have a query or fragment", value)
+	}
+	if _, err := url.Parse(value); err != nil {
+		return fmt.Errorf("invalid base path %q: %w", value, err)
+	}
+	return nil
+}
+
+func isAccessLogField(name string) bool {
+	if name == AccessLogPayloadField {
+		return true
+	}
+	for _, f := range DefaultAccessLogFields {
+		if name == f {
+			return true
+		}
+	}
+	return false
+}
+
+// provenanceLabels maps the names accepted by the ProvenanceLabelsAnnotation to the label keys.
+var provenanceLabels = map[string]string{
+	"eventlistener": EventListenerLabelKey,
+	"eventid":       EventIDLabelKey,
+	"trigger":       TriggerLabelKey,
+}
+
+const (
+	// LabelSanitizationTruncate replaces the invalid characters of label values and truncates them.
+	LabelSanitizationTruncate = "truncate"
+	// LabelSanitizationHash replaces the invalid characters of label values and truncates them with a
+	// hash of the original value as suffix, so that distinct values keep distinct labels.
+	LabelSanitizationHash = "hash"
+	// LabelSanitizationReject fails the creation of the resources with invalid label values.
+	LabelSanitizationReject = "reject"
+)
+
+// ValidateLabelSanitization checks that value is a label sanitization strategy.
+func ValidateLabelSanitization(value string) error {
+	switch value {
+	case LabelSanitizationTruncate, LabelSanitizationHash, LabelSanitizationReject:
+		return nil
+	}
+	return fmt.Errorf("must be one of %s, %s or %s", LabelSanitizationTruncate, LabelSanitizationHash, LabelSanitizationReject)
+}
+
+// ValidateFieldValidation checks that value is a field validation directive of the API server.
+func ValidateFieldValidation(value string) error {
+	switch value {
+	case metav1.FieldValidationIgnore, metav1.FieldValidationWarn, metav1.FieldValidationStrict:
+		return nil
+	}
+	return fmt.Errorf("must be one of %s, %s or %s", metav1.FieldValidationIgnore, metav1.FieldValidationWarn, metav1.FieldValidationStrict)
+}
+
+// ParseProvenanceLabels returns the keys, e.g. TriggerLabelKey, of the provenance labels selected by
+// the value of the ProvenanceLabelsAnnotation. The result is empty, but not nil, for "none".
+func ParseProvenanceLabels(value string) ([]string, error) {
+	keys := []string{}
+	if strings.TrimSpace(value) == "none" {
+		return keys, nil
+	}
+	for _, name := range strings.Split(value, ",") {
+		key, ok := provenanceLabels[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown provenance label %q: must be one of eventlistener, eventid and trigger, or none", strings.TrimSpace(name))
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// ParseTrustedProxies returns the networks of the comma separated CIDRs or IP addresses of value, the
+// value of the TrustedProxiesAnnotation or of the ProxyProtocolUpstreamsAnnotation. An IP address is a network of its own, e.g. 10.0.0.1/32.
+func ParseTrustedProxies(value string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, s := range strings.Split(value, ",") {
+		s = strings.TrimSpace(s)
+		if ip := net.ParseIP(s); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: must be a CIDR or an IP address", s)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// The forwarding headers of the TrustedProxyHeaderAnnotation.
+const (
+	XForwardedForHeader = "X-Forwarded-For"
+	ForwardedHeader     = "Forwarded"
+)
+
+// ValidateTrustedProxyHeader checks the forwarding header of the trusted proxies: empty, for the default,
+// X-Forwarded-For or Forwarded (RFC 7239).
+func ValidateTrustedProxyHeader(value string) error {
+	if value != "" && value != XForwardedForHeader && value != ForwardedHeader {
+		return fmt.Errorf("unsupported forwarding header %q: must be %s or %s", value, XForwardedForHeader, ForwardedHeader)
+	}
+	return nil
+}
+
+// JSONLogFormat is the log format of the EventListeners writing their logs as JSON, with stable keys
+// for the fields correlating them with the events.
+const JSONLogFormat = "json"
+
+// ValidateLogFormat checks the log format of the EventListeners: empty, for the format of their
+// logging config, or json.
+func ValidateLogFormat(value string) error {
+	if value != "" && value != JSONLogFormat {
+		return fmt.Errorf("unsupported log format %q: must be %s", value, JSONLogFormat)
+	}
+	return nil
+}
+
+// ParseTLSVersion returns the TLS version of value, 1.2 or 1.3, for the minimum TLS version of the
+// EventListener server and of its clients. Older versions are rejected.
+func ParseTLSVersion(value string) (uint16, error) {
+	switch strings.TrimSpace(value) {
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unsupported TLS version %q: must be 1.2 or 1.3", value)
+}
+
+// ParseTLSCipherSuites returns the IDs of the comma separated cipher suites of value, given by their IANA
+// names, e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256. Only the secure TLS 1.2 cipher suites supported by Go
+// are accepted, since the TLS 1.3 ones are not configurable.
+func ParseTLSCipherSuites(value string) ([]uint16, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, fmt.Errorf("no cipher suites")
+	}
+	secure := map[string]*tls.CipherSuite{}
+	for _, c := range tls.CipherSuites() {
+		secure[c.Name] = c
+	}
+	insecure := map[string]bool{}
+	for _, c := range tls.InsecureCipherSuites() {
+		insecure[c.Name] = true
+	}
+	var ids []uint16
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		c, ok := secure[name]
+		switch {
+		case name == "":
+			return nil, fmt.Errorf("empty cipher suite in %q", value)
+		case insecure[name]:
+			return nil, fmt.Errorf("cipher suite %s is insecure", name)
+		case !ok:
+			return nil, fmt.Errorf("unknown cipher suite %q", name)
+		case !supportsTLS12(c):
+			return nil, fmt.Errorf("cipher suite %s is a TLS 1.3 cipher suite, which is not configurable", name)
+		}
+		ids = append(ids, c.ID)
+	}
+	return ids, nil
+}
+
+func supportsTLS12(c *tls.CipherSuite) bool {
+	for _, v := range c.SupportedVersions {
+		if v == tls.VersionTLS12 {
+			return true
+		}
+	}
+	return false
+}
+
+// methodRegexp matches HTTP method tokens.
+var methodRegexp = regexp.MustCompile(`^[A-Z]+$`)
+
+// annotationPath returns the field path of the annotation with the given key.
+func annotationPath(key string) string {
+	return fmt.Sprintf("metadata.annotations[%s]", key)
+}
+
 func ValidateAnnotations(annotations map[string]string) *apis.FieldError {
 	var errs *apis.FieldError
 
-	if value, ok := annotations[PayloadValidationAnnotation]; ok {
-		if value != "true" && value != "false" {
-			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must have value 'true' or 'false'", PayloadValidationAnnotation), "metadata.annotations"))
+	for _, key := range []string{PayloadValidationAnnotation, CreationLimitPerTriggerAnnotation, H2CAnnotation, AccessLogAnnotation, SynchronousAnnotation, MultiStatusAnnotation, RollbackOnFailureAnnotation, QuotaRetryPersistAnnotation} {
+		if value, ok := annotations[key]; ok {
+			if value != "true" && value != "false" {
+				errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must have value 'true' or 'false'", key), annotationPath(key)))
+			}
+		}
+	}
+
+	for _, key := range []string{BackpressureMaxInFlightAnnotation, BackpressureRetryAfterAnnotation, CreationLimitAnnotation, MaxCreatesPerEventAnnotation, QuotaRetryMaxQueuedAnnotation, EventWorkersAnnotation} {
+		if value, ok := annotations[key]; ok {
+			if n, err := strconv.Atoi(value); err != nil || n <= 0 {
+				errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be a positive integer", key), annotationPath(key)))
+			}
+		}
+	}
+
+	if value, ok := annotations[BatchSizeAnnotation]; ok {
+		if n, err := strconv.Atoi(value); err != nil || n <= 0 || n > MaxBatchSize {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be an integer between 1 and %d", BatchSizeAnnotation, MaxBatchSize), annotationPath(BatchSizeAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[SinkPortAnnotation]; ok {
+		if n, err := strconv.Atoi(value); err != nil || n <= 0 || n > 65535 {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be a port number between 1 and 65535", SinkPortAnnotation), annotationPath(SinkPortAnnotation)))
+		} else if n == EventListenerMetricsPort {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation can't be %d, the port of the metrics of the EventListener", SinkPortAnnotation, EventListenerMetricsPort), annotationPath(SinkPortAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[CallbackRetriesAnnotation]; ok {
+		if n, err := strconv.Atoi(value); err != nil || n < 0 || n > MaxCallbackRetries {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be an integer between 0 and %d", CallbackRetriesAnnotation, MaxCallbackRetries), annotationPath(CallbackRetriesAnnotation)))
+		}
+	}
+
+	for _, key := range []string{InterceptorMetricsNamesAnnotation, ResourceMetricsNamespacesAnnotation, EventQueueSizeAnnotation} {
+		if value, ok := annotations[key]; ok {
+			if n, err := strconv.Atoi(value); err != nil || n < 0 {
+				errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be a non-negative integer", key), annotationPath(key)))
+			}
+		}
+	}
+
+	for _, key := range []string{InterceptorTimeoutAnnotation, CreationLimitWindowAnnotation, CreateTimeoutAnnotation, ActivityIntervalAnnotation, QuotaRetryWindowAnnotation, CallbackTimeoutAnnotation, MutationHookTimeoutAnnotation, DiscoveryGracePeriodAnnotation, DiscoveryBackoffAnnotation} {
+		if value, ok := annotations[key]; ok {
+			if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+				errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be a positive duration", key), annotationPath(key)))
+			}
+		}
+	}
+
+	if value, ok := annotations[AllowedMethodsAnnotation]; ok {
+		for _, m := range strings.Split(value, ",") {
+			if !methodRegexp.MatchString(strings.TrimSpace(m)) {
+				errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be a comma separated list of upper case HTTP methods", AllowedMethodsAnnotation), annotationPath(AllowedMethodsAnnotation)))
+				break
+			}
+		}
+	}
+
+	if value, ok := annotations[ProvenanceLabelsAnnotation]; ok {
+		if _, err := ParseProvenanceLabels(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be a comma separated list of eventlistener, eventid and trigger, or none", ProvenanceLabelsAnnotation), annotationPath(ProvenanceLabelsAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[AccessLogFieldsAnnotation]; ok {
+		if _, err := ParseAccessLogFields(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be a comma separated list of access log fields: %v", AccessLogFieldsAnnotation, err), annotationPath(AccessLogFieldsAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[AccessLogSampleRateAnnotation]; ok {
+		if rate, err := strconv.ParseFloat(value, 64); err != nil || rate < 0 || rate > 1 {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be a number between 0 and 1", AccessLogSampleRateAnnotation), annotationPath(AccessLogSampleRateAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[BasePathAnnotation]; ok {
+		if err := ValidateBasePath(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be an absolute URL path: %v", BasePathAnnotation, err), annotationPath(BasePathAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[EventIDHeadersAnnotation]; ok {
+		for _, h := range strings.Split(value, ",") {
+			if !httpguts.ValidHeaderFieldName(strings.TrimSpace(h)) {
+				errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be a comma separated list of header names", EventIDHeadersAnnotation), annotationPath(EventIDHeadersAnnotation)))
+				break
+			}
+		}
+	}
+
+	if value, ok := annotations[TrustedProxiesAnnotation]; ok {
+		if _, err := ParseTrustedProxies(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be a comma separated list of CIDRs or IP addresses: %v", TrustedProxiesAnnotation, err), annotationPath(TrustedProxiesAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[TrustedProxyHeaderAnnotation]; ok {
+		if err := ValidateTrustedProxyHeader(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be %s or %s", TrustedProxyHeaderAnnotation, XForwardedForHeader, ForwardedHeader), annotationPath(TrustedProxyHeaderAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[ProxyProtocolUpstreamsAnnotation]; ok {
+		if _, err := ParseTrustedProxies(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be a comma separated list of CIDRs or IP addresses: %v", ProxyProtocolUpstreamsAnnotation, err), annotationPath(ProxyProtocolUpstreamsAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[EventIDExpressionAnnotation]; ok && strings.TrimSpace(value) == "" {
+		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must not be empty", EventIDExpressionAnnotation), annotationPath(EventIDExpressionAnnotation)))
+	}
+
+	if value, ok := annotations[FieldValidationAnnotation]; ok {
+		if err := ValidateFieldValidation(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation %v", FieldValidationAnnotation, err), annotationPath(FieldValidationAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[LabelSanitizationAnnotation]; ok {
+		if err := ValidateLabelSanitization(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation %v", LabelSanitizationAnnotation, err), annotationPath(LabelSanitizationAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[NoMatchPolicyAnnotation]; ok {
+		if err := ValidateNoMatchPolicy(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation %v", NoMatchPolicyAnnotation, err), annotationPath(NoMatchPolicyAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[NoMatchLogLevelAnnotation]; ok {
+		if err := ValidateNoMatchLogLevel(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation %v", NoMatchLogLevelAnnotation, err), annotationPath(NoMatchLogLevelAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[AuditSinkAnnotation]; ok {
+		if err := ValidateAuditSink(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation %v", AuditSinkAnnotation, err), annotationPath(AuditSinkAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[AuditModeAnnotation]; ok {
+		if err := ValidateAuditMode(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation %v", AuditModeAnnotation, err), annotationPath(AuditModeAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[AuditFailurePolicyAnnotation]; ok {
+		if err := ValidateAuditFailurePolicy(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation %v", AuditFailurePolicyAnnotation, err), annotationPath(AuditFailurePolicyAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[EventOverflowAnnotation]; ok {
+		if err := ValidateEventOverflow(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation %v", EventOverflowAnnotation, err), annotationPath(EventOverflowAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[CreateFailurePolicyAnnotation]; ok {
+		if err := ValidateCreateFailurePolicy(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation %v", CreateFailurePolicyAnnotation, err), annotationPath(CreateFailurePolicyAnnotation)))
+		}
+	}
+
+	for _, key := range []string{SelfTestSecretAnnotation, ConfigSecretAnnotation, CallbackSecretAnnotation, DebugTraceSecretAnnotation} {
+		if value, ok := annotations[key]; ok {
+			if msgs := validation.IsDNS1123Subdomain(value); len(msgs) > 0 {
+				errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be a secret name: %s", key, strings.Join(msgs, ", ")), annotationPath(key)))
+			}
+		}
+	}
+
+	if value, ok := annotations[DebugTraceSampleRateAnnotation]; ok {
+		if rate, err := strconv.ParseFloat(value, 64); err != nil || rate < 0 || rate > 1 {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be a number between 0 and 1", DebugTraceSampleRateAnnotation), annotationPath(DebugTraceSampleRateAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[ResourcePoliciesAnnotation]; ok {
+		for _, name := range strings.Split(value, ",") {
+			if msgs := validation.IsDNS1123Subdomain(strings.TrimSpace(name)); len(msgs) > 0 {
+				errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be a comma separated list of ConfigMap names: %s", ResourcePoliciesAnnotation, strings.Join(msgs, ", ")), annotationPath(ResourcePoliciesAnnotation)))
+				break
+			}
+		}
+	}
+
+	if value, ok := annotations[CallbackURLAnnotation]; ok {
+		if err := ValidateCallbackURL(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation %v", CallbackURLAnnotation, err), annotationPath(CallbackURLAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[ResourceEventsAnnotation]; ok {
+		if _, _, err := ParseResourceEvents(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be a comma separated list of created and failed: %v", ResourceEventsAnnotation, err), annotationPath(ResourceEventsAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[ResourceEventsURIAnnotation]; ok {
+		if err := ValidateResourceEventsURI(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation %v", ResourceEventsURIAnnotation, err), annotationPath(ResourceEventsURIAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[ResourceEventsSourceAnnotation]; ok {
+		if err := ValidateResourceEventsSource(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation %v", ResourceEventsSourceAnnotation, err), annotationPath(ResourceEventsSourceAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[MutationHookURLAnnotation]; ok {
+		if err := ValidateMutationHookURL(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation %v", MutationHookURLAnnotation, err), annotationPath(MutationHookURLAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[MutationHookFailurePolicyAnnotation]; ok {
+		if err := ValidateMutationHookFailurePolicy(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation %v", MutationHookFailurePolicyAnnotation, err), annotationPath(MutationHookFailurePolicyAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[CallbackPayloadAnnotation]; ok && !json.Valid([]byte(value)) {
+		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be a JSON document", CallbackPayloadAnnotation), annotationPath(CallbackPayloadAnnotation)))
+	}
+
+	if value, ok := annotations[PayloadParsersAnnotation]; ok {
+		if _, err := ParsePayloadParsers(value); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be a comma separated list of payload parsers: %v", PayloadParsersAnnotation, err), annotationPath(PayloadParsersAnnotation)))
+		}
+	}
+
+	if value, ok := annotations[AllowedContentTypesAnnotation]; ok {
+		for _, ct := range strings.Split(value, ",") {
+			ct = strings.TrimSpace(ct)
+			if _, err := path.Match(ct, ""); err != nil || strings.Count(ct, "/") != 1 {
+				errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s annotation must be a comma separated list of media types", AllowedContentTypesAnnotation), annotationPath(AllowedContentTypesAnnotation)))
+				break
+			}
 		}
 	}
 
@@ -17,7 +17,10 @@ limitations under the License.
 package triggers
 
 import (
+	"crypto/tls"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func Test_PayloadValidationAnnotation_Valid(t *testing.T) {
@@ -35,3 +38,757 @@ func Test_PayloadValidationAnnotation_InvalidValue(t *testing.T) {
 		t.Errorf("Expected Error but got nil")
 	}
 }
+
+func Test_BackpressureAnnotations_Valid(t *testing.T) {
+	annotations := map[string]string{
+		BackpressureMaxInFlightAnnotation: "10",
+		BackpressureRetryAfterAnnotation:  "60",
+	}
+	err := ValidateAnnotations(annotations)
+	if err != nil {
+		t.Errorf("Unexpected Error: %v", err)
+	}
+}
+
+func Test_BackpressureAnnotations_InvalidValue(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{BackpressureMaxInFlightAnnotation: "abc"},
+		{BackpressureMaxInFlightAnnotation: "0"},
+		{BackpressureRetryAfterAnnotation: "-1"},
+	} {
+		err := ValidateAnnotations(annotations)
+		if err == nil {
+			t.Errorf("Expected Error for %v but got nil", annotations)
+		}
+	}
+}
+
+func Test_InterceptorTimeoutAnnotation_Valid(t *testing.T) {
+	annotations := map[string]string{InterceptorTimeoutAnnotation: "10s"}
+	err := ValidateAnnotations(annotations)
+	if err != nil {
+		t.Errorf("Unexpected Error: %v", err)
+	}
+}
+
+func Test_InterceptorTimeoutAnnotation_InvalidValue(t *testing.T) {
+	for _, value := range []string{"10", "abc", "-5s", "0s"} {
+		err := ValidateAnnotations(map[string]string{InterceptorTimeoutAnnotation: value})
+		if err == nil {
+			t.Errorf("Expected Error for %q but got nil", value)
+		}
+	}
+}
+
+func Test_AllowedRequestsAnnotations_Valid(t *testing.T) {
+	annotations := map[string]string{
+		AllowedMethodsAnnotation:      "POST, PUT",
+		AllowedContentTypesAnnotation: "application/json,application/*+json, text/plain",
+	}
+	err := ValidateAnnotations(annotations)
+	if err != nil {
+		t.Errorf("Unexpected Error: %v", err)
+	}
+}
+
+func Test_AllowedRequestsAnnotations_InvalidValue(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{AllowedMethodsAnnotation: "post"},
+		{AllowedMethodsAnnotation: "POST,"},
+		{AllowedContentTypesAnnotation: "json"},
+		{AllowedContentTypesAnnotation: "application/[json"},
+	} {
+		err := ValidateAnnotations(annotations)
+		if err == nil {
+			t.Errorf("Expected Error for %v but got nil", annotations)
+		}
+	}
+}
+
+func Test_CreationLimitAnnotations_Valid(t *testing.T) {
+	annotations := map[string]string{
+		CreationLimitAnnotation:           "100",
+		CreationLimitWindowAnnotation:     "1h",
+		CreationLimitPerTriggerAnnotation: "true",
+		MaxCreatesPerEventAnnotation:      "20",
+	}
+	err := ValidateAnnotations(annotations)
+	if err != nil {
+		t.Errorf("Unexpected Error: %v", err)
+	}
+}
+
+func Test_CreationLimitAnnotations_InvalidValue(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{CreationLimitAnnotation: "0"},
+		{CreationLimitAnnotation: "many"},
+		{CreationLimitWindowAnnotation: "60"},
+		{CreationLimitWindowAnnotation: "-1m"},
+		{CreationLimitPerTriggerAnnotation: "yes"},
+		{MaxCreatesPerEventAnnotation: "0"},
+		{MaxCreatesPerEventAnnotation: "unlimited"},
+	} {
+		err := ValidateAnnotations(annotations)
+		if err == nil {
+			t.Errorf("Expected Error for %v but got nil", annotations)
+		}
+	}
+}
+
+func Test_CreateTimeoutAnnotation_Valid(t *testing.T) {
+	annotations := map[string]string{CreateTimeoutAnnotation: "30s"}
+	err := ValidateAnnotations(annotations)
+	if err != nil {
+		t.Errorf("Unexpected Error: %v", err)
+	}
+}
+
+func Test_CreateTimeoutAnnotation_InvalidValue(t *testing.T) {
+	for _, value := range []string{"30", "abc", "-5s", "0s"} {
+		err := ValidateAnnotations(map[string]string{CreateTimeoutAnnotation: value})
+		if err == nil {
+			t.Errorf("Expected Error for %q but got nil", value)
+		}
+	}
+}
+
+func Test_QuotaRetryAnnotations_Valid(t *testing.T) {
+	annotations := map[string]string{
+		QuotaRetryWindowAnnotation:    "5m",
+		QuotaRetryMaxQueuedAnnotation: "50",
+		QuotaRetryPersistAnnotation:   "true",
+	}
+	err := ValidateAnnotations(annotations)
+	if err != nil {
+		t.Errorf("Unexpected Error: %v", err)
+	}
+}
+
+func Test_QuotaRetryAnnotations_InvalidValue(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{QuotaRetryWindowAnnotation: "300"},
+		{QuotaRetryWindowAnnotation: "0s"},
+		{QuotaRetryMaxQueuedAnnotation: "0"},
+		{QuotaRetryMaxQueuedAnnotation: "unbounded"},
+		{QuotaRetryPersistAnnotation: "configmap"},
+	} {
+		err := ValidateAnnotations(annotations)
+		if err == nil {
+			t.Errorf("Expected Error for %v but got nil", annotations)
+		}
+	}
+}
+
+func Test_ActivityIntervalAnnotation_Valid(t *testing.T) {
+	annotations := map[string]string{ActivityIntervalAnnotation: "15s"}
+	err := ValidateAnnotations(annotations)
+	if err != nil {
+		t.Errorf("Unexpected Error: %v", err)
+	}
+}
+
+func Test_ActivityIntervalAnnotation_InvalidValue(t *testing.T) {
+	for _, value := range []string{"15", "abc", "-5s", "0s"} {
+		err := ValidateAnnotations(map[string]string{ActivityIntervalAnnotation: value})
+		if err == nil {
+			t.Errorf("Expected Error for %q but got nil", value)
+		}
+	}
+}
+
+func Test_ProvenanceLabelsAnnotation_Valid(t *testing.T) {
+	for _, value := range []string{"none", "trigger", "eventlistener, eventid,trigger"} {
+		err := ValidateAnnotations(map[string]string{ProvenanceLabelsAnnotation: value})
+		if err != nil {
+			t.Errorf("Unexpected Error for %q: %v", value, err)
+		}
+	}
+}
+
+func Test_ProvenanceLabelsAnnotation_InvalidValue(t *testing.T) {
+	for _, value := range []string{"", "all", "trigger,none", "trigger,"} {
+		err := ValidateAnnotations(map[string]string{ProvenanceLabelsAnnotation: value})
+		if err == nil {
+			t.Errorf("Expected Error for %q but got nil", value)
+		}
+	}
+}
+
+func TestParseProvenanceLabels(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		want  []string
+	}{{
+		value: "none",
+		want:  []string{},
+	}, {
+		value: "trigger",
+		want:  []string{TriggerLabelKey},
+	}, {
+		value: "eventlistener,eventid, trigger",
+		want:  []string{EventListenerLabelKey, EventIDLabelKey, TriggerLabelKey},
+	}} {
+		got, err := ParseProvenanceLabels(tc.value)
+		if err != nil {
+			t.Fatalf("ParseProvenanceLabels(%q) returned error: %v", tc.value, err)
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("ParseProvenanceLabels(%q) (-want +got): %s", tc.value, diff)
+		}
+	}
+}
+
+func Test_AccessLogAnnotations_Valid(t *testing.T) {
+	annotations := map[string]string{
+		AccessLogAnnotation:           "true",
+		AccessLogFieldsAnnotation:     "timestamp, eventID,status,payload",
+		AccessLogSampleRateAnnotation: "0.25",
+	}
+	err := ValidateAnnotations(annotations)
+	if err != nil {
+		t.Errorf("Unexpected Error: %v", err)
+	}
+}
+
+func Test_AccessLogAnnotations_InvalidValue(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{AccessLogAnnotation: "yes"},
+		{AccessLogFieldsAnnotation: ""},
+		{AccessLogFieldsAnnotation: "status,headers"},
+		{AccessLogSampleRateAnnotation: "10%"},
+		{AccessLogSampleRateAnnotation: "1.5"},
+		{AccessLogSampleRateAnnotation: "-0.1"},
+	} {
+		err := ValidateAnnotations(annotations)
+		if err == nil {
+			t.Errorf("Expected Error for %v but got nil", annotations)
+		}
+	}
+}
+
+func Test_BasePathAnnotation_Valid(t *testing.T) {
+	for _, value := range []string{"/webhooks/tekton", "/webhooks/tekton/", "/tenant-a"} {
+		err := ValidateAnnotations(map[string]string{BasePathAnnotation: value})
+		if err != nil {
+			t.Errorf("Unexpected Error for %q: %v", value, err)
+		}
+	}
+}
+
+func Test_BasePathAnnotation_InvalidValue(t *testing.T) {
+	for _, value := range []string{"", "webhooks", "//proxy/webhooks", "/webhooks?tenant=a", "/webhooks#a", "https://example.com/webhooks", "/%zz"} {
+		err := ValidateAnnotations(map[string]string{BasePathAnnotation: value})
+		if err == nil {
+			t.Errorf("Expected Error for %q but got nil", value)
+		}
+	}
+}
+
+func Test_EventIDAnnotations_Valid(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{EventIDHeadersAnnotation: "X-GitHub-Delivery"},
+		{EventIDHeadersAnnotation: "X-GitHub-Delivery, X-Gitlab-Event-UUID"},
+		{EventIDExpressionAnnotation: "body.delivery.id"},
+	} {
+		err := ValidateAnnotations(annotations)
+		if err != nil {
+			t.Errorf("Unexpected Error for %v: %v", annotations, err)
+		}
+	}
+}
+
+func Test_EventIDAnnotations_InvalidValue(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{EventIDHeadersAnnotation: ""},
+		{EventIDHeadersAnnotation: "X-GitHub-Delivery,"},
+		{EventIDHeadersAnnotation: "X GitHub Delivery"},
+		{EventIDExpressionAnnotation: " "},
+	} {
+		err := ValidateAnnotations(annotations)
+		if err == nil {
+			t.Errorf("Expected Error for %v but got nil", annotations)
+		}
+	}
+}
+
+func Test_FieldValidationAnnotation_Valid(t *testing.T) {
+	for _, value := range []string{"Ignore", "Warn", "Strict"} {
+		err := ValidateAnnotations(map[string]string{FieldValidationAnnotation: value})
+		if err != nil {
+			t.Errorf("Unexpected Error for %q: %v", value, err)
+		}
+	}
+}
+
+func Test_FieldValidationAnnotation_InvalidValue(t *testing.T) {
+	for _, value := range []string{"", "strict", "Error"} {
+		err := ValidateAnnotations(map[string]string{FieldValidationAnnotation: value})
+		if err == nil {
+			t.Errorf("Expected Error for %q but got nil", value)
+		}
+	}
+}
+
+func Test_LabelSanitizationAnnotation_Valid(t *testing.T) {
+	for _, value := range []string{"truncate", "hash", "reject"} {
+		err := ValidateAnnotations(map[string]string{LabelSanitizationAnnotation: value})
+		if err != nil {
+			t.Errorf("Unexpected Error for %q: %v", value, err)
+		}
+	}
+}
+
+func Test_LabelSanitizationAnnotation_InvalidValue(t *testing.T) {
+	for _, value := range []string{"", "Hash", "drop"} {
+		err := ValidateAnnotations(map[string]string{LabelSanitizationAnnotation: value})
+		if err == nil {
+			t.Errorf("Expected Error for %q but got nil", value)
+		}
+	}
+}
+
+func Test_AuditAnnotations_Valid(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{AuditSinkAnnotation: "file:///audit", AuditModeAnnotation: "alongside", AuditFailurePolicyAnnotation: "fatal"},
+		{AuditSinkAnnotation: "s3://bucket", AuditModeAnnotation: "instead", AuditFailurePolicyAnnotation: "best-effort"},
+		{AuditSinkAnnotation: "s3://bucket/events/prod?region=eu-west-1&endpoint=https://minio.example.com:9000"},
+	} {
+		if err := ValidateAnnotations(annotations); err != nil {
+			t.Errorf("Unexpected Error for %v: %v", annotations, err)
+		}
+	}
+}
+
+func Test_AuditAnnotations_InvalidValue(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{AuditSinkAnnotation: ""},
+		{AuditSinkAnnotation: "/audit"},
+		{AuditSinkAnnotation: "file://audit"},
+		{AuditSinkAnnotation: "s3:///prefix"},
+		{AuditSinkAnnotation: "s3://bucket?endpoint=minio:9000"},
+		{AuditSinkAnnotation: "gs://bucket"},
+		{AuditModeAnnotation: "both"},
+		{AuditFailurePolicyAnnotation: "ignore"},
+	} {
+		if err := ValidateAnnotations(annotations); err == nil {
+			t.Errorf("Expected Error for %v but got nil", annotations)
+		}
+	}
+}
+
+func Test_CreateFailurePolicyAnnotation(t *testing.T) {
+	for _, value := range []string{"fail-fast", "best-effort"} {
+		if err := ValidateAnnotations(map[string]string{CreateFailurePolicyAnnotation: value}); err != nil {
+			t.Errorf("Unexpected Error for %q: %v", value, err)
+		}
+	}
+	for _, value := range []string{"", "ignore", "Fail-Fast"} {
+		if err := ValidateAnnotations(map[string]string{CreateFailurePolicyAnnotation: value}); err == nil {
+			t.Errorf("Expected Error for %q but got nil", value)
+		}
+	}
+}
+
+func Test_CallbackAnnotations_Valid(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{CallbackURLAnnotation: "https://chatops.example.com/hooks/tekton"},
+		{
+			CallbackURLAnnotation:     "http://dashboard.tools.svc:8080/events",
+			CallbackPayloadAnnotation: `{"text": "$(trigger) created $(resources[0].name)"}`,
+			CallbackSecretAnnotation:  "callback-token",
+			CallbackTimeoutAnnotation: "5s",
+			CallbackRetriesAnnotation: "0",
+		},
+		{CallbackRetriesAnnotation: "10"},
+	} {
+		if err := ValidateAnnotations(annotations); err != nil {
+			t.Errorf("Unexpected Error for %v: %v", annotations, err)
+		}
+	}
+}
+
+func Test_CallbackAnnotations_InvalidValue(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{CallbackURLAnnotation: ""},
+		{CallbackURLAnnotation: "chatops.example.com/hooks"},
+		{CallbackURLAnnotation: "ftp://chatops.example.com"},
+		{CallbackPayloadAnnotation: `{"text": $(trigger)}`},
+		{CallbackSecretAnnotation: "Callback_Token"},
+		{CallbackTimeoutAnnotation: "0s"},
+		{CallbackRetriesAnnotation: "-1"},
+		{CallbackRetriesAnnotation: "11"},
+	} {
+		if err := ValidateAnnotations(annotations); err == nil {
+			t.Errorf("Expected Error for %v but got nil", annotations)
+		}
+	}
+}
+
+func Test_ResourceEventsAnnotations_Valid(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{ResourceEventsAnnotation: "created"},
+		{
+			ResourceEventsAnnotation:       "created, failed",
+			ResourceEventsURIAnnotation:    "http://broker-ingress.knative-eventing.svc/default/default",
+			ResourceEventsSourceAnnotation: "/triggers/github",
+		},
+		{ResourceEventsAnnotation: "failed", ResourceEventsSourceAnnotation: "https://tekton.example.com/listeners/github"},
+	} {
+		if err := ValidateAnnotations(annotations); err != nil {
+			t.Errorf("Unexpected Error for %v: %v", annotations, err)
+		}
+	}
+}
+
+func Test_ResourceEventsAnnotations_InvalidValue(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{ResourceEventsAnnotation: ""},
+		{ResourceEventsAnnotation: "created,deleted"},
+		{ResourceEventsURIAnnotation: "broker-ingress.knative-eventing.svc"},
+		{ResourceEventsURIAnnotation: "ftp://broker.example.com"},
+		{ResourceEventsSourceAnnotation: ""},
+		{ResourceEventsSourceAnnotation: "%zz"},
+	} {
+		if err := ValidateAnnotations(annotations); err == nil {
+			t.Errorf("Expected Error for %v but got nil", annotations)
+		}
+	}
+}
+
+func TestParseResourceEvents(t *testing.T) {
+	for _, tc := range []struct {
+		value           string
+		created, failed bool
+	}{
+		{value: "created", created: true},
+		{value: "failed", failed: true},
+		{value: "failed,created", created: true, failed: true},
+	} {
+		created, failed, err := ParseResourceEvents(tc.value)
+		if err != nil {
+			t.Fatalf("ParseResourceEvents(%q) returned error: %v", tc.value, err)
+		}
+		if created != tc.created || failed != tc.failed {
+			t.Errorf("ParseResourceEvents(%q) = %t, %t, want %t, %t", tc.value, created, failed, tc.created, tc.failed)
+		}
+	}
+}
+
+func Test_MutationHookAnnotations_Valid(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{MutationHookURLAnnotation: "https://defaults.platform.svc/mutate"},
+		{
+			MutationHookURLAnnotation:           "http://defaults.platform.svc:8080/mutate",
+			MutationHookTimeoutAnnotation:       "2s",
+			MutationHookFailurePolicyAnnotation: "fail-open",
+		},
+		{MutationHookFailurePolicyAnnotation: "fail-closed"},
+	} {
+		if err := ValidateAnnotations(annotations); err != nil {
+			t.Errorf("Unexpected Error for %v: %v", annotations, err)
+		}
+	}
+}
+
+func Test_MutationHookAnnotations_InvalidValue(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{MutationHookURLAnnotation: ""},
+		{MutationHookURLAnnotation: "defaults.platform.svc/mutate"},
+		{MutationHookURLAnnotation: "grpc://defaults.platform.svc"},
+		{MutationHookTimeoutAnnotation: "2"},
+		{MutationHookTimeoutAnnotation: "0s"},
+		{MutationHookFailurePolicyAnnotation: "ignore"},
+	} {
+		if err := ValidateAnnotations(annotations); err == nil {
+			t.Errorf("Expected Error for %v but got nil", annotations)
+		}
+	}
+}
+
+func Test_ResourcePoliciesAnnotation(t *testing.T) {
+	for _, value := range []string{"resource-policies", "timeouts, tekton-baseline"} {
+		if err := ValidateAnnotations(map[string]string{ResourcePoliciesAnnotation: value}); err != nil {
+			t.Errorf("Unexpected Error for %q: %v", value, err)
+		}
+	}
+	for _, value := range []string{"", "timeouts,", "Resource_Policies"} {
+		if err := ValidateAnnotations(map[string]string{ResourcePoliciesAnnotation: value}); err == nil {
+			t.Errorf("Expected Error for %q but got nil", value)
+		}
+	}
+}
+
+func Test_SinkAnnotations_Valid(t *testing.T) {
+	annotations := map[string]string{
+		SinkPortAnnotation:          "9090",
+		H2CAnnotation:               "true",
+		SynchronousAnnotation:       "false",
+		MultiStatusAnnotation:       "true",
+		RollbackOnFailureAnnotation: "true",
+		SelfTestSecretAnnotation:    "self-test-token",
+		ConfigSecretAnnotation:      "config-token",
+		BatchSizeAnnotation:         "100",
+	}
+	err := ValidateAnnotations(annotations)
+	if err != nil {
+		t.Errorf("Unexpected Error: %v", err)
+	}
+}
+
+func Test_SinkAnnotations_InvalidValue(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{SinkPortAnnotation: "0"},
+		{SinkPortAnnotation: "65536"},
+		{SinkPortAnnotation: "9000"},
+		{SinkPortAnnotation: "http"},
+		{H2CAnnotation: "yes"},
+		{SynchronousAnnotation: "sync"},
+		{MultiStatusAnnotation: "207"},
+		{RollbackOnFailureAnnotation: "always"},
+		{SelfTestSecretAnnotation: ""},
+		{SelfTestSecretAnnotation: "Self_Test"},
+		{ConfigSecretAnnotation: "Config_Token"},
+		{BatchSizeAnnotation: "0"},
+		{BatchSizeAnnotation: "1001"},
+		{BatchSizeAnnotation: "all"},
+	} {
+		err := ValidateAnnotations(annotations)
+		if err == nil {
+			t.Errorf("Expected Error for %v but got nil", annotations)
+		}
+	}
+}
+
+func Test_DebugTraceAnnotations_Valid(t *testing.T) {
+	annotations := map[string]string{
+		DebugTraceSecretAnnotation:     "debug-trace-token",
+		DebugTraceSampleRateAnnotation: "0.01",
+	}
+	err := ValidateAnnotations(annotations)
+	if err != nil {
+		t.Errorf("Unexpected Error: %v", err)
+	}
+}
+
+func Test_DebugTraceAnnotations_InvalidValue(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{DebugTraceSecretAnnotation: ""},
+		{DebugTraceSecretAnnotation: "Debug_Trace"},
+		{DebugTraceSampleRateAnnotation: "1%"},
+		{DebugTraceSampleRateAnnotation: "2"},
+		{DebugTraceSampleRateAnnotation: "-1"},
+	} {
+		err := ValidateAnnotations(annotations)
+		if err == nil {
+			t.Errorf("Expected Error for %v but got nil", annotations)
+		}
+	}
+}
+
+func Test_InterceptorMetricsNamesAnnotation_Valid(t *testing.T) {
+	for _, key := range []string{InterceptorMetricsNamesAnnotation, ResourceMetricsNamespacesAnnotation} {
+		for _, value := range []string{"0", "20", "500"} {
+			if err := ValidateAnnotations(map[string]string{key: value}); err != nil {
+				t.Errorf("Unexpected Error for %s %q: %v", key, value, err)
+			}
+		}
+	}
+}
+
+func Test_InterceptorMetricsNamesAnnotation_InvalidValue(t *testing.T) {
+	for _, key := range []string{InterceptorMetricsNamesAnnotation, ResourceMetricsNamespacesAnnotation} {
+		for _, value := range []string{"", "-1", "ten", "1.5"} {
+			if err := ValidateAnnotations(map[string]string{key: value}); err == nil {
+				t.Errorf("Expected Error for %s %q but got nil", key, value)
+			}
+		}
+	}
+}
+
+func Test_NoMatchAnnotations_Valid(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{NoMatchPolicyAnnotation: "ignore"},
+		{NoMatchPolicyAnnotation: "error", NoMatchLogLevelAnnotation: "warn"},
+		{NoMatchLogLevelAnnotation: "debug"},
+	} {
+		if err := ValidateAnnotations(annotations); err != nil {
+			t.Errorf("Unexpected Error for %v: %v", annotations, err)
+		}
+	}
+}
+
+func Test_NoMatchAnnotations_InvalidValue(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{NoMatchPolicyAnnotation: ""},
+		{NoMatchPolicyAnnotation: "reject"},
+		{NoMatchLogLevelAnnotation: "warning"},
+		{NoMatchLogLevelAnnotation: "INFO"},
+	} {
+		if err := ValidateAnnotations(annotations); err == nil {
+			t.Errorf("Expected Error for %v but got nil", annotations)
+		}
+	}
+}
+
+func Test_DiscoveryAnnotations_Valid(t *testing.T) {
+	annotations := map[string]string{
+		DiscoveryGracePeriodAnnotation: "5m",
+		DiscoveryBackoffAnnotation:     "500ms",
+	}
+	if err := ValidateAnnotations(annotations); err != nil {
+		t.Errorf("Unexpected Error: %v", err)
+	}
+}
+
+func Test_DiscoveryAnnotations_InvalidValue(t *testing.T) {
+	for _, annotations := range []map[string]string{
+		{DiscoveryGracePeriodAnnotation: "120"},
+		{DiscoveryGracePeriodAnnotation: "0s"},
+		{DiscoveryBackoffAnnotation: "-1s"},
+	} {
+		if err := ValidateAnnotations(annotations); err == nil {
+			t.Errorf("Expected Error for %v but got nil", annotations)
+		}
+	}
+}
+
+func Test_PayloadParsersAnnotation_Valid(t *testing.T) {
+	for _, value := range []string{"form", "gzip", "protobuf", "form, gzip"} {
+		if err := ValidateAnnotations(map[string]string{PayloadParsersAnnotation: value}); err != nil {
+			t.Errorf("Unexpected Error for %q: %v", value, err)
+		}
+	}
+}
+
+func Test_PayloadParsersAnnotation_InvalidValue(t *testing.T) {
+	for _, value := range []string{"", "json", "form,", "xml"} {
+		if err := ValidateAnnotations(map[string]string{PayloadParsersAnnotation: value}); err == nil {
+			t.Errorf("Expected Error for %q but got nil", value)
+		}
+	}
+}
+
+func TestValidateLogFormat(t *testing.T) {
+	for _, value := range []string{"", "json"} {
+		if err := ValidateLogFormat(value); err != nil {
+			t.Errorf("ValidateLogFormat(%q) returned error: %v", value, err)
+		}
+	}
+	for _, value := range []string{"JSON", "console", " json"} {
+		if err := ValidateLogFormat(value); err == nil {
+			t.Errorf("ValidateLogFormat(%q) expected an error", value)
+		}
+	}
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	for value, want := range map[string]uint16{"1.2": tls.VersionTLS12, " 1.3": tls.VersionTLS13} {
+		got, err := ParseTLSVersion(value)
+		if err != nil {
+			t.Fatalf("ParseTLSVersion(%q) returned error: %v", value, err)
+		}
+		if got != want {
+			t.Errorf("ParseTLSVersion(%q) = %x, want %x", value, got, want)
+		}
+	}
+	for _, value := range []string{"", "1.0", "1.1", "TLS1.2"} {
+		if _, err := ParseTLSVersion(value); err == nil {
+			t.Errorf("ParseTLSVersion(%q) expected an error", value)
+		}
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	got, err := ParseTrustedProxies("10.0.0.0/8, 192.0.2.10, 2001:db8::/32, 2001:db8::1")
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies() returned error: %v", err)
+	}
+	var networks []string
+	for _, n := range got {
+		networks = append(networks, n.String())
+	}
+	if diff := cmp.Diff([]string{"10.0.0.0/8", "192.0.2.10/32", "2001:db8::/32", "2001:db8::1/128"}, networks); diff != "" {
+		t.Errorf("ParseTrustedProxies() (-want +got): %s", diff)
+	}
+
+	for _, value := range []string{"", "10.0.0.0/8,", "10.0.0.0/33", "proxy.example.com"} {
+		if _, err := ParseTrustedProxies(value); err == nil {
+			t.Errorf("ParseTrustedProxies(%q) expected an error", value)
+		}
+		if err := ValidateAnnotations(map[string]string{TrustedProxiesAnnotation: value}); err == nil {
+			t.Errorf("Expected Error for %q but got nil", value)
+		}
+	}
+	if err := ValidateAnnotations(map[string]string{TrustedProxiesAnnotation: "10.0.0.0/8,192.0.2.10"}); err != nil {
+		t.Errorf("Unexpected Error: %v", err)
+	}
+}
+
+func TestValidateAnnotations_ProxyProtocolUpstreams(t *testing.T) {
+	for _, value := range []string{"", "10.0.0.0/8,", "lb.example.com"} {
+		if err := ValidateAnnotations(map[string]string{ProxyProtocolUpstreamsAnnotation: value}); err == nil {
+			t.Errorf("Expected Error for %q but got nil", value)
+		}
+	}
+	if err := ValidateAnnotations(map[string]string{ProxyProtocolUpstreamsAnnotation: "10.0.0.0/8, 2001:db8::1"}); err != nil {
+		t.Errorf("Unexpected Error: %v", err)
+	}
+}
+
+func TestValidateTrustedProxyHeader(t *testing.T) {
+	for _, value := range []string{"", "X-Forwarded-For", "Forwarded"} {
+		if err := ValidateTrustedProxyHeader(value); err != nil {
+			t.Errorf("ValidateTrustedProxyHeader(%q) returned error: %v", value, err)
+		}
+	}
+	for _, value := range []string{"X-Real-IP", "forwarded"} {
+		if err := ValidateTrustedProxyHeader(value); err == nil {
+			t.Errorf("ValidateTrustedProxyHeader(%q) expected an error", value)
+		}
+		if err := ValidateAnnotations(map[string]string{TrustedProxyHeaderAnnotation: value}); err == nil {
+			t.Errorf("Expected Error for %q but got nil", value)
+		}
+	}
+}
+
+func TestParseTLSCipherSuites(t *testing.T) {
+	got, err := ParseTLSCipherSuites("TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384")
+	if err != nil {
+		t.Fatalf("ParseTLSCipherSuites() returned error: %v", err)
+	}
+	want := []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseTLSCipherSuites() (-want +got): %s", diff)
+	}
+	for _, value := range []string{
+		"",
+		"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,",
+		"TLS_RSA_WITH_RC4_128_SHA",
+		"TLS_AES_128_GCM_SHA256",
+		"TLS_UNKNOWN",
+	} {
+		if _, err := ParseTLSCipherSuites(value); err == nil {
+			t.Errorf("ParseTLSCipherSuites(%q) expected an error", value)
+		}
+	}
+}
+
+func Test_EventPoolAnnotations(t *testing.T) {
+	if err := ValidateAnnotations(map[string]string{
+		EventWorkersAnnotation:   "8",
+		EventQueueSizeAnnotation: "0",
+		EventOverflowAnnotation:  "wait",
+	}); err != nil {
+		t.Errorf("Unexpected Error: %v", err)
+	}
+	for _, annotations := range []map[string]string{
+		{EventWorkersAnnotation: "0"},
+		{EventWorkersAnnotation: "many"},
+		{EventQueueSizeAnnotation: "-1"},
+		{EventOverflowAnnotation: "drop"},
+	} {
+		if err := ValidateAnnotations(annotations); err == nil {
+			t.Errorf("Expected Error for %v but got nil", annotations)
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/triggers/pkg/apis/triggers"
 	"github.com/tektoncd/triggers/pkg/client/dynamic/clientset"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 )
@@ -33,6 +34,16 @@ var (
 		"v1alpha1": {"clusterinterceptors", "interceptors"},
 		"v1beta1":  {"clustertriggerbindings", "eventlisteners", "triggerbindings", "triggers", "triggertemplates"},
 	}
+	// allowedCoreTypes are the core types created on behalf of resource templates, e.g. the namespaces of
+	// the templates with the create-namespace annotation.
+	allowedCoreTypes = map[string][]string{
+		"v1": {"namespaces"},
+	}
+	// allowedCoordinationTypes are the Leases held while creating the resources of the templates with the
+	// create-lease annotation.
+	allowedCoordinationTypes = map[string][]string{
+		"v1": {"leases"},
+	}
 )
 
 // WithClient adds Tekton related clients to the Dynamic client.
@@ -58,5 +69,15 @@ func WithClient(client dynamic.Interface) clientset.Option {
 				cs.Add(r, client)
 			}
 		}
+		for version, resources := range allowedCoreTypes {
+			for _, resource := range resources {
+				cs.Add(schema.GroupVersionResource{Version: version, Resource: resource}, client)
+			}
+		}
+		for version, resources := range allowedCoordinationTypes {
+			for _, resource := range resources {
+				cs.Add(schema.GroupVersionResource{Group: coordinationv1.GroupName, Version: version, Resource: resource}, client)
+			}
+		}
 	}
 }
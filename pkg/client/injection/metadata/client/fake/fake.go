@@ -0,0 +1,44 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+
+	"github.com/tektoncd/triggers/pkg/client/injection/metadata/client"
+	"k8s.io/client-go/metadata/fake"
+	"k8s.io/client-go/rest"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/logging"
+)
+
+func init() {
+	injection.Fake.RegisterClient(withClient)
+}
+
+func withClient(ctx context.Context, cfg *rest.Config) context.Context {
+	return context.WithValue(ctx, client.Key{}, fake.NewSimpleMetadataClient(fake.NewTestScheme()))
+}
+
+// Get extracts the fake metadata client from the context.
+func Get(ctx context.Context) *fake.FakeMetadataClient {
+	untyped := ctx.Value(client.Key{})
+	if untyped == nil {
+		logging.FromContext(ctx).Panic("Unable to fetch k8s.io/client-go/metadata/fake.FakeMetadataClient from context.")
+	}
+	return untyped.(*fake.FakeMetadataClient)
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package secret injects an informer of the metadata of Secrets, which doesn't cache their data.
+package secret
+
+import (
+	"context"
+
+	"github.com/tektoncd/triggers/pkg/client/injection/metadata/client"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/logging"
+)
+
+func init() {
+	injection.Default.RegisterInformer(withInformer)
+}
+
+// Key is used for associating the Informer inside the context.Context.
+type Key struct{}
+
+func withInformer(ctx context.Context) (context.Context, controller.Informer) {
+	return WithInformer(ctx, client.Get(ctx))
+}
+
+// WithInformer adds the informer of the metadata of Secrets listed with the client to the context.
+func WithInformer(ctx context.Context, c metadata.Interface) (context.Context, controller.Informer) {
+	inf := metadatainformer.NewSharedInformerFactory(c, controller.GetResyncPeriod(ctx)).
+		ForResource(corev1.SchemeGroupVersion.WithResource("secrets"))
+	return context.WithValue(ctx, Key{}, inf), inf.Informer()
+}
+
+// Get extracts the informer of the metadata of Secrets from the context. Its objects are
+// *metav1.PartialObjectMetadata.
+func Get(ctx context.Context) informers.GenericInformer {
+	untyped := ctx.Value(Key{})
+	if untyped == nil {
+		logging.FromContext(ctx).Panic("Unable to fetch the informer of the metadata of Secrets from context.")
+	}
+	return untyped.(informers.GenericInformer)
+}
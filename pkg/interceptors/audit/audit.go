@@ -0,0 +1,261 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"google.golang.org/grpc/codes"
+)
+
+var _ triggersv1.InterceptorInterface = (*Interceptor)(nil)
+
+const (
+	// ExtensionKey is the extensions key under which the first matching audit event is returned.
+	ExtensionKey = "audit"
+	// EventsExtensionKey is the extensions key under which all the matching audit events are returned,
+	// since the audit webhook backend sends events in batches.
+	EventsExtensionKey = "audit_events"
+
+	// apiGroup is the API group of audit events.
+	apiGroup = "audit.k8s.io"
+	// defaultStage is the only stage accepted if no stages are set, so that requests logged at several
+	// stages trigger once, with their response.
+	defaultStage = "ResponseComplete"
+)
+
+// InterceptorParams provides a webhook to intercept and pre-process events.
+type InterceptorParams struct {
+	// Verbs are the accepted verbs, e.g. "delete". All verbs are accepted if empty.
+	Verbs []string `json:"verbs,omitempty"`
+	// Resources are the accepted resources, as "<resource>[.<group>][/<subresource>]", e.g.
+	// "deployments.apps" or "pods/exec". A resource without a group matches any group. All resources
+	// are accepted if empty.
+	Resources []string `json:"resources,omitempty"`
+	// Namespaces are the accepted namespaces of the objects. All namespaces are accepted if empty.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Users are the accepted usernames. All users are accepted if empty.
+	Users []string `json:"users,omitempty"`
+	// ExcludeUsers are the rejected usernames, e.g. the service accounts of the pipelines triggered,
+	// to not react to their own changes.
+	ExcludeUsers []string `json:"excludeUsers,omitempty"`
+	// Stages are the accepted stages. Only ResponseComplete is accepted if empty.
+	Stages []string `json:"stages,omitempty"`
+}
+
+// Interceptor filters Kubernetes audit events, as sent by the audit webhook backend, and returns the
+// matching ones with normalized fields for bindings.
+type Interceptor struct{}
+
+func NewInterceptor() *Interceptor {
+	return &Interceptor{}
+}
+
+// event is the part of an audit.k8s.io Event used by the interceptor.
+type event struct {
+	AuditID    string `json:"auditID"`
+	Stage      string `json:"stage"`
+	RequestURI string `json:"requestURI"`
+	Verb       string `json:"verb"`
+	User       struct {
+		Username string   `json:"username"`
+		Groups   []string `json:"groups"`
+	} `json:"user"`
+	ImpersonatedUser *struct {
+		Username string `json:"username"`
+	} `json:"impersonatedUser"`
+	SourceIPs []string `json:"sourceIPs"`
+	UserAgent string   `json:"userAgent"`
+	ObjectRef *struct {
+		Resource    string `json:"resource"`
+		Namespace   string `json:"namespace"`
+		Name        string `json:"name"`
+		APIGroup    string `json:"apiGroup"`
+		APIVersion  string `json:"apiVersion"`
+		Subresource string `json:"subresource"`
+	} `json:"objectRef"`
+	ResponseStatus *struct {
+		Code int `json:"code"`
+	} `json:"responseStatus"`
+	StageTimestamp string `json:"stageTimestamp"`
+}
+
+// payload is an audit.k8s.io Event or EventList.
+type payload struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	event
+	Items []event `json:"items"`
+}
+
+func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequest) *triggersv1.InterceptorResponse {
+	p := InterceptorParams{}
+	if err := interceptors.UnmarshalParams(r.InterceptorParams, &p); err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "failed to parse interceptor params: %v", err)
+	}
+	resources, err := parseResources(p.Resources)
+	if err != nil {
+		return interceptors.Fail(codes.InvalidArgument, err.Error())
+	}
+
+	events, err := parseEvents(r.Body)
+	if err != nil {
+		return interceptors.Fail(codes.InvalidArgument, err.Error())
+	}
+
+	var matches []interface{}
+	for _, e := range events {
+		if p.matches(e, resources) {
+			matches = append(matches, normalize(e))
+		}
+	}
+	if len(matches) == 0 {
+		return interceptors.Failf(codes.FailedPrecondition, "none of the %d audit events matched", len(events))
+	}
+	return &triggersv1.InterceptorResponse{
+		Continue: true,
+		Extensions: map[string]interface{}{
+			ExtensionKey:       matches[0],
+			EventsExtensionKey: matches,
+		},
+	}
+}
+
+// parseEvents returns the audit events of body, which must be an audit.k8s.io Event or EventList.
+func parseEvents(body string) ([]event, error) {
+	var pl payload
+	if err := json.Unmarshal([]byte(body), &pl); err != nil {
+		return nil, fmt.Errorf("payload is not a Kubernetes audit event: %v", err)
+	}
+	if !strings.HasPrefix(pl.APIVersion, apiGroup+"/") {
+		return nil, fmt.Errorf("payload is not a Kubernetes audit event: apiVersion %q is not in the %s group", pl.APIVersion, apiGroup)
+	}
+	switch pl.Kind {
+	case "Event":
+		return []event{pl.event}, nil
+	case "EventList":
+		return pl.Items, nil
+	}
+	return nil, fmt.Errorf("payload is not a Kubernetes audit event: kind %q is neither Event nor EventList", pl.Kind)
+}
+
+// resource is a parsed entry of InterceptorParams.Resources.
+type resource struct {
+	name, group, subresource string
+	anyGroup                 bool
+}
+
+func parseResources(values []string) ([]resource, error) {
+	resources := make([]resource, 0, len(values))
+	for _, v := range values {
+		var res resource
+		rest := v
+		if i := strings.Index(rest, "/"); i >= 0 {
+			res.subresource = rest[i+1:]
+			rest = rest[:i]
+		}
+		if i := strings.Index(rest, "."); i >= 0 {
+			res.name, res.group = rest[:i], rest[i+1:]
+		} else {
+			res.name, res.anyGroup = rest, true
+		}
+		if res.name == "" || strings.Contains(res.subresource, "/") {
+			return nil, fmt.Errorf("invalid resource %q: must be <resource>[.<group>][/<subresource>]", v)
+		}
+		resources = append(resources, res)
+	}
+	return resources, nil
+}
+
+func (p InterceptorParams) matches(e event, resources []resource) bool {
+	stages := p.Stages
+	if len(stages) == 0 {
+		stages = []string{defaultStage}
+	}
+	if !interceptors.Contains(stages, e.Stage) {
+		return false
+	}
+	if len(p.Verbs) > 0 && !interceptors.Contains(p.Verbs, e.Verb) {
+		return false
+	}
+	if len(p.Users) > 0 && !interceptors.Contains(p.Users, e.User.Username) {
+		return false
+	}
+	if interceptors.Contains(p.ExcludeUsers, e.User.Username) {
+		return false
+	}
+	if len(p.Namespaces) > 0 && (e.ObjectRef == nil || !interceptors.Contains(p.Namespaces, e.ObjectRef.Namespace)) {
+		return false
+	}
+	if len(resources) == 0 {
+		return true
+	}
+	if e.ObjectRef == nil {
+		return false
+	}
+	for _, res := range resources {
+		if res.name == e.ObjectRef.Resource && res.subresource == e.ObjectRef.Subresource &&
+			(res.anyGroup || res.group == e.ObjectRef.APIGroup) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize returns the fields of e for bindings: who did what to which object.
+func normalize(e event) map[string]interface{} {
+	fields := map[string]interface{}{
+		"auditID":    e.AuditID,
+		"stage":      e.Stage,
+		"verb":       e.Verb,
+		"user":       e.User.Username,
+		"groups":     stringList(e.User.Groups),
+		"sourceIPs":  stringList(e.SourceIPs),
+		"userAgent":  e.UserAgent,
+		"requestURI": e.RequestURI,
+		"timestamp":  e.StageTimestamp,
+	}
+	if e.ImpersonatedUser != nil {
+		fields["impersonatedUser"] = e.ImpersonatedUser.Username
+	}
+	if e.ObjectRef != nil {
+		fields["resource"] = e.ObjectRef.Resource
+		fields["subresource"] = e.ObjectRef.Subresource
+		fields["apiGroup"] = e.ObjectRef.APIGroup
+		fields["apiVersion"] = e.ObjectRef.APIVersion
+		fields["namespace"] = e.ObjectRef.Namespace
+		fields["name"] = e.ObjectRef.Name
+	}
+	if e.ResponseStatus != nil {
+		fields["responseCode"] = e.ResponseStatus.Code
+	}
+	return fields
+}
+
+// stringList returns values as a list for the extensions, which are marshalled to JSON.
+func stringList(values []string) []interface{} {
+	list := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		list = append(list, v)
+	}
+	return list
+}
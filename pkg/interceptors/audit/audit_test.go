@@ -0,0 +1,274 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors/interceptorstest"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	deleteDeployment = `{
+  "kind": "Event",
+  "apiVersion": "audit.k8s.io/v1",
+  "level": "Metadata",
+  "auditID": "a1b2c3",
+  "stage": "ResponseComplete",
+  "requestURI": "/apis/apps/v1/namespaces/prod/deployments/web",
+  "verb": "delete",
+  "user": {"username": "alice@example.com", "groups": ["system:authenticated"]},
+  "sourceIPs": ["10.0.0.1"],
+  "userAgent": "kubectl/v1.24.0",
+  "objectRef": {"resource": "deployments", "namespace": "prod", "name": "web", "apiGroup": "apps", "apiVersion": "v1"},
+  "responseStatus": {"metadata": {}, "code": 200},
+  "requestReceivedTimestamp": "2022-06-01T12:00:00.000000Z",
+  "stageTimestamp": "2022-06-01T12:00:00.100000Z"
+}`
+	eventList = `{
+  "kind": "EventList",
+  "apiVersion": "audit.k8s.io/v1",
+  "metadata": {},
+  "items": [{
+    "auditID": "d4e5f6",
+    "stage": "ResponseComplete",
+    "verb": "get",
+    "user": {"username": "system:serviceaccount:tekton-pipelines:tekton-triggers-controller"},
+    "objectRef": {"resource": "secrets", "namespace": "prod", "name": "token", "apiVersion": "v1"}
+  }, {
+    "auditID": "a7b8c9",
+    "stage": "RequestReceived",
+    "verb": "create",
+    "user": {"username": "bob@example.com"},
+    "objectRef": {"resource": "pods", "namespace": "prod", "name": "web-1", "apiVersion": "v1", "subresource": "exec"}
+  }, {
+    "auditID": "a7b8c9",
+    "stage": "ResponseComplete",
+    "verb": "create",
+    "user": {"username": "bob@example.com"},
+    "impersonatedUser": {"username": "admin"},
+    "objectRef": {"resource": "pods", "namespace": "prod", "name": "web-1", "apiVersion": "v1", "subresource": "exec"},
+    "responseStatus": {"code": 101}
+  }]
+}`
+)
+
+func newRequest(body string, params InterceptorParams) *triggersv1.InterceptorRequest {
+	return interceptorstest.NewRequest(body, nil, map[string]interface{}{
+		"verbs":        params.Verbs,
+		"resources":    params.Resources,
+		"namespaces":   params.Namespaces,
+		"users":        params.Users,
+		"excludeUsers": params.ExcludeUsers,
+		"stages":       params.Stages,
+	})
+}
+
+func TestInterceptor_Process_ShouldContinue(t *testing.T) {
+	deleted := map[string]interface{}{
+		"auditID":      "a1b2c3",
+		"stage":        "ResponseComplete",
+		"verb":         "delete",
+		"user":         "alice@example.com",
+		"groups":       []interface{}{"system:authenticated"},
+		"sourceIPs":    []interface{}{"10.0.0.1"},
+		"userAgent":    "kubectl/v1.24.0",
+		"requestURI":   "/apis/apps/v1/namespaces/prod/deployments/web",
+		"timestamp":    "2022-06-01T12:00:00.100000Z",
+		"resource":     "deployments",
+		"subresource":  "",
+		"apiGroup":     "apps",
+		"apiVersion":   "v1",
+		"namespace":    "prod",
+		"name":         "web",
+		"responseCode": 200,
+	}
+	exec := map[string]interface{}{
+		"auditID":          "a7b8c9",
+		"stage":            "ResponseComplete",
+		"verb":             "create",
+		"user":             "bob@example.com",
+		"impersonatedUser": "admin",
+		"groups":           []interface{}{},
+		"sourceIPs":        []interface{}{},
+		"userAgent":        "",
+		"requestURI":       "",
+		"timestamp":        "",
+		"resource":         "pods",
+		"subresource":      "exec",
+		"apiGroup":         "",
+		"apiVersion":       "v1",
+		"namespace":        "prod",
+		"name":             "web-1",
+		"responseCode":     101,
+	}
+
+	for _, tc := range []struct {
+		name       string
+		body       string
+		params     InterceptorParams
+		wantEvents []interface{}
+	}{{
+		name:       "no filters",
+		body:       deleteDeployment,
+		wantEvents: []interface{}{deleted},
+	}, {
+		name: "all filters",
+		body: deleteDeployment,
+		params: InterceptorParams{
+			Verbs:      []string{"update", "delete"},
+			Resources:  []string{"deployments.apps"},
+			Namespaces: []string{"prod"},
+			Users:      []string{"alice@example.com"},
+		},
+		wantEvents: []interface{}{deleted},
+	}, {
+		name:       "resource in any group",
+		body:       deleteDeployment,
+		params:     InterceptorParams{Resources: []string{"deployments"}},
+		wantEvents: []interface{}{deleted},
+	}, {
+		name:       "event list",
+		body:       eventList,
+		params:     InterceptorParams{Resources: []string{"pods/exec"}, ExcludeUsers: []string{"system:serviceaccount:tekton-pipelines:tekton-triggers-controller"}},
+		wantEvents: []interface{}{exec},
+	}, {
+		name:       "core group resource",
+		body:       eventList,
+		params:     InterceptorParams{Resources: []string{"pods./exec"}},
+		wantEvents: []interface{}{exec},
+	}, {
+		name:   "stages",
+		body:   eventList,
+		params: InterceptorParams{Verbs: []string{"create"}, Stages: []string{"RequestReceived", "ResponseComplete"}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			res := NewInterceptor().Process(context.Background(), newRequest(tc.body, tc.params))
+			if !res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be true but got %t. \nStatus.Err(): %v", res.Continue, res.Status.Err())
+			}
+			if tc.wantEvents == nil {
+				if got := len(res.Extensions[EventsExtensionKey].([]interface{})); got != 2 {
+					t.Errorf("Interceptor.Process() got %d matching events, want 2", got)
+				}
+				return
+			}
+			if diff := cmp.Diff(tc.wantEvents, res.Extensions[EventsExtensionKey]); diff != "" {
+				t.Errorf("Interceptor.Process() matching events (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tc.wantEvents[0], res.Extensions[ExtensionKey]); diff != "" {
+				t.Errorf("Interceptor.Process() first matching event (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestInterceptor_Process_ShouldNotContinue(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		body     string
+		params   InterceptorParams
+		wantCode codes.Code
+		wantMsg  string
+	}{{
+		name:     "not JSON",
+		body:     "verb=delete",
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "payload is not a Kubernetes audit event",
+	}, {
+		name:     "other API group",
+		body:     `{"apiVersion": "v1", "kind": "Event", "verb": "delete"}`,
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `payload is not a Kubernetes audit event: apiVersion "v1" is not in the audit.k8s.io group`,
+	}, {
+		name:     "other kind",
+		body:     `{"apiVersion": "audit.k8s.io/v1", "kind": "Policy"}`,
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `kind "Policy" is neither Event nor EventList`,
+	}, {
+		name:     "invalid resource",
+		body:     deleteDeployment,
+		params:   InterceptorParams{Resources: []string{".apps"}},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `invalid resource ".apps"`,
+	}, {
+		name:     "verb not allowed",
+		body:     deleteDeployment,
+		params:   InterceptorParams{Verbs: []string{"create"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "none of the 1 audit events matched",
+	}, {
+		name:     "resource group not allowed",
+		body:     deleteDeployment,
+		params:   InterceptorParams{Resources: []string{"deployments.extensions"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "none of the 1 audit events matched",
+	}, {
+		name:     "subresource",
+		body:     eventList,
+		params:   InterceptorParams{Resources: []string{"pods"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "none of the 3 audit events matched",
+	}, {
+		name:     "namespace not allowed",
+		body:     deleteDeployment,
+		params:   InterceptorParams{Namespaces: []string{"staging"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "none of the 1 audit events matched",
+	}, {
+		name:     "user not allowed",
+		body:     deleteDeployment,
+		params:   InterceptorParams{Users: []string{"bob@example.com"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "none of the 1 audit events matched",
+	}, {
+		name:     "user excluded",
+		body:     deleteDeployment,
+		params:   InterceptorParams{ExcludeUsers: []string{"alice@example.com"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "none of the 1 audit events matched",
+	}, {
+		name:     "stage not allowed",
+		body:     deleteDeployment,
+		params:   InterceptorParams{Stages: []string{"RequestReceived"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "none of the 1 audit events matched",
+	}, {
+		name:     "empty event list",
+		body:     `{"apiVersion": "audit.k8s.io/v1", "kind": "EventList", "items": []}`,
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "none of the 0 audit events matched",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			res := NewInterceptor().Process(context.Background(), newRequest(tc.body, tc.params))
+			if res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be false but got %t", res.Continue)
+			}
+			if res.Status.Code != tc.wantCode {
+				t.Errorf("Interceptor.Process() got status code %v, want %v", res.Status.Code, tc.wantCode)
+			}
+			if !strings.Contains(res.Status.Message, tc.wantMsg) {
+				t.Errorf("Interceptor.Process() got message %q, want it to contain %q", res.Status.Message, tc.wantMsg)
+			}
+		})
+	}
+}
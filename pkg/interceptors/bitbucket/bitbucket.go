@@ -77,12 +77,14 @@ func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequ
 		}
 
 		ns, _ := triggersv1.ParseTriggerID(r.Context.TriggerID)
-		secretToken, err := w.SecretGetter.Get(ctx, ns, p.SecretRef)
+		secretTokens, err := interceptors.GetSecrets(ctx, w.SecretGetter, ns, p.SecretRef, p.AdditionalSecretRefs)
 		if err != nil {
 			return interceptors.Failf(codes.FailedPrecondition, "error getting secret: %v", err)
 		}
 
-		if err := gh.ValidateSignature(header, []byte(r.Body), secretToken); err != nil {
+		if err := interceptors.ValidateAny(secretTokens, func(secret []byte) error {
+			return gh.ValidateSignature(header, []byte(interceptors.SignedBody(r)), secret)
+		}); err != nil {
 			return interceptors.Failf(codes.FailedPrecondition, err.Error())
 		}
 	}
@@ -0,0 +1,188 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucketserver
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	gh "github.com/google/go-github/v31/github"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"google.golang.org/grpc/codes"
+)
+
+var _ triggersv1.InterceptorInterface = (*Interceptor)(nil)
+
+// ExtensionKey is the extensions key under which the normalized event fields are returned.
+const ExtensionKey = "bitbucketServer"
+
+// Interceptor validates and filters Bitbucket Server (and Data Center) webhooks.
+// Unlike Bitbucket Cloud, Bitbucket Server signs its payloads with an HMAC
+// in the X-Hub-Signature header and identifies the event with X-Event-Key.
+type Interceptor struct {
+	SecretGetter interceptors.SecretGetter
+}
+
+func NewInterceptor(sg interceptors.SecretGetter) *Interceptor {
+	return &Interceptor{
+		SecretGetter: sg,
+	}
+}
+
+// ref is a Bitbucket Server ref e.g. the fromRef of a pull request.
+type ref struct {
+	ID           string     `json:"id"`
+	DisplayID    string     `json:"displayId"`
+	Type         string     `json:"type"`
+	LatestCommit string     `json:"latestCommit"`
+	Repository   repository `json:"repository"`
+}
+
+type repository struct {
+	Slug    string `json:"slug"`
+	Name    string `json:"name"`
+	Project struct {
+		Key string `json:"key"`
+	} `json:"project"`
+}
+
+// payload contains the subset of the Bitbucket Server event payload used for normalization.
+type payload struct {
+	Repository *repository `json:"repository"`
+	Changes    []struct {
+		Ref    ref    `json:"ref"`
+		ToHash string `json:"toHash"`
+	} `json:"changes"`
+	PullRequest *struct {
+		ID      int `json:"id"`
+		FromRef ref `json:"fromRef"`
+		ToRef   ref `json:"toRef"`
+	} `json:"pullRequest"`
+}
+
+func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequest) *triggersv1.InterceptorResponse {
+	p := triggersv1.BitbucketInterceptor{}
+	if err := interceptors.UnmarshalParams(r.InterceptorParams, &p); err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "failed to parse interceptor params: %v", err)
+	}
+
+	headers := interceptors.Canonical(r.Header)
+	actualEvent := headers.Get("X-Event-Key")
+
+	// Check if the event type is in the allow-list
+	if p.EventTypes != nil {
+		isAllowed := false
+		for _, allowedEvent := range p.EventTypes {
+			if actualEvent == allowedEvent {
+				isAllowed = true
+				break
+			}
+		}
+		if !isAllowed {
+			return interceptors.Failf(codes.FailedPrecondition, "event type %s is not allowed", actualEvent)
+		}
+	}
+
+	// Next validate secrets if set
+	if p.SecretRef != nil {
+		// Check the secret to see if it is empty
+		if p.SecretRef.SecretKey == "" {
+			return interceptors.Fail(codes.FailedPrecondition, "bitbucket-server interceptor secretRef.secretKey is empty")
+		}
+		header := headers.Get("X-Hub-Signature")
+		if header == "" {
+			return interceptors.Fail(codes.InvalidArgument, "no X-Hub-Signature header set")
+		}
+
+		if r.Context == nil {
+			return interceptors.Failf(codes.InvalidArgument, "no request context passed")
+		}
+
+		ns, _ := triggersv1.ParseTriggerID(r.Context.TriggerID)
+		secretTokens, err := interceptors.GetSecrets(ctx, w.SecretGetter, ns, p.SecretRef, p.AdditionalSecretRefs)
+		if err != nil {
+			return interceptors.Failf(codes.FailedPrecondition, "error getting secret: %v", err)
+		}
+
+		if err := interceptors.ValidateAny(secretTokens, func(secret []byte) error {
+			return gh.ValidateSignature(header, []byte(interceptors.SignedBody(r)), secret)
+		}); err != nil {
+			return interceptors.Fail(codes.FailedPrecondition, err.Error())
+		}
+	}
+
+	// Bitbucket Server sends an empty body when testing the webhook connection (diagnostics:ping)
+	if r.Body == "" {
+		return &triggersv1.InterceptorResponse{
+			Continue: true,
+		}
+	}
+
+	var pl payload
+	if err := json.Unmarshal([]byte(r.Body), &pl); err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "failed to parse body as a Bitbucket Server payload: %v", err)
+	}
+
+	return &triggersv1.InterceptorResponse{
+		Continue: true,
+		Extensions: map[string]interface{}{
+			ExtensionKey: normalize(actualEvent, pl),
+		},
+	}
+}
+
+// normalize extracts the repository, branch and commit from the push (repo:*) or
+// pull request (pr:*) payload so that bindings do not need to know the payload shape.
+// For pull requests, the branch and commit are the ones of the source ref.
+func normalize(eventKey string, pl payload) map[string]interface{} {
+	out := map[string]interface{}{
+		"eventKey": eventKey,
+	}
+	var repo *repository
+	switch {
+	case pl.PullRequest != nil:
+		from := pl.PullRequest.FromRef
+		repo = &from.Repository
+		out["branch"] = from.DisplayID
+		out["commit"] = from.LatestCommit
+		out["pullRequest"] = map[string]interface{}{
+			"id":           pl.PullRequest.ID,
+			"targetBranch": pl.PullRequest.ToRef.DisplayID,
+		}
+	case len(pl.Changes) > 0:
+		change := pl.Changes[0]
+		repo = pl.Repository
+		out["commit"] = change.ToHash
+		if strings.EqualFold(change.Ref.Type, "TAG") {
+			out["tag"] = change.Ref.DisplayID
+		} else {
+			out["branch"] = change.Ref.DisplayID
+		}
+	default:
+		repo = pl.Repository
+	}
+	if repo != nil {
+		out["repository"] = map[string]interface{}{
+			"project": repo.Project.Key,
+			"slug":    repo.Slug,
+			"name":    repo.Name,
+		}
+	}
+	return out
+}
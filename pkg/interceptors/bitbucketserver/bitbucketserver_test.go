@@ -0,0 +1,200 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitbucketserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/pkg/interceptors/interceptorstest"
+	"github.com/tektoncd/triggers/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+)
+
+const (
+	pushBody = `{
+  "eventKey": "repo:refs_changed",
+  "repository": {"slug": "api", "name": "API", "project": {"key": "PLAT"}},
+  "changes": [{"ref": {"id": "refs/heads/main", "displayId": "main", "type": "BRANCH"}, "toHash": "abc123"}]
+}`
+	tagBody = `{
+  "eventKey": "repo:refs_changed",
+  "repository": {"slug": "api", "name": "API", "project": {"key": "PLAT"}},
+  "changes": [{"ref": {"id": "refs/tags/v1.0.0", "displayId": "v1.0.0", "type": "TAG"}, "toHash": "def456"}]
+}`
+	prBody = `{
+  "eventKey": "pr:opened",
+  "pullRequest": {
+    "id": 7,
+    "fromRef": {"displayId": "feature", "latestCommit": "fff000", "repository": {"slug": "fork", "name": "Fork", "project": {"key": "~USER"}}},
+    "toRef": {"displayId": "main", "latestCommit": "aaa111", "repository": {"slug": "api", "name": "API", "project": {"key": "PLAT"}}}
+  }
+}`
+)
+
+var secret = &corev1.Secret{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "mysecret",
+		Namespace: metav1.NamespaceDefault,
+	},
+	Data: map[string][]byte{
+		"token": []byte("secret"),
+	},
+}
+
+func newRequest(body string, params *triggersv1.BitbucketInterceptor, eventKey, signature string) *triggersv1.InterceptorRequest {
+	req := interceptorstest.NewRequest(body, http.Header{"Content-Type": []string{"application/json"}}, map[string]interface{}{
+		"eventTypes": params.EventTypes,
+		"secretRef":  params.SecretRef,
+	})
+	if eventKey != "" {
+		req.Header["X-Event-Key"] = []string{eventKey}
+	}
+	if signature != "" {
+		req.Header["X-Hub-Signature"] = []string{signature}
+	}
+	return req
+}
+
+func TestInterceptor_Process_ShouldContinue(t *testing.T) {
+	secretRef := &triggersv1.SecretRef{SecretName: "mysecret", SecretKey: "token"}
+	for _, tc := range []struct {
+		name           string
+		params         *triggersv1.BitbucketInterceptor
+		body           string
+		eventKey       string
+		signature      string
+		wantExtensions map[string]interface{}
+	}{{
+		name:      "push with valid sha256 signature",
+		params:    &triggersv1.BitbucketInterceptor{SecretRef: secretRef, EventTypes: []string{"repo:refs_changed"}},
+		body:      pushBody,
+		eventKey:  "repo:refs_changed",
+		signature: test.HMACHeader(t, "secret", []byte(pushBody), "sha256"),
+		wantExtensions: map[string]interface{}{
+			ExtensionKey: map[string]interface{}{
+				"eventKey":   "repo:refs_changed",
+				"branch":     "main",
+				"commit":     "abc123",
+				"repository": map[string]interface{}{"project": "PLAT", "slug": "api", "name": "API"},
+			},
+		},
+	}, {
+		name:     "tag push",
+		params:   &triggersv1.BitbucketInterceptor{},
+		body:     tagBody,
+		eventKey: "repo:refs_changed",
+		wantExtensions: map[string]interface{}{
+			ExtensionKey: map[string]interface{}{
+				"eventKey":   "repo:refs_changed",
+				"tag":        "v1.0.0",
+				"commit":     "def456",
+				"repository": map[string]interface{}{"project": "PLAT", "slug": "api", "name": "API"},
+			},
+		},
+	}, {
+		name:     "pull request uses source ref",
+		params:   &triggersv1.BitbucketInterceptor{EventTypes: []string{"pr:opened"}},
+		body:     prBody,
+		eventKey: "pr:opened",
+		wantExtensions: map[string]interface{}{
+			ExtensionKey: map[string]interface{}{
+				"eventKey":    "pr:opened",
+				"branch":      "feature",
+				"commit":      "fff000",
+				"pullRequest": map[string]interface{}{"id": float64(7), "targetBranch": "main"},
+				"repository":  map[string]interface{}{"project": "~USER", "slug": "fork", "name": "Fork"},
+			},
+		},
+	}, {
+		name:     "empty body for connection test",
+		params:   &triggersv1.BitbucketInterceptor{},
+		eventKey: "diagnostics:ping",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, secret)
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+
+			res := w.Process(ctx, newRequest(tc.body, tc.params, tc.eventKey, tc.signature))
+			if !res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be true but got %t. \nStatus.Err(): %v", res.Continue, res.Status.Err())
+			}
+			// Round trip through JSON as the sink would receive the response
+			b, err := json.Marshal(res.Extensions)
+			if err != nil {
+				t.Fatalf("json.Marshal() failed: %v", err)
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatalf("json.Unmarshal() failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantExtensions, got); diff != "" {
+				t.Errorf("Extensions mismatch (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestInterceptor_Process_ShouldNotContinue(t *testing.T) {
+	secretRef := &triggersv1.SecretRef{SecretName: "mysecret", SecretKey: "token"}
+	for _, tc := range []struct {
+		name      string
+		params    *triggersv1.BitbucketInterceptor
+		body      string
+		eventKey  string
+		signature string
+	}{{
+		name:      "invalid signature",
+		params:    &triggersv1.BitbucketInterceptor{SecretRef: secretRef},
+		body:      pushBody,
+		eventKey:  "repo:refs_changed",
+		signature: test.HMACHeader(t, "othersecret", []byte(pushBody), "sha256"),
+	}, {
+		name:     "missing signature",
+		params:   &triggersv1.BitbucketInterceptor{SecretRef: secretRef},
+		body:     pushBody,
+		eventKey: "repo:refs_changed",
+	}, {
+		name:     "event key not allowed",
+		params:   &triggersv1.BitbucketInterceptor{EventTypes: []string{"pr:opened"}},
+		body:     pushBody,
+		eventKey: "repo:refs_changed",
+	}, {
+		name:     "invalid body",
+		params:   &triggersv1.BitbucketInterceptor{},
+		body:     `not json`,
+		eventKey: "repo:refs_changed",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, secret)
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+
+			res := w.Process(ctx, newRequest(tc.body, tc.params, tc.eventKey, tc.signature))
+			if res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be false but got %t", res.Continue)
+			}
+		})
+	}
+}
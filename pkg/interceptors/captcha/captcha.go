@@ -0,0 +1,261 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"google.golang.org/grpc/codes"
+)
+
+var _ triggersv1.InterceptorInterface = (*Interceptor)(nil)
+
+const (
+	// ExtensionKey is the extensions key under which the result of the verification is returned.
+	ExtensionKey = "captcha"
+
+	// ProviderRecaptcha verifies Google reCAPTCHA tokens, of v2 and v3.
+	ProviderRecaptcha = "recaptcha"
+	// ProviderTurnstile verifies Cloudflare Turnstile tokens.
+	ProviderTurnstile = "turnstile"
+
+	// defaultTimeout bounds the requests to the siteverify API without a timeout param.
+	defaultTimeout = 10 * time.Second
+	// maxResponseSize bounds the size of the responses of the siteverify API read.
+	maxResponseSize = 64 * 1024
+)
+
+// provider is the siteverify API of a CAPTCHA provider, and the field of the forms it protects that
+// holds the token.
+type provider struct {
+	verifyURL  string
+	tokenField string
+}
+
+var providers = map[string]provider{
+	ProviderRecaptcha: {verifyURL: "https://www.google.com/recaptcha/api/siteverify", tokenField: "g-recaptcha-response"},
+	ProviderTurnstile: {verifyURL: "https://challenges.cloudflare.com/turnstile/v0/siteverify", tokenField: "cf-turnstile-response"},
+}
+
+// secretErrorCodes are the error codes of the siteverify API caused by the secret of the interceptor
+// rather than by the token of the event.
+var secretErrorCodes = []string{"missing-input-secret", "invalid-input-secret"}
+
+// InterceptorParams provides a webhook to intercept and pre-process events.
+type InterceptorParams struct {
+	// Provider is the CAPTCHA provider whose tokens are verified: recaptcha or turnstile.
+	Provider string `json:"provider,omitempty"`
+	// VerifyURL, if set, is the HTTPS URL of the siteverify API, instead of the one of the provider, e.g.
+	// of a compatible provider or of an enterprise endpoint.
+	VerifyURL string `json:"verifyURL,omitempty"`
+	// SecretRef references the secret key of the site, which authenticates the interceptor to the provider.
+	SecretRef *triggersv1.SecretRef `json:"secretRef,omitempty"`
+	// TokenField is the top-level field of the body holding the token. Defaults to the field of the
+	// provider's widget, e.g. g-recaptcha-response.
+	TokenField string `json:"tokenField,omitempty"`
+	// TokenHeader, if set, is the header holding the token, instead of a field of the body.
+	TokenHeader string `json:"tokenHeader,omitempty"`
+	// MinScore, if set, is the minimum score, from 0.0 to 1.0, of score-based CAPTCHAs such as
+	// reCAPTCHA v3. Verifications without a score are rejected.
+	MinScore *float64 `json:"minScore,omitempty"`
+	// Actions, if set, lists the allowed actions of the verified tokens.
+	Actions []string `json:"actions,omitempty"`
+	// Hostnames, if set, lists the allowed hostnames of the sites where the tokens were issued.
+	Hostnames []string `json:"hostnames,omitempty"`
+	// Timeout is the time, as a duration e.g. 5s, after which the request to the siteverify API is
+	// abandoned. Defaults to 10s.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// verifyResponse is the result of the siteverify API of the providers.
+type verifyResponse struct {
+	Success     bool     `json:"success"`
+	Score       *float64 `json:"score,omitempty"`
+	Action      string   `json:"action,omitempty"`
+	Hostname    string   `json:"hostname,omitempty"`
+	ChallengeTS string   `json:"challenge_ts,omitempty"`
+	ErrorCodes  []string `json:"error-codes,omitempty"`
+}
+
+// Interceptor verifies the CAPTCHA tokens of events submitted by humans, e.g. from the forms of a
+// self-service portal, with the siteverify API of the provider, so that bots don't fire triggers.
+type Interceptor struct {
+	SecretGetter interceptors.SecretGetter
+
+	client *http.Client
+}
+
+func NewInterceptor(sg interceptors.SecretGetter) *Interceptor {
+	return &Interceptor{
+		SecretGetter: sg,
+		client:       http.DefaultClient,
+	}
+}
+
+func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequest) *triggersv1.InterceptorResponse {
+	p := InterceptorParams{}
+	if err := interceptors.UnmarshalParams(r.InterceptorParams, &p); err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "failed to parse interceptor params: %v", err)
+	}
+	pv, ok := providers[p.Provider]
+	if !ok {
+		return interceptors.Failf(codes.InvalidArgument, "captcha interceptor provider %q must be %s or %s", p.Provider, ProviderRecaptcha, ProviderTurnstile)
+	}
+	if p.VerifyURL != "" {
+		if u, err := url.Parse(p.VerifyURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return interceptors.Failf(codes.InvalidArgument, "captcha interceptor verifyURL %q must be an HTTPS URL", p.VerifyURL)
+		}
+		pv.verifyURL = p.VerifyURL
+	}
+	if p.TokenField != "" {
+		pv.tokenField = p.TokenField
+	}
+	if p.SecretRef == nil || p.SecretRef.SecretKey == "" {
+		return interceptors.Fail(codes.InvalidArgument, "captcha interceptor secretRef.secretKey is empty")
+	}
+	if p.MinScore != nil && (*p.MinScore < 0 || *p.MinScore > 1) {
+		return interceptors.Failf(codes.InvalidArgument, "captcha interceptor minScore %v must be between 0 and 1", *p.MinScore)
+	}
+	timeout := defaultTimeout
+	if p.Timeout != "" {
+		d, err := time.ParseDuration(p.Timeout)
+		if err != nil || d <= 0 {
+			return interceptors.Failf(codes.InvalidArgument, "captcha interceptor timeout %q must be a positive duration", p.Timeout)
+		}
+		timeout = d
+	}
+
+	var token string
+	if p.TokenHeader != "" {
+		token = interceptors.Canonical(r.Header).Get(p.TokenHeader)
+		if token == "" {
+			return interceptors.Failf(codes.Unauthenticated, "no captcha token in the %s header", http.CanonicalHeaderKey(p.TokenHeader))
+		}
+	} else {
+		var body map[string]interface{}
+		if err := json.Unmarshal([]byte(r.Body), &body); err != nil {
+			return interceptors.Failf(codes.InvalidArgument, "failed to parse body as JSON: %v", err)
+		}
+		token, _ = body[pv.tokenField].(string)
+		if token == "" {
+			return interceptors.Failf(codes.Unauthenticated, "no captcha token in the %s field", pv.tokenField)
+		}
+	}
+
+	if r.Context == nil {
+		return interceptors.Failf(codes.InvalidArgument, "no request context passed")
+	}
+	ns, _ := triggersv1.ParseTriggerID(r.Context.TriggerID)
+	secret, err := w.SecretGetter.Get(ctx, ns, p.SecretRef)
+	if err != nil {
+		return interceptors.Failf(codes.FailedPrecondition, "error getting secret: %v", err)
+	}
+
+	res, err := w.verify(ctx, pv.verifyURL, timeout, string(secret), token, r.Context.ClientIP)
+	if err != nil {
+		return interceptors.Failf(codes.Unavailable, "failed to verify the captcha token: %v", err)
+	}
+	if !res.Success {
+		for _, c := range res.ErrorCodes {
+			if interceptors.Contains(secretErrorCodes, c) {
+				return interceptors.Failf(codes.FailedPrecondition, "captcha provider rejected the secret: %s", c)
+			}
+		}
+		reason := "token is not valid"
+		if len(res.ErrorCodes) > 0 {
+			reason = strings.Join(res.ErrorCodes, ", ")
+		}
+		return interceptors.Failf(codes.PermissionDenied, "captcha verification failed: %s", reason)
+	}
+	if p.MinScore != nil {
+		if res.Score == nil {
+			return interceptors.Failf(codes.PermissionDenied, "captcha verification returned no score, minScore %v requires a score-based captcha", *p.MinScore)
+		}
+		if *res.Score < *p.MinScore {
+			return interceptors.Failf(codes.PermissionDenied, "captcha score %v is below the minimum score %v", *res.Score, *p.MinScore)
+		}
+	}
+	if p.Actions != nil && !interceptors.Contains(p.Actions, res.Action) {
+		return interceptors.Failf(codes.PermissionDenied, "captcha action %q is not allowed", res.Action)
+	}
+	if p.Hostnames != nil && !interceptors.Contains(p.Hostnames, res.Hostname) {
+		return interceptors.Failf(codes.PermissionDenied, "captcha hostname %q is not allowed", res.Hostname)
+	}
+
+	fields := map[string]interface{}{
+		"action":      res.Action,
+		"hostname":    res.Hostname,
+		"challengeTs": res.ChallengeTS,
+	}
+	if res.Score != nil {
+		fields["score"] = *res.Score
+	}
+	return &triggersv1.InterceptorResponse{
+		Continue: true,
+		Extensions: map[string]interface{}{
+			ExtensionKey: fields,
+		},
+	}
+}
+
+// verify posts the token to the siteverify API at verifyURL, with the secret and the IP address of the
+// sender if known, and returns its result.
+func (w *Interceptor) verify(ctx context.Context, verifyURL string, timeout time.Duration, secret, token, remoteIP string) (*verifyResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	form := url.Values{"secret": {secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("POST %s failed: %w", verifyURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("POST %s returned %s", verifyURL, resp.Status)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response of %s: %w", verifyURL, err)
+	}
+	res := &verifyResponse{}
+	if err := json.Unmarshal(b, res); err != nil {
+		return nil, fmt.Errorf("failed to parse the response of %s: %w", verifyURL, err)
+	}
+	return res, nil
+}
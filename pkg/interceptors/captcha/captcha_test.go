@@ -0,0 +1,302 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package captcha
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/pkg/interceptors/interceptorstest"
+	"github.com/tektoncd/triggers/test"
+	"google.golang.org/grpc/codes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+)
+
+var secret = &corev1.Secret{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "captcha",
+		Namespace: metav1.NamespaceDefault,
+	},
+	Data: map[string][]byte{
+		"secretKey": []byte("site-secret"),
+		"wrongKey":  []byte("old-secret"),
+	},
+}
+
+// siteverify returns a fake siteverify API, whose results depend on the tokens, and records the IP
+// addresses of the senders it was given.
+func siteverify(t *testing.T, remoteIPs *[]string) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Method != http.MethodPost {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		*remoteIPs = append(*remoteIPs, r.PostForm.Get("remoteip"))
+		var res string
+		switch {
+		case r.PostForm.Get("secret") != "site-secret":
+			res = `{"success":false,"error-codes":["invalid-input-secret"]}`
+		case r.PostForm.Get("response") == "human":
+			res = `{"success":true,"score":0.9,"action":"submit","hostname":"portal.example.com","challenge_ts":"2022-10-01T12:00:00Z"}`
+		case r.PostForm.Get("response") == "bot":
+			res = `{"success":true,"score":0.1,"action":"submit","hostname":"portal.example.com"}`
+		case r.PostForm.Get("response") == "checkbox":
+			res = `{"success":true,"action":"login","hostname":"other.example.com"}`
+		case r.PostForm.Get("response") == "slow":
+			time.Sleep(100 * time.Millisecond)
+			res = `{"success":true}`
+		case r.PostForm.Get("response") == "broken":
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		default:
+			res = `{"success":false,"error-codes":["timeout-or-duplicate"]}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(res))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func newRequest(body string, header http.Header, params map[string]interface{}) *triggersv1.InterceptorRequest {
+	req := interceptorstest.NewRequest(body, header, params)
+	req.Context.ClientIP = "203.0.113.7"
+	return req
+}
+
+// newInterceptor returns a function processing requests with an interceptor verifying the tokens with ts.
+func newInterceptor(t *testing.T, ts *httptest.Server) func(*triggersv1.InterceptorRequest) *triggersv1.InterceptorResponse {
+	t.Helper()
+	ctx, _ := test.SetupFakeContext(t)
+	ctx, clientset := fakekubeclient.With(ctx, secret)
+	w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+	w.client = ts.Client()
+	return func(r *triggersv1.InterceptorRequest) *triggersv1.InterceptorResponse {
+		return w.Process(ctx, r)
+	}
+}
+
+func TestInterceptor_Process_ShouldContinue(t *testing.T) {
+	var remoteIPs []string
+	ts := siteverify(t, &remoteIPs)
+	process := newInterceptor(t, ts)
+	secretRef := map[string]interface{}{"secretName": "captcha", "secretKey": "secretKey"}
+
+	for _, tc := range []struct {
+		name   string
+		body   string
+		header http.Header
+		params map[string]interface{}
+		want   map[string]interface{}
+	}{{
+		name:   "recaptcha form field",
+		body:   `{"g-recaptcha-response":"human","app":"portal"}`,
+		params: map[string]interface{}{"provider": "recaptcha", "verifyURL": ts.URL, "secretRef": secretRef},
+		want:   map[string]interface{}{"score": 0.9, "action": "submit", "hostname": "portal.example.com", "challengeTs": "2022-10-01T12:00:00Z"},
+	}, {
+		name:   "turnstile form field",
+		body:   `{"cf-turnstile-response":"checkbox"}`,
+		params: map[string]interface{}{"provider": "turnstile", "verifyURL": ts.URL, "secretRef": secretRef},
+		want:   map[string]interface{}{"action": "login", "hostname": "other.example.com", "challengeTs": ""},
+	}, {
+		name:   "token field",
+		body:   `{"captcha":"checkbox"}`,
+		params: map[string]interface{}{"provider": "turnstile", "verifyURL": ts.URL, "secretRef": secretRef, "tokenField": "captcha"},
+		want:   map[string]interface{}{"action": "login", "hostname": "other.example.com", "challengeTs": ""},
+	}, {
+		name:   "token header",
+		body:   `not json`,
+		header: http.Header{"X-Captcha-Token": {"human"}},
+		params: map[string]interface{}{"provider": "recaptcha", "verifyURL": ts.URL, "secretRef": secretRef, "tokenHeader": "x-captcha-token"},
+		want:   map[string]interface{}{"score": 0.9, "action": "submit", "hostname": "portal.example.com", "challengeTs": "2022-10-01T12:00:00Z"},
+	}, {
+		name: "score, action and hostname",
+		body: `{"g-recaptcha-response":"human"}`,
+		params: map[string]interface{}{"provider": "recaptcha", "verifyURL": ts.URL, "secretRef": secretRef,
+			"minScore": 0.5, "actions": []string{"submit"}, "hostnames": []string{"portal.example.com"}},
+		want: map[string]interface{}{"score": 0.9, "action": "submit", "hostname": "portal.example.com", "challengeTs": "2022-10-01T12:00:00Z"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			res := process(newRequest(tc.body, tc.header, tc.params))
+			if !res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be true but got %t. \nStatus.Err(): %v", res.Continue, res.Status.Err())
+			}
+			// The extensions are compared as the JSON the sink receives.
+			b, err := json.Marshal(res.Extensions[ExtensionKey])
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Interceptor.Process() extensions -want +got: %s", diff)
+			}
+		})
+	}
+	if remoteIPs[0] != "203.0.113.7" {
+		t.Errorf("siteverify got remoteip %q, want the client IP of the event", remoteIPs[0])
+	}
+}
+
+func TestInterceptor_Process_ShouldNotContinue(t *testing.T) {
+	var remoteIPs []string
+	ts := siteverify(t, &remoteIPs)
+	secretRef := map[string]interface{}{"secretName": "captcha", "secretKey": "secretKey"}
+	params := func(extra map[string]interface{}) map[string]interface{} {
+		p := map[string]interface{}{"provider": "recaptcha", "verifyURL": ts.URL, "secretRef": secretRef}
+		for k, v := range extra {
+			p[k] = v
+		}
+		return p
+	}
+
+	for _, tc := range []struct {
+		name     string
+		body     string
+		params   map[string]interface{}
+		wantCode codes.Code
+		wantMsg  string
+	}{{
+		name:     "unknown provider",
+		body:     `{"g-recaptcha-response":"human"}`,
+		params:   params(map[string]interface{}{"provider": "hcaptcha"}),
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `captcha interceptor provider "hcaptcha" must be recaptcha or turnstile`,
+	}, {
+		name:     "plain HTTP verifyURL",
+		body:     `{"g-recaptcha-response":"human"}`,
+		params:   params(map[string]interface{}{"verifyURL": "http://siteverify.example.com"}),
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `captcha interceptor verifyURL "http://siteverify.example.com" must be an HTTPS URL`,
+	}, {
+		name:     "no secretRef",
+		body:     `{"g-recaptcha-response":"human"}`,
+		params:   params(map[string]interface{}{"secretRef": nil}),
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "captcha interceptor secretRef.secretKey is empty",
+	}, {
+		name:     "minScore out of range",
+		body:     `{"g-recaptcha-response":"human"}`,
+		params:   params(map[string]interface{}{"minScore": 5}),
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "captcha interceptor minScore 5 must be between 0 and 1",
+	}, {
+		name:     "invalid timeout",
+		body:     `{"g-recaptcha-response":"human"}`,
+		params:   params(map[string]interface{}{"timeout": "soon"}),
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `captcha interceptor timeout "soon" must be a positive duration`,
+	}, {
+		name:     "no token",
+		body:     `{"app":"portal"}`,
+		params:   params(nil),
+		wantCode: codes.Unauthenticated,
+		wantMsg:  "no captcha token in the g-recaptcha-response field",
+	}, {
+		name:     "no token header",
+		body:     `{"g-recaptcha-response":"human"}`,
+		params:   params(map[string]interface{}{"tokenHeader": "X-Captcha-Token"}),
+		wantCode: codes.Unauthenticated,
+		wantMsg:  "no captcha token in the X-Captcha-Token header",
+	}, {
+		name:     "body not JSON",
+		body:     `g-recaptcha-response=human`,
+		params:   params(nil),
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "failed to parse body as JSON",
+	}, {
+		name:     "missing secret",
+		body:     `{"g-recaptcha-response":"human"}`,
+		params:   params(map[string]interface{}{"secretRef": map[string]interface{}{"secretName": "other", "secretKey": "secretKey"}}),
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "error getting secret",
+	}, {
+		name:     "invalid token",
+		body:     `{"g-recaptcha-response":"replayed"}`,
+		params:   params(nil),
+		wantCode: codes.PermissionDenied,
+		wantMsg:  "captcha verification failed: timeout-or-duplicate",
+	}, {
+		name:     "wrong secret",
+		body:     `{"g-recaptcha-response":"human"}`,
+		params:   params(map[string]interface{}{"secretRef": map[string]interface{}{"secretName": "captcha", "secretKey": "wrongKey"}}),
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "captcha provider rejected the secret: invalid-input-secret",
+	}, {
+		name:     "score below the minimum",
+		body:     `{"g-recaptcha-response":"bot"}`,
+		params:   params(map[string]interface{}{"minScore": 0.5}),
+		wantCode: codes.PermissionDenied,
+		wantMsg:  "captcha score 0.1 is below the minimum score 0.5",
+	}, {
+		name:     "no score",
+		body:     `{"g-recaptcha-response":"checkbox"}`,
+		params:   params(map[string]interface{}{"minScore": 0.5}),
+		wantCode: codes.PermissionDenied,
+		wantMsg:  "captcha verification returned no score",
+	}, {
+		name:     "action not allowed",
+		body:     `{"g-recaptcha-response":"checkbox"}`,
+		params:   params(map[string]interface{}{"actions": []string{"submit"}}),
+		wantCode: codes.PermissionDenied,
+		wantMsg:  `captcha action "login" is not allowed`,
+	}, {
+		name:     "hostname not allowed",
+		body:     `{"g-recaptcha-response":"checkbox"}`,
+		params:   params(map[string]interface{}{"hostnames": []string{"portal.example.com"}}),
+		wantCode: codes.PermissionDenied,
+		wantMsg:  `captcha hostname "other.example.com" is not allowed`,
+	}, {
+		name:     "provider error",
+		body:     `{"g-recaptcha-response":"broken"}`,
+		params:   params(nil),
+		wantCode: codes.Unavailable,
+		wantMsg:  "500 Internal Server Error",
+	}, {
+		name:     "provider timeout",
+		body:     `{"g-recaptcha-response":"slow"}`,
+		params:   params(map[string]interface{}{"timeout": "10ms"}),
+		wantCode: codes.Unavailable,
+		wantMsg:  "context deadline exceeded",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			process := newInterceptor(t, ts)
+			res := process(newRequest(tc.body, nil, tc.params))
+			if res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be false but got %t", res.Continue)
+			}
+			if res.Status.Code != tc.wantCode {
+				t.Errorf("Interceptor.Process() got status code %v, want %v", res.Status.Code, tc.wantCode)
+			}
+			if !strings.Contains(res.Status.Message, tc.wantMsg) {
+				t.Errorf("Interceptor.Process() got message %q, want it to contain %q", res.Status.Message, tc.wantMsg)
+			}
+		})
+	}
+}
@@ -48,6 +48,11 @@ type Interceptor struct {
 	SecretGetter     interceptors.SecretGetter
 	CEL              *triggersv1.CELInterceptor
 	TriggerNamespace string
+	// Resolver performs the DNS lookups of expressions. Defaults to DefaultResolver if nil.
+	Resolver *Resolver
+	// ClusterInfo is the metadata of the cluster returned by the clusterInfo function. Defaults to
+	// DefaultClusterInfo if nil.
+	ClusterInfo *ClusterInfo
 }
 
 var (
@@ -86,10 +91,30 @@ func evaluate(expr string, env *cel.Env, data map[string]interface{}) (ref.Val,
 	return out, nil
 }
 
-func makeCelEnv(ctx context.Context, ns string, sg interceptors.SecretGetter) (*cel.Env, error) {
+// Check compiles and type checks the CEL expression expr in the environment of the interceptor without
+// evaluating it, e.g. to reject the Triggers whose expressions don't compile when they are applied. The
+// variables have the types they have when events are processed: body, header, extensions and clientCert are
+// maps of strings to dyn values, and requestURL and clientIP are strings. The errors have the positions of
+// the issues in expr.
+func Check(expr string) error {
+	env, err := makeCelEnv(context.Background(), "", nil, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("error creating cel environment: %w", err)
+	}
+	if _, issues := env.Compile(expr); issues != nil && issues.Err() != nil {
+		return issues.Err()
+	}
+	return nil
+}
+
+func makeCelEnv(ctx context.Context, ns string, sg interceptors.SecretGetter, resolver *Resolver, clusterInfo *ClusterInfo, extensions map[string]interface{}) (*cel.Env, error) {
 	mapStrDyn := decls.NewMapType(decls.String, decls.Dyn)
 	return cel.NewEnv(
 		Triggers(ctx, ns, sg),
+		Extensions(extensions),
+		DNS(ctx, resolver),
+		Random(),
+		Cluster(clusterInfo),
 		celext.Strings(),
 		celext.Encoders(),
 		cel.Declarations(
@@ -97,23 +122,120 @@ func makeCelEnv(ctx context.Context, ns string, sg interceptors.SecretGetter) (*
 			decls.NewVar("header", mapStrDyn),
 			decls.NewVar("extensions", mapStrDyn),
 			decls.NewVar("requestURL", decls.String),
+			decls.NewVar("clientCert", mapStrDyn),
+			decls.NewVar("clientIP", decls.String),
 		))
 }
 
-func makeEvalContext(body []byte, h http.Header, url string, extensions map[string]interface{}) (map[string]interface{}, error) {
+func makeEvalContext(body []byte, h http.Header, url string, extensions map[string]interface{}, cert *triggersv1.ClientCertificate, clientIP string, numbers triggersv1.JSONNumbers) (map[string]interface{}, error) {
 	var jsonMap map[string]interface{}
-	err := json.Unmarshal(body, &jsonMap)
+	err := numbers.Unmarshal(body, &jsonMap)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse the body as JSON: %w", err)
 	}
+	if numbers == triggersv1.JSONNumbersExact {
+		jsonMap = celNumbers(jsonMap).(map[string]interface{})
+		extensions, _ = celNumbers(extensions).(map[string]interface{})
+	}
 	return map[string]interface{}{
 		"body":       jsonMap,
 		"header":     h,
 		"requestURL": url,
 		"extensions": extensions,
+		"clientCert": clientCertValues(cert),
+		"clientIP":   clientIP,
 	}, nil
 }
 
+// nativeJSON returns the JSON encoding of val converted to the native type t.
+func nativeJSON(val ref.Val, t reflect.Type) ([]byte, error) {
+	native, err := val.ConvertToNative(t)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(native)
+}
+
+// celNumbers returns a copy of v, a JSON value decoded with exact numbers, in which the json.Number values,
+// which CEL doesn't support, are converted to ints if they are integers that fit in 64 bits, so that they
+// are exact, and to doubles otherwise.
+func celNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = celNumbers(e)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, e := range v {
+			l[i] = celNumbers(e)
+		}
+		return l
+	}
+	return v
+}
+
+// clientCertValues returns the fields of the client certificate of the sender. All the fields are
+// present, and empty if the sender did not present a certificate, so that expressions like
+// clientCert.commonName == 'ci-prod' evaluate to false rather than failing.
+func clientCertValues(cert *triggersv1.ClientCertificate) map[string]interface{} {
+	if cert == nil {
+		cert = &triggersv1.ClientCertificate{}
+	}
+	list := func(l []string) []string {
+		if l == nil {
+			return []string{}
+		}
+		return l
+	}
+	return map[string]interface{}{
+		"subject":        cert.Subject,
+		"commonName":     cert.CommonName,
+		"dnsNames":       list(cert.DNSNames),
+		"uris":           list(cert.URIs),
+		"emailAddresses": list(cert.EmailAddresses),
+	}
+}
+
+// Evaluate evaluates expr against the body, headers, event URL and extensions
+// of r, in the same environment as the CEL interceptor.
+func Evaluate(ctx context.Context, sg interceptors.SecretGetter, expr string, r *triggersv1.InterceptorRequest) (ref.Val, error) {
+	var (
+		ns, url, clientIP string
+		cert              *triggersv1.ClientCertificate
+		numbers           triggersv1.JSONNumbers
+	)
+	if r.Context != nil {
+		ns, _ = triggersv1.ParseTriggerID(r.Context.TriggerID)
+		url = r.Context.EventURL
+		cert = r.Context.ClientCert
+		clientIP = r.Context.ClientIP
+		numbers = r.Context.JSONNumbers
+	}
+	env, err := makeCelEnv(ctx, ns, sg, DefaultResolver, DefaultClusterInfo, r.Extensions)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cel environment: %w", err)
+	}
+
+	var payload = []byte(`{}`)
+	if r.Body != "" {
+		payload = []byte(r.Body)
+	}
+	evalContext, err := makeEvalContext(payload, r.Header, url, r.Extensions, cert, clientIP, numbers)
+	if err != nil {
+		return nil, fmt.Errorf("error making the evaluation context: %w", err)
+	}
+	return evaluate(expr, env, evalContext)
+}
+
 func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequest) *triggersv1.InterceptorResponse {
 	p := triggersv1.CELInterceptor{}
 	if err := interceptors.UnmarshalParams(r.InterceptorParams, &p); err != nil {
@@ -125,7 +247,7 @@ func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequ
 	}
 
 	ns, _ := triggersv1.ParseTriggerID(r.Context.TriggerID)
-	env, err := makeCelEnv(ctx, ns, w.SecretGetter)
+	env, err := makeCelEnv(ctx, ns, w.SecretGetter, w.Resolver, w.ClusterInfo, r.Extensions)
 	if err != nil {
 		return interceptors.Failf(codes.Internal, "error creating cel environment: %v", err)
 	}
@@ -135,7 +257,7 @@ func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequ
 		payload = []byte(r.Body)
 	}
 
-	evalContext, err := makeEvalContext(payload, r.Header, r.Context.EventURL, r.Extensions)
+	evalContext, err := makeEvalContext(payload, r.Header, r.Context.EventURL, r.Extensions, r.Context.ClientCert, r.Context.ClientIP, r.Context.JSONNumbers)
 	if err != nil {
 		return interceptors.Failf(codes.InvalidArgument, "error making the evaluation context: %v", err)
 	}
@@ -163,6 +285,9 @@ func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequ
 
 		var raw interface{}
 		var b []byte
+		// The ints of exact EventListeners are marshalled as they are, rather than converted to the
+		// doubles of structpb values.
+		exact := r.Context.JSONNumbers == triggersv1.JSONNumbersExact
 
 		switch val.(type) {
 		// this causes types.Bytes to be rendered as a Base64 string this is
@@ -176,12 +301,25 @@ func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequ
 			if err == nil {
 				b, err = raw.(*structpb.Value).MarshalJSON()
 			}
-		case types.Double, types.Int:
+		case types.Int:
+			if exact {
+				b, err = json.Marshal(int64(val.(types.Int)))
+				break
+			}
+			raw, err = val.ConvertToNative(structType)
+			if err == nil {
+				b, err = raw.(*structpb.Value).MarshalJSON()
+			}
+		case types.Double:
 			raw, err = val.ConvertToNative(structType)
 			if err == nil {
 				b, err = raw.(*structpb.Value).MarshalJSON()
 			}
 		case traits.Lister:
+			if exact {
+				b, err = nativeJSON(val, reflect.TypeOf([]interface{}{}))
+				break
+			}
 			raw, err = val.ConvertToNative(listType)
 			if err == nil {
 				s, err := protojson.Marshal(raw.(proto.Message))
@@ -190,6 +328,10 @@ func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequ
 				}
 			}
 		case traits.Mapper:
+			if exact {
+				b, err = nativeJSON(val, reflect.TypeOf(map[string]interface{}{}))
+				break
+			}
 			raw, err = val.ConvertToNative(mapType)
 			if err == nil {
 				s, err := protojson.Marshal(raw.(proto.Message))
@@ -230,7 +372,7 @@ func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequ
 	}
 
 	extensionsMap := map[string]interface{}{}
-	if err := json.Unmarshal(extensions, &extensionsMap); err != nil {
+	if err := r.Context.JSONNumbers.Unmarshal(extensions, &extensionsMap); err != nil {
 		return interceptors.Failf(codes.Internal, "failed to unmarshal extensions into map: %v", err)
 	}
 	return &triggersv1.InterceptorResponse{
@@ -285,6 +285,114 @@ func TestInterceptor_Process(t *testing.T) {
 	}
 }
 
+func TestInterceptor_Process_ClientCert(t *testing.T) {
+	prod := &triggersv1.ClientCertificate{
+		Subject:    "CN=ci-prod,O=Example",
+		CommonName: "ci-prod",
+		DNSNames:   []string{"ci.example.com"},
+		URIs:       []string{"spiffe://example.com/ci"},
+	}
+	tests := []struct {
+		name   string
+		filter string
+		cert   *triggersv1.ClientCertificate
+		want   bool
+	}{{
+		name:   "matching common name",
+		filter: "clientCert.commonName == 'ci-prod'",
+		cert:   prod,
+		want:   true,
+	}, {
+		name:   "matching subject",
+		filter: "clientCert.subject == 'CN=ci-prod,O=Example'",
+		cert:   prod,
+		want:   true,
+	}, {
+		name:   "matching SANs",
+		filter: "'ci.example.com' in clientCert.dnsNames && 'spiffe://example.com/ci' in clientCert.uris",
+		cert:   prod,
+		want:   true,
+	}, {
+		name:   "other common name",
+		filter: "clientCert.commonName == 'ci-prod'",
+		cert:   &triggersv1.ClientCertificate{CommonName: "ci-dev"},
+		want:   false,
+	}, {
+		name:   "no client certificate",
+		filter: "clientCert.commonName == 'ci-prod' || 'ci.example.com' in clientCert.dnsNames",
+		want:   false,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := test.SetupFakeContext(t)
+			w := &Interceptor{
+				SecretGetter: interceptors.DefaultSecretGetter(fakekubeclient.Get(ctx).CoreV1()),
+			}
+			res := w.Process(ctx, &triggersv1.InterceptorRequest{
+				Body: `{}`,
+				InterceptorParams: map[string]interface{}{
+					"filter": tt.filter,
+				},
+				Context: &triggersv1.TriggerContext{
+					EventURL:   "https://testing.example.com",
+					EventID:    "abcde",
+					TriggerID:  fmt.Sprintf("namespaces/%s/triggers/example-trigger", testNS),
+					ClientCert: tt.cert,
+				},
+			})
+			if res.Continue != tt.want {
+				t.Fatalf("cel.Process() continue = %t, want %t: %v", res.Continue, tt.want, res.Status.Err())
+			}
+			if !tt.want && res.Status.Code != codes.FailedPrecondition {
+				t.Errorf("cel.Process() code = %v, want %v: %v", res.Status.Code, codes.FailedPrecondition, res.Status.Err())
+			}
+		})
+	}
+}
+
+func TestInterceptor_Process_JSONNumbers(t *testing.T) {
+	body := `{"id": 1234567890123456789, "ratio": 0.5}`
+	p := triggersv1.CELInterceptor{
+		Filter: "body.id == 1234567890123456789 && extensions.count > 1.5 && body.ratio < 1",
+		Overlays: []triggersv1.CELOverlay{
+			{Key: "id", Expression: "body.id"},
+			{Key: "ids", Expression: "[body.id, extensions.count]"},
+			{Key: "item", Expression: "{'id': body.id}"},
+			{Key: "ratio", Expression: "body.ratio"},
+		},
+	}
+	ctx, _ := test.SetupFakeContext(t)
+	w := &Interceptor{
+		SecretGetter: interceptors.DefaultSecretGetter(fakekubeclient.Get(ctx).CoreV1()),
+	}
+	res := w.Process(ctx, &triggersv1.InterceptorRequest{
+		Body:       body,
+		Extensions: map[string]interface{}{"count": json.Number("2")},
+		InterceptorParams: map[string]interface{}{
+			"filter":   p.Filter,
+			"overlays": p.Overlays,
+		},
+		Context: &triggersv1.TriggerContext{
+			EventURL:    "https://testing.example.com",
+			EventID:     "abcde",
+			TriggerID:   fmt.Sprintf("namespaces/%s/triggers/example-trigger", testNS),
+			JSONNumbers: triggersv1.JSONNumbersExact,
+		},
+	})
+	if !res.Continue {
+		t.Fatalf("cel.Process() unexpectedly returned continue: false. Response is: %v", res.Status.Err())
+	}
+	want := map[string]interface{}{
+		"id":    json.Number("1234567890123456789"),
+		"ids":   []interface{}{json.Number("1234567890123456789"), json.Number("2")},
+		"item":  map[string]interface{}{"id": json.Number("1234567890123456789")},
+		"ratio": json.Number("0.5"),
+	}
+	if diff := cmp.Diff(want, res.Extensions); diff != "" {
+		t.Errorf("cel.Process() did return correct extensions (-want +got): %v", diff)
+	}
+}
+
 func TestInterceptor_Process_Error(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -439,7 +547,14 @@ func TestExpressionEvaluation(t *testing.T) {
 	header := http.Header{}
 	header.Add("X-Test-Header", "value")
 	req := httptest.NewRequest(http.MethodPost, "https://example.com/testing?param=value", nil)
-	evalEnv := map[string]interface{}{"body": jsonMap, "header": header, "requestURL": req.URL.String()}
+	extensions := map[string]interface{}{
+		"enrich": map[string]interface{}{
+			"team":  "platform",
+			"owner": nil,
+		},
+		"empty": "",
+	}
+	evalEnv := map[string]interface{}{"body": jsonMap, "header": header, "requestURL": req.URL.String(), "extensions": extensions}
 	tests := []struct {
 		name   string
 		expr   string
@@ -589,6 +704,206 @@ func TestExpressionEvaluation(t *testing.T) {
 			expr: "body.jsonArray.marshalJSON()",
 			want: types.String(`["one","two"]`),
 		},
+		{
+			name: "canonical JSON of an object",
+			expr: "canonicalJSON(body.jsonObject)",
+			want: types.String(`{"integer":2,"string":"value"}`),
+		},
+		{
+			name: "canonical JSON of nested objects and arrays",
+			expr: "canonicalJSON({'b': [1, {'z': true, 'a': null}], 'a': 'x<y'})",
+			want: types.String(`{"a":"x<y","b":[1,{"a":null,"z":true}]}`),
+		},
+		{
+			name: "canonical JSON ignores field order",
+			expr: `canonicalJSON('{"b": 1, "a": {"d": [2, 1], "c": 3.5}}'.parseJSON()) == canonicalJSON('{"a":{"c":3.5, "d":[2,1]},"b":1}'.parseJSON())`,
+			want: types.True,
+		},
+		{
+			name: "canonical JSON of a string",
+			expr: "canonicalJSON(body.value)",
+			want: types.String(`"testing"`),
+		},
+		{
+			name: "JSON patch of an object",
+			expr: "canonicalJSON(body.jsonObject.jsonPatch([{'op': 'add', 'path': '/added', 'value': [1]}, {'op': 'replace', 'path': '/string', 'value': 'replaced'}, {'op': 'remove', 'path': '/integer'}]))",
+			want: types.String(`{"added":[1],"string":"replaced"}`),
+		},
+		{
+			name: "JSON patch moving and copying values",
+			expr: "canonicalJSON({'a': {'b': 1}, 'c': 'd'}.jsonPatch([{'op': 'move', 'from': '/a/b', 'path': '/b'}, {'op': 'copy', 'from': '/c', 'path': '/a/c'}, {'op': 'test', 'path': '/b', 'value': 1}]))",
+			want: types.String(`{"a":{"c":"d"},"b":1,"c":"d"}`),
+		},
+		{
+			name: "JSON patch returns a value usable in expressions",
+			expr: "body.jsonPatch([{'op': 'add', 'path': '/extra', 'value': 'yes'}]).extra == 'yes' && !has(body.extra)",
+			want: types.True,
+		},
+		{
+			name: "empty JSON patch",
+			expr: "canonicalJSON(body.jsonObject.jsonPatch([]))",
+			want: types.String(`{"integer":2,"string":"value"}`),
+		},
+		{
+			name: "count matching list elements",
+			expr: "[{'state': 'APPROVED'}, {'state': 'COMMENTED'}, {'state': 'APPROVED'}].count(r, r.state == 'APPROVED')",
+			want: types.Int(2),
+		},
+		{
+			name: "count as a threshold",
+			expr: "body.jsonArray.count(f, f.startsWith('t')) <= 1",
+			want: types.True,
+		},
+		{
+			name: "count map keys",
+			expr: "body.jsonObject.count(k, k.size() > 6)",
+			want: types.Int(1),
+		},
+		{
+			name: "count nested lists",
+			expr: "[[1, 2], [3], []].count(l, l.exists(x, x > 1))",
+			want: types.Int(2),
+		},
+		{
+			name: "count empty list",
+			expr: "[].count(x, x > 0)",
+			want: types.Int(0),
+		},
+		{
+			name: "join strings",
+			expr: "join(body.jsonArray, ',')",
+			want: types.String("one,two"),
+		},
+		{
+			name: "join mapped elements",
+			expr: "join([{'login': 'alice'}, {'login': 'bob'}].map(r, r.login), ', ')",
+			want: types.String("alice, bob"),
+		},
+		{
+			name: "join scalars",
+			expr: "join([1, 2.0, 2.5, 3u, true, b'bytes', duration('90s')], ' ')",
+			want: types.String("1 2 2.5 3 true bytes 90s"),
+		},
+		{
+			name: "join maps, lists and null",
+			expr: "join([{'b': 1, 'a': 'x'}, [1, 'two'], null], ';')",
+			want: types.String(`{"a":"x","b":1};[1,"two"];null`),
+		},
+		{
+			name: "join empty list",
+			expr: "join([], ',')",
+			want: types.String(""),
+		},
+		{
+			name: "join with empty separator",
+			expr: "join(['a', 'b'], '')",
+			want: types.String("ab"),
+		},
+		{
+			name: "intersect watched files",
+			expr: "intersect(['README.md', 'go.sum', 'go.mod', 'go.sum'], ['go.mod', 'go.sum', 'Makefile']) == ['go.sum', 'go.mod']",
+			want: types.True,
+		},
+		{
+			name: "intersect without common elements",
+			expr: "intersect(body.jsonArray, ['three']).size() == 0",
+			want: types.True,
+		},
+		{
+			name: "difference with missing labels",
+			expr: "difference(['lgtm', 'approved', 'lgtm'], ['approved', 'needs-rebase']) == ['lgtm']",
+			want: types.True,
+		},
+		{
+			name: "difference with all labels",
+			expr: "difference(['lgtm'], ['lgtm', 'approved']) == []",
+			want: types.True,
+		},
+		{
+			name: "union",
+			expr: "union(['alice', 'bob', 'alice'], ['carol', 'bob']) == ['alice', 'bob', 'carol']",
+			want: types.True,
+		},
+		{
+			name: "union of empty lists",
+			expr: "union([], []) == []",
+			want: types.True,
+		},
+		{
+			name: "intersect numbers",
+			expr: "intersect([1, 2, 3], [3, 2]) == [2, 3]",
+			want: types.True,
+		},
+		{
+			name: "intersect maps",
+			expr: "intersect([{'name': 'a'}, {'name': 'b'}], [{'name': 'b'}]) == [{'name': 'b'}]",
+			want: types.True,
+		},
+		{
+			name: "redact matches",
+			expr: "redact('token=abc123 id=42', '[0-9]+')",
+			want: types.String("token=abc*** id=**"),
+		},
+		{
+			name: "redact as a method",
+			expr: "'Bearer s3cr3t'.redact('Bearer (.+)')",
+			want: types.String("*************"),
+		},
+		{
+			name: "redact without matches",
+			expr: "body.value.redact('ghp_[A-Za-z0-9]+')",
+			want: types.String("testing"),
+		},
+		{
+			name: "redact keeps the length of multibyte characters",
+			expr: "redact('pässwörd: süß', 'ü.')",
+			want: types.String("pässwörd: s**"),
+		},
+		{
+			name: "equalsIgnoreCase with different casing",
+			expr: "'deploy'.equalsIgnoreCase('Deploy')",
+			want: types.True,
+		},
+		{
+			name: "equalsIgnoreCase with different strings",
+			expr: "body.value.equalsIgnoreCase('test')",
+			want: types.False,
+		},
+		{
+			name: "containsIgnoreCase",
+			expr: "'Fix build [SKIP CI]'.containsIgnoreCase('[skip ci]')",
+			want: types.True,
+		},
+		{
+			name: "containsIgnoreCase without the substring",
+			expr: "body.value.containsIgnoreCase('prod')",
+			want: types.False,
+		},
+		{
+			name: "fuzzyMatch within the threshold",
+			expr: "'refs/heads/Relase'.fuzzyMatch('refs/heads/release', 1)",
+			want: types.True,
+		},
+		{
+			name: "fuzzyMatch above the threshold",
+			expr: "'refs/heads/main'.fuzzyMatch('refs/heads/master', 2)",
+			want: types.False,
+		},
+		{
+			name: "fuzzyMatch with a zero threshold ignores case",
+			expr: "'MAIN'.fuzzyMatch('main', 0)",
+			want: types.True,
+		},
+		{
+			name: "fuzzyMatch with insertions and deletions",
+			expr: "'kitten'.fuzzyMatch('sitting', 3) && !'kitten'.fuzzyMatch('sitting', 2)",
+			want: types.True,
+		},
+		{
+			name: "fuzzyMatch empty strings",
+			expr: "''.fuzzyMatch('abc', 3) && !''.fuzzyMatch('abcd', 3)",
+			want: types.True,
+		},
 		{
 			name: "extension base64 decoding",
 			expr: "base64.decode(body.b64value)",
@@ -605,9 +920,39 @@ func TestExpressionEvaluation(t *testing.T) {
 			want: types.String("one, two"),
 		},
 		{
-			name: "extension string join",
-			expr: "body.jsonArray.join(', ')",
-			want: types.String("one, two"),
+			name: "has top level extension",
+			expr: "hasExtension('enrich')",
+			want: types.True,
+		},
+		{
+			name: "has nested extension",
+			expr: "hasExtension('enrich.team')",
+			want: types.True,
+		},
+		{
+			name: "has empty string extension",
+			expr: "hasExtension('empty')",
+			want: types.True,
+		},
+		{
+			name: "missing extension",
+			expr: "hasExtension('missing')",
+			want: types.False,
+		},
+		{
+			name: "missing nested extension",
+			expr: "hasExtension('enrich.missing') || hasExtension('missing.team') || hasExtension('empty.nested')",
+			want: types.False,
+		},
+		{
+			name: "null extension",
+			expr: "hasExtension('enrich.owner')",
+			want: types.False,
+		},
+		{
+			name: "branch on extension",
+			expr: "hasExtension('enrich.team') ? extensions.enrich.team : 'unknown'",
+			want: types.String("platform"),
 		},
 	}
 	for _, tt := range tests {
@@ -617,7 +962,7 @@ func TestExpressionEvaluation(t *testing.T) {
 			if tt.secret != nil {
 				_, clientset = fakekubeclient.With(ctx, tt.secret)
 			}
-			env, err := makeCelEnv(context.Background(), testNS, interceptors.DefaultSecretGetter(clientset.CoreV1()))
+			env, err := makeCelEnv(context.Background(), testNS, interceptors.DefaultSecretGetter(clientset.CoreV1()), nil, nil, extensions)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -716,6 +1061,91 @@ func TestExpressionEvaluation_Error(t *testing.T) {
 			expr: "body.invalid_yaml.parseYAML().key1 == 'value1'",
 			want: "failed to decode 'key1: value1key2: value2\n' in parseYAML:",
 		},
+		{
+			name: "canonical JSON of a map with non-string keys",
+			expr: "canonicalJSON({1: 'one'})",
+			want: "failed to convert map to JSON",
+		},
+		{
+			name: "JSON patch with a failed test",
+			expr: "body.jsonPatch([{'op': 'add', 'path': '/a', 'value': 1}, {'op': 'test', 'path': '/value', 'value': 'other'}])",
+			want: "failed to apply operation 1 (test /value) in jsonPatch",
+		},
+		{
+			name: "JSON patch removing a missing path",
+			expr: "body.jsonPatch([{'op': 'remove', 'path': '/missing'}])",
+			want: "failed to apply operation 0 (remove /missing) in jsonPatch",
+		},
+		{
+			name: "JSON patch with an unknown operation",
+			expr: "body.jsonPatch([{'op': 'rename', 'path': '/value'}])",
+			want: "failed to apply operation 0 (rename /value) in jsonPatch",
+		},
+		{
+			name: "count with a non identifier variable",
+			expr: "[1, 2].count('x', true)",
+			want: "argument must be a simple name",
+		},
+		{
+			name: "count with a non boolean predicate",
+			expr: "[1, 2].count(x, x + 1)",
+			want: "found no matching overload for '_?_:_'",
+		},
+		{
+			name: "count over a string",
+			expr: "body.value.count(c, true)",
+			want: "expected iterable type",
+		},
+		{
+			name: "join a string",
+			expr: "join(body.value, ',')",
+			want: "no such overload: join(string, string)",
+		},
+		{
+			name: "join without a separator",
+			expr: "join(body.jsonArray)",
+			want: "no matching overload for 'join'",
+		},
+		{
+			name: "intersect a string",
+			expr: "intersect(body.value, ['testing'])",
+			want: "no such overload: intersect(string, list)",
+		},
+		{
+			name: "union with a map",
+			expr: "union(['a'], body.pull_request)",
+			want: "no such overload",
+		},
+		{
+			name: "redact with an invalid pattern",
+			expr: "body.value.redact('[a-')",
+			want: "failed to compile the pattern of redact",
+		},
+		{
+			name: "redact a map",
+			expr: "redact(body.pull_request, 'x')",
+			want: "no such overload",
+		},
+		{
+			name: "fuzzyMatch with a negative threshold",
+			expr: "body.value.fuzzyMatch('test', -1)",
+			want: "the threshold of fuzzyMatch must be between 0 and 8, got -1",
+		},
+		{
+			name: "fuzzyMatch with a threshold above the limit",
+			expr: "body.value.fuzzyMatch('test', 9)",
+			want: "the threshold of fuzzyMatch must be between 0 and 8, got 9",
+		},
+		{
+			name: "fuzzyMatch with a string above the limit",
+			expr: "'" + strings.Repeat("a", 257) + "'.fuzzyMatch('test', 1)",
+			want: "fuzzyMatch compares strings of at most 256 characters, got 257 and 4",
+		},
+		{
+			name: "equalsIgnoreCase a map",
+			expr: "body.pull_request.equalsIgnoreCase('x')",
+			want: "no such overload",
+		},
 		{
 			name: "marshalJSON marshalling string",
 			expr: "body.value.marshalJSON()",
@@ -741,7 +1171,7 @@ func TestExpressionEvaluation_Error(t *testing.T) {
 				_, clientset = fakekubeclient.With(ctx, makeSecret())
 				ns = tt.secretNS
 			}
-			env, err := makeCelEnv(context.Background(), ns, interceptors.DefaultSecretGetter(clientset.CoreV1()))
+			env, err := makeCelEnv(context.Background(), ns, interceptors.DefaultSecretGetter(clientset.CoreV1()), nil, nil, nil)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -782,11 +1212,35 @@ func TestURLToMap(t *testing.T) {
 	}
 }
 
+func TestLevenshteinWithin(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "abc", b: "", want: 3},
+		{a: "kitten", b: "sitting", want: 3},
+		{a: "flaw", b: "lawn", want: 2},
+		{a: "main", b: "master", want: 4},
+		{a: "release", b: "relaese", want: 2},
+		{a: "süß", b: "suß", want: 1},
+	} {
+		for limit := 0; limit <= maxFuzzyMatchThreshold; limit++ {
+			if got := levenshteinWithin([]rune(tc.a), []rune(tc.b), limit); got != (tc.want <= limit) {
+				t.Errorf("levenshteinWithin(%q, %q, %d) = %t, want %t", tc.a, tc.b, limit, got, tc.want <= limit)
+			}
+			if got := levenshteinWithin([]rune(tc.b), []rune(tc.a), limit); got != (tc.want <= limit) {
+				t.Errorf("levenshteinWithin(%q, %q, %d) = %t, want %t", tc.b, tc.a, limit, got, tc.want <= limit)
+			}
+		}
+	}
+}
+
 func TestMakeEvalContextWithError(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/", nil)
 	payload := []byte(`{"tes`)
 
-	_, err := makeEvalContext(payload, req.Header, req.URL.String(), map[string]interface{}{})
+	_, err := makeEvalContext(payload, req.Header, req.URL.String(), map[string]interface{}{}, nil, "", "")
 
 	if err == nil {
 		t.Fatalf("makeEvalContext(). expected err was nil")
@@ -797,6 +1251,39 @@ func TestMakeEvalContextWithError(t *testing.T) {
 	}
 }
 
+func TestCheck(t *testing.T) {
+	for _, tc := range []struct {
+		expr    string
+		wantErr string
+	}{{
+		expr: `body.pull_request.head.sha.truncate(7) == header.canonical('X-Sha')`,
+	}, {
+		expr: `extensions.labels.exists(l, l == 'ci') && hasExtension('labels')`,
+	}, {
+		expr:    `body == 'push'`,
+		wantErr: `<input>:1:6: found no matching overload for '_==_' applied to '(map(string, dyn), string)'`,
+	}, {
+		expr:    `body.action ==`,
+		wantErr: `<input>:1:15: Syntax error`,
+	}, {
+		expr:    `header.matches('X-Event', 'push')`,
+		wantErr: `<input>:1:15: found no matching overload for 'matches' applied to 'map(string, dyn).(string, string)'`,
+	}} {
+		t.Run(tc.expr, func(t *testing.T) {
+			err := Check(tc.expr)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Check() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Check() = %v, want an error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
 func checkMessageContains(t *testing.T, x, y string) bool {
 	t.Helper()
 	match, err := regexp.MatchString(x, y)
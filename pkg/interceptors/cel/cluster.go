@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ClusterInfoConfigName is the name of the ConfigMap, in the namespace of the
+// core interceptors, holding the metadata of the cluster returned by the
+// clusterInfo function, e.g. its name, environment and region.
+const ClusterInfoConfigName = "cluster-info-triggers"
+
+// ClusterInfo holds the metadata of the cluster returned by the clusterInfo
+// function. It is safe for concurrent use.
+type ClusterInfo struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// DefaultClusterInfo is the metadata of the cluster of the CEL interceptors
+// without their own ClusterInfo and of Evaluate. It is empty until it is set
+// from the ConfigMap.
+var DefaultClusterInfo = NewClusterInfo(nil)
+
+// NewClusterInfo returns a ClusterInfo with the given metadata.
+func NewClusterInfo(values map[string]string) *ClusterInfo {
+	c := &ClusterInfo{}
+	c.Set(values)
+	return c
+}
+
+// Set replaces the metadata of the cluster.
+func (c *ClusterInfo) Set(values map[string]string) {
+	copied := make(map[string]string, len(values))
+	for k, v := range values {
+		copied[k] = v
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values = copied
+}
+
+// Get returns the value of key, or an empty string if the cluster has no
+// metadata with that key.
+func (c *ClusterInfo) Get(key string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.values[key]
+}
+
+// UpdateFromConfigMap replaces the metadata of the cluster with the data of
+// the ConfigMap, as a configmap.Observer.
+func (c *ClusterInfo) UpdateFromConfigMap(cm *corev1.ConfigMap) {
+	c.Set(cm.Data)
+}
+
+// Cluster creates and returns a new cel.Lib with the clusterInfo function,
+// which returns the metadata of the cluster in info, so that one trigger can
+// behave differently per cluster, e.g. clusterInfo('environment') == 'prod'.
+func Cluster(info *ClusterInfo) cel.EnvOption {
+	if info == nil {
+		info = DefaultClusterInfo
+	}
+	return cel.Lib(clusterLib{info: info})
+}
+
+type clusterLib struct {
+	info *ClusterInfo
+}
+
+func (c clusterLib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("clusterInfo",
+			cel.Overload("clusterInfo_string", []*cel.Type{cel.StringType}, cel.StringType,
+				cel.UnaryBinding(c.clusterInfo))),
+	}
+}
+
+func (c clusterLib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (c clusterLib) clusterInfo(val ref.Val) ref.Val {
+	key, ok := val.(types.String)
+	if !ok {
+		return types.ValOrErr(key, "unexpected type '%v' passed to clusterInfo", val.Type())
+	}
+	return types.String(c.info.Get(string(key)))
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestClusterInfo(t *testing.T) {
+	info := NewClusterInfo(map[string]string{"name": "east-1", "environment": "prod"})
+	env, err := makeCelEnv(context.Background(), testNS, nil, nil, info, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		expr string
+		want bool
+	}{
+		{expr: "clusterInfo('environment') == 'prod'", want: true},
+		{expr: "clusterInfo('name').startsWith('east-')", want: true},
+		{expr: "clusterInfo('region') == ''", want: true},
+		{expr: "clusterInfo('region') == 'eu'", want: false},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			got, err := evaluate(tc.expr, env, map[string]interface{}{})
+			if err != nil {
+				t.Fatalf("evaluate() returned error: %v", err)
+			}
+			if got != types.Bool(tc.want) {
+				t.Errorf("evaluate() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	// The metadata is read when expressions are evaluated, so updates of the ConfigMap apply to the next events.
+	info.UpdateFromConfigMap(&corev1.ConfigMap{Data: map[string]string{"environment": "staging"}})
+	got, err := evaluate("clusterInfo('environment')", env, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("evaluate() returned error: %v", err)
+	}
+	if got != types.String("staging") {
+		t.Errorf("evaluate() = %v after the update, want staging", got)
+	}
+}
+
+func TestClusterInfo_Default(t *testing.T) {
+	env, err := makeCelEnv(context.Background(), testNS, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := evaluate("clusterInfo('name')", env, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("evaluate() returned error: %v", err)
+	}
+	if got != types.String("") {
+		t.Errorf("evaluate() = %v without cluster metadata, want an empty string", got)
+	}
+	if _, err := evaluate("clusterInfo(1)", env, map[string]interface{}{}); err == nil {
+		t.Error("evaluate(clusterInfo(1)) did not fail")
+	}
+}
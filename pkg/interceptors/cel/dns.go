@@ -0,0 +1,292 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+const (
+	// DefaultDNSTimeout is the time budget of the DNS lookups of a function call.
+	DefaultDNSTimeout = 2 * time.Second
+	// DefaultDNSCacheTTL is how long the results of DNS lookups are cached.
+	DefaultDNSCacheTTL = time.Minute
+
+	// maxDNSCacheEntries bounds the size of the cache, since the looked up
+	// addresses come from the senders of events.
+	maxDNSCacheEntries = 4096
+)
+
+// DNSLookup looks up the names of IP addresses and the addresses of hosts,
+// like a net.Resolver.
+type DNSLookup interface {
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// Resolver performs the DNS lookups of the lookupAddr, lookupHost and
+// matchesHost functions, with a timeout, and caches their results.
+type Resolver struct {
+	lookup   DNSLookup
+	timeout  time.Duration
+	ttl      time.Duration
+	failOpen bool
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]dnsEntry
+}
+
+type dnsEntry struct {
+	values  []string
+	expires time.Time
+}
+
+// DefaultResolver uses the resolver of the host with the default timeout and
+// cache TTL, and fails closed.
+var DefaultResolver = NewResolver(net.DefaultResolver, DefaultDNSTimeout, DefaultDNSCacheTTL, false)
+
+// NewResolver returns a Resolver bounding the lookups of each function call
+// by timeout and caching their results for ttl. If failOpen is true,
+// matchesHost returns true rather than false when a lookup fails.
+func NewResolver(lookup DNSLookup, timeout, ttl time.Duration, failOpen bool) *Resolver {
+	return &Resolver{
+		lookup:   lookup,
+		timeout:  timeout,
+		ttl:      ttl,
+		failOpen: failOpen,
+		now:      time.Now,
+		cache:    map[string]dnsEntry{},
+	}
+}
+
+// DNS creates and returns a new cel.Lib with the functions that look up the
+// names of IP addresses and the addresses of hosts with r.
+func DNS(ctx context.Context, r *Resolver) cel.EnvOption {
+	if r == nil {
+		r = DefaultResolver
+	}
+	return cel.Lib(dnsLib{ctx: ctx, resolver: r})
+}
+
+type dnsLib struct {
+	ctx      context.Context
+	resolver *Resolver
+}
+
+func (d dnsLib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("lookupAddr",
+			cel.MemberOverload("lookupAddr_string", []*cel.Type{cel.StringType}, cel.ListType(cel.StringType),
+				cel.UnaryBinding(d.lookupAddr))),
+		cel.Function("lookupHost",
+			cel.MemberOverload("lookupHost_string", []*cel.Type{cel.StringType}, cel.ListType(cel.StringType),
+				cel.UnaryBinding(d.lookupHost))),
+		cel.Function("matchesHost",
+			cel.MemberOverload("matchesHost_string_list", []*cel.Type{cel.StringType, cel.ListType(cel.StringType)}, cel.BoolType,
+				cel.BinaryBinding(d.matchesHost))),
+	}
+}
+
+func (d dnsLib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (d dnsLib) lookupAddr(val ref.Val) ref.Val {
+	ip, ok := val.(types.String)
+	if !ok {
+		return types.ValOrErr(ip, "unexpected type '%v' passed to lookupAddr", val.Type())
+	}
+	ctx, cancel := context.WithTimeout(d.ctx, d.resolver.timeout)
+	defer cancel()
+	names, err := d.resolver.confirmedNames(ctx, string(ip))
+	if err != nil {
+		return types.NewErr(err.Error())
+	}
+	return types.DefaultTypeAdapter.NativeToValue(names)
+}
+
+func (d dnsLib) lookupHost(val ref.Val) ref.Val {
+	host, ok := val.(types.String)
+	if !ok {
+		return types.ValOrErr(host, "unexpected type '%v' passed to lookupHost", val.Type())
+	}
+	ctx, cancel := context.WithTimeout(d.ctx, d.resolver.timeout)
+	defer cancel()
+	addrs, err := d.resolver.addrs(ctx, string(host))
+	if err != nil {
+		return types.NewErr(err.Error())
+	}
+	return types.DefaultTypeAdapter.NativeToValue(addrs)
+}
+
+func (d dnsLib) matchesHost(lhs, rhs ref.Val) ref.Val {
+	ip, ok := lhs.(types.String)
+	if !ok {
+		return types.ValOrErr(ip, "unexpected type '%v' passed to matchesHost", lhs.Type())
+	}
+	l, ok := rhs.(traits.Lister)
+	if !ok {
+		return types.ValOrErr(rhs, "unexpected type '%v' passed to matchesHost", rhs.Type())
+	}
+	var patterns []string
+	for it := l.Iterator(); it.HasNext() == types.True; {
+		p, ok := it.Next().(types.String)
+		if !ok {
+			return types.NewErr("matchesHost patterns must be strings")
+		}
+		patterns = append(patterns, strings.ToLower(strings.TrimSuffix(string(p), ".")))
+	}
+	// Only failed lookups fail open, not invalid addresses.
+	if net.ParseIP(string(ip)) == nil {
+		return types.NewErr("%q is not an IP address", string(ip))
+	}
+	ctx, cancel := context.WithTimeout(d.ctx, d.resolver.timeout)
+	defer cancel()
+	matched, err := d.resolver.matchesHost(ctx, string(ip), patterns)
+	if err != nil {
+		return types.Bool(d.resolver.failOpen)
+	}
+	return types.Bool(matched)
+}
+
+// matchesHost returns true if ip is one of the addresses of the hosts in
+// patterns, or if one of its confirmed names matches one of the patterns,
+// either exactly or, for "*.example.com", as a subdomain. The error of a
+// failed lookup is only returned if nothing matched.
+func (r *Resolver) matchesHost(ctx context.Context, ip string, patterns []string) (bool, error) {
+	parsed := net.ParseIP(ip)
+	var lookupErr error
+	for _, p := range patterns {
+		if strings.HasPrefix(p, "*.") {
+			continue
+		}
+		addrs, err := r.addrs(ctx, p)
+		if err != nil {
+			lookupErr = err
+			continue
+		}
+		if containsIP(addrs, parsed) {
+			return true, nil
+		}
+	}
+	names, err := r.confirmedNames(ctx, ip)
+	if err != nil {
+		return false, err
+	}
+	for _, n := range names {
+		for _, p := range patterns {
+			if n == p || strings.HasPrefix(p, "*.") && strings.HasSuffix(n, p[1:]) {
+				return true, nil
+			}
+		}
+	}
+	return false, lookupErr
+}
+
+// confirmedNames returns the names that ip reverse resolves to and that
+// resolve back to ip. Names that don't resolve back to ip are left out, since
+// anyone controlling the reverse zone of an address can point it to any name.
+func (r *Resolver) confirmedNames(ctx context.Context, ip string) ([]string, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, fmt.Errorf("%q is not an IP address", ip)
+	}
+	names, err := r.cached(ctx, "addr/"+parsed.String(), func(ctx context.Context) ([]string, error) {
+		return r.lookup.LookupAddr(ctx, parsed.String())
+	})
+	if err != nil {
+		return nil, err
+	}
+	confirmed := []string{}
+	for _, n := range names {
+		n = strings.ToLower(strings.TrimSuffix(n, "."))
+		addrs, err := r.addrs(ctx, n)
+		if err != nil {
+			return nil, err
+		}
+		if containsIP(addrs, parsed) {
+			confirmed = append(confirmed, n)
+		}
+	}
+	return confirmed, nil
+}
+
+// addrs returns the addresses of host.
+func (r *Resolver) addrs(ctx context.Context, host string) ([]string, error) {
+	return r.cached(ctx, "host/"+host, func(ctx context.Context) ([]string, error) {
+		return r.lookup.LookupHost(ctx, host)
+	})
+}
+
+// cached returns the cached result of lookup under key, or calls it and caches
+// its result. Unknown names and addresses have an empty result rather than an
+// error, which is kept for other failures, such as timeouts, and not cached.
+func (r *Resolver) cached(ctx context.Context, key string, lookup func(context.Context) ([]string, error)) ([]string, error) {
+	now := r.now()
+	r.mu.Lock()
+	e, ok := r.cache[key]
+	r.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.values, nil
+	}
+
+	values, err := lookup(ctx)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+			return nil, fmt.Errorf("DNS lookup of %s failed: %w", strings.SplitN(key, "/", 2)[1], err)
+		}
+		values = []string{}
+	}
+	if r.ttl > 0 {
+		r.mu.Lock()
+		if len(r.cache) >= maxDNSCacheEntries {
+			for k, e := range r.cache {
+				if !now.Before(e.expires) {
+					delete(r.cache, k)
+				}
+			}
+			if len(r.cache) >= maxDNSCacheEntries {
+				r.cache = map[string]dnsEntry{}
+			}
+		}
+		r.cache[key] = dnsEntry{values: values, expires: now.Add(r.ttl)}
+		r.mu.Unlock()
+	}
+	return values, nil
+}
+
+func containsIP(addrs []string, ip net.IP) bool {
+	for _, a := range addrs {
+		if ip.Equal(net.ParseIP(a)) {
+			return true
+		}
+	}
+	return false
+}
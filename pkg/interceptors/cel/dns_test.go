@@ -0,0 +1,296 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+)
+
+// fakeDNS resolves the names and addresses of its maps. Others are not found,
+// and those in failing time out.
+type fakeDNS struct {
+	names   map[string][]string
+	addrs   map[string][]string
+	failing map[string]bool
+	lookups int
+}
+
+func (f *fakeDNS) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	return f.resolve(f.names, addr)
+}
+
+func (f *fakeDNS) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return f.resolve(f.addrs, host)
+}
+
+func (f *fakeDNS) resolve(m map[string][]string, key string) ([]string, error) {
+	f.lookups++
+	if f.failing[key] {
+		return nil, &net.DNSError{Err: "i/o timeout", Name: key, IsTimeout: true}
+	}
+	values, ok := m[key]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: key, IsNotFound: true}
+	}
+	return values, nil
+}
+
+func newFakeDNS() *fakeDNS {
+	return &fakeDNS{
+		names: map[string][]string{
+			"192.0.2.10":  {"hooks-1.ci.example.com."},
+			"192.0.2.20":  {"ci.example.org."},
+			"192.0.2.66":  {"hooks-2.ci.example.com."},
+			"192.0.2.99":  {"broken.example.net."},
+			"2001:db8::1": {"HOOKS-v6.ci.example.com."},
+		},
+		addrs: map[string][]string{
+			"hooks-1.ci.example.com":  {"192.0.2.10"},
+			"hooks-2.ci.example.com":  {"192.0.2.11"},
+			"hooks-v6.ci.example.com": {"2001:db8:0:0::1"},
+			"ci.example.org":          {"192.0.2.20"},
+			"static.example.org":      {"192.0.2.30", "192.0.2.31"},
+		},
+		failing: map[string]bool{
+			"192.0.2.40":         true,
+			"broken.example.net": true,
+			"down.example.org":   true,
+		},
+	}
+}
+
+func TestDNSFunctions(t *testing.T) {
+	env, err := makeCelEnv(context.Background(), testNS, nil, NewResolver(newFakeDNS(), time.Second, time.Minute, false), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name     string
+		clientIP string
+		expr     string
+		want     types.Bool
+	}{{
+		name:     "confirmed name",
+		clientIP: "192.0.2.10",
+		expr:     "clientIP.lookupAddr() == ['hooks-1.ci.example.com']",
+		want:     types.True,
+	}, {
+		name:     "name not resolving back to the address",
+		clientIP: "192.0.2.66",
+		expr:     "clientIP.lookupAddr() == []",
+		want:     types.True,
+	}, {
+		name:     "address without names",
+		clientIP: "192.0.2.50",
+		expr:     "clientIP.lookupAddr() == []",
+		want:     types.True,
+	}, {
+		name:     "IPv6 name",
+		clientIP: "2001:db8::1",
+		expr:     "clientIP.lookupAddr() == ['hooks-v6.ci.example.com']",
+		want:     types.True,
+	}, {
+		name: "host addresses",
+		expr: "'static.example.org'.lookupHost() == ['192.0.2.30', '192.0.2.31']",
+		want: types.True,
+	}, {
+		name: "unknown host",
+		expr: "'unknown.example.org'.lookupHost() == []",
+		want: types.True,
+	}, {
+		name:     "wildcard pattern",
+		clientIP: "192.0.2.10",
+		expr:     "clientIP.matchesHost(['*.ci.example.com'])",
+		want:     types.True,
+	}, {
+		name:     "wildcard pattern not matching the domain itself",
+		clientIP: "192.0.2.20",
+		expr:     "clientIP.matchesHost(['*.ci.example.org'])",
+		want:     types.False,
+	}, {
+		name:     "exact pattern matching the name",
+		clientIP: "192.0.2.20",
+		expr:     "clientIP.matchesHost(['CI.example.org.'])",
+		want:     types.True,
+	}, {
+		name:     "exact pattern resolving to the address",
+		clientIP: "192.0.2.31",
+		expr:     "clientIP.matchesHost(['other.example.org', 'static.example.org'])",
+		want:     types.True,
+	}, {
+		name:     "unconfirmed name",
+		clientIP: "192.0.2.66",
+		expr:     "clientIP.matchesHost(['*.ci.example.com'])",
+		want:     types.False,
+	}, {
+		name:     "failed lookup closed",
+		clientIP: "192.0.2.40",
+		expr:     "clientIP.matchesHost(['*.ci.example.com'])",
+		want:     types.False,
+	}, {
+		name:     "match despite a failed lookup",
+		clientIP: "192.0.2.30",
+		expr:     "clientIP.matchesHost(['down.example.org', 'static.example.org'])",
+		want:     types.True,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evaluate(tt.expr, env, map[string]interface{}{"clientIP": tt.clientIP})
+			if err != nil {
+				t.Fatalf("evaluate() got an error %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		name     string
+		clientIP string
+		expr     string
+		want     string
+	}{{
+		name:     "failed reverse lookup",
+		clientIP: "192.0.2.40",
+		expr:     "clientIP.lookupAddr() == []",
+		want:     "DNS lookup of 192.0.2.40 failed",
+	}, {
+		name:     "failed forward lookup of a name",
+		clientIP: "192.0.2.99",
+		expr:     "clientIP.lookupAddr() == []",
+		want:     "DNS lookup of broken.example.net failed",
+	}, {
+		name: "failed host lookup",
+		expr: "'down.example.org'.lookupHost() == []",
+		want: "DNS lookup of down.example.org failed",
+	}, {
+		name:     "not an address",
+		clientIP: "hooks-1.ci.example.com",
+		expr:     "clientIP.lookupAddr() == []",
+		want:     `"hooks-1.ci.example.com" is not an IP address`,
+	}, {
+		name: "no client address",
+		expr: "clientIP.matchesHost(['*.ci.example.com'])",
+		want: `"" is not an IP address`,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := evaluate(tt.expr, env, map[string]interface{}{"clientIP": tt.clientIP})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("evaluate() got error %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDNSFunctions_FailOpen(t *testing.T) {
+	env, err := makeCelEnv(context.Background(), testNS, nil, NewResolver(newFakeDNS(), time.Second, time.Minute, true), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name     string
+		clientIP string
+		want     types.Bool
+	}{{
+		name:     "failed lookup",
+		clientIP: "192.0.2.40",
+		want:     types.True,
+	}, {
+		name:     "no match",
+		clientIP: "192.0.2.20",
+		want:     types.False,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evaluate("clientIP.matchesHost(['*.ci.example.com'])", env, map[string]interface{}{"clientIP": tt.clientIP})
+			if err != nil {
+				t.Fatalf("evaluate() got an error %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolver_Cache(t *testing.T) {
+	dns := newFakeDNS()
+	r := NewResolver(dns, time.Second, time.Minute, false)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := r.confirmedNames(ctx, "192.0.2.10"); err != nil {
+			t.Fatalf("confirmedNames() got an error %s", err)
+		}
+		if _, err := r.addrs(ctx, "unknown.example.org"); err != nil {
+			t.Fatalf("addrs() got an error %s", err)
+		}
+	}
+	if dns.lookups != 3 {
+		t.Errorf("got %d lookups, want 3 with cached results", dns.lookups)
+	}
+
+	// Failures are not cached.
+	for i := 0; i < 2; i++ {
+		if _, err := r.addrs(ctx, "down.example.org"); err == nil {
+			t.Fatal("addrs() expected an error")
+		}
+	}
+	if dns.lookups != 5 {
+		t.Errorf("got %d lookups, want 5 with failures looked up again", dns.lookups)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := r.addrs(ctx, "unknown.example.org"); err != nil {
+		t.Fatalf("addrs() got an error %s", err)
+	}
+	if dns.lookups != 6 {
+		t.Errorf("got %d lookups, want 6 with expired results looked up again", dns.lookups)
+	}
+}
+
+func TestResolver_Timeout(t *testing.T) {
+	r := NewResolver(blockingDNS{}, 10*time.Millisecond, time.Minute, false)
+	env, err := makeCelEnv(context.Background(), testNS, nil, r, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = evaluate("clientIP.lookupAddr() == []", env, map[string]interface{}{"clientIP": "192.0.2.10"})
+	if err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("evaluate() got error %v, want a deadline exceeded error", err)
+	}
+}
+
+// blockingDNS blocks lookups until their context is done.
+type blockingDNS struct{}
+
+func (blockingDNS) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (blockingDNS) LookupHost(ctx context.Context, host string) ([]string, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
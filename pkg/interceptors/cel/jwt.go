@@ -0,0 +1,222 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter/functions"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+)
+
+// jwtMethods are the signing methods accepted by verifyJWT. Notably, this
+// excludes "none".
+var jwtMethods = []string{
+	"HS256", "HS384", "HS512",
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+}
+
+// jsonWebKey is the subset of the fields of a JSON Web Key (RFC 7517) needed
+// to verify JWT signatures.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	// RSA public keys
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC public keys
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	// Symmetric keys
+	K string `json:"k"`
+}
+
+// decodeJWT decodes the claims of a JWT without verifying its signature.
+func decodeJWT(val ref.Val) ref.Val {
+	str, ok := val.(types.String)
+	if !ok {
+		return types.ValOrErr(str, "unexpected type '%v' passed to decodeJWT", val.Type())
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(string(str), claims); err != nil {
+		return types.NewErr("failed to decode JWT in decodeJWT: %w", err)
+	}
+	return claimsToMap(claims)
+}
+
+// makeVerifyJWT creates and returns a functions.FunctionOp that verifies the
+// signature of a JWT against a key read from a Kubernetes secret in the
+// defaultNS, and returns its claims.
+func makeVerifyJWT(ctx context.Context, defaultNS string, sg interceptors.SecretGetter) functions.FunctionOp {
+	return func(vals ...ref.Val) ref.Val {
+		token, ok := vals[0].(types.String)
+		if !ok {
+			return types.ValOrErr(token, "unexpected type '%v' passed to verifyJWT", vals[0].Type())
+		}
+		secretKey, ok := vals[1].(types.String)
+		if !ok {
+			return types.ValOrErr(secretKey, "unexpected type '%v' passed to verifyJWT", vals[1].Type())
+		}
+		secretName, ok := vals[2].(types.String)
+		if !ok {
+			return types.ValOrErr(secretName, "unexpected type '%v' passed to verifyJWT", vals[2].Type())
+		}
+
+		secretRef := &triggersv1.SecretRef{
+			SecretKey:  string(secretKey),
+			SecretName: string(secretName),
+		}
+		secret, err := sg.Get(ctx, defaultNS, secretRef)
+		if err != nil {
+			return types.NewErr("failed to find secret '%#v' in verifyJWT: %w", *secretRef, err)
+		}
+		keyFunc, err := jwtKeyFunc(secret)
+		if err != nil {
+			return types.NewErr("failed to read keys from secret '%#v' in verifyJWT: %w", *secretRef, err)
+		}
+
+		claims := jwt.MapClaims{}
+		if _, err := jwt.NewParser(jwt.WithValidMethods(jwtMethods)).ParseWithClaims(string(token), claims, keyFunc); err != nil {
+			return types.NewErr("failed to verify JWT in verifyJWT: %w", err)
+		}
+		return claimsToMap(claims)
+	}
+}
+
+// jwtKeyFunc returns a jwt.Keyfunc for the keys in secret. If secret is a JSON
+// Web Key Set, the key is selected using the "kid" header of the token.
+// Otherwise the secret is used as a shared HMAC key.
+func jwtKeyFunc(secret []byte) (jwt.Keyfunc, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(secret, &set); err != nil || set.Keys == nil {
+		return func(t *jwt.Token) (interface{}, error) {
+			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("signing method %s requires a JSON Web Key Set", t.Method.Alg())
+			}
+			return secret, nil
+		}, nil
+	}
+	if len(set.Keys) == 0 {
+		return nil, errors.New("the JSON Web Key Set has no keys")
+	}
+
+	return func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" && len(set.Keys) > 1 {
+			return nil, errors.New("token has no kid header to select a key from the JSON Web Key Set")
+		}
+		for _, k := range set.Keys {
+			if kid != "" && k.Kid != kid {
+				continue
+			}
+			if k.Alg != "" && k.Alg != t.Method.Alg() {
+				return nil, fmt.Errorf("key %q does not support signing method %s", k.Kid, t.Method.Alg())
+			}
+			return k.publicKey(t.Method)
+		}
+		return nil, fmt.Errorf("no key with kid %q in the JSON Web Key Set", kid)
+	}, nil
+}
+
+// publicKey returns the key to verify signatures made with method.
+func (k jsonWebKey) publicKey(method jwt.SigningMethod) (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		switch method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		default:
+			return nil, fmt.Errorf("RSA key %q does not support signing method %s", k.Kid, method.Alg())
+		}
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus of key %q: %w", k.Kid, err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, fmt.Errorf("invalid exponent of key %q", k.Kid)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if _, ok := method.(*jwt.SigningMethodECDSA); !ok {
+			return nil, fmt.Errorf("EC key %q does not support signing method %s", k.Kid, method.Alg())
+		}
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q of key %q", k.Crv, k.Kid)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate of key %q: %w", k.Kid, err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate of key %q: %w", k.Kid, err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "oct":
+		if _, ok := method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("symmetric key %q does not support signing method %s", k.Kid, method.Alg())
+		}
+		return base64.RawURLEncoding.DecodeString(k.K)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q of key %q", k.Kty, k.Kid)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func claimsToMap(claims jwt.MapClaims) ref.Val {
+	r, err := types.NewRegistry()
+	if err != nil {
+		return types.NewErr("failed to create a new registry for JWT claims: %w", err)
+	}
+	return types.NewDynamicMap(r, map[string]interface{}(claims))
+}
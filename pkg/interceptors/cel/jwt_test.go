@@ -0,0 +1,223 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/cel-go/common/types"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+)
+
+func signJWT(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign JWT: %v", err)
+	}
+	return s
+}
+
+func encodeBigInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func TestJWTEvaluation(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherRSAKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "rsa-key",
+			"alg": "RS256",
+			"n":   encodeBigInt(rsaKey.N),
+			"e":   encodeBigInt(big.NewInt(int64(rsaKey.E))),
+		}, {
+			"kty": "EC",
+			"kid": "ec-key",
+			"crv": "P-256",
+			"x":   encodeBigInt(ecKey.X),
+			"y":   encodeBigInt(ecKey.Y),
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNS,
+			Name:      "jwt-keys",
+		},
+		Data: map[string][]byte{
+			"jwks":   jwks,
+			"shared": []byte("sharedsecret"),
+		},
+	}
+
+	claims := jwt.MapClaims{
+		"sub": "ci-bot",
+		"aud": "triggers",
+		"exp": float64(time.Now().Add(time.Hour).Unix()),
+	}
+	expired := jwt.MapClaims{
+		"sub": "ci-bot",
+		"exp": float64(time.Now().Add(-time.Hour).Unix()),
+	}
+	rsaToken := signJWT(t, jwt.SigningMethodRS256, "rsa-key", rsaKey, claims)
+	evalEnv := map[string]interface{}{
+		"body": map[string]interface{}{
+			"rsa":       rsaToken,
+			"ec":        signJWT(t, jwt.SigningMethodES256, "ec-key", ecKey, claims),
+			"hmac":      signJWT(t, jwt.SigningMethodHS256, "", []byte("sharedsecret"), claims),
+			"expired":   signJWT(t, jwt.SigningMethodRS256, "rsa-key", rsaKey, expired),
+			"forged":    signJWT(t, jwt.SigningMethodRS256, "rsa-key", otherRSAKey, claims),
+			"unknown":   signJWT(t, jwt.SigningMethodRS256, "other-key", otherRSAKey, claims),
+			"wrongAlg":  signJWT(t, jwt.SigningMethodRS384, "rsa-key", rsaKey, claims),
+			"confusion": signJWT(t, jwt.SigningMethodHS256, "rsa-key", []byte("sharedsecret"), claims),
+			"unsigned":  signJWT(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, claims),
+			"garbage":   "not-a-jwt",
+		},
+	}
+
+	ctx, _ := test.SetupFakeContext(t)
+	_, clientset := fakekubeclient.With(ctx, secret)
+	env, err := makeCelEnv(context.Background(), testNS, interceptors.DefaultSecretGetter(clientset.CoreV1()), nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		expr string
+		want types.Bool
+	}{{
+		name: "decode signed token",
+		expr: "body.rsa.decodeJWT().sub == 'ci-bot'",
+		want: types.True,
+	}, {
+		name: "decode forged token",
+		expr: "body.forged.decodeJWT().sub == 'ci-bot'",
+		want: types.True,
+	}, {
+		name: "decode claim in allow-list",
+		expr: "body.rsa.decodeJWT().sub in ['ci-bot', 'release-bot']",
+		want: types.True,
+	}, {
+		name: "verify RSA token with JWKS",
+		expr: "body.rsa.verifyJWT('jwks', 'jwt-keys').aud == 'triggers'",
+		want: types.True,
+	}, {
+		name: "verify EC token with JWKS",
+		expr: "body.ec.verifyJWT('jwks', 'jwt-keys').sub == 'ci-bot'",
+		want: types.True,
+	}, {
+		name: "verify HMAC token with shared secret",
+		expr: "body.hmac.verifyJWT('shared', 'jwt-keys').sub == 'ci-bot'",
+		want: types.True,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evaluate(tt.expr, env, evalEnv)
+			if err != nil {
+				t.Fatalf("evaluate() got an error %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		name string
+		expr string
+		want string
+	}{{
+		name: "decode invalid token",
+		expr: "body.garbage.decodeJWT().sub == 'ci-bot'",
+		want: "failed to decode JWT in decodeJWT",
+	}, {
+		name: "verify forged token",
+		expr: "body.forged.verifyJWT('jwks', 'jwt-keys').sub == 'ci-bot'",
+		want: "verification error",
+	}, {
+		name: "verify expired token",
+		expr: "body.expired.verifyJWT('jwks', 'jwt-keys').sub == 'ci-bot'",
+		want: "Token is expired",
+	}, {
+		name: "verify token with unknown kid",
+		expr: "body.unknown.verifyJWT('jwks', 'jwt-keys').sub == 'ci-bot'",
+		want: `no key with kid "other-key"`,
+	}, {
+		name: "verify token with algorithm not allowed by the key",
+		expr: "body.wrongAlg.verifyJWT('jwks', 'jwt-keys').sub == 'ci-bot'",
+		want: "does not support signing method RS384",
+	}, {
+		name: "verify HMAC token with RSA key",
+		expr: "body.confusion.verifyJWT('jwks', 'jwt-keys').sub == 'ci-bot'",
+		want: "does not support signing method HS256",
+	}, {
+		name: "verify RSA token with shared secret",
+		expr: "body.rsa.verifyJWT('shared', 'jwt-keys').sub == 'ci-bot'",
+		want: "requires a JSON Web Key Set",
+	}, {
+		name: "verify unsigned token",
+		expr: "body.unsigned.verifyJWT('shared', 'jwt-keys').sub == 'ci-bot'",
+		want: "signing method none is invalid",
+	}, {
+		name: "verify with missing secret",
+		expr: "body.rsa.verifyJWT('jwks', 'missing').sub == 'ci-bot'",
+		want: "failed to find secret",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := evaluate(tt.expr, env, evalEnv)
+			if err == nil {
+				t.Fatal("evaluate() expected err but got nil")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("evaluate() got %s, wanted %s", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxRandAlphaNumLength bounds the length of the strings returned by randAlphaNum.
+	maxRandAlphaNumLength = 64
+
+	// alphaNum are the characters of the strings returned by randAlphaNum. They are lowercase so that
+	// the strings can be used in resource names.
+	alphaNum = "abcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+// Random creates and returns a new cel.Lib with the uuid and randAlphaNum
+// functions. The values are generated on the first call, and every later call
+// in the environment returns the same value, so that the filter and overlays
+// of a CEL interceptor, which share an environment for an event, can refer to
+// the same value several times.
+func Random() cel.EnvOption {
+	return cel.Lib(randomLib{values: &randomValues{reader: rand.Reader, alphaNums: map[int64]string{}}})
+}
+
+type randomLib struct {
+	values *randomValues
+}
+
+// randomValues holds the values generated in an environment.
+type randomValues struct {
+	reader io.Reader
+
+	mu        sync.Mutex
+	uuid      string
+	alphaNums map[int64]string
+}
+
+func (r randomLib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("uuid",
+			cel.Overload("uuid", []*cel.Type{}, cel.StringType,
+				cel.FunctionBinding(func(...ref.Val) ref.Val { return r.uuid() }))),
+		cel.Function("randAlphaNum",
+			cel.Overload("randAlphaNum_int", []*cel.Type{cel.IntType}, cel.StringType,
+				cel.UnaryBinding(r.randAlphaNum))),
+	}
+}
+
+func (r randomLib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (r randomLib) uuid() ref.Val {
+	r.values.mu.Lock()
+	defer r.values.mu.Unlock()
+	if r.values.uuid == "" {
+		u, err := uuid.NewRandomFromReader(r.values.reader)
+		if err != nil {
+			return types.NewErr("failed to generate a UUID: %s", err)
+		}
+		r.values.uuid = u.String()
+	}
+	return types.String(r.values.uuid)
+}
+
+func (r randomLib) randAlphaNum(val ref.Val) ref.Val {
+	n, ok := val.(types.Int)
+	if !ok {
+		return types.ValOrErr(n, "unexpected type '%v' passed to randAlphaNum", val.Type())
+	}
+	if n < 1 || n > maxRandAlphaNumLength {
+		return types.NewErr("randAlphaNum length %d is not between 1 and %d", n, maxRandAlphaNumLength)
+	}
+
+	r.values.mu.Lock()
+	defer r.values.mu.Unlock()
+	if s, ok := r.values.alphaNums[int64(n)]; ok {
+		return types.String(s)
+	}
+	b := make([]byte, n)
+	max := big.NewInt(int64(len(alphaNum)))
+	for i := range b {
+		c, err := rand.Int(r.values.reader, max)
+		if err != nil {
+			return types.NewErr("failed to generate a random string: %s", err)
+		}
+		b[i] = alphaNum[c.Int64()]
+	}
+	r.values.alphaNums[int64(n)] = string(b)
+	return types.String(b)
+}
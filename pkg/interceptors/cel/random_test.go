@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+)
+
+func TestRandomFunctions(t *testing.T) {
+	process := func() map[string]interface{} {
+		t.Helper()
+		w := &Interceptor{}
+		res := w.Process(context.Background(), &triggersv1.InterceptorRequest{
+			Body:   `{"name": "api"}`,
+			Header: http.Header{"Content-Type": []string{"application/json"}},
+			InterceptorParams: map[string]interface{}{
+				"filter": "uuid() != '' && randAlphaNum(8) != ''",
+				"overlays": []triggersv1.CELOverlay{
+					{Key: "id", Expression: "uuid()"},
+					{Key: "run", Expression: "'build-' + uuid()"},
+					{Key: "short", Expression: "body.name + '-' + randAlphaNum(8)"},
+					{Key: "again", Expression: "randAlphaNum(8) + '/' + randAlphaNum(8)"},
+					{Key: "long", Expression: "randAlphaNum(64)"},
+				},
+			},
+			Context: &triggersv1.TriggerContext{
+				EventID:   "abcde",
+				TriggerID: fmt.Sprintf("namespaces/%s/triggers/example-trigger", testNS),
+			},
+		})
+		if !res.Continue {
+			t.Fatalf("cel.Process() unexpectedly returned continue: false. Response is: %v", res.Status.Err())
+		}
+		return res.Extensions
+	}
+
+	first := process()
+	id := first["id"].(string)
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(id) {
+		t.Errorf("uuid() returned %q, want a random UUID", id)
+	}
+	if first["run"] != "build-"+id {
+		t.Errorf("uuid() returned %q, then %q, want the same UUID", id, first["run"])
+	}
+	short := strings.TrimPrefix(first["short"].(string), "api-")
+	if !regexp.MustCompile(`^[a-z0-9]{8}$`).MatchString(short) {
+		t.Errorf("randAlphaNum(8) returned %q, want 8 lowercase letters and digits", short)
+	}
+	if first["again"] != short+"/"+short {
+		t.Errorf("randAlphaNum(8) returned %q, then %q, want the same string", short, first["again"])
+	}
+	if long := first["long"].(string); !regexp.MustCompile(`^[a-z0-9]{64}$`).MatchString(long) || strings.HasPrefix(long, short) {
+		t.Errorf("randAlphaNum(64) returned %q, want a different string of 64 lowercase letters and digits", long)
+	}
+
+	second := process()
+	if second["id"] == id || second["short"] == first["short"] {
+		t.Errorf("got the same values %v for two events", second)
+	}
+}
+
+func TestRandomFunctions_Error(t *testing.T) {
+	env, err := makeCelEnv(context.Background(), testNS, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expr := range []string{"randAlphaNum(0)", "randAlphaNum(65)", "randAlphaNum(-1)"} {
+		if _, err := evaluate(expr, env, map[string]interface{}{}); err == nil || !strings.Contains(err.Error(), "is not between 1 and 64") {
+			t.Errorf("evaluate(%s) returned error %v, want the length to be rejected", expr, err)
+		}
+	}
+	if _, err := evaluate("uuid(1)", env, map[string]interface{}{}); err == nil {
+		t.Error("evaluate(uuid(1)) did not fail")
+	}
+}
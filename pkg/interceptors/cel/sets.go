@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+// intersect returns the elements of the first list that are in the second.
+func intersect(lhs, rhs ref.Val) ref.Val {
+	return setOperation("intersect", lhs, rhs, func(a, b []ref.Val) []ref.Val {
+		var result []ref.Val
+		for _, v := range a {
+			if contains(b, v) {
+				result = appendUnique(result, v)
+			}
+		}
+		return result
+	})
+}
+
+// difference returns the elements of the first list that aren't in the second.
+func difference(lhs, rhs ref.Val) ref.Val {
+	return setOperation("difference", lhs, rhs, func(a, b []ref.Val) []ref.Val {
+		var result []ref.Val
+		for _, v := range a {
+			if !contains(b, v) {
+				result = appendUnique(result, v)
+			}
+		}
+		return result
+	})
+}
+
+// union returns the elements of the first list followed by the elements of the
+// second that aren't in the first.
+func union(lhs, rhs ref.Val) ref.Val {
+	return setOperation("union", lhs, rhs, func(a, b []ref.Val) []ref.Val {
+		var result []ref.Val
+		for _, list := range [][]ref.Val{a, b} {
+			for _, v := range list {
+				result = appendUnique(result, v)
+			}
+		}
+		return result
+	})
+}
+
+// setOperation applies op to the elements of two lists, and returns its result
+// as a list. The results have no duplicates and keep the order in which their
+// elements first appear in the lists.
+func setOperation(name string, lhs, rhs ref.Val, op func(a, b []ref.Val) []ref.Val) ref.Val {
+	a, ok := lhs.(traits.Lister)
+	if !ok {
+		return types.ValOrErr(lhs, "unexpected type '%v' passed to %s", lhs.Type(), name)
+	}
+	b, ok := rhs.(traits.Lister)
+	if !ok {
+		return types.ValOrErr(rhs, "unexpected type '%v' passed to %s", rhs.Type(), name)
+	}
+	result := op(elements(a), elements(b))
+	if result == nil {
+		result = []ref.Val{}
+	}
+	return types.NewRefValList(types.DefaultTypeAdapter, result)
+}
+
+func elements(l traits.Lister) []ref.Val {
+	var vals []ref.Val
+	for it := l.Iterator(); it.HasNext() == types.True; {
+		vals = append(vals, it.Next())
+	}
+	return vals
+}
+
+// contains returns whether val is in vals, using the semantics of the CEL ==
+// operator: scalars are equal if they have the same value, and maps and lists
+// if their entries or elements are equal.
+func contains(vals []ref.Val, val ref.Val) bool {
+	for _, v := range vals {
+		if v.Equal(val) == types.True {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(vals []ref.Val, val ref.Val) []ref.Val {
+	if contains(vals, val) {
+		return vals
+	}
+	return append(vals, val)
+}
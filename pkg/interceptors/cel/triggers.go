@@ -16,19 +16,29 @@ limitations under the License.
 package cel
 
 import (
+	"bytes"
 	"context"
 	"crypto/subtle"
 	"encoding/json"
 	"net/http"
 	"net/url"
 	"reflect"
+	"regexp"
 	"strings"
+	"unicode/utf8"
 
+	jsonpatch "github.com/evanphx/json-patch"
+	structpb "github.com/golang/protobuf/ptypes/struct"
 	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/operators"
 	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
 	"github.com/google/cel-go/interpreter/functions"
+	"github.com/google/cel-go/parser"
 	"github.com/tektoncd/triggers/pkg/interceptors"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 	"sigs.k8s.io/yaml"
 
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
@@ -143,6 +153,270 @@ import (
 // Examples:
 //
 // 		body.jsonObjectOrList.marshalJSON()
+//
+// canonicalJSON
+//
+// Returns a canonical JSON encoding of a value, in which the keys of objects
+// are sorted and there is no insignificant whitespace, so that semantically
+// identical values are encoded identically regardless of their field order.
+//
+// 		canonicalJSON(<dyn>) -> <string>
+//
+// Examples:
+//
+// 		canonicalJSON(body.pull_request.head)
+//
+// jsonPatch
+//
+// Applies a JSON Patch (RFC 6902) to a value and returns the patched copy.
+// The operations are applied in order, and the first failing operation, e.g.
+// a failed test or a missing path, fails the expression with its index.
+//
+// 		<dyn>.jsonPatch(<list>) -> <dyn>
+//
+// Examples:
+//
+// 		body.jsonPatch([{'op': 'move', 'from': '/pull_request/head', 'path': '/head'}, {'op': 'remove', 'path': '/sender'}])
+//
+// count
+//
+// A macro that returns the number of elements of a list, or keys of a map,
+// for which the predicate is true. Like exists and all, the predicate is an
+// expression over the variable named by the first argument.
+//
+// 		<list>.count(<ident>, <predicate>) -> <int>
+//
+// Examples:
+//
+// 		body.files.count(f, f.additions > 0) <= 500
+//
+// 		body.reviews.count(r, r.state == 'APPROVED') >= 1
+//
+// join
+//
+// Joins the elements of a list into a string, with the separator between
+// them. Strings are joined as they are, other scalars are converted as with
+// string() and maps, lists and null are encoded as canonical JSON. An empty
+// list returns an empty string.
+//
+// 		join(<list>, <string>) -> <string>
+//
+// Examples:
+//
+// 		join(body.commits.map(c, c.id), ',')
+//
+// 		join([1, 2.0, true], ' ') == '1 2 true'
+//
+// redact
+//
+// Masks the substrings of a string that match a regular expression, replacing
+// each character of a match with an asterisk so that the masked value keeps
+// the length and shape of the original. Invalid expressions fail the
+// expression.
+//
+// 		redact(<string>, <string>) -> <string>
+//
+// 		<string>.redact(<string>) -> <string>
+//
+// Examples:
+//
+// 		redact(body.message, 'ghp_[A-Za-z0-9]+')
+//
+// 		'token=abc123'.redact('[0-9]+') == 'token=abc***'
+//
+// hasExtension
+//
+// Returns true if an earlier interceptor in the chain added a non-null value
+// under the given key of the extensions. Nested keys are separated by dots.
+// Missing keys return false rather than an error.
+//
+// 		hasExtension(<string>) -> <bool>
+//
+// Examples:
+//
+// 		hasExtension('bitbucketServer.pullRequest') ? extensions.bitbucketServer.pullRequest.id : 0
+
+//
+// decodeJWT
+//
+// Decodes the claims of a JWT into a map of strings to dynamic values. The
+// signature of the token is NOT verified, so the claims must not be trusted
+// unless the token is verified by other means, e.g. with verifyJWT.
+//
+// 		<string>.decodeJWT() -> map<string, dyn>
+//
+// Examples:
+//
+// 		header.canonical('X-Id-Token').decodeJWT().sub
+//
+// verifyJWT
+//
+// Verifies the signature and the exp, nbf and iat claims of a JWT and returns
+// its claims. The key is read from the provided key, secret-name combination
+// in the namespace the event-listener is in. The secret can contain either a
+// JSON Web Key Set, from which the key is selected by the "kid" header of the
+// token, or a shared key for HMAC signed tokens.
+//
+// 		<string>.verifyJWT(<string>, <string>) -> map<string, dyn>
+//
+// Examples:
+//
+// 		header.canonical('X-Id-Token').verifyJWT('jwks', 'ci-keys').sub in ['ci-bot']
+//
+// parseCertificate
+//
+// Parses a PEM X.509 certificate, e.g. one embedded in the payload by a
+// device, and returns its subject, issuer, serialNumber, notBefore, notAfter,
+// dnsNames, emailAddresses, ipAddresses, uris, isCA and SHA-256 fingerprint.
+// The certificate is NOT verified, so its details must not be trusted unless
+// it is verified by other means, e.g. with verifyCertificate.
+//
+// 		<string>.parseCertificate() -> map<string, dyn>
+//
+// Examples:
+//
+// 		body.certificate.parseCertificate().subject.commonName
+//
+// verifyCertificate
+//
+// Verifies that a PEM X.509 certificate is currently valid and issued by one of
+// the PEM CA certificates read from the provided key, secret-name combination
+// in the namespace the event-listener is in, and returns its details like
+// parseCertificate. The certificates following the first one in the PEM are
+// used as intermediates.
+//
+// 		<string>.verifyCertificate(<string>, <string>) -> map<string, dyn>
+//
+// Examples:
+//
+// 		body.certificate.verifyCertificate('ca.crt', 'device-ca').subject.organization == ['devices']
+//
+// lookupAddr
+//
+// Returns the names that an IP address reverse resolves to and that resolve
+// back to it, without the trailing dot. Other names are left out, as anyone
+// controlling the reverse DNS zone of an address can point it to any name.
+// Failed lookups fail the expression.
+//
+// 		<string>.lookupAddr() -> list<string>
+//
+// Examples:
+//
+// 		clientIP.lookupAddr().exists(n, n.endsWith('.hooks.example.com'))
+//
+// lookupHost
+//
+// Returns the IP addresses of a host. Failed lookups fail the expression.
+//
+// 		<string>.lookupHost() -> list<string>
+//
+// Examples:
+//
+// 		clientIP in 'ci.example.com'.lookupHost()
+//
+// matchesHost
+//
+// Returns true if an IP address is one of the addresses of the hosts in the
+// list, or if one of the names returned by lookupAddr matches one of them,
+// where "*.example.com" matches the subdomains of example.com. Failed lookups
+// return false, or true if the interceptors are configured to fail open.
+//
+// 		<string>.matchesHost(<list<string>>) -> <bool>
+//
+// Examples:
+//
+// 		clientIP.matchesHost(['*.hooks.example.com', 'ci.example.org'])
+//
+// uuid
+//
+// Returns a random UUID. Every call in the filter and overlays of a CEL
+// interceptor for an event returns the same UUID.
+//
+// 		uuid() -> <string>
+//
+// Examples:
+//
+// 		'build-' + uuid()
+//
+// randAlphaNum
+//
+// Returns a random string of lowercase letters and digits of the given length,
+// between 1 and 64. Every call with the same length in the filter and overlays
+// of a CEL interceptor for an event returns the same string.
+//
+// 		randAlphaNum(<int>) -> <string>
+//
+// Examples:
+//
+// 		body.repository.name + '-' + randAlphaNum(8)
+//
+// equalsIgnoreCase
+//
+// Returns true if two strings are equal under Unicode case folding.
+//
+// 		<string>.equalsIgnoreCase(<string>) -> <bool>
+//
+// Examples:
+//
+// 		body.label.name.equalsIgnoreCase('Deploy')
+//
+// containsIgnoreCase
+//
+// Returns true if a string contains a substring under Unicode case folding.
+//
+// 		<string>.containsIgnoreCase(<string>) -> <bool>
+//
+// Examples:
+//
+// 		body.pull_request.title.containsIgnoreCase('[skip ci]')
+//
+// fuzzyMatch
+//
+// Returns true if the Levenshtein distance between two strings, ignoring case,
+// is at most the given threshold, between 0 and 8. Both strings must be at most
+// 256 characters long, which bounds the cost of the comparison.
+//
+// 		<string>.fuzzyMatch(<string>, <int>) -> <bool>
+//
+// Examples:
+//
+// 		body.ref.fuzzyMatch('refs/heads/release', 2)
+//
+// intersect
+//
+// Returns the elements of the first list that are also in the second.
+//
+// 		intersect(<list>, <list>) -> <list>
+//
+// Examples:
+//
+// 		intersect(extensions.changed_files.split(','), ['go.mod', 'go.sum']).size() > 0
+//
+// difference
+//
+// Returns the elements of the first list that are not in the second.
+//
+// 		difference(<list>, <list>) -> <list>
+//
+// Examples:
+//
+// 		difference(['lgtm', 'approved'], body.pull_request.labels.map(l, l.name)) == []
+//
+// union
+//
+// Returns the elements of the first list followed by the elements of the
+// second that are not in the first.
+//
+// 		union(<list>, <list>) -> <list>
+//
+// Examples:
+//
+// 		union(body.pull_request.assignees.map(a, a.login), ['release-bot'])
+//
+// The results of intersect, difference and union have no duplicates, and keep
+// the order in which their elements first appear in the lists. Elements are
+// compared like with the == operator, which is meant for strings, numbers and
+// bools: maps and lists are equal if all of their entries or elements are.
 
 // Triggers creates and returns a new cel.Lib with the triggers extensions.
 func Triggers(ctx context.Context, ns string, sg interceptors.SecretGetter) cel.EnvOption {
@@ -182,11 +456,56 @@ func (t triggersLib) CompileOptions() []cel.EnvOption {
 		cel.Function("parseURL",
 			cel.MemberOverload("parseURL_string", []*cel.Type{cel.StringType}, mapStrDyn,
 				cel.UnaryBinding(parseURLString))),
+		cel.Function("decodeJWT",
+			cel.MemberOverload("decodeJWT_string", []*cel.Type{cel.StringType}, mapStrDyn,
+				cel.UnaryBinding(decodeJWT))),
+		cel.Function("verifyJWT",
+			cel.MemberOverload("verifyJWT_string_string", []*cel.Type{cel.StringType, cel.StringType, cel.StringType}, mapStrDyn,
+				cel.FunctionBinding(makeVerifyJWT(t.ctx, t.defaultNS, t.secretGetter)))),
+		cel.Function("parseCertificate",
+			cel.MemberOverload("parseCertificate_string", []*cel.Type{cel.StringType}, mapStrDyn,
+				cel.UnaryBinding(parseCertificate))),
+		cel.Function("verifyCertificate",
+			cel.MemberOverload("verifyCertificate_string_string_string", []*cel.Type{cel.StringType, cel.StringType, cel.StringType}, mapStrDyn,
+				cel.FunctionBinding(makeVerifyCertificate(t.ctx, t.defaultNS, t.secretGetter)))),
 		cel.Function("marshalJSON",
 			cel.MemberOverload("marshalJSON_map", []*cel.Type{mapStrDyn}, cel.StringType,
 				cel.UnaryBinding(marshalJSON)),
 			cel.MemberOverload("marshalJSON_list", []*cel.Type{listStrDyn}, cel.StringType,
 				cel.UnaryBinding(marshalJSON))),
+		cel.Function("canonicalJSON",
+			cel.Overload("canonicalJSON_dyn", []*cel.Type{cel.DynType}, cel.StringType,
+				cel.UnaryBinding(canonicalJSON))),
+		cel.Function("jsonPatch",
+			cel.MemberOverload("jsonPatch_dyn_list", []*cel.Type{cel.DynType, cel.ListType(cel.DynType)}, cel.DynType,
+				cel.BinaryBinding(applyJSONPatch))),
+		cel.Function("join",
+			cel.Overload("join_list_string", []*cel.Type{cel.ListType(cel.DynType), cel.StringType}, cel.StringType,
+				cel.BinaryBinding(joinList))),
+		cel.Function("redact",
+			cel.Overload("redact_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.StringType,
+				cel.BinaryBinding(redactString)),
+			cel.MemberOverload("string_redact_string", []*cel.Type{cel.StringType, cel.StringType}, cel.StringType,
+				cel.BinaryBinding(redactString))),
+		cel.Function("equalsIgnoreCase",
+			cel.MemberOverload("string_equalsIgnoreCase_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(equalsIgnoreCase))),
+		cel.Function("containsIgnoreCase",
+			cel.MemberOverload("string_containsIgnoreCase_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(containsIgnoreCase))),
+		cel.Function("fuzzyMatch",
+			cel.MemberOverload("string_fuzzyMatch_string_int", []*cel.Type{cel.StringType, cel.StringType, cel.IntType}, cel.BoolType,
+				cel.FunctionBinding(fuzzyMatch))),
+		cel.Function("intersect",
+			cel.Overload("intersect_list_list", []*cel.Type{cel.ListType(cel.DynType), cel.ListType(cel.DynType)}, cel.ListType(cel.DynType),
+				cel.BinaryBinding(intersect))),
+		cel.Function("difference",
+			cel.Overload("difference_list_list", []*cel.Type{cel.ListType(cel.DynType), cel.ListType(cel.DynType)}, cel.ListType(cel.DynType),
+				cel.BinaryBinding(difference))),
+		cel.Function("union",
+			cel.Overload("union_list_list", []*cel.Type{cel.ListType(cel.DynType), cel.ListType(cel.DynType)}, cel.ListType(cel.DynType),
+				cel.BinaryBinding(union))),
+		cel.Macros(cel.NewReceiverMacro("count", 2, countMacroExpander)),
 	}
 }
 
@@ -194,6 +513,58 @@ func (t triggersLib) ProgramOptions() []cel.ProgramOption {
 	return []cel.ProgramOption{}
 }
 
+// Extensions creates and returns a new cel.Lib with the functions that operate
+// on the extensions added by earlier interceptors in the chain.
+func Extensions(extensions map[string]interface{}) cel.EnvOption {
+	return cel.Lib(extensionsLib{extensions: extensions})
+}
+
+type extensionsLib struct {
+	extensions map[string]interface{}
+}
+
+func (e extensionsLib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("hasExtension",
+			cel.Overload("hasExtension_string", []*cel.Type{cel.StringType}, cel.BoolType,
+				cel.UnaryBinding(e.hasExtension))),
+	}
+}
+
+func (e extensionsLib) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+func (e extensionsLib) hasExtension(val ref.Val) ref.Val {
+	name, ok := val.(types.String)
+	if !ok {
+		return types.ValOrErr(name, "unexpected type '%v' passed to hasExtension", val.Type())
+	}
+	var current interface{} = e.extensions
+	for _, key := range strings.Split(string(name), ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return types.False
+		}
+		if current, ok = m[key]; !ok {
+			return types.False
+		}
+	}
+	return types.Bool(current != nil)
+}
+
+// countMacroExpander expands <iterRange>.count(<iterVar>, <predicate>) into a comprehension that adds one
+// to the accumulator for each element matching the predicate, in the same way as exists_one.
+func countMacroExpander(eh cel.MacroExprHelper, target *exprpb.Expr, args []*exprpb.Expr) (*exprpb.Expr, *common.Error) {
+	v, ok := args[0].ExprKind.(*exprpb.Expr_IdentExpr)
+	if !ok {
+		return nil, &common.Error{Message: "argument must be a simple name", Location: eh.OffsetLocation(args[0].GetId())}
+	}
+	step := eh.GlobalCall(operators.Conditional, args[1],
+		eh.GlobalCall(operators.Add, eh.AccuIdent(), eh.LiteralInt(1)), eh.AccuIdent())
+	return eh.Fold(v.IdentExpr.GetName(), target, parser.AccumulatorName, eh.LiteralInt(0), eh.LiteralBool(true), step, eh.AccuIdent()), nil
+}
+
 func matchHeader(vals ...ref.Val) ref.Val {
 	h, err := vals[0].ConvertToNative(reflect.TypeOf(http.Header{}))
 	if err != nil {
@@ -324,6 +695,230 @@ func marshalJSON(val ref.Val) ref.Val {
 	return types.String(marshaledVal)
 }
 
+func canonicalJSON(val ref.Val) ref.Val {
+	nativeVal, err := val.ConvertToNative(structType)
+	if err != nil {
+		return types.NewErr("failed to convert %v to JSON: %w", val.Type(), err)
+	}
+
+	// encoding/json sorts the keys of maps, and unlike the protobuf JSON
+	// encoding used by marshalJSON, doesn't vary its whitespace.
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(nativeVal.(*structpb.Value).AsInterface()); err != nil {
+		return types.NewErr("failed to marshal to json: %w", err)
+	}
+	return types.String(bytes.TrimSuffix(b.Bytes(), []byte("\n")))
+}
+
+func joinList(list, sep ref.Val) ref.Val {
+	l, ok := list.(traits.Lister)
+	if !ok {
+		return types.ValOrErr(list, "unexpected type '%v' passed to join", list.Type())
+	}
+	separator, ok := sep.(types.String)
+	if !ok {
+		return types.ValOrErr(sep, "unexpected type '%v' passed as the separator to join", sep.Type())
+	}
+	var elements []string
+	for it := l.Iterator(); it.HasNext() == types.True; {
+		val := it.Next()
+		var str ref.Val
+		switch val.Type() {
+		case types.MapType, types.ListType, types.NullType:
+			str = canonicalJSON(val)
+		default:
+			str = val.ConvertToType(types.StringType)
+		}
+		if types.IsError(str) {
+			return types.NewErr("failed to convert element %d of type %v to a string in join: %v", len(elements), val.Type(), str)
+		}
+		elements = append(elements, string(str.(types.String)))
+	}
+	return types.String(strings.Join(elements, string(separator)))
+}
+
+func redactString(lhs, rhs ref.Val) ref.Val {
+	str, ok := lhs.(types.String)
+	if !ok {
+		return types.ValOrErr(lhs, "unexpected type '%v' passed to redact", lhs.Type())
+	}
+	pattern, ok := rhs.(types.String)
+	if !ok {
+		return types.ValOrErr(rhs, "unexpected type '%v' passed as the pattern to redact", rhs.Type())
+	}
+	re, err := regexp.Compile(string(pattern))
+	if err != nil {
+		return types.NewErr("failed to compile the pattern of redact: %w", err)
+	}
+	return types.String(re.ReplaceAllStringFunc(string(str), func(match string) string {
+		return strings.Repeat("*", utf8.RuneCountInString(match))
+	}))
+}
+
+func equalsIgnoreCase(lhs, rhs ref.Val) ref.Val {
+	str, ok := lhs.(types.String)
+	if !ok {
+		return types.ValOrErr(lhs, "unexpected type '%v' passed to equalsIgnoreCase", lhs.Type())
+	}
+	other, ok := rhs.(types.String)
+	if !ok {
+		return types.ValOrErr(rhs, "unexpected type '%v' passed as the argument of equalsIgnoreCase", rhs.Type())
+	}
+	return types.Bool(strings.EqualFold(string(str), string(other)))
+}
+
+func containsIgnoreCase(lhs, rhs ref.Val) ref.Val {
+	str, ok := lhs.(types.String)
+	if !ok {
+		return types.ValOrErr(lhs, "unexpected type '%v' passed to containsIgnoreCase", lhs.Type())
+	}
+	substr, ok := rhs.(types.String)
+	if !ok {
+		return types.ValOrErr(rhs, "unexpected type '%v' passed as the argument of containsIgnoreCase", rhs.Type())
+	}
+	return types.Bool(strings.Contains(foldCase(string(str)), foldCase(string(substr))))
+}
+
+const (
+	// maxFuzzyMatchThreshold is the largest Levenshtein distance that fuzzyMatch accepts.
+	maxFuzzyMatchThreshold = 8
+	// maxFuzzyMatchLength is the length in characters of the longest strings that fuzzyMatch compares.
+	maxFuzzyMatchLength = 256
+)
+
+func fuzzyMatch(vals ...ref.Val) ref.Val {
+	str, ok := vals[0].(types.String)
+	if !ok {
+		return types.ValOrErr(vals[0], "unexpected type '%v' passed to fuzzyMatch", vals[0].Type())
+	}
+	other, ok := vals[1].(types.String)
+	if !ok {
+		return types.ValOrErr(vals[1], "unexpected type '%v' passed as the string of fuzzyMatch", vals[1].Type())
+	}
+	threshold, ok := vals[2].(types.Int)
+	if !ok {
+		return types.ValOrErr(vals[2], "unexpected type '%v' passed as the threshold of fuzzyMatch", vals[2].Type())
+	}
+	if threshold < 0 || threshold > maxFuzzyMatchThreshold {
+		return types.NewErr("the threshold of fuzzyMatch must be between 0 and %d, got %d", maxFuzzyMatchThreshold, threshold)
+	}
+	a, b := []rune(foldCase(string(str))), []rune(foldCase(string(other)))
+	if len(a) > maxFuzzyMatchLength || len(b) > maxFuzzyMatchLength {
+		return types.NewErr("fuzzyMatch compares strings of at most %d characters, got %d and %d", maxFuzzyMatchLength, len(a), len(b))
+	}
+	return types.Bool(levenshteinWithin(a, b, int(threshold)))
+}
+
+// foldCase maps s to a form in which strings that are equal under Unicode case
+// folding are identical.
+func foldCase(s string) string {
+	return strings.ToLower(strings.ToUpper(s))
+}
+
+// levenshteinWithin returns whether the Levenshtein distance between a and b is
+// at most limit. Only the cells of the matrix within limit of its diagonal are
+// computed, since the others are always above limit.
+func levenshteinWithin(a, b []rune, limit int) bool {
+	if d := len(a) - len(b); d > limit || -d > limit {
+		return false
+	}
+	// Cells outside the band hold limit+1, which stands for any distance above limit.
+	outside := limit + 1
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+		if j > limit {
+			prev[j] = outside
+		}
+	}
+	for i := 1; i <= len(a); i++ {
+		lo, hi := i-limit, i+limit
+		if lo < 1 {
+			lo = 1
+		}
+		if hi > len(b) {
+			hi = len(b)
+		}
+		for j := range curr {
+			curr[j] = outside
+		}
+		if i <= limit {
+			curr[0] = i
+		}
+		rowMin := curr[0]
+		for j := lo; j <= hi; j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d := prev[j-1] + cost
+			if v := prev[j] + 1; v < d {
+				d = v
+			}
+			if v := curr[j-1] + 1; v < d {
+				d = v
+			}
+			if d > outside {
+				d = outside
+			}
+			curr[j] = d
+			if d < rowMin {
+				rowMin = d
+			}
+		}
+		if rowMin > limit {
+			return false
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)] <= limit
+}
+
+func applyJSONPatch(val, ops ref.Val) ref.Val {
+	doc, err := toJSON(val)
+	if err != nil {
+		return types.NewErr("failed to convert %v to JSON in jsonPatch: %w", val.Type(), err)
+	}
+	rawPatch, err := toJSON(ops)
+	if err != nil {
+		return types.NewErr("failed to convert patch to JSON in jsonPatch: %w", err)
+	}
+	patch, err := jsonpatch.DecodePatch(rawPatch)
+	if err != nil {
+		return types.NewErr("failed to decode patch in jsonPatch: %w", err)
+	}
+
+	// The operations are applied one at a time to report which one failed.
+	for i, op := range patch {
+		if doc, err = (jsonpatch.Patch{op}).Apply(doc); err != nil {
+			path, _ := op.Path()
+			return types.NewErr("failed to apply operation %d (%s %s) in jsonPatch: %w", i, op.Kind(), path, err)
+		}
+	}
+
+	var patched interface{}
+	if err := json.Unmarshal(doc, &patched); err != nil {
+		return types.NewErr("failed to decode patched value in jsonPatch: %w", err)
+	}
+	r, err := types.NewRegistry()
+	if err != nil {
+		return types.NewErr("failed to create a new registry in jsonPatch: %w", err)
+	}
+	return r.NativeToValue(patched)
+}
+
+// toJSON returns the JSON encoding of a CEL value.
+func toJSON(val ref.Val) ([]byte, error) {
+	nativeVal, err := val.ConvertToNative(structType)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(nativeVal.(*structpb.Value).AsInterface())
+}
+
 func max(x, y types.Int) types.Int {
 	switch x.Compare(y) {
 	case types.IntNegOne:
@@ -0,0 +1,184 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter/functions"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+)
+
+// maxCertificatesSize bounds the size of the PEM certificates parsed by
+// parseCertificate and verifyCertificate.
+const maxCertificatesSize = 64 * 1024
+
+// parseCertificate parses a PEM certificate without verifying it, and returns
+// its details.
+func parseCertificate(val ref.Val) ref.Val {
+	str, ok := val.(types.String)
+	if !ok {
+		return types.ValOrErr(str, "unexpected type '%v' passed to parseCertificate", val.Type())
+	}
+	certs, err := parsePEMCertificates([]byte(str))
+	if err != nil {
+		return types.NewErr("failed to parse certificate in parseCertificate: %w", err)
+	}
+	return certificateToMap(certs[0])
+}
+
+// makeVerifyCertificate creates and returns a functions.FunctionOp that
+// verifies a PEM certificate against the CA certificates read from a
+// Kubernetes secret in the defaultNS, and returns its details.
+func makeVerifyCertificate(ctx context.Context, defaultNS string, sg interceptors.SecretGetter) functions.FunctionOp {
+	return func(vals ...ref.Val) ref.Val {
+		cert, ok := vals[0].(types.String)
+		if !ok {
+			return types.ValOrErr(cert, "unexpected type '%v' passed to verifyCertificate", vals[0].Type())
+		}
+		secretKey, ok := vals[1].(types.String)
+		if !ok {
+			return types.ValOrErr(secretKey, "unexpected type '%v' passed to verifyCertificate", vals[1].Type())
+		}
+		secretName, ok := vals[2].(types.String)
+		if !ok {
+			return types.ValOrErr(secretName, "unexpected type '%v' passed to verifyCertificate", vals[2].Type())
+		}
+
+		certs, err := parsePEMCertificates([]byte(cert))
+		if err != nil {
+			return types.NewErr("failed to parse certificate in verifyCertificate: %w", err)
+		}
+		secretRef := &triggersv1.SecretRef{
+			SecretKey:  string(secretKey),
+			SecretName: string(secretName),
+		}
+		secret, err := sg.Get(ctx, defaultNS, secretRef)
+		if err != nil {
+			return types.NewErr("failed to find secret '%#v' in verifyCertificate: %w", *secretRef, err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(secret) {
+			return types.NewErr("failed to read CA certificates from secret '%#v' in verifyCertificate", *secretRef)
+		}
+
+		// The certificates following the first one are the intermediates of its chain.
+		intermediates := x509.NewCertPool()
+		for _, c := range certs[1:] {
+			intermediates.AddCert(c)
+		}
+		if _, err := certs[0].Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			CurrentTime:   time.Now(),
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		}); err != nil {
+			return types.NewErr("failed to verify certificate in verifyCertificate: %w", err)
+		}
+		return certificateToMap(certs[0])
+	}
+}
+
+// parsePEMCertificates parses the PEM CERTIFICATE blocks of data, which must
+// have at least one. Other blocks are ignored.
+func parsePEMCertificates(data []byte) ([]*x509.Certificate, error) {
+	if len(data) > maxCertificatesSize {
+		return nil, fmt.Errorf("certificates are larger than %d bytes", maxCertificatesSize)
+	}
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM certificate found")
+	}
+	return certs, nil
+}
+
+// certificateToMap returns the details of the certificate to gate events on.
+func certificateToMap(cert *x509.Certificate) ref.Val {
+	ips := make([]string, 0, len(cert.IPAddresses))
+	for _, ip := range cert.IPAddresses {
+		ips = append(ips, ip.String())
+	}
+	uris := make([]string, 0, len(cert.URIs))
+	for _, u := range cert.URIs {
+		uris = append(uris, u.String())
+	}
+	fingerprint := sha256.Sum256(cert.Raw)
+	details := map[string]interface{}{
+		"subject": map[string]interface{}{
+			"string":             cert.Subject.String(),
+			"commonName":         cert.Subject.CommonName,
+			"organization":       nonNil(cert.Subject.Organization),
+			"organizationalUnit": nonNil(cert.Subject.OrganizationalUnit),
+			"country":            nonNil(cert.Subject.Country),
+		},
+		"issuer": map[string]interface{}{
+			"string":             cert.Issuer.String(),
+			"commonName":         cert.Issuer.CommonName,
+			"organization":       nonNil(cert.Issuer.Organization),
+			"organizationalUnit": nonNil(cert.Issuer.OrganizationalUnit),
+			"country":            nonNil(cert.Issuer.Country),
+		},
+		"serialNumber":   cert.SerialNumber.Text(16),
+		"notBefore":      cert.NotBefore,
+		"notAfter":       cert.NotAfter,
+		"dnsNames":       nonNil(cert.DNSNames),
+		"emailAddresses": nonNil(cert.EmailAddresses),
+		"ipAddresses":    ips,
+		"uris":           uris,
+		"isCA":           cert.IsCA,
+		"fingerprint":    hex.EncodeToString(fingerprint[:]),
+	}
+	r, err := types.NewRegistry()
+	if err != nil {
+		return types.NewErr("failed to create a new registry for certificate details: %w", err)
+	}
+	return types.NewDynamicMap(r, details)
+}
+
+// nonNil returns an empty list rather than nil, so that expressions can check
+// the size of the lists.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+)
+
+// testCA is a certificate with its key, which can sign other certificates.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  string
+}
+
+// issueCertificate returns a certificate from the template, signed by the issuer or
+// self-signed if it is nil.
+func issueCertificate(t *testing.T, template *x509.Certificate, issuer *testCA) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, signer := template, key
+	if issuer != nil {
+		parent, signer = issuer.cert, issuer.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key, pem: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))}
+}
+
+func certificateTemplate(serial int64, cn string, isCA bool, notAfter time.Time) *x509.Certificate {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"devices"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{cn + ".devices.example.com"},
+	}
+	if isCA {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+		template.ExtKeyUsage = nil
+		template.DNSNames = nil
+	}
+	return template
+}
+
+func TestCertificateEvaluation(t *testing.T) {
+	valid := time.Now().Add(time.Hour)
+	ca := issueCertificate(t, certificateTemplate(1, "Device CA", true, valid), nil)
+	intermediate := issueCertificate(t, certificateTemplate(2, "Device Intermediate CA", true, valid), ca)
+	otherCA := issueCertificate(t, certificateTemplate(3, "Other CA", true, valid), nil)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNS,
+			Name:      "device-ca",
+		},
+		Data: map[string][]byte{
+			"ca.crt":  []byte(ca.pem),
+			"garbage": []byte("not a certificate"),
+		},
+	}
+	evalEnv := map[string]interface{}{
+		"body": map[string]interface{}{
+			"device":      issueCertificate(t, certificateTemplate(10, "sensor-1", false, valid), ca).pem,
+			"chained":     issueCertificate(t, certificateTemplate(11, "sensor-2", false, valid), intermediate).pem + intermediate.pem,
+			"unchained":   issueCertificate(t, certificateTemplate(12, "sensor-3", false, valid), intermediate).pem,
+			"expired":     issueCertificate(t, certificateTemplate(13, "sensor-4", false, time.Now().Add(-time.Minute)), ca).pem,
+			"otherIssuer": issueCertificate(t, certificateTemplate(14, "sensor-5", false, valid), otherCA).pem,
+			"garbage":     "-----BEGIN CERTIFICATE-----\nbm90IGEgY2VydGlmaWNhdGU=\n-----END CERTIFICATE-----\n",
+			"notPEM":      "not a certificate",
+		},
+	}
+
+	ctx, _ := test.SetupFakeContext(t)
+	_, clientset := fakekubeclient.With(ctx, secret)
+	env, err := makeCelEnv(context.Background(), testNS, interceptors.DefaultSecretGetter(clientset.CoreV1()), nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		expr string
+		want types.Bool
+	}{{
+		name: "parse subject",
+		expr: "body.device.parseCertificate().subject.commonName == 'sensor-1'",
+		want: types.True,
+	}, {
+		name: "parse certificate of another issuer",
+		expr: "body.otherIssuer.parseCertificate().issuer.commonName == 'Other CA'",
+		want: types.True,
+	}, {
+		name: "parse validity",
+		expr: "body.expired.parseCertificate().notAfter < timestamp('" + time.Now().Format(time.RFC3339) + "')",
+		want: types.True,
+	}, {
+		name: "parse SANs",
+		expr: "'sensor-1.devices.example.com' in body.device.parseCertificate().dnsNames && body.device.parseCertificate().ipAddresses.size() == 0",
+		want: types.True,
+	}, {
+		name: "verify certificate issued by the CA",
+		expr: "body.device.verifyCertificate('ca.crt', 'device-ca').subject.organization == ['devices']",
+		want: types.True,
+	}, {
+		name: "verify certificate with its intermediate",
+		expr: "body.chained.verifyCertificate('ca.crt', 'device-ca').issuer.commonName == 'Device Intermediate CA'",
+		want: types.True,
+	}, {
+		name: "verified serial number",
+		expr: "body.device.verifyCertificate('ca.crt', 'device-ca').serialNumber == 'a'",
+		want: types.True,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evaluate(tt.expr, env, evalEnv)
+			if err != nil {
+				t.Fatalf("evaluate() got an error %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		name string
+		expr string
+		want string
+	}{{
+		name: "parse invalid certificate",
+		expr: "body.garbage.parseCertificate().subject.commonName == 'sensor-1'",
+		want: "failed to parse certificate in parseCertificate",
+	}, {
+		name: "parse text without certificate",
+		expr: "body.notPEM.parseCertificate().subject.commonName == 'sensor-1'",
+		want: "no PEM certificate found",
+	}, {
+		name: "verify expired certificate",
+		expr: "body.expired.verifyCertificate('ca.crt', 'device-ca').subject.commonName == 'sensor-4'",
+		want: "expired",
+	}, {
+		name: "verify certificate of another issuer",
+		expr: "body.otherIssuer.verifyCertificate('ca.crt', 'device-ca').subject.commonName == 'sensor-5'",
+		want: "unknown authority",
+	}, {
+		name: "verify certificate without its intermediate",
+		expr: "body.unchained.verifyCertificate('ca.crt', 'device-ca').subject.commonName == 'sensor-3'",
+		want: "unknown authority",
+	}, {
+		name: "verify with a secret without CA certificates",
+		expr: "body.device.verifyCertificate('garbage', 'device-ca').subject.commonName == 'sensor-1'",
+		want: "failed to read CA certificates",
+	}, {
+		name: "verify with missing secret",
+		expr: "body.device.verifyCertificate('ca.crt', 'missing').subject.commonName == 'sensor-1'",
+		want: "failed to find secret",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := evaluate(tt.expr, env, evalEnv)
+			if err == nil {
+				t.Fatal("evaluate() expected err but got nil")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("evaluate() got %s, wanted %s", err, tt.want)
+			}
+		})
+	}
+}
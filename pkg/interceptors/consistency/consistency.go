@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consistency
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"google.golang.org/grpc/codes"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+var _ triggersv1.InterceptorInterface = (*Interceptor)(nil)
+
+const (
+	// ExactComparison requires the header and body values to be equal.
+	ExactComparison = "exact"
+	// CaseInsensitiveComparison requires the header and body values to be equal under Unicode case folding.
+	CaseInsensitiveComparison = "caseInsensitive"
+)
+
+// InterceptorParams provides a webhook to intercept and pre-process events.
+type InterceptorParams struct {
+	// Pairs lists the headers and body fields that must have the same value.
+	Pairs []Pair `json:"pairs,omitempty"`
+}
+
+// Pair is a header and a body field that must have the same value, e.g. a tenant header and the tenant
+// of the payload.
+type Pair struct {
+	// Header is the name of the header. Every value of the header must match the body field, so that a
+	// sender can't add a matching value to a mismatching one.
+	Header string `json:"header"`
+	// Body is the JSONPath of the field in the body, e.g. tenant.id. The leading $, dot and curly braces
+	// are optional. It must match a single field, which may be a string, a number or a boolean.
+	Body string `json:"body"`
+	// Comparison is how the values are compared: exact or caseInsensitive. Defaults to exact.
+	Comparison string `json:"comparison,omitempty"`
+}
+
+// Interceptor rejects the requests whose headers don't have the same values as fields of their body, such
+// as misrouted events or events replayed with the headers of another one. The pairs are checked in order,
+// and the message names the first one whose values are missing or differ.
+type Interceptor struct{}
+
+func NewInterceptor() *Interceptor {
+	return &Interceptor{}
+}
+
+func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequest) *triggersv1.InterceptorResponse {
+	p := InterceptorParams{}
+	if err := interceptors.UnmarshalParams(r.InterceptorParams, &p); err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "failed to parse interceptor params: %v", err)
+	}
+	if len(p.Pairs) == 0 {
+		return interceptors.Fail(codes.InvalidArgument, "consistency interceptor pairs is empty")
+	}
+	paths := make([]*jsonpath.JSONPath, len(p.Pairs))
+	for i, pair := range p.Pairs {
+		if strings.TrimSpace(pair.Header) == "" {
+			return interceptors.Failf(codes.InvalidArgument, "consistency interceptor pairs[%d].header is empty", i)
+		}
+		if strings.TrimSpace(pair.Body) == "" {
+			return interceptors.Failf(codes.InvalidArgument, "consistency interceptor pairs[%d].body is empty", i)
+		}
+		switch pair.Comparison {
+		case "", ExactComparison, CaseInsensitiveComparison:
+		default:
+			return interceptors.Failf(codes.InvalidArgument, "consistency interceptor pairs[%d].comparison %q must be %s or %s", i, pair.Comparison, ExactComparison, CaseInsensitiveComparison)
+		}
+		j := jsonpath.New(pair.Body)
+		if err := j.Parse(interceptors.JSONPathExpression(pair.Body)); err != nil {
+			return interceptors.Failf(codes.InvalidArgument, "consistency interceptor pairs[%d].body %q is not a valid JSONPath: %v", i, pair.Body, err)
+		}
+		paths[i] = j
+	}
+
+	var numbers triggersv1.JSONNumbers
+	if r.Context != nil {
+		numbers = r.Context.JSONNumbers
+	}
+	var body interface{}
+	if err := numbers.Unmarshal([]byte(r.Body), &body); err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "body is not valid JSON: %v", err)
+	}
+	headers := interceptors.Canonical(r.Header)
+	for i, pair := range p.Pairs {
+		name := http.CanonicalHeaderKey(pair.Header)
+		headerValues := headers[name]
+		if len(headerValues) == 0 {
+			return interceptors.Failf(codes.FailedPrecondition, "no %s header to compare with body field %s", name, pair.Body)
+		}
+		results, err := paths[i].FindResults(body)
+		var values []interface{}
+		if err == nil {
+			for _, res := range results {
+				for _, v := range res {
+					values = append(values, v.Interface())
+				}
+			}
+		}
+		if len(values) != 1 {
+			return interceptors.Failf(codes.FailedPrecondition, "body field %s to compare with header %s matches %d values, expected 1", pair.Body, name, len(values))
+		}
+		want, ok := scalarString(values[0])
+		if !ok {
+			return interceptors.Failf(codes.FailedPrecondition, "body field %s to compare with header %s is not a string, number or boolean", pair.Body, name)
+		}
+		for _, got := range headerValues {
+			if !equal(pair.Comparison, got, want) {
+				return interceptors.Failf(codes.FailedPrecondition, "header %s value %q doesn't match body field %s value %q", name, got, pair.Body, want)
+			}
+		}
+	}
+
+	return &triggersv1.InterceptorResponse{
+		Continue: true,
+	}
+}
+
+func equal(comparison, a, b string) bool {
+	if comparison == CaseInsensitiveComparison {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// scalarString returns the string form of a JSON string, number or boolean, e.g. 42 for the number 42.
+func scalarString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		// The number as written in the body, if the EventListener decodes numbers exactly.
+		return v.String(), true
+	case float64, bool:
+		b, err := json.Marshal(v)
+		return string(b), err == nil
+	}
+	return "", false
+}
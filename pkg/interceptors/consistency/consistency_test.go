@@ -0,0 +1,200 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consistency
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors/interceptorstest"
+	"google.golang.org/grpc/codes"
+)
+
+const body = `{"tenant": {"id": "Acme", "number": 42}, "event": "push", "private": true, "repositories": [{"name": "a"}, {"name": "b"}], "owner": {"login": "octocat"}}`
+
+func newRequest(body string, header map[string][]string, pairs ...Pair) *triggersv1.InterceptorRequest {
+	return interceptorstest.NewRequest(body, header, map[string]interface{}{"pairs": pairs})
+}
+
+func TestInterceptor_Process_ShouldContinue(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		header map[string][]string
+		pairs  []Pair
+	}{{
+		name:   "exact match",
+		header: map[string][]string{"X-Tenant": {"Acme"}, "X-Event": {"push"}},
+		pairs:  []Pair{{Header: "X-Tenant", Body: "tenant.id"}, {Header: "X-Event", Body: "event", Comparison: ExactComparison}},
+	}, {
+		name:   "case insensitive match",
+		header: map[string][]string{"X-Tenant": {"ACME"}},
+		pairs:  []Pair{{Header: "X-Tenant", Body: "tenant.id", Comparison: CaseInsensitiveComparison}},
+	}, {
+		name:   "header names are case insensitive",
+		header: map[string][]string{"x-tenant": {"Acme"}},
+		pairs:  []Pair{{Header: "X-TENANT", Body: "{.tenant.id}"}},
+	}, {
+		name:   "numbers and booleans",
+		header: map[string][]string{"X-Tenant-Number": {"42"}, "X-Private": {"true"}},
+		pairs:  []Pair{{Header: "X-Tenant-Number", Body: "$.tenant.number"}, {Header: "X-Private", Body: "private"}},
+	}, {
+		name:   "every value matches",
+		header: map[string][]string{"X-Tenant": {"Acme", "acme"}},
+		pairs:  []Pair{{Header: "X-Tenant", Body: "tenant.id", Comparison: CaseInsensitiveComparison}},
+	}, {
+		name:   "array element",
+		header: map[string][]string{"X-Repository": {"b"}},
+		pairs:  []Pair{{Header: "X-Repository", Body: "repositories[1].name"}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			res := NewInterceptor().Process(context.Background(), newRequest(body, tc.header, tc.pairs...))
+			if !res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be true but got %t. \nStatus.Err(): %v", res.Continue, res.Status.Err())
+			}
+		})
+	}
+}
+
+func TestInterceptor_Process_ShouldNotContinue(t *testing.T) {
+	tenant := map[string][]string{"X-Tenant": {"Acme"}}
+	for _, tc := range []struct {
+		name     string
+		body     string
+		header   map[string][]string
+		pairs    []Pair
+		wantCode codes.Code
+		wantMsg  string
+	}{{
+		name:     "no pairs",
+		body:     body,
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "consistency interceptor pairs is empty",
+	}, {
+		name:     "empty header",
+		body:     body,
+		pairs:    []Pair{{Header: " ", Body: "tenant.id"}},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "consistency interceptor pairs[0].header is empty",
+	}, {
+		name:     "empty body path",
+		body:     body,
+		pairs:    []Pair{{Header: "X-Tenant", Body: "tenant.id"}, {Header: "X-Event"}},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "consistency interceptor pairs[1].body is empty",
+	}, {
+		name:     "unknown comparison",
+		body:     body,
+		pairs:    []Pair{{Header: "X-Tenant", Body: "tenant.id", Comparison: "prefix"}},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `consistency interceptor pairs[0].comparison "prefix" must be exact or caseInsensitive`,
+	}, {
+		name:     "invalid path",
+		body:     body,
+		pairs:    []Pair{{Header: "X-Tenant", Body: "repositories[0"}},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `consistency interceptor pairs[0].body "repositories[0" is not a valid JSONPath`,
+	}, {
+		name:     "invalid body",
+		body:     `{"tenant": `,
+		header:   tenant,
+		pairs:    []Pair{{Header: "X-Tenant", Body: "tenant.id"}},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "body is not valid JSON",
+	}, {
+		name:     "mismatch",
+		body:     body,
+		header:   map[string][]string{"X-Tenant": {"Globex"}},
+		pairs:    []Pair{{Header: "X-Tenant", Body: "tenant.id"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  `header X-Tenant value "Globex" doesn't match body field tenant.id value "Acme"`,
+	}, {
+		name:     "case mismatch",
+		body:     body,
+		header:   map[string][]string{"X-Tenant": {"acme"}},
+		pairs:    []Pair{{Header: "X-Tenant", Body: "tenant.id"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  `header X-Tenant value "acme" doesn't match body field tenant.id value "Acme"`,
+	}, {
+		name:     "one of the values mismatches",
+		body:     body,
+		header:   map[string][]string{"X-Tenant": {"Acme", "Globex"}},
+		pairs:    []Pair{{Header: "X-Tenant", Body: "tenant.id"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  `header X-Tenant value "Globex" doesn't match`,
+	}, {
+		name:     "first mismatching pair",
+		body:     body,
+		header:   map[string][]string{"X-Tenant": {"Acme"}, "X-Event": {"pull_request"}},
+		pairs:    []Pair{{Header: "X-Tenant", Body: "tenant.id"}, {Header: "X-Event", Body: "event"}, {Header: "X-Owner", Body: "owner.login"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  `header X-Event value "pull_request" doesn't match body field event value "push"`,
+	}, {
+		name:     "missing header",
+		body:     body,
+		pairs:    []Pair{{Header: "x-tenant", Body: "tenant.id"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "no X-Tenant header to compare with body field tenant.id",
+	}, {
+		name:     "missing body field",
+		body:     body,
+		header:   tenant,
+		pairs:    []Pair{{Header: "X-Tenant", Body: "organization.id"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "body field organization.id to compare with header X-Tenant matches 0 values, expected 1",
+	}, {
+		name:     "several body fields",
+		body:     body,
+		header:   tenant,
+		pairs:    []Pair{{Header: "X-Tenant", Body: "repositories[*].name"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "body field repositories[*].name to compare with header X-Tenant matches 2 values, expected 1",
+	}, {
+		name:     "object body field",
+		body:     body,
+		header:   tenant,
+		pairs:    []Pair{{Header: "X-Tenant", Body: "tenant"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "body field tenant to compare with header X-Tenant is not a string, number or boolean",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			res := NewInterceptor().Process(context.Background(), newRequest(tc.body, tc.header, tc.pairs...))
+			if res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be false but got %t", res.Continue)
+			}
+			if res.Status.Code != tc.wantCode {
+				t.Errorf("Interceptor.Process() got status code %v, want %v", res.Status.Code, tc.wantCode)
+			}
+			if !strings.Contains(res.Status.Message, tc.wantMsg) {
+				t.Errorf("Interceptor.Process() got message %q, want it to contain %q", res.Status.Message, tc.wantMsg)
+			}
+		})
+	}
+}
+
+func TestInterceptor_Process_JSONNumbers(t *testing.T) {
+	header := map[string][]string{"X-Installation": {"12345678901234567890"}}
+	req := newRequest(`{"installation": {"id": 12345678901234567890}}`, header, Pair{Header: "X-Installation", Body: "installation.id"})
+	if res := NewInterceptor().Process(context.Background(), req); res.Continue {
+		t.Fatal("Interceptor.Process() expected a large integer decoded as a float not to match")
+	}
+	req.Context.JSONNumbers = triggersv1.JSONNumbersExact
+	if res := NewInterceptor().Process(context.Background(), req); !res.Continue {
+		t.Fatalf("Interceptor.Process() expected res.Continue to be true but got %t. \nStatus.Err(): %v", res.Continue, res.Status.Err())
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dedup
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/cel-go/common/types"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/pkg/interceptors/cel"
+	"google.golang.org/grpc/codes"
+)
+
+var _ triggersv1.InterceptorInterface = (*Interceptor)(nil)
+
+// DefaultTTL is how long a key stays claimed when no TTL is configured.
+const DefaultTTL = time.Hour
+
+// InterceptorParams configures the deduplication of events.
+type InterceptorParams struct {
+	// Key is a CEL expression evaluated against the request that returns the
+	// idempotency key of the event, e.g. "header['X-Github-Delivery'][0]".
+	Key string `json:"key"`
+	// TTL is how long a key stays claimed, as a duration string. Defaults to
+	// one hour.
+	TTL string `json:"ttl,omitempty"`
+	// FailOpen lets events through when the store cannot be reached. By
+	// default, such events are rejected.
+	FailOpen bool `json:"failOpen,omitempty"`
+}
+
+// Interceptor rejects events whose idempotency key was already claimed by an
+// earlier event for the same Trigger. Keys are claimed in a Store that is
+// shared by all replicas.
+type Interceptor struct {
+	SecretGetter interceptors.SecretGetter
+	Store        Store
+}
+
+func NewInterceptor(sg interceptors.SecretGetter, store Store) *Interceptor {
+	return &Interceptor{
+		SecretGetter: sg,
+		Store:        store,
+	}
+}
+
+func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequest) *triggersv1.InterceptorResponse {
+	p := InterceptorParams{}
+	if err := interceptors.UnmarshalParams(r.InterceptorParams, &p); err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "failed to parse interceptor params: %v", err)
+	}
+	if p.Key == "" {
+		return interceptors.Fail(codes.InvalidArgument, "dedup interceptor key is empty")
+	}
+	ttl := DefaultTTL
+	if p.TTL != "" {
+		d, err := time.ParseDuration(p.TTL)
+		if err != nil || d <= 0 {
+			return interceptors.Failf(codes.InvalidArgument, "dedup interceptor ttl %q is not a positive duration", p.TTL)
+		}
+		ttl = d
+	}
+
+	if r.Context == nil {
+		return interceptors.Failf(codes.InvalidArgument, "no request context passed")
+	}
+
+	val, err := cel.Evaluate(ctx, w.SecretGetter, p.Key, r)
+	if err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "error evaluating dedup key: %v", err)
+	}
+	key, ok := val.(types.String)
+	if !ok {
+		return interceptors.Failf(codes.InvalidArgument, "dedup key expression %s returned %v, expected a string", p.Key, val.Type())
+	}
+	if key == "" {
+		return interceptors.Failf(codes.InvalidArgument, "dedup key expression %s returned an empty string", p.Key)
+	}
+
+	// Keys are scoped to the Trigger, so that several Triggers can process
+	// the same event.
+	claimed, err := w.Store.Claim(ctx, r.Context.TriggerID+"/"+string(key), ttl)
+	if err != nil {
+		if p.FailOpen {
+			return &triggersv1.InterceptorResponse{Continue: true}
+		}
+		return interceptors.Failf(codes.Unavailable, "failed to claim dedup key %q: %v", key, err)
+	}
+	if !claimed {
+		return interceptors.Failf(codes.FailedPrecondition, "event with dedup key %q was already processed", key)
+	}
+	return &triggersv1.InterceptorResponse{Continue: true}
+}
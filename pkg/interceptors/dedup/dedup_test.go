@@ -0,0 +1,193 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dedup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/pkg/interceptors/interceptorstest"
+	"github.com/tektoncd/triggers/test"
+	"google.golang.org/grpc/codes"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+)
+
+// fakeStore records claimed keys in memory.
+type fakeStore struct {
+	claimed map[string]time.Duration
+	err     error
+}
+
+func (s *fakeStore) Claim(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	if _, ok := s.claimed[key]; ok {
+		return false, nil
+	}
+	s.claimed[key] = ttl
+	return true, nil
+}
+
+func newRequest(triggerID, delivery string, params map[string]interface{}) *triggersv1.InterceptorRequest {
+	req := interceptorstest.NewRequest(`{"action":"opened"}`, http.Header{"X-Github-Delivery": []string{delivery}}, params)
+	req.Context.TriggerID = triggerID
+	return req
+}
+
+func TestInterceptor_Process(t *testing.T) {
+	ctx, _ := test.SetupFakeContext(t)
+	clientset := fakekubeclient.Get(ctx)
+	store := &fakeStore{claimed: map[string]time.Duration{}}
+	w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()), store)
+	params := map[string]interface{}{
+		"key": "header['X-Github-Delivery'][0] + '-' + body.action",
+		"ttl": "10m",
+	}
+
+	for _, tt := range []struct {
+		name      string
+		triggerID string
+		delivery  string
+		want      bool
+	}{{
+		name:      "first delivery",
+		triggerID: "namespaces/default/triggers/push",
+		delivery:  "1",
+		want:      true,
+	}, {
+		name:      "redelivery",
+		triggerID: "namespaces/default/triggers/push",
+		delivery:  "1",
+		want:      false,
+	}, {
+		name:      "another delivery",
+		triggerID: "namespaces/default/triggers/push",
+		delivery:  "2",
+		want:      true,
+	}, {
+		name:      "same delivery for another trigger",
+		triggerID: "namespaces/default/triggers/release",
+		delivery:  "1",
+		want:      true,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			res := w.Process(ctx, newRequest(tt.triggerID, tt.delivery, params))
+			if res.Continue != tt.want {
+				t.Fatalf("Process() continue = %t, want %t: %v", res.Continue, tt.want, res.Status.Err())
+			}
+			if !tt.want && res.Status.Code != codes.FailedPrecondition {
+				t.Errorf("Process() code = %v, want %v", res.Status.Code, codes.FailedPrecondition)
+			}
+		})
+	}
+	if got := store.claimed["namespaces/default/triggers/push/1-opened"]; got != 10*time.Minute {
+		t.Errorf("key claimed with ttl %s, want 10m", got)
+	}
+}
+
+func TestInterceptor_Process_DefaultTTL(t *testing.T) {
+	ctx, _ := test.SetupFakeContext(t)
+	store := &fakeStore{claimed: map[string]time.Duration{}}
+	w := NewInterceptor(interceptors.DefaultSecretGetter(fakekubeclient.Get(ctx).CoreV1()), store)
+	res := w.Process(ctx, newRequest("namespaces/default/triggers/push", "1", map[string]interface{}{
+		"key": "header['X-Github-Delivery'][0]",
+	}))
+	if !res.Continue {
+		t.Fatalf("Process() unexpected failure: %v", res.Status.Err())
+	}
+	if got := store.claimed["namespaces/default/triggers/push/1"]; got != DefaultTTL {
+		t.Errorf("key claimed with ttl %s, want %s", got, DefaultTTL)
+	}
+}
+
+func TestInterceptor_Process_StoreFailure(t *testing.T) {
+	ctx, _ := test.SetupFakeContext(t)
+	store := &fakeStore{err: errors.New("store is down")}
+	w := NewInterceptor(interceptors.DefaultSecretGetter(fakekubeclient.Get(ctx).CoreV1()), store)
+
+	res := w.Process(ctx, newRequest("namespaces/default/triggers/push", "1", map[string]interface{}{
+		"key": "header['X-Github-Delivery'][0]",
+	}))
+	if res.Continue {
+		t.Fatal("Process() expected store failure to reject the event")
+	}
+	if res.Status.Code != codes.Unavailable {
+		t.Errorf("Process() code = %v, want %v", res.Status.Code, codes.Unavailable)
+	}
+
+	res = w.Process(ctx, newRequest("namespaces/default/triggers/push", "1", map[string]interface{}{
+		"key":      "header['X-Github-Delivery'][0]",
+		"failOpen": true,
+	}))
+	if !res.Continue {
+		t.Fatalf("Process() expected failOpen to let the event through: %v", res.Status.Err())
+	}
+}
+
+func TestInterceptor_Process_Error(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		params map[string]interface{}
+		want   string
+	}{{
+		name:   "missing key",
+		params: map[string]interface{}{},
+		want:   "key is empty",
+	}, {
+		name:   "invalid ttl",
+		params: map[string]interface{}{"key": "body.action", "ttl": "soon"},
+		want:   `ttl "soon" is not a positive duration`,
+	}, {
+		name:   "negative ttl",
+		params: map[string]interface{}{"key": "body.action", "ttl": "-1m"},
+		want:   `ttl "-1m" is not a positive duration`,
+	}, {
+		name:   "invalid expression",
+		params: map[string]interface{}{"key": "body.action +"},
+		want:   "error evaluating dedup key",
+	}, {
+		name:   "non string key",
+		params: map[string]interface{}{"key": "size(body)"},
+		want:   "expected a string",
+	}, {
+		name:   "empty key",
+		params: map[string]interface{}{"key": "''"},
+		want:   "returned an empty string",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := test.SetupFakeContext(t)
+			w := NewInterceptor(interceptors.DefaultSecretGetter(fakekubeclient.Get(ctx).CoreV1()), &fakeStore{claimed: map[string]time.Duration{}})
+			res := w.Process(ctx, newRequest("namespaces/default/triggers/push", "1", tt.params))
+			if res.Continue {
+				t.Fatal("Process() expected an error")
+			}
+			if res.Status.Code != codes.InvalidArgument {
+				t.Errorf("Process() code = %v, want %v", res.Status.Code, codes.InvalidArgument)
+			}
+			if !strings.Contains(res.Status.Message, tt.want) {
+				t.Errorf("Process() got %q, want %q", res.Status.Message, tt.want)
+			}
+		})
+	}
+}
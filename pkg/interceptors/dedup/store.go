@@ -0,0 +1,164 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dedup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coordinationclientv1 "k8s.io/client-go/kubernetes/typed/coordination/v1"
+)
+
+const (
+	// LeaseLabel is the label set on the Leases created by the LeaseStore.
+	LeaseLabel = "triggers.tekton.dev/dedup"
+	// DefaultCollectInterval is how often the expired Leases of the LeaseStore
+	// are deleted by default.
+	DefaultCollectInterval = 10 * time.Minute
+)
+
+// Store claims idempotency keys for a period of time. Implementations must be
+// safe to share between replicas of the interceptor.
+type Store interface {
+	// Claim claims key for ttl. It returns false if key is already claimed.
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// LeaseStore is a Store that claims keys by holding a Kubernetes Lease per
+// key. Expired Leases are taken over by the next claim of their key, and
+// deleted by CollectExpired.
+type LeaseStore struct {
+	Client    coordinationclientv1.LeasesGetter
+	Namespace string
+
+	now func() time.Time
+}
+
+// NewLeaseStore returns a LeaseStore that creates Leases in namespace.
+func NewLeaseStore(client coordinationclientv1.LeasesGetter, namespace string) *LeaseStore {
+	return &LeaseStore{
+		Client:    client,
+		Namespace: namespace,
+		now:       time.Now,
+	}
+}
+
+// Claim implements Store.
+func (s *LeaseStore) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	sum := sha256.Sum256([]byte(key))
+	name := "dedup-" + hex.EncodeToString(sum[:])
+	now := metav1.NewMicroTime(s.now())
+	seconds := int32(math.Ceil(ttl.Seconds()))
+	spec := coordinationv1.LeaseSpec{
+		HolderIdentity:       &key,
+		LeaseDurationSeconds: &seconds,
+		AcquireTime:          &now,
+		RenewTime:            &now,
+	}
+
+	leases := s.Client.Leases(s.Namespace)
+	_, err := leases.Create(ctx, &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{LeaseLabel: "true"},
+		},
+		Spec: spec,
+	}, metav1.CreateOptions{})
+	if err == nil {
+		return true, nil
+	}
+	if !kerrors.IsAlreadyExists(err) {
+		return false, fmt.Errorf("failed to create lease %s: %w", name, err)
+	}
+
+	lease, err := leases.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to get lease %s: %w", name, err)
+	}
+	if !expired(lease, now.Time) {
+		return false, nil
+	}
+	// The update is made at the resourceVersion that was read, so only one
+	// of several concurrent claims of an expired key succeeds.
+	lease.Spec = spec
+	if _, err := leases.Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		if kerrors.IsConflict(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to update lease %s: %w", name, err)
+	}
+	return true, nil
+}
+
+func expired(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	return !now.Before(expiry)
+}
+
+// CollectExpired deletes the expired Leases of the store every interval until
+// ctx is done, so that the Leases of keys that are never claimed again don't
+// pile up. The replicas sharing the store may collect concurrently.
+func (s *LeaseStore) CollectExpired(ctx context.Context, interval time.Duration, logger *zap.SugaredLogger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.deleteExpired(ctx); err != nil {
+				logger.Errorf("failed to delete the expired dedup leases: %v", err)
+			}
+		}
+	}
+}
+
+// deleteExpired deletes the Leases of the store that are expired.
+func (s *LeaseStore) deleteExpired(ctx context.Context) error {
+	leases := s.Client.Leases(s.Namespace)
+	list, err := leases.List(ctx, metav1.ListOptions{LabelSelector: LeaseLabel})
+	if err != nil {
+		return fmt.Errorf("failed to list leases: %w", err)
+	}
+	now := s.now()
+	for i := range list.Items {
+		lease := &list.Items[i]
+		if !expired(lease, now) {
+			continue
+		}
+		// The deletion is made at the resourceVersion that was listed, so a
+		// Lease claimed again in the meantime is kept.
+		err := leases.Delete(ctx, lease.Name, metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{ResourceVersion: &lease.ResourceVersion},
+		})
+		if err != nil && !kerrors.IsNotFound(err) && !kerrors.IsConflict(err) {
+			return fmt.Errorf("failed to delete lease %s: %w", lease.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dedup
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func TestLeaseStore_Claim(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset()
+	now := time.Unix(1650000000, 0)
+	s := NewLeaseStore(clientset.CoordinationV1(), "tekton-pipelines")
+	s.now = func() time.Time { return now }
+
+	claim := func(key string, want bool) {
+		t.Helper()
+		got, err := s.Claim(ctx, key, time.Minute)
+		if err != nil {
+			t.Fatalf("Claim(%s) unexpected error: %v", key, err)
+		}
+		if got != want {
+			t.Fatalf("Claim(%s) = %t, want %t", key, got, want)
+		}
+	}
+
+	claim("a", true)
+	claim("a", false)
+	claim("b", true)
+
+	now = now.Add(59 * time.Second)
+	claim("a", false)
+
+	// Once the lease expires, the key can be claimed again.
+	now = now.Add(time.Second)
+	claim("a", true)
+	claim("a", false)
+
+	leases, err := clientset.CoordinationV1().Leases("tekton-pipelines").List(ctx, metav1.ListOptions{LabelSelector: LeaseLabel})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leases.Items) != 2 {
+		t.Errorf("got %d leases, want 2", len(leases.Items))
+	}
+	for _, l := range leases.Items {
+		if !strings.HasPrefix(l.Name, "dedup-") {
+			t.Errorf("unexpected lease name %s", l.Name)
+		}
+		if *l.Spec.LeaseDurationSeconds != 60 {
+			t.Errorf("lease %s has duration %d, want 60", l.Name, *l.Spec.LeaseDurationSeconds)
+		}
+	}
+}
+
+func TestLeaseStore_Claim_Error(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "leases", func(ktesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("apiserver is down")
+	})
+	s := NewLeaseStore(clientset.CoordinationV1(), "tekton-pipelines")
+	if _, err := s.Claim(context.Background(), "a", time.Minute); err == nil || !strings.Contains(err.Error(), "apiserver is down") {
+		t.Errorf("Claim() expected error, got %v", err)
+	}
+}
+
+func TestLeaseStore_DeleteExpired(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset()
+	now := time.Unix(1650000000, 0)
+	s := NewLeaseStore(clientset.CoordinationV1(), "tekton-pipelines")
+	s.now = func() time.Time { return now }
+
+	for _, key := range []string{"a", "b"} {
+		if _, err := s.Claim(ctx, key, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now = now.Add(30 * time.Second)
+	if _, err := s.Claim(ctx, "c", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	// The leases of a and b are expired, the one of c is not.
+	now = now.Add(30 * time.Second)
+	if err := s.deleteExpired(ctx); err != nil {
+		t.Fatalf("deleteExpired() unexpected error: %v", err)
+	}
+	leases, err := clientset.CoordinationV1().Leases("tekton-pipelines").List(ctx, metav1.ListOptions{LabelSelector: LeaseLabel})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leases.Items) != 1 || *leases.Items[0].Spec.HolderIdentity != "c" {
+		t.Errorf("got leases %v, want the lease of c", leases.Items)
+	}
+	if got, _ := s.Claim(ctx, "a", time.Minute); !got {
+		t.Error("Claim(a) = false after its lease was deleted, want true")
+	}
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v31/github"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// changedFilesExtension is the extension the changed files are added to, as a comma separated list.
+	changedFilesExtension = "changed_files"
+	// filesPerPage is the number of files requested per page, the maximum allowed by the GitHub API.
+	filesPerPage = 100
+	// nullSHA is the before or after commit of push events creating or deleting a branch.
+	nullSHA = "0000000000000000000000000000000000000000"
+	// redacted replaces the personal access token in error messages.
+	redacted = "[REDACTED]"
+)
+
+// changedFilesPayload is the part of pull_request and push event payloads needed to fetch the changed files.
+type changedFilesPayload struct {
+	Number     int    `json:"number"`
+	Before     string `json:"before"`
+	After      string `json:"after"`
+	Repository struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Removed  []string `json:"removed"`
+		Modified []string `json:"modified"`
+	} `json:"commits"`
+}
+
+// addChangedFiles returns the files changed by the event as the changed_files extension.
+// Events other than pull_request and push have no changed files.
+func (w *Interceptor) addChangedFiles(ctx context.Context, r *triggersv1.InterceptorRequest, headers http.Header, host string, p triggersv1.GithubAddChangedFiles) (map[string]interface{}, *triggersv1.InterceptorResponse) {
+	event := headers.Get("X-GitHub-Event")
+	if event != "pull_request" && event != "push" {
+		return nil, nil
+	}
+
+	var payload changedFilesPayload
+	if err := json.Unmarshal([]byte(r.Body), &payload); err != nil {
+		return nil, interceptors.Failf(codes.InvalidArgument, "failed to parse body as JSON: %v", err)
+	}
+	owner, repo := payload.Repository.Owner.Login, payload.Repository.Name
+	if owner == "" || repo == "" {
+		return nil, interceptors.Fail(codes.InvalidArgument, "no repository owner or name in the event")
+	}
+	if event == "pull_request" && payload.Number == 0 {
+		return nil, interceptors.Fail(codes.InvalidArgument, "no pull request number in the event")
+	}
+
+	token, failure := w.personalAccessToken(ctx, r, p.PersonalAccessToken)
+	if failure != nil {
+		return nil, failure
+	}
+	client, err := w.githubClient(host, token)
+	if err != nil {
+		return nil, interceptors.Failf(codes.InvalidArgument, "failed to create GitHub client: %v", err)
+	}
+
+	var files []string
+	if event == "pull_request" {
+		files, err = pullRequestFiles(ctx, client, owner, repo, payload.Number)
+	} else {
+		files, err = pushFiles(ctx, client, owner, repo, payload)
+	}
+	if err != nil {
+		return nil, apiFailure(err, token, "changed files")
+	}
+	return map[string]interface{}{
+		changedFilesExtension: strings.Join(files, ","),
+	}, nil
+}
+
+// personalAccessToken returns the token read from the secret ref, or an empty token if ref is nil.
+func (w *Interceptor) personalAccessToken(ctx context.Context, r *triggersv1.InterceptorRequest, ref *triggersv1.SecretRef) (string, *triggersv1.InterceptorResponse) {
+	if ref == nil {
+		return "", nil
+	}
+	if ref.SecretKey == "" {
+		return "", interceptors.Fail(codes.FailedPrecondition, "github interceptor personalAccessToken.secretKey is empty")
+	}
+	if r.Context == nil {
+		return "", interceptors.Failf(codes.InvalidArgument, "no request context passed")
+	}
+	ns, _ := triggersv1.ParseTriggerID(r.Context.TriggerID)
+	secret, err := w.SecretGetter.Get(ctx, ns, ref)
+	if err != nil {
+		return "", interceptors.Failf(codes.FailedPrecondition, "error getting secret: %v", err)
+	}
+	return strings.TrimSpace(string(secret)), nil
+}
+
+// enterpriseHost returns the GitHub Enterprise host whose API is called for the event: the configured
+// host, or none for the public GitHub API. The X-GitHub-Enterprise-Host header isn't covered by the
+// signature of the event, so it is only checked against the configured host: using it would let any
+// sender have the personal access token sent to its own server, and the responses of the API forged.
+func enterpriseHost(headers http.Header, configured string) (string, *triggersv1.InterceptorResponse) {
+	if configured != "" {
+		if u, err := url.Parse("https://" + configured); err != nil || u.Host != configured {
+			return "", interceptors.Failf(codes.InvalidArgument, "invalid enterpriseHost %q: must be a host name", configured)
+		}
+	}
+	sent := headers.Get("X-GitHub-Enterprise-Host")
+	switch {
+	case sent == "":
+	case configured == "":
+		return "", interceptors.Failf(codes.FailedPrecondition, "event sent by GitHub Enterprise host %s, but no enterpriseHost is configured", sent)
+	case !strings.EqualFold(sent, configured):
+		return "", interceptors.Failf(codes.FailedPrecondition, "event sent by GitHub Enterprise host %s, not the configured enterpriseHost %s", sent, configured)
+	}
+	return configured, nil
+}
+
+// githubClient returns a client for the GitHub API, or for the API of the GitHub Enterprise host if set.
+// Requests are authenticated with token if it is not empty.
+func (w *Interceptor) githubClient(enterpriseHost, token string) (*gh.Client, error) {
+	httpClient := w.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if token != "" {
+		transport := httpClient.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		authenticated := *httpClient
+		authenticated.Transport = &tokenTransport{token: token, base: transport}
+		httpClient = &authenticated
+	}
+
+	baseURL := w.APIURL
+	if baseURL == "" && enterpriseHost != "" {
+		baseURL = (&url.URL{Scheme: "https", Host: enterpriseHost, Path: "/api/v3/"}).String()
+	}
+	if baseURL == "" {
+		return gh.NewClient(httpClient), nil
+	}
+	return gh.NewEnterpriseClient(baseURL, baseURL, httpClient)
+}
+
+// pullRequestFiles lists the files changed by a pull request, following the pages of the API.
+func pullRequestFiles(ctx context.Context, client *gh.Client, owner, repo string, number int) ([]string, error) {
+	var files []string
+	opts := &gh.ListOptions{PerPage: filesPerPage}
+	for {
+		page, resp, err := client.PullRequests.ListFiles(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range page {
+			files = append(files, f.GetFilename())
+		}
+		if resp.NextPage == 0 {
+			return files, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// pushFiles lists the files changed between the before and after commits of a push, following the
+// pages of the API. Pushes creating a branch have no before commit to compare to, so the files listed
+// in the commits of the event are used instead. Pushes deleting a branch change no files.
+func pushFiles(ctx context.Context, client *gh.Client, owner, repo string, payload changedFilesPayload) ([]string, error) {
+	switch {
+	case payload.After == "" || payload.After == nullSHA:
+		return nil, nil
+	case payload.Before == "" || payload.Before == nullSHA:
+		return commitFiles(payload), nil
+	}
+
+	var files []string
+	page := 1
+	for {
+		u := fmt.Sprintf("repos/%v/%v/compare/%v...%v?per_page=%d&page=%d",
+			url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(payload.Before), url.PathEscape(payload.After), filesPerPage, page)
+		req, err := client.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		comparison := new(gh.CommitsComparison)
+		resp, err := client.Do(ctx, req, comparison)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range comparison.Files {
+			files = append(files, f.GetFilename())
+		}
+		if resp.NextPage == 0 {
+			return files, nil
+		}
+		page = resp.NextPage
+	}
+}
+
+// commitFiles returns the files added, removed or modified by the commits of a push event.
+func commitFiles(payload changedFilesPayload) []string {
+	seen := map[string]bool{}
+	var files []string
+	for _, c := range payload.Commits {
+		for _, list := range [][]string{c.Added, c.Removed, c.Modified} {
+			for _, f := range list {
+				if !seen[f] {
+					seen[f] = true
+					files = append(files, f)
+				}
+			}
+		}
+	}
+	return files
+}
+
+// apiFailure returns the response for an error getting what from the GitHub API, without the token.
+func apiFailure(err error, token, what string) *triggersv1.InterceptorResponse {
+	msg := err.Error()
+	if token != "" {
+		msg = strings.ReplaceAll(msg, token, redacted)
+	}
+
+	var rateLimitErr *gh.RateLimitError
+	var abuseErr *gh.AbuseRateLimitError
+	switch {
+	case errors.As(err, &rateLimitErr):
+		return interceptors.Failf(codes.ResourceExhausted, "GitHub API rate limit exceeded, resets at %s: %s", rateLimitErr.Rate.Reset.Format(time.RFC3339), msg)
+	case errors.Is(err, context.DeadlineExceeded):
+		return interceptors.Failf(codes.DeadlineExceeded, "timed out getting %s from the GitHub API: %s", what, msg)
+	case errors.As(err, &abuseErr):
+		if abuseErr.RetryAfter != nil {
+			return interceptors.Failf(codes.ResourceExhausted, "GitHub API secondary rate limit exceeded, retry after %s: %s", abuseErr.RetryAfter, msg)
+		}
+		return interceptors.Failf(codes.ResourceExhausted, "GitHub API secondary rate limit exceeded: %s", msg)
+	}
+	return interceptors.Failf(codes.Unavailable, "failed to get %s from the GitHub API: %s", what, msg)
+}
+
+// tokenTransport authenticates requests with a personal access token.
+type tokenTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	authenticated := req.Clone(req.Context())
+	authenticated.Header.Set("Authorization", "token "+t.token)
+	return t.base.RoundTrip(authenticated)
+}
@@ -0,0 +1,407 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/test"
+	"google.golang.org/grpc/codes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+)
+
+const (
+	pullRequestBody = `{"number": 7, "repository": {"name": "triggers", "owner": {"login": "tektoncd"}}}`
+	pushBody        = `{"before": "1111111111111111111111111111111111111111", "after": "2222222222222222222222222222222222222222", "repository": {"name": "triggers", "owner": {"login": "tektoncd"}}}`
+	newBranchBody   = `{"before": "0000000000000000000000000000000000000000", "after": "2222222222222222222222222222222222222222", "repository": {"name": "triggers", "owner": {"login": "tektoncd"}},
+		"commits": [{"added": ["docs/new.md"], "modified": ["README.md"]}, {"removed": ["old.go"], "modified": ["README.md"]}]}`
+	deletedBranchBody = `{"before": "2222222222222222222222222222222222222222", "after": "0000000000000000000000000000000000000000", "repository": {"name": "triggers", "owner": {"login": "tektoncd"}}}`
+
+	token = "ghp_abcdefghijklmnop"
+)
+
+var tokenSecret = &corev1.Secret{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "github-token",
+		Namespace: metav1.NamespaceDefault,
+	},
+	Data: map[string][]byte{
+		"token": []byte(token + "\n"),
+	},
+}
+
+// fakeGitHubAPI serves the files of pull request 7 and of the comparison of the commits of pushBody,
+// two files per page. It records the Authorization header of the requests.
+func fakeGitHubAPI(t *testing.T, files []string, authorization *string) *httptest.Server {
+	t.Helper()
+	page := func(w http.ResponseWriter, r *http.Request) string {
+		*authorization = r.Header.Get("Authorization")
+		n, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if n == 0 {
+			n = 1
+		}
+		start, end := (n-1)*2, n*2
+		if end >= len(files) {
+			end = len(files)
+		} else {
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=%d>; rel="next"`, r.URL.Path, n+1))
+		}
+		var entries []string
+		for _, f := range files[start:end] {
+			entries = append(entries, fmt.Sprintf(`{"filename": %q}`, f))
+		}
+		return "[" + strings.Join(entries, ",") + "]"
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/tektoncd/triggers/pulls/7/files", func(w http.ResponseWriter, r *http.Request) {
+		body := page(w, r)
+		fmt.Fprint(w, body)
+	})
+	mux.HandleFunc("/api/v3/repos/tektoncd/triggers/compare/1111111111111111111111111111111111111111...2222222222222222222222222222222222222222", func(w http.ResponseWriter, r *http.Request) {
+		body := page(w, r)
+		fmt.Fprintf(w, `{"files": %s}`, body)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func changedFilesRequest(event, body string, params triggersv1.GithubAddChangedFiles) *triggersv1.InterceptorRequest {
+	return &triggersv1.InterceptorRequest{
+		Body: body,
+		Header: http.Header{
+			"Content-Type":   []string{"application/json"},
+			"X-GitHub-Event": []string{event},
+		},
+		InterceptorParams: map[string]interface{}{
+			"addChangedFiles": params,
+		},
+		Context: &triggersv1.TriggerContext{
+			EventURL:  "https://testing.example.com",
+			EventID:   "abcde",
+			TriggerID: "namespaces/default/triggers/example-trigger",
+		},
+	}
+}
+
+func TestInterceptor_Process_AddChangedFiles(t *testing.T) {
+	files := []string{"README.md", "docs/interceptors.md", "pkg/interceptors/github/github.go"}
+	withToken := triggersv1.GithubAddChangedFiles{
+		Enabled:             true,
+		PersonalAccessToken: &triggersv1.SecretRef{SecretName: "github-token", SecretKey: "token"},
+	}
+
+	for _, tc := range []struct {
+		name              string
+		event             string
+		body              string
+		params            triggersv1.GithubAddChangedFiles
+		want              map[string]interface{}
+		wantAuthorization string
+	}{{
+		name:              "pull request",
+		event:             "pull_request",
+		body:              pullRequestBody,
+		params:            withToken,
+		want:              map[string]interface{}{"changed_files": strings.Join(files, ",")},
+		wantAuthorization: "token " + token,
+	}, {
+		name:   "pull request without token",
+		event:  "pull_request",
+		body:   pullRequestBody,
+		params: triggersv1.GithubAddChangedFiles{Enabled: true},
+		want:   map[string]interface{}{"changed_files": strings.Join(files, ",")},
+	}, {
+		name:              "push",
+		event:             "push",
+		body:              pushBody,
+		params:            withToken,
+		want:              map[string]interface{}{"changed_files": strings.Join(files, ",")},
+		wantAuthorization: "token " + token,
+	}, {
+		name:   "push creating a branch",
+		event:  "push",
+		body:   newBranchBody,
+		params: withToken,
+		want:   map[string]interface{}{"changed_files": "docs/new.md,README.md,old.go"},
+	}, {
+		name:   "push deleting a branch",
+		event:  "push",
+		body:   deletedBranchBody,
+		params: withToken,
+		want:   map[string]interface{}{"changed_files": ""},
+	}, {
+		name:   "other event",
+		event:  "issues",
+		body:   `{}`,
+		params: withToken,
+	}, {
+		name:   "disabled",
+		event:  "pull_request",
+		body:   pullRequestBody,
+		params: triggersv1.GithubAddChangedFiles{},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var authorization string
+			srv := fakeGitHubAPI(t, files, &authorization)
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, tokenSecret)
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+			w.APIURL = srv.URL + "/api/v3/"
+
+			res := w.Process(ctx, changedFilesRequest(tc.event, tc.body, tc.params))
+			if !res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be true but got %t. \nStatus.Err(): %v", res.Continue, res.Status.Err())
+			}
+			if len(res.Extensions) != len(tc.want) || fmt.Sprint(res.Extensions) != fmt.Sprint(tc.want) {
+				t.Errorf("Interceptor.Process() got extensions %v, want %v", res.Extensions, tc.want)
+			}
+			if authorization != tc.wantAuthorization {
+				t.Errorf("GitHub API got Authorization %q, want %q", authorization, tc.wantAuthorization)
+			}
+		})
+	}
+}
+
+func TestInterceptor_Process_AddChangedFiles_EnterpriseHost(t *testing.T) {
+	var authorization string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if r.URL.Path != "/api/v3/repos/tektoncd/triggers/pulls/7/files" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `[{"filename": "README.md"}]`)
+	}))
+	defer srv.Close()
+
+	ctx, _ := test.SetupFakeContext(t)
+	ctx, clientset := fakekubeclient.With(ctx, tokenSecret)
+	w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+	w.HTTPClient = srv.Client()
+
+	req := changedFilesRequest("pull_request", pullRequestBody, triggersv1.GithubAddChangedFiles{
+		Enabled:             true,
+		PersonalAccessToken: &triggersv1.SecretRef{SecretName: "github-token", SecretKey: "token"},
+	})
+	host := strings.TrimPrefix(srv.URL, "https://")
+	req.Header["X-GitHub-Enterprise-Host"] = []string{host}
+	req.InterceptorParams["enterpriseHost"] = host
+	res := w.Process(ctx, req)
+	if !res.Continue {
+		t.Fatalf("Interceptor.Process() expected res.Continue to be true but got %t. \nStatus.Err(): %v", res.Continue, res.Status.Err())
+	}
+	if got := res.Extensions["changed_files"]; got != "README.md" {
+		t.Errorf("Interceptor.Process() got changed_files %v, want README.md", got)
+	}
+	if authorization != "token "+token {
+		t.Errorf("GitHub API got Authorization %q, want %q", authorization, "token "+token)
+	}
+}
+
+func TestInterceptor_Process_EnterpriseHost_ShouldNotContinue(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		sent     string
+		host     string
+		wantCode codes.Code
+		wantMsg  string
+	}{{
+		name:     "no configured host",
+		sent:     "attacker.example.com",
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "event sent by GitHub Enterprise host attacker.example.com, but no enterpriseHost is configured",
+	}, {
+		name:     "other host",
+		sent:     "attacker.example.com",
+		host:     "github.example.com",
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "event sent by GitHub Enterprise host attacker.example.com, not the configured enterpriseHost github.example.com",
+	}, {
+		name:     "invalid host",
+		host:     "https://github.example.com/api/v3",
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `invalid enterpriseHost "https://github.example.com/api/v3": must be a host name`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, tokenSecret)
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+			w.HTTPClient = &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				t.Errorf("unexpected GitHub API request %s", r.URL)
+				return nil, fmt.Errorf("unexpected request")
+			})}
+
+			req := changedFilesRequest("pull_request", pullRequestBody, triggersv1.GithubAddChangedFiles{
+				Enabled:             true,
+				PersonalAccessToken: &triggersv1.SecretRef{SecretName: "github-token", SecretKey: "token"},
+			})
+			if tc.sent != "" {
+				req.Header["X-GitHub-Enterprise-Host"] = []string{tc.sent}
+			}
+			if tc.host != "" {
+				req.InterceptorParams["enterpriseHost"] = tc.host
+			}
+			res := w.Process(ctx, req)
+			if res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be false but got %t", res.Continue)
+			}
+			if res.Status.Code != tc.wantCode {
+				t.Errorf("Interceptor.Process() got status code %v, want %v", res.Status.Code, tc.wantCode)
+			}
+			if res.Status.Message != tc.wantMsg {
+				t.Errorf("Interceptor.Process() got message %q, want %q", res.Status.Message, tc.wantMsg)
+			}
+		})
+	}
+}
+
+// roundTripperFunc is an http.RoundTripper calling the function.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestInterceptor_Process_AddChangedFiles_ShouldNotContinue(t *testing.T) {
+	reset := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	withToken := triggersv1.GithubAddChangedFiles{
+		Enabled:             true,
+		PersonalAccessToken: &triggersv1.SecretRef{SecretName: "github-token", SecretKey: "token"},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		body     string
+		params   triggersv1.GithubAddChangedFiles
+		handler  http.HandlerFunc
+		wantCode codes.Code
+		wantMsg  string
+	}{{
+		name:   "rate limited",
+		body:   pullRequestBody,
+		params: withToken,
+		handler: func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-RateLimit-Limit", "5000")
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "API rate limit exceeded"}`)
+		},
+		wantCode: codes.ResourceExhausted,
+		wantMsg:  "GitHub API rate limit exceeded, resets at 2022-06-01T12:00:00Z",
+	}, {
+		name:   "secondary rate limited",
+		body:   pullRequestBody,
+		params: withToken,
+		handler: func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "You have triggered an abuse detection mechanism", "documentation_url": "https://developer.github.com/v3/#abuse-rate-limits"}`)
+		},
+		wantCode: codes.ResourceExhausted,
+		wantMsg:  "GitHub API secondary rate limit exceeded, retry after 30s",
+	}, {
+		name:   "token redacted",
+		body:   pullRequestBody,
+		params: withToken,
+		handler: func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintf(w, `{"message": "Bad credentials: %s"}`, r.Header.Get("Authorization"))
+		},
+		wantCode: codes.Unavailable,
+		wantMsg:  "Bad credentials: token [REDACTED]",
+	}, {
+		name:   "not found",
+		body:   pullRequestBody,
+		params: triggersv1.GithubAddChangedFiles{Enabled: true},
+		handler: func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		},
+		wantCode: codes.Unavailable,
+		wantMsg:  "failed to get changed files from the GitHub API",
+	}, {
+		name:     "no repository",
+		body:     `{"number": 7}`,
+		params:   withToken,
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "no repository owner or name in the event",
+	}, {
+		name:     "no pull request number",
+		body:     `{"repository": {"name": "triggers", "owner": {"login": "tektoncd"}}}`,
+		params:   withToken,
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "no pull request number in the event",
+	}, {
+		name: "empty token secret key",
+		body: pullRequestBody,
+		params: triggersv1.GithubAddChangedFiles{
+			Enabled:             true,
+			PersonalAccessToken: &triggersv1.SecretRef{SecretName: "github-token"},
+		},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "github interceptor personalAccessToken.secretKey is empty",
+	}, {
+		name: "missing token secret",
+		body: pullRequestBody,
+		params: triggersv1.GithubAddChangedFiles{
+			Enabled:             true,
+			PersonalAccessToken: &triggersv1.SecretRef{SecretName: "missing", SecretKey: "token"},
+		},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "error getting secret",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := tc.handler
+			if handler == nil {
+				handler = func(w http.ResponseWriter, r *http.Request) {
+					t.Errorf("unexpected GitHub API request %s", r.URL)
+				}
+			}
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, tokenSecret)
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+			w.APIURL = srv.URL + "/api/v3/"
+
+			res := w.Process(ctx, changedFilesRequest("pull_request", tc.body, tc.params))
+			if res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be false but got %t", res.Continue)
+			}
+			if res.Status.Code != tc.wantCode {
+				t.Errorf("Interceptor.Process() got status code %v, want %v", res.Status.Code, tc.wantCode)
+			}
+			if !strings.Contains(res.Status.Message, tc.wantMsg) {
+				t.Errorf("Interceptor.Process() got message %q, want it to contain %q", res.Status.Message, tc.wantMsg)
+			}
+			if strings.Contains(res.Status.Message, token) {
+				t.Errorf("Interceptor.Process() message %q contains the token", res.Status.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	gh "github.com/google/go-github/v31/github"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// commitsExtension is the extension the commits of the event are added to, oldest first.
+	commitsExtension = "commits"
+	// commitsTruncatedExtension is true if the event has more commits than the commits extension.
+	commitsTruncatedExtension = "commits_truncated"
+	// commitsPerPage is the number of commits requested per page, the maximum allowed by the GitHub API.
+	commitsPerPage = 100
+	// defaultMaxCommits bounds the number of commits listed if no maximum is configured.
+	defaultMaxCommits = 250
+	// defaultMaxRateLimitWait is the longest wait for the rate limit to reset if none is configured.
+	defaultMaxRateLimitWait = 10 * time.Second
+	// maxRateLimitRetries is the number of times a page is retried once the rate limit resets.
+	maxRateLimitRetries = 3
+	// maxPayloadCommits is the most commits GitHub lists in push events. Pushes with more commits only
+	// list the first ones.
+	maxPayloadCommits = 20
+	// secondaryRateLimitBackoff is the first wait before retrying a page rejected by the secondary rate
+	// limit without a Retry-After header. It doubles with each retry.
+	secondaryRateLimitBackoff = time.Second
+)
+
+// commitRangePayload is the part of pull_request and push event payloads needed to list their commits.
+type commitRangePayload struct {
+	Number     int    `json:"number"`
+	Before     string `json:"before"`
+	After      string `json:"after"`
+	Repository struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+	Commits []struct {
+		ID        string        `json:"id"`
+		Message   string        `json:"message"`
+		Timestamp string        `json:"timestamp"`
+		URL       string        `json:"url"`
+		Author    payloadAuthor `json:"author"`
+		Committer payloadAuthor `json:"committer"`
+	} `json:"commits"`
+}
+
+// payloadAuthor is an author or committer of a commit in a push event payload.
+type payloadAuthor struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+}
+
+// expandCommits returns the commits of the event, oldest first, as the commits extension, and whether
+// the event has more than the maximum number of commits as the commits_truncated extension. Events other
+// than pull_request and push have no commits.
+func (w *Interceptor) expandCommits(ctx context.Context, r *triggersv1.InterceptorRequest, headers http.Header, host string, p triggersv1.GithubExpandCommits) (map[string]interface{}, *triggersv1.InterceptorResponse) {
+	maxCommits := defaultMaxCommits
+	if p.MaxCommits < 0 {
+		return nil, interceptors.Failf(codes.InvalidArgument, "invalid expandCommits.maxCommits %d: must be positive", p.MaxCommits)
+	} else if p.MaxCommits > 0 {
+		maxCommits = p.MaxCommits
+	}
+	maxWait := defaultMaxRateLimitWait
+	if p.MaxRateLimitWait != "" {
+		d, err := time.ParseDuration(p.MaxRateLimitWait)
+		if err != nil || d < 0 {
+			return nil, interceptors.Failf(codes.InvalidArgument, "invalid expandCommits.maxRateLimitWait %q: must be a duration", p.MaxRateLimitWait)
+		}
+		maxWait = d
+	}
+
+	event := headers.Get("X-GitHub-Event")
+	if event != "pull_request" && event != "push" {
+		return nil, nil
+	}
+
+	var payload commitRangePayload
+	if err := json.Unmarshal([]byte(r.Body), &payload); err != nil {
+		return nil, interceptors.Failf(codes.InvalidArgument, "failed to parse body as JSON: %v", err)
+	}
+	owner, repo := payload.Repository.Owner.Login, payload.Repository.Name
+	if owner == "" || repo == "" {
+		return nil, interceptors.Fail(codes.InvalidArgument, "no repository owner or name in the event")
+	}
+	if event == "pull_request" && payload.Number == 0 {
+		return nil, interceptors.Fail(codes.InvalidArgument, "no pull request number in the event")
+	}
+
+	var commits []interface{}
+	truncated := false
+	switch {
+	case event == "push" && (payload.After == "" || payload.After == nullSHA):
+		// Pushes deleting a branch have no commits.
+		commits = []interface{}{}
+	case event == "push" && (payload.Before == "" || payload.Before == nullSHA):
+		// Pushes creating a branch have no commit to compare to.
+		commits, truncated = payloadCommits(payload, maxCommits)
+	default:
+		token, failure := w.personalAccessToken(ctx, r, p.PersonalAccessToken)
+		if failure != nil {
+			return nil, failure
+		}
+		client, err := w.githubClient(host, token)
+		if err != nil {
+			return nil, interceptors.Failf(codes.InvalidArgument, "failed to create GitHub client: %v", err)
+		}
+		pages := commitPages{client: client, owner: owner, repo: repo, maxWait: maxWait}
+		if event == "pull_request" {
+			commits, truncated, err = pages.list(ctx, maxCommits, pages.pullRequest(payload.Number))
+		} else {
+			commits, truncated, err = pages.list(ctx, maxCommits, pages.comparison(payload.Before, payload.After))
+		}
+		if err != nil {
+			return nil, apiFailure(err, token, "commits")
+		}
+	}
+	return map[string]interface{}{
+		commitsExtension:          commits,
+		commitsTruncatedExtension: truncated,
+	}, nil
+}
+
+// commitPages lists the commits of a repository page by page.
+type commitPages struct {
+	client      *gh.Client
+	owner, repo string
+	// maxWait is the longest wait for the rate limit to reset before retrying a page.
+	maxWait time.Duration
+}
+
+// commitPage gets a page of commits, starting at 1.
+type commitPage func(ctx context.Context, page int) ([]*gh.RepositoryCommit, *gh.Response, error)
+
+// pullRequest returns the pages of the commits of a pull request.
+func (c commitPages) pullRequest(number int) commitPage {
+	return func(ctx context.Context, page int) ([]*gh.RepositoryCommit, *gh.Response, error) {
+		return c.client.PullRequests.ListCommits(ctx, c.owner, c.repo, number, &gh.ListOptions{Page: page, PerPage: commitsPerPage})
+	}
+}
+
+// comparison returns the pages of the commits between the before and after commits of a push.
+func (c commitPages) comparison(before, after string) commitPage {
+	return func(ctx context.Context, page int) ([]*gh.RepositoryCommit, *gh.Response, error) {
+		u := fmt.Sprintf("repos/%v/%v/compare/%v...%v?per_page=%d&page=%d",
+			url.PathEscape(c.owner), url.PathEscape(c.repo), url.PathEscape(before), url.PathEscape(after), commitsPerPage, page)
+		req, err := c.client.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		comparison := new(gh.CommitsComparison)
+		resp, err := c.client.Do(ctx, req, comparison)
+		if err != nil {
+			return nil, resp, err
+		}
+		return comparison.Commits, resp, nil
+	}
+}
+
+// list follows the pages of commits until maxCommits are listed, and returns whether there were more.
+func (c commitPages) list(ctx context.Context, maxCommits int, get commitPage) ([]interface{}, bool, error) {
+	commits := []interface{}{}
+	page := 1
+	for {
+		var list []*gh.RepositoryCommit
+		resp, err := c.withRateLimitBackoff(ctx, func() (*gh.Response, error) {
+			var resp *gh.Response
+			var err error
+			list, resp, err = get(ctx, page)
+			return resp, err
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		for i, commit := range list {
+			commits = append(commits, map[string]interface{}{
+				"sha":       commit.GetSHA(),
+				"message":   commit.GetCommit().GetMessage(),
+				"author":    commitAuthor(commit.GetCommit().GetAuthor(), commit.GetAuthor()),
+				"committer": commitAuthor(commit.GetCommit().GetCommitter(), commit.GetCommitter()),
+				"url":       commit.GetHTMLURL(),
+			})
+			if len(commits) == maxCommits {
+				return commits, i < len(list)-1 || resp.NextPage != 0, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return commits, false, nil
+		}
+		page = resp.NextPage
+	}
+}
+
+// withRateLimitBackoff calls do, and calls it again when the GitHub API rejects it because a rate limit
+// is exceeded, as long as the limit resets within maxWait and ctx isn't done, up to maxRateLimitRetries
+// times. It returns the error of the last call.
+func (c commitPages) withRateLimitBackoff(ctx context.Context, do func() (*gh.Response, error)) (*gh.Response, error) {
+	for retry := 0; ; retry++ {
+		resp, err := do()
+		wait, limited := rateLimitWait(err, retry)
+		if !limited || retry == maxRateLimitRetries || wait > c.maxWait {
+			return resp, err
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, err
+		case <-timer.C:
+		}
+	}
+}
+
+// rateLimitWait returns how long to wait before the retry of a request that failed with err, and
+// whether err is a rate limit error.
+func rateLimitWait(err error, retry int) (time.Duration, bool) {
+	var rateLimitErr *gh.RateLimitError
+	var abuseErr *gh.AbuseRateLimitError
+	switch {
+	case errors.As(err, &rateLimitErr):
+		wait := time.Until(rateLimitErr.Rate.Reset.Time)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	case errors.As(err, &abuseErr):
+		if abuseErr.RetryAfter != nil {
+			return *abuseErr.RetryAfter, true
+		}
+		return secondaryRateLimitBackoff << retry, true
+	}
+	return 0, false
+}
+
+// payloadCommits returns up to maxCommits of the commits listed in a push event, and whether there were
+// more. GitHub lists at most maxPayloadCommits commits in push events, so a push listing that many may
+// have had more.
+func payloadCommits(payload commitRangePayload, maxCommits int) ([]interface{}, bool) {
+	commits := []interface{}{}
+	for _, c := range payload.Commits {
+		if len(commits) == maxCommits {
+			return commits, true
+		}
+		date := c.Timestamp
+		if t, err := time.Parse(time.RFC3339, c.Timestamp); err == nil {
+			date = t.UTC().Format(time.RFC3339)
+		}
+		commits = append(commits, map[string]interface{}{
+			"sha":       c.ID,
+			"message":   c.Message,
+			"author":    map[string]interface{}{"name": c.Author.Name, "email": c.Author.Email, "login": c.Author.Username, "date": date},
+			"committer": map[string]interface{}{"name": c.Committer.Name, "email": c.Committer.Email, "login": c.Committer.Username, "date": date},
+			"url":       c.URL,
+		})
+	}
+	return commits, len(payload.Commits) >= maxPayloadCommits
+}
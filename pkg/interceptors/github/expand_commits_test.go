@@ -0,0 +1,308 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/test"
+	"google.golang.org/grpc/codes"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+)
+
+// fakeCommitPagesAPI serves the commits of pull request 7 and of the comparison of the commits of pushBody,
+// two commits per page. The first requests are rejected with the responses of limited, if any.
+func fakeCommitPagesAPI(t *testing.T, shas []string, limited ...http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	page := func(w http.ResponseWriter, r *http.Request) string {
+		n, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if n == 0 {
+			n = 1
+		}
+		start, end := (n-1)*2, n*2
+		if end >= len(shas) {
+			end = len(shas)
+		} else {
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=%d>; rel="next"`, r.URL.Path, n+1))
+		}
+		var entries []string
+		for _, sha := range shas[start:end] {
+			entries = append(entries, fmt.Sprintf(`{
+				"sha": %q,
+				"html_url": "https://github.com/tektoncd/triggers/commit/%s",
+				"commit": {"message": "Commit %s", "author": {"name": "Jane Doe", "email": "jane@example.com", "date": "2022-06-01T12:00:00Z"}},
+				"author": {"login": "janedoe"}
+			}`, sha, sha, sha))
+		}
+		return "[" + strings.Join(entries, ",") + "]"
+	}
+	serve := func(write func(w http.ResponseWriter, body string)) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if len(limited) > 0 {
+				handler := limited[0]
+				limited = limited[1:]
+				handler(w, r)
+				return
+			}
+			write(w, page(w, r))
+		}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/tektoncd/triggers/pulls/7/commits", serve(func(w http.ResponseWriter, body string) {
+		fmt.Fprint(w, body)
+	}))
+	mux.HandleFunc("/api/v3/repos/tektoncd/triggers/compare/1111111111111111111111111111111111111111...2222222222222222222222222222222222222222", serve(func(w http.ResponseWriter, body string) {
+		fmt.Fprintf(w, `{"commits": %s}`, body)
+	}))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// rateLimited rejects a request because the rate limit of the GitHub API is exceeded until reset.
+func rateLimited(reset time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "API rate limit exceeded"}`)
+	}
+}
+
+// secondaryRateLimited rejects a request because the secondary rate limit of the GitHub API is exceeded.
+func secondaryRateLimited(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Retry-After", "0")
+	w.WriteHeader(http.StatusForbidden)
+	fmt.Fprint(w, `{"message": "You have triggered an abuse detection mechanism", "documentation_url": "https://developer.github.com/v3/#abuse-rate-limits"}`)
+}
+
+func expandCommitsRequest(event, body string, params triggersv1.GithubExpandCommits) *triggersv1.InterceptorRequest {
+	return &triggersv1.InterceptorRequest{
+		Body: body,
+		Header: http.Header{
+			"Content-Type":   []string{"application/json"},
+			"X-GitHub-Event": []string{event},
+		},
+		InterceptorParams: map[string]interface{}{
+			"expandCommits": params,
+		},
+		Context: &triggersv1.TriggerContext{
+			EventURL:  "https://testing.example.com",
+			EventID:   "abcde",
+			TriggerID: "namespaces/default/triggers/example-trigger",
+		},
+	}
+}
+
+// listedCommits returns the expected commits extension for the commits served by fakeCommitPagesAPI.
+func listedCommits(shas ...string) []interface{} {
+	commits := []interface{}{}
+	for _, sha := range shas {
+		commits = append(commits, map[string]interface{}{
+			"sha":       sha,
+			"message":   "Commit " + sha,
+			"author":    map[string]interface{}{"name": "Jane Doe", "email": "jane@example.com", "login": "janedoe", "date": "2022-06-01T12:00:00Z"},
+			"committer": map[string]interface{}{"name": "", "email": "", "login": "", "date": ""},
+			"url":       "https://github.com/tektoncd/triggers/commit/" + sha,
+		})
+	}
+	return commits
+}
+
+func TestInterceptor_Process_ExpandCommits(t *testing.T) {
+	shas := []string{"a1", "b2", "c3", "d4", "e5"}
+	newBranchCommitsBody := `{"before": "0000000000000000000000000000000000000000", "after": "2222222222222222222222222222222222222222", "repository": {"name": "triggers", "owner": {"login": "tektoncd"}},
+		"commits": [
+			{"id": "a1", "message": "Commit a1", "timestamp": "2022-06-01T08:00:00-04:00", "url": "https://github.com/tektoncd/triggers/commit/a1",
+			 "author": {"name": "Jane Doe", "email": "jane@example.com", "username": "janedoe"}, "committer": {"name": "GitHub", "email": "noreply@github.com", "username": "web-flow"}},
+			{"id": "b2"}
+		]}`
+	var payloadCommits []string
+	cappedBranchCommits := []interface{}{}
+	for i := 0; i < maxPayloadCommits; i++ {
+		payloadCommits = append(payloadCommits, fmt.Sprintf(`{"id": "c%d"}`, i))
+		cappedBranchCommits = append(cappedBranchCommits, map[string]interface{}{
+			"sha":       fmt.Sprintf("c%d", i),
+			"message":   "",
+			"author":    map[string]interface{}{"name": "", "email": "", "login": "", "date": ""},
+			"committer": map[string]interface{}{"name": "", "email": "", "login": "", "date": ""},
+			"url":       "",
+		})
+	}
+	cappedBranchCommitsBody := `{"before": "0000000000000000000000000000000000000000", "after": "2222222222222222222222222222222222222222", "repository": {"name": "triggers", "owner": {"login": "tektoncd"}},
+		"commits": [` + strings.Join(payloadCommits, ",") + `]}`
+
+	for _, tc := range []struct {
+		name          string
+		event         string
+		body          string
+		params        triggersv1.GithubExpandCommits
+		limited       []http.HandlerFunc
+		want          map[string]interface{}
+		wantNoCommits bool
+	}{{
+		name:   "pull request",
+		event:  "pull_request",
+		body:   pullRequestBody,
+		params: triggersv1.GithubExpandCommits{Enabled: true},
+		want:   map[string]interface{}{"commits": listedCommits(shas...), "commits_truncated": false},
+	}, {
+		name:   "push with more commits than the maximum",
+		event:  "push",
+		body:   pushBody,
+		params: triggersv1.GithubExpandCommits{Enabled: true, MaxCommits: 3},
+		want:   map[string]interface{}{"commits": listedCommits("a1", "b2", "c3"), "commits_truncated": true},
+	}, {
+		name:   "maximum at the end of a page",
+		event:  "push",
+		body:   pushBody,
+		params: triggersv1.GithubExpandCommits{Enabled: true, MaxCommits: 4},
+		want:   map[string]interface{}{"commits": listedCommits("a1", "b2", "c3", "d4"), "commits_truncated": true},
+	}, {
+		name:    "retried once the rate limit resets",
+		event:   "pull_request",
+		body:    pullRequestBody,
+		params:  triggersv1.GithubExpandCommits{Enabled: true},
+		limited: []http.HandlerFunc{rateLimited(time.Now()), secondaryRateLimited},
+		want:    map[string]interface{}{"commits": listedCommits(shas...), "commits_truncated": false},
+	}, {
+		name:   "push creating a branch",
+		event:  "push",
+		body:   newBranchCommitsBody,
+		params: triggersv1.GithubExpandCommits{Enabled: true},
+		want: map[string]interface{}{
+			"commits": []interface{}{
+				map[string]interface{}{
+					"sha":       "a1",
+					"message":   "Commit a1",
+					"author":    map[string]interface{}{"name": "Jane Doe", "email": "jane@example.com", "login": "janedoe", "date": "2022-06-01T12:00:00Z"},
+					"committer": map[string]interface{}{"name": "GitHub", "email": "noreply@github.com", "login": "web-flow", "date": "2022-06-01T12:00:00Z"},
+					"url":       "https://github.com/tektoncd/triggers/commit/a1",
+				},
+				map[string]interface{}{
+					"sha":       "b2",
+					"message":   "",
+					"author":    map[string]interface{}{"name": "", "email": "", "login": "", "date": ""},
+					"committer": map[string]interface{}{"name": "", "email": "", "login": "", "date": ""},
+					"url":       "",
+				},
+			},
+			"commits_truncated": false,
+		},
+	}, {
+		name:   "push creating a branch with as many commits as GitHub lists",
+		event:  "push",
+		body:   cappedBranchCommitsBody,
+		params: triggersv1.GithubExpandCommits{Enabled: true},
+		want:   map[string]interface{}{"commits": cappedBranchCommits, "commits_truncated": true},
+	}, {
+		name:   "push deleting a branch",
+		event:  "push",
+		body:   deletedBranchBody,
+		params: triggersv1.GithubExpandCommits{Enabled: true},
+		want:   map[string]interface{}{"commits": []interface{}{}, "commits_truncated": false},
+	}, {
+		name:          "other event",
+		event:         "issues",
+		body:          `{}`,
+		params:        triggersv1.GithubExpandCommits{Enabled: true},
+		wantNoCommits: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := fakeCommitPagesAPI(t, shas, tc.limited...)
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, tokenSecret)
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+			w.APIURL = srv.URL + "/api/v3/"
+
+			res := w.Process(ctx, expandCommitsRequest(tc.event, tc.body, tc.params))
+			if !res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be true but got %t. \nStatus.Err(): %v", res.Continue, res.Status.Err())
+			}
+			if tc.wantNoCommits {
+				if len(res.Extensions) != 0 {
+					t.Errorf("Interceptor.Process() got extensions %v, want none", res.Extensions)
+				}
+				return
+			}
+			if diff := cmp.Diff(tc.want, res.Extensions); diff != "" {
+				t.Errorf("Interceptor.Process() got extensions -want +got: %s", diff)
+			}
+		})
+	}
+}
+
+func TestInterceptor_Process_ExpandCommits_ShouldNotContinue(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		params   triggersv1.GithubExpandCommits
+		limited  []http.HandlerFunc
+		wantCode codes.Code
+		wantMsg  string
+	}{{
+		name:     "rate limit resetting after the maximum wait",
+		params:   triggersv1.GithubExpandCommits{Enabled: true, MaxRateLimitWait: "1s"},
+		limited:  []http.HandlerFunc{rateLimited(time.Now().Add(time.Hour))},
+		wantCode: codes.ResourceExhausted,
+		wantMsg:  "GitHub API rate limit exceeded",
+	}, {
+		name:     "secondary rate limit exceeded after the retries",
+		params:   triggersv1.GithubExpandCommits{Enabled: true},
+		limited:  []http.HandlerFunc{secondaryRateLimited, secondaryRateLimited, secondaryRateLimited, secondaryRateLimited},
+		wantCode: codes.ResourceExhausted,
+		wantMsg:  "GitHub API secondary rate limit exceeded",
+	}, {
+		name:     "invalid maximum commits",
+		params:   triggersv1.GithubExpandCommits{Enabled: true, MaxCommits: -1},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "invalid expandCommits.maxCommits -1",
+	}, {
+		name:     "invalid maximum wait",
+		params:   triggersv1.GithubExpandCommits{Enabled: true, MaxRateLimitWait: "forever"},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `invalid expandCommits.maxRateLimitWait "forever"`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := fakeCommitPagesAPI(t, []string{"a1"}, tc.limited...)
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, tokenSecret)
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+			w.APIURL = srv.URL + "/api/v3/"
+
+			res := w.Process(ctx, expandCommitsRequest("pull_request", pullRequestBody, tc.params))
+			if res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be false but got %t", res.Continue)
+			}
+			if res.Status.Code != tc.wantCode {
+				t.Errorf("Interceptor.Process() got status code %v, want %v", res.Status.Code, tc.wantCode)
+			}
+			if !strings.Contains(res.Status.Message, tc.wantMsg) {
+				t.Errorf("Interceptor.Process() got message %q, want it to contain %q", res.Status.Message, tc.wantMsg)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ package github
 import (
 	"context"
 	"errors"
+	"net/http"
 
 	gh "github.com/google/go-github/v31/github"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
@@ -33,6 +34,11 @@ var ErrInvalidContentType = errors.New("form parameter encoding not supported, p
 
 type Interceptor struct {
 	SecretGetter interceptors.SecretGetter
+	// HTTPClient is the client used to call the GitHub API, http.DefaultClient if nil.
+	HTTPClient *http.Client
+	// APIURL is the base URL of the GitHub API. If empty, the API of the enterpriseHost param is
+	// used, or the public GitHub API.
+	APIURL string
 }
 
 func NewInterceptor(sg interceptors.SecretGetter) *Interceptor {
@@ -86,17 +92,57 @@ func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequ
 		}
 
 		ns, _ := triggersv1.ParseTriggerID(r.Context.TriggerID)
-		secretToken, err := w.SecretGetter.Get(ctx, ns, p.SecretRef)
+		secretTokens, err := interceptors.GetSecrets(ctx, w.SecretGetter, ns, p.SecretRef, p.AdditionalSecretRefs)
 		if err != nil {
 			return interceptors.Failf(codes.FailedPrecondition, "error getting secret: %v", err)
 		}
 
-		if err := gh.ValidateSignature(header, []byte(r.Body), secretToken); err != nil {
+		if err := interceptors.ValidateAny(secretTokens, func(secret []byte) error {
+			return gh.ValidateSignature(header, []byte(interceptors.SignedBody(r)), secret)
+		}); err != nil {
 			return interceptors.Fail(codes.FailedPrecondition, err.Error())
 		}
 	}
 
+	if !p.AddChangedFiles.Enabled && !p.VerifyCommits.Enabled && !p.ExpandCommits.Enabled {
+		return &triggersv1.InterceptorResponse{
+			Continue: true,
+		}
+	}
+	host, failure := enterpriseHost(headers, p.EnterpriseHost)
+	if failure != nil {
+		return failure
+	}
+	extensions := map[string]interface{}{}
+	if p.AddChangedFiles.Enabled {
+		files, failure := w.addChangedFiles(ctx, r, headers, host, p.AddChangedFiles)
+		if failure != nil {
+			return failure
+		}
+		for k, v := range files {
+			extensions[k] = v
+		}
+	}
+	if p.VerifyCommits.Enabled {
+		commit, failure := w.verifyCommit(ctx, r, headers, host, p.VerifyCommits)
+		if failure != nil {
+			return failure
+		}
+		for k, v := range commit {
+			extensions[k] = v
+		}
+	}
+	if p.ExpandCommits.Enabled {
+		commits, failure := w.expandCommits(ctx, r, headers, host, p.ExpandCommits)
+		if failure != nil {
+			return failure
+		}
+		for k, v := range commits {
+			extensions[k] = v
+		}
+	}
 	return &triggersv1.InterceptorResponse{
-		Continue: true,
+		Continue:   true,
+		Extensions: extensions,
 	}
 }
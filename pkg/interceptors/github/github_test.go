@@ -41,6 +41,7 @@ func TestInterceptor_ExecuteTrigger_Signature(t *testing.T) {
 		name              string
 		interceptorParams *triggersv1.GitHubInterceptor
 		payload           []byte
+		rawPayload        []byte
 		secret            *corev1.Secret
 		headers           map[string][]string
 		eventType         string
@@ -114,6 +115,48 @@ func TestInterceptor_ExecuteTrigger_Signature(t *testing.T) {
 		},
 		eventType: "MY_EVENT",
 		payload:   emptyJSONBody,
+	}, {
+		name: "valid header for additional secret",
+		interceptorParams: &triggersv1.GitHubInterceptor{
+			SecretRef: &triggersv1.SecretRef{
+				SecretName: "mysecret",
+				SecretKey:  "new-token",
+			},
+			AdditionalSecretRefs: []triggersv1.SecretRef{{
+				SecretName: "mysecret",
+				SecretKey:  "token",
+			}},
+		},
+		headers: emptyBodySha256Header,
+		secret: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "mysecret",
+			},
+			Data: map[string][]byte{
+				"new-token": []byte("new-secret"),
+				"token":     []byte(secretToken),
+			},
+		},
+		payload: emptyJSONBody,
+	}, {
+		name: "valid header for the raw body of a converted payload",
+		interceptorParams: &triggersv1.GitHubInterceptor{
+			SecretRef: &triggersv1.SecretRef{
+				SecretName: "mysecret",
+				SecretKey:  "token",
+			},
+		},
+		headers: map[string][]string{"X-Hub-Signature-256": {test.HMACHeader(t, secretToken, []byte(`payload=%7B%7D`), "sha256")}},
+		secret: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "mysecret",
+			},
+			Data: map[string][]byte{
+				"token": []byte(secretToken),
+			},
+		},
+		payload:    []byte(`{"payload":"{}"}`),
+		rawPayload: []byte(`payload=%7B%7D`),
 	}, {
 		name:              "nil body does not panic",
 		interceptorParams: &triggersv1.GitHubInterceptor{},
@@ -132,11 +175,13 @@ func TestInterceptor_ExecuteTrigger_Signature(t *testing.T) {
 			}
 
 			req := &triggersv1.InterceptorRequest{
-				Body:   string(tt.payload),
-				Header: headers,
+				Body:    string(tt.payload),
+				RawBody: string(tt.rawPayload),
+				Header:  headers,
 				InterceptorParams: map[string]interface{}{
-					"eventTypes": tt.interceptorParams.EventTypes,
-					"secretRef":  tt.interceptorParams.SecretRef,
+					"eventTypes":           tt.interceptorParams.EventTypes,
+					"secretRef":            tt.interceptorParams.SecretRef,
+					"additionalSecretRefs": tt.interceptorParams.AdditionalSecretRefs,
 				},
 				Context: &triggersv1.TriggerContext{
 					EventURL:  "https://testing.example.com",
@@ -260,6 +305,50 @@ func TestInterceptor_ExecuteTrigger_ShouldNotContinue(t *testing.T) {
 		},
 		eventType: "MY_EVENT",
 		payload:   emptyJSONBody,
+	}, {
+		name: "invalid header for all secrets",
+		interceptorParams: &triggersv1.GitHubInterceptor{
+			SecretRef: &triggersv1.SecretRef{
+				SecretName: "mysecret",
+				SecretKey:  "token",
+			},
+			AdditionalSecretRefs: []triggersv1.SecretRef{{
+				SecretName: "mysecret",
+				SecretKey:  "old-token",
+			}},
+		},
+		signature: emptyBodyHMACSignature,
+		secret: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "mysecret",
+			},
+			Data: map[string][]byte{
+				"token":     []byte("secrettoken"),
+				"old-token": []byte("oldsecrettoken"),
+			},
+		},
+		payload: emptyJSONBody,
+	}, {
+		name: "empty additional secret key",
+		interceptorParams: &triggersv1.GitHubInterceptor{
+			SecretRef: &triggersv1.SecretRef{
+				SecretName: "mysecret",
+				SecretKey:  "token",
+			},
+			AdditionalSecretRefs: []triggersv1.SecretRef{{
+				SecretName: "mysecret",
+			}},
+		},
+		signature: emptyBodyHMACSignature,
+		secret: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "mysecret",
+			},
+			Data: map[string][]byte{
+				"token": []byte(secretToken),
+			},
+		},
+		payload: emptyJSONBody,
 	}, {
 		name: "empty secret",
 		interceptorParams: &triggersv1.GitHubInterceptor{
@@ -288,8 +377,9 @@ func TestInterceptor_ExecuteTrigger_ShouldNotContinue(t *testing.T) {
 					"Content-Type": []string{"application/json"},
 				},
 				InterceptorParams: map[string]interface{}{
-					"eventTypes": tt.interceptorParams.EventTypes,
-					"secretRef":  tt.interceptorParams.SecretRef,
+					"eventTypes":           tt.interceptorParams.EventTypes,
+					"secretRef":            tt.interceptorParams.SecretRef,
+					"additionalSecretRefs": tt.interceptorParams.AdditionalSecretRefs,
 				},
 				Context: &triggersv1.TriggerContext{
 					EventURL:  "https://testing.example.com",
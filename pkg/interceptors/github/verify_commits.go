@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	gh "github.com/google/go-github/v31/github"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// commitExtension is the extension the head commit of the event is added to.
+	commitExtension = "commit"
+	// defaultVerifyCommitsTimeout bounds the call to the GitHub API if no timeout is configured.
+	defaultVerifyCommitsTimeout = 10 * time.Second
+)
+
+// headCommitPayload is the part of pull_request and push event payloads needed to fetch the head commit.
+type headCommitPayload struct {
+	After       string `json:"after"`
+	PullRequest struct {
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	} `json:"pull_request"`
+	Repository struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+}
+
+// verifyCommit returns the head commit of the event, with its authors and the verification of its
+// signature by GitHub, as the commit extension. Events other than pull_request and push, and pushes
+// deleting a branch, have no head commit.
+func (w *Interceptor) verifyCommit(ctx context.Context, r *triggersv1.InterceptorRequest, headers http.Header, host string, p triggersv1.GithubVerifyCommits) (map[string]interface{}, *triggersv1.InterceptorResponse) {
+	timeout := defaultVerifyCommitsTimeout
+	if p.Timeout != "" {
+		d, err := time.ParseDuration(p.Timeout)
+		if err != nil || d <= 0 {
+			return nil, interceptors.Failf(codes.InvalidArgument, "invalid verifyCommits.timeout %q: must be a positive duration", p.Timeout)
+		}
+		timeout = d
+	}
+
+	event := headers.Get("X-GitHub-Event")
+	if event != "pull_request" && event != "push" {
+		return nil, nil
+	}
+
+	var payload headCommitPayload
+	if err := json.Unmarshal([]byte(r.Body), &payload); err != nil {
+		return nil, interceptors.Failf(codes.InvalidArgument, "failed to parse body as JSON: %v", err)
+	}
+	owner, repo := payload.Repository.Owner.Login, payload.Repository.Name
+	if owner == "" || repo == "" {
+		return nil, interceptors.Fail(codes.InvalidArgument, "no repository owner or name in the event")
+	}
+	sha := payload.After
+	if event == "pull_request" {
+		sha = payload.PullRequest.Head.SHA
+		if sha == "" {
+			return nil, interceptors.Fail(codes.InvalidArgument, "no pull request head commit in the event")
+		}
+	}
+	if sha == "" || sha == nullSHA {
+		return nil, nil
+	}
+
+	token, failure := w.personalAccessToken(ctx, r, p.PersonalAccessToken)
+	if failure != nil {
+		return nil, failure
+	}
+	client, err := w.githubClient(host, token)
+	if err != nil {
+		return nil, interceptors.Failf(codes.InvalidArgument, "failed to create GitHub client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	commit, _, err := client.Repositories.GetCommit(ctx, owner, repo, sha)
+	if err != nil {
+		return nil, apiFailure(err, token, "commit "+sha)
+	}
+
+	verification := commit.GetCommit().GetVerification()
+	if p.RequireVerified && !verification.GetVerified() {
+		return nil, interceptors.Failf(codes.FailedPrecondition, "commit %s is not verified: %s", sha, verification.GetReason())
+	}
+	return map[string]interface{}{
+		commitExtension: map[string]interface{}{
+			"sha":                sha,
+			"author":             commitAuthor(commit.GetCommit().GetAuthor(), commit.GetAuthor()),
+			"committer":          commitAuthor(commit.GetCommit().GetCommitter(), commit.GetCommitter()),
+			"verified":           verification.GetVerified(),
+			"verificationReason": verification.GetReason(),
+		},
+	}, nil
+}
+
+// commitAuthor returns the git identity of an author or committer, with the login of the GitHub user
+// it is associated with if any.
+func commitAuthor(author *gh.CommitAuthor, user *gh.User) map[string]interface{} {
+	a := map[string]interface{}{
+		"name":  author.GetName(),
+		"email": author.GetEmail(),
+		"login": user.GetLogin(),
+		"date":  "",
+	}
+	if date := author.GetDate(); !date.IsZero() {
+		a["date"] = date.UTC().Format(time.RFC3339)
+	}
+	return a
+}
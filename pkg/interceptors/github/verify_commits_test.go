@@ -0,0 +1,323 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/test"
+	"google.golang.org/grpc/codes"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+)
+
+const (
+	verifiedSHA   = "2222222222222222222222222222222222222222"
+	unverifiedSHA = "3333333333333333333333333333333333333333"
+
+	headCommitBody = `{"number": 7, "pull_request": {"head": {"sha": "2222222222222222222222222222222222222222"}}, "repository": {"name": "triggers", "owner": {"login": "tektoncd"}}}`
+	unverifiedBody = `{"after": "3333333333333333333333333333333333333333", "repository": {"name": "triggers", "owner": {"login": "tektoncd"}}}`
+)
+
+// fakeCommitsAPI serves a commit with a verified signature and one without. It records the Authorization
+// header of the requests.
+func fakeCommitsAPI(t *testing.T, authorization *string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/tektoncd/triggers/commits/"+verifiedSHA, func(w http.ResponseWriter, r *http.Request) {
+		*authorization = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{
+			"sha": "2222222222222222222222222222222222222222",
+			"commit": {
+				"author": {"name": "Jane Doe", "email": "jane@example.com", "date": "2022-06-01T12:00:00Z"},
+				"committer": {"name": "GitHub", "email": "noreply@github.com", "date": "2022-06-01T12:30:00Z"},
+				"verification": {"verified": true, "reason": "valid"}
+			},
+			"author": {"login": "janedoe"},
+			"committer": {"login": "web-flow"}
+		}`)
+	})
+	mux.HandleFunc("/api/v3/repos/tektoncd/triggers/commits/"+unverifiedSHA, func(w http.ResponseWriter, r *http.Request) {
+		*authorization = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{
+			"sha": "3333333333333333333333333333333333333333",
+			"commit": {
+				"author": {"name": "Jane Doe", "email": "jane@example.com", "date": "2022-06-01T12:00:00Z"},
+				"committer": {"name": "Jane Doe", "email": "jane@example.com", "date": "2022-06-01T12:00:00Z"},
+				"verification": {"verified": false, "reason": "unsigned"}
+			}
+		}`)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func verifyCommitsRequest(event, body string, params triggersv1.GithubVerifyCommits) *triggersv1.InterceptorRequest {
+	return &triggersv1.InterceptorRequest{
+		Body: body,
+		Header: http.Header{
+			"Content-Type":   []string{"application/json"},
+			"X-GitHub-Event": []string{event},
+		},
+		InterceptorParams: map[string]interface{}{
+			"verifyCommits": params,
+		},
+		Context: &triggersv1.TriggerContext{
+			EventURL:  "https://testing.example.com",
+			EventID:   "abcde",
+			TriggerID: "namespaces/default/triggers/example-trigger",
+		},
+	}
+}
+
+func TestInterceptor_Process_VerifyCommits(t *testing.T) {
+	withToken := triggersv1.GithubVerifyCommits{
+		Enabled:             true,
+		PersonalAccessToken: &triggersv1.SecretRef{SecretName: "github-token", SecretKey: "token"},
+	}
+
+	for _, tc := range []struct {
+		name              string
+		event             string
+		body              string
+		params            triggersv1.GithubVerifyCommits
+		want              map[string]interface{}
+		wantAuthorization string
+	}{{
+		name:   "verified pull request",
+		event:  "pull_request",
+		body:   headCommitBody,
+		params: triggersv1.GithubVerifyCommits{Enabled: true, RequireVerified: true, Timeout: "5s"},
+		want: map[string]interface{}{
+			"commit": map[string]interface{}{
+				"sha":                verifiedSHA,
+				"author":             map[string]interface{}{"name": "Jane Doe", "email": "jane@example.com", "login": "janedoe", "date": "2022-06-01T12:00:00Z"},
+				"committer":          map[string]interface{}{"name": "GitHub", "email": "noreply@github.com", "login": "web-flow", "date": "2022-06-01T12:30:00Z"},
+				"verified":           true,
+				"verificationReason": "valid",
+			},
+		},
+	}, {
+		name:   "unverified push",
+		event:  "push",
+		body:   unverifiedBody,
+		params: withToken,
+		want: map[string]interface{}{
+			"commit": map[string]interface{}{
+				"sha":                unverifiedSHA,
+				"author":             map[string]interface{}{"name": "Jane Doe", "email": "jane@example.com", "login": "", "date": "2022-06-01T12:00:00Z"},
+				"committer":          map[string]interface{}{"name": "Jane Doe", "email": "jane@example.com", "login": "", "date": "2022-06-01T12:00:00Z"},
+				"verified":           false,
+				"verificationReason": "unsigned",
+			},
+		},
+		wantAuthorization: "token " + token,
+	}, {
+		name:   "push deleting a branch",
+		event:  "push",
+		body:   deletedBranchBody,
+		params: triggersv1.GithubVerifyCommits{Enabled: true, RequireVerified: true},
+	}, {
+		name:   "other event",
+		event:  "issues",
+		body:   `{}`,
+		params: withToken,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var authorization string
+			srv := fakeCommitsAPI(t, &authorization)
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, tokenSecret)
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+			w.APIURL = srv.URL + "/api/v3/"
+
+			res := w.Process(ctx, verifyCommitsRequest(tc.event, tc.body, tc.params))
+			if !res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be true but got %t. \nStatus.Err(): %v", res.Continue, res.Status.Err())
+			}
+			if diff := cmp.Diff(tc.want, res.Extensions, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Interceptor.Process() got extensions -want +got: %s", diff)
+			}
+			if authorization != tc.wantAuthorization {
+				t.Errorf("GitHub API got Authorization %q, want %q", authorization, tc.wantAuthorization)
+			}
+		})
+	}
+}
+
+func TestInterceptor_Process_VerifyCommitsAndAddChangedFiles(t *testing.T) {
+	var authorization string
+	srv := fakeCommitsAPI(t, &authorization)
+	ctx, _ := test.SetupFakeContext(t)
+	ctx, clientset := fakekubeclient.With(ctx, tokenSecret)
+	w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+	w.APIURL = srv.URL + "/api/v3/"
+
+	body := `{"before": "0000000000000000000000000000000000000000", "after": "2222222222222222222222222222222222222222", "repository": {"name": "triggers", "owner": {"login": "tektoncd"}},
+		"commits": [{"added": ["docs/new.md"]}]}`
+	req := verifyCommitsRequest("push", body, triggersv1.GithubVerifyCommits{Enabled: true})
+	req.InterceptorParams["addChangedFiles"] = triggersv1.GithubAddChangedFiles{Enabled: true}
+	res := w.Process(ctx, req)
+	if !res.Continue {
+		t.Fatalf("Interceptor.Process() expected res.Continue to be true but got %t. \nStatus.Err(): %v", res.Continue, res.Status.Err())
+	}
+	if got := res.Extensions["changed_files"]; got != "docs/new.md" {
+		t.Errorf("Interceptor.Process() got changed_files %v, want docs/new.md", got)
+	}
+	commit, _ := res.Extensions["commit"].(map[string]interface{})
+	if verified := commit["verified"]; verified != true {
+		t.Errorf("Interceptor.Process() got commit.verified %v, want true", verified)
+	}
+}
+
+func TestInterceptor_Process_VerifyCommits_ForgedEnterpriseHost(t *testing.T) {
+	forged := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to the forged GitHub Enterprise host %s", r.URL)
+		fmt.Fprint(w, `{"sha": "3333333333333333333333333333333333333333", "commit": {"verification": {"verified": true, "reason": "valid"}}}`)
+	}))
+	defer forged.Close()
+	var authorization string
+	srv := fakeCommitsAPI(t, &authorization)
+	ctx, _ := test.SetupFakeContext(t)
+	ctx, clientset := fakekubeclient.With(ctx, tokenSecret)
+	w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+	w.HTTPClient = forged.Client()
+
+	req := verifyCommitsRequest("push", unverifiedBody, triggersv1.GithubVerifyCommits{
+		Enabled:             true,
+		RequireVerified:     true,
+		PersonalAccessToken: &triggersv1.SecretRef{SecretName: "github-token", SecretKey: "token"},
+	})
+	req.Header["X-GitHub-Enterprise-Host"] = []string{strings.TrimPrefix(forged.URL, "https://")}
+	if res := w.Process(ctx, req); res.Continue || res.Status.Code != codes.FailedPrecondition {
+		t.Errorf("Interceptor.Process() got %v, want the event rejected with FailedPrecondition", res)
+	}
+
+	// With a configured host, the commit is verified by its API whatever the header.
+	w.APIURL = srv.URL + "/api/v3/"
+	w.HTTPClient = nil
+	req.InterceptorParams["enterpriseHost"] = "github.example.com"
+	req.Header["X-GitHub-Enterprise-Host"] = []string{"github.example.com"}
+	res := w.Process(ctx, req)
+	if res.Continue || res.Status.Code != codes.FailedPrecondition || !strings.Contains(res.Status.Message, "is not verified: unsigned") {
+		t.Errorf("Interceptor.Process() got %v, want the unverified commit rejected", res)
+	}
+	if authorization != "token "+token {
+		t.Errorf("GitHub API got Authorization %q, want %q", authorization, "token "+token)
+	}
+}
+
+func TestInterceptor_Process_VerifyCommits_ShouldNotContinue(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		event    string
+		body     string
+		params   triggersv1.GithubVerifyCommits
+		handler  http.HandlerFunc
+		wantCode codes.Code
+		wantMsg  string
+	}{{
+		name:     "unverified commit required to be verified",
+		event:    "push",
+		body:     unverifiedBody,
+		params:   triggersv1.GithubVerifyCommits{Enabled: true, RequireVerified: true},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "commit 3333333333333333333333333333333333333333 is not verified: unsigned",
+	}, {
+		name:   "timed out",
+		event:  "pull_request",
+		body:   headCommitBody,
+		params: triggersv1.GithubVerifyCommits{Enabled: true, Timeout: "10ms"},
+		handler: func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		},
+		wantCode: codes.DeadlineExceeded,
+		wantMsg:  "timed out getting commit 2222222222222222222222222222222222222222 from the GitHub API",
+	}, {
+		name:   "not found",
+		event:  "pull_request",
+		body:   headCommitBody,
+		params: triggersv1.GithubVerifyCommits{Enabled: true},
+		handler: func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		},
+		wantCode: codes.Unavailable,
+		wantMsg:  "failed to get commit 2222222222222222222222222222222222222222 from the GitHub API",
+	}, {
+		name:     "invalid timeout",
+		event:    "pull_request",
+		body:     headCommitBody,
+		params:   triggersv1.GithubVerifyCommits{Enabled: true, Timeout: "soon"},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `invalid verifyCommits.timeout "soon"`,
+	}, {
+		name:     "no pull request head commit",
+		event:    "pull_request",
+		body:     pullRequestBody,
+		params:   triggersv1.GithubVerifyCommits{Enabled: true},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "no pull request head commit in the event",
+	}, {
+		name:  "empty token secret key",
+		event: "pull_request",
+		body:  headCommitBody,
+		params: triggersv1.GithubVerifyCommits{
+			Enabled:             true,
+			PersonalAccessToken: &triggersv1.SecretRef{SecretName: "github-token"},
+		},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "github interceptor personalAccessToken.secretKey is empty",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var authorization string
+			var srv *httptest.Server
+			if tc.handler != nil {
+				srv = httptest.NewServer(tc.handler)
+				defer srv.Close()
+			} else {
+				srv = fakeCommitsAPI(t, &authorization)
+			}
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, tokenSecret)
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+			w.APIURL = srv.URL + "/api/v3/"
+
+			res := w.Process(ctx, verifyCommitsRequest(tc.event, tc.body, tc.params))
+			if res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be false but got %t", res.Continue)
+			}
+			if res.Status.Code != tc.wantCode {
+				t.Errorf("Interceptor.Process() got status code %v, want %v", res.Status.Code, tc.wantCode)
+			}
+			if !strings.Contains(res.Status.Message, tc.wantMsg) {
+				t.Errorf("Interceptor.Process() got message %q, want it to contain %q", res.Status.Message, tc.wantMsg)
+			}
+		})
+	}
+}
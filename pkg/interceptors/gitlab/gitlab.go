@@ -19,6 +19,7 @@ package gitlab
 import (
 	"context"
 	"crypto/subtle"
+	"errors"
 
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
 	"github.com/tektoncd/triggers/pkg/interceptors"
@@ -27,6 +28,8 @@ import (
 
 var _ triggersv1.InterceptorInterface = (*Interceptor)(nil)
 
+var errInvalidToken = errors.New("invalid X-GitLab-Token")
+
 type Interceptor struct {
 	SecretGetter interceptors.SecretGetter
 }
@@ -76,13 +79,18 @@ func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequ
 		}
 
 		ns, _ := triggersv1.ParseTriggerID(r.Context.TriggerID)
-		secretToken, err := w.SecretGetter.Get(ctx, ns, p.SecretRef)
+		secretTokens, err := interceptors.GetSecrets(ctx, w.SecretGetter, ns, p.SecretRef, p.AdditionalSecretRefs)
 		if err != nil {
 			return interceptors.Failf(codes.FailedPrecondition, "error getting secret: %v", err)
 		}
 
 		// Make sure to use a constant time comparison here.
-		if subtle.ConstantTimeCompare([]byte(header), secretToken) == 0 {
+		if err := interceptors.ValidateAny(secretTokens, func(secret []byte) error {
+			if subtle.ConstantTimeCompare([]byte(header), secret) == 0 {
+				return errInvalidToken
+			}
+			return nil
+		}); err != nil {
 			return interceptors.Fail(codes.InvalidArgument, "Invalid X-GitLab-Token")
 		}
 	}
@@ -61,6 +61,30 @@ func TestInterceptor_ExecuteTrigger_ShouldContinue(t *testing.T) {
 			},
 		},
 		payload: []byte("somepayload"),
+	}, {
+		name: "valid header for additional secret",
+		interceptorParams: &triggersv1.GitLabInterceptor{
+			SecretRef: &triggersv1.SecretRef{
+				SecretName: "mysecret",
+				SecretKey:  "new-token",
+			},
+			AdditionalSecretRefs: []triggersv1.SecretRef{{
+				SecretName: "mysecret",
+				SecretKey:  "token",
+			}},
+		},
+
+		token: "secret",
+		secret: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "mysecret",
+			},
+			Data: map[string][]byte{
+				"new-token": []byte("new-secret"),
+				"token":     []byte("secret"),
+			},
+		},
+		payload: []byte("somepayload"),
 	}, {
 		name: "valid event",
 		interceptorParams: &triggersv1.GitLabInterceptor{
@@ -99,8 +123,9 @@ func TestInterceptor_ExecuteTrigger_ShouldContinue(t *testing.T) {
 					"Content-Type": []string{"application/json"},
 				},
 				InterceptorParams: map[string]interface{}{
-					"eventTypes": tt.interceptorParams.EventTypes,
-					"secretRef":  tt.interceptorParams.SecretRef,
+					"eventTypes":           tt.interceptorParams.EventTypes,
+					"secretRef":            tt.interceptorParams.SecretRef,
+					"additionalSecretRefs": tt.interceptorParams.AdditionalSecretRefs,
 				},
 				Context: &triggersv1.TriggerContext{
 					EventURL:  "https://testing.example.com",
@@ -158,6 +183,30 @@ func TestInterceptor_ExecuteTrigger_ShouldNotContinue(t *testing.T) {
 			},
 		},
 		payload: []byte("somepayload"),
+	}, {
+		name: "invalid header for all secrets",
+		interceptorParams: &triggersv1.GitLabInterceptor{
+			SecretRef: &triggersv1.SecretRef{
+				SecretName: "mysecret",
+				SecretKey:  "token",
+			},
+			AdditionalSecretRefs: []triggersv1.SecretRef{{
+				SecretName: "mysecret",
+				SecretKey:  "old-token",
+			}},
+		},
+
+		token: "foo",
+		secret: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "mysecret",
+			},
+			Data: map[string][]byte{
+				"token":     []byte("secrettoken"),
+				"old-token": []byte("oldsecrettoken"),
+			},
+		},
+		payload: []byte("somepayload"),
 	}, {
 		name: "missing header for secret",
 		interceptorParams: &triggersv1.GitLabInterceptor{
@@ -250,8 +299,9 @@ func TestInterceptor_ExecuteTrigger_ShouldNotContinue(t *testing.T) {
 					"Content-Type": []string{"application/json"},
 				},
 				InterceptorParams: map[string]interface{}{
-					"eventTypes": tt.interceptorParams.EventTypes,
-					"secretRef":  tt.interceptorParams.SecretRef,
+					"eventTypes":           tt.interceptorParams.EventTypes,
+					"secretRef":            tt.interceptorParams.SecretRef,
+					"additionalSecretRefs": tt.interceptorParams.AdditionalSecretRefs,
 				},
 				Context: &triggersv1.TriggerContext{
 					EventURL:  "https://testing.example.com",
@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package header
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"google.golang.org/grpc/codes"
+)
+
+var _ triggersv1.InterceptorInterface = (*Interceptor)(nil)
+
+// InterceptorParams provides a webhook to intercept and pre-process events.
+type InterceptorParams struct {
+	// Headers lists the headers that the requests must have.
+	Headers []RequiredHeader `json:"headers,omitempty"`
+}
+
+// RequiredHeader is a header that the requests must have. Without Values or a SecretRef, any value is
+// accepted.
+type RequiredHeader struct {
+	Name string `json:"name"`
+	// Values lists the allowed values of the header.
+	Values []string `json:"values,omitempty"`
+	// SecretRef and the optional AdditionalSecretRefs reference the secret values that the header must
+	// have, e.g. a token shared with the sender.
+	SecretRef            *triggersv1.SecretRef  `json:"secretRef,omitempty"`
+	AdditionalSecretRefs []triggersv1.SecretRef `json:"additionalSecretRefs,omitempty"`
+}
+
+// Interceptor rejects the requests that don't have the required headers, or whose headers have values
+// that are not allowed. Each value of a header that is sent several times must be allowed.
+type Interceptor struct {
+	SecretGetter interceptors.SecretGetter
+}
+
+func NewInterceptor(sg interceptors.SecretGetter) *Interceptor {
+	return &Interceptor{
+		SecretGetter: sg,
+	}
+}
+
+func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequest) *triggersv1.InterceptorResponse {
+	p := InterceptorParams{}
+	if err := interceptors.UnmarshalParams(r.InterceptorParams, &p); err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "failed to parse interceptor params: %v", err)
+	}
+	if len(p.Headers) == 0 {
+		return interceptors.Fail(codes.InvalidArgument, "header interceptor headers is empty")
+	}
+
+	headers := interceptors.Canonical(r.Header)
+	for i, h := range p.Headers {
+		if h.Name == "" {
+			return interceptors.Failf(codes.InvalidArgument, "header interceptor headers[%d].name is empty", i)
+		}
+		if h.Values != nil && h.SecretRef != nil {
+			return interceptors.Failf(codes.InvalidArgument, "header interceptor headers[%d] can't have both values and a secretRef", i)
+		}
+		name := http.CanonicalHeaderKey(h.Name)
+		values := headers.Values(name)
+		if len(values) == 0 {
+			return interceptors.Failf(codes.Unauthenticated, "no %s header set", name)
+		}
+
+		if h.Values != nil {
+			for _, v := range values {
+				if !interceptors.Contains(h.Values, v) {
+					return interceptors.Failf(codes.PermissionDenied, "%s header value %q is not allowed", name, v)
+				}
+			}
+		}
+
+		if h.SecretRef != nil {
+			if h.SecretRef.SecretKey == "" {
+				return interceptors.Failf(codes.InvalidArgument, "header interceptor headers[%d].secretRef.secretKey is empty", i)
+			}
+			if r.Context == nil {
+				return interceptors.Failf(codes.InvalidArgument, "no request context passed")
+			}
+			ns, _ := triggersv1.ParseTriggerID(r.Context.TriggerID)
+			secretTokens, err := interceptors.GetSecrets(ctx, w.SecretGetter, ns, h.SecretRef, h.AdditionalSecretRefs)
+			if err != nil {
+				return interceptors.Failf(codes.FailedPrecondition, "error getting secret: %v", err)
+			}
+			for _, v := range values {
+				if err := interceptors.ValidateAny(secretTokens, func(secret []byte) error {
+					return validateValue(name, v, secret)
+				}); err != nil {
+					return interceptors.Fail(codes.Unauthenticated, err.Error())
+				}
+			}
+		}
+	}
+
+	return &triggersv1.InterceptorResponse{
+		Continue: true,
+	}
+}
+
+// validateValue checks that the value of the header is the secret. The SHA-256 hashes of the value
+// and the secret are compared in constant time, so that neither the time taken nor an early return on
+// different lengths reveals the secret.
+func validateValue(name, value string, secret []byte) error {
+	got := sha256.Sum256([]byte(value))
+	want := sha256.Sum256(secret)
+	if subtle.ConstantTimeCompare(got[:], want[:]) != 1 {
+		return fmt.Errorf("%s header does not match the secret", name)
+	}
+	return nil
+}
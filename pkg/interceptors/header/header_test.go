@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package header
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/pkg/interceptors/interceptorstest"
+	"github.com/tektoncd/triggers/test"
+	"google.golang.org/grpc/codes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+)
+
+var (
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mysecret",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Data: map[string][]byte{
+			"token":    []byte("internal-token"),
+			"newToken": []byte("new-internal-token"),
+		},
+	}
+	secretRef = &triggersv1.SecretRef{SecretName: "mysecret", SecretKey: "token"}
+)
+
+func newRequest(header http.Header, headers ...RequiredHeader) *triggersv1.InterceptorRequest {
+	return interceptorstest.NewRequest(`{}`, header, map[string]interface{}{"headers": headers})
+}
+
+func TestInterceptor_Process_ShouldContinue(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		header  http.Header
+		headers []RequiredHeader
+	}{{
+		name:    "present header",
+		header:  http.Header{"X-Source": {"anything"}},
+		headers: []RequiredHeader{{Name: "X-Source"}},
+	}, {
+		name:    "allowed value",
+		header:  http.Header{"X-Source": {"scheduler"}},
+		headers: []RequiredHeader{{Name: "x-source", Values: []string{"ci", "scheduler"}}},
+	}, {
+		name:    "repeated allowed values",
+		header:  http.Header{"X-Source": {"scheduler", "ci"}},
+		headers: []RequiredHeader{{Name: "X-Source", Values: []string{"ci", "scheduler"}}},
+	}, {
+		name:    "secret value",
+		header:  http.Header{"X-Internal-Token": {"internal-token"}},
+		headers: []RequiredHeader{{Name: "X-Internal-Token", SecretRef: secretRef}},
+	}, {
+		name:   "additional secret value",
+		header: http.Header{"X-Internal-Token": {"new-internal-token"}},
+		headers: []RequiredHeader{{
+			Name:                 "X-Internal-Token",
+			SecretRef:            secretRef,
+			AdditionalSecretRefs: []triggersv1.SecretRef{{SecretName: "mysecret", SecretKey: "newToken"}},
+		}},
+	}, {
+		name:   "several headers",
+		header: http.Header{"X-Source": {"ci"}, "X-Internal-Token": {"internal-token"}},
+		headers: []RequiredHeader{
+			{Name: "X-Source", Values: []string{"ci"}},
+			{Name: "X-Internal-Token", SecretRef: secretRef},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, secret)
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+
+			res := w.Process(ctx, newRequest(tc.header, tc.headers...))
+			if !res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be true but got %t. \nStatus.Err(): %v", res.Continue, res.Status.Err())
+			}
+		})
+	}
+}
+
+func TestInterceptor_Process_ShouldNotContinue(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		header   http.Header
+		headers  []RequiredHeader
+		wantCode codes.Code
+		wantMsg  string
+	}{{
+		name:     "no headers",
+		header:   http.Header{"X-Source": {"ci"}},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "header interceptor headers is empty",
+	}, {
+		name:     "empty name",
+		header:   http.Header{"X-Source": {"ci"}},
+		headers:  []RequiredHeader{{Values: []string{"ci"}}},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "header interceptor headers[0].name is empty",
+	}, {
+		name:     "values and secretRef",
+		header:   http.Header{"X-Source": {"ci"}},
+		headers:  []RequiredHeader{{Name: "X-Source", Values: []string{"ci"}, SecretRef: secretRef}},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "header interceptor headers[0] can't have both values and a secretRef",
+	}, {
+		name:     "missing header",
+		header:   http.Header{"X-Other": {"ci"}},
+		headers:  []RequiredHeader{{Name: "x-source"}},
+		wantCode: codes.Unauthenticated,
+		wantMsg:  "no X-Source header set",
+	}, {
+		name:     "value not allowed",
+		header:   http.Header{"X-Source": {"laptop"}},
+		headers:  []RequiredHeader{{Name: "X-Source", Values: []string{"ci", "scheduler"}}},
+		wantCode: codes.PermissionDenied,
+		wantMsg:  `X-Source header value "laptop" is not allowed`,
+	}, {
+		name:     "repeated value not allowed",
+		header:   http.Header{"X-Source": {"ci", "laptop"}},
+		headers:  []RequiredHeader{{Name: "X-Source", Values: []string{"ci"}}},
+		wantCode: codes.PermissionDenied,
+		wantMsg:  `X-Source header value "laptop" is not allowed`,
+	}, {
+		name:     "wrong secret value",
+		header:   http.Header{"X-Internal-Token": {"internal"}},
+		headers:  []RequiredHeader{{Name: "X-Internal-Token", SecretRef: secretRef}},
+		wantCode: codes.Unauthenticated,
+		wantMsg:  "X-Internal-Token header does not match the secret",
+	}, {
+		name:     "repeated header with a wrong secret value",
+		header:   http.Header{"X-Internal-Token": {"internal-token", "guess"}},
+		headers:  []RequiredHeader{{Name: "X-Internal-Token", SecretRef: secretRef}},
+		wantCode: codes.Unauthenticated,
+		wantMsg:  "X-Internal-Token header does not match the secret",
+	}, {
+		name:     "empty secret key",
+		header:   http.Header{"X-Internal-Token": {"internal-token"}},
+		headers:  []RequiredHeader{{Name: "X-Internal-Token", SecretRef: &triggersv1.SecretRef{SecretName: "mysecret"}}},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "header interceptor headers[0].secretRef.secretKey is empty",
+	}, {
+		name:     "missing secret",
+		header:   http.Header{"X-Internal-Token": {"internal-token"}},
+		headers:  []RequiredHeader{{Name: "X-Internal-Token", SecretRef: &triggersv1.SecretRef{SecretName: "other", SecretKey: "token"}}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "error getting secret",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, secret)
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+
+			res := w.Process(ctx, newRequest(tc.header, tc.headers...))
+			if res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be false but got %t", res.Continue)
+			}
+			if res.Status.Code != tc.wantCode {
+				t.Errorf("Interceptor.Process() got status code %v, want %v", res.Status.Code, tc.wantCode)
+			}
+			if !strings.Contains(res.Status.Message, tc.wantMsg) {
+				t.Errorf("Interceptor.Process() got message %q, want it to contain %q", res.Status.Message, tc.wantMsg)
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 
 	triggersv1alpha1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 	triggersv1beta1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
@@ -83,6 +84,39 @@ func Canonical(h map[string][]string) http.Header {
 	return http.Header(c)
 }
 
+// SignedBody returns the body of the request whose signature interceptors verify: the body as it was sent,
+// if the payload parsers of the EventListener converted it, or else the body.
+func SignedBody(r *triggersv1beta1.InterceptorRequest) string {
+	if r.RawBody != "" {
+		return r.RawBody
+	}
+	return r.Body
+}
+
+// JSONPathExpression turns path, which may omit the leading $, dot and curly braces, into a JSONPath
+// template of the body, e.g. {.repository.url}.
+func JSONPathExpression(path string) string {
+	path = strings.TrimSpace(path)
+	if strings.HasPrefix(path, "{") && strings.HasSuffix(path, "}") {
+		path = path[1 : len(path)-1]
+	}
+	path = strings.TrimPrefix(path, "$")
+	if !strings.HasPrefix(path, ".") && !strings.HasPrefix(path, "[") {
+		path = "." + path
+	}
+	return "{" + path + "}"
+}
+
+// Contains returns whether values contains v.
+func Contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
 // UnmarshalParams unmarshalls the passed in InterceptorParams into the provided param struct
 func UnmarshalParams(ip map[string]interface{}, p interface{}) error {
 	b, err := json.Marshal(ip)
@@ -137,8 +171,12 @@ func Execute(ctx context.Context, client *http.Client, req *triggersv1beta1.Inte
 		// TODO: error type for easier checking. wrap in status.Errorf?
 		return nil, fmt.Errorf("interceptor response was not 200: %v", string(body))
 	}
+	var numbers triggersv1beta1.JSONNumbers
+	if req.Context != nil {
+		numbers = req.Context.JSONNumbers
+	}
 	iresp := triggersv1beta1.InterceptorResponse{}
-	if err := json.Unmarshal(body, &iresp); err != nil {
+	if err := numbers.Unmarshal(body, &iresp); err != nil {
 		return nil, err
 	}
 	return &iresp, nil
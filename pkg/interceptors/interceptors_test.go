@@ -234,6 +234,39 @@ func TestCanonical(t *testing.T) {
 	}
 }
 
+func TestSignedBody(t *testing.T) {
+	if got := interceptors.SignedBody(&triggersv1.InterceptorRequest{Body: `{"ref":"main"}`}); got != `{"ref":"main"}` {
+		t.Errorf("SignedBody() = %s, want the body", got)
+	}
+	if got := interceptors.SignedBody(&triggersv1.InterceptorRequest{Body: `{"ref":"main"}`, RawBody: "ref=main"}); got != "ref=main" {
+		t.Errorf("SignedBody() = %s, want the raw body", got)
+	}
+}
+
+func TestJSONPathExpression(t *testing.T) {
+	for path, want := range map[string]string{
+		"repository.url":    "{.repository.url}",
+		".repository.url":   "{.repository.url}",
+		"$.repository.url":  "{.repository.url}",
+		"{.repository.url}": "{.repository.url}",
+		" commits[0].id ":   "{.commits[0].id}",
+		"[0].id":            "{[0].id}",
+	} {
+		if got := interceptors.JSONPathExpression(path); got != want {
+			t.Errorf("JSONPathExpression(%q) = %s, want %s", path, got, want)
+		}
+	}
+}
+
+func TestContains(t *testing.T) {
+	if !interceptors.Contains([]string{"push", "pull_request"}, "push") {
+		t.Error("Contains() = false for a listed value")
+	}
+	if interceptors.Contains([]string{"push"}, "Push") || interceptors.Contains(nil, "push") {
+		t.Error("Contains() = true for a value that isn't listed")
+	}
+}
+
 func TestUnmarshalParam(t *testing.T) {
 	in := map[string]interface{}{
 		"secretKey":  "key",
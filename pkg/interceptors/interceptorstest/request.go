@@ -0,0 +1,47 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package interceptorstest provides fixtures to test interceptors.
+package interceptorstest
+
+import (
+	"net/http"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+)
+
+const (
+	// EventURL is the URL of the events of the requests returned by NewRequest.
+	EventURL = "https://testing.example.com"
+	// EventID is the ID of the events of the requests returned by NewRequest.
+	EventID = "abcde"
+	// TriggerID is the ID of the trigger of the requests returned by NewRequest.
+	TriggerID = "namespaces/default/triggers/example-trigger"
+)
+
+// NewRequest returns a request with the body, header and params, from the trigger TriggerID.
+func NewRequest(body string, header http.Header, params map[string]interface{}) *triggersv1.InterceptorRequest {
+	return &triggersv1.InterceptorRequest{
+		Body:              body,
+		Header:            header,
+		InterceptorParams: params,
+		Context: &triggersv1.TriggerContext{
+			EventURL:  EventURL,
+			EventID:   EventID,
+			TriggerID: TriggerID,
+		},
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protobuf
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var _ triggersv1.InterceptorInterface = (*Interceptor)(nil)
+
+const (
+	// ExtensionKey is the extensions key under which the decoded message is returned.
+	ExtensionKey = "protobuf"
+
+	// BinaryEncoding is the binary wire format, passed on by the protobuf payload parser of the
+	// EventListener as a JSON string holding its base64 encoding.
+	BinaryEncoding = "binary"
+	// JSONEncoding is the canonical JSON mapping of protobuf messages.
+	JSONEncoding = "json"
+)
+
+// InterceptorParams provides a webhook to intercept and pre-process events.
+type InterceptorParams struct {
+	// DescriptorSetRef is the key of a secret holding a binary FileDescriptorSet with the message type and
+	// all its dependencies, e.g. the output of protoc --include_imports --descriptor_set_out.
+	DescriptorSetRef *triggersv1.SecretRef `json:"descriptorSetRef,omitempty"`
+	// MessageType is the fully qualified name of the message type of the payloads, e.g. acme.events.v1.Push.
+	MessageType string `json:"messageType,omitempty"`
+	// Encoding is the encoding of the payloads: binary or json. Defaults to binary.
+	Encoding string `json:"encoding,omitempty"`
+	// AllowUnknownFields accepts the payloads with fields that are not in the message type, which are left
+	// out of the decoded message. They are rejected by default.
+	AllowUnknownFields bool `json:"allowUnknownFields,omitempty"`
+}
+
+// Interceptor validates payloads against a protobuf message type and returns them decoded as JSON, with
+// the field names of the .proto file, for the bindings of the trigger.
+type Interceptor struct {
+	SecretGetter interceptors.SecretGetter
+}
+
+func NewInterceptor(sg interceptors.SecretGetter) *Interceptor {
+	return &Interceptor{
+		SecretGetter: sg,
+	}
+}
+
+func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequest) *triggersv1.InterceptorResponse {
+	p := InterceptorParams{}
+	if err := interceptors.UnmarshalParams(r.InterceptorParams, &p); err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "failed to parse interceptor params: %v", err)
+	}
+	if p.DescriptorSetRef == nil || p.DescriptorSetRef.SecretName == "" || p.DescriptorSetRef.SecretKey == "" {
+		return interceptors.Fail(codes.InvalidArgument, "protobuf interceptor descriptorSetRef must set secretName and secretKey")
+	}
+	if p.MessageType == "" {
+		return interceptors.Fail(codes.InvalidArgument, "protobuf interceptor messageType is empty")
+	}
+	switch p.Encoding {
+	case "", BinaryEncoding, JSONEncoding:
+	default:
+		return interceptors.Failf(codes.InvalidArgument, "protobuf interceptor encoding %q must be %s or %s", p.Encoding, BinaryEncoding, JSONEncoding)
+	}
+	if r.Context == nil {
+		return interceptors.Failf(codes.InvalidArgument, "no request context passed")
+	}
+
+	ns, _ := triggersv1.ParseTriggerID(r.Context.TriggerID)
+	descriptorSet, err := w.SecretGetter.Get(ctx, ns, p.DescriptorSetRef)
+	if err != nil {
+		return interceptors.Failf(codes.FailedPrecondition, "error getting secret: %v", err)
+	}
+	md, err := findMessage(descriptorSet, p.MessageType)
+	if err != nil {
+		return interceptors.Fail(codes.FailedPrecondition, err.Error())
+	}
+
+	msg := dynamicpb.NewMessage(md)
+	if p.Encoding == JSONEncoding {
+		opts := protojson.UnmarshalOptions{DiscardUnknown: p.AllowUnknownFields}
+		if err := opts.Unmarshal([]byte(r.Body), msg); err != nil {
+			return interceptors.Failf(codes.InvalidArgument, "body is not a valid %s JSON message: %v", p.MessageType, err)
+		}
+	} else {
+		var encoded string
+		if err := json.Unmarshal([]byte(r.Body), &encoded); err != nil {
+			return interceptors.Fail(codes.InvalidArgument, "binary body must be passed on as a base64 JSON string by the protobuf payload parser of the EventListener")
+		}
+		wire, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return interceptors.Failf(codes.InvalidArgument, "binary body is not valid base64: %v", err)
+		}
+		if err := proto.Unmarshal(wire, msg); err != nil {
+			return interceptors.Failf(codes.InvalidArgument, "body is not a valid %s message: %v", p.MessageType, err)
+		}
+		if path, ok := unknownField(msg.ProtoReflect(), p.MessageType); ok && !p.AllowUnknownFields {
+			return interceptors.Failf(codes.InvalidArgument, "body is not a valid %s message: unknown field in %s", p.MessageType, path)
+		}
+	}
+
+	decoded, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return interceptors.Failf(codes.Internal, "failed to convert %s message to JSON: %v", p.MessageType, err)
+	}
+	var fields interface{}
+	if err := json.Unmarshal(decoded, &fields); err != nil {
+		return interceptors.Failf(codes.Internal, "failed to convert %s message to JSON: %v", p.MessageType, err)
+	}
+	return &triggersv1.InterceptorResponse{
+		Continue: true,
+		Extensions: map[string]interface{}{
+			ExtensionKey: fields,
+		},
+	}
+}
+
+// findMessage returns the descriptor of the message type of the given name in the binary FileDescriptorSet.
+func findMessage(descriptorSet []byte, name string) (protoreflect.MessageDescriptor, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(descriptorSet, set); err != nil {
+		return nil, fmt.Errorf("descriptor set is not a valid FileDescriptorSet: %w", err)
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	d, err := files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("message type %s is not in the descriptor set", name)
+	}
+	md, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a message type", name)
+	}
+	return md, nil
+}
+
+// unknownField returns the path of the first message with unknown fields, e.g. acme.Push.commits[1], if any.
+func unknownField(m protoreflect.Message, path string) (string, bool) {
+	if len(m.GetUnknown()) > 0 {
+		return path, true
+	}
+	var found string
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := path + "." + string(fd.Name())
+		switch {
+		case fd.IsList() && fd.Message() != nil:
+			for i := 0; i < v.List().Len(); i++ {
+				if p, ok := unknownField(v.List().Get(i).Message(), fmt.Sprintf("%s[%d]", name, i)); ok {
+					found = p
+					return false
+				}
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				if p, ok := unknownField(mv.Message(), fmt.Sprintf("%s[%v]", name, k.Interface())); ok {
+					found = p
+					return false
+				}
+				return true
+			})
+			return found == ""
+		case fd.Message() != nil && !fd.IsMap():
+			if p, ok := unknownField(v.Message(), name); ok {
+				found = p
+				return false
+			}
+		}
+		return true
+	})
+	return found, found != ""
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protobuf
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/pkg/interceptors/interceptorstest"
+	"github.com/tektoncd/triggers/test"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+)
+
+var descriptorSetRef = &triggersv1.SecretRef{SecretName: "descriptors", SecretKey: "events.pb"}
+
+// descriptorSet is the descriptor set of:
+//
+//	syntax = "proto3";
+//	package acme.events.v1;
+//	message Push { string ref = 1; int64 size = 2; repeated Commit commits = 3; }
+//	message Commit { string id = 1; }
+func descriptorSet(t *testing.T) []byte {
+	t.Helper()
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     typ.Enum(),
+			Label:    label.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	optional, repeated := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	b, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{{
+			Name:    proto.String("acme/events/v1/push.proto"),
+			Package: proto.String("acme.events.v1"),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("Push"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("ref", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+					field("size", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64, optional, ""),
+					field("commits", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, repeated, ".acme.events.v1.Commit"),
+				},
+			}, {
+				Name: proto.String("Commit"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+				},
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// push is the binary encoding of {ref: "main", size: 42, commits: [{id: "a"}]}, followed by extra.
+func push(extra ...byte) string {
+	wire := append([]byte{0x0a, 0x04, 'm', 'a', 'i', 'n', 0x10, 42, 0x1a, 0x03, 0x0a, 0x01, 'a'}, extra...)
+	return `"` + base64.StdEncoding.EncodeToString(wire) + `"`
+}
+
+func TestInterceptor_Process_ShouldContinue(t *testing.T) {
+	pushFields := map[string]interface{}{
+		"ref":     "main",
+		"size":    "42",
+		"commits": []interface{}{map[string]interface{}{"id": "a"}},
+	}
+	for _, tc := range []struct {
+		name   string
+		body   string
+		params map[string]interface{}
+		want   interface{}
+	}{{
+		name:   "binary",
+		body:   push(),
+		params: map[string]interface{}{"descriptorSetRef": descriptorSetRef, "messageType": "acme.events.v1.Push"},
+		want:   pushFields,
+	}, {
+		name:   "explicit binary encoding",
+		body:   push(),
+		params: map[string]interface{}{"descriptorSetRef": descriptorSetRef, "messageType": "acme.events.v1.Push", "encoding": "binary"},
+		want:   pushFields,
+	}, {
+		name:   "binary with allowed unknown field",
+		body:   push(0x78, 0x01),
+		params: map[string]interface{}{"descriptorSetRef": descriptorSetRef, "messageType": "acme.events.v1.Push", "allowUnknownFields": true},
+		want:   pushFields,
+	}, {
+		name:   "json",
+		body:   `{"ref": "main", "size": 42, "commits": [{"id": "a"}]}`,
+		params: map[string]interface{}{"descriptorSetRef": descriptorSetRef, "messageType": "acme.events.v1.Push", "encoding": "json"},
+		want:   pushFields,
+	}, {
+		name:   "json with allowed unknown field",
+		body:   `{"ref": "main", "size": "42", "commits": [{"id": "a"}], "sender": "octocat"}`,
+		params: map[string]interface{}{"descriptorSetRef": descriptorSetRef, "messageType": "acme.events.v1.Push", "encoding": "json", "allowUnknownFields": true},
+		want:   pushFields,
+	}, {
+		name:   "empty message",
+		body:   `""`,
+		params: map[string]interface{}{"descriptorSetRef": descriptorSetRef, "messageType": "acme.events.v1.Commit"},
+		want:   map[string]interface{}{},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, descriptorsSecret(t))
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+
+			res := w.Process(ctx, interceptorstest.NewRequest(tc.body, nil, tc.params))
+			if !res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be true but got %t. \nStatus.Err(): %v", res.Continue, res.Status.Err())
+			}
+			if diff := cmp.Diff(map[string]interface{}{ExtensionKey: tc.want}, res.Extensions); diff != "" {
+				t.Errorf("Interceptor.Process() extensions -want +got: %s", diff)
+			}
+		})
+	}
+}
+
+func TestInterceptor_Process_ShouldNotContinue(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		body     string
+		params   map[string]interface{}
+		wantCode codes.Code
+		wantMsg  string
+	}{{
+		name:     "no descriptor set",
+		body:     push(),
+		params:   map[string]interface{}{"messageType": "acme.events.v1.Push"},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "protobuf interceptor descriptorSetRef must set secretName and secretKey",
+	}, {
+		name:     "no message type",
+		body:     push(),
+		params:   map[string]interface{}{"descriptorSetRef": descriptorSetRef},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "protobuf interceptor messageType is empty",
+	}, {
+		name:     "unknown encoding",
+		body:     push(),
+		params:   map[string]interface{}{"descriptorSetRef": descriptorSetRef, "messageType": "acme.events.v1.Push", "encoding": "text"},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `protobuf interceptor encoding "text" must be binary or json`,
+	}, {
+		name:     "missing secret key",
+		body:     push(),
+		params:   map[string]interface{}{"descriptorSetRef": &triggersv1.SecretRef{SecretName: "descriptors", SecretKey: "missing.pb"}, "messageType": "acme.events.v1.Push"},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "error getting secret",
+	}, {
+		name:     "invalid descriptor set",
+		body:     push(),
+		params:   map[string]interface{}{"descriptorSetRef": &triggersv1.SecretRef{SecretName: "descriptors", SecretKey: "garbage"}, "messageType": "acme.events.v1.Push"},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "descriptor set is not a valid FileDescriptorSet",
+	}, {
+		name:     "unknown message type",
+		body:     push(),
+		params:   map[string]interface{}{"descriptorSetRef": descriptorSetRef, "messageType": "acme.events.v1.Tag"},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "message type acme.events.v1.Tag is not in the descriptor set",
+	}, {
+		name:     "not a message type",
+		body:     push(),
+		params:   map[string]interface{}{"descriptorSetRef": descriptorSetRef, "messageType": "acme.events.v1.Push.ref"},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "acme.events.v1.Push.ref is not a message type",
+	}, {
+		name:     "raw binary body",
+		body:     "\n\x04main",
+		params:   map[string]interface{}{"descriptorSetRef": descriptorSetRef, "messageType": "acme.events.v1.Push"},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "binary body must be passed on as a base64 JSON string",
+	}, {
+		name:     "invalid base64",
+		body:     `"not base64!"`,
+		params:   map[string]interface{}{"descriptorSetRef": descriptorSetRef, "messageType": "acme.events.v1.Push"},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "binary body is not valid base64",
+	}, {
+		name:     "truncated binary message",
+		body:     `"` + base64.StdEncoding.EncodeToString([]byte{0x0a, 0x04, 'm', 'a'}) + `"`,
+		params:   map[string]interface{}{"descriptorSetRef": descriptorSetRef, "messageType": "acme.events.v1.Push"},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "body is not a valid acme.events.v1.Push message",
+	}, {
+		name:     "binary wrong wire type",
+		body:     `"` + base64.StdEncoding.EncodeToString([]byte{0x0a, 0x04, 'm', 'a', 'i', 'n', 0x12, 0x01, 'x'}) + `"`,
+		params:   map[string]interface{}{"descriptorSetRef": descriptorSetRef, "messageType": "acme.events.v1.Push"},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "body is not a valid acme.events.v1.Push message: unknown field in acme.events.v1.Push",
+	}, {
+		name:     "binary unknown field",
+		body:     push(0x78, 0x01),
+		params:   map[string]interface{}{"descriptorSetRef": descriptorSetRef, "messageType": "acme.events.v1.Push"},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "body is not a valid acme.events.v1.Push message: unknown field in acme.events.v1.Push",
+	}, {
+		name:     "binary unknown field of nested message",
+		body:     `"` + base64.StdEncoding.EncodeToString([]byte{0x1a, 0x03, 0x0a, 0x01, 'a', 0x1a, 0x05, 0x0a, 0x01, 'b', 0x78, 0x01}) + `"`,
+		params:   map[string]interface{}{"descriptorSetRef": descriptorSetRef, "messageType": "acme.events.v1.Push"},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "unknown field in acme.events.v1.Push.commits[1]",
+	}, {
+		name:     "json unknown field",
+		body:     `{"ref": "main", "sender": "octocat"}`,
+		params:   map[string]interface{}{"descriptorSetRef": descriptorSetRef, "messageType": "acme.events.v1.Push", "encoding": "json"},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `body is not a valid acme.events.v1.Push JSON message`,
+	}, {
+		name:     "json wrong type",
+		body:     `{"size": "large"}`,
+		params:   map[string]interface{}{"descriptorSetRef": descriptorSetRef, "messageType": "acme.events.v1.Push", "encoding": "json"},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `body is not a valid acme.events.v1.Push JSON message`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, descriptorsSecret(t))
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+
+			res := w.Process(ctx, interceptorstest.NewRequest(tc.body, nil, tc.params))
+			if res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be false but got %t", res.Continue)
+			}
+			if res.Status.Code != tc.wantCode {
+				t.Errorf("Interceptor.Process() got status code %v, want %v", res.Status.Code, tc.wantCode)
+			}
+			if !strings.Contains(res.Status.Message, tc.wantMsg) {
+				t.Errorf("Interceptor.Process() got message %q, want it to contain %q", res.Status.Message, tc.wantMsg)
+			}
+		})
+	}
+}
+
+func descriptorsSecret(t *testing.T) *corev1.Secret {
+	t.Helper()
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      descriptorSetRef.SecretName,
+			Namespace: metav1.NamespaceDefault,
+		},
+		Data: map[string][]byte{
+			descriptorSetRef.SecretKey: descriptorSet(t),
+			"garbage":                  []byte("not a descriptor set"),
+		},
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package require
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"google.golang.org/grpc/codes"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+var _ triggersv1.InterceptorInterface = (*Interceptor)(nil)
+
+// InterceptorParams provides a webhook to intercept and pre-process events.
+type InterceptorParams struct {
+	// Fields lists the fields that the body of the requests must have.
+	Fields []RequiredField `json:"fields,omitempty"`
+}
+
+// RequiredField is a field that the body of the requests must have. Without NonEmpty or a Type, any
+// value is accepted, including null.
+type RequiredField struct {
+	// Path is the JSONPath of the field in the body, e.g. repository.url or commits[*].id. The leading $,
+	// dot and curly braces are optional. A path matching several fields requires at least one of them,
+	// and NonEmpty and Type apply to each of them.
+	Path string `json:"path"`
+	// NonEmpty requires the field not to be null, an empty string, an empty array or an empty object.
+	NonEmpty bool `json:"nonEmpty,omitempty"`
+	// Type is the JSON type that the field must have: string, number, boolean, array or object.
+	Type string `json:"type,omitempty"`
+}
+
+// Interceptor rejects the requests whose body doesn't have the required fields, before more expensive
+// interceptors process them. The fields are checked in order, and the message names the first one
+// that is missing or invalid.
+type Interceptor struct{}
+
+func NewInterceptor() *Interceptor {
+	return &Interceptor{}
+}
+
+// jsonTypes are the JSON types that a RequiredField can require.
+var jsonTypes = []string{"string", "number", "boolean", "array", "object"}
+
+func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequest) *triggersv1.InterceptorResponse {
+	p := InterceptorParams{}
+	if err := interceptors.UnmarshalParams(r.InterceptorParams, &p); err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "failed to parse interceptor params: %v", err)
+	}
+	if len(p.Fields) == 0 {
+		return interceptors.Fail(codes.InvalidArgument, "require interceptor fields is empty")
+	}
+	paths := make([]*jsonpath.JSONPath, len(p.Fields))
+	for i, f := range p.Fields {
+		if strings.TrimSpace(f.Path) == "" {
+			return interceptors.Failf(codes.InvalidArgument, "require interceptor fields[%d].path is empty", i)
+		}
+		if f.Type != "" && !interceptors.Contains(jsonTypes, f.Type) {
+			return interceptors.Failf(codes.InvalidArgument, "require interceptor fields[%d].type %q must be one of %s", i, f.Type, strings.Join(jsonTypes, ", "))
+		}
+		j := jsonpath.New(f.Path)
+		if err := j.Parse(interceptors.JSONPathExpression(f.Path)); err != nil {
+			return interceptors.Failf(codes.InvalidArgument, "require interceptor fields[%d].path %q is not a valid JSONPath: %v", i, f.Path, err)
+		}
+		paths[i] = j
+	}
+
+	var numbers triggersv1.JSONNumbers
+	if r.Context != nil {
+		numbers = r.Context.JSONNumbers
+	}
+	var body interface{}
+	if err := numbers.Unmarshal([]byte(r.Body), &body); err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "body is not valid JSON: %v", err)
+	}
+	for i, f := range p.Fields {
+		results, err := paths[i].FindResults(body)
+		var values []interface{}
+		if err == nil {
+			for _, res := range results {
+				for _, v := range res {
+					values = append(values, v.Interface())
+				}
+			}
+		}
+		if len(values) == 0 {
+			return interceptors.Failf(codes.FailedPrecondition, "body is missing required field %s", f.Path)
+		}
+		for _, v := range values {
+			if f.NonEmpty && isEmpty(v) {
+				return interceptors.Failf(codes.FailedPrecondition, "required field %s is empty", f.Path)
+			}
+			if f.Type != "" && jsonType(v) != f.Type {
+				return interceptors.Failf(codes.FailedPrecondition, "required field %s is a %s, expected a %s", f.Path, jsonType(v), f.Type)
+			}
+		}
+	}
+
+	return &triggersv1.InterceptorResponse{
+		Continue: true,
+	}
+}
+
+func isEmpty(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64, json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
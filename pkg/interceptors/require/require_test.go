@@ -0,0 +1,183 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package require
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors/interceptorstest"
+	"google.golang.org/grpc/codes"
+)
+
+const body = `{"repository": {"url": "https://github.com/tektoncd/triggers", "topics": []}, "ref": "main", "before": "", "deleted": false, "size": 2, "forced": null, "commits": [{"id": "abc"}, {"id": "def"}]}`
+
+func newRequest(body string, fields ...RequiredField) *triggersv1.InterceptorRequest {
+	return interceptorstest.NewRequest(body, nil, map[string]interface{}{"fields": fields})
+}
+
+func TestInterceptor_Process_ShouldContinue(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		fields []RequiredField
+	}{{
+		name:   "present fields",
+		fields: []RequiredField{{Path: "repository"}, {Path: "ref"}},
+	}, {
+		name:   "present empty and null fields",
+		fields: []RequiredField{{Path: "before"}, {Path: "forced"}, {Path: "repository.topics"}},
+	}, {
+		name:   "path syntaxes",
+		fields: []RequiredField{{Path: "repository.url"}, {Path: ".repository.url"}, {Path: "$.repository.url"}, {Path: "{.repository.url}"}, {Path: "commits[0].id"}},
+	}, {
+		name:   "non-empty fields",
+		fields: []RequiredField{{Path: "ref", NonEmpty: true}, {Path: "deleted", NonEmpty: true}, {Path: "commits[*].id", NonEmpty: true}},
+	}, {
+		name: "typed fields",
+		fields: []RequiredField{
+			{Path: "repository", Type: "object"},
+			{Path: "ref", Type: "string"},
+			{Path: "size", Type: "number"},
+			{Path: "deleted", Type: "boolean"},
+			{Path: "commits", Type: "array"},
+			{Path: "commits[*].id", Type: "string", NonEmpty: true},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			res := NewInterceptor().Process(context.Background(), newRequest(body, tc.fields...))
+			if !res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be true but got %t. \nStatus.Err(): %v", res.Continue, res.Status.Err())
+			}
+		})
+	}
+}
+
+func TestInterceptor_Process_ShouldNotContinue(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		body     string
+		fields   []RequiredField
+		wantCode codes.Code
+		wantMsg  string
+	}{{
+		name:     "no fields",
+		body:     body,
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "require interceptor fields is empty",
+	}, {
+		name:     "empty path",
+		body:     body,
+		fields:   []RequiredField{{Path: "ref"}, {Path: " "}},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "require interceptor fields[1].path is empty",
+	}, {
+		name:     "invalid path",
+		body:     body,
+		fields:   []RequiredField{{Path: "commits[0"}},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `require interceptor fields[0].path "commits[0" is not a valid JSONPath`,
+	}, {
+		name:     "unknown type",
+		body:     body,
+		fields:   []RequiredField{{Path: "ref", Type: "integer"}},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `require interceptor fields[0].type "integer" must be one of string, number, boolean, array, object`,
+	}, {
+		name:     "invalid body",
+		body:     `{"ref": `,
+		fields:   []RequiredField{{Path: "ref"}},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "body is not valid JSON",
+	}, {
+		name:     "first missing field",
+		body:     body,
+		fields:   []RequiredField{{Path: "ref"}, {Path: "pull_request.head.sha"}, {Path: "sender"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "body is missing required field pull_request.head.sha",
+	}, {
+		name:     "missing array element",
+		body:     body,
+		fields:   []RequiredField{{Path: "commits[2].id"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "body is missing required field commits[2].id",
+	}, {
+		name:     "no matching elements",
+		body:     `{"commits": []}`,
+		fields:   []RequiredField{{Path: "commits[*].id"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "body is missing required field commits[*].id",
+	}, {
+		name:     "not an object",
+		body:     `[]`,
+		fields:   []RequiredField{{Path: "ref"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "body is missing required field ref",
+	}, {
+		name:     "empty string",
+		body:     body,
+		fields:   []RequiredField{{Path: "before", NonEmpty: true}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "required field before is empty",
+	}, {
+		name:     "null",
+		body:     body,
+		fields:   []RequiredField{{Path: "forced", NonEmpty: true}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "required field forced is empty",
+	}, {
+		name:     "empty array",
+		body:     body,
+		fields:   []RequiredField{{Path: "repository.topics", NonEmpty: true}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "required field repository.topics is empty",
+	}, {
+		name:     "wrong type",
+		body:     body,
+		fields:   []RequiredField{{Path: "size", Type: "string"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "required field size is a number, expected a string",
+	}, {
+		name:     "wrong type of one match",
+		body:     `{"commits": [{"id": "abc"}, {"id": 1}]}`,
+		fields:   []RequiredField{{Path: "commits[*].id", Type: "string"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "required field commits[*].id is a number, expected a string",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			res := NewInterceptor().Process(context.Background(), newRequest(tc.body, tc.fields...))
+			if res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be false but got %t", res.Continue)
+			}
+			if res.Status.Code != tc.wantCode {
+				t.Errorf("Interceptor.Process() got status code %v, want %v", res.Status.Code, tc.wantCode)
+			}
+			if !strings.Contains(res.Status.Message, tc.wantMsg) {
+				t.Errorf("Interceptor.Process() got message %q, want it to contain %q", res.Status.Message, tc.wantMsg)
+			}
+		})
+	}
+}
+
+func TestInterceptor_Process_JSONNumbers(t *testing.T) {
+	req := newRequest(`{"installation": {"id": 12345678901234567890}}`, RequiredField{Path: "installation.id", Type: "number", NonEmpty: true})
+	req.Context.JSONNumbers = triggersv1.JSONNumbersExact
+	if res := NewInterceptor().Process(context.Background(), req); !res.Continue {
+		t.Fatalf("Interceptor.Process() expected res.Continue to be true but got %t. \nStatus.Err(): %v", res.Continue, res.Status.Err())
+	}
+}
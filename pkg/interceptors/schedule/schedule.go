@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"google.golang.org/grpc/codes"
+
+	// Embed the timezone database so that timezones can be loaded even if the image doesn't ship one.
+	_ "time/tzdata"
+)
+
+var _ triggersv1.InterceptorInterface = (*Interceptor)(nil)
+
+// InterceptorParams provides a webhook to intercept and pre-process events.
+type InterceptorParams struct {
+	// Timezone is the IANA name of the timezone the windows are evaluated in, UTC by default.
+	Timezone string `json:"timezone,omitempty"`
+	// Allow lists the windows in which events are accepted. If empty, events are accepted at any
+	// time that isn't denied.
+	Allow []Window `json:"allow,omitempty"`
+	// Deny lists the windows in which events are rejected, even if they are in an allowed window.
+	Deny []Window `json:"deny,omitempty"`
+}
+
+// Window is a recurring weekly time window, expressed in wall clock time.
+type Window struct {
+	// Days is a cron day-of-week field, e.g. "Mon-Fri" or "0,6". Every day by default.
+	Days string `json:"days,omitempty"`
+	// Start is the time of day at which the window opens, as HH:MM. Midnight by default.
+	Start string `json:"start,omitempty"`
+	// End is the time of day at which the window closes, as HH:MM. The end of the day, "24:00",
+	// by default. If End is before Start, the window closes on the following day.
+	End string `json:"end,omitempty"`
+}
+
+// Interceptor rejects events received outside of the allowed windows of a weekly schedule.
+type Interceptor struct {
+	now func() time.Time
+}
+
+func NewInterceptor() *Interceptor {
+	return &Interceptor{
+		now: time.Now,
+	}
+}
+
+func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequest) *triggersv1.InterceptorResponse {
+	p := InterceptorParams{}
+	if err := interceptors.UnmarshalParams(r.InterceptorParams, &p); err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "failed to parse interceptor params: %v", err)
+	}
+
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "invalid timezone %q: %v", p.Timezone, err)
+	}
+	allow, err := parseWindows("allow", p.Allow)
+	if err != nil {
+		return interceptors.Fail(codes.InvalidArgument, err.Error())
+	}
+	deny, err := parseWindows("deny", p.Deny)
+	if err != nil {
+		return interceptors.Fail(codes.InvalidArgument, err.Error())
+	}
+
+	now := w.now().In(loc)
+	for _, win := range deny {
+		if win.contains(now) {
+			return interceptors.Failf(codes.FailedPrecondition, "event received at %s is in the denied window %s", formatTime(now), win)
+		}
+	}
+	if len(allow) == 0 {
+		return &triggersv1.InterceptorResponse{Continue: true}
+	}
+	for _, win := range allow {
+		if win.contains(now) {
+			return &triggersv1.InterceptorResponse{Continue: true}
+		}
+	}
+	return interceptors.Failf(codes.FailedPrecondition, "event received at %s is outside of the allowed windows %v", formatTime(now), allow)
+}
+
+// formatTime formats t with its weekday and the name of its location, which the windows are matched against.
+func formatTime(t time.Time) string {
+	return fmt.Sprintf("%s (%s)", t.Format("Mon 2006-01-02 15:04:05 -0700"), t.Location())
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors/interceptorstest"
+	"google.golang.org/grpc/codes"
+)
+
+func mustParse(t *testing.T, value string) time.Time {
+	t.Helper()
+	ts, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts
+}
+
+func newRequest(params InterceptorParams) *triggersv1.InterceptorRequest {
+	return interceptorstest.NewRequest(`{}`, nil, map[string]interface{}{
+		"timezone": params.Timezone,
+		"allow":    params.Allow,
+		"deny":     params.Deny,
+	})
+}
+
+var businessHours = []Window{{Days: "Mon-Fri", Start: "09:00", End: "17:00"}}
+
+func TestInterceptor_Process_ShouldContinue(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		params InterceptorParams
+		now    string
+	}{{
+		name: "no windows",
+		now:  "2022-04-16T03:00:00Z",
+	}, {
+		name:   "in allowed window",
+		params: InterceptorParams{Allow: businessHours},
+		now:    "2022-04-15T09:00:00Z",
+	}, {
+		name:   "in allowed window in timezone",
+		params: InterceptorParams{Timezone: "Europe/Berlin", Allow: businessHours},
+		// 16:59 in Berlin, which is UTC+2 in summer.
+		now: "2022-04-15T14:59:00Z",
+	}, {
+		name:   "outside of denied window",
+		params: InterceptorParams{Deny: []Window{{Days: "Fri", Start: "12:00"}}},
+		now:    "2022-04-15T11:59:59Z",
+	}, {
+		name: "in allowed window spanning midnight",
+		params: InterceptorParams{
+			Allow: []Window{{Days: "Sun", Start: "22:00", End: "06:00"}},
+		},
+		// Monday, in the window opened on Sunday.
+		now: "2022-04-18T05:00:00Z",
+	}, {
+		name: "in allowed window after the clocks go forward",
+		params: InterceptorParams{
+			Timezone: "America/New_York",
+			Allow:    []Window{{Start: "02:30", End: "04:00"}},
+		},
+		// 03:00 EDT, which follows 01:59 EST, so 02:30 never happens on that day.
+		now: "2022-03-13T07:00:00Z",
+	}, {
+		name: "in allowed window in the first repeated hour",
+		params: InterceptorParams{
+			Timezone: "America/New_York",
+			Allow:    []Window{{Start: "01:00", End: "02:00"}},
+		},
+		// 01:30 EDT.
+		now: "2022-11-06T05:30:00Z",
+	}, {
+		name: "in allowed window in the second repeated hour",
+		params: InterceptorParams{
+			Timezone: "America/New_York",
+			Allow:    []Window{{Start: "01:00", End: "02:00"}},
+		},
+		// 01:30 EST.
+		now: "2022-11-06T06:30:00Z",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			now := mustParse(t, tt.now)
+			w := &Interceptor{now: func() time.Time { return now }}
+			res := w.Process(context.Background(), newRequest(tt.params))
+			if !res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be true but got %t. \nStatus.Err(): %v", res.Continue, res.Status.Err())
+			}
+		})
+	}
+}
+
+func TestInterceptor_Process_ShouldNotContinue(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		params   InterceptorParams
+		now      string
+		wantCode codes.Code
+		wantMsg  string
+	}{{
+		name:     "before allowed window",
+		params:   InterceptorParams{Allow: businessHours},
+		now:      "2022-04-15T08:59:00Z",
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "event received at Fri 2022-04-15 08:59:00 +0000 (UTC) is outside of the allowed windows [Mon-Fri 09:00-17:00]",
+	}, {
+		name:     "at the end of the allowed window",
+		params:   InterceptorParams{Allow: businessHours},
+		now:      "2022-04-15T17:00:00Z",
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "outside of the allowed windows",
+	}, {
+		name:     "on a day without allowed window",
+		params:   InterceptorParams{Allow: businessHours},
+		now:      "2022-04-16T12:00:00Z",
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "outside of the allowed windows",
+	}, {
+		name:     "outside of allowed window in timezone",
+		params:   InterceptorParams{Timezone: "Europe/Berlin", Allow: businessHours},
+		now:      "2022-04-15T15:00:00Z",
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "event received at Fri 2022-04-15 17:00:00 +0200 (Europe/Berlin)",
+	}, {
+		name: "in denied window",
+		params: InterceptorParams{
+			Allow: businessHours,
+			Deny:  []Window{{Days: "Fri", Start: "12:00"}},
+		},
+		now:      "2022-04-15T12:00:00Z",
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "is in the denied window Fri 12:00-24:00",
+	}, {
+		name: "in denied window spanning midnight",
+		params: InterceptorParams{
+			Deny: []Window{{Days: "Fri", Start: "18:00", End: "08:00"}},
+		},
+		now:      "2022-04-16T07:59:00Z",
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "is in the denied window Fri 18:00-08:00",
+	}, {
+		name: "in allowed window spanning midnight on the wrong day",
+		params: InterceptorParams{
+			Allow: []Window{{Days: "Sun", Start: "22:00", End: "06:00"}},
+		},
+		// Sunday, before the window opens. The window opened on Saturday doesn't exist.
+		now:      "2022-04-17T05:00:00Z",
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "outside of the allowed windows",
+	}, {
+		name: "before allowed window on the day the clocks go forward",
+		params: InterceptorParams{
+			Timezone: "America/New_York",
+			Allow:    []Window{{Start: "02:30", End: "04:00"}},
+		},
+		// 01:59 EST.
+		now:      "2022-03-13T06:59:00Z",
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "Sun 2022-03-13 01:59:00 -0500 (America/New_York)",
+	}, {
+		name:     "invalid timezone",
+		params:   InterceptorParams{Timezone: "Mars/Olympus_Mons"},
+		now:      "2022-04-15T12:00:00Z",
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `invalid timezone "Mars/Olympus_Mons"`,
+	}, {
+		name:     "invalid window",
+		params:   InterceptorParams{Deny: []Window{{Days: "Mon-Fri"}, {Days: "Someday"}}},
+		now:      "2022-04-15T12:00:00Z",
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `invalid deny[1]: invalid day "Someday"`,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			now := mustParse(t, tt.now)
+			w := &Interceptor{now: func() time.Time { return now }}
+			res := w.Process(context.Background(), newRequest(tt.params))
+			if res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be false but got %t", res.Continue)
+			}
+			if res.Status.Code != tt.wantCode {
+				t.Errorf("Interceptor.Process() got status code %v, want %v", res.Status.Code, tt.wantCode)
+			}
+			if !strings.Contains(res.Status.Message, tt.wantMsg) {
+				t.Errorf("Interceptor.Process() got message %q, want it to contain %q", res.Status.Message, tt.wantMsg)
+			}
+		})
+	}
+}
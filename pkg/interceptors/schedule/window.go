@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const minutesPerDay = 24 * 60
+
+var dayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// window is a parsed Window. Times are in minutes since midnight.
+type window struct {
+	spec  Window
+	days  [7]bool
+	start int
+	end   int
+}
+
+func (w window) String() string {
+	days := w.spec.Days
+	if days == "" {
+		days = "*"
+	}
+	return fmt.Sprintf("%s %s-%s", days, formatMinutes(w.start), formatMinutes(w.end))
+}
+
+// contains returns whether t is in the window. The window is matched against the wall clock time of t
+// in its location, so that it opens and closes at the same local time across DST transitions: on the
+// day of a transition the window is an hour shorter or longer, a window opening or closing in the
+// skipped hour does so when the clocks jump, and the repeated hour matches both times.
+func (w window) contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+	if w.start < w.end {
+		return w.days[day] && minute >= w.start && minute < w.end
+	}
+	// The window spans midnight, so it is either opened today or was opened yesterday.
+	yesterday := (day + 6) % 7
+	return (w.days[day] && minute >= w.start) || (w.days[yesterday] && minute < w.end)
+}
+
+func parseWindows(field string, specs []Window) ([]window, error) {
+	windows := make([]window, 0, len(specs))
+	for i, spec := range specs {
+		w, err := parseWindow(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s[%d]: %w", field, i, err)
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+func parseWindow(spec Window) (window, error) {
+	w := window{spec: spec, end: minutesPerDay}
+	var err error
+	if w.days, err = parseDays(spec.Days); err != nil {
+		return window{}, err
+	}
+	if spec.Start != "" {
+		if w.start, err = parseMinutes(spec.Start); err != nil {
+			return window{}, fmt.Errorf("invalid start: %w", err)
+		}
+		if w.start == minutesPerDay {
+			return window{}, fmt.Errorf("invalid start: %q is the end of the day", spec.Start)
+		}
+	}
+	if spec.End != "" {
+		if w.end, err = parseMinutes(spec.End); err != nil {
+			return window{}, fmt.Errorf("invalid end: %w", err)
+		}
+	}
+	if w.start == w.end {
+		return window{}, fmt.Errorf("start and end are both %s", formatMinutes(w.start))
+	}
+	return w, nil
+}
+
+// parseDays parses a cron day-of-week field: a comma separated list of days or ranges of days, where
+// days are either numbers from 0 (Sunday) to 7 (Sunday again) or three letter names, and "*" is every day.
+func parseDays(field string) ([7]bool, error) {
+	var days [7]bool
+	if field == "" || field == "*" {
+		for i := range days {
+			days[i] = true
+		}
+		return days, nil
+	}
+	for _, part := range strings.Split(field, ",") {
+		first, last := part, part
+		if i := strings.Index(part, "-"); i >= 0 {
+			first, last = part[:i], part[i+1:]
+		}
+		from, err := parseDay(first)
+		if err != nil {
+			return days, err
+		}
+		to, err := parseDay(last)
+		if err != nil {
+			return days, err
+		}
+		if from > to {
+			return days, fmt.Errorf("invalid day range %q", part)
+		}
+		for d := from; d <= to; d++ {
+			days[d%7] = true
+		}
+	}
+	return days, nil
+}
+
+func parseDay(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if d, ok := dayNames[strings.ToLower(s)]; ok {
+		return int(d), nil
+	}
+	d, err := strconv.Atoi(s)
+	if err != nil || d < 0 || d > 7 {
+		return 0, fmt.Errorf("invalid day %q, must be a number from 0 to 7 or a name like Mon", s)
+	}
+	return d, nil
+}
+
+// parseMinutes parses a HH:MM time of day, from 00:00 to 24:00, into minutes since midnight.
+func parseMinutes(s string) (int, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 || len(parts[0]) != 2 || len(parts[1]) != 2 {
+		return 0, fmt.Errorf("time of day %q must be formatted as HH:MM", s)
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("time of day %q must be formatted as HH:MM", s)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("time of day %q must be formatted as HH:MM", s)
+	}
+	m := hours*60 + minutes
+	if hours < 0 || minutes < 0 || minutes > 59 || m > minutesPerDay {
+		return 0, fmt.Errorf("time of day %q must be between 00:00 and 24:00", s)
+	}
+	return m, nil
+}
+
+func formatMinutes(m int) string {
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDays(t *testing.T) {
+	for _, tt := range []struct {
+		field string
+		want  [7]bool
+	}{{
+		field: "",
+		want:  [7]bool{true, true, true, true, true, true, true},
+	}, {
+		field: "*",
+		want:  [7]bool{true, true, true, true, true, true, true},
+	}, {
+		field: "Mon-Fri",
+		want:  [7]bool{false, true, true, true, true, true, false},
+	}, {
+		field: "1-5",
+		want:  [7]bool{false, true, true, true, true, true, false},
+	}, {
+		field: "sat,SUN",
+		want:  [7]bool{true, false, false, false, false, false, true},
+	}, {
+		field: "5-7",
+		want:  [7]bool{true, false, false, false, false, true, true},
+	}, {
+		field: "Mon, Wed-thu",
+		want:  [7]bool{false, true, false, true, true, false, false},
+	}} {
+		t.Run(tt.field, func(t *testing.T) {
+			got, err := parseDays(tt.field)
+			if err != nil {
+				t.Fatalf("parseDays() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("parseDays() (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestParseWindow_Error(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		spec    Window
+		wantErr string
+	}{{
+		name:    "unknown day",
+		spec:    Window{Days: "Monday"},
+		wantErr: `invalid day "Monday"`,
+	}, {
+		name:    "day out of range",
+		spec:    Window{Days: "1-8"},
+		wantErr: `invalid day "8"`,
+	}, {
+		name:    "reversed day range",
+		spec:    Window{Days: "Fri-Mon"},
+		wantErr: `invalid day range "Fri-Mon"`,
+	}, {
+		name:    "malformed time",
+		spec:    Window{Start: "9:00"},
+		wantErr: `invalid start: time of day "9:00" must be formatted as HH:MM`,
+	}, {
+		name:    "time out of range",
+		spec:    Window{End: "24:30"},
+		wantErr: `invalid end: time of day "24:30" must be between 00:00 and 24:00`,
+	}, {
+		name:    "start at the end of the day",
+		spec:    Window{Start: "24:00", End: "06:00"},
+		wantErr: `invalid start: "24:00" is the end of the day`,
+	}, {
+		name:    "empty window",
+		spec:    Window{Start: "09:00", End: "09:00"},
+		wantErr: "start and end are both 09:00",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseWindow(tt.spec)
+			if err == nil {
+				t.Fatalf("parseWindow() expected error %q, got nil", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseWindow() got error %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -18,22 +18,36 @@ package interceptors
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
 	triggersv1beta1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/apimachinery/pkg/util/validation"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"knative.dev/pkg/logging"
 )
 
 const (
 	// cacheSize is the size of the LRU secrets cache
 	cacheSize = 1024
-	// ttl is the time to live for a cache entry
-	ttl = 5 * time.Second
+	// DefaultSecretCacheTTL is the default time to live for a cache entry
+	DefaultSecretCacheTTL = 5 * time.Second
+
+	// AllowedTriggerNamespacesAnnotation is the annotation of a secret listing the comma-separated namespaces
+	// of the triggers that can reference it from another namespace, with the namespace field of a SecretRef.
+	// Secrets without the annotation can only be used by the triggers of their own namespace.
+	AllowedTriggerNamespacesAnnotation = triggers.GroupName + "/allowed-trigger-namespaces"
 )
 
+// ErrSecretNamespaceNotAllowed is returned when a trigger references a secret of another namespace that
+// isn't shared with the namespace of the trigger.
+var ErrSecretNamespaceNotAllowed = errors.New("secret is not shared with the namespace of the trigger")
+
 type SecretGetter interface {
 	Get(ctx context.Context, triggerNS string, sr *triggersv1beta1.SecretRef) ([]byte, error)
 }
@@ -50,11 +64,21 @@ type cacheKey struct {
 }
 
 func DefaultSecretGetter(getter corev1.SecretsGetter) SecretGetter {
-	return &kubeclientSecretGetter{
+	return NewSecretGetter(getter, DefaultSecretCacheTTL)
+}
+
+// NewSecretGetter returns a SecretGetter caching secret values for ttl. Updates to a secret are
+// picked up once its cache entry expires, without restarting the interceptors. A ttl of 0 disables
+// the cache so that secrets are read from Kubernetes on every request.
+func NewSecretGetter(getter corev1.SecretsGetter, ttl time.Duration) SecretGetter {
+	g := &kubeclientSecretGetter{
 		getter: getter,
-		cache:  cache.NewLRUExpireCache(cacheSize),
 		ttl:    ttl,
 	}
+	if ttl > 0 {
+		g.cache = cache.NewLRUExpireCache(cacheSize)
+	}
+	return g
 }
 
 // Get queries Kubernetes for the given secret reference. We use this function
@@ -68,18 +92,104 @@ func (g *kubeclientSecretGetter) Get(ctx context.Context, triggerNS string, sr *
 		triggerNS: triggerNS,
 		sr:        *sr,
 	}
-	val, ok := g.cache.Get(key)
-	if ok {
-		return val.([]byte), nil
+	if g.cache != nil {
+		if val, ok := g.cache.Get(key); ok {
+			return val.([]byte), nil
+		}
+	}
+	namespace, err := SecretNamespace(triggerNS, sr)
+	if err != nil {
+		return nil, err
 	}
-	secret, err := g.getter.Secrets(triggerNS).Get(ctx, sr.SecretName, metav1.GetOptions{})
+	secret, err := g.getter.Secrets(namespace).Get(ctx, sr.SecretName, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
+	if err := CheckSecretAccess(secret, triggerNS); err != nil {
+		return nil, err
+	}
 	secretValue, ok := secret.Data[sr.SecretKey]
 	if !ok {
-		return nil, fmt.Errorf("cannot find %s key in secret %s/%s", sr.SecretKey, triggerNS, sr.SecretName)
+		return nil, fmt.Errorf("cannot find %s key in secret %s/%s", sr.SecretKey, namespace, sr.SecretName)
+	}
+	if g.cache != nil {
+		g.cache.Add(key, secretValue, g.ttl)
 	}
-	g.cache.Add(key, secretValue, g.ttl)
 	return secretValue, nil
 }
+
+// SecretNamespace returns the namespace of the secret referenced by sr for a trigger of triggerNS: the
+// namespace of sr if it is set, or else triggerNS.
+func SecretNamespace(triggerNS string, sr *triggersv1beta1.SecretRef) (string, error) {
+	if sr.Namespace == "" {
+		return triggerNS, nil
+	}
+	if msgs := validation.IsDNS1123Label(sr.Namespace); len(msgs) > 0 {
+		return "", fmt.Errorf("invalid namespace %q of secret %s: %s", sr.Namespace, sr.SecretName, strings.Join(msgs, ", "))
+	}
+	return sr.Namespace, nil
+}
+
+// CheckSecretAccess returns an error wrapping ErrSecretNamespaceNotAllowed if a trigger of triggerNS can't use
+// the secret, i.e. the secret is in another namespace and its AllowedTriggerNamespacesAnnotation doesn't list
+// triggerNS.
+func CheckSecretAccess(secret metav1.Object, triggerNS string) error {
+	if secret.GetNamespace() == triggerNS {
+		return nil
+	}
+	for _, ns := range strings.Split(secret.GetAnnotations()[AllowedTriggerNamespacesAnnotation], ",") {
+		if strings.TrimSpace(ns) == triggerNS {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: secret %s/%s doesn't list namespace %s in its %s annotation", ErrSecretNamespaceNotAllowed, secret.GetNamespace(), secret.GetName(), triggerNS, AllowedTriggerNamespacesAnnotation)
+}
+
+// GetSecrets resolves the primary secret reference followed by any additional ones. Interceptors
+// accept requests signed with any of the returned secrets so that webhook secrets can be rotated
+// without downtime. Only the primary secret is required: the additional ones that can't be resolved,
+// e.g. an old secret deleted before its reference, are skipped with a warning logged to the logger of
+// ctx.
+func GetSecrets(ctx context.Context, sg SecretGetter, triggerNS string, primary *triggersv1beta1.SecretRef, additional []triggersv1beta1.SecretRef) ([][]byte, error) {
+	secrets := make([][]byte, 0, len(additional)+1)
+	secret, err := sg.Get(ctx, triggerNS, primary)
+	if err != nil {
+		return nil, err
+	}
+	secrets = append(secrets, secret)
+	for i := range additional {
+		if additional[i].SecretKey == "" {
+			return nil, fmt.Errorf("additionalSecretRefs[%d].secretKey is empty", i)
+		}
+		secret, err := sg.Get(ctx, triggerNS, &additional[i])
+		if err != nil {
+			logging.FromContext(ctx).Warnf("skipping additionalSecretRefs[%d] %s: %v", i, additional[i].SecretName, err)
+			continue
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets, nil
+}
+
+// ValidateAny calls validate with each of the secrets and returns nil if any of them is valid, or
+// the error for the first secret otherwise. Every secret is checked even after one matches, so
+// the time taken does not reveal which secret matched.
+func ValidateAny(secrets [][]byte, validate func(secret []byte) error) error {
+	if len(secrets) == 0 {
+		return errors.New("no secrets to validate against")
+	}
+	var firstErr error
+	valid := false
+	for i, secret := range secrets {
+		err := validate(secret)
+		if err == nil {
+			valid = true
+		} else if i == 0 {
+			firstErr = err
+		}
+	}
+	if valid {
+		return nil
+	}
+	return firstErr
+}
@@ -18,7 +18,9 @@ package interceptors_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
 	"github.com/tektoncd/triggers/pkg/interceptors"
@@ -67,3 +69,204 @@ func TestCacheSecrets(t *testing.T) {
 		t.Fatalf("Unexpected payload. Got: %s", string(bin))
 	}
 }
+
+func TestNewSecretGetter_Reload(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		ttl  time.Duration
+		want string
+	}{{
+		name: "cached",
+		ttl:  time.Hour,
+		want: "foobar",
+	}, {
+		name: "no cache",
+		ttl:  0,
+		want: "rotated",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := test.SetupFakeContext(t)
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "name",
+					Namespace: "ns",
+				},
+				Data: map[string][]byte{
+					"key": []byte("foobar"),
+				},
+			}
+			_, clientset := fakekubeclient.With(ctx, secret.DeepCopy())
+			getter := interceptors.NewSecretGetter(clientset.CoreV1(), tc.ttl)
+			sr := &triggersv1.SecretRef{SecretKey: "key", SecretName: "name"}
+
+			if _, err := getter.Get(context.Background(), "ns", sr); err != nil {
+				t.Fatalf("Get() unexpected error: %s", err)
+			}
+			secret.Data["key"] = []byte("rotated")
+			if _, err := clientset.CoreV1().Secrets("ns").Update(context.Background(), secret, metav1.UpdateOptions{}); err != nil {
+				t.Fatalf("Cannot update secret: %s", err)
+			}
+
+			bin, err := getter.Get(context.Background(), "ns", sr)
+			if err != nil {
+				t.Fatalf("Get() unexpected error: %s", err)
+			}
+			if string(bin) != tc.want {
+				t.Errorf("Unexpected payload. Got: %s, want: %s", string(bin), tc.want)
+			}
+		})
+	}
+}
+
+func TestGetSecrets(t *testing.T) {
+	ctx, _ := test.SetupFakeContext(t)
+	_, clientset := fakekubeclient.With(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "ns",
+		},
+		Data: map[string][]byte{
+			"new": []byte("new-secret"),
+			"old": []byte("old-secret"),
+		},
+	})
+	getter := interceptors.DefaultSecretGetter(clientset.CoreV1())
+
+	secrets, err := interceptors.GetSecrets(context.Background(), getter, "ns",
+		&triggersv1.SecretRef{SecretName: "name", SecretKey: "new"},
+		[]triggersv1.SecretRef{{SecretName: "name", SecretKey: "old"}})
+	if err != nil {
+		t.Fatalf("GetSecrets() unexpected error: %s", err)
+	}
+	if len(secrets) != 2 || string(secrets[0]) != "new-secret" || string(secrets[1]) != "old-secret" {
+		t.Fatalf("Unexpected secrets. Got: %q", secrets)
+	}
+
+	if _, err := interceptors.GetSecrets(context.Background(), getter, "ns",
+		&triggersv1.SecretRef{SecretName: "name", SecretKey: "new"},
+		[]triggersv1.SecretRef{{SecretName: "name"}}); err == nil {
+		t.Fatal("GetSecrets() expected error for empty secretKey")
+	}
+
+	// The additional secrets that can't be resolved are skipped, but the primary one is required.
+	secrets, err = interceptors.GetSecrets(context.Background(), getter, "ns",
+		&triggersv1.SecretRef{SecretName: "name", SecretKey: "new"},
+		[]triggersv1.SecretRef{{SecretName: "deleted", SecretKey: "old"}, {SecretName: "name", SecretKey: "missing"}, {SecretName: "name", SecretKey: "old"}})
+	if err != nil {
+		t.Fatalf("GetSecrets() unexpected error: %s", err)
+	}
+	if len(secrets) != 2 || string(secrets[0]) != "new-secret" || string(secrets[1]) != "old-secret" {
+		t.Fatalf("Unexpected secrets. Got: %q", secrets)
+	}
+	if _, err := interceptors.GetSecrets(context.Background(), getter, "ns",
+		&triggersv1.SecretRef{SecretName: "deleted", SecretKey: "new"},
+		[]triggersv1.SecretRef{{SecretName: "name", SecretKey: "old"}}); err == nil {
+		t.Fatal("GetSecrets() expected error for a missing primary secret")
+	}
+}
+
+func TestValidateAny(t *testing.T) {
+	errInvalid := errors.New("invalid")
+	tests := []struct {
+		name    string
+		secrets [][]byte
+		want    error
+		calls   int
+	}{{
+		name:    "first secret matches",
+		secrets: [][]byte{[]byte("match"), []byte("other")},
+		calls:   2,
+	}, {
+		name:    "last secret matches",
+		secrets: [][]byte{[]byte("other"), []byte("match")},
+		calls:   2,
+	}, {
+		name:    "no secret matches",
+		secrets: [][]byte{[]byte("other"), []byte("another")},
+		want:    errInvalid,
+		calls:   2,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := interceptors.ValidateAny(tt.secrets, func(secret []byte) error {
+				calls++
+				if string(secret) != "match" {
+					return errInvalid
+				}
+				return nil
+			})
+			if err != tt.want {
+				t.Errorf("ValidateAny() = %v, want %v", err, tt.want)
+			}
+			if calls != tt.calls {
+				t.Errorf("ValidateAny() validated %d secrets, want %d", calls, tt.calls)
+			}
+		})
+	}
+
+	if err := interceptors.ValidateAny(nil, func([]byte) error { return nil }); err == nil {
+		t.Error("ValidateAny() expected error without secrets")
+	}
+}
+
+func TestSecretGetter_Namespace(t *testing.T) {
+	ctx, _ := test.SetupFakeContext(t)
+	_, clientset := fakekubeclient.With(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "shared",
+			Namespace:   "team-a",
+			Annotations: map[string]string{interceptors.AllowedTriggerNamespacesAnnotation: "team-b, team-c"},
+		},
+		Data: map[string][]byte{"key": []byte("shared-secret")},
+	}, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "private",
+			Namespace: "team-a",
+		},
+		Data: map[string][]byte{"key": []byte("private-secret")},
+	})
+	getter := interceptors.NewSecretGetter(clientset.CoreV1(), 0)
+
+	for _, tc := range []struct {
+		name      string
+		triggerNS string
+		sr        triggersv1.SecretRef
+		want      string
+		wantErr   error
+	}{{
+		name:      "shared with the namespace of the trigger",
+		triggerNS: "team-b",
+		sr:        triggersv1.SecretRef{SecretName: "shared", SecretKey: "key", Namespace: "team-a"},
+		want:      "shared-secret",
+	}, {
+		name:      "namespace of the trigger",
+		triggerNS: "team-a",
+		sr:        triggersv1.SecretRef{SecretName: "private", SecretKey: "key", Namespace: "team-a"},
+		want:      "private-secret",
+	}, {
+		name:      "not shared with the namespace of the trigger",
+		triggerNS: "team-d",
+		sr:        triggersv1.SecretRef{SecretName: "shared", SecretKey: "key", Namespace: "team-a"},
+		wantErr:   interceptors.ErrSecretNamespaceNotAllowed,
+	}, {
+		name:      "not shared",
+		triggerNS: "team-b",
+		sr:        triggersv1.SecretRef{SecretName: "private", SecretKey: "key", Namespace: "team-a"},
+		wantErr:   interceptors.ErrSecretNamespaceNotAllowed,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := getter.Get(context.Background(), tc.triggerNS, &tc.sr)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Get() = %v, want error %v", err, tc.wantErr)
+			}
+			if string(got) != tc.want {
+				t.Errorf("Get() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	if _, err := getter.Get(context.Background(), "team-b", &triggersv1.SecretRef{SecretName: "shared", SecretKey: "key", Namespace: "Team_A"}); err == nil {
+		t.Error("Get() with an invalid namespace did not fail")
+	}
+}
@@ -20,14 +20,27 @@ import (
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
 	triggersv1alpha1 "github.com/tektoncd/triggers/pkg/client/clientset/versioned/typed/triggers/v1alpha1"
 	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/pkg/interceptors/audit"
 	"github.com/tektoncd/triggers/pkg/interceptors/bitbucket"
+	"github.com/tektoncd/triggers/pkg/interceptors/bitbucketserver"
+	"github.com/tektoncd/triggers/pkg/interceptors/captcha"
 	"github.com/tektoncd/triggers/pkg/interceptors/cel"
+	"github.com/tektoncd/triggers/pkg/interceptors/consistency"
 	"github.com/tektoncd/triggers/pkg/interceptors/github"
 	"github.com/tektoncd/triggers/pkg/interceptors/gitlab"
+	"github.com/tektoncd/triggers/pkg/interceptors/header"
+	"github.com/tektoncd/triggers/pkg/interceptors/protobuf"
+	"github.com/tektoncd/triggers/pkg/interceptors/require"
+	"github.com/tektoncd/triggers/pkg/interceptors/schedule"
+	"github.com/tektoncd/triggers/pkg/interceptors/shopify"
+	"github.com/tektoncd/triggers/pkg/interceptors/slack"
+	"github.com/tektoncd/triggers/pkg/interceptors/sns"
+	"github.com/tektoncd/triggers/pkg/interceptors/stripe"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	secretInformer "knative.dev/pkg/client/injection/kube/informers/core/v1/secret"
+	"knative.dev/pkg/logging"
 	"knative.dev/pkg/system"
 	certresources "knative.dev/pkg/webhook/certificates/resources"
 )
@@ -58,10 +71,22 @@ func (is *Server) RegisterInterceptor(path string, interceptor triggersv1.Interc
 
 func NewWithCoreInterceptors(sg interceptors.SecretGetter, logger *zap.SugaredLogger) (*Server, error) {
 	i := map[string]triggersv1.InterceptorInterface{
-		"bitbucket": bitbucket.NewInterceptor(sg),
-		"cel":       cel.NewInterceptor(sg),
-		"github":    github.NewInterceptor(sg),
-		"gitlab":    gitlab.NewInterceptor(sg),
+		"audit":            audit.NewInterceptor(),
+		"bitbucket":        bitbucket.NewInterceptor(sg),
+		"bitbucket-server": bitbucketserver.NewInterceptor(sg),
+		"captcha":          captcha.NewInterceptor(sg),
+		"cel":              cel.NewInterceptor(sg),
+		"consistency":      consistency.NewInterceptor(),
+		"github":           github.NewInterceptor(sg),
+		"gitlab":           gitlab.NewInterceptor(sg),
+		"header":           header.NewInterceptor(sg),
+		"protobuf":         protobuf.NewInterceptor(sg),
+		"require":          require.NewInterceptor(),
+		"schedule":         schedule.NewInterceptor(),
+		"shopify":          shopify.NewInterceptor(sg),
+		"slack":            slack.NewInterceptor(sg),
+		"sns":              sns.NewInterceptor(),
+		"stripe":           stripe.NewInterceptor(sg),
 	}
 
 	for k, v := range i {
@@ -135,7 +160,7 @@ func (is *Server) ExecuteInterceptor(r *http.Request) ([]byte, error) {
 	}
 
 	// Create a context
-	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(logging.WithLogger(r.Context(), is.Logger), 3*time.Second)
 	defer cancel()
 
 	var body bytes.Buffer
@@ -147,6 +172,13 @@ func (is *Server) ExecuteInterceptor(r *http.Request) ([]byte, error) {
 	if err := json.Unmarshal(body.Bytes(), &ireq); err != nil {
 		return nil, badRequest(fmt.Errorf("failed to parse body as InterceptorRequest: %w", err))
 	}
+	if ireq.Context != nil && ireq.Context.JSONNumbers == triggersv1.JSONNumbersExact {
+		// Decode the numbers of the extensions exactly, as the EventListener does.
+		ireq = triggersv1.InterceptorRequest{}
+		if err := triggersv1.JSONNumbersExact.Unmarshal(body.Bytes(), &ireq); err != nil {
+			return nil, badRequest(fmt.Errorf("failed to parse body as InterceptorRequest: %w", err))
+		}
+	}
 	is.Logger.Debugf("Interceptor Request is: %+v", ireq)
 	iresp := ii.Process(ctx, &ireq)
 	is.Logger.Infof("Interceptor response is: %+v", iresp)
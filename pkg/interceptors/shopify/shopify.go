@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shopify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"google.golang.org/grpc/codes"
+)
+
+var _ triggersv1.InterceptorInterface = (*Interceptor)(nil)
+
+// InterceptorParams provides a webhook to intercept and pre-process events.
+type InterceptorParams struct {
+	SecretRef            *triggersv1.SecretRef  `json:"secretRef,omitempty"`
+	AdditionalSecretRefs []triggersv1.SecretRef `json:"additionalSecretRefs,omitempty"`
+	// Topics lists the allowed values of the X-Shopify-Topic header, e.g. "orders/create".
+	Topics []string `json:"topics,omitempty"`
+}
+
+// Interceptor verifies Shopify webhook signatures and filters events by topic.
+// Shopify signs webhooks with the app's client secret and sends the base64 encoded
+// HMAC-SHA256 of the body in X-Shopify-Hmac-Sha256.
+type Interceptor struct {
+	SecretGetter interceptors.SecretGetter
+}
+
+func NewInterceptor(sg interceptors.SecretGetter) *Interceptor {
+	return &Interceptor{
+		SecretGetter: sg,
+	}
+}
+
+func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequest) *triggersv1.InterceptorResponse {
+	p := InterceptorParams{}
+	if err := interceptors.UnmarshalParams(r.InterceptorParams, &p); err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "failed to parse interceptor params: %v", err)
+	}
+
+	headers := interceptors.Canonical(r.Header)
+
+	// Check if the topic is in the allow-list
+	if p.Topics != nil {
+		actualTopic := headers.Get("X-Shopify-Topic")
+		isAllowed := false
+		for _, allowedTopic := range p.Topics {
+			if actualTopic == allowedTopic {
+				isAllowed = true
+				break
+			}
+		}
+		if !isAllowed {
+			return interceptors.Failf(codes.FailedPrecondition, "topic %s is not allowed", actualTopic)
+		}
+	}
+
+	// Next validate secrets
+	if p.SecretRef != nil {
+		// Check the secret to see if it is empty
+		if p.SecretRef.SecretKey == "" {
+			return interceptors.Fail(codes.FailedPrecondition, "shopify interceptor secretRef.secretKey is empty")
+		}
+		header := headers.Get("X-Shopify-Hmac-Sha256")
+		if header == "" {
+			return interceptors.Fail(codes.InvalidArgument, "no X-Shopify-Hmac-Sha256 header set")
+		}
+		signature, err := base64.StdEncoding.DecodeString(header)
+		if err != nil {
+			return interceptors.Failf(codes.InvalidArgument, "invalid X-Shopify-Hmac-Sha256 header: %v", err)
+		}
+
+		if r.Context == nil {
+			return interceptors.Failf(codes.InvalidArgument, "no request context passed")
+		}
+
+		ns, _ := triggersv1.ParseTriggerID(r.Context.TriggerID)
+		secretTokens, err := interceptors.GetSecrets(ctx, w.SecretGetter, ns, p.SecretRef, p.AdditionalSecretRefs)
+		if err != nil {
+			return interceptors.Failf(codes.FailedPrecondition, "error getting secret: %v", err)
+		}
+
+		if err := interceptors.ValidateAny(secretTokens, func(secret []byte) error {
+			return validateSignature(signature, interceptors.SignedBody(r), secret)
+		}); err != nil {
+			return interceptors.Fail(codes.FailedPrecondition, err.Error())
+		}
+	}
+
+	return &triggersv1.InterceptorResponse{
+		Continue: true,
+	}
+}
+
+// validateSignature checks that signature is the HMAC-SHA256 of body computed with secretToken.
+func validateSignature(signature []byte, body string, secretToken []byte) error {
+	mac := hmac.New(sha256.New, secretToken)
+	fmt.Fprint(mac, body)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return errors.New("X-Shopify-Hmac-Sha256 does not match the request")
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shopify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/pkg/interceptors/interceptorstest"
+	"github.com/tektoncd/triggers/test"
+	"google.golang.org/grpc/codes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+)
+
+const orderBody = `{"id": 820982911946154508, "email": "jon@example.com", "total_price": "403.00"}`
+
+var (
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mysecret",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Data: map[string][]byte{
+			"token":    []byte("clientsecret"),
+			"newToken": []byte("newclientsecret"),
+		},
+	}
+	secretRef = &triggersv1.SecretRef{SecretName: "mysecret", SecretKey: "token"}
+)
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newRequest(body string, params *InterceptorParams, topic, signature string) *triggersv1.InterceptorRequest {
+	req := interceptorstest.NewRequest(body, http.Header{
+		"Content-Type":          []string{"application/json"},
+		"X-Shopify-Shop-Domain": []string{"example.myshopify.com"},
+	}, map[string]interface{}{
+		"secretRef":            params.SecretRef,
+		"additionalSecretRefs": params.AdditionalSecretRefs,
+		"topics":               params.Topics,
+	})
+	if topic != "" {
+		req.Header["X-Shopify-Topic"] = []string{topic}
+	}
+	if signature != "" {
+		req.Header["X-Shopify-Hmac-Sha256"] = []string{signature}
+	}
+	return req
+}
+
+func TestInterceptor_Process_ShouldContinue(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		params    *InterceptorParams
+		topic     string
+		signature string
+	}{{
+		name:      "valid signature",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		signature: sign("clientsecret", orderBody),
+	}, {
+		name: "signed with additional secret",
+		params: &InterceptorParams{
+			SecretRef:            secretRef,
+			AdditionalSecretRefs: []triggersv1.SecretRef{{SecretName: "mysecret", SecretKey: "newToken"}},
+		},
+		signature: sign("newclientsecret", orderBody),
+	}, {
+		name:      "allowed topic",
+		params:    &InterceptorParams{SecretRef: secretRef, Topics: []string{"orders/create", "orders/paid"}},
+		topic:     "orders/paid",
+		signature: sign("clientsecret", orderBody),
+	}, {
+		name:   "no secret",
+		params: &InterceptorParams{},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, secret)
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+
+			res := w.Process(ctx, newRequest(orderBody, tc.params, tc.topic, tc.signature))
+			if !res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be true but got %t. \nStatus.Err(): %v", res.Continue, res.Status.Err())
+			}
+		})
+	}
+}
+
+func TestInterceptor_Process_ShouldNotContinue(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		params    *InterceptorParams
+		body      string
+		topic     string
+		signature string
+		wantCode  codes.Code
+		wantMsg   string
+	}{{
+		name:      "invalid signature",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      orderBody,
+		signature: sign("othersecret", orderBody),
+		wantCode:  codes.FailedPrecondition,
+		wantMsg:   "X-Shopify-Hmac-Sha256 does not match the request",
+	}, {
+		name:      "signature over different body",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      `{"id": 1}`,
+		signature: sign("clientsecret", orderBody),
+		wantCode:  codes.FailedPrecondition,
+		wantMsg:   "X-Shopify-Hmac-Sha256 does not match the request",
+	}, {
+		name:     "missing signature",
+		params:   &InterceptorParams{SecretRef: secretRef},
+		body:     orderBody,
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "no X-Shopify-Hmac-Sha256 header set",
+	}, {
+		name:      "hex encoded signature",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      orderBody,
+		signature: "sha256=abcdef",
+		wantCode:  codes.InvalidArgument,
+		wantMsg:   "invalid X-Shopify-Hmac-Sha256 header",
+	}, {
+		name:      "empty secret key",
+		params:    &InterceptorParams{SecretRef: &triggersv1.SecretRef{SecretName: "mysecret"}},
+		body:      orderBody,
+		signature: sign("clientsecret", orderBody),
+		wantCode:  codes.FailedPrecondition,
+		wantMsg:   "shopify interceptor secretRef.secretKey is empty",
+	}, {
+		name:      "topic not allowed",
+		params:    &InterceptorParams{SecretRef: secretRef, Topics: []string{"orders/create"}},
+		body:      orderBody,
+		topic:     "customers/redact",
+		signature: sign("clientsecret", orderBody),
+		wantCode:  codes.FailedPrecondition,
+		wantMsg:   "topic customers/redact is not allowed",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, secret)
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+
+			res := w.Process(ctx, newRequest(tc.body, tc.params, tc.topic, tc.signature))
+			if res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be false but got %t", res.Continue)
+			}
+			if res.Status.Code != tc.wantCode {
+				t.Errorf("Interceptor.Process() got status code %v, want %v", res.Status.Code, tc.wantCode)
+			}
+			if !strings.Contains(res.Status.Message, tc.wantMsg) {
+				t.Errorf("Interceptor.Process() got message %q, want it to contain %q", res.Status.Message, tc.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"google.golang.org/grpc/codes"
+)
+
+var _ triggersv1.InterceptorInterface = (*Interceptor)(nil)
+
+const (
+	// signatureVersion is the version prefix of the Slack signature and base string.
+	signatureVersion = "v0"
+	// replayWindow is the maximum age of a request timestamp, as recommended by Slack.
+	replayWindow = 5 * time.Minute
+)
+
+// InterceptorParams provides a webhook to intercept and pre-process events.
+type InterceptorParams struct {
+	SecretRef *triggersv1.SecretRef `json:"secretRef,omitempty"`
+	Commands  []string              `json:"commands,omitempty"`
+}
+
+// Interceptor verifies Slack request signatures and filters slash commands.
+// Slack signs requests with its signing secret over the base string
+// "v0:<X-Slack-Request-Timestamp>:<body>" and sends the result in X-Slack-Signature.
+// Slack sends command payloads as forms, which the form payload parser of the EventListener
+// converts into the JSON body, keeping the form as the raw body the signature is verified over.
+type Interceptor struct {
+	SecretGetter interceptors.SecretGetter
+
+	now func() time.Time
+}
+
+func NewInterceptor(sg interceptors.SecretGetter) *Interceptor {
+	return &Interceptor{
+		SecretGetter: sg,
+		now:          time.Now,
+	}
+}
+
+func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequest) *triggersv1.InterceptorResponse {
+	p := InterceptorParams{}
+	if err := interceptors.UnmarshalParams(r.InterceptorParams, &p); err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "failed to parse interceptor params: %v", err)
+	}
+
+	headers := interceptors.Canonical(r.Header)
+
+	// Validate the signature first so that unauthenticated payloads are never parsed
+	if p.SecretRef != nil {
+		// Check the secret to see if it is empty
+		if p.SecretRef.SecretKey == "" {
+			return interceptors.Fail(codes.FailedPrecondition, "slack interceptor secretRef.secretKey is empty")
+		}
+		signature := headers.Get("X-Slack-Signature")
+		if signature == "" {
+			return interceptors.Fail(codes.InvalidArgument, "no X-Slack-Signature header set")
+		}
+		timestamp := headers.Get("X-Slack-Request-Timestamp")
+		if timestamp == "" {
+			return interceptors.Fail(codes.InvalidArgument, "no X-Slack-Request-Timestamp header set")
+		}
+		if err := w.checkTimestamp(timestamp); err != nil {
+			return interceptors.Fail(codes.FailedPrecondition, err.Error())
+		}
+
+		if r.Context == nil {
+			return interceptors.Failf(codes.InvalidArgument, "no request context passed")
+		}
+
+		ns, _ := triggersv1.ParseTriggerID(r.Context.TriggerID)
+		secretToken, err := w.SecretGetter.Get(ctx, ns, p.SecretRef)
+		if err != nil {
+			return interceptors.Failf(codes.FailedPrecondition, "error getting secret: %v", err)
+		}
+
+		if err := validateSignature(signature, timestamp, interceptors.SignedBody(r), secretToken); err != nil {
+			return interceptors.Fail(codes.FailedPrecondition, err.Error())
+		}
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(r.Body), &payload); err != nil || payload == nil {
+		return interceptors.Failf(codes.InvalidArgument, "body is not a Slack command payload converted to JSON, add form to the %s annotation of the EventListener", triggers.PayloadParsersAnnotation)
+	}
+
+	// Check if the command is in the allow-list
+	if p.Commands != nil {
+		command, _ := payload["command"].(string)
+		isAllowed := false
+		for _, allowedCommand := range p.Commands {
+			if command == allowedCommand {
+				isAllowed = true
+				break
+			}
+		}
+		if !isAllowed {
+			return interceptors.Failf(codes.FailedPrecondition, "command %s is not allowed", command)
+		}
+	}
+
+	return &triggersv1.InterceptorResponse{
+		Continue: true,
+	}
+}
+
+// checkTimestamp rejects requests whose timestamp is outside of the replay window.
+func (w *Interceptor) checkTimestamp(timestamp string) error {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid X-Slack-Request-Timestamp header %q: %w", timestamp, err)
+	}
+	age := w.now().Sub(time.Unix(sec, 0))
+	if age < 0 {
+		age = -age
+	}
+	if age > replayWindow {
+		return fmt.Errorf("request timestamp %s is outside of the %s replay window", timestamp, replayWindow)
+	}
+	return nil
+}
+
+// validateSignature checks that signature is the HMAC-SHA256 of the Slack base string computed with secretToken.
+func validateSignature(signature, timestamp, body string, secretToken []byte) error {
+	if !strings.HasPrefix(signature, signatureVersion+"=") {
+		return fmt.Errorf("unsupported X-Slack-Signature version, expected %s", signatureVersion)
+	}
+	actual, err := hex.DecodeString(strings.TrimPrefix(signature, signatureVersion+"="))
+	if err != nil {
+		return fmt.Errorf("invalid X-Slack-Signature header: %w", err)
+	}
+	mac := hmac.New(sha256.New, secretToken)
+	fmt.Fprintf(mac, "%s:%s:%s", signatureVersion, timestamp, body)
+	if !hmac.Equal(actual, mac.Sum(nil)) {
+		return fmt.Errorf("X-Slack-Signature does not match the request")
+	}
+	return nil
+}
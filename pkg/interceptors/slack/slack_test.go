@@ -0,0 +1,205 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/pkg/interceptors/interceptorstest"
+	"github.com/tektoncd/triggers/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+)
+
+const (
+	commandBody = "token=xyz&team_domain=example&channel_id=C123&user_name=alice&command=%2Fdeploy&text=api+staging&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2F1"
+	// commandJSON is commandBody converted by the form payload parser.
+	commandJSON = `{"channel_id":"C123","command":"/deploy","response_url":"https://hooks.slack.com/commands/1","team_domain":"example","text":"api staging","token":"xyz","user_name":"alice"}`
+)
+
+var (
+	now = time.Unix(1650000000, 0)
+
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mysecret",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Data: map[string][]byte{
+			"token": []byte("signingsecret"),
+		},
+	}
+)
+
+func sign(secret, timestamp, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newRequest(body, rawBody string, params *InterceptorParams, timestamp, signature string) *triggersv1.InterceptorRequest {
+	req := interceptorstest.NewRequest(body, http.Header{"Content-Type": []string{"application/json"}}, map[string]interface{}{
+		"commands":  params.Commands,
+		"secretRef": params.SecretRef,
+	})
+	req.RawBody = rawBody
+	if timestamp != "" {
+		req.Header["X-Slack-Request-Timestamp"] = []string{timestamp}
+	}
+	if signature != "" {
+		req.Header["X-Slack-Signature"] = []string{signature}
+	}
+	return req
+}
+
+func TestInterceptor_Process_ShouldContinue(t *testing.T) {
+	secretRef := &triggersv1.SecretRef{SecretName: "mysecret", SecretKey: "token"}
+	ts := strconv.FormatInt(now.Unix(), 10)
+	for _, tc := range []struct {
+		name      string
+		params    *InterceptorParams
+		timestamp string
+		signature string
+	}{{
+		name:      "valid signature",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		timestamp: ts,
+		signature: sign("signingsecret", ts, commandBody),
+	}, {
+		name:      "valid signature within replay window",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		timestamp: strconv.FormatInt(now.Add(-4*time.Minute).Unix(), 10),
+		signature: sign("signingsecret", strconv.FormatInt(now.Add(-4*time.Minute).Unix(), 10), commandBody),
+	}, {
+		name:   "allowed command",
+		params: &InterceptorParams{Commands: []string{"/build", "/deploy"}},
+	}, {
+		name:   "no secret",
+		params: &InterceptorParams{},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, secret)
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+			w.now = func() time.Time { return now }
+
+			res := w.Process(ctx, newRequest(commandJSON, commandBody, tc.params, tc.timestamp, tc.signature))
+			if !res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be true but got %t. \nStatus.Err(): %v", res.Continue, res.Status.Err())
+			}
+		})
+	}
+}
+
+func TestInterceptor_Process_ShouldNotContinue(t *testing.T) {
+	secretRef := &triggersv1.SecretRef{SecretName: "mysecret", SecretKey: "token"}
+	ts := strconv.FormatInt(now.Unix(), 10)
+	old := strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10)
+	for _, tc := range []struct {
+		name      string
+		params    *InterceptorParams
+		body      string
+		rawBody   string
+		timestamp string
+		signature string
+	}{{
+		name:      "invalid signature",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      commandJSON,
+		rawBody:   commandBody,
+		timestamp: ts,
+		signature: sign("othersecret", ts, commandBody),
+	}, {
+		name:      "signature over different body",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      commandJSON,
+		rawBody:   commandBody + "&extra=1",
+		timestamp: ts,
+		signature: sign("signingsecret", ts, commandBody),
+	}, {
+		name:      "missing signature",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      commandJSON,
+		rawBody:   commandBody,
+		timestamp: ts,
+	}, {
+		name:      "missing timestamp",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      commandJSON,
+		rawBody:   commandBody,
+		signature: sign("signingsecret", ts, commandBody),
+	}, {
+		name:      "replayed request",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      commandJSON,
+		rawBody:   commandBody,
+		timestamp: old,
+		signature: sign("signingsecret", old, commandBody),
+	}, {
+		name:      "invalid timestamp",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      commandJSON,
+		rawBody:   commandBody,
+		timestamp: "yesterday",
+		signature: sign("signingsecret", "yesterday", commandBody),
+	}, {
+		name:      "unsupported signature version",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      commandJSON,
+		rawBody:   commandBody,
+		timestamp: ts,
+		signature: "v1=abcdef",
+	}, {
+		name:    "command not allowed",
+		params:  &InterceptorParams{Commands: []string{"/build"}},
+		body:    commandJSON,
+		rawBody: commandBody,
+	}, {
+		name:   "body not converted by the form payload parser",
+		params: &InterceptorParams{},
+		body:   commandBody,
+	}, {
+		name:      "signature over the converted body",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      commandJSON,
+		rawBody:   commandBody,
+		timestamp: ts,
+		signature: sign("signingsecret", ts, commandJSON),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, secret)
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+			w.now = func() time.Time { return now }
+
+			res := w.Process(ctx, newRequest(tc.body, tc.rawBody, tc.params, tc.timestamp, tc.signature))
+			if res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be false but got %t", res.Continue)
+			}
+		})
+	}
+}
@@ -0,0 +1,401 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sns
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1" // #nosec G505 -- SNS signature version 1 is SHA1withRSA.
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"google.golang.org/grpc/codes"
+)
+
+var _ triggersv1.InterceptorInterface = (*Interceptor)(nil)
+
+const (
+	// ExtensionKey is the extensions key under which the fields of the notification are returned.
+	ExtensionKey = "sns"
+
+	// The values of the x-amz-sns-message-type header and of the Type field of messages.
+	typeNotification             = "Notification"
+	typeSubscriptionConfirmation = "SubscriptionConfirmation"
+	typeUnsubscribeConfirmation  = "UnsubscribeConfirmation"
+
+	// fetchTimeout bounds the requests for signing certificates and subscription confirmations.
+	fetchTimeout = 10 * time.Second
+	// maxCertSize bounds the size of the signing certificates read.
+	maxCertSize = 64 * 1024
+	// maxCachedCerts bounds the number of signing certificates cached. SNS only uses a few of them.
+	maxCachedCerts = 64
+	// defaultMaxAge is the maximum age of the timestamp of messages: SNS stops redelivering a message to an
+	// HTTPS endpoint an hour after it was published.
+	defaultMaxAge = time.Hour
+)
+
+// snsHostRegex matches the hosts of the SNS endpoints of the AWS regions, including those in China.
+var snsHostRegex = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// InterceptorParams provides a webhook to intercept and pre-process events.
+type InterceptorParams struct {
+	// TopicArns lists the ARNs of the topics whose messages are accepted. Subscriptions are only
+	// confirmed for those topics.
+	TopicArns []string `json:"topicArns,omitempty"`
+	// MaxAge is the maximum age of the Timestamp of messages, as a duration, so that captured messages
+	// can't be replayed. Defaults to 1h.
+	MaxAge string `json:"maxAge,omitempty"`
+}
+
+// message is the JSON document SNS sends in the body of HTTP(S) deliveries.
+type message struct {
+	Type              string                      `json:"Type"`
+	MessageID         string                      `json:"MessageId"`
+	Token             string                      `json:"Token,omitempty"`
+	TopicArn          string                      `json:"TopicArn"`
+	Subject           *string                     `json:"Subject,omitempty"`
+	Message           string                      `json:"Message"`
+	Timestamp         string                      `json:"Timestamp"`
+	SignatureVersion  string                      `json:"SignatureVersion"`
+	Signature         string                      `json:"Signature"`
+	SigningCertURL    string                      `json:"SigningCertURL"`
+	SubscribeURL      string                      `json:"SubscribeURL,omitempty"`
+	MessageAttributes map[string]messageAttribute `json:"MessageAttributes,omitempty"`
+}
+
+type messageAttribute struct {
+	Type  string `json:"Type"`
+	Value string `json:"Value"`
+}
+
+// Interceptor verifies the signatures of Amazon SNS messages, confirms the subscriptions to the
+// allowed topics and unwraps the Message field of notifications.
+// SNS signs the fields of messages with the private key of the certificate at SigningCertURL, as
+// described in https://docs.aws.amazon.com/sns/latest/dg/sns-verify-signature-of-message.html.
+type Interceptor struct {
+	client *http.Client
+	// isSNSHost reports whether the signing certificates and subscription URLs of messages are served by SNS.
+	isSNSHost func(host string) bool
+	now       func() time.Time
+
+	mu    sync.Mutex
+	certs map[string]*x509.Certificate
+}
+
+func NewInterceptor() *Interceptor {
+	return &Interceptor{
+		client: &http.Client{
+			Timeout: fetchTimeout,
+			// The URLs are checked to be SNS endpoints, which a redirect would get around.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		isSNSHost: snsHostRegex.MatchString,
+		now:       time.Now,
+		certs:     map[string]*x509.Certificate{},
+	}
+}
+
+func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequest) *triggersv1.InterceptorResponse {
+	p := InterceptorParams{}
+	if err := interceptors.UnmarshalParams(r.InterceptorParams, &p); err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "failed to parse interceptor params: %v", err)
+	}
+	maxAge := defaultMaxAge
+	if p.MaxAge != "" {
+		d, err := time.ParseDuration(p.MaxAge)
+		if err != nil || d <= 0 {
+			return interceptors.Failf(codes.InvalidArgument, "invalid maxAge %q: must be a positive duration", p.MaxAge)
+		}
+		maxAge = d
+	}
+
+	m := message{}
+	if err := json.Unmarshal([]byte(r.Body), &m); err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "failed to parse body as an SNS message: %v", err)
+	}
+	// The header is missing from SNS messages relayed by other means, such as an SQS queue.
+	headers := interceptors.Canonical(r.Header)
+	if t := headers.Get("X-Amz-Sns-Message-Type"); t != "" && t != m.Type {
+		return interceptors.Failf(codes.InvalidArgument, "x-amz-sns-message-type header %s doesn't match the message type %s", t, m.Type)
+	}
+
+	// Validate the signature first so that unauthenticated messages are never acted upon
+	if err := w.verify(ctx, m); err != nil {
+		var unavailable *unavailableError
+		if errors.As(err, &unavailable) {
+			return interceptors.Fail(codes.Unavailable, err.Error())
+		}
+		return interceptors.Fail(codes.FailedPrecondition, err.Error())
+	}
+	// The timestamp is signed, so it can't be refreshed to replay a message.
+	if err := w.checkTimestamp(m.Timestamp, maxAge); err != nil {
+		return interceptors.Fail(codes.FailedPrecondition, err.Error())
+	}
+
+	// Check if the topic is in the allow-list
+	if p.TopicArns != nil && !interceptors.Contains(p.TopicArns, m.TopicArn) {
+		return interceptors.Failf(codes.FailedPrecondition, "topic %s is not allowed", m.TopicArn)
+	}
+
+	switch m.Type {
+	case typeNotification:
+	case typeSubscriptionConfirmation:
+		// Otherwise anyone could subscribe the EventListener to their own topic.
+		if p.TopicArns == nil {
+			return interceptors.Failf(codes.FailedPrecondition, "not confirming the subscription to topic %s: topicArns must list the topics to subscribe to", m.TopicArn)
+		}
+		// Subscriptions are confirmed by visiting the SubscribeURL of the message.
+		u, err := w.snsURL(m.SubscribeURL)
+		if err != nil {
+			return interceptors.Failf(codes.FailedPrecondition, "invalid SubscribeURL: %v", err)
+		}
+		if _, err := w.get(ctx, u, 0); err != nil {
+			return interceptors.Failf(codes.Unavailable, "failed to confirm the subscription to topic %s: %v", m.TopicArn, err)
+		}
+		return stop(fmt.Sprintf("confirmed the subscription to topic %s", m.TopicArn))
+	case typeUnsubscribeConfirmation:
+		return stop(fmt.Sprintf("unsubscribed from topic %s", m.TopicArn))
+	default:
+		return interceptors.Failf(codes.InvalidArgument, "unsupported SNS message type %q", m.Type)
+	}
+
+	// The message is JSON for most AWS services and for structured notifications, but can be any text.
+	var body interface{} = m.Message
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(m.Message), &parsed); err == nil {
+		body = parsed
+	}
+	attributes := make(map[string]interface{}, len(m.MessageAttributes))
+	for k, a := range m.MessageAttributes {
+		attributes[k] = a.Value
+	}
+	fields := map[string]interface{}{
+		"message":           body,
+		"messageId":         m.MessageID,
+		"topicArn":          m.TopicArn,
+		"timestamp":         m.Timestamp,
+		"messageAttributes": attributes,
+	}
+	if m.Subject != nil {
+		fields["subject"] = *m.Subject
+	}
+	return &triggersv1.InterceptorResponse{
+		Continue: true,
+		Extensions: map[string]interface{}{
+			ExtensionKey: fields,
+		},
+	}
+}
+
+// stop stops the processing of handshake messages, which aren't events.
+func stop(msg string) *triggersv1.InterceptorResponse {
+	return &triggersv1.InterceptorResponse{
+		Continue: false,
+		Status: triggersv1.Status{
+			Code:    codes.OK,
+			Message: msg,
+		},
+	}
+}
+
+// unavailableError is a failure to reach SNS, after which the message can be delivered again.
+type unavailableError struct {
+	err error
+}
+
+func (e *unavailableError) Error() string {
+	return e.err.Error()
+}
+
+// verify checks the signature of m against the certificate at its SigningCertURL.
+func (w *Interceptor) verify(ctx context.Context, m message) error {
+	var hash crypto.Hash
+	switch m.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return fmt.Errorf("unsupported SNS signature version %q", m.SignatureVersion)
+	}
+	signature, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid SNS message signature: %w", err)
+	}
+	payload, err := signedPayload(m)
+	if err != nil {
+		return err
+	}
+	cert, err := w.certificate(ctx, m.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("SNS signing certificate doesn't have an RSA public key")
+	}
+	var digest []byte
+	if hash == crypto.SHA1 {
+		sum := sha1.Sum(payload) // #nosec G401 -- SNS signature version 1 is SHA1withRSA.
+		digest = sum[:]
+	} else {
+		sum := sha256.Sum256(payload)
+		digest = sum[:]
+	}
+	if err := rsa.VerifyPKCS1v15(key, hash, digest, signature); err != nil {
+		return errors.New("SNS message signature does not match the message")
+	}
+	return nil
+}
+
+// checkTimestamp rejects messages whose timestamp is more than maxAge away from the current time.
+func (w *Interceptor) checkTimestamp(timestamp string, maxAge time.Duration) error {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return fmt.Errorf("invalid SNS message timestamp %q: %w", timestamp, err)
+	}
+	age := w.now().Sub(t)
+	if age < 0 {
+		age = -age
+	}
+	if age > maxAge {
+		return fmt.Errorf("SNS message timestamp %s is outside of the %s maximum age", timestamp, maxAge)
+	}
+	return nil
+}
+
+// signedPayload returns the string SNS signs for a message: the names and values of some of its
+// fields, in alphabetical order, each followed by a newline.
+func signedPayload(m message) ([]byte, error) {
+	var fields [][2]string
+	switch m.Type {
+	case typeNotification:
+		fields = [][2]string{{"Message", m.Message}, {"MessageId", m.MessageID}}
+		if m.Subject != nil {
+			fields = append(fields, [2]string{"Subject", *m.Subject})
+		}
+		fields = append(fields, [][2]string{{"Timestamp", m.Timestamp}, {"TopicArn", m.TopicArn}, {"Type", m.Type}}...)
+	case typeSubscriptionConfirmation, typeUnsubscribeConfirmation:
+		fields = [][2]string{{"Message", m.Message}, {"MessageId", m.MessageID}, {"SubscribeURL", m.SubscribeURL},
+			{"Timestamp", m.Timestamp}, {"Token", m.Token}, {"TopicArn", m.TopicArn}, {"Type", m.Type}}
+	default:
+		return nil, fmt.Errorf("unsupported SNS message type %q", m.Type)
+	}
+	var b strings.Builder
+	for _, f := range fields {
+		fmt.Fprintf(&b, "%s\n%s\n", f[0], f[1])
+	}
+	return []byte(b.String()), nil
+}
+
+// certificate returns the signing certificate at rawURL, which must be served by SNS over HTTPS.
+func (w *Interceptor) certificate(ctx context.Context, rawURL string) (*x509.Certificate, error) {
+	u, err := w.snsURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SigningCertURL: %w", err)
+	}
+	if !strings.HasSuffix(u.Path, ".pem") {
+		return nil, fmt.Errorf("invalid SigningCertURL %s: not a PEM certificate", rawURL)
+	}
+	w.mu.Lock()
+	cert, ok := w.certs[u.String()]
+	w.mu.Unlock()
+	if ok {
+		return cert, nil
+	}
+
+	b, err := w.get(ctx, u, maxCertSize)
+	if err != nil {
+		return nil, &unavailableError{err: fmt.Errorf("failed to get the SNS signing certificate: %w", err)}
+	}
+	block, _ := pem.Decode(b)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("SNS signing certificate at %s is not a PEM certificate", rawURL)
+	}
+	cert, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the SNS signing certificate: %w", err)
+	}
+
+	w.mu.Lock()
+	if len(w.certs) >= maxCachedCerts {
+		w.certs = map[string]*x509.Certificate{}
+	}
+	w.certs[u.String()] = cert
+	w.mu.Unlock()
+	return cert, nil
+}
+
+// snsURL parses rawURL and checks that it is an HTTPS URL of an SNS endpoint, so that messages
+// can't make the interceptor fetch URLs of their choice.
+func (w *Interceptor) snsURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" || u.User != nil || !w.isSNSHost(u.Host) {
+		return nil, fmt.Errorf("%s is not an HTTPS URL of Amazon SNS", rawURL)
+	}
+	return u, nil
+}
+
+// get returns up to limit bytes of the body of a GET request to u, or discards it if limit is 0.
+func (w *Interceptor) get(ctx context.Context, u *url.URL, limit int64) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	// The query of subscription URLs holds the subscription token, which is left out of errors.
+	endpoint := u.Scheme + "://" + u.Host + u.Path
+	resp, err := w.client.Do(req) // #nosec G107 -- only SNS endpoints are requested.
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("GET %s failed: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned %s", endpoint, resp.Status)
+	}
+	if limit == 0 {
+		_, err = io.Copy(ioutil.Discard, resp.Body)
+		return nil, err
+	}
+	return ioutil.ReadAll(io.LimitReader(resp.Body, limit))
+}
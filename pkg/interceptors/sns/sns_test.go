@@ -0,0 +1,417 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sns
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors/interceptorstest"
+	"google.golang.org/grpc/codes"
+)
+
+const topicArn = "arn:aws:sns:us-east-1:123456789012:builds"
+
+// now is the current time of the interceptors, a few minutes after the messages were sent.
+var now = time.Date(2022, 5, 12, 19, 30, 0, 0, time.UTC)
+
+// fakeSNS serves a signing certificate and records the subscriptions confirmed.
+type fakeSNS struct {
+	*httptest.Server
+	key       *rsa.PrivateKey
+	confirmed []string
+	certGets  int
+}
+
+func newFakeSNS(t *testing.T) *fakeSNS {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	f := &fakeSNS{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/cert.pem", func(w http.ResponseWriter, r *http.Request) {
+		f.certGets++
+		_, _ = w.Write(cert)
+	})
+	mux.HandleFunc("/redirect.pem", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/cert.pem", http.StatusFound)
+	})
+	mux.HandleFunc("/confirm", func(w http.ResponseWriter, r *http.Request) {
+		f.confirmed = append(f.confirmed, r.URL.Query().Get("Token"))
+	})
+	f.Server = httptest.NewTLSServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeSNS) interceptor() *Interceptor {
+	w := NewInterceptor()
+	// The client of the interceptor, trusting the certificate of the server.
+	w.client.Transport = f.Client().Transport
+	host := strings.TrimPrefix(f.URL, "https://")
+	w.isSNSHost = func(h string) bool { return h == host }
+	w.now = func() time.Time { return now }
+	return w
+}
+
+// sign sets the signature fields of m, signing it with the key of the certificate of f.
+func (f *fakeSNS) sign(t *testing.T, m *message, version string) {
+	t.Helper()
+	m.SignatureVersion = version
+	m.SigningCertURL = f.URL + "/cert.pem"
+	payload, err := signedPayload(*m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, digest := crypto.SHA256, sha256.Sum256(payload)
+	var d []byte = digest[:]
+	if version == "1" {
+		sum := sha1.Sum(payload)
+		hash, d = crypto.SHA1, sum[:]
+	}
+	signature, err := rsa.SignPKCS1v15(rand.Reader, f.key, hash, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Signature = base64.StdEncoding.EncodeToString(signature)
+}
+
+func notification(msg string) *message {
+	subject := "Build finished"
+	return &message{
+		Type:      typeNotification,
+		MessageID: "22b80b92-fdea-4c2c-8f9d-bdfb0c7bf324",
+		TopicArn:  topicArn,
+		Subject:   &subject,
+		Message:   msg,
+		Timestamp: "2022-05-12T19:23:45.123Z",
+		MessageAttributes: map[string]messageAttribute{
+			"branch": {Type: "String", Value: "main"},
+		},
+	}
+}
+
+func (f *fakeSNS) subscriptionConfirmation(typ string) *message {
+	return &message{
+		Type:         typ,
+		MessageID:    "165545c9-2a5c-472c-8df2-7ff2be2b3b1b",
+		Token:        "2336412f37",
+		TopicArn:     topicArn,
+		Message:      "You have chosen to subscribe to the topic " + topicArn,
+		SubscribeURL: f.URL + "/confirm?Action=ConfirmSubscription&Token=2336412f37",
+		Timestamp:    "2022-05-12T19:20:00.000Z",
+	}
+}
+
+func request(t *testing.T, m *message, params map[string]interface{}) *triggersv1.InterceptorRequest {
+	t.Helper()
+	body, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return interceptorstest.NewRequest(string(body), http.Header{
+		"Content-Type":           []string{"text/plain; charset=UTF-8"},
+		"X-Amz-Sns-Message-Type": []string{m.Type},
+	}, params)
+}
+
+func TestInterceptor_Process_Notification(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		version string
+		message string
+		params  map[string]interface{}
+		want    interface{}
+	}{{
+		name:    "JSON message",
+		version: "1",
+		message: `{"build": {"id": 42, "status": "SUCCEEDED"}}`,
+		want:    map[string]interface{}{"build": map[string]interface{}{"id": float64(42), "status": "SUCCEEDED"}},
+	}, {
+		name:    "text message with signature version 2",
+		version: "2",
+		message: "build 42 succeeded",
+		params:  map[string]interface{}{"topicArns": []string{"arn:aws:sns:us-east-1:123456789012:other", topicArn}},
+		want:    "build 42 succeeded",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFakeSNS(t)
+			m := notification(tc.message)
+			f.sign(t, m, tc.version)
+
+			res := f.interceptor().Process(context.Background(), request(t, m, tc.params))
+			if !res.Continue {
+				t.Fatalf("Process() stopped with status %v", res.Status)
+			}
+			want := map[string]interface{}{
+				ExtensionKey: map[string]interface{}{
+					"message":           tc.want,
+					"messageId":         m.MessageID,
+					"topicArn":          topicArn,
+					"subject":           "Build finished",
+					"timestamp":         m.Timestamp,
+					"messageAttributes": map[string]interface{}{"branch": "main"},
+				},
+			}
+			if diff := cmp.Diff(want, res.Extensions); diff != "" {
+				t.Errorf("Process() extensions -want/+got: %s", diff)
+			}
+		})
+	}
+}
+
+func TestInterceptor_Process_CachesCertificates(t *testing.T) {
+	f := newFakeSNS(t)
+	w := f.interceptor()
+	for i := 0; i < 2; i++ {
+		m := notification("hello")
+		f.sign(t, m, "2")
+		if res := w.Process(context.Background(), request(t, m, nil)); !res.Continue {
+			t.Fatalf("Process() stopped with status %v", res.Status)
+		}
+	}
+	if f.certGets != 1 {
+		t.Errorf("got the certificate %d times, want 1", f.certGets)
+	}
+}
+
+func TestInterceptor_Process_SubscriptionConfirmation(t *testing.T) {
+	f := newFakeSNS(t)
+	m := f.subscriptionConfirmation(typeSubscriptionConfirmation)
+	f.sign(t, m, "1")
+
+	res := f.interceptor().Process(context.Background(), request(t, m, map[string]interface{}{"topicArns": []string{topicArn}}))
+	if res.Continue {
+		t.Fatal("Process() continued processing a subscription confirmation")
+	}
+	if res.Status.Code != codes.OK {
+		t.Errorf("Process() got status %v, want an OK status", res.Status)
+	}
+	if diff := cmp.Diff([]string{"2336412f37"}, f.confirmed); diff != "" {
+		t.Errorf("confirmed subscriptions -want/+got: %s", diff)
+	}
+}
+
+func TestInterceptor_Process_UnsubscribeConfirmation(t *testing.T) {
+	f := newFakeSNS(t)
+	m := f.subscriptionConfirmation(typeUnsubscribeConfirmation)
+	f.sign(t, m, "2")
+
+	res := f.interceptor().Process(context.Background(), request(t, m, nil))
+	if res.Continue || res.Status.Code != codes.OK {
+		t.Errorf("Process() got continue %t and status %v, want it to stop with an OK status", res.Continue, res.Status)
+	}
+	if len(f.confirmed) != 0 {
+		t.Errorf("got %d confirmed subscriptions, want none", len(f.confirmed))
+	}
+}
+
+func TestInterceptor_Process_Error(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		modify func(f *fakeSNS, m *message, header http.Header)
+		// body replaces the body of the request if set.
+		body string
+		// resign signs the message again once modified.
+		resign   bool
+		params   map[string]interface{}
+		wantCode codes.Code
+		wantMsg  string
+	}{{
+		name:     "not a message",
+		body:     "hello",
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "failed to parse body as an SNS message",
+	}, {
+		name: "message type not matching the header",
+		modify: func(f *fakeSNS, m *message, header http.Header) {
+			header.Set("X-Amz-Sns-Message-Type", typeSubscriptionConfirmation)
+		},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "doesn't match the message type Notification",
+	}, {
+		name: "tampered message",
+		modify: func(f *fakeSNS, m *message, header http.Header) {
+			m.Message = `{"build": {"id": 43}}`
+		},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "SNS message signature does not match the message",
+	}, {
+		name: "unsigned message",
+		modify: func(f *fakeSNS, m *message, header http.Header) {
+			m.Signature = ""
+			m.SignatureVersion = ""
+		},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  `unsupported SNS signature version ""`,
+	}, {
+		name: "certificate not served by SNS",
+		modify: func(f *fakeSNS, m *message, header http.Header) {
+			m.SigningCertURL = "https://sns.example.com/cert.pem"
+		},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "https://sns.example.com/cert.pem is not an HTTPS URL of Amazon SNS",
+	}, {
+		name: "certificate not served over HTTPS",
+		modify: func(f *fakeSNS, m *message, header http.Header) {
+			m.SigningCertURL = strings.Replace(m.SigningCertURL, "https://", "http://", 1)
+		},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "is not an HTTPS URL of Amazon SNS",
+	}, {
+		name: "missing certificate",
+		modify: func(f *fakeSNS, m *message, header http.Header) {
+			m.SigningCertURL = f.URL + "/missing.pem"
+		},
+		wantCode: codes.Unavailable,
+		wantMsg:  "404 Not Found",
+	}, {
+		name: "redirected certificate",
+		modify: func(f *fakeSNS, m *message, header http.Header) {
+			m.SigningCertURL = f.URL + "/redirect.pem"
+		},
+		wantCode: codes.Unavailable,
+		wantMsg:  "302 Found",
+	}, {
+		name: "replayed message",
+		modify: func(f *fakeSNS, m *message, header http.Header) {
+			m.Timestamp = "2022-05-12T17:23:45.123Z"
+		},
+		resign:   true,
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "SNS message timestamp 2022-05-12T17:23:45.123Z is outside of the 1h0m0s maximum age",
+	}, {
+		name:     "message older than maxAge",
+		params:   map[string]interface{}{"maxAge": "5m"},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "is outside of the 5m0s maximum age",
+	}, {
+		name: "invalid timestamp",
+		modify: func(f *fakeSNS, m *message, header http.Header) {
+			m.Timestamp = "yesterday"
+		},
+		resign:   true,
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  `invalid SNS message timestamp "yesterday"`,
+	}, {
+		name:     "invalid maxAge",
+		params:   map[string]interface{}{"maxAge": "-1h"},
+		wantCode: codes.InvalidArgument,
+		wantMsg:  `invalid maxAge "-1h"`,
+	}, {
+		name:     "topic not allowed",
+		params:   map[string]interface{}{"topicArns": []string{"arn:aws:sns:us-east-1:123456789012:other"}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "topic " + topicArn + " is not allowed",
+	}, {
+		name: "subscription confirmation without allowed topics",
+		modify: func(f *fakeSNS, m *message, header http.Header) {
+			*m = *f.subscriptionConfirmation(typeSubscriptionConfirmation)
+			header.Set("X-Amz-Sns-Message-Type", m.Type)
+		},
+		resign:   true,
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "topicArns must list the topics to subscribe to",
+	}, {
+		name: "subscription URL not served by SNS",
+		modify: func(f *fakeSNS, m *message, header http.Header) {
+			*m = *f.subscriptionConfirmation(typeSubscriptionConfirmation)
+			m.SubscribeURL = "https://attacker.example.com/?Token=2336412f37"
+			header.Set("X-Amz-Sns-Message-Type", m.Type)
+		},
+		resign:   true,
+		params:   map[string]interface{}{"topicArns": []string{topicArn}},
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "invalid SubscribeURL",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFakeSNS(t)
+			m := notification(`{"build": {"id": 42}}`)
+			f.sign(t, m, "2")
+			header := http.Header{}
+			if tc.modify != nil {
+				tc.modify(f, m, header)
+				if tc.resign {
+					f.sign(t, m, "2")
+				}
+			}
+			r := request(t, m, tc.params)
+			for k, v := range header {
+				r.Header[k] = v
+			}
+			if tc.body != "" {
+				r.Body = tc.body
+			}
+
+			res := f.interceptor().Process(context.Background(), r)
+			if res.Continue {
+				t.Fatal("Process() continued processing the message")
+			}
+			if res.Status.Code != tc.wantCode || !strings.Contains(res.Status.Message, tc.wantMsg) {
+				t.Errorf("Process() got status %v, want code %v and a message containing %q", res.Status, tc.wantCode, tc.wantMsg)
+			}
+			if len(f.confirmed) != 0 {
+				t.Errorf("got %d confirmed subscriptions, want none", len(f.confirmed))
+			}
+		})
+	}
+}
+
+func TestSNSHost(t *testing.T) {
+	for host, want := range map[string]bool{
+		"sns.us-east-1.amazonaws.com":         true,
+		"sns.cn-north-1.amazonaws.com.cn":     true,
+		"sns.us-east-1.amazonaws.com.evil.io": false,
+		"evilsns.us-east-1.amazonaws.com":     false,
+		"sns.us-east-1.amazonaws.com:8443":    false,
+	} {
+		if got := snsHostRegex.MatchString(host); got != want {
+			t.Errorf("snsHostRegex.MatchString(%q) = %t, want %t", host, got, want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stripe
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"google.golang.org/grpc/codes"
+)
+
+var _ triggersv1.InterceptorInterface = (*Interceptor)(nil)
+
+const (
+	// signatureScheme is the scheme of the Stripe-Signature header elements holding the signatures.
+	signatureScheme = "v1"
+	// defaultTolerance is the maximum age of a signature timestamp, as used by the Stripe libraries.
+	defaultTolerance = 5 * time.Minute
+)
+
+// InterceptorParams provides a webhook to intercept and pre-process events.
+type InterceptorParams struct {
+	SecretRef            *triggersv1.SecretRef  `json:"secretRef,omitempty"`
+	AdditionalSecretRefs []triggersv1.SecretRef `json:"additionalSecretRefs,omitempty"`
+	// EventTypes lists the allowed values of the type field of the event, e.g. "invoice.paid".
+	EventTypes []string `json:"eventTypes,omitempty"`
+	// Tolerance is the maximum age of the signature timestamp, as a duration. Defaults to 5m.
+	Tolerance string `json:"tolerance,omitempty"`
+}
+
+// Interceptor verifies Stripe webhook signatures and filters events by type.
+// Stripe signs events with the endpoint secret over the payload "<t>.<body>", and sends the
+// timestamp and signatures in the Stripe-Signature header as "t=<t>,v1=<signature>,...".
+type Interceptor struct {
+	SecretGetter interceptors.SecretGetter
+
+	now func() time.Time
+}
+
+func NewInterceptor(sg interceptors.SecretGetter) *Interceptor {
+	return &Interceptor{
+		SecretGetter: sg,
+		now:          time.Now,
+	}
+}
+
+func (w *Interceptor) Process(ctx context.Context, r *triggersv1.InterceptorRequest) *triggersv1.InterceptorResponse {
+	p := InterceptorParams{}
+	if err := interceptors.UnmarshalParams(r.InterceptorParams, &p); err != nil {
+		return interceptors.Failf(codes.InvalidArgument, "failed to parse interceptor params: %v", err)
+	}
+
+	tolerance := defaultTolerance
+	if p.Tolerance != "" {
+		d, err := time.ParseDuration(p.Tolerance)
+		if err != nil || d <= 0 {
+			return interceptors.Failf(codes.InvalidArgument, "invalid tolerance %q: must be a positive duration", p.Tolerance)
+		}
+		tolerance = d
+	}
+
+	headers := interceptors.Canonical(r.Header)
+
+	// Validate the signature first so that unauthenticated payloads are never parsed
+	if p.SecretRef != nil {
+		// Check the secret to see if it is empty
+		if p.SecretRef.SecretKey == "" {
+			return interceptors.Fail(codes.FailedPrecondition, "stripe interceptor secretRef.secretKey is empty")
+		}
+		header := headers.Get("Stripe-Signature")
+		if header == "" {
+			return interceptors.Fail(codes.InvalidArgument, "no Stripe-Signature header set")
+		}
+		timestamp, signatures, err := parseHeader(header)
+		if err != nil {
+			return interceptors.Fail(codes.InvalidArgument, err.Error())
+		}
+		if err := w.checkTimestamp(timestamp, tolerance); err != nil {
+			return interceptors.Fail(codes.FailedPrecondition, err.Error())
+		}
+
+		if r.Context == nil {
+			return interceptors.Failf(codes.InvalidArgument, "no request context passed")
+		}
+
+		ns, _ := triggersv1.ParseTriggerID(r.Context.TriggerID)
+		secretTokens, err := interceptors.GetSecrets(ctx, w.SecretGetter, ns, p.SecretRef, p.AdditionalSecretRefs)
+		if err != nil {
+			return interceptors.Failf(codes.FailedPrecondition, "error getting secret: %v", err)
+		}
+
+		if err := interceptors.ValidateAny(secretTokens, func(secret []byte) error {
+			return validateSignature(signatures, timestamp, interceptors.SignedBody(r), secret)
+		}); err != nil {
+			return interceptors.Fail(codes.FailedPrecondition, err.Error())
+		}
+	}
+
+	// Check if the event type is in the allow-list
+	if p.EventTypes != nil {
+		event := struct {
+			Type string `json:"type"`
+		}{}
+		if err := json.Unmarshal([]byte(r.Body), &event); err != nil {
+			return interceptors.Failf(codes.InvalidArgument, "failed to parse body as a Stripe event: %v", err)
+		}
+		isAllowed := false
+		for _, allowedType := range p.EventTypes {
+			if event.Type == allowedType {
+				isAllowed = true
+				break
+			}
+		}
+		if !isAllowed {
+			return interceptors.Failf(codes.FailedPrecondition, "event type %s is not allowed", event.Type)
+		}
+	}
+
+	return &triggersv1.InterceptorResponse{
+		Continue: true,
+	}
+}
+
+// parseHeader returns the timestamp and the v1 signatures of a Stripe-Signature header. Stripe
+// sends several signatures while an endpoint secret is being rolled, and elements of other
+// schemes, such as v0 test signatures, which are ignored.
+func parseHeader(header string) (string, [][]byte, error) {
+	var timestamp string
+	var signatures [][]byte
+	for _, element := range strings.Split(header, ",") {
+		parts := strings.SplitN(strings.TrimSpace(element), "=", 2)
+		if len(parts) != 2 {
+			return "", nil, fmt.Errorf("invalid Stripe-Signature header element %q", element)
+		}
+		switch parts[0] {
+		case "t":
+			timestamp = parts[1]
+		case signatureScheme:
+			signature, err := hex.DecodeString(parts[1])
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid Stripe-Signature header: %w", err)
+			}
+			signatures = append(signatures, signature)
+		}
+	}
+	if timestamp == "" {
+		return "", nil, errors.New("no timestamp in Stripe-Signature header")
+	}
+	if len(signatures) == 0 {
+		return "", nil, fmt.Errorf("no %s signature in Stripe-Signature header", signatureScheme)
+	}
+	return timestamp, signatures, nil
+}
+
+// checkTimestamp rejects requests whose timestamp is more than tolerance away from the current time.
+func (w *Interceptor) checkTimestamp(timestamp string, tolerance time.Duration) error {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid Stripe-Signature timestamp %q: %w", timestamp, err)
+	}
+	age := w.now().Sub(time.Unix(sec, 0))
+	if age < 0 {
+		age = -age
+	}
+	if age > tolerance {
+		return fmt.Errorf("request timestamp %s is outside of the %s tolerance", timestamp, tolerance)
+	}
+	return nil
+}
+
+// validateSignature checks that one of signatures is the HMAC-SHA256 of the Stripe signed payload
+// computed with secretToken.
+func validateSignature(signatures [][]byte, timestamp, body string, secretToken []byte) error {
+	mac := hmac.New(sha256.New, secretToken)
+	fmt.Fprintf(mac, "%s.%s", timestamp, body)
+	expected := mac.Sum(nil)
+	for _, signature := range signatures {
+		if hmac.Equal(signature, expected) {
+			return nil
+		}
+	}
+	return errors.New("Stripe-Signature does not match the request")
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stripe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/pkg/interceptors/interceptorstest"
+	"github.com/tektoncd/triggers/test"
+	"google.golang.org/grpc/codes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+)
+
+const eventBody = `{"id": "evt_1", "object": "event", "type": "invoice.paid", "data": {"object": {"id": "in_1"}}}`
+
+var (
+	now = time.Unix(1650000000, 0)
+
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mysecret",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Data: map[string][]byte{
+			"token":    []byte("whsec_current"),
+			"newToken": []byte("whsec_rolled"),
+		},
+	}
+	secretRef = &triggersv1.SecretRef{SecretName: "mysecret", SecretKey: "token"}
+)
+
+func sign(secret string, timestamp time.Time, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.%s", timestamp.Unix(), body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func header(timestamp time.Time, signatures ...string) string {
+	elements := []string{"t=" + strconv.FormatInt(timestamp.Unix(), 10)}
+	for _, s := range signatures {
+		elements = append(elements, "v1="+s)
+	}
+	return strings.Join(elements, ",")
+}
+
+func newRequest(body string, params *InterceptorParams, signature string) *triggersv1.InterceptorRequest {
+	req := interceptorstest.NewRequest(body, http.Header{"Content-Type": []string{"application/json"}}, map[string]interface{}{
+		"secretRef":            params.SecretRef,
+		"additionalSecretRefs": params.AdditionalSecretRefs,
+		"eventTypes":           params.EventTypes,
+		"tolerance":            params.Tolerance,
+	})
+	if signature != "" {
+		req.Header["Stripe-Signature"] = []string{signature}
+	}
+	return req
+}
+
+func TestInterceptor_Process_ShouldContinue(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		params    *InterceptorParams
+		signature string
+	}{{
+		name:      "valid signature",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		signature: header(now, sign("whsec_current", now, eventBody)),
+	}, {
+		name:      "valid signature within tolerance",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		signature: header(now.Add(-4*time.Minute), sign("whsec_current", now.Add(-4*time.Minute), eventBody)),
+	}, {
+		name:      "valid signature within custom tolerance",
+		params:    &InterceptorParams{SecretRef: secretRef, Tolerance: "1h"},
+		signature: header(now.Add(-30*time.Minute), sign("whsec_current", now.Add(-30*time.Minute), eventBody)),
+	}, {
+		name:      "one of several signatures while the secret is rolled",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		signature: header(now, sign("whsec_rolled", now, eventBody), sign("whsec_current", now, eventBody)),
+	}, {
+		name:      "test signatures are ignored",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		signature: header(now, sign("whsec_current", now, eventBody)) + ",v0=6ffbb59b2300aae63f272406069a9788598b792a944a07aba816edb039989a39",
+	}, {
+		name: "signed with additional secret",
+		params: &InterceptorParams{
+			SecretRef:            secretRef,
+			AdditionalSecretRefs: []triggersv1.SecretRef{{SecretName: "mysecret", SecretKey: "newToken"}},
+		},
+		signature: header(now, sign("whsec_rolled", now, eventBody)),
+	}, {
+		name:   "allowed event type",
+		params: &InterceptorParams{EventTypes: []string{"invoice.created", "invoice.paid"}},
+	}, {
+		name:   "no secret",
+		params: &InterceptorParams{},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, secret)
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+			w.now = func() time.Time { return now }
+
+			res := w.Process(ctx, newRequest(eventBody, tc.params, tc.signature))
+			if !res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be true but got %t. \nStatus.Err(): %v", res.Continue, res.Status.Err())
+			}
+		})
+	}
+}
+
+func TestInterceptor_Process_ShouldNotContinue(t *testing.T) {
+	old := now.Add(-10 * time.Minute)
+	for _, tc := range []struct {
+		name      string
+		params    *InterceptorParams
+		body      string
+		signature string
+		wantCode  codes.Code
+		wantMsg   string
+	}{{
+		name:      "invalid signature",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      eventBody,
+		signature: header(now, sign("whsec_other", now, eventBody)),
+		wantCode:  codes.FailedPrecondition,
+		wantMsg:   "Stripe-Signature does not match the request",
+	}, {
+		name:      "signature over different body",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      `{"type": "invoice.paid"}`,
+		signature: header(now, sign("whsec_current", now, eventBody)),
+		wantCode:  codes.FailedPrecondition,
+		wantMsg:   "Stripe-Signature does not match the request",
+	}, {
+		name:      "signature for a different timestamp",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      eventBody,
+		signature: header(now, sign("whsec_current", now.Add(-time.Second), eventBody)),
+		wantCode:  codes.FailedPrecondition,
+		wantMsg:   "Stripe-Signature does not match the request",
+	}, {
+		name:     "missing signature",
+		params:   &InterceptorParams{SecretRef: secretRef},
+		body:     eventBody,
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "no Stripe-Signature header set",
+	}, {
+		name:      "missing timestamp",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      eventBody,
+		signature: "v1=" + sign("whsec_current", now, eventBody),
+		wantCode:  codes.InvalidArgument,
+		wantMsg:   "no timestamp in Stripe-Signature header",
+	}, {
+		name:      "only test signatures",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      eventBody,
+		signature: header(now) + ",v0=" + sign("whsec_current", now, eventBody),
+		wantCode:  codes.InvalidArgument,
+		wantMsg:   "no v1 signature in Stripe-Signature header",
+	}, {
+		name:      "malformed signature",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      eventBody,
+		signature: header(now, "xyz"),
+		wantCode:  codes.InvalidArgument,
+		wantMsg:   "invalid Stripe-Signature header",
+	}, {
+		name:      "replayed request",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      eventBody,
+		signature: header(old, sign("whsec_current", old, eventBody)),
+		wantCode:  codes.FailedPrecondition,
+		wantMsg:   "is outside of the 5m0s tolerance",
+	}, {
+		name:      "replayed request with custom tolerance",
+		params:    &InterceptorParams{SecretRef: secretRef, Tolerance: "1m"},
+		body:      eventBody,
+		signature: header(now.Add(-2*time.Minute), sign("whsec_current", now.Add(-2*time.Minute), eventBody)),
+		wantCode:  codes.FailedPrecondition,
+		wantMsg:   "is outside of the 1m0s tolerance",
+	}, {
+		name:      "invalid timestamp",
+		params:    &InterceptorParams{SecretRef: secretRef},
+		body:      eventBody,
+		signature: "t=yesterday,v1=" + sign("whsec_current", now, eventBody),
+		wantCode:  codes.FailedPrecondition,
+		wantMsg:   `invalid Stripe-Signature timestamp "yesterday"`,
+	}, {
+		name:      "invalid tolerance",
+		params:    &InterceptorParams{SecretRef: secretRef, Tolerance: "-5m"},
+		body:      eventBody,
+		signature: header(now, sign("whsec_current", now, eventBody)),
+		wantCode:  codes.InvalidArgument,
+		wantMsg:   `invalid tolerance "-5m"`,
+	}, {
+		name:      "empty secret key",
+		params:    &InterceptorParams{SecretRef: &triggersv1.SecretRef{SecretName: "mysecret"}},
+		body:      eventBody,
+		signature: header(now, sign("whsec_current", now, eventBody)),
+		wantCode:  codes.FailedPrecondition,
+		wantMsg:   "stripe interceptor secretRef.secretKey is empty",
+	}, {
+		name:     "event type not allowed",
+		params:   &InterceptorParams{EventTypes: []string{"invoice.created"}},
+		body:     eventBody,
+		wantCode: codes.FailedPrecondition,
+		wantMsg:  "event type invoice.paid is not allowed",
+	}, {
+		name:     "invalid body",
+		params:   &InterceptorParams{EventTypes: []string{"invoice.paid"}},
+		body:     "type=invoice.paid",
+		wantCode: codes.InvalidArgument,
+		wantMsg:  "failed to parse body as a Stripe event",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := test.SetupFakeContext(t)
+			ctx, clientset := fakekubeclient.With(ctx, secret)
+			w := NewInterceptor(interceptors.DefaultSecretGetter(clientset.CoreV1()))
+			w.now = func() time.Time { return now }
+
+			res := w.Process(ctx, newRequest(tc.body, tc.params, tc.signature))
+			if res.Continue {
+				t.Fatalf("Interceptor.Process() expected res.Continue to be false but got %t", res.Continue)
+			}
+			if res.Status.Code != tc.wantCode {
+				t.Errorf("Interceptor.Process() got status code %v, want %v", res.Status.Code, tc.wantCode)
+			}
+			if !strings.Contains(res.Status.Message, tc.wantMsg) {
+				t.Errorf("Interceptor.Process() got message %q, want it to contain %q", res.Status.Message, tc.wantMsg)
+			}
+		})
+	}
+}
@@ -23,6 +23,8 @@ import (
 	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
 	triggersclient "github.com/tektoncd/triggers/pkg/client/injection/client"
 	eventlistenerinformer "github.com/tektoncd/triggers/pkg/client/injection/informers/triggers/v1beta1/eventlistener"
+	triggerinformer "github.com/tektoncd/triggers/pkg/client/injection/informers/triggers/v1beta1/trigger"
+	secretinformer "github.com/tektoncd/triggers/pkg/client/injection/metadata/informers/core/v1/secret"
 	eventlistenerreconciler "github.com/tektoncd/triggers/pkg/client/injection/reconciler/triggers/v1beta1/eventlistener"
 	dynamicduck "github.com/tektoncd/triggers/pkg/dynamic"
 	"github.com/tektoncd/triggers/pkg/reconciler/eventlistener/resources"
@@ -37,6 +39,7 @@ import (
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/injection/clients/dynamicclient"
+	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/logging"
 )
 
@@ -50,6 +53,8 @@ func NewController(config resources.Config) func(context.Context, configmap.Watc
 		eventListenerInformer := eventlistenerinformer.Get(ctx)
 		deploymentInformer := filtereddeployinformer.Get(ctx, labels.FormatLabels(resources.DefaultStaticResourceLabels))
 		serviceInformer := filteredserviceinformer.Get(ctx, labels.FormatLabels(resources.DefaultStaticResourceLabels))
+		triggerInformer := triggerinformer.Get(ctx)
+		secretInformer := secretinformer.Get(ctx)
 
 		reconciler := &Reconciler{
 			DynamicClientSet:  dynamicclientset,
@@ -57,6 +62,8 @@ func NewController(config resources.Config) func(context.Context, configmap.Watc
 			TriggersClientSet: triggersclientset,
 			deploymentLister:  deploymentInformer.Lister(),
 			serviceLister:     serviceInformer.Lister(),
+			triggerLister:     triggerInformer.Lister(),
+			secretLister:      secretInformer.Lister(),
 			configAcc:         reconcilersource.WatchConfigurations(ctx, "eventlistener", cmw),
 			config:            config,
 			Metrics:           metrics.Get(ctx),
@@ -85,6 +92,55 @@ func NewController(config resources.Config) func(context.Context, configmap.Watc
 			Handler:    controller.HandleAll(impl.EnqueueControllerOf),
 		})
 
+		// Recheck the secrets of the EventListeners that reference a secret when it changes. Only the
+		// metadata of the secrets is watched, so that their data isn't cached.
+		secretInformer.Informer().AddEventHandler(controller.HandleAll(func(obj interface{}) {
+			secret, err := kmeta.DeletionHandlingAccessor(obj)
+			if err != nil {
+				return
+			}
+			// EventListeners can reference the secrets of other namespaces.
+			els, err := eventListenerInformer.Lister().List(labels.Everything())
+			if err != nil {
+				logger.Errorf("Error listing EventListeners: %s", err)
+				return
+			}
+			for _, el := range els {
+				triggers, err := reconciler.referencedTriggers(el)
+				if err != nil {
+					logger.Errorf("Error listing the Triggers of EventListener %s/%s: %s", el.Namespace, el.Name, err)
+					continue
+				}
+				if referencesSecret(el, triggers, secret.GetNamespace(), secret.GetName()) {
+					impl.Enqueue(el)
+				}
+			}
+		}))
+
+		// Recheck the secrets of the EventListeners that select a Trigger when it changes.
+		triggerInformer.Informer().AddEventHandler(controller.HandleAll(func(obj interface{}) {
+			t, ok := obj.(*v1beta1.Trigger)
+			if !ok {
+				tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					return
+				}
+				if t, ok = tombstone.Obj.(*v1beta1.Trigger); !ok {
+					return
+				}
+			}
+			els, err := eventListenerInformer.Lister().List(labels.Everything())
+			if err != nil {
+				logger.Errorf("Error listing EventListeners: %s", err)
+				return
+			}
+			for _, el := range els {
+				if selectsTrigger(el, t) {
+					impl.Enqueue(el)
+				}
+			}
+		}))
+
 		return impl
 	}
 }
@@ -27,6 +27,7 @@ import (
 	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
 	triggersclientset "github.com/tektoncd/triggers/pkg/client/clientset/versioned"
 	eventlistenerreconciler "github.com/tektoncd/triggers/pkg/client/injection/reconciler/triggers/v1beta1/eventlistener"
+	listers "github.com/tektoncd/triggers/pkg/client/listers/triggers/v1beta1"
 	dynamicduck "github.com/tektoncd/triggers/pkg/dynamic"
 	"github.com/tektoncd/triggers/pkg/reconciler/eventlistener/resources"
 	"github.com/tektoncd/triggers/pkg/reconciler/metrics"
@@ -41,6 +42,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	appsv1lister "k8s.io/client-go/listers/apps/v1"
 	corev1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	reconcilersource "knative.dev/eventing/pkg/reconciler/source"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/kmeta"
@@ -79,6 +81,9 @@ type Reconciler struct {
 	// listers index properties about resources
 	deploymentLister appsv1lister.DeploymentLister
 	serviceLister    corev1lister.ServiceLister
+	triggerLister    listers.TriggerLister
+	// secretLister lists the metadata of secrets, without their data.
+	secretLister cache.GenericLister
 
 	// config accessor for observability/logging/tracing
 	configAcc reconcilersource.ConfigAccessor
@@ -108,6 +113,10 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, el *v1beta1.EventListene
 	// and may not have had all of the assumed default specified.
 	el.SetDefaults(contexts.WithUpgradeViaDefaulting(ctx))
 
+	if err := r.reconcileSecrets(ctx, el); err != nil {
+		return err
+	}
+
 	if el.Spec.Resources.CustomResource != nil {
 		return r.reconcileCustomObject(ctx, el)
 	}
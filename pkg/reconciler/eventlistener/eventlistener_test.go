@@ -215,7 +215,7 @@ func makeDeployment(ops ...func(d *appsv1.Deployment)) *appsv1.Deployment {
 						ReadinessProbe: &corev1.Probe{
 							ProbeHandler: corev1.ProbeHandler{
 								HTTPGet: &corev1.HTTPGetAction{
-									Path:   "/live",
+									Path:   "/ready",
 									Scheme: corev1.URISchemeHTTP,
 									Port:   intstr.FromInt(eventListenerContainerPort),
 								},
@@ -228,6 +228,7 @@ func makeDeployment(ops ...func(d *appsv1.Deployment)) *appsv1.Deployment {
 							"--el-namespace=" + namespace,
 							"--port=" + strconv.Itoa(eventListenerContainerPort),
 							"--readtimeout=" + strconv.FormatInt(resources.DefaultReadTimeout, 10),
+							"--readheadertimeout=" + strconv.FormatInt(resources.DefaultReadHeaderTimeout, 10),
 							"--writetimeout=" + strconv.FormatInt(resources.DefaultWriteTimeout, 10),
 							"--idletimeout=" + strconv.FormatInt(resources.DefaultIdleTimeout, 10),
 							"--timeouthandler=" + strconv.FormatInt(resources.DefaultTimeOutHandler, 10),
@@ -236,6 +237,9 @@ func makeDeployment(ops ...func(d *appsv1.Deployment)) *appsv1.Deployment {
 							"--httpclient-tlshandshaketimeout=" + strconv.FormatInt(resources.DefaultHTTPClientTLSHandshakeTimeout, 10),
 							"--httpclient-responseheadertimeout=" + strconv.FormatInt(resources.DefaultHTTPClientResponseHeaderTimeout, 10),
 							"--httpclient-expectcontinuetimeout=" + strconv.FormatInt(resources.DefaultHTTPClientExpectContinueTimeout, 10),
+							"--resource-client-qps=" + strconv.FormatFloat(resources.DefaultResourceClientQPS, 'f', -1, 64),
+							"--resource-client-burst=" + strconv.Itoa(resources.DefaultResourceClientBurst),
+							"--resource-client-max-idle-conns=" + strconv.Itoa(resources.DefaultResourceClientMaxIdleConns),
 							"--is-multi-ns=false",
 							"--payload-validation=true",
 							"--cloudevent-uri=",
@@ -383,6 +387,7 @@ func makeWithPod(ops ...func(d *duckv1.WithPod)) *duckv1.WithPod {
 							"--el-namespace=" + namespace,
 							"--port=" + strconv.Itoa(eventListenerContainerPort),
 							"--readtimeout=" + strconv.FormatInt(resources.DefaultReadTimeout, 10),
+							"--readheadertimeout=" + strconv.FormatInt(resources.DefaultReadHeaderTimeout, 10),
 							"--writetimeout=" + strconv.FormatInt(resources.DefaultWriteTimeout, 10),
 							"--idletimeout=" + strconv.FormatInt(resources.DefaultIdleTimeout, 10),
 							"--timeouthandler=" + strconv.FormatInt(resources.DefaultTimeOutHandler, 10),
@@ -391,6 +396,9 @@ func makeWithPod(ops ...func(d *duckv1.WithPod)) *duckv1.WithPod {
 							"--httpclient-tlshandshaketimeout=" + strconv.FormatInt(resources.DefaultHTTPClientTLSHandshakeTimeout, 10),
 							"--httpclient-responseheadertimeout=" + strconv.FormatInt(resources.DefaultHTTPClientResponseHeaderTimeout, 10),
 							"--httpclient-expectcontinuetimeout=" + strconv.FormatInt(resources.DefaultHTTPClientExpectContinueTimeout, 10),
+							"--resource-client-qps=" + strconv.FormatFloat(resources.DefaultResourceClientQPS, 'f', -1, 64),
+							"--resource-client-burst=" + strconv.Itoa(resources.DefaultResourceClientBurst),
+							"--resource-client-max-idle-conns=" + strconv.Itoa(resources.DefaultResourceClientMaxIdleConns),
 							"--is-multi-ns=" + strconv.FormatBool(false),
 							"--payload-validation=" + strconv.FormatBool(true),
 							"--cloudevent-uri=",
@@ -425,7 +433,7 @@ func makeWithPod(ops ...func(d *duckv1.WithPod)) *duckv1.WithPod {
 						ReadinessProbe: &corev1.Probe{
 							ProbeHandler: corev1.ProbeHandler{
 								HTTPGet: &corev1.HTTPGetAction{
-									Path:   "/live",
+									Path:   "/ready",
 									Scheme: corev1.URISchemeHTTP,
 								},
 							},
@@ -27,6 +27,8 @@ var (
 	DefaultEventListenerEvent = "disable"
 	// DefaultReadTimeout is the ReadTimeout used by default.
 	DefaultReadTimeout = int64(5)
+	// DefaultReadHeaderTimeout is the ReadHeaderTimeout used by default.
+	DefaultReadHeaderTimeout = int64(5)
 	// DefaultWriteTimeout is the WriteTimeout used by default.
 	DefaultWriteTimeout = int64(40)
 	// DefaultIdleTimeout is the IdleTimeout used by default.
@@ -47,6 +49,23 @@ var (
 	DefaultHTTPClientResponseHeaderTimeout = int64(10)
 	// DefaultHTTPClientExpectContinueTimeout is the HTTPClient Expect Continue Timeout
 	DefaultHTTPClientExpectContinueTimeout = int64(1)
+	// DefaultResourceClientQPS is the QPS of the clients creating resources used by default, higher than
+	// the client-go default of 5 since EventListeners mostly create resources.
+	DefaultResourceClientQPS = float64(50)
+	// DefaultResourceClientBurst is the burst of the clients creating resources used by default.
+	DefaultResourceClientBurst = 100
+	// DefaultResourceClientMaxIdleConns is the maximum number of idle connections to the API server kept by
+	// the clients creating resources used by default.
+	DefaultResourceClientMaxIdleConns = 50
+	// DefaultTLSMinVersion is the minimum TLS version used by default. It is empty, so that the
+	// EventListener keeps its own defaults.
+	DefaultTLSMinVersion = ""
+	// DefaultTLSCipherSuites are the TLS cipher suites used by default. They are empty, so that the
+	// cipher suites of Go are used.
+	DefaultTLSCipherSuites = ""
+	// DefaultLogFormat is the log format used by default. It is empty, so that the EventListener
+	// logs in the format of its logging config.
+	DefaultLogFormat = ""
 	// DefaultStaticResourceLabels are the StaticResourceLabels used by default.
 	DefaultStaticResourceLabels = map[string]string{
 		"app.kubernetes.io/managed-by": "EventListener",
@@ -67,6 +86,8 @@ type Config struct {
 	SetEventListenerEvent *string
 	// ReadTimeOut defines the read timeout for EventListener Server.
 	ReadTimeOut *int64
+	// ReadHeaderTimeOut defines the timeout for reading the request headers for EventListener Server.
+	ReadHeaderTimeOut *int64
 	// WriteTimeOut defines the write timeout for EventListener Server.
 	WriteTimeOut *int64
 	// IdleTimeOut defines the read timeout for EventListener Server.
@@ -83,6 +104,22 @@ type Config struct {
 	HTTPClientResponseHeaderTimeout *int64
 	// HTTPClientExpectContinueTimeout defines the Expect timeout for HTTP Client
 	HTTPClientExpectContinueTimeout *int64
+	// ResourceClientQPS defines the QPS of the dynamic and discovery clients creating resources
+	ResourceClientQPS *float64
+	// ResourceClientBurst defines the burst of the dynamic and discovery clients creating resources
+	ResourceClientBurst *int
+	// ResourceClientMaxIdleConns defines the maximum number of idle connections to the API server kept by
+	// the dynamic and discovery clients creating resources
+	ResourceClientMaxIdleConns *int
+	// TLSMinVersion defines the minimum TLS version of the EventListener server and of its clients of
+	// interceptors, 1.2 or 1.3
+	TLSMinVersion *string
+	// TLSCipherSuites defines the comma separated TLS 1.2 cipher suites of the EventListener server and of
+	// its clients of interceptors
+	TLSCipherSuites *string
+	// LogFormat defines the format of the logs of the EventListener: empty, for the format of its
+	// logging config, or json
+	LogFormat *string
 	// PeriodSeconds defines Period Seconds for the EventListener Liveness and Readiness Probes.
 	PeriodSeconds *int
 	// FailureThreshold defines the Failure Threshold for the EventListener Liveness and Readiness Probes.
@@ -105,6 +142,7 @@ func MakeConfig(ops ...ConfigOption) *Config {
 		SetEventListenerEvent: &DefaultEventListenerEvent,
 
 		ReadTimeOut:                     &DefaultReadTimeout,
+		ReadHeaderTimeOut:               &DefaultReadHeaderTimeout,
 		WriteTimeOut:                    &DefaultWriteTimeout,
 		IdleTimeOut:                     &DefaultIdleTimeout,
 		TimeOutHandler:                  &DefaultTimeOutHandler,
@@ -113,6 +151,12 @@ func MakeConfig(ops ...ConfigOption) *Config {
 		HTTPClientTLSHandshakeTimeout:   &DefaultHTTPClientTLSHandshakeTimeout,
 		HTTPClientResponseHeaderTimeout: &DefaultHTTPClientResponseHeaderTimeout,
 		HTTPClientExpectContinueTimeout: &DefaultHTTPClientExpectContinueTimeout,
+		ResourceClientQPS:               &DefaultResourceClientQPS,
+		ResourceClientBurst:             &DefaultResourceClientBurst,
+		ResourceClientMaxIdleConns:      &DefaultResourceClientMaxIdleConns,
+		TLSMinVersion:                   &DefaultTLSMinVersion,
+		TLSCipherSuites:                 &DefaultTLSCipherSuites,
+		LogFormat:                       &DefaultLogFormat,
 		PeriodSeconds:                   &DefaultPeriodSeconds,
 		FailureThreshold:                &DefaultFailureThreshold,
 
@@ -40,20 +40,206 @@ func MakeContainer(el *v1beta1.EventListener, configAcc reconcilersource.ConfigA
 		}
 	}
 
+	var annotationArgs []string
+	if value, ok := el.GetAnnotations()[triggers.BackpressureMaxInFlightAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--backpressure-max-in-flight="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.BackpressureRetryAfterAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--backpressure-retry-after="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.InterceptorTimeoutAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--interceptor-timeout="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.AllowedMethodsAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--allowed-methods="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.AllowedContentTypesAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--allowed-content-types="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.CreationLimitAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--creation-limit="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.CreationLimitWindowAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--creation-limit-window="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.CreationLimitPerTriggerAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--creation-limit-per-trigger="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.MaxCreatesPerEventAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--max-creates-per-event="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.QuotaRetryWindowAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--quota-retry-window="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.QuotaRetryMaxQueuedAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--quota-retry-max-queued="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.QuotaRetryPersistAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--quota-retry-persist="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.CreateTimeoutAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--create-timeout="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.H2CAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--h2c="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.ActivityIntervalAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--activity-interval="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.ProvenanceLabelsAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--provenance-labels="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.AccessLogAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--access-log="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.AccessLogFieldsAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--access-log-fields="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.AccessLogSampleRateAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--access-log-sample-rate="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.BasePathAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--base-path="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.TrustedProxiesAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--trusted-proxies="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.TrustedProxyHeaderAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--trusted-proxy-header="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.ProxyProtocolUpstreamsAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--proxy-protocol-upstreams="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.EventIDHeadersAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--event-id-headers="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.EventIDExpressionAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--event-id-expression="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.FieldValidationAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--field-validation="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.LabelSanitizationAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--label-sanitization="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.SynchronousAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--synchronous="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.MultiStatusAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--multi-status="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.RollbackOnFailureAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--rollback-on-failure="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.CreateFailurePolicyAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--create-failure-policy="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.AuditSinkAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--audit-sink="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.AuditModeAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--audit-mode="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.AuditFailurePolicyAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--audit-failure-policy="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.PayloadParsersAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--payload-parsers="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.SelfTestSecretAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--self-test-secret="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.ConfigSecretAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--config-secret="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.BatchSizeAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--batch-size="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.ResourcePoliciesAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--resource-policies="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.MutationHookURLAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--mutation-hook-url="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.MutationHookTimeoutAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--mutation-hook-timeout="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.MutationHookFailurePolicyAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--mutation-hook-failure-policy="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.CallbackURLAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--callback-url="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.CallbackPayloadAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--callback-payload="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.CallbackSecretAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--callback-secret="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.CallbackTimeoutAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--callback-timeout="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.CallbackRetriesAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--callback-retries="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.ResourceEventsAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--resource-events="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.ResourceEventsURIAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--resource-events-uri="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.ResourceEventsSourceAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--resource-events-source="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.DebugTraceSecretAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--debug-trace-secret="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.DebugTraceSampleRateAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--debug-trace-sample-rate="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.InterceptorMetricsNamesAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--interceptor-metrics-names="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.ResourceMetricsNamespacesAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--resource-metrics-namespaces="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.NoMatchPolicyAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--no-match-policy="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.NoMatchLogLevelAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--no-match-log-level="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.DiscoveryGracePeriodAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--discovery-grace-period="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.DiscoveryBackoffAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--discovery-backoff="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.EventWorkersAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--event-workers="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.EventQueueSizeAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--event-queue-size="+value)
+	}
+	if value, ok := el.GetAnnotations()[triggers.EventOverflowAnnotation]; ok {
+		annotationArgs = append(annotationArgs, "--event-overflow="+value)
+	}
+
 	ev := configAcc.ToEnvVars()
 
 	container := corev1.Container{
 		Name:  "event-listener",
 		Image: *c.Image,
 		Ports: []corev1.ContainerPort{{
-			ContainerPort: int32(eventListenerContainerPort),
+			ContainerPort: int32(containerPort(el)),
 			Protocol:      corev1.ProtocolTCP,
 		}},
 		Args: []string{
 			"--el-name=" + el.Name,
 			"--el-namespace=" + el.Namespace,
-			"--port=" + strconv.Itoa(eventListenerContainerPort),
+			"--port=" + strconv.Itoa(containerPort(el)),
 			"--readtimeout=" + strconv.FormatInt(*c.ReadTimeOut, 10),
+			"--readheadertimeout=" + strconv.FormatInt(*c.ReadHeaderTimeOut, 10),
 			"--writetimeout=" + strconv.FormatInt(*c.WriteTimeOut, 10),
 			"--idletimeout=" + strconv.FormatInt(*c.IdleTimeOut, 10),
 			"--timeouthandler=" + strconv.FormatInt(*c.TimeOutHandler, 10),
@@ -62,6 +248,9 @@ func MakeContainer(el *v1beta1.EventListener, configAcc reconcilersource.ConfigA
 			"--httpclient-tlshandshaketimeout=" + strconv.FormatInt(*c.HTTPClientTLSHandshakeTimeout, 10),
 			"--httpclient-responseheadertimeout=" + strconv.FormatInt(*c.HTTPClientResponseHeaderTimeout, 10),
 			"--httpclient-expectcontinuetimeout=" + strconv.FormatInt(*c.HTTPClientExpectContinueTimeout, 10),
+			"--resource-client-qps=" + strconv.FormatFloat(*c.ResourceClientQPS, 'f', -1, 64),
+			"--resource-client-burst=" + strconv.Itoa(*c.ResourceClientBurst),
+			"--resource-client-max-idle-conns=" + strconv.Itoa(*c.ResourceClientMaxIdleConns),
 			"--is-multi-ns=" + strconv.FormatBool(isMultiNS),
 			"--payload-validation=" + strconv.FormatBool(payloadValidation),
 			"--cloudevent-uri=" + el.Spec.CloudEventURI,
@@ -81,6 +270,17 @@ func MakeContainer(el *v1beta1.EventListener, configAcc reconcilersource.ConfigA
 		}}...),
 	}
 
+	if *c.TLSMinVersion != "" {
+		container.Args = append(container.Args, "--tls-min-version="+*c.TLSMinVersion)
+	}
+	if *c.TLSCipherSuites != "" {
+		container.Args = append(container.Args, "--tls-cipher-suites="+*c.TLSCipherSuites)
+	}
+	if *c.LogFormat != "" {
+		container.Args = append(container.Args, "--log-format="+*c.LogFormat)
+	}
+	container.Args = append(container.Args, annotationArgs...)
+
 	for _, opt := range opts {
 		opt(&container)
 	}
@@ -51,6 +51,7 @@ func TestContainer(t *testing.T) {
 				"--el-namespace=" + namespace,
 				"--port=" + strconv.Itoa(eventListenerContainerPort),
 				"--readtimeout=" + strconv.FormatInt(DefaultReadTimeout, 10),
+				"--readheadertimeout=" + strconv.FormatInt(DefaultReadHeaderTimeout, 10),
 				"--writetimeout=" + strconv.FormatInt(DefaultWriteTimeout, 10),
 				"--idletimeout=" + strconv.FormatInt(DefaultIdleTimeout, 10),
 				"--timeouthandler=" + strconv.FormatInt(DefaultTimeOutHandler, 10),
@@ -59,6 +60,9 @@ func TestContainer(t *testing.T) {
 				"--httpclient-tlshandshaketimeout=" + strconv.FormatInt(DefaultHTTPClientTLSHandshakeTimeout, 10),
 				"--httpclient-responseheadertimeout=" + strconv.FormatInt(DefaultHTTPClientResponseHeaderTimeout, 10),
 				"--httpclient-expectcontinuetimeout=" + strconv.FormatInt(DefaultHTTPClientExpectContinueTimeout, 10),
+				"--resource-client-qps=" + strconv.FormatFloat(DefaultResourceClientQPS, 'f', -1, 64),
+				"--resource-client-burst=" + strconv.Itoa(DefaultResourceClientBurst),
+				"--resource-client-max-idle-conns=" + strconv.Itoa(DefaultResourceClientMaxIdleConns),
 				"--is-multi-ns=" + strconv.FormatBool(false),
 				"--payload-validation=" + strconv.FormatBool(true),
 				"--cloudevent-uri=",
@@ -107,6 +111,7 @@ func TestContainer(t *testing.T) {
 				"--el-namespace=" + namespace,
 				"--port=" + strconv.Itoa(eventListenerContainerPort),
 				"--readtimeout=" + strconv.FormatInt(DefaultReadTimeout, 10),
+				"--readheadertimeout=" + strconv.FormatInt(DefaultReadHeaderTimeout, 10),
 				"--writetimeout=" + strconv.FormatInt(DefaultWriteTimeout, 10),
 				"--idletimeout=" + strconv.FormatInt(DefaultIdleTimeout, 10),
 				"--timeouthandler=" + strconv.FormatInt(DefaultTimeOutHandler, 10),
@@ -115,6 +120,9 @@ func TestContainer(t *testing.T) {
 				"--httpclient-tlshandshaketimeout=" + strconv.FormatInt(DefaultHTTPClientTLSHandshakeTimeout, 10),
 				"--httpclient-responseheadertimeout=" + strconv.FormatInt(DefaultHTTPClientResponseHeaderTimeout, 10),
 				"--httpclient-expectcontinuetimeout=" + strconv.FormatInt(DefaultHTTPClientExpectContinueTimeout, 10),
+				"--resource-client-qps=" + strconv.FormatFloat(DefaultResourceClientQPS, 'f', -1, 64),
+				"--resource-client-burst=" + strconv.Itoa(DefaultResourceClientBurst),
+				"--resource-client-max-idle-conns=" + strconv.Itoa(DefaultResourceClientMaxIdleConns),
 				"--is-multi-ns=" + strconv.FormatBool(false),
 				"--payload-validation=" + strconv.FormatBool(true),
 				"--cloudevent-uri=",
@@ -167,6 +175,7 @@ func TestContainer(t *testing.T) {
 				"--el-namespace=" + namespace,
 				"--port=" + strconv.Itoa(eventListenerContainerPort),
 				"--readtimeout=" + strconv.FormatInt(DefaultReadTimeout, 10),
+				"--readheadertimeout=" + strconv.FormatInt(DefaultReadHeaderTimeout, 10),
 				"--writetimeout=" + strconv.FormatInt(DefaultWriteTimeout, 10),
 				"--idletimeout=" + strconv.FormatInt(DefaultIdleTimeout, 10),
 				"--timeouthandler=" + strconv.FormatInt(DefaultTimeOutHandler, 10),
@@ -175,6 +184,9 @@ func TestContainer(t *testing.T) {
 				"--httpclient-tlshandshaketimeout=" + strconv.FormatInt(DefaultHTTPClientTLSHandshakeTimeout, 10),
 				"--httpclient-responseheadertimeout=" + strconv.FormatInt(DefaultHTTPClientResponseHeaderTimeout, 10),
 				"--httpclient-expectcontinuetimeout=" + strconv.FormatInt(DefaultHTTPClientExpectContinueTimeout, 10),
+				"--resource-client-qps=" + strconv.FormatFloat(DefaultResourceClientQPS, 'f', -1, 64),
+				"--resource-client-burst=" + strconv.Itoa(DefaultResourceClientBurst),
+				"--resource-client-max-idle-conns=" + strconv.Itoa(DefaultResourceClientMaxIdleConns),
 				"--is-multi-ns=" + strconv.FormatBool(false),
 				"--payload-validation=" + strconv.FormatBool(true),
 				"--cloudevent-uri=",
@@ -201,6 +213,7 @@ func TestContainer(t *testing.T) {
 				"--el-namespace=" + namespace,
 				"--port=" + strconv.Itoa(eventListenerContainerPort),
 				"--readtimeout=" + strconv.FormatInt(DefaultReadTimeout, 10),
+				"--readheadertimeout=" + strconv.FormatInt(DefaultReadHeaderTimeout, 10),
 				"--writetimeout=" + strconv.FormatInt(DefaultWriteTimeout, 10),
 				"--idletimeout=" + strconv.FormatInt(DefaultIdleTimeout, 10),
 				"--timeouthandler=" + strconv.FormatInt(DefaultTimeOutHandler, 10),
@@ -209,6 +222,9 @@ func TestContainer(t *testing.T) {
 				"--httpclient-tlshandshaketimeout=" + strconv.FormatInt(DefaultHTTPClientTLSHandshakeTimeout, 10),
 				"--httpclient-responseheadertimeout=" + strconv.FormatInt(DefaultHTTPClientResponseHeaderTimeout, 10),
 				"--httpclient-expectcontinuetimeout=" + strconv.FormatInt(DefaultHTTPClientExpectContinueTimeout, 10),
+				"--resource-client-qps=" + strconv.FormatFloat(DefaultResourceClientQPS, 'f', -1, 64),
+				"--resource-client-burst=" + strconv.Itoa(DefaultResourceClientBurst),
+				"--resource-client-max-idle-conns=" + strconv.Itoa(DefaultResourceClientMaxIdleConns),
 				"--is-multi-ns=" + strconv.FormatBool(true),
 				"--payload-validation=" + strconv.FormatBool(true),
 				"--cloudevent-uri=",
@@ -252,6 +268,7 @@ func TestContainer(t *testing.T) {
 				"--el-namespace=" + namespace,
 				"--port=" + strconv.Itoa(eventListenerContainerPort),
 				"--readtimeout=" + strconv.FormatInt(DefaultReadTimeout, 10),
+				"--readheadertimeout=" + strconv.FormatInt(DefaultReadHeaderTimeout, 10),
 				"--writetimeout=" + strconv.FormatInt(DefaultWriteTimeout, 10),
 				"--idletimeout=" + strconv.FormatInt(DefaultIdleTimeout, 10),
 				"--timeouthandler=" + strconv.FormatInt(DefaultTimeOutHandler, 10),
@@ -260,6 +277,9 @@ func TestContainer(t *testing.T) {
 				"--httpclient-tlshandshaketimeout=" + strconv.FormatInt(DefaultHTTPClientTLSHandshakeTimeout, 10),
 				"--httpclient-responseheadertimeout=" + strconv.FormatInt(DefaultHTTPClientResponseHeaderTimeout, 10),
 				"--httpclient-expectcontinuetimeout=" + strconv.FormatInt(DefaultHTTPClientExpectContinueTimeout, 10),
+				"--resource-client-qps=" + strconv.FormatFloat(DefaultResourceClientQPS, 'f', -1, 64),
+				"--resource-client-burst=" + strconv.Itoa(DefaultResourceClientBurst),
+				"--resource-client-max-idle-conns=" + strconv.Itoa(DefaultResourceClientMaxIdleConns),
 				"--is-multi-ns=" + strconv.FormatBool(false),
 				"--payload-validation=" + strconv.FormatBool(false),
 				"--cloudevent-uri=",
@@ -284,6 +304,182 @@ func TestContainer(t *testing.T) {
 				Value: strconv.FormatInt(DefaultTimeOutHandler, 10),
 			}},
 		},
+	}, {
+		name: "with request handling annotations",
+		el: makeEL(func(el *v1beta1.EventListener) {
+			el.Annotations = map[string]string{
+				triggers.BackpressureMaxInFlightAnnotation:   "10",
+				triggers.BackpressureRetryAfterAnnotation:    "60",
+				triggers.InterceptorTimeoutAnnotation:        "10s",
+				triggers.AllowedMethodsAnnotation:            "POST,PUT",
+				triggers.AllowedContentTypesAnnotation:       "application/json",
+				triggers.CreationLimitAnnotation:             "100",
+				triggers.CreationLimitWindowAnnotation:       "1h",
+				triggers.CreationLimitPerTriggerAnnotation:   "true",
+				triggers.MaxCreatesPerEventAnnotation:        "20",
+				triggers.QuotaRetryWindowAnnotation:          "5m",
+				triggers.QuotaRetryMaxQueuedAnnotation:       "50",
+				triggers.QuotaRetryPersistAnnotation:         "true",
+				triggers.CreateTimeoutAnnotation:             "30s",
+				triggers.H2CAnnotation:                       "true",
+				triggers.ActivityIntervalAnnotation:          "15s",
+				triggers.ProvenanceLabelsAnnotation:          "trigger",
+				triggers.AccessLogAnnotation:                 "true",
+				triggers.AccessLogFieldsAnnotation:           "eventID,status",
+				triggers.AccessLogSampleRateAnnotation:       "0.1",
+				triggers.BasePathAnnotation:                  "/webhooks/tekton",
+				triggers.TrustedProxiesAnnotation:            "10.0.0.0/8",
+				triggers.TrustedProxyHeaderAnnotation:        "Forwarded",
+				triggers.ProxyProtocolUpstreamsAnnotation:    "10.0.1.0/24",
+				triggers.EventIDHeadersAnnotation:            "X-GitHub-Delivery",
+				triggers.EventIDExpressionAnnotation:         "body.id",
+				triggers.FieldValidationAnnotation:           "Strict",
+				triggers.LabelSanitizationAnnotation:         "reject",
+				triggers.SynchronousAnnotation:               "true",
+				triggers.MultiStatusAnnotation:               "true",
+				triggers.RollbackOnFailureAnnotation:         "true",
+				triggers.CreateFailurePolicyAnnotation:       "best-effort",
+				triggers.AuditSinkAnnotation:                 "s3://audit/events",
+				triggers.AuditModeAnnotation:                 "instead",
+				triggers.AuditFailurePolicyAnnotation:        "best-effort",
+				triggers.PayloadParsersAnnotation:            "form,gzip",
+				triggers.SelfTestSecretAnnotation:            "self-test-token",
+				triggers.ConfigSecretAnnotation:              "config-token",
+				triggers.BatchSizeAnnotation:                 "100",
+				triggers.ResourcePoliciesAnnotation:          "resource-policies",
+				triggers.MutationHookURLAnnotation:           "https://defaults.platform.svc/mutate",
+				triggers.MutationHookTimeoutAnnotation:       "2s",
+				triggers.MutationHookFailurePolicyAnnotation: "fail-open",
+				triggers.CallbackURLAnnotation:               "https://chatops.example.com/hooks/tekton",
+				triggers.CallbackPayloadAnnotation:           `{"text": "$(trigger) fired"}`,
+				triggers.CallbackSecretAnnotation:            "callback-token",
+				triggers.CallbackTimeoutAnnotation:           "5s",
+				triggers.CallbackRetriesAnnotation:           "2",
+				triggers.ResourceEventsAnnotation:            "created,failed",
+				triggers.ResourceEventsURIAnnotation:         "http://broker-ingress.knative-eventing.svc/default/default",
+				triggers.ResourceEventsSourceAnnotation:      "/triggers/github",
+				triggers.DebugTraceSecretAnnotation:          "debug-trace-token",
+				triggers.DebugTraceSampleRateAnnotation:      "0.01",
+				triggers.InterceptorMetricsNamesAnnotation:   "50",
+				triggers.ResourceMetricsNamespacesAnnotation: "100",
+				triggers.NoMatchPolicyAnnotation:             "error",
+				triggers.NoMatchLogLevelAnnotation:           "warn",
+				triggers.DiscoveryGracePeriodAnnotation:      "5m",
+				triggers.DiscoveryBackoffAnnotation:          "2s",
+				triggers.EventWorkersAnnotation:              "8",
+				triggers.EventQueueSizeAnnotation:            "32",
+				triggers.EventOverflowAnnotation:             "wait",
+			}
+		}),
+		want: corev1.Container{
+			Name:  "event-listener",
+			Image: DefaultImage,
+			Ports: []corev1.ContainerPort{{
+				ContainerPort: int32(eventListenerContainerPort),
+				Protocol:      corev1.ProtocolTCP,
+			}},
+			Args: []string{
+				"--el-name=" + eventListenerName,
+				"--el-namespace=" + namespace,
+				"--port=" + strconv.Itoa(eventListenerContainerPort),
+				"--readtimeout=" + strconv.FormatInt(DefaultReadTimeout, 10),
+				"--readheadertimeout=" + strconv.FormatInt(DefaultReadHeaderTimeout, 10),
+				"--writetimeout=" + strconv.FormatInt(DefaultWriteTimeout, 10),
+				"--idletimeout=" + strconv.FormatInt(DefaultIdleTimeout, 10),
+				"--timeouthandler=" + strconv.FormatInt(DefaultTimeOutHandler, 10),
+				"--httpclient-readtimeout=" + strconv.FormatInt(DefaultHTTPClientReadTimeOut, 10),
+				"--httpclient-keep-alive=" + strconv.FormatInt(DefaultHTTPClientKeepAlive, 10),
+				"--httpclient-tlshandshaketimeout=" + strconv.FormatInt(DefaultHTTPClientTLSHandshakeTimeout, 10),
+				"--httpclient-responseheadertimeout=" + strconv.FormatInt(DefaultHTTPClientResponseHeaderTimeout, 10),
+				"--httpclient-expectcontinuetimeout=" + strconv.FormatInt(DefaultHTTPClientExpectContinueTimeout, 10),
+				"--resource-client-qps=" + strconv.FormatFloat(DefaultResourceClientQPS, 'f', -1, 64),
+				"--resource-client-burst=" + strconv.Itoa(DefaultResourceClientBurst),
+				"--resource-client-max-idle-conns=" + strconv.Itoa(DefaultResourceClientMaxIdleConns),
+				"--is-multi-ns=" + strconv.FormatBool(false),
+				"--payload-validation=" + strconv.FormatBool(true),
+				"--cloudevent-uri=",
+				"--backpressure-max-in-flight=10",
+				"--backpressure-retry-after=60",
+				"--interceptor-timeout=10s",
+				"--allowed-methods=POST,PUT",
+				"--allowed-content-types=application/json",
+				"--creation-limit=100",
+				"--creation-limit-window=1h",
+				"--creation-limit-per-trigger=true",
+				"--max-creates-per-event=20",
+				"--quota-retry-window=5m",
+				"--quota-retry-max-queued=50",
+				"--quota-retry-persist=true",
+				"--create-timeout=30s",
+				"--h2c=true",
+				"--activity-interval=15s",
+				"--provenance-labels=trigger",
+				"--access-log=true",
+				"--access-log-fields=eventID,status",
+				"--access-log-sample-rate=0.1",
+				"--base-path=/webhooks/tekton",
+				"--trusted-proxies=10.0.0.0/8",
+				"--trusted-proxy-header=Forwarded",
+				"--proxy-protocol-upstreams=10.0.1.0/24",
+				"--event-id-headers=X-GitHub-Delivery",
+				"--event-id-expression=body.id",
+				"--field-validation=Strict",
+				"--label-sanitization=reject",
+				"--synchronous=true",
+				"--multi-status=true",
+				"--rollback-on-failure=true",
+				"--create-failure-policy=best-effort",
+				"--audit-sink=s3://audit/events",
+				"--audit-mode=instead",
+				"--audit-failure-policy=best-effort",
+				"--payload-parsers=form,gzip",
+				"--self-test-secret=self-test-token",
+				"--config-secret=config-token",
+				"--batch-size=100",
+				"--resource-policies=resource-policies",
+				"--mutation-hook-url=https://defaults.platform.svc/mutate",
+				"--mutation-hook-timeout=2s",
+				"--mutation-hook-failure-policy=fail-open",
+				"--callback-url=https://chatops.example.com/hooks/tekton",
+				`--callback-payload={"text": "$(trigger) fired"}`,
+				"--callback-secret=callback-token",
+				"--callback-timeout=5s",
+				"--callback-retries=2",
+				"--resource-events=created,failed",
+				"--resource-events-uri=http://broker-ingress.knative-eventing.svc/default/default",
+				"--resource-events-source=/triggers/github",
+				"--debug-trace-secret=debug-trace-token",
+				"--debug-trace-sample-rate=0.01",
+				"--interceptor-metrics-names=50",
+				"--resource-metrics-namespaces=100",
+				"--no-match-policy=error",
+				"--no-match-log-level=warn",
+				"--discovery-grace-period=5m",
+				"--discovery-backoff=2s",
+				"--event-workers=8",
+				"--event-queue-size=32",
+				"--event-overflow=wait",
+			},
+			Env: []corev1.EnvVar{{
+				Name: "K_LOGGING_CONFIG",
+			}, {
+				Name: "K_METRICS_CONFIG",
+			}, {
+				Name: "K_TRACING_CONFIG",
+			}, {
+				Name:  "NAMESPACE",
+				Value: namespace,
+			}, {
+				Name:  "NAME",
+				Value: eventListenerName,
+			}, {
+				Name:  "EL_EVENT",
+				Value: "disable",
+			}, {
+				Name:  "K_SINK_TIMEOUT",
+				Value: strconv.FormatInt(DefaultTimeOutHandler, 10),
+			}},
+		},
 	}}
 
 	for _, tt := range tests {
@@ -295,3 +491,49 @@ func TestContainer(t *testing.T) {
 		})
 	}
 }
+
+func TestContainer_SinkPort(t *testing.T) {
+	config := *MakeConfig()
+	el := makeEL(func(el *v1beta1.EventListener) {
+		el.Annotations = map[string]string{triggers.SinkPortAnnotation: "9090"}
+	})
+
+	got := MakeContainer(el, &reconcilersource.EmptyVarsGenerator{}, config, addCertsForSecureConnection(config))
+	if diff := cmp.Diff([]corev1.ContainerPort{{ContainerPort: 9090, Protocol: corev1.ProtocolTCP}}, got.Ports); diff != "" {
+		t.Errorf("MakeContainer() ports -want, +got: %s", diff)
+	}
+	if got.Args[2] != "--port=9090" {
+		t.Errorf("MakeContainer() args[2] = %s, want --port=9090", got.Args[2])
+	}
+	for _, p := range []*corev1.Probe{got.LivenessProbe, got.ReadinessProbe} {
+		if p.HTTPGet.Port.IntValue() != 9090 {
+			t.Errorf("MakeContainer() probe port = %s, want 9090", p.HTTPGet.Port.String())
+		}
+	}
+}
+
+func TestContainer_TLSConfig(t *testing.T) {
+	minVersion, cipherSuites := "1.2", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"
+	config := *MakeConfig(func(c *Config) {
+		c.TLSMinVersion = &minVersion
+		c.TLSCipherSuites = &cipherSuites
+	})
+
+	got := MakeContainer(makeEL(), &reconcilersource.EmptyVarsGenerator{}, config)
+	want := []string{"--tls-min-version=1.2", "--tls-cipher-suites=" + cipherSuites}
+	if diff := cmp.Diff(want, got.Args[len(got.Args)-2:]); diff != "" {
+		t.Errorf("MakeContainer() TLS args -want, +got: %s", diff)
+	}
+}
+
+func TestContainer_LogFormat(t *testing.T) {
+	format := "json"
+	config := *MakeConfig(func(c *Config) {
+		c.LogFormat = &format
+	})
+
+	got := MakeContainer(makeEL(), &reconcilersource.EmptyVarsGenerator{}, config)
+	if last := got.Args[len(got.Args)-1]; last != "--log-format=json" {
+		t.Errorf("MakeContainer() last arg = %s, want --log-format=json", last)
+	}
+}
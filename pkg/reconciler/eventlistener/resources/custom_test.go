@@ -64,6 +64,7 @@ func TestCustomObject(t *testing.T) {
 		"--el-namespace=" + namespace,
 		"--port=" + strconv.Itoa(eventListenerContainerPort),
 		"--readtimeout=" + strconv.FormatInt(DefaultReadTimeout, 10),
+		"--readheadertimeout=" + strconv.FormatInt(DefaultReadHeaderTimeout, 10),
 		"--writetimeout=" + strconv.FormatInt(DefaultWriteTimeout, 10),
 		"--idletimeout=" + strconv.FormatInt(DefaultIdleTimeout, 10),
 		"--timeouthandler=" + strconv.FormatInt(DefaultTimeOutHandler, 10),
@@ -72,6 +73,9 @@ func TestCustomObject(t *testing.T) {
 		"--httpclient-tlshandshaketimeout=" + strconv.FormatInt(DefaultHTTPClientTLSHandshakeTimeout, 10),
 		"--httpclient-responseheadertimeout=" + strconv.FormatInt(DefaultHTTPClientResponseHeaderTimeout, 10),
 		"--httpclient-expectcontinuetimeout=" + strconv.FormatInt(DefaultHTTPClientExpectContinueTimeout, 10),
+		"--resource-client-qps=" + strconv.FormatFloat(DefaultResourceClientQPS, 'f', -1, 64),
+		"--resource-client-burst=" + strconv.Itoa(DefaultResourceClientBurst),
+		"--resource-client-max-idle-conns=" + strconv.Itoa(DefaultResourceClientMaxIdleConns),
 		"--is-multi-ns=" + strconv.FormatBool(false),
 		"--payload-validation=" + strconv.FormatBool(true),
 		"--cloudevent-uri=",
@@ -160,7 +164,7 @@ func TestCustomObject(t *testing.T) {
 									"resources": map[string]interface{}{},
 									"readinessProbe": map[string]interface{}{
 										"httpGet": map[string]interface{}{
-											"path":   "/live",
+											"path":   "/ready",
 											"port":   int64(0),
 											"scheme": "HTTP",
 										},
@@ -227,7 +231,7 @@ func TestCustomObject(t *testing.T) {
 									"resources": map[string]interface{}{},
 									"readinessProbe": map[string]interface{}{
 										"httpGet": map[string]interface{}{
-											"path":   "/live",
+											"path":   "/ready",
 											"port":   int64(0),
 											"scheme": "HTTP",
 										},
@@ -294,7 +298,7 @@ func TestCustomObject(t *testing.T) {
 									},
 									"readinessProbe": map[string]interface{}{
 										"httpGet": map[string]interface{}{
-											"path":   "/live",
+											"path":   "/ready",
 											"port":   int64(0),
 											"scheme": "HTTP",
 										},
@@ -444,7 +448,7 @@ func TestUpdateCustomObject(t *testing.T) {
 									},
 									"readinessProbe": map[string]interface{}{
 										"httpGet": map[string]interface{}{
-											"path":   "/live",
+											"path":   "/ready",
 											"port":   int64(0),
 											"scheme": "HTTP",
 										},
@@ -193,7 +193,7 @@ func addCertsForSecureConnection(c Config) ContainerOption {
 				HTTPGet: &corev1.HTTPGetAction{
 					Path:   "/live",
 					Scheme: scheme,
-					Port:   intstr.FromInt(eventListenerContainerPort),
+					Port:   intstr.FromInt(int(container.Ports[0].ContainerPort)),
 				},
 			},
 			PeriodSeconds:    int32(*c.PeriodSeconds),
@@ -202,14 +202,17 @@ func addCertsForSecureConnection(c Config) ContainerOption {
 		container.ReadinessProbe = &corev1.Probe{
 			ProbeHandler: corev1.ProbeHandler{
 				HTTPGet: &corev1.HTTPGetAction{
-					Path:   "/live",
+					Path:   "/ready",
 					Scheme: scheme,
-					Port:   intstr.FromInt(eventListenerContainerPort),
+					Port:   intstr.FromInt(int(container.Ports[0].ContainerPort)),
 				},
 			},
 			PeriodSeconds:    int32(*c.PeriodSeconds),
 			FailureThreshold: int32(*c.FailureThreshold),
 		}
 		container.Args = append(container.Args, "--tls-cert="+elCert, "--tls-key="+elKey)
+		if v, ok := certEnv["TLS_CLIENT_CA"]; ok && v != nil && v.SecretKeyRef != nil && scheme == corev1.URISchemeHTTPS {
+			container.Args = append(container.Args, "--tls-client-ca=/etc/triggers/tls/"+v.SecretKeyRef.Key)
+		}
 	}
 }
@@ -314,6 +314,48 @@ func TestDeploymentError(t *testing.T) {
 	}
 }
 
+func TestAddCertsForSecureConnection_ClientCA(t *testing.T) {
+	secretEnv := func(name, key string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					Key:                  key,
+					LocalObjectReference: corev1.LocalObjectReference{Name: "tls-secret"},
+				},
+			},
+		}
+	}
+	for _, tt := range []struct {
+		name string
+		env  []corev1.EnvVar
+		want []string
+	}{{
+		name: "TLS with client CA",
+		env:  []corev1.EnvVar{secretEnv("TLS_CERT", "cert"), secretEnv("TLS_KEY", "key"), secretEnv("TLS_CLIENT_CA", "ca.crt")},
+		want: []string{"--tls-cert=/etc/triggers/tls/cert", "--tls-key=/etc/triggers/tls/key", "--tls-client-ca=/etc/triggers/tls/ca.crt"},
+	}, {
+		name: "TLS without client CA",
+		env:  []corev1.EnvVar{secretEnv("TLS_CERT", "cert"), secretEnv("TLS_KEY", "key")},
+		want: []string{"--tls-cert=/etc/triggers/tls/cert", "--tls-key=/etc/triggers/tls/key"},
+	}, {
+		name: "client CA without TLS",
+		env:  []corev1.EnvVar{secretEnv("TLS_CLIENT_CA", "ca.crt")},
+		want: []string{"--tls-cert=", "--tls-key="},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			container := corev1.Container{
+				Env:   tt.env,
+				Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+			}
+			addCertsForSecureConnection(*MakeConfig())(&container)
+			if diff := cmp.Diff(tt.want, container.Args); diff != "" {
+				t.Errorf("addCertsForSecureConnection() args did not match. -want, +got: %s", diff)
+			}
+		})
+	}
+}
+
 func withTLSEnvFrom(name string) func(*v1beta1.EventListener) {
 	return func(el *v1beta1.EventListener) {
 		el.Spec.Resources.KubernetesResource = &v1beta1.KubernetesResource{
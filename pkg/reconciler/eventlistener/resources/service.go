@@ -19,7 +19,9 @@ package resources
 import (
 	"context"
 	"fmt"
+	"strconv"
 
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
 	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -33,10 +35,12 @@ const (
 	eventListenerServiceTLSPortName = "https-listener"
 	// eventListenerMetricsPortName defines the metrics port name by the EventListener Container
 	eventListenerMetricsPortName = "http-metrics"
-	// eventListenerContainerPort defines service port for EventListener Service
+	// eventListenerContainerPort defines the default port the EventListener Container listens on
 	eventListenerContainerPort = 8080
+	// h2cAppProtocol is the appProtocol of the service port of EventListeners serving HTTP/2 over cleartext
+	h2cAppProtocol = "kubernetes.io/h2c"
 	// eventListenerMetricsPort defines metrics port for EventListener Service
-	eventListenerMetricsPort = 9000
+	eventListenerMetricsPort = triggers.EventListenerMetricsPort
 )
 
 var metricsPort = corev1.ServicePort{
@@ -109,14 +113,27 @@ func ServicePort(el *v1beta1.EventListener, c Config) corev1.ServicePort {
 		}
 	}
 
-	return corev1.ServicePort{
+	sp := corev1.ServicePort{
 		Name:     servicePortName,
 		Protocol: corev1.ProtocolTCP,
 		Port:     int32(servicePortPort),
 		TargetPort: intstr.IntOrString{
-			IntVal: int32(eventListenerContainerPort),
+			IntVal: int32(containerPort(el)),
 		},
 	}
+	if el.GetAnnotations()[triggers.H2CAnnotation] == "true" && servicePortName == eventListenerServicePortName {
+		appProtocol := h2cAppProtocol
+		sp.AppProtocol = &appProtocol
+	}
+	return sp
+}
+
+// containerPort returns the port the EventListener Container listens on.
+func containerPort(el *v1beta1.EventListener) int {
+	if port, err := strconv.Atoi(el.GetAnnotations()[triggers.SinkPortAnnotation]); err == nil {
+		return port
+	}
+	return eventListenerContainerPort
 }
 
 // ListenerHostname returns the intended hostname for the EventListener service.
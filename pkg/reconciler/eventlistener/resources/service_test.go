@@ -21,12 +21,14 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
 	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/ptr"
 )
 
 func TestService(t *testing.T) {
@@ -216,6 +218,24 @@ func TestServicePort(t *testing.T) {
 			},
 		},
 		expectedServiceType: "LoadBalancer",
+	}, {
+		name: "EventListener with sink port and h2c annotations",
+		el: makeEL(withStatus, func(el *v1beta1.EventListener) {
+			el.Annotations = map[string]string{
+				triggers.SinkPortAnnotation: "9090",
+				triggers.H2CAnnotation:      "true",
+			}
+		}),
+		config: *MakeConfig(),
+		expectedServicePort: corev1.ServicePort{
+			Name:        eventListenerServicePortName,
+			Protocol:    corev1.ProtocolTCP,
+			AppProtocol: ptr.String(h2cAppProtocol),
+			Port:        int32(DefaultPort),
+			TargetPort: intstr.IntOrString{
+				IntVal: 9090,
+			},
+		},
 	}}
 
 	for _, tt := range tests {
@@ -0,0 +1,250 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventlistener
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/logging"
+)
+
+// secretReference is a secret referenced by the params of an interceptor.
+type secretReference struct {
+	v1beta1.SecretRef
+	// source describes where the reference is, for the status messages.
+	source string
+	// triggerNamespace is the namespace of the trigger of the interceptor, which the secret is read from
+	// unless the reference specifies another one.
+	triggerNamespace string
+}
+
+// referencedSecrets returns the secrets referenced by the secretRef and additionalSecretRefs
+// params, and the required headers of the header interceptor, of the interceptors of the triggers and trigger groups embedded in the EventListener,
+// which are read from its namespace unless they specify another one, and of the interceptors of the
+// Triggers it selects, which are read from their namespace. Params that don't parse are left to the
+// interceptors to report.
+func referencedSecrets(el *v1beta1.EventListener, triggers []*v1beta1.Trigger) []secretReference {
+	var refs []secretReference
+	add := func(source, triggerNamespace string, interceptors []*v1beta1.TriggerInterceptor) {
+		for i, interceptor := range interceptors {
+			if interceptor == nil {
+				continue
+			}
+			name := interceptor.GetName()
+			if interceptor.Name != nil {
+				name = *interceptor.Name
+			}
+			src := fmt.Sprintf("interceptor %d (%s) of %s", i, name, source)
+			addRefs := func(srs ...v1beta1.SecretRef) {
+				for _, sr := range srs {
+					if sr.SecretName != "" {
+						refs = append(refs, secretReference{SecretRef: sr, source: src, triggerNamespace: triggerNamespace})
+					}
+				}
+			}
+			for _, p := range interceptor.Params {
+				switch p.Name {
+				case "secretRef":
+					var sr v1beta1.SecretRef
+					if err := json.Unmarshal(p.Value.Raw, &sr); err == nil {
+						addRefs(sr)
+					}
+				case "additionalSecretRefs":
+					var srs []v1beta1.SecretRef
+					if err := json.Unmarshal(p.Value.Raw, &srs); err == nil {
+						addRefs(srs...)
+					}
+				case "headers":
+					// The required headers of the header interceptor reference their secret values.
+					var headers []struct {
+						SecretRef            v1beta1.SecretRef   `json:"secretRef"`
+						AdditionalSecretRefs []v1beta1.SecretRef `json:"additionalSecretRefs"`
+					}
+					if err := json.Unmarshal(p.Value.Raw, &headers); err == nil {
+						for _, h := range headers {
+							addRefs(h.SecretRef)
+							addRefs(h.AdditionalSecretRefs...)
+						}
+					}
+				}
+			}
+		}
+	}
+	for i, t := range el.Spec.Triggers {
+		add(fmt.Sprintf("trigger %q", triggerName(i, t.Name)), el.Namespace, t.Interceptors)
+	}
+	for i, g := range el.Spec.TriggerGroups {
+		add(fmt.Sprintf("trigger group %q", triggerName(i, g.Name)), el.Namespace, g.Interceptors)
+	}
+	for _, t := range triggers {
+		add(fmt.Sprintf("Trigger %s/%s", t.Namespace, t.Name), t.Namespace, t.Spec.Interceptors)
+	}
+	return refs
+}
+
+// selectsTrigger returns true if the EventListener references the Trigger by name, or selects it with its
+// namespace and label selectors or those of one of its trigger groups, like its sink does.
+func selectsTrigger(el *v1beta1.EventListener, t *v1beta1.Trigger) bool {
+	for _, et := range el.Spec.Triggers {
+		if et.Template == nil && et.TriggerRef == t.Name && t.Namespace == el.Namespace {
+			return true
+		}
+	}
+	if selectorSelects(el.Namespace, el.Spec.NamespaceSelector, el.Spec.LabelSelector, t) {
+		return true
+	}
+	for _, g := range el.Spec.TriggerGroups {
+		if selectorSelects(el.Namespace, g.TriggerSelector.NamespaceSelector, g.TriggerSelector.LabelSelector, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectorSelects returns true if the namespace and label selectors of an EventListener in namespace
+// select the Trigger: without a namespace selector, only the Triggers of its namespace are selected, and
+// only if there is a label selector.
+func selectorSelects(namespace string, namespaceSelector v1beta1.NamespaceSelector, labelSelector *metav1.LabelSelector, t *v1beta1.Trigger) bool {
+	switch {
+	case len(namespaceSelector.MatchNames) == 1 && namespaceSelector.MatchNames[0] == "*":
+	case len(namespaceSelector.MatchNames) != 0:
+		if !sets.NewString(namespaceSelector.MatchNames...).Has(t.Namespace) {
+			return false
+		}
+	case labelSelector == nil:
+		return false
+	default:
+		if t.Namespace != namespace {
+			return false
+		}
+	}
+	if labelSelector == nil {
+		return true
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(t.Labels))
+}
+
+// referencedTriggers returns the Triggers the EventListener selects.
+func (r *Reconciler) referencedTriggers(el *v1beta1.EventListener) ([]*v1beta1.Trigger, error) {
+	all, err := r.triggerLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var triggers []*v1beta1.Trigger
+	for _, t := range all {
+		if selectsTrigger(el, t) {
+			triggers = append(triggers, t)
+		}
+	}
+	sort.Slice(triggers, func(i, j int) bool {
+		if triggers[i].Namespace != triggers[j].Namespace {
+			return triggers[i].Namespace < triggers[j].Namespace
+		}
+		return triggers[i].Name < triggers[j].Name
+	})
+	return triggers, nil
+}
+
+func triggerName(i int, name string) string {
+	if name == "" {
+		return fmt.Sprint(i)
+	}
+	return name
+}
+
+// namespace returns the namespace of the referenced secret, or an empty string if the namespace of the
+// reference is invalid.
+func (ref secretReference) namespace() string {
+	ns, err := interceptors.SecretNamespace(ref.triggerNamespace, &ref.SecretRef)
+	if err != nil {
+		return ""
+	}
+	return ns
+}
+
+// referencesSecret returns true if the interceptors of the EventListener, or of the Triggers it selects,
+// reference the secret.
+func referencesSecret(el *v1beta1.EventListener, triggers []*v1beta1.Trigger, namespace, name string) bool {
+	for _, ref := range referencedSecrets(el, triggers) {
+		if ref.SecretName == name && ref.namespace() == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileSecrets checks that the secrets referenced by the interceptors of the EventListener
+// exist and are shared with the namespace of the EventListener if they are in another one, and sets
+// the SecretsExist condition accordingly, so that the EventListener isn't Ready while events would
+// fail to be processed. Only the metadata of the secrets is checked, so that the controller never
+// reads their data: the missing keys are reported by the interceptors.
+func (r *Reconciler) reconcileSecrets(ctx context.Context, el *v1beta1.EventListener) error {
+	triggers, err := r.referencedTriggers(el)
+	if err != nil {
+		logging.FromContext(ctx).Error(err)
+		return err
+	}
+	refs := referencedSecrets(el, triggers)
+	var missing []string
+	for _, ref := range refs {
+		namespace, err := interceptors.SecretNamespace(ref.triggerNamespace, &ref.SecretRef)
+		if err != nil {
+			missing = append(missing, fmt.Sprintf("secret %q referenced by %s: %v", ref.SecretName, ref.source, err))
+			continue
+		}
+		obj, err := r.secretLister.ByNamespace(namespace).Get(ref.SecretName)
+		switch {
+		case apierrors.IsNotFound(err):
+			missing = append(missing, fmt.Sprintf("secret %q referenced by %s does not exist", ref.SecretName, ref.source))
+			continue
+		case err != nil:
+			logging.FromContext(ctx).Error(err)
+			return err
+		}
+		secret, err := meta.Accessor(obj)
+		if err != nil {
+			logging.FromContext(ctx).Error(err)
+			return err
+		}
+		if interceptors.CheckSecretAccess(secret, ref.triggerNamespace) != nil {
+			missing = append(missing, fmt.Sprintf("secret %q of namespace %s referenced by %s is not shared with namespace %s", ref.SecretName, namespace, ref.source, ref.triggerNamespace))
+		}
+	}
+	var missingErr error
+	if len(missing) > 0 {
+		missingErr = errors.New(strings.Join(missing, "; "))
+		logging.FromContext(ctx).Infof("EventListener %s/%s is not ready: %s", el.Namespace, el.Name, missingErr)
+	}
+	el.Status.SetSecretsCondition(len(refs) > 0, missingErr)
+	return nil
+}
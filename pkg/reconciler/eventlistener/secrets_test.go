@@ -0,0 +1,318 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventlistener
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/test"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/ptr"
+)
+
+func withSecretRefs(t *testing.T) func(*v1beta1.EventListener) {
+	t.Helper()
+	return func(el *v1beta1.EventListener) {
+		el.Spec.Triggers = []v1beta1.EventListenerTrigger{{
+			Name: "github-push",
+			Interceptors: []*v1beta1.EventInterceptor{{
+				Ref: v1beta1.InterceptorRef{Name: "github"},
+				Params: []v1beta1.InterceptorParams{{
+					Name:  "secretRef",
+					Value: test.ToV1JSON(t, map[string]string{"secretName": "github-secret", "secretKey": "token"}),
+				}, {
+					Name:  "eventTypes",
+					Value: test.ToV1JSON(t, []string{"push"}),
+				}},
+			}},
+		}}
+		el.Spec.TriggerGroups = []v1beta1.EventListenerTriggerGroup{{
+			Name: "gitlab",
+			Interceptors: []*v1beta1.TriggerInterceptor{{
+				Name: ptr.String("verify"),
+				Ref:  v1beta1.InterceptorRef{Name: "gitlab"},
+				Params: []v1beta1.InterceptorParams{{
+					Name:  "additionalSecretRefs",
+					Value: test.ToV1JSON(t, []map[string]string{{"secretName": "gitlab-old", "secretKey": "token"}}),
+				}, {
+					Name:  "secretRef",
+					Value: test.ToV1JSON(t, "not a secret ref"),
+				}},
+			}, {
+				Ref: v1beta1.InterceptorRef{Name: "header"},
+				Params: []v1beta1.InterceptorParams{{
+					Name: "headers",
+					Value: test.ToV1JSON(t, []map[string]interface{}{
+						{"name": "X-Source", "values": []string{"ci"}},
+						{"name": "X-Internal-Token", "secretRef": map[string]string{"secretName": "internal-token", "secretKey": "token"}},
+					}),
+				}},
+			}},
+		}}
+	}
+}
+
+// makeSecretTrigger returns a Trigger of the namespace whose interceptor references the secret.
+func makeSecretTrigger(t *testing.T, namespace, name, secret string, labels map[string]string) *v1beta1.Trigger {
+	t.Helper()
+	return &v1beta1.Trigger{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec: v1beta1.TriggerSpec{
+			Interceptors: []*v1beta1.TriggerInterceptor{{
+				Ref: v1beta1.InterceptorRef{Name: "gitlab"},
+				Params: []v1beta1.InterceptorParams{{
+					Name:  "secretRef",
+					Value: test.ToV1JSON(t, map[string]string{"secretName": secret, "secretKey": "token"}),
+				}},
+			}},
+			Template: v1beta1.TriggerSpecTemplate{Ref: ptr.String("tt")},
+		},
+	}
+}
+
+func TestReferencedSecrets(t *testing.T) {
+	trigger := makeSecretTrigger(t, "team-a", "deploy", "deploy-token", nil)
+	got := referencedSecrets(makeEL(withSecretRefs(t)), []*v1beta1.Trigger{trigger})
+	want := []secretReference{{
+		SecretRef:        v1beta1.SecretRef{SecretName: "github-secret", SecretKey: "token"},
+		source:           `interceptor 0 (github) of trigger "github-push"`,
+		triggerNamespace: namespace,
+	}, {
+		SecretRef:        v1beta1.SecretRef{SecretName: "gitlab-old", SecretKey: "token"},
+		source:           `interceptor 0 (verify) of trigger group "gitlab"`,
+		triggerNamespace: namespace,
+	}, {
+		SecretRef:        v1beta1.SecretRef{SecretName: "internal-token", SecretKey: "token"},
+		source:           `interceptor 1 (header) of trigger group "gitlab"`,
+		triggerNamespace: namespace,
+	}, {
+		SecretRef:        v1beta1.SecretRef{SecretName: "deploy-token", SecretKey: "token"},
+		source:           "interceptor 0 (gitlab) of Trigger team-a/deploy",
+		triggerNamespace: "team-a",
+	}}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(secretReference{})); diff != "" {
+		t.Errorf("referencedSecrets() -want +got: %s", diff)
+	}
+	if referencedSecrets(makeEL(), nil) != nil {
+		t.Error("referencedSecrets() returned secrets for an EventListener without interceptors")
+	}
+	if !referencesSecret(makeEL(withSecretRefs(t)), nil, namespace, "gitlab-old") || referencesSecret(makeEL(withSecretRefs(t)), nil, namespace, "other") {
+		t.Error("referencesSecret() did not match the referenced secrets")
+	}
+	if !referencesSecret(makeEL(withSecretRefs(t), withSharedSecretRef(t)), nil, "team-a", "github-secret") || referencesSecret(makeEL(withSecretRefs(t), withSharedSecretRef(t)), nil, namespace, "github-secret") {
+		t.Error("referencesSecret() did not match the secret referenced in another namespace")
+	}
+	if !referencesSecret(makeEL(), []*v1beta1.Trigger{trigger}, "team-a", "deploy-token") || referencesSecret(makeEL(), []*v1beta1.Trigger{trigger}, namespace, "deploy-token") {
+		t.Error("referencesSecret() did not match the secret referenced by a selected Trigger in its namespace")
+	}
+}
+
+func TestSelectsTrigger(t *testing.T) {
+	ci := map[string]string{"team": "ci"}
+	for _, tc := range []struct {
+		name    string
+		el      func(*v1beta1.EventListener)
+		trigger *v1beta1.Trigger
+		want    bool
+	}{{
+		name: "triggerRef",
+		el: func(el *v1beta1.EventListener) {
+			el.Spec.Triggers = []v1beta1.EventListenerTrigger{{TriggerRef: "deploy"}}
+		},
+		trigger: makeSecretTrigger(t, namespace, "deploy", "token", nil),
+		want:    true,
+	}, {
+		name: "triggerRef to another namespace",
+		el: func(el *v1beta1.EventListener) {
+			el.Spec.Triggers = []v1beta1.EventListenerTrigger{{TriggerRef: "deploy"}}
+		},
+		trigger: makeSecretTrigger(t, "team-a", "deploy", "token", nil),
+	}, {
+		name: "label selector",
+		el: func(el *v1beta1.EventListener) {
+			el.Spec.LabelSelector = &metav1.LabelSelector{MatchLabels: ci}
+		},
+		trigger: makeSecretTrigger(t, namespace, "deploy", "token", ci),
+		want:    true,
+	}, {
+		name: "label selector of another namespace",
+		el: func(el *v1beta1.EventListener) {
+			el.Spec.LabelSelector = &metav1.LabelSelector{MatchLabels: ci}
+		},
+		trigger: makeSecretTrigger(t, "team-a", "deploy", "token", ci),
+	}, {
+		name: "namespace selector",
+		el: func(el *v1beta1.EventListener) {
+			el.Spec.NamespaceSelector = v1beta1.NamespaceSelector{MatchNames: []string{"team-a"}}
+		},
+		trigger: makeSecretTrigger(t, "team-a", "deploy", "token", nil),
+		want:    true,
+	}, {
+		name: "all namespaces with a label selector",
+		el: func(el *v1beta1.EventListener) {
+			el.Spec.NamespaceSelector = v1beta1.NamespaceSelector{MatchNames: []string{"*"}}
+			el.Spec.LabelSelector = &metav1.LabelSelector{MatchLabels: ci}
+		},
+		trigger: makeSecretTrigger(t, "team-b", "deploy", "token", map[string]string{"team": "cd"}),
+	}, {
+		name: "trigger group selector",
+		el: func(el *v1beta1.EventListener) {
+			el.Spec.TriggerGroups = []v1beta1.EventListenerTriggerGroup{{
+				TriggerSelector: v1beta1.EventListenerTriggerSelector{LabelSelector: &metav1.LabelSelector{MatchLabels: ci}},
+			}}
+		},
+		trigger: makeSecretTrigger(t, namespace, "deploy", "token", ci),
+		want:    true,
+	}, {
+		name:    "no selectors",
+		el:      func(el *v1beta1.EventListener) {},
+		trigger: makeSecretTrigger(t, namespace, "deploy", "token", nil),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := selectsTrigger(makeEL(tc.el), tc.trigger); got != tc.want {
+				t.Errorf("selectsTrigger() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+// withSharedSecretRef makes the first trigger reference the github-secret of the team-a namespace.
+func withSharedSecretRef(t *testing.T) func(*v1beta1.EventListener) {
+	t.Helper()
+	return func(el *v1beta1.EventListener) {
+		el.Spec.Triggers[0].Interceptors[0].Params[0].Value = test.ToV1JSON(t, map[string]string{"secretName": "github-secret", "secretKey": "token", "namespace": "team-a"})
+	}
+}
+
+func TestReconcile_Secrets(t *testing.T) {
+	if err := os.Setenv("METRICS_PROMETHEUS_PORT", "9000"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("SYSTEM_NAMESPACE", "tekton-pipelines"); err != nil {
+		t.Fatal(err)
+	}
+	shared := func(s *corev1.Secret, allowed string) *corev1.Secret {
+		s.Namespace = "team-a"
+		if allowed != "" {
+			s.Annotations = map[string]string{interceptors.AllowedTriggerNamespacesAnnotation: allowed}
+		}
+		return s
+	}
+	secret := func(name string, keys ...string) *corev1.Secret {
+		s := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Data:       map[string][]byte{},
+		}
+		for _, k := range keys {
+			s.Data[k] = []byte("secret")
+		}
+		return s
+	}
+
+	for _, tc := range []struct {
+		name        string
+		opts        []func(*v1beta1.EventListener)
+		triggers    []*v1beta1.Trigger
+		secrets     []*corev1.Secret
+		wantStatus  corev1.ConditionStatus
+		wantMessage string
+	}{{
+		name:        "secrets exist",
+		secrets:     []*corev1.Secret{secret("github-secret", "token"), secret("gitlab-old", "token"), secret("internal-token", "token")},
+		wantStatus:  corev1.ConditionTrue,
+		wantMessage: "Referenced secrets exist",
+	}, {
+		name:        "secret missing",
+		secrets:     []*corev1.Secret{secret("github-secret", "token"), secret("internal-token", "token")},
+		wantStatus:  corev1.ConditionFalse,
+		wantMessage: `secret "gitlab-old" referenced by interceptor 0 (verify) of trigger group "gitlab" does not exist`,
+	}, {
+		// The controller only checks the metadata of the secrets.
+		name:        "key missing",
+		secrets:     []*corev1.Secret{secret("github-secret", "other"), secret("gitlab-old", "token"), secret("internal-token", "token")},
+		wantStatus:  corev1.ConditionTrue,
+		wantMessage: "Referenced secrets exist",
+	}, {
+		name:        "secret shared from another namespace",
+		opts:        []func(*v1beta1.EventListener){withSharedSecretRef(t)},
+		secrets:     []*corev1.Secret{shared(secret("github-secret", "token"), namespace), secret("gitlab-old", "token"), secret("internal-token", "token")},
+		wantStatus:  corev1.ConditionTrue,
+		wantMessage: "Referenced secrets exist",
+	}, {
+		name:       "secret of another namespace not shared",
+		opts:       []func(*v1beta1.EventListener){withSharedSecretRef(t)},
+		secrets:    []*corev1.Secret{shared(secret("github-secret", "token"), "other"), secret("gitlab-old", "token"), secret("internal-token", "token")},
+		wantStatus: corev1.ConditionFalse,
+		wantMessage: `secret "github-secret" of namespace team-a referenced by interceptor 0 (github) of trigger "github-push" ` +
+			`is not shared with namespace ` + namespace,
+	}, {
+		name: "secret of a selected Trigger missing",
+		opts: []func(*v1beta1.EventListener){func(el *v1beta1.EventListener) {
+			el.Spec.NamespaceSelector = v1beta1.NamespaceSelector{MatchNames: []string{"team-a"}}
+		}},
+		triggers:    []*v1beta1.Trigger{makeSecretTrigger(t, "team-a", "deploy", "deploy-token", nil)},
+		secrets:     []*corev1.Secret{secret("github-secret", "token"), secret("gitlab-old", "token"), secret("internal-token", "token"), secret("deploy-token", "token")},
+		wantStatus:  corev1.ConditionFalse,
+		wantMessage: `secret "deploy-token" referenced by interceptor 0 (gitlab) of Trigger team-a/deploy does not exist`,
+	}, {
+		name: "secret of a selected Trigger in its namespace",
+		opts: []func(*v1beta1.EventListener){func(el *v1beta1.EventListener) {
+			el.Spec.NamespaceSelector = v1beta1.NamespaceSelector{MatchNames: []string{"team-a"}}
+		}},
+		triggers:    []*v1beta1.Trigger{makeSecretTrigger(t, "team-a", "deploy", "deploy-token", nil)},
+		secrets:     []*corev1.Secret{secret("github-secret", "token"), secret("gitlab-old", "token"), secret("internal-token", "token"), shared(secret("deploy-token", "token"), "")},
+		wantStatus:  corev1.ConditionTrue,
+		wantMessage: "Referenced secrets exist",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			testAssets, cancel := getEventListenerTestAssets(t, test.Resources{
+				Namespaces:     []*corev1.Namespace{namespaceResource},
+				EventListeners: []*v1beta1.EventListener{makeEL(append([]func(*v1beta1.EventListener){withStatus, withSecretRefs(t)}, tc.opts...)...)},
+				Deployments: []*appsv1.Deployment{makeDeployment(func(d *appsv1.Deployment) {
+					d.Status.Conditions = []appsv1.DeploymentCondition{deploymentAvailableCondition, deploymentProgressingCondition}
+				})},
+				Services: []*corev1.Service{makeService()},
+				Triggers: tc.triggers,
+				Secrets:  tc.secrets,
+			}, nil)
+			defer cancel()
+
+			if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), reconcileKey); err != nil {
+				t.Fatalf("eventlistener.Reconcile() returned error: %s", err)
+			}
+			el, err := testAssets.Clients.Triggers.TriggersV1beta1().EventListeners(namespace).Get(context.Background(), eventListenerName, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cond := el.Status.GetCondition(v1beta1.SecretsExist)
+			if cond == nil || cond.Status != tc.wantStatus || cond.Message != tc.wantMessage {
+				t.Errorf("got Secrets condition %+v, want status %s with message %q", cond, tc.wantStatus, tc.wantMessage)
+			}
+			if ready := el.Status.GetCondition(apis.ConditionReady); ready == nil || ready.Status != tc.wantStatus {
+				t.Errorf("got Ready condition %+v, want status %s", ready, tc.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	discoveryclient "k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// AuditRecord is the record of a resource created for an event, written by an AuditingCreator.
+type AuditRecord struct {
+	// Time is when the resource was created.
+	Time metav1.Time `json:"time"`
+	// EventListener is the name of the EventListener that received the event.
+	EventListener string `json:"eventListener"`
+	// EventListenerNamespace is the namespace of the EventListener.
+	EventListenerNamespace string `json:"eventListenerNamespace"`
+	// EventID is the ID of the event.
+	EventID string `json:"eventID"`
+	// Trigger is the name of the Trigger that created the resource.
+	Trigger string `json:"trigger"`
+	// Created is whether the resource was created in the cluster, rather than only recorded.
+	Created bool `json:"created"`
+	// Resource identifies the resource.
+	Resource AuditedResource `json:"resource"`
+	// Manifest is the rendered resource template.
+	Manifest json.RawMessage `json:"manifest"`
+}
+
+// AuditedResource identifies the resource of an AuditRecord.
+type AuditedResource struct {
+	APIVersion   string    `json:"apiVersion"`
+	Kind         string    `json:"kind"`
+	Namespace    string    `json:"namespace,omitempty"`
+	Name         string    `json:"name,omitempty"`
+	GenerateName string    `json:"generateName,omitempty"`
+	UID          types.UID `json:"uid,omitempty"`
+}
+
+// AuditWriter writes audit records to durable storage.
+type AuditWriter interface {
+	WriteAudit(ctx context.Context, record *AuditRecord) error
+}
+
+// NewAuditWriter returns the AuditWriter for the value of the triggers.AuditSinkAnnotation: a DirAuditWriter
+// for file URLs and an S3AuditWriter, with the credentials in the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// optional AWS_SESSION_TOKEN environment variables, for s3 URLs.
+func NewAuditWriter(sink string, client *http.Client) (AuditWriter, error) {
+	if err := triggers.ValidateAuditSink(sink); err != nil {
+		return nil, err
+	}
+	u, _ := url.Parse(sink)
+	if u.Scheme == "file" {
+		return &DirAuditWriter{Dir: u.Path}, nil
+	}
+	w := &S3AuditWriter{
+		Endpoint:        u.Query().Get("endpoint"),
+		Region:          u.Query().Get("region"),
+		Bucket:          u.Host,
+		Prefix:          strings.Trim(u.Path, "/"),
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		Client:          client,
+	}
+	if w.Region == "" {
+		w.Region = "us-east-1"
+	}
+	if w.Endpoint == "" {
+		w.Endpoint = "https://s3." + w.Region + ".amazonaws.com"
+	}
+	if w.AccessKeyID == "" || w.SecretAccessKey == "" {
+		return nil, fmt.Errorf("audit sink %s requires the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables", sink)
+	}
+	return w, nil
+}
+
+// AuditingCreator is a Creator that writes an audit record of each resource it creates with Writer.
+type AuditingCreator struct {
+	// Creator creates the resources. Defaults to DefaultCreator if nil.
+	Creator Creator
+	// Writer writes the audit records.
+	Writer AuditWriter
+	// EventListenerNamespace is the namespace of the EventListener, recorded in the audit records.
+	EventListenerNamespace string
+	// SkipCreate only writes the audit records of the resources, which are returned as they would be
+	// created, instead of creating them.
+	SkipCreate bool
+	// BestEffort logs the failures to write audit records instead of failing the creation of the resources.
+	// Otherwise a created resource whose record can't be written is deleted, unless it may have been patched.
+	BestEffort bool
+}
+
+var _ Creator = (*AuditingCreator)(nil)
+
+// Create creates the resource and writes its audit record.
+func (a *AuditingCreator) Create(ctx context.Context, logger *zap.SugaredLogger, rt json.RawMessage, triggerName, eventID, elName, elNamespace string, c discoveryclient.ServerResourcesInterface, dc dynamic.Interface) (*unstructured.Unstructured, error) {
+	record := &AuditRecord{
+		EventListener:          elName,
+		EventListenerNamespace: a.EventListenerNamespace,
+		EventID:                eventID,
+		Trigger:                triggerName,
+		Created:                !a.SkipCreate,
+		Manifest:               rt,
+	}
+
+	if a.SkipCreate {
+		data, _, err := prepare(ctx, rt, triggerName, eventID, elName)
+		if err != nil {
+			return nil, err
+		}
+		if data.GetNamespace() == "" {
+			data.SetNamespace(elNamespace)
+		}
+		record.Time = metav1.Now()
+		record.Resource = auditedResource(data)
+		// The audit record is the only trace of the resource, so failing to write it is always fatal.
+		if err := a.Writer.WriteAudit(ctx, record); err != nil {
+			return nil, fmt.Errorf("couldn't write the audit record of %s %s: %w", data.GetKind(), record.Resource.name(), err)
+		}
+		logger.Infof("For event ID %q recorded %s %s without creating it", eventID, data.GetKind(), record.Resource.name())
+		return data, nil
+	}
+
+	creator := a.Creator
+	if creator == nil {
+		creator = DefaultCreator
+	}
+	created, err := creator.Create(ctx, logger, rt, triggerName, eventID, elName, elNamespace, c, dc)
+	if err != nil {
+		return nil, err
+	}
+	record.Time = metav1.Now()
+	record.Resource = auditedResource(created)
+	if err := a.Writer.WriteAudit(ctx, record); err != nil {
+		err = fmt.Errorf("couldn't write the audit record of %s %s: %w", created.GetKind(), created.GetName(), err)
+		if a.BestEffort {
+			logger.Error(err)
+			return created, nil
+		}
+		if !MayPatch(rt) && createStrategy(ctx, created.GroupVersionKind()) == triggersv1.CreateStrategyCreate {
+			if derr := Delete(ctx, created, c, dc); derr != nil {
+				logger.Errorf("problem deleting %s %s without an audit record: %v", created.GetKind(), created.GetName(), derr)
+			}
+		}
+		return nil, err
+	}
+	return created, nil
+}
+
+func auditedResource(obj *unstructured.Unstructured) AuditedResource {
+	return AuditedResource{
+		APIVersion:   obj.GetAPIVersion(),
+		Kind:         obj.GetKind(),
+		Namespace:    obj.GetNamespace(),
+		Name:         obj.GetName(),
+		GenerateName: obj.GetGenerateName(),
+		UID:          obj.GetUID(),
+	}
+}
+
+// name returns the name of the resource, or its generateName if it wasn't created.
+func (r AuditedResource) name() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.GenerateName
+}
+
+// auditKey returns the path of the record relative to the root of the audit sink, e.g.
+// default/listener/<event ID>/<trigger>/20220415T052000.000000000Z-pipelinerun-build-xk2lp.json,
+// so that the records of an event are next to each other.
+func auditKey(record *AuditRecord) string {
+	file := fmt.Sprintf("%s-%s-%s.json", record.Time.UTC().Format("20060102T150405.000000000Z"),
+		strings.ToLower(record.Resource.Kind), record.Resource.name())
+	return path.Join(keySegment(record.EventListenerNamespace), keySegment(record.EventListener),
+		keySegment(record.EventID), keySegment(record.Trigger), keySegment(file))
+}
+
+// keySegment replaces the characters of s other than letters, digits, dots, dashes and underscores with
+// underscores, so that values such as event IDs taken from requests can't escape their directory.
+func keySegment(s string) string {
+	segment := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, s)
+	if strings.Trim(segment, ".") == "" {
+		return "_"
+	}
+	return segment
+}
+
+// DirAuditWriter writes audit records as JSON files under Dir, e.g. the mount path of a PersistentVolumeClaim.
+// Existing files are never overwritten.
+type DirAuditWriter struct {
+	Dir string
+}
+
+// WriteAudit writes the record to its file.
+func (w *DirAuditWriter) WriteAudit(_ context.Context, record *AuditRecord) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	p := filepath.Join(w.Dir, filepath.FromSlash(auditKey(record)))
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640) // #nosec G304 -- the path is sanitized by auditKey
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
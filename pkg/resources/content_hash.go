@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// ContentHashAnnotation can be set to "true" on a resource template to annotate the resource with the
+	// ContentHash of its rendered content, so that a controller can detect whether the resource still matches
+	// what the Trigger would create now. Create replaces the value of the annotation with the hash.
+	ContentHashAnnotation = triggers.GroupName + "/content-hash"
+
+	// contentHashPrefix is the prefix of the values of the ContentHashAnnotation, naming the hash function.
+	contentHashPrefix = "sha256:"
+)
+
+// ContentHash returns a hash of the content of the resource us, e.g. "sha256:1f2e...", which only changes if
+// the content does. Only the apiVersion, kind, labels, annotations and the fields outside of metadata and
+// status, such as spec, are hashed, in a canonical form with sorted keys. The labels and annotations with the
+// triggers.tekton.dev/ prefix are left out, since the provenance labels differ for each event, along with the
+// ContentHashAnnotation itself, so that the hash of a created resource is the value of its annotation.
+func ContentHash(us *unstructured.Unstructured) (string, error) {
+	content := make(map[string]interface{}, len(us.Object))
+	for k, v := range us.Object {
+		if k == "metadata" || k == "status" {
+			continue
+		}
+		content[k] = v
+	}
+	metadata := map[string]interface{}{}
+	if labels := stableMetadata(us.GetLabels()); len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	if annotations := stableMetadata(us.GetAnnotations()); len(annotations) > 0 {
+		metadata["annotations"] = annotations
+	}
+	if len(metadata) > 0 {
+		content["metadata"] = metadata
+	}
+	// Maps are marshalled with their keys sorted.
+	b, err := json.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("couldn't marshal the content of resource %s: %w", us.GetName(), err)
+	}
+	sum := sha256.Sum256(b)
+	return contentHashPrefix + hex.EncodeToString(sum[:]), nil
+}
+
+// stableMetadata returns the labels or annotations without the ones set by Triggers.
+func stableMetadata(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		if !strings.HasPrefix(k, triggers.GroupName+"/") {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// applyContentHash replaces the ContentHashAnnotation of the resource with its ContentHash if it is set to
+// true, and removes it if it is set to false.
+func applyContentHash(us *unstructured.Unstructured) error {
+	value, ok := us.GetAnnotations()[ContentHashAnnotation]
+	if !ok {
+		return nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s annotation %q: %v", ContentHashAnnotation, value, err)
+	}
+	if !enabled {
+		popAnnotation(us, ContentHashAnnotation)
+		return nil
+	}
+	hash, err := ContentHash(us)
+	if err != nil {
+		return err
+	}
+	annotations := us.GetAnnotations()
+	annotations[ContentHashAnnotation] = hash
+	us.SetAnnotations(annotations)
+	return nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func mustUnstructured(t *testing.T, s string) *unstructured.Unstructured {
+	t.Helper()
+	us := &unstructured.Unstructured{}
+	if err := us.UnmarshalJSON([]byte(s)); err != nil {
+		t.Fatalf("UnmarshalJSON() returned error: %v", err)
+	}
+	return us
+}
+
+func TestContentHash(t *testing.T) {
+	base := `{"apiVersion":"tekton.dev/v1beta1","kind":"PipelineRun","metadata":{"name":"run-abcde","labels":{"app":"api","triggers.tekton.dev/triggers-eventid":"1"}},"spec":{"params":[{"name":"revision","value":"main"}],"serviceAccountName":"builder"}}`
+	want, err := ContentHash(mustUnstructured(t, base))
+	if err != nil {
+		t.Fatalf("ContentHash() returned error: %v", err)
+	}
+	if !strings.HasPrefix(want, "sha256:") {
+		t.Errorf("ContentHash() = %q, want a sha256: prefix", want)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		resource string
+		same     bool
+	}{{
+		name:     "keys in another order",
+		resource: `{"spec":{"serviceAccountName":"builder","params":[{"value":"main","name":"revision"}]},"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"labels":{"triggers.tekton.dev/triggers-eventid":"1","app":"api"},"name":"run-abcde"}}`,
+		same:     true,
+	}, {
+		name:     "volatile fields",
+		resource: `{"apiVersion":"tekton.dev/v1beta1","kind":"PipelineRun","metadata":{"name":"run-fghij","uid":"1234","resourceVersion":"42","labels":{"app":"api","triggers.tekton.dev/triggers-eventid":"2","triggers.tekton.dev/trigger":"build"},"annotations":{"triggers.tekton.dev/content-hash":"sha256:old"}},"spec":{"params":[{"name":"revision","value":"main"}],"serviceAccountName":"builder"},"status":{"conditions":[]}}`,
+		same:     true,
+	}, {
+		name:     "changed spec",
+		resource: `{"apiVersion":"tekton.dev/v1beta1","kind":"PipelineRun","metadata":{"name":"run-abcde","labels":{"app":"api"}},"spec":{"params":[{"name":"revision","value":"release"}],"serviceAccountName":"builder"}}`,
+	}, {
+		name:     "changed label",
+		resource: `{"apiVersion":"tekton.dev/v1beta1","kind":"PipelineRun","metadata":{"name":"run-abcde","labels":{"app":"web"}},"spec":{"params":[{"name":"revision","value":"main"}],"serviceAccountName":"builder"}}`,
+	}, {
+		name:     "changed kind",
+		resource: `{"apiVersion":"tekton.dev/v1beta1","kind":"TaskRun","metadata":{"name":"run-abcde","labels":{"app":"api"}},"spec":{"params":[{"name":"revision","value":"main"}],"serviceAccountName":"builder"}}`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ContentHash(mustUnstructured(t, tc.resource))
+			if err != nil {
+				t.Fatalf("ContentHash() returned error: %v", err)
+			}
+			if (got == want) != tc.same {
+				t.Errorf("ContentHash() = %q, want same as %q: %t", got, want, tc.same)
+			}
+		})
+	}
+}
+
+func TestFakeCreator_ContentHash(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		value    string
+		wantHash bool
+		wantErr  bool
+	}{{
+		name:     "enabled",
+		value:    "true",
+		wantHash: true,
+	}, {
+		name:  "disabled",
+		value: "false",
+	}, {
+		name:    "invalid",
+		value:   "sha256",
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := json.RawMessage(`{"apiVersion":"tekton.dev/v1beta1","kind":"PipelineRun","metadata":{"generateName":"run-","annotations":{"triggers.tekton.dev/content-hash":"` + tc.value + `"}},"spec":{"serviceAccountName":"builder"}}`)
+			creator := &FakeCreator{}
+			created, err := creator.Create(context.Background(), nil, rt, triggerName, eventID, "foo-el", "bar", nil, nil)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Create() returned error %v, want error %t", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			got, ok := created.GetAnnotations()[ContentHashAnnotation]
+			if !tc.wantHash {
+				if ok {
+					t.Errorf("Create() kept the %s annotation %q", ContentHashAnnotation, got)
+				}
+				return
+			}
+			want, err := ContentHash(created)
+			if err != nil {
+				t.Fatalf("ContentHash() returned error: %v", err)
+			}
+			if got != want {
+				t.Errorf("Create() set the %s annotation to %q, want the ContentHash %q of the created resource", ContentHashAnnotation, got, want)
+			}
+		})
+	}
+}
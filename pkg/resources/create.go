@@ -19,10 +19,14 @@ package resources
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 
 	"k8s.io/client-go/dynamic"
@@ -32,14 +36,139 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	discoveryclient "k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/retry"
 )
 
-// findAPIResource returns the APIResource definition using the discovery client c.
-func findAPIResource(apiVersion, kind string, c discoveryclient.ServerResourcesInterface) (*metav1.APIResource, error) {
+const (
+	// PatchStrategyAnnotation can be set on a resource template to update the resource in place if it
+	// already exists, instead of failing to create it. The value is the patch strategy to use.
+	PatchStrategyAnnotation = triggers.GroupName + "/patch-strategy"
+
+	// MergePatchStrategy patches existing resources using a JSON merge patch (RFC 7386).
+	MergePatchStrategy = "merge"
+	// StrategicMergePatchStrategy patches existing resources using a strategic merge patch, which
+	// merges lists with merge keys (e.g. containers) instead of replacing them. It falls back to a
+	// JSON merge patch for types without a strategic merge schema, such as custom resources.
+	StrategicMergePatchStrategy = "strategic-merge"
+
+	// GenerateNameFromTriggerAnnotation can be set to "true" on a resource template to prefix the
+	// resource's generateName with the (sanitized) name of the Trigger that created it.
+	GenerateNameFromTriggerAnnotation = triggers.GroupName + "/generate-name-from-trigger"
+
+	// ExpectedResourceVersionAnnotation can be set with a PatchStrategyAnnotation to only patch the existing
+	// resource if its resourceVersion still matches the value, i.e. if it hasn't changed since it was read.
+	ExpectedResourceVersionAnnotation = triggers.GroupName + "/expected-resource-version"
+
+	// CreateNamespaceAnnotation can be set to "true" on a resource template to create the namespace of the
+	// resource, e.g. pr-$(tt.params.pr-number), if it doesn't exist yet. The namespace gets the provenance
+	// labels of the resource and the finalizer set by WithFinalizer, and is left as it is if it already exists.
+	CreateNamespaceAnnotation = triggers.GroupName + "/create-namespace"
+
+	// CreateLeaseAnnotation can be set on a resource template with a name to create the resource while holding a
+	// Lease keyed by its kind, namespace and name, so that only one replica of the EventListener creates it and the
+	// others return the existing resource. The value is the duration of the Lease, e.g. 30s, after which a Lease
+	// that wasn't released, e.g. because its replica stopped, can be taken over.
+	CreateLeaseAnnotation = triggers.GroupName + "/create-lease"
+
+	// CreateTimeoutAnnotation can be set on a resource template to the time, as a duration e.g. 10s, after which
+	// the creation of its resource is abandoned, instead of the create timeout of the EventListener, so that a
+	// slow resource doesn't hold up the others of its Trigger. See TemplateCreateTimeout.
+	CreateTimeoutAnnotation = triggers.GroupName + "/create-timeout"
+
+	// ApplyFieldManager is the field manager of the server-side applies of the resources whose create strategy
+	// is triggersv1.CreateStrategyApply.
+	ApplyFieldManager = "tekton-triggers-eventlistener"
+
+	// maxGenerateNameLength is the longest generateName prefix the API server keeps before appending
+	// its random suffix; longer prefixes are truncated, so we truncate first to keep the suffix intact.
+	maxGenerateNameLength = 63 - 5
+)
+
+// ConflictError is returned when an existing resource is not patched because its resourceVersion does not
+// match the ExpectedResourceVersionAnnotation of the resource template, or because it no longer exists.
+type ConflictError struct {
+	// Name is the name of the resource.
+	Name string
+	// ExpectedResourceVersion is the resourceVersion the resource template expected.
+	ExpectedResourceVersion string
+	// Err is the error returned by the API server.
+	Err error
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("resource %s changed since resourceVersion %s: %v", e.Name, e.ExpectedResourceVersion, e.Err)
+}
+
+// Unwrap returns the error returned by the API server.
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// directives are the Triggers annotations of a resource template that control how the resource is created.
+type directives struct {
+	// patchStrategy is the value of the PatchStrategyAnnotation.
+	patchStrategy string
+	// expectedResourceVersion is the value of the ExpectedResourceVersionAnnotation.
+	expectedResourceVersion string
+	// onlyAddedFinalizer is whether the finalizers of the resource are only the one set by WithFinalizer.
+	onlyAddedFinalizer bool
+	// createNamespace is the value of the CreateNamespaceAnnotation.
+	createNamespace bool
+	// leaseDuration is the value of the CreateLeaseAnnotation.
+	leaseDuration time.Duration
+	// idempotent is whether the context selected an idempotency key with WithIdempotencyKey.
+	idempotent bool
+}
+
+var (
+	// ErrDiscoveryNotReady is wrapped by the errors of FindAPIResource when the resources of the API server
+	// couldn't be listed, e.g. while it starts or is unreachable. Finding the resource can be retried.
+	ErrDiscoveryNotReady = errors.New("discovery not ready")
+	// ErrAPIResourceNotFound is wrapped by the errors of FindAPIResource when the API server doesn't serve
+	// the apiVersion or the kind. Retrying won't find the resource until its CRD is installed.
+	ErrAPIResourceNotFound = errors.New("API resource not found")
+)
+
+// KindNotAllowedError is returned by Create when the resource kind policy selected by WithKindPolicy
+// doesn't allow the kind of the resource, or of the namespace it would create for it.
+type KindNotAllowedError struct {
+	// GroupVersionKind is the kind that isn't allowed.
+	GroupVersionKind schema.GroupVersionKind
+	// Name is the name, or else the generateName, of the resource.
+	Name string
+}
+
+func (e *KindNotAllowedError) Error() string {
+	gvk := e.GroupVersionKind
+	return fmt.Sprintf("%s %s of apiVersion %s is not allowed by the resourceKinds of the EventListener", gvk.Kind, e.Name, gvk.GroupVersion())
+}
+
+// apiResourceError is an error of FindAPIResource, which wraps ErrDiscoveryNotReady or ErrAPIResourceNotFound
+// without changing its message.
+type apiResourceError struct {
+	reason error
+	msg    string
+}
+
+func (e *apiResourceError) Error() string { return e.msg }
+
+func (e *apiResourceError) Unwrap() error { return e.reason }
+
+// FindAPIResource returns the APIResource definition using the discovery client c. Its errors wrap
+// ErrAPIResourceNotFound if the API server doesn't serve the resource, and ErrDiscoveryNotReady if the
+// resources of the API server couldn't be listed.
+func FindAPIResource(apiVersion, kind string, c discoveryclient.ServerResourcesInterface) (*metav1.APIResource, error) {
 	resourceList, err := c.ServerResourcesForGroupVersion(apiVersion)
 	if err != nil {
-		return nil, fmt.Errorf("error getting kubernetes server resources for apiVersion %s: %s", apiVersion, err)
+		reason := ErrDiscoveryNotReady
+		if kerrors.IsNotFound(err) {
+			reason = ErrAPIResourceNotFound
+		}
+		return nil, &apiResourceError{reason: reason, msg: fmt.Sprintf("error getting kubernetes server resources for apiVersion %s: %s", apiVersion, err)}
 	}
 	for i := range resourceList.APIResources {
 		r := &resourceList.APIResources[i]
@@ -58,25 +187,33 @@ func findAPIResource(apiVersion, kind string, c discoveryclient.ServerResourcesI
 		}
 		return r, nil
 	}
-	return nil, fmt.Errorf("error could not find resource with apiVersion %s and kind %s", apiVersion, kind)
+	return nil, &apiResourceError{reason: ErrAPIResourceNotFound, msg: fmt.Sprintf("error could not find resource with apiVersion %s and kind %s", apiVersion, kind)}
 }
 
-// Create uses the kubeClient to create the resource defined in the
-// TriggerResourceTemplate and returns any errors with this process
-func Create(logger *zap.SugaredLogger, rt json.RawMessage, triggerName, eventID, elName, elNamespace string, c discoveryclient.ServerResourcesInterface, dc dynamic.Interface) error {
-	// Assume the TriggerResourceTemplate is valid (it has an apiVersion and Kind)
-	data := new(unstructured.Unstructured)
-	if err := data.UnmarshalJSON(rt); err != nil {
-		return fmt.Errorf("couldn't unmarshal json from the TriggerTemplate: %v", err)
+// APIPath returns the path of obj on the API server, e.g.
+// /apis/tekton.dev/v1beta1/namespaces/default/pipelineruns/run-abcde, using the discovery client c.
+func APIPath(obj *unstructured.Unstructured, c discoveryclient.ServerResourcesInterface) (string, error) {
+	r, err := FindAPIResource(obj.GetAPIVersion(), obj.GetKind(), c)
+	if err != nil {
+		return "", err
+	}
+	p := "/apis/" + r.Group + "/" + r.Version
+	if r.Group == "" {
+		p = "/api/" + r.Version
 	}
+	if r.Namespaced && obj.GetNamespace() != "" {
+		p += "/namespaces/" + obj.GetNamespace()
+	}
+	return p + "/" + r.Name + "/" + obj.GetName(), nil
+}
 
-	data, err := addLabels(data, map[string]string{
-		triggers.EventListenerLabelKey: elName,
-		triggers.EventIDLabelKey:       eventID,
-		triggers.TriggerLabelKey:       triggerName,
-	})
+// Create uses the kubeClient to create the resource defined in the
+// TriggerResourceTemplate and returns the created resource, or any errors with
+// this process. The calls to the API server are abandoned when ctx is done.
+func Create(ctx context.Context, logger *zap.SugaredLogger, rt json.RawMessage, triggerName, eventID, elName, elNamespace string, c discoveryclient.ServerResourcesInterface, dc dynamic.Interface) (*unstructured.Unstructured, error) {
+	data, d, err := prepare(ctx, rt, triggerName, eventID, elName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	namespace := data.GetNamespace()
@@ -86,15 +223,27 @@ func Create(logger *zap.SugaredLogger, rt json.RawMessage, triggerName, eventID,
 	}
 
 	// Resolve resource kind to the underlying API Resource type.
-	apiResource, err := findAPIResource(data.GetAPIVersion(), data.GetKind(), c)
+	apiResource, err := FindAPIResource(data.GetAPIVersion(), data.GetKind(), c)
 	if err != nil {
-		return fmt.Errorf("couldn't find API resource for json: %v", err)
+		return nil, fmt.Errorf("couldn't find API resource for json: %w", err)
 	}
 
 	name := data.GetName()
 	if name == "" {
 		name = data.GetGenerateName()
 	}
+	gvk := schema.GroupVersionKind{Group: apiResource.Group, Version: apiResource.Version, Kind: apiResource.Kind}
+	if err := checkKind(ctx, gvk, name); err != nil {
+		return nil, err
+	}
+	// The PatchStrategyAnnotation of the template takes precedence over the create strategy of its kind.
+	strategy := triggersv1.CreateStrategyCreate
+	if d.patchStrategy == "" {
+		strategy = createStrategy(ctx, gvk)
+	}
+	if strategy == triggersv1.CreateStrategyPatch {
+		d.patchStrategy = StrategicMergePatchStrategy
+	}
 	logger.Infof("Generating resource: kind: %s, name: %s", apiResource, name)
 
 	gvr := schema.GroupVersionResource{
@@ -105,15 +254,623 @@ func Create(logger *zap.SugaredLogger, rt json.RawMessage, triggerName, eventID,
 
 	logger.Infof("For event ID %q creating resource %v", eventID, gvr)
 
-	if _, err := dc.Resource(gvr).Namespace(namespace).Create(context.Background(), data, metav1.CreateOptions{}); err != nil {
+	if d.createNamespace && apiResource.Namespaced {
+		if err := checkKind(ctx, schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, namespace); err != nil {
+			return nil, err
+		}
+		if err := ensureNamespace(ctx, logger, namespace, data, dc); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.leaseDuration > 0 {
+		// The Leases of cluster-scoped resources are in the namespace of the EventListener.
+		keyNamespace, leaseNamespace := namespace, namespace
+		if !apiResource.Namespaced {
+			keyNamespace, leaseNamespace = "", elNamespace
+		}
+		lease, err := acquireCreateLease(ctx, logger, createLeaseName(gvr, keyNamespace, data.GetName()), leaseNamespace, leaseHolder(eventID), d.leaseDuration, dc)
+		if err != nil {
+			return nil, err
+		}
+		defer lease.release(ctx, logger, dc)
+	}
+
+	if d.expectedResourceVersion != "" {
+		// The resource is expected to exist, so creating it would defeat the precondition.
+		data.SetResourceVersion(d.expectedResourceVersion)
+		patched, err := patch(ctx, logger, patchData(data, d), d.patchStrategy, gvr, namespace, dc)
+		if kerrors.IsConflict(err) || kerrors.IsNotFound(err) {
+			return nil, &ConflictError{Name: data.GetName(), ExpectedResourceVersion: d.expectedResourceVersion, Err: err}
+		}
+		return patched, err
+	}
+
+	// Server-side applies need a name, so the resources with a generateName are created.
+	if strategy == triggersv1.CreateStrategyApply && data.GetName() != "" {
+		return apply(ctx, logger, data, gvr, namespace, dc)
+	}
+
+	created, err := dc.Resource(gvr).Namespace(namespace).Create(ctx, data, metav1.CreateOptions{FieldValidation: fieldValidation(ctx)})
+	if kerrors.IsAlreadyExists(err) && d.patchStrategy != "" && data.GetName() != "" {
+		return patch(ctx, logger, patchData(data, d), d.patchStrategy, gvr, namespace, dc)
+	}
+	if kerrors.IsAlreadyExists(err) && strategy == triggersv1.CreateStrategyCreateOrUpdate && data.GetName() != "" {
+		return update(ctx, logger, data, d, gvr, namespace, dc)
+	}
+	if kerrors.IsAlreadyExists(err) && d.leaseDuration > 0 {
+		// Another replica, or an earlier event with the same key, created it.
+		logger.Infof("Resource %s already exists, returning it", data.GetName())
+		existing, err := dc.Resource(gvr).Namespace(namespace).Get(ctx, data.GetName(), metav1.GetOptions{})
+		if err != nil {
+			if kerrors.IsUnauthorized(err) || kerrors.IsForbidden(err) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("couldn't get existing resource with group version kind %q: %w", gvr, err)
+		}
+		return existing, nil
+	}
+	if kerrors.IsAlreadyExists(err) && d.idempotent {
+		// An earlier attempt with the same idempotency key may have created it.
+		existing, getErr := dc.Resource(gvr).Namespace(namespace).Get(ctx, data.GetName(), metav1.GetOptions{})
+		if getErr == nil && createdWithSameKey(existing, data) {
+			logger.Infof("Resource %s was already created for event ID %q, returning it", data.GetName(), eventID)
+			return existing, nil
+		}
+	}
+	if err != nil {
+		if kerrors.IsUnauthorized(err) || kerrors.IsForbidden(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("couldn't create resource with group version kind %q: %w", gvr, err)
+	}
+	if observe, ok := ctx.Value(createdObserverKey{}).(func(*unstructured.Unstructured)); ok {
+		observe(created)
+	}
+	return created, nil
+}
+
+// namespacesGVR is the resource of namespaces.
+var namespacesGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// ensureNamespace creates the namespace of the resource data with its provenance labels and the finalizer
+// selected by ctx if it doesn't exist. Existing namespaces are left as they are, so that the events sharing a
+// namespace, e.g. the pushes to a pull request, don't need the permission to create namespaces once it exists.
+func ensureNamespace(ctx context.Context, logger *zap.SugaredLogger, namespace string, data *unstructured.Unstructured, dc dynamic.Interface) error {
+	if msgs := validation.IsDNS1123Label(namespace); len(msgs) > 0 {
+		return fmt.Errorf("invalid namespace %q: %s", namespace, strings.Join(msgs, ", "))
+	}
+	_, err := dc.Resource(namespacesGVR).Get(ctx, namespace, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !kerrors.IsNotFound(err) {
+		if kerrors.IsUnauthorized(err) || kerrors.IsForbidden(err) {
+			return err
+		}
+		return fmt.Errorf("couldn't get namespace %s: %w", namespace, err)
+	}
+
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(namespace)
+	labels := map[string]string{}
+	for k, v := range data.GetLabels() {
+		if strings.HasPrefix(k, triggers.GroupName+"/") {
+			labels[k] = v
+		}
+	}
+	if len(labels) > 0 {
+		ns.SetLabels(labels)
+	}
+	if finalizer, ok := ctx.Value(finalizerKey{}).(string); ok && finalizer != "" {
+		ns.SetFinalizers([]string{finalizer})
+	}
+	logger.Infof("Creating namespace %s", namespace)
+	_, err = dc.Resource(namespacesGVR).Create(ctx, ns, metav1.CreateOptions{})
+	if err == nil || kerrors.IsAlreadyExists(err) {
+		return nil
+	}
+	if kerrors.IsUnauthorized(err) || kerrors.IsForbidden(err) {
+		return err
+	}
+	return fmt.Errorf("couldn't create namespace %s: %w", namespace, err)
+}
+
+// MayPatch returns whether Create may update an existing resource with the resource template rt rather than
+// create it, i.e. whether rt has a PatchStrategyAnnotation. The resources of the kinds whose create strategy
+// isn't triggersv1.CreateStrategyCreate may also be updated, see WithCreateStrategies.
+func MayPatch(rt json.RawMessage) bool {
+	_, ok := templateAnnotations(rt)[PatchStrategyAnnotation]
+	return ok
+}
+
+// TemplateCreateTimeout returns the value of the CreateTimeoutAnnotation of the resource template rt, or 0 if it
+// has none. An invalid value also returns 0, and fails the creation of the resource.
+func TemplateCreateTimeout(rt json.RawMessage) time.Duration {
+	timeout, err := time.ParseDuration(templateAnnotations(rt)[CreateTimeoutAnnotation])
+	if err != nil || timeout <= 0 {
+		return 0
+	}
+	return timeout
+}
+
+// templateAnnotations returns the annotations of the resource template rt, which may lack the apiVersion and
+// kind given by WithDefaultType.
+func templateAnnotations(rt json.RawMessage) map[string]string {
+	var obj metav1.PartialObjectMetadata
+	if err := json.Unmarshal(rt, &obj); err != nil {
+		return nil
+	}
+	return obj.Annotations
+}
+
+// Delete deletes the resource obj returned by Create, using the discovery client c to find its API resource.
+// The deletion is conditioned on the UID of obj, so that a resource since recreated with the same name is left
+// alone, and the dependents of obj are deleted in the background.
+func Delete(ctx context.Context, obj *unstructured.Unstructured, c discoveryclient.ServerResourcesInterface, dc dynamic.Interface) error {
+	apiResource, err := FindAPIResource(obj.GetAPIVersion(), obj.GetKind(), c)
+	if err != nil {
+		return fmt.Errorf("couldn't find API resource for %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	gvr := schema.GroupVersionResource{
+		Group:    apiResource.Group,
+		Version:  apiResource.Version,
+		Resource: apiResource.Name,
+	}
+	propagation := metav1.DeletePropagationBackground
+	opts := metav1.DeleteOptions{PropagationPolicy: &propagation}
+	if uid := obj.GetUID(); uid != "" {
+		opts.Preconditions = metav1.NewUIDPreconditions(string(uid))
+	}
+	if err := dc.Resource(gvr).Namespace(obj.GetNamespace()).Delete(ctx, obj.GetName(), opts); err != nil {
 		if kerrors.IsUnauthorized(err) || kerrors.IsForbidden(err) {
 			return err
 		}
-		return fmt.Errorf("couldn't create resource with group version kind %q: %v", gvr, err)
+		return fmt.Errorf("couldn't delete resource with group version kind %q: %w", gvr, err)
 	}
 	return nil
 }
 
+// prepare unmarshals the resource template, applies the Triggers annotation directives, adds the labels and
+// annotations of the trigger, the TTL annotation and the autogenerated labels, calls the mutation hook selected by ctx, sanitizes
+// the label values, computes the content hash and adds the finalizer selected by ctx. It returns the resource
+// to create and the directives for creating it.
+func prepare(ctx context.Context, rt json.RawMessage, triggerName, eventID, elName string) (*unstructured.Unstructured, directives, error) {
+	var d directives
+	// Assume the TriggerResourceTemplate is valid (it has an apiVersion and Kind, or the default ones
+	// selected by ctx apply)
+	if t, ok := ctx.Value(defaultTypeKey{}).(metav1.TypeMeta); ok && t.Kind != "" {
+		var err error
+		if rt, err = applyDefaultType(rt, t); err != nil {
+			return nil, d, fmt.Errorf("couldn't unmarshal json from the TriggerTemplate: %v", err)
+		}
+	}
+	data := new(unstructured.Unstructured)
+	if err := data.UnmarshalJSON(rt); err != nil {
+		return nil, d, fmt.Errorf("couldn't unmarshal json from the TriggerTemplate: %v", err)
+	}
+
+	var err error
+	if d.patchStrategy, err = popPatchStrategy(data); err != nil {
+		return nil, d, err
+	}
+	if rv, ok := popAnnotation(data, ExpectedResourceVersionAnnotation); ok {
+		if d.patchStrategy == "" || data.GetName() == "" {
+			return nil, d, fmt.Errorf("%s annotation requires a %s annotation and a name", ExpectedResourceVersionAnnotation, PatchStrategyAnnotation)
+		}
+		d.expectedResourceVersion = rv
+	}
+	if err := applyGenerateNameFromTrigger(data, triggerName); err != nil {
+		return nil, d, err
+	}
+	d.idempotent = applyIdempotencyKey(ctx, data)
+	if value, ok := popAnnotation(data, CreateNamespaceAnnotation); ok {
+		if d.createNamespace, err = strconv.ParseBool(value); err != nil {
+			return nil, d, fmt.Errorf("invalid %s annotation %q: %v", CreateNamespaceAnnotation, value, err)
+		}
+	}
+	if value, ok := popAnnotation(data, CreateLeaseAnnotation); ok {
+		if d.leaseDuration, err = time.ParseDuration(value); err != nil || d.leaseDuration <= 0 {
+			return nil, d, fmt.Errorf("invalid %s annotation %q: must be a positive duration", CreateLeaseAnnotation, value)
+		}
+		if data.GetName() == "" {
+			return nil, d, fmt.Errorf("%s annotation requires a name", CreateLeaseAnnotation)
+		}
+	}
+	if value, ok := popAnnotation(data, CreateTimeoutAnnotation); ok {
+		if timeout, err := time.ParseDuration(value); err != nil || timeout <= 0 {
+			return nil, d, fmt.Errorf("invalid %s annotation %q: must be a positive duration", CreateTimeoutAnnotation, value)
+		}
+	}
+	if m, ok := ctx.Value(triggerMetadataKey{}).(triggerMetadata); ok {
+		data.SetLabels(mergeMetadata(data.GetLabels(), m.labels))
+		data.SetAnnotations(mergeMetadata(data.GetAnnotations(), m.annotations))
+	}
+	applyTTL(ctx, data)
+
+	labels := map[string]string{
+		triggers.EventListenerLabelKey: elName,
+		triggers.EventIDLabelKey:       eventID,
+		triggers.TriggerLabelKey:       triggerName,
+	}
+	if keys, ok := ctx.Value(provenanceLabelsKey{}).([]string); ok {
+		selected := make(map[string]string, len(keys))
+		for _, k := range keys {
+			selected[k] = labels[k]
+		}
+		labels = selected
+	}
+	data, err = addLabels(data, labels)
+	if err != nil {
+		return nil, d, err
+	}
+	if data, err = applyMutation(ctx, data); err != nil {
+		return nil, d, err
+	}
+	if err := sanitizeLabels(ctx, data); err != nil {
+		return nil, d, err
+	}
+	if err := applyContentHash(data); err != nil {
+		return nil, d, err
+	}
+	if finalizer, ok := ctx.Value(finalizerKey{}).(string); ok && finalizer != "" {
+		d.onlyAddedFinalizer = addFinalizer(data, finalizer)
+	}
+	return data, d, nil
+}
+
+// applyDefaultType returns the resource template with the default apiVersion and kind if it specifies
+// neither.
+func applyDefaultType(rt json.RawMessage, t metav1.TypeMeta) (json.RawMessage, error) {
+	var obj map[string]interface{}
+	if err := json.Unmarshal(rt, &obj); err != nil {
+		return nil, err
+	}
+	if obj["apiVersion"] != nil || obj["kind"] != nil {
+		return rt, nil
+	}
+	obj["apiVersion"] = t.APIVersion
+	obj["kind"] = t.Kind
+	return json.Marshal(obj)
+}
+
+// patchData returns the resource to patch an existing resource with. A JSON merge patch replaces the
+// whole list of finalizers, so the finalizer added by WithFinalizer is left out unless the template lists
+// finalizers itself, rather than removing the finalizers added to the existing resource by controllers.
+func patchData(data *unstructured.Unstructured, d directives) *unstructured.Unstructured {
+	if !d.onlyAddedFinalizer {
+		return data
+	}
+	data = data.DeepCopy()
+	data.SetFinalizers(nil)
+	return data
+}
+
+// finalizerKey is the context key for the finalizer set by WithFinalizer.
+type finalizerKey struct{}
+
+// WithFinalizer returns a context in which Create adds the finalizer to the resources it creates, in
+// addition to the finalizers listed in their templates.
+func WithFinalizer(ctx context.Context, finalizer string) context.Context {
+	return context.WithValue(ctx, finalizerKey{}, finalizer)
+}
+
+// addFinalizer adds the finalizer to the resource unless it is already listed, and returns whether it is
+// now the only finalizer of the resource.
+func addFinalizer(us *unstructured.Unstructured, finalizer string) bool {
+	finalizers := us.GetFinalizers()
+	for _, f := range finalizers {
+		if f == finalizer {
+			return false
+		}
+	}
+	us.SetFinalizers(append(finalizers, finalizer))
+	return len(finalizers) == 0
+}
+
+// ttlKey is the context key for the time to live set by WithTTL.
+type ttlKey struct{}
+
+type resourceTTL struct {
+	annotation string
+	ttl        time.Duration
+}
+
+// WithTTL returns a context in which Create annotates the resources it creates with their time to live after
+// completion, e.g. 24h0m0s, for a cleanup controller to delete them once they have completed for longer. The
+// annotation is triggers.tekton.dev/ttl-after-completion if empty. The templates that set the annotation keep
+// their value, and the resources whose templates set ownerReferences aren't annotated, since they are deleted
+// with their owners. The deletion still waits for the finalizer set by WithFinalizer, if any.
+func WithTTL(ctx context.Context, annotation string, ttl time.Duration) context.Context {
+	if annotation == "" {
+		annotation = triggers.GroupName + triggers.TTLAfterCompletionAnnotationKey
+	}
+	return context.WithValue(ctx, ttlKey{}, resourceTTL{annotation: annotation, ttl: ttl})
+}
+
+// applyTTL adds the annotation with the time to live selected by ctx to the resource.
+func applyTTL(ctx context.Context, us *unstructured.Unstructured) {
+	t, ok := ctx.Value(ttlKey{}).(resourceTTL)
+	if !ok || t.ttl <= 0 || len(us.GetOwnerReferences()) > 0 {
+		return
+	}
+	us.SetAnnotations(mergeMetadata(us.GetAnnotations(), map[string]string{t.annotation: t.ttl.String()}))
+}
+
+// triggerMetadataKey is the context key for the labels and annotations set by WithTriggerMetadata.
+type triggerMetadataKey struct{}
+
+type triggerMetadata struct {
+	labels      map[string]string
+	annotations map[string]string
+}
+
+// WithTriggerMetadata returns a context in which Create adds the labels and annotations of a trigger to
+// the resources it creates. The labels and annotations of the templates take precedence over those of
+// the trigger with the same keys, and the provenance labels over both.
+func WithTriggerMetadata(ctx context.Context, labels, annotations map[string]string) context.Context {
+	return context.WithValue(ctx, triggerMetadataKey{}, triggerMetadata{labels: labels, annotations: annotations})
+}
+
+// mergeMetadata returns the labels or annotations of a resource template with the values of the trigger
+// added for the keys that the template doesn't specify.
+func mergeMetadata(template, trigger map[string]string) map[string]string {
+	if len(trigger) == 0 {
+		return template
+	}
+	merged := make(map[string]string, len(template)+len(trigger))
+	for k, v := range trigger {
+		merged[k] = v
+	}
+	for k, v := range template {
+		merged[k] = v
+	}
+	return merged
+}
+
+// provenanceLabelsKey is the context key for the provenance labels set by WithProvenanceLabels.
+type provenanceLabelsKey struct{}
+
+// WithProvenanceLabels returns a context in which Create only adds the provenance labels with the given
+// keys, e.g. triggers.TriggerLabelKey, to the resources it creates. By default, all of them are added.
+func WithProvenanceLabels(ctx context.Context, keys []string) context.Context {
+	return context.WithValue(ctx, provenanceLabelsKey{}, keys)
+}
+
+// defaultTypeKey is the context key for the default type set by WithDefaultType.
+type defaultTypeKey struct{}
+
+// WithDefaultType returns a context in which Create gives the resource templates that specify neither an
+// apiVersion nor a kind the given apiVersion and kind, so that minimal templates only have a spec. The
+// apiVersion and kind of the templates that specify them are left alone.
+func WithDefaultType(ctx context.Context, apiVersion, kind string) context.Context {
+	return context.WithValue(ctx, defaultTypeKey{}, metav1.TypeMeta{APIVersion: apiVersion, Kind: kind})
+}
+
+// createdObserverKey is the context key for the function set by WithCreatedObserver.
+type createdObserverKey struct{}
+
+// WithCreatedObserver returns a context in which Create calls observe with each resource it creates, e.g. to
+// count them. It isn't called for the resources that are patched or that already existed.
+func WithCreatedObserver(ctx context.Context, observe func(*unstructured.Unstructured)) context.Context {
+	return context.WithValue(ctx, createdObserverKey{}, observe)
+}
+
+// kindPolicyKey is the context key for the resource kind policy set by WithKindPolicy.
+type kindPolicyKey struct{}
+
+// WithKindPolicy returns a context in which Create refuses, with a *KindNotAllowedError, to create the
+// resources whose kind the policy doesn't allow, before making any request to create them. By default, all
+// the kinds the service account of the EventListener can create are allowed.
+func WithKindPolicy(ctx context.Context, policy *triggersv1.ResourceKindPolicy) context.Context {
+	return context.WithValue(ctx, kindPolicyKey{}, policy)
+}
+
+// checkKind returns a *KindNotAllowedError if the resource kind policy selected by ctx doesn't allow gvk.
+func checkKind(ctx context.Context, gvk schema.GroupVersionKind, name string) error {
+	if policy, _ := ctx.Value(kindPolicyKey{}).(*triggersv1.ResourceKindPolicy); !policy.Allows(gvk) {
+		return &KindNotAllowedError{GroupVersionKind: gvk, Name: name}
+	}
+	return nil
+}
+
+// createStrategiesKey is the context key for the create strategies set by WithCreateStrategies.
+type createStrategiesKey struct{}
+
+// WithCreateStrategies returns a context in which Create creates the resources of each kind with the first of
+// the strategies that matches it, see triggersv1.CreateStrategyFor, unless their template has a
+// PatchStrategyAnnotation. By default, the resources of all kinds are created with
+// triggersv1.CreateStrategyCreate.
+func WithCreateStrategies(ctx context.Context, strategies []triggersv1.ResourceCreateStrategy) context.Context {
+	return context.WithValue(ctx, createStrategiesKey{}, strategies)
+}
+
+// createStrategy returns the create strategy selected by ctx for the resources of the kind gvk.
+func createStrategy(ctx context.Context, gvk schema.GroupVersionKind) triggersv1.CreateStrategy {
+	strategies, _ := ctx.Value(createStrategiesKey{}).([]triggersv1.ResourceCreateStrategy)
+	return triggersv1.CreateStrategyFor(strategies, gvk)
+}
+
+// fieldValidationKey is the context key for the field validation set by WithFieldValidation.
+type fieldValidationKey struct{}
+
+// WithFieldValidation returns a context in which Create asks the API server to handle unknown and
+// duplicate fields in the resources it creates or patches with the given field validation directive:
+// metav1.FieldValidationIgnore, metav1.FieldValidationWarn or metav1.FieldValidationStrict. By default,
+// the API server returns warnings for them.
+func WithFieldValidation(ctx context.Context, fieldValidation string) context.Context {
+	return context.WithValue(ctx, fieldValidationKey{}, fieldValidation)
+}
+
+// fieldValidation returns the field validation directive selected by ctx.
+func fieldValidation(ctx context.Context) string {
+	if v, ok := ctx.Value(fieldValidationKey{}).(string); ok && v != "" {
+		return v
+	}
+	return metav1.FieldValidationWarn
+}
+
+// popAnnotation removes the annotation from the resource and returns its value.
+func popAnnotation(us *unstructured.Unstructured, key string) (string, bool) {
+	annotations := us.GetAnnotations()
+	value, ok := annotations[key]
+	if !ok {
+		return "", false
+	}
+	delete(annotations, key)
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	us.SetAnnotations(annotations)
+	return value, true
+}
+
+// popPatchStrategy removes the PatchStrategyAnnotation from the resource and returns its value.
+func popPatchStrategy(us *unstructured.Unstructured) (string, error) {
+	strategy, ok := popAnnotation(us, PatchStrategyAnnotation)
+	if !ok {
+		return "", nil
+	}
+	if strategy != MergePatchStrategy && strategy != StrategicMergePatchStrategy {
+		return "", fmt.Errorf("invalid %s annotation %q: must be %q or %q", PatchStrategyAnnotation, strategy, MergePatchStrategy, StrategicMergePatchStrategy)
+	}
+	return strategy, nil
+}
+
+// applyGenerateNameFromTrigger removes the GenerateNameFromTriggerAnnotation from the resource and, if
+// it is set to true, prefixes the resource's generateName with the sanitized trigger name. Resources
+// with an explicit name are left unchanged.
+func applyGenerateNameFromTrigger(us *unstructured.Unstructured, triggerName string) error {
+	value, ok := popAnnotation(us, GenerateNameFromTriggerAnnotation)
+	if !ok {
+		return nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s annotation %q: %v", GenerateNameFromTriggerAnnotation, value, err)
+	}
+	if !enabled || us.GetName() != "" {
+		return nil
+	}
+	prefix := sanitizeName(triggerName)
+	if prefix == "" {
+		return nil
+	}
+	us.SetGenerateName(generateNameWithPrefix(prefix, us.GetGenerateName()))
+	return nil
+}
+
+// generateNameWithPrefix joins prefix and generateName, truncating the prefix first and then
+// generateName so that the result fits within maxGenerateNameLength.
+func generateNameWithPrefix(prefix, generateName string) string {
+	generateName = strings.TrimPrefix(generateName, prefix+"-")
+	if len(generateName) > maxGenerateNameLength-2 {
+		// Keep at least one character of the prefix and its separator.
+		generateName = generateName[:maxGenerateNameLength-2]
+	}
+	if limit := maxGenerateNameLength - len(generateName) - 1; len(prefix) > limit {
+		prefix = strings.TrimRight(prefix[:limit], "-.")
+	}
+	return prefix + "-" + generateName
+}
+
+// sanitizeName converts s into a string usable as a Kubernetes resource name prefix.
+func sanitizeName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return '-'
+		}
+	}, s)
+	return strings.Trim(s, "-.")
+}
+
+// patch updates the existing resource in place with the resource template using the given strategy.
+func patch(ctx context.Context, logger *zap.SugaredLogger, data *unstructured.Unstructured, strategy string, gvr schema.GroupVersionResource, namespace string, dc dynamic.Interface) (*unstructured.Unstructured, error) {
+	pt := types.MergePatchType
+	if strategy == StrategicMergePatchStrategy {
+		// The API server can only apply strategic merge patches to types it has a Go schema for.
+		if scheme.Scheme.Recognizes(data.GroupVersionKind()) {
+			pt = types.StrategicMergePatchType
+		} else {
+			logger.Infof("No strategic merge schema for %s, falling back to JSON merge patch", data.GroupVersionKind())
+		}
+	}
+
+	b, err := data.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't marshal resource %s for patching: %v", data.GetName(), err)
+	}
+	logger.Infof("Resource %s already exists, patching it with %s", data.GetName(), pt)
+	patched, err := dc.Resource(gvr).Namespace(namespace).Patch(ctx, data.GetName(), pt, b, metav1.PatchOptions{FieldValidation: fieldValidation(ctx)})
+	if err != nil {
+		if kerrors.IsUnauthorized(err) || kerrors.IsForbidden(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("couldn't patch resource with group version kind %q: %w", gvr, err)
+	}
+	return patched, nil
+}
+
+// apply creates the resource, or updates the fields of the existing resource set by the EventListener, with a
+// server-side apply. The fields owned by other field managers are taken over, so that the resource converges
+// to its template.
+func apply(ctx context.Context, logger *zap.SugaredLogger, data *unstructured.Unstructured, gvr schema.GroupVersionResource, namespace string, dc dynamic.Interface) (*unstructured.Unstructured, error) {
+	b, err := data.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't marshal resource %s for applying: %v", data.GetName(), err)
+	}
+	logger.Infof("Applying resource %s", data.GetName())
+	force := true
+	applied, err := dc.Resource(gvr).Namespace(namespace).Patch(ctx, data.GetName(), types.ApplyPatchType, b, metav1.PatchOptions{
+		FieldManager:    ApplyFieldManager,
+		Force:           &force,
+		FieldValidation: fieldValidation(ctx),
+	})
+	if err != nil {
+		if kerrors.IsUnauthorized(err) || kerrors.IsForbidden(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("couldn't apply resource with group version kind %q: %w", gvr, err)
+	}
+	return applied, nil
+}
+
+// update replaces the existing resource with the resource. Like patchData, it keeps the finalizers of the
+// existing resource unless the template lists finalizers itself, and it retries if the resource changes
+// between reading and replacing it.
+func update(ctx context.Context, logger *zap.SugaredLogger, data *unstructured.Unstructured, d directives, gvr schema.GroupVersionResource, namespace string, dc dynamic.Interface) (*unstructured.Unstructured, error) {
+	logger.Infof("Resource %s already exists, replacing it", data.GetName())
+	var updated *unstructured.Unstructured
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := dc.Resource(gvr).Namespace(namespace).Get(ctx, data.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		obj := data.DeepCopy()
+		obj.SetResourceVersion(existing.GetResourceVersion())
+		if d.onlyAddedFinalizer {
+			obj.SetFinalizers(existing.GetFinalizers())
+			addFinalizer(obj, data.GetFinalizers()[0])
+		}
+		updated, err = dc.Resource(gvr).Namespace(namespace).Update(ctx, obj, metav1.UpdateOptions{FieldValidation: fieldValidation(ctx)})
+		return err
+	})
+	if err != nil {
+		if kerrors.IsUnauthorized(err) || kerrors.IsForbidden(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("couldn't update resource with group version kind %q: %w", gvr, err)
+	}
+	return updated, nil
+}
+
 // addLabels adds autogenerated Tekton labels to created resources.
 func addLabels(us *unstructured.Unstructured, labelsToAdd map[string]string) (*unstructured.Unstructured, error) {
 	labels, _, err := unstructured.NestedStringMap(us.Object, "metadata", "labels")
@@ -17,11 +17,16 @@ limitations under the License.
 package resources
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
 	"go.uber.org/zap/zaptest"
 
 	"github.com/google/go-cmp/cmp"
@@ -29,10 +34,14 @@ import (
 	dynamicclientset "github.com/tektoncd/triggers/pkg/client/dynamic/clientset"
 	"github.com/tektoncd/triggers/pkg/client/dynamic/clientset/tekton"
 	"github.com/tektoncd/triggers/test"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	discoveryclient "k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	fakedynamic "k8s.io/client-go/dynamic/fake"
 	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
 	ktesting "k8s.io/client-go/testing"
@@ -49,8 +58,50 @@ const (
 
 func Test_FindAPIResource_error(t *testing.T) {
 	dc := fakekubeclientset.NewSimpleClientset().Discovery()
-	if _, err := findAPIResource("v1", "Pod", dc); err == nil {
-		t.Error("findAPIResource() did not return error when expected")
+	if _, err := FindAPIResource("v1", "Pod", dc); err == nil {
+		t.Error("FindAPIResource() did not return error when expected")
+	}
+}
+
+// stubDiscovery is a discovery client returning list, or failing with err.
+type stubDiscovery struct {
+	discoveryclient.ServerResourcesInterface
+	list *metav1.APIResourceList
+	err  error
+}
+
+func (d stubDiscovery) ServerResourcesForGroupVersion(string) (*metav1.APIResourceList, error) {
+	return d.list, d.err
+}
+
+func Test_FindAPIResource_errorReason(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		dc   discoveryclient.ServerResourcesInterface
+		want error
+	}{{
+		name: "apiVersion not served",
+		dc:   fakekubeclientset.NewSimpleClientset().Discovery(),
+		want: ErrAPIResourceNotFound,
+	}, {
+		name: "kind not served",
+		dc:   stubDiscovery{list: &metav1.APIResourceList{GroupVersion: "v1", APIResources: []metav1.APIResource{{Kind: "Service"}}}},
+		want: ErrAPIResourceNotFound,
+	}, {
+		name: "API server unavailable",
+		dc:   stubDiscovery{err: kerrors.NewServiceUnavailable("starting")},
+		want: ErrDiscoveryNotReady,
+	}, {
+		name: "API server unreachable",
+		dc:   stubDiscovery{err: errors.New("dial tcp 10.0.0.1:443: connect: connection refused")},
+		want: ErrDiscoveryNotReady,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := FindAPIResource("v1", "Pod", tc.dc)
+			if !errors.Is(err, tc.want) {
+				t.Errorf("FindAPIResource() got error %v, want it to wrap %v", err, tc.want)
+			}
+		})
 	}
 }
 
@@ -118,16 +169,72 @@ func TestFindAPIResource(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(fmt.Sprintf("%s_%s", tt.apiVersion, tt.kind), func(t *testing.T) {
-			got, err := findAPIResource(tt.apiVersion, tt.kind, dc)
+			got, err := FindAPIResource(tt.apiVersion, tt.kind, dc)
 			if err != nil {
-				t.Errorf("findAPIResource() returned error: %s", err)
+				t.Errorf("FindAPIResource() returned error: %s", err)
 			} else if diff := cmp.Diff(tt.want, got); diff != "" {
-				t.Errorf("findAPIResource() Diff: -want +got: %s", diff)
+				t.Errorf("FindAPIResource() Diff: -want +got: %s", diff)
 			}
 		})
 	}
 }
 
+func TestAPIPath(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	kubeClient.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{
+			Name:       "pods",
+			Namespaced: true,
+			Kind:       "Pod",
+		}, {
+			Name:       "namespaces",
+			Namespaced: false,
+			Kind:       "Namespace",
+		}},
+	}}
+	test.AddTektonResources(kubeClient)
+
+	for _, tt := range []struct {
+		name string
+		obj  string
+		want string
+	}{{
+		name: "core resource",
+		obj:  `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"my-pod","namespace":"bar"}}`,
+		want: "/api/v1/namespaces/bar/pods/my-pod",
+	}, {
+		name: "cluster scoped resource",
+		obj:  `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"bar"}}`,
+		want: "/api/v1/namespaces/bar",
+	}, {
+		name: "group resource",
+		obj:  `{"apiVersion":"tekton.dev/v1alpha1","kind":"PipelineRun","metadata":{"name":"my-run","namespace":"bar"}}`,
+		want: "/apis/tekton.dev/v1alpha1/namespaces/bar/pipelineruns/my-run",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			if err := obj.UnmarshalJSON([]byte(tt.obj)); err != nil {
+				t.Fatal(err)
+			}
+			got, err := APIPath(obj, kubeClient.Discovery())
+			if err != nil {
+				t.Fatalf("APIPath() returned error: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("APIPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("example.com/v1")
+	obj.SetKind("Unknown")
+	if _, err := APIPath(obj, kubeClient.Discovery()); err == nil {
+		t.Error("APIPath() did not return error for an unknown resource")
+	}
+}
+
 func TestCreateResource(t *testing.T) {
 	elName := "foo-el"
 	elNamespace := "bar"
@@ -194,8 +301,12 @@ func TestCreateResource(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			dynamicClient.ClearActions()
-			if err := Create(logger.Sugar(), tt.json, triggerName, eventID, elName, elNamespace, kubeClient.Discovery(), dynamicSet); err != nil {
-				t.Errorf("createResource() returned error: %s", err)
+			created, err := Create(context.Background(), logger.Sugar(), tt.json, triggerName, eventID, elName, elNamespace, kubeClient.Discovery(), dynamicSet)
+			if err != nil {
+				t.Fatalf("createResource() returned error: %s", err)
+			}
+			if created.GetKind() != "PipelineResource" || created.GetName() != tt.want.Name {
+				t.Errorf("createResource() returned %s %s, want PipelineResource %s", created.GetKind(), created.GetName(), tt.want.Name)
 			}
 
 			gvr := schema.GroupVersionResource{
@@ -215,6 +326,904 @@ func TestCreateResource(t *testing.T) {
 	}
 }
 
+func TestCreateResource_ProvenanceLabels(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	logger := zaptest.NewLogger(t)
+	rt := json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","labels":{"original":"label"}},"spec":{"type":"git"}}`)
+
+	for _, tc := range []struct {
+		name string
+		keys []string
+		want map[string]string
+	}{{
+		name: "some labels",
+		keys: []string{triggers.EventListenerLabelKey, triggers.TriggerLabelKey},
+		want: map[string]string{"original": "label", resourceLabel: "foo-el", triggerLabel: triggerName},
+	}, {
+		name: "no labels",
+		keys: []string{},
+		want: map[string]string{"original": "label"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			dynamicSet := dynamicclientset.New(tekton.WithClient(fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())))
+			ctx := WithProvenanceLabels(context.Background(), tc.keys)
+			created, err := Create(ctx, logger.Sugar(), rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+			if err != nil {
+				t.Fatalf("Create() returned error: %s", err)
+			}
+			if diff := cmp.Diff(tc.want, created.GetLabels()); diff != "" {
+				t.Errorf("Create() labels (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateResource_AlreadyExists(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	logger := zaptest.NewLogger(t)
+	dynamicSet := dynamicclientset.New(tekton.WithClient(fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())))
+	rt := json.RawMessage(`{"kind":"TaskRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"my-taskrun"}}`)
+
+	if _, err := Create(context.Background(), logger.Sugar(), rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet); err != nil {
+		t.Fatalf("Create() returned error: %s", err)
+	}
+	// The error of the API server is wrapped, so that its reason can be reported.
+	_, err := Create(context.Background(), logger.Sugar(), rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+	if !kerrors.IsAlreadyExists(err) {
+		t.Errorf("Create() of an existing resource = %v, want an AlreadyExists error", err)
+	}
+}
+
+func TestCreateResource_LabelSanitization(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	logger := zaptest.NewLogger(t)
+	// The branch label is resolved from a param.
+	rt := json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","labels":{"branch":"feature/Add login!"}},"spec":{"type":"git"}}`)
+
+	for _, tc := range []struct {
+		name     string
+		strategy string
+		want     string
+		wantErr  bool
+	}{{
+		name: "default",
+		want: "feature-Add-login-ebe15bfdc8",
+	}, {
+		name:     "truncate",
+		strategy: triggers.LabelSanitizationTruncate,
+		want:     "feature-Add-login",
+	}, {
+		name:     "reject",
+		strategy: triggers.LabelSanitizationReject,
+		wantErr:  true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			dynamicSet := dynamicclientset.New(tekton.WithClient(fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())))
+			ctx := context.Background()
+			if tc.strategy != "" {
+				ctx = WithLabelSanitization(ctx, tc.strategy)
+			}
+			created, err := Create(ctx, logger.Sugar(), rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Create() returned error %v, want error %t", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			want := map[string]string{"branch": tc.want, resourceLabel: "foo-el", triggerLabel: triggerName, eventIDLabel: eventID}
+			if diff := cmp.Diff(want, created.GetLabels()); diff != "" {
+				t.Errorf("Create() labels (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateResource_TriggerMetadata(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	logger := zaptest.NewLogger(t)
+	rt := json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","labels":{"team":"template"},"annotations":{"original":"annotation"}},"spec":{"type":"git"}}`)
+	dynamicSet := dynamicclientset.New(tekton.WithClient(fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())))
+
+	ctx := WithTriggerMetadata(context.Background(),
+		map[string]string{"team": "trigger", "cost-center": "cc-1234", triggerLabel: "other"},
+		map[string]string{"example.com/notify": "#builds"})
+	created, err := Create(ctx, logger.Sugar(), rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+	if err != nil {
+		t.Fatalf("Create() returned error: %s", err)
+	}
+	wantLabels := map[string]string{"team": "template", "cost-center": "cc-1234", resourceLabel: "foo-el", triggerLabel: triggerName, eventIDLabel: eventID}
+	if diff := cmp.Diff(wantLabels, created.GetLabels()); diff != "" {
+		t.Errorf("Create() labels (-want +got): %s", diff)
+	}
+	wantAnnotations := map[string]string{"original": "annotation", "example.com/notify": "#builds"}
+	if diff := cmp.Diff(wantAnnotations, created.GetAnnotations()); diff != "" {
+		t.Errorf("Create() annotations (-want +got): %s", diff)
+	}
+}
+
+func TestCreateResource_TTL(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	logger := zaptest.NewLogger(t)
+
+	for _, tc := range []struct {
+		name       string
+		json       json.RawMessage
+		annotation string
+		want       map[string]string
+	}{{
+		name: "default annotation",
+		json: json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource"},"spec":{"type":"git"}}`),
+		want: map[string]string{"triggers.tekton.dev/ttl-after-completion": "1h30m0s"},
+	}, {
+		name:       "custom annotation",
+		json:       json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","annotations":{"original":"annotation"}},"spec":{"type":"git"}}`),
+		annotation: "example.com/ttl",
+		want:       map[string]string{"original": "annotation", "example.com/ttl": "1h30m0s"},
+	}, {
+		name: "annotation in template",
+		json: json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","annotations":{"triggers.tekton.dev/ttl-after-completion":"10m"}},"spec":{"type":"git"}}`),
+		want: map[string]string{"triggers.tekton.dev/ttl-after-completion": "10m"},
+	}, {
+		name: "owned resource",
+		json: json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","ownerReferences":[{"apiVersion":"tekton.dev/v1beta1","kind":"PipelineRun","name":"my-run","uid":"1234"}]},"spec":{"type":"git"}}`),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			dynamicSet := dynamicclientset.New(tekton.WithClient(fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())))
+			ctx := WithTTL(context.Background(), tc.annotation, 90*time.Minute)
+			created, err := Create(ctx, logger.Sugar(), tc.json, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+			if err != nil {
+				t.Fatalf("Create() returned error: %s", err)
+			}
+			if diff := cmp.Diff(tc.want, created.GetAnnotations()); diff != "" {
+				t.Errorf("Create() annotations (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateResource_Finalizer(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	logger := zaptest.NewLogger(t)
+	finalizer := "example.com/cleanup"
+
+	for _, tc := range []struct {
+		name string
+		json json.RawMessage
+		want []string
+	}{{
+		name: "no template finalizers",
+		json: json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource"},"spec":{"type":"git"}}`),
+		want: []string{finalizer},
+	}, {
+		name: "template finalizers",
+		json: json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","finalizers":["example.com/other"]},"spec":{"type":"git"}}`),
+		want: []string{"example.com/other", finalizer},
+	}, {
+		name: "finalizer already in template",
+		json: json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","finalizers":["example.com/cleanup","example.com/other"]},"spec":{"type":"git"}}`),
+		want: []string{finalizer, "example.com/other"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			dynamicSet := dynamicclientset.New(tekton.WithClient(fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())))
+			ctx := WithFinalizer(context.Background(), finalizer)
+			created, err := Create(ctx, logger.Sugar(), tc.json, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+			if err != nil {
+				t.Fatalf("Create() returned error: %s", err)
+			}
+			if diff := cmp.Diff(tc.want, created.GetFinalizers()); diff != "" {
+				t.Errorf("Create() finalizers (-want +got): %s", diff)
+			}
+		})
+	}
+
+	t.Run("merge patch preserves existing finalizers", func(t *testing.T) {
+		existing := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "tekton.dev/v1alpha1",
+			"kind":       "PipelineResource",
+			"metadata": map[string]interface{}{
+				"name":       "my-pipelineresource",
+				"namespace":  "bar",
+				"finalizers": []interface{}{finalizer, "example.com/controller"},
+			},
+		}}
+		dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), existing)
+		rt := json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","annotations":{"triggers.tekton.dev/patch-strategy":"merge"}},"spec":{"type":"git"}}`)
+		patched, err := Create(WithFinalizer(context.Background(), finalizer), logger.Sugar(), rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicClient)
+		if err != nil {
+			t.Fatalf("Create() returned error: %s", err)
+		}
+		if diff := cmp.Diff([]string{finalizer, "example.com/controller"}, patched.GetFinalizers()); diff != "" {
+			t.Errorf("Create() finalizers (-want +got): %s", diff)
+		}
+	})
+}
+
+// optionsRecorder is a dynamic client recording the options of the create and patch calls.
+type optionsRecorder struct {
+	dynamic.Interface
+	create []metav1.CreateOptions
+	patch  []metav1.PatchOptions
+}
+
+func (o *optionsRecorder) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &recordingResource{NamespaceableResourceInterface: o.Interface.Resource(gvr), recorder: o}
+}
+
+type recordingResource struct {
+	dynamic.NamespaceableResourceInterface
+	recorder *optionsRecorder
+}
+
+func (r *recordingResource) Namespace(ns string) dynamic.ResourceInterface {
+	return &recordingNamespacedResource{ResourceInterface: r.NamespaceableResourceInterface.Namespace(ns), recorder: r.recorder}
+}
+
+type recordingNamespacedResource struct {
+	dynamic.ResourceInterface
+	recorder *optionsRecorder
+}
+
+func (r *recordingNamespacedResource) Create(ctx context.Context, obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	r.recorder.create = append(r.recorder.create, options)
+	return r.ResourceInterface.Create(ctx, obj, options, subresources...)
+}
+
+func (r *recordingNamespacedResource) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	r.recorder.patch = append(r.recorder.patch, options)
+	return r.ResourceInterface.Patch(ctx, name, pt, data, options, subresources...)
+}
+
+func TestCreateResource_CreateNamespace(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	logger := zaptest.NewLogger(t)
+	finalizer := "example.com/cleanup"
+	rt := json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","namespace":"pr-1234","annotations":{"triggers.tekton.dev/create-namespace":"true"}},"spec":{"type":"git"}}`)
+
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+	dynamicSet := dynamicclientset.New(tekton.WithClient(dynamicClient))
+	ctx := WithFinalizer(context.Background(), finalizer)
+	created, err := Create(ctx, logger.Sugar(), rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+	if err != nil {
+		t.Fatalf("Create() returned error: %s", err)
+	}
+	if created.GetNamespace() != "pr-1234" || len(created.GetAnnotations()) != 0 {
+		t.Errorf("Create() created %v, want the resource in pr-1234 without the annotation", created)
+	}
+	ns, err := dynamicSet.Resource(namespacesGVR).Get(context.Background(), "pr-1234", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() namespace returned error: %s", err)
+	}
+	wantLabels := map[string]string{resourceLabel: "foo-el", triggerLabel: triggerName, eventIDLabel: eventID}
+	if diff := cmp.Diff(wantLabels, ns.GetLabels()); diff != "" {
+		t.Errorf("namespace labels (-want +got): %s", diff)
+	}
+	if diff := cmp.Diff([]string{finalizer}, ns.GetFinalizers()); diff != "" {
+		t.Errorf("namespace finalizers (-want +got): %s", diff)
+	}
+
+	// The namespace already exists for the next events, which don't need to create it.
+	dynamicClient.PrependReactor("create", "namespaces", func(ktesting.Action) (bool, runtime.Object, error) {
+		return true, nil, kerrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "pr-1234", errors.New("forbidden"))
+	})
+	rt = json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"other-pipelineresource","namespace":"pr-1234","annotations":{"triggers.tekton.dev/create-namespace":"true"}},"spec":{"type":"git"}}`)
+	if _, err := Create(context.Background(), logger.Sugar(), rt, triggerName, "67890", "foo-el", "bar", kubeClient.Discovery(), dynamicSet); err != nil {
+		t.Fatalf("Create() returned error for an existing namespace: %s", err)
+	}
+
+	rt = json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","namespace":"pr-5678","annotations":{"triggers.tekton.dev/create-namespace":"true"}},"spec":{"type":"git"}}`)
+	if _, err := Create(context.Background(), logger.Sugar(), rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet); !kerrors.IsForbidden(err) {
+		t.Errorf("Create() returned error %v, want a forbidden error", err)
+	}
+}
+
+func TestCreateResource_CreateNamespace_Error(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	logger := zaptest.NewLogger(t)
+	for _, tc := range []struct {
+		name    string
+		json    json.RawMessage
+		wantErr string
+	}{{
+		name:    "invalid annotation",
+		json:    json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","annotations":{"triggers.tekton.dev/create-namespace":"yes please"}}}`),
+		wantErr: "invalid triggers.tekton.dev/create-namespace annotation",
+	}, {
+		name:    "invalid namespace",
+		json:    json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","namespace":"PR_1234","annotations":{"triggers.tekton.dev/create-namespace":"true"}}}`),
+		wantErr: `invalid namespace "PR_1234"`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			dynamicSet := dynamicclientset.New(tekton.WithClient(fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())))
+			_, err := Create(context.Background(), logger.Sugar(), tc.json, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Create() returned error %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateResource_FieldValidation(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	logger := zaptest.NewLogger(t)
+	rt := json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","annotations":{"triggers.tekton.dev/patch-strategy":"merge"}},"spec":{"type":"git"}}`)
+
+	for _, tc := range []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{{
+		name: "default",
+		ctx:  context.Background(),
+		want: metav1.FieldValidationWarn,
+	}, {
+		name: "strict",
+		ctx:  WithFieldValidation(context.Background(), metav1.FieldValidationStrict),
+		want: metav1.FieldValidationStrict,
+	}, {
+		name: "ignore",
+		ctx:  WithFieldValidation(context.Background(), metav1.FieldValidationIgnore),
+		want: metav1.FieldValidationIgnore,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			dc := &optionsRecorder{Interface: fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())}
+			// The second call patches the resource created by the first one.
+			for i := 0; i < 2; i++ {
+				if _, err := Create(tc.ctx, logger.Sugar(), rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dc); err != nil {
+					t.Fatalf("Create() returned error: %s", err)
+				}
+			}
+			if diff := cmp.Diff([]metav1.CreateOptions{{FieldValidation: tc.want}, {FieldValidation: tc.want}}, dc.create); diff != "" {
+				t.Errorf("Create() create options (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff([]metav1.PatchOptions{{FieldValidation: tc.want}}, dc.patch); diff != "" {
+				t.Errorf("Create() patch options (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateResource_CreatedObserver(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	logger := zaptest.NewLogger(t)
+	dynamicSet := dynamicclientset.New(tekton.WithClient(fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())))
+	var observed []string
+	ctx := WithCreatedObserver(context.Background(), func(obj *unstructured.Unstructured) {
+		observed = append(observed, fmt.Sprintf("%s %s/%s", obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName()))
+	})
+
+	// The second call patches the resource created by the first one, and the third one fails.
+	rt := json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","annotations":{"triggers.tekton.dev/patch-strategy":"merge"}},"spec":{"type":"git"}}`)
+	for i := 0; i < 2; i++ {
+		if _, err := Create(ctx, logger.Sugar(), rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet); err != nil {
+			t.Fatalf("Create() returned error: %s", err)
+		}
+	}
+	rt = json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource"},"spec":{"type":"git"}}`)
+	if _, err := Create(ctx, logger.Sugar(), rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet); err == nil {
+		t.Fatal("Create() of an existing resource did not fail")
+	}
+	if diff := cmp.Diff([]string{"tekton.dev/v1alpha1, Kind=PipelineResource bar/my-pipelineresource"}, observed); diff != "" {
+		t.Errorf("observed resources (-want +got): %s", diff)
+	}
+}
+
+func TestCreateResource_DefaultType(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	logger := zaptest.NewLogger(t)
+	dynamicSet := dynamicclientset.New(tekton.WithClient(fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())))
+	ctx := WithDefaultType(context.Background(), "tekton.dev/v1alpha1", "PipelineResource")
+
+	for _, tc := range []struct {
+		name string
+		rt   json.RawMessage
+		want string
+	}{{
+		name: "untyped template",
+		rt:   json.RawMessage(`{"metadata":{"name":"defaulted"},"spec":{"type":"git"}}`),
+		want: "tekton.dev/v1alpha1, Kind=PipelineResource",
+	}, {
+		name: "typed template",
+		rt:   json.RawMessage(`{"kind":"TaskRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"explicit"}}`),
+		want: "tekton.dev/v1beta1, Kind=TaskRun",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			created, err := Create(ctx, logger.Sugar(), tc.rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+			if err != nil {
+				t.Fatalf("Create() returned error: %s", err)
+			}
+			if got := created.GroupVersionKind().String(); got != tc.want {
+				t.Errorf("Create() created a %s, want a %s", got, tc.want)
+			}
+		})
+	}
+
+	// Without a default type, an untyped template can't be created.
+	rt := json.RawMessage(`{"metadata":{"name":"untyped"},"spec":{"type":"git"}}`)
+	if _, err := Create(context.Background(), logger.Sugar(), rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet); err == nil {
+		t.Error("Create() of an untyped template without a default type did not fail")
+	}
+}
+
+func TestCreateResource_KindPolicy(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	logger := zaptest.NewLogger(t)
+	ctx := WithKindPolicy(context.Background(), &triggersv1.ResourceKindPolicy{
+		Allowed: []triggersv1.ResourceKind{{Group: "tekton.dev", Kind: "*"}},
+		Denied:  []triggersv1.ResourceKind{{Group: "tekton.dev", Version: "v1alpha1", Kind: "PipelineResource"}},
+	})
+
+	for _, tc := range []struct {
+		name    string
+		rt      json.RawMessage
+		wantErr *KindNotAllowedError
+	}{{
+		name: "allowed kind",
+		rt:   json.RawMessage(`{"kind":"TaskRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"my-taskrun"}}`),
+	}, {
+		name:    "denied kind",
+		rt:      json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"generateName":"my-pipelineresource-"},"spec":{"type":"git"}}`),
+		wantErr: &KindNotAllowedError{GroupVersionKind: schema.GroupVersionKind{Group: "tekton.dev", Version: "v1alpha1", Kind: "PipelineResource"}, Name: "my-pipelineresource-"},
+	}, {
+		name:    "namespace of an allowed kind",
+		rt:      json.RawMessage(`{"kind":"TaskRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"my-taskrun","namespace":"pr-1234","annotations":{"triggers.tekton.dev/create-namespace":"true"}}}`),
+		wantErr: &KindNotAllowedError{GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, Name: "pr-1234"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+			_, err := Create(ctx, logger.Sugar(), tc.rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicclientset.New(tekton.WithClient(dynamicClient)))
+			if tc.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Create() returned error: %s", err)
+				}
+				return
+			}
+			var kindErr *KindNotAllowedError
+			if !errors.As(err, &kindErr) {
+				t.Fatalf("Create() returned error %v, want a KindNotAllowedError", err)
+			}
+			if diff := cmp.Diff(tc.wantErr, kindErr); diff != "" {
+				t.Errorf("Create() error (-want +got): %s", diff)
+			}
+			// Nothing is requested from the API server for a kind that isn't allowed.
+			if actions := dynamicClient.Actions(); len(actions) != 0 {
+				t.Errorf("Create() made the requests %v, want none", actions)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+	dynamicSet := dynamicclientset.New(tekton.WithClient(dynamicClient))
+	logger := zaptest.NewLogger(t)
+
+	rt := json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource"}}`)
+	created, err := Create(context.Background(), logger.Sugar(), rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+	if err != nil {
+		t.Fatalf("Create() returned error: %s", err)
+	}
+	if err := Delete(context.Background(), created, kubeClient.Discovery(), dynamicSet); err != nil {
+		t.Fatalf("Delete() returned error: %s", err)
+	}
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1alpha1", Resource: "pipelineresources"}
+	if _, err := dynamicSet.Resource(gvr).Namespace("bar").Get(context.Background(), "my-pipelineresource", metav1.GetOptions{}); !kerrors.IsNotFound(err) {
+		t.Errorf("Get() after Delete() returned %v, want a not found error", err)
+	}
+	if err := Delete(context.Background(), created, kubeClient.Discovery(), dynamicSet); !kerrors.IsNotFound(err) {
+		t.Errorf("Delete() of a deleted resource returned %v, want a not found error", err)
+	}
+}
+
+func TestMayPatch(t *testing.T) {
+	for _, tt := range []struct {
+		rt   string
+		want bool
+	}{{
+		rt:   `{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"cm","annotations":{"triggers.tekton.dev/patch-strategy":"merge"}}}`,
+		want: true,
+	}, {
+		rt: `{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"cm"}}`,
+	}, {
+		rt: `not json`,
+	}} {
+		if got := MayPatch(json.RawMessage(tt.rt)); got != tt.want {
+			t.Errorf("MayPatch(%s) = %t, want %t", tt.rt, got, tt.want)
+		}
+	}
+}
+
+func TestTemplateCreateTimeout(t *testing.T) {
+	for _, tt := range []struct {
+		rt   string
+		want time.Duration
+	}{{
+		rt:   `{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"cm","annotations":{"triggers.tekton.dev/create-timeout":"10s"}}}`,
+		want: 10 * time.Second,
+	}, {
+		rt: `{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"cm","annotations":{"triggers.tekton.dev/create-timeout":"soon"}}}`,
+	}, {
+		rt: `{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"cm"}}`,
+	}, {
+		rt: `not json`,
+	}} {
+		if got := TemplateCreateTimeout(json.RawMessage(tt.rt)); got != tt.want {
+			t.Errorf("TemplateCreateTimeout(%s) = %s, want %s", tt.rt, got, tt.want)
+		}
+	}
+}
+
+func TestCreateResource_CreateTimeoutAnnotation(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	logger := zaptest.NewLogger(t).Sugar()
+	dynamicSet := dynamicclientset.New(tekton.WithClient(fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())))
+
+	rt := json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"pr-1234","annotations":{"triggers.tekton.dev/create-timeout":"10s"}},"spec":{"type":"git"}}`)
+	created, err := Create(context.Background(), logger, rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+	if err != nil {
+		t.Fatalf("Create() returned error: %s", err)
+	}
+	if len(created.GetAnnotations()) != 0 {
+		t.Errorf("Create() created %v, want the resource without the annotation", created)
+	}
+
+	rt = json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"pr-5678","annotations":{"triggers.tekton.dev/create-timeout":"0s"}},"spec":{"type":"git"}}`)
+	wantErr := `invalid triggers.tekton.dev/create-timeout annotation "0s": must be a positive duration`
+	if _, err := Create(context.Background(), logger, rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet); err == nil || err.Error() != wantErr {
+		t.Errorf("Create() returned error %v, want %q", err, wantErr)
+	}
+}
+
+func TestCreateResource_Patch(t *testing.T) {
+	elName := "foo-el"
+	elNamespace := "bar"
+
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	kubeClient.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{
+			Name:       "configmaps",
+			Namespaced: true,
+			Kind:       "ConfigMap",
+		}},
+	}}
+	test.AddTektonResources(kubeClient)
+
+	existingPipelineResource := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "tekton.dev/v1alpha1",
+		"kind":       "PipelineResource",
+		"metadata": map[string]interface{}{
+			"name":        "my-pipelineresource",
+			"namespace":   elNamespace,
+			"annotations": map[string]interface{}{"existing": "annotation"},
+		},
+		"spec": map[string]interface{}{"type": "git"},
+	}}
+	existingConfigMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      "my-configmap",
+			"namespace": elNamespace,
+		},
+	}}
+
+	tests := []struct {
+		name          string
+		json          []byte
+		wantPatchType types.PatchType
+		wantErr       bool
+	}{{
+		name:          "merge patch",
+		json:          json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","annotations":{"triggers.tekton.dev/patch-strategy":"merge","new":"annotation"}}}`),
+		wantPatchType: types.MergePatchType,
+	}, {
+		name:          "strategic merge patch falls back to merge patch for custom resources",
+		json:          json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","annotations":{"triggers.tekton.dev/patch-strategy":"strategic-merge","new":"annotation"}}}`),
+		wantPatchType: types.MergePatchType,
+	}, {
+		name:          "strategic merge patch for built-in types",
+		json:          json.RawMessage(`{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"my-configmap","annotations":{"triggers.tekton.dev/patch-strategy":"strategic-merge"}},"data":{"foo":"bar"}}`),
+		wantPatchType: types.StrategicMergePatchType,
+	}, {
+		name:    "existing resource without patch strategy",
+		json:    json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource"}}`),
+		wantErr: true,
+	}, {
+		name:    "invalid patch strategy",
+		json:    json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","annotations":{"triggers.tekton.dev/patch-strategy":"replace"}}}`),
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), existingPipelineResource.DeepCopy(), existingConfigMap.DeepCopy())
+			// The fake client can't apply strategic merge patches to unstructured objects, so only record them.
+			dynamicClient.PrependReactor("patch", "configmaps", func(action ktesting.Action) (bool, runtime.Object, error) {
+				return true, existingConfigMap.DeepCopy(), nil
+			})
+
+			_, err := Create(context.Background(), zaptest.NewLogger(t).Sugar(), tt.json, triggerName, eventID, elName, elNamespace, kubeClient.Discovery(), dynamicClient)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Create() did not return error when expected")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create() returned error: %s", err)
+			}
+
+			actions := dynamicClient.Actions()
+			if len(actions) != 2 {
+				t.Fatalf("expected a create and a patch action, got: %v", actions)
+			}
+			patchAction, ok := actions[1].(ktesting.PatchAction)
+			if !ok {
+				t.Fatalf("expected a patch action, got: %v", actions[1])
+			}
+			if patchAction.GetPatchType() != tt.wantPatchType {
+				t.Errorf("expected patch type %s, got %s", tt.wantPatchType, patchAction.GetPatchType())
+			}
+			var patch unstructured.Unstructured
+			if err := patch.UnmarshalJSON(patchAction.GetPatch()); err != nil {
+				t.Fatalf("couldn't unmarshal patch: %v", err)
+			}
+			if _, ok := patch.GetAnnotations()[PatchStrategyAnnotation]; ok {
+				t.Errorf("expected %s annotation to be removed from the patch", PatchStrategyAnnotation)
+			}
+			if got := patch.GetLabels()[eventIDLabel]; got != eventID {
+				t.Errorf("expected patch to set the event ID label, got %q", got)
+			}
+		})
+	}
+
+	t.Run("merge patch preserves existing fields", func(t *testing.T) {
+		dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), existingPipelineResource.DeepCopy())
+		rt := json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","annotations":{"triggers.tekton.dev/patch-strategy":"merge","new":"annotation"}}}`)
+		if _, err := Create(context.Background(), zaptest.NewLogger(t).Sugar(), rt, triggerName, eventID, elName, elNamespace, kubeClient.Discovery(), dynamicClient); err != nil {
+			t.Fatalf("Create() returned error: %s", err)
+		}
+		gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1alpha1", Resource: "pipelineresources"}
+		got, err := dynamicClient.Resource(gvr).Namespace(elNamespace).Get(context.Background(), "my-pipelineresource", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("couldn't get patched resource: %v", err)
+		}
+		wantAnnotations := map[string]string{"existing": "annotation", "new": "annotation"}
+		if diff := cmp.Diff(wantAnnotations, got.GetAnnotations()); diff != "" {
+			t.Errorf("annotations: -want +got: %s", diff)
+		}
+		if spec, _, _ := unstructured.NestedString(got.Object, "spec", "type"); spec != "git" {
+			t.Errorf("expected spec.type to be preserved, got %q", spec)
+		}
+	})
+}
+
+func TestCreateResource_CreateStrategies(t *testing.T) {
+	elNamespace := "bar"
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	kubeClient.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{
+			Name:       "configmaps",
+			Namespaced: true,
+			Kind:       "ConfigMap",
+		}},
+	}}
+	test.AddTektonResources(kubeClient)
+	ctx := WithCreateStrategies(context.Background(), []triggersv1.ResourceCreateStrategy{
+		{ResourceKind: triggersv1.ResourceKind{Version: "v1", Kind: "ConfigMap"}, Strategy: triggersv1.CreateStrategyApply},
+		{ResourceKind: triggersv1.ResourceKind{Group: "tekton.dev", Kind: "PipelineResource"}, Strategy: triggersv1.CreateStrategyPatch},
+		{ResourceKind: triggersv1.ResourceKind{Group: "tekton.dev", Kind: "TaskRun"}, Strategy: triggersv1.CreateStrategyCreateOrUpdate},
+	})
+
+	existingPipelineResource := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "tekton.dev/v1alpha1",
+		"kind":       "PipelineResource",
+		"metadata": map[string]interface{}{
+			"name":      "my-pipelineresource",
+			"namespace": elNamespace,
+		},
+		"spec": map[string]interface{}{"type": "git"},
+	}}
+	existingTaskRun := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "tekton.dev/v1beta1",
+		"kind":       "TaskRun",
+		"metadata": map[string]interface{}{
+			"name":            "my-taskrun",
+			"namespace":       elNamespace,
+			"resourceVersion": "7",
+			"finalizers":      []interface{}{"example.dev/cleanup"},
+		},
+		"spec": map[string]interface{}{"serviceAccountName": "old"},
+	}}
+	existingPipelineRun := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "tekton.dev/v1alpha1",
+		"kind":       "PipelineRun",
+		"metadata": map[string]interface{}{
+			"name":      "my-pipelinerun",
+			"namespace": elNamespace,
+		},
+	}}
+
+	for _, tc := range []struct {
+		name        string
+		rt          json.RawMessage
+		wantActions []string
+		wantPatch   types.PatchType
+		wantErr     bool
+	}{{
+		name:        "apply",
+		rt:          json.RawMessage(`{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"my-configmap"},"data":{"foo":"bar"}}`),
+		wantActions: []string{"patch"},
+		wantPatch:   types.ApplyPatchType,
+	}, {
+		name:        "apply creates the resources with a generateName",
+		rt:          json.RawMessage(`{"kind":"ConfigMap","apiVersion":"v1","metadata":{"generateName":"my-configmap-"},"data":{"foo":"bar"}}`),
+		wantActions: []string{"create"},
+	}, {
+		name:        "the patch strategy annotation takes precedence",
+		rt:          json.RawMessage(`{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"my-configmap","annotations":{"triggers.tekton.dev/patch-strategy":"merge"}},"data":{"foo":"bar"}}`),
+		wantActions: []string{"create"},
+	}, {
+		name:        "patch",
+		rt:          json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource"},"spec":{"type":"git"}}`),
+		wantActions: []string{"create", "patch"},
+		// The strategic merge patch falls back to a merge patch for custom resources.
+		wantPatch: types.MergePatchType,
+	}, {
+		name:        "createOrUpdate",
+		rt:          json.RawMessage(`{"kind":"TaskRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"my-taskrun"},"spec":{"serviceAccountName":"new"}}`),
+		wantActions: []string{"create", "get", "update"},
+	}, {
+		name:        "create",
+		rt:          json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelinerun"}}`),
+		wantActions: []string{"create"},
+		wantErr:     true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), existingPipelineResource.DeepCopy(), existingTaskRun.DeepCopy(), existingPipelineRun.DeepCopy())
+			// The fake client can't apply server-side applies, so only record them.
+			dynamicClient.PrependReactor("patch", "configmaps", func(action ktesting.Action) (bool, runtime.Object, error) {
+				return true, &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"}}, nil
+			})
+
+			_, err := Create(WithFinalizer(ctx, "triggers.tekton.dev/cleanup"), zaptest.NewLogger(t).Sugar(), tc.rt, triggerName, eventID, "foo-el", elNamespace, kubeClient.Discovery(), dynamicClient)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("Create() returned error %v, want error %t", err, tc.wantErr)
+			}
+			var verbs []string
+			for _, a := range dynamicClient.Actions() {
+				verbs = append(verbs, a.GetVerb())
+				if p, ok := a.(ktesting.PatchAction); ok && p.GetPatchType() != tc.wantPatch {
+					t.Errorf("Create() patched with %s, want %s", p.GetPatchType(), tc.wantPatch)
+				}
+			}
+			if diff := cmp.Diff(tc.wantActions, verbs); diff != "" {
+				t.Errorf("Create() requests (-want +got): %s", diff)
+			}
+		})
+	}
+
+	t.Run("createOrUpdate keeps the finalizers of the existing resource", func(t *testing.T) {
+		dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), existingTaskRun.DeepCopy())
+		rt := json.RawMessage(`{"kind":"TaskRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"my-taskrun"},"spec":{"serviceAccountName":"new"}}`)
+		got, err := Create(WithFinalizer(ctx, "triggers.tekton.dev/cleanup"), zaptest.NewLogger(t).Sugar(), rt, triggerName, eventID, "foo-el", elNamespace, kubeClient.Discovery(), dynamicClient)
+		if err != nil {
+			t.Fatalf("Create() returned error: %s", err)
+		}
+		if diff := cmp.Diff([]string{"example.dev/cleanup", "triggers.tekton.dev/cleanup"}, got.GetFinalizers()); diff != "" {
+			t.Errorf("finalizers (-want +got): %s", diff)
+		}
+		if sa, _, _ := unstructured.NestedString(got.Object, "spec", "serviceAccountName"); sa != "new" {
+			t.Errorf("spec.serviceAccountName = %q, want the one of the template", sa)
+		}
+	})
+}
+
+func TestCreateResource_ExpectedResourceVersion(t *testing.T) {
+	elName := "foo-el"
+	elNamespace := "bar"
+
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "tekton.dev/v1alpha1",
+		"kind":       "PipelineResource",
+		"metadata": map[string]interface{}{
+			"name":            "my-pipelineresource",
+			"namespace":       elNamespace,
+			"resourceVersion": "42",
+		},
+	}}
+	rt := json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","annotations":{"triggers.tekton.dev/patch-strategy":"merge","triggers.tekton.dev/expected-resource-version":"42"}}}`)
+	gr := schema.GroupResource{Group: "tekton.dev", Resource: "pipelineresources"}
+
+	tests := []struct {
+		name         string
+		patchErr     error
+		objects      []runtime.Object
+		wantConflict bool
+	}{{
+		name:    "resourceVersion matches",
+		objects: []runtime.Object{existing.DeepCopy()},
+	}, {
+		name:         "resourceVersion changed",
+		objects:      []runtime.Object{existing.DeepCopy()},
+		patchErr:     kerrors.NewConflict(gr, "my-pipelineresource", errors.New("the object has been modified")),
+		wantConflict: true,
+	}, {
+		name:         "resource deleted",
+		wantConflict: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), tt.objects...)
+			if tt.patchErr != nil {
+				dynamicClient.PrependReactor("patch", "pipelineresources", func(action ktesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.patchErr
+				})
+			}
+
+			_, err := Create(context.Background(), zaptest.NewLogger(t).Sugar(), rt, triggerName, eventID, elName, elNamespace, kubeClient.Discovery(), dynamicClient)
+			var conflict *ConflictError
+			if got := errors.As(err, &conflict); got != tt.wantConflict {
+				t.Fatalf("Create() returned error %v, want conflict: %t", err, tt.wantConflict)
+			}
+			if tt.wantConflict {
+				if conflict.ExpectedResourceVersion != "42" {
+					t.Errorf("expected resourceVersion 42 in conflict error, got %q", conflict.ExpectedResourceVersion)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create() returned error: %s", err)
+			}
+
+			actions := dynamicClient.Actions()
+			if len(actions) != 1 {
+				t.Fatalf("expected only a patch action, got: %v", actions)
+			}
+			patchAction, ok := actions[0].(ktesting.PatchAction)
+			if !ok {
+				t.Fatalf("expected a patch action, got: %v", actions[0])
+			}
+			var patch unstructured.Unstructured
+			if err := patch.UnmarshalJSON(patchAction.GetPatch()); err != nil {
+				t.Fatalf("couldn't unmarshal patch: %v", err)
+			}
+			if got := patch.GetResourceVersion(); got != "42" {
+				t.Errorf("expected patch to carry resourceVersion 42 as a precondition, got %q", got)
+			}
+			if _, ok := patch.GetAnnotations()[ExpectedResourceVersionAnnotation]; ok {
+				t.Errorf("expected %s annotation to be removed from the patch", ExpectedResourceVersionAnnotation)
+			}
+		})
+	}
+
+	t.Run("requires a patch strategy", func(t *testing.T) {
+		dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+		rt := json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"my-pipelineresource","annotations":{"triggers.tekton.dev/expected-resource-version":"42"}}}`)
+		if _, err := Create(context.Background(), zaptest.NewLogger(t).Sugar(), rt, triggerName, eventID, elName, elNamespace, kubeClient.Discovery(), dynamicClient); err == nil {
+			t.Fatal("Create() did not return error when expected")
+		}
+	})
+}
+
 func Test_AddLabels(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -324,3 +1333,159 @@ func Test_AddLabels(t *testing.T) {
 		}
 	})
 }
+
+func Test_ApplyGenerateNameFromTrigger(t *testing.T) {
+	tests := []struct {
+		name        string
+		triggerName string
+		metadata    map[string]interface{}
+		want        map[string]interface{}
+	}{{
+		name:        "no annotation",
+		triggerName: "build-api",
+		metadata:    map[string]interface{}{"generateName": "pipelinerun-"},
+		want:        map[string]interface{}{"generateName": "pipelinerun-"},
+	}, {
+		name:        "without generateName",
+		triggerName: "build-api",
+		metadata: map[string]interface{}{
+			"annotations": map[string]interface{}{GenerateNameFromTriggerAnnotation: "true"},
+		},
+		want: map[string]interface{}{"generateName": "build-api-"},
+	}, {
+		name:        "prepended to generateName",
+		triggerName: "build-api",
+		metadata: map[string]interface{}{
+			"generateName": "pipelinerun-",
+			"annotations":  map[string]interface{}{GenerateNameFromTriggerAnnotation: "true", "foo": "bar"},
+		},
+		want: map[string]interface{}{
+			"generateName": "build-api-pipelinerun-",
+			"annotations":  map[string]interface{}{"foo": "bar"},
+		},
+	}, {
+		name:        "generateName already prefixed",
+		triggerName: "build-api",
+		metadata: map[string]interface{}{
+			"generateName": "build-api-run-",
+			"annotations":  map[string]interface{}{GenerateNameFromTriggerAnnotation: "true"},
+		},
+		want: map[string]interface{}{"generateName": "build-api-run-"},
+	}, {
+		name:        "sanitized trigger name",
+		triggerName: "--Build_API/v2.",
+		metadata: map[string]interface{}{
+			"annotations": map[string]interface{}{GenerateNameFromTriggerAnnotation: "true"},
+		},
+		want: map[string]interface{}{"generateName": "build-api-v2-"},
+	}, {
+		name:        "trigger name truncated",
+		triggerName: strings.Repeat("a", 60),
+		metadata: map[string]interface{}{
+			"generateName": "run-",
+			"annotations":  map[string]interface{}{GenerateNameFromTriggerAnnotation: "true"},
+		},
+		want: map[string]interface{}{"generateName": strings.Repeat("a", 53) + "-run-"},
+	}, {
+		name:        "generateName truncated",
+		triggerName: "build",
+		metadata: map[string]interface{}{
+			"generateName": strings.Repeat("b", 60),
+			"annotations":  map[string]interface{}{GenerateNameFromTriggerAnnotation: "true"},
+		},
+		want: map[string]interface{}{"generateName": "b-" + strings.Repeat("b", 56)},
+	}, {
+		name:        "explicit name",
+		triggerName: "build-api",
+		metadata: map[string]interface{}{
+			"name":        "my-run",
+			"annotations": map[string]interface{}{GenerateNameFromTriggerAnnotation: "true"},
+		},
+		want: map[string]interface{}{"name": "my-run"},
+	}, {
+		name:        "disabled",
+		triggerName: "build-api",
+		metadata: map[string]interface{}{
+			"generateName": "pipelinerun-",
+			"annotations":  map[string]interface{}{GenerateNameFromTriggerAnnotation: "false"},
+		},
+		want: map[string]interface{}{"generateName": "pipelinerun-"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			us := &unstructured.Unstructured{Object: map[string]interface{}{"metadata": tt.metadata}}
+			if err := applyGenerateNameFromTrigger(us, tt.triggerName); err != nil {
+				t.Fatalf("applyGenerateNameFromTrigger() returned error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, us.Object["metadata"]); diff != "" {
+				t.Errorf("applyGenerateNameFromTrigger() (-want, +got): %s", diff)
+			}
+			if name := us.GetGenerateName(); len(name) > maxGenerateNameLength {
+				t.Errorf("generateName %q is longer than %d characters", name, maxGenerateNameLength)
+			}
+		})
+	}
+}
+
+func Test_ApplyGenerateNameFromTrigger_Error(t *testing.T) {
+	us := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{GenerateNameFromTriggerAnnotation: "yes please"},
+		},
+	}}
+	if err := applyGenerateNameFromTrigger(us, "build-api"); err == nil {
+		t.Error("applyGenerateNameFromTrigger() did not return error for invalid annotation value")
+	}
+}
+
+func TestCreateResource_IdempotencyKey(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	logger := zaptest.NewLogger(t)
+	dynamicSet := dynamicclientset.New(tekton.WithClient(fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())))
+	rt := json.RawMessage(`{"kind":"TaskRun","apiVersion":"tekton.dev/v1beta1","metadata":{"generateName":"build-"}}`)
+	ctx := WithIdempotencyKey(context.Background(), eventID+"/"+triggerName+"/0")
+
+	first, err := Create(ctx, logger.Sugar(), rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+	if err != nil {
+		t.Fatalf("Create() returned error: %s", err)
+	}
+	if first.GetGenerateName() != "" || !strings.HasPrefix(first.GetName(), "build-") || len(first.GetName()) != len("build-")+nameSuffixLength {
+		t.Errorf("Create() created %q with generateName %q, want a name derived from the idempotency key", first.GetName(), first.GetGenerateName())
+	}
+
+	// Creating it again with the same key returns the resource created by the first attempt.
+	second, err := Create(ctx, logger.Sugar(), rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+	if err != nil {
+		t.Fatalf("Create() of the same key returned error: %s", err)
+	}
+	if second.GetName() != first.GetName() || second.GetUID() != first.GetUID() {
+		t.Errorf("Create() of the same key returned %q, want %q", second.GetName(), first.GetName())
+	}
+
+	// The resource of an earlier attempt is found without the provenance labels.
+	unlabelled := WithProvenanceLabels(WithIdempotencyKey(context.Background(), eventID+"/"+triggerName+"/1"), nil)
+	if _, err := Create(unlabelled, logger.Sugar(), rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet); err != nil {
+		t.Fatalf("Create() without provenance labels returned error: %s", err)
+	}
+	if _, err := Create(unlabelled, logger.Sugar(), rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet); err != nil {
+		t.Errorf("Create() of the same key without provenance labels returned error: %s", err)
+	}
+
+	// A resource with the same name that wasn't created with the key is not returned.
+	other := &unstructured.Unstructured{}
+	other.SetAPIVersion("tekton.dev/v1beta1")
+	other.SetKind("TaskRun")
+	other.SetName("build-" + nameSuffix(eventID+"/"+triggerName+"/2"))
+	if _, err := dynamicSet.Resource(schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}).Namespace("bar").Create(context.Background(), other, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	taken := WithIdempotencyKey(context.Background(), eventID+"/"+triggerName+"/2")
+	if _, err := Create(taken, logger.Sugar(), rt, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Create() of a name taken by another resource = %v, want an already exists error", err)
+	}
+
+	if a, b := nameSuffix("key-a"), nameSuffix("key-b"); a == b || a != nameSuffix("key-a") {
+		t.Errorf("nameSuffix() = %q and %q, want different stable suffixes for different keys", a, b)
+	}
+}
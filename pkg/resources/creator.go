@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	discoveryclient "k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// Creator creates the resource defined in a rendered TriggerResourceTemplate and returns the created resource.
+type Creator interface {
+	Create(ctx context.Context, logger *zap.SugaredLogger, rt json.RawMessage, triggerName, eventID, elName, elNamespace string, c discoveryclient.ServerResourcesInterface, dc dynamic.Interface) (*unstructured.Unstructured, error)
+}
+
+// CreatorFunc adapts an ordinary function to a Creator.
+type CreatorFunc func(ctx context.Context, logger *zap.SugaredLogger, rt json.RawMessage, triggerName, eventID, elName, elNamespace string, c discoveryclient.ServerResourcesInterface, dc dynamic.Interface) (*unstructured.Unstructured, error)
+
+// Create calls f.
+func (f CreatorFunc) Create(ctx context.Context, logger *zap.SugaredLogger, rt json.RawMessage, triggerName, eventID, elName, elNamespace string, c discoveryclient.ServerResourcesInterface, dc dynamic.Interface) (*unstructured.Unstructured, error) {
+	return f(ctx, logger, rt, triggerName, eventID, elName, elNamespace, c, dc)
+}
+
+// DefaultCreator creates resources in the cluster using Create.
+var DefaultCreator Creator = CreatorFunc(Create)
+
+// FakeCreator is a Creator that records the resources that would be created instead of creating them.
+// It is safe for concurrent use.
+type FakeCreator struct {
+	// Err, if set, is returned by every call to Create.
+	Err error
+
+	mu      sync.Mutex
+	created []*unstructured.Unstructured
+}
+
+var _ Creator = (*FakeCreator)(nil)
+
+// Create records and returns the resource with the labels and namespace it would be created with. The clients are ignored,
+// but the resource kind policy selected by WithKindPolicy is enforced.
+func (f *FakeCreator) Create(ctx context.Context, _ *zap.SugaredLogger, rt json.RawMessage, triggerName, eventID, elName, elNamespace string, _ discoveryclient.ServerResourcesInterface, _ dynamic.Interface) (*unstructured.Unstructured, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	data, _, err := prepare(ctx, rt, triggerName, eventID, elName)
+	if err != nil {
+		return nil, err
+	}
+	if data.GetNamespace() == "" {
+		data.SetNamespace(elNamespace)
+	}
+	name := data.GetName()
+	if name == "" {
+		name = data.GetGenerateName()
+	}
+	if err := checkKind(ctx, data.GroupVersionKind(), name); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.created = append(f.created, data)
+	return data.DeepCopy(), nil
+}
+
+// Created returns the resources recorded so far, in the order Create was called.
+func (f *FakeCreator) Created() []*unstructured.Unstructured {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*unstructured.Unstructured{}, f.created...)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap/zaptest"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestFakeCreator(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	f := &FakeCreator{}
+
+	rts := []json.RawMessage{
+		json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"generateName":"run-"}}`),
+		json.RawMessage(`{"kind":"TaskRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"task","namespace":"other","labels":{"foo":"bar"}}}`),
+	}
+	for _, rt := range rts {
+		if _, err := f.Create(context.Background(), logger, rt, triggerName, eventID, "el", "el-ns", nil, nil); err != nil {
+			t.Fatalf("FakeCreator.Create() returned error: %v", err)
+		}
+	}
+
+	want := []*unstructured.Unstructured{{
+		Object: map[string]interface{}{
+			"kind":       "PipelineRun",
+			"apiVersion": "tekton.dev/v1beta1",
+			"metadata": map[string]interface{}{
+				"generateName": "run-",
+				"namespace":    "el-ns",
+				"labels": map[string]interface{}{
+					resourceLabel: "el",
+					triggerLabel:  triggerName,
+					eventIDLabel:  eventID,
+				},
+			},
+		},
+	}, {
+		Object: map[string]interface{}{
+			"kind":       "TaskRun",
+			"apiVersion": "tekton.dev/v1beta1",
+			"metadata": map[string]interface{}{
+				"name":      "task",
+				"namespace": "other",
+				"labels": map[string]interface{}{
+					"foo":         "bar",
+					resourceLabel: "el",
+					triggerLabel:  triggerName,
+					eventIDLabel:  eventID,
+				},
+			},
+		},
+	}}
+	if diff := cmp.Diff(want, f.Created()); diff != "" {
+		t.Errorf("FakeCreator.Created(): -want +got: %s", diff)
+	}
+}
+
+func TestFakeCreator_Error(t *testing.T) {
+	wantErr := errors.New("boom")
+	f := &FakeCreator{Err: wantErr}
+	rt := json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"run"}}`)
+	if _, err := f.Create(context.Background(), zaptest.NewLogger(t).Sugar(), rt, triggerName, eventID, "el", "el-ns", nil, nil); !errors.Is(err, wantErr) {
+		t.Errorf("FakeCreator.Create() = %v, want %v", err, wantErr)
+	}
+	if got := f.Created(); len(got) != 0 {
+		t.Errorf("expected no resources to be recorded, got: %v", got)
+	}
+}
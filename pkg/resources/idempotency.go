@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// IdempotencyKeyAnnotation is set by Create to a hash of the idempotency key selected by WithIdempotencyKey,
+// if any, so that an existing resource can be told apart from the resource of an earlier attempt. Unlike the
+// provenance labels, which WithProvenanceLabels may leave out, it is always set.
+const IdempotencyKeyAnnotation = triggers.GroupName + "/idempotency-key"
+
+// nameSuffixAlphabet is the alphabet of the random suffixes of the API server, without vowels so that
+// the suffixes don't spell words.
+const nameSuffixAlphabet = "bcdfghjklmnpqrstvwxz2456789"
+
+// nameSuffixLength is the length of the suffixes the API server appends to generateName.
+const nameSuffixLength = 5
+
+// idempotencyKey is the context key for the key set by WithIdempotencyKey.
+type idempotencyKey struct{}
+
+// WithIdempotencyKey returns a context in which creating the same resource template with the same key more
+// than once creates a single resource, e.g. when replaying a create that may have succeeded before the
+// EventListener restarted. The resources whose templates only have a generateName are named with a suffix
+// derived from key instead of a random one, and Create returns the existing resource with that name if it
+// was created with the same key rather than failing.
+//
+// The key should identify a single creation, e.g. the event ID, trigger and template index of the creation.
+// Event IDs taken from requests are chosen by their senders, so a sender reusing the event ID of an event
+// gets the resources created for that event back rather than new ones, like a redelivery of the event
+// would. This only lets senders skip creations, not get the resources of other triggers or templates.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// applyIdempotencyKey names the resource after its generateName and the key selected by ctx, if any,
+// annotates it with the hash of the key, and returns whether ctx selected a key.
+func applyIdempotencyKey(ctx context.Context, us *unstructured.Unstructured) bool {
+	key, ok := ctx.Value(idempotencyKey{}).(string)
+	if !ok || key == "" {
+		return false
+	}
+	if us.GetName() == "" && us.GetGenerateName() != "" {
+		generateName := us.GetGenerateName()
+		if len(generateName) > maxGenerateNameLength {
+			generateName = generateName[:maxGenerateNameLength]
+		}
+		us.SetName(generateName + nameSuffix(key))
+		us.SetGenerateName("")
+	}
+	annotations := us.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[IdempotencyKeyAnnotation] = keyHash(key)
+	us.SetAnnotations(annotations)
+	return true
+}
+
+// nameSuffix returns a suffix like those of the API server derived from key.
+func nameSuffix(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	n := binary.BigEndian.Uint64(sum[:8])
+	suffix := make([]byte, nameSuffixLength)
+	for i := range suffix {
+		suffix[i] = nameSuffixAlphabet[n%uint64(len(nameSuffixAlphabet))]
+		n /= uint64(len(nameSuffixAlphabet))
+	}
+	return string(suffix)
+}
+
+// keyHash returns the value of the IdempotencyKeyAnnotation for key.
+func keyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// createdWithSameKey returns whether the existing resource has the IdempotencyKeyAnnotation of the resource
+// to create, i.e. it's the resource of an earlier attempt to create it.
+func createdWithSameKey(existing, data *unstructured.Unstructured) bool {
+	want, ok := data.GetAnnotations()[IdempotencyKeyAnnotation]
+	return ok && existing.GetAnnotations()[IdempotencyKeyAnnotation] == want
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// labelHashLength is the number of hex characters of the hash appended to the label values sanitized with
+// triggers.LabelSanitizationHash.
+const labelHashLength = 10
+
+// SanitizeLabelValue returns v unchanged if it is a valid label value, or else sanitizes it with the given
+// strategy, triggers.LabelSanitizationHash if empty:
+//
+//   - triggers.LabelSanitizationTruncate replaces the characters other than alphanumerics, '-', '_' and '.'
+//     with '-', truncates the value to 63 characters and strips its leading and trailing non alphanumeric
+//     characters. Distinct values may have the same sanitized value.
+//   - triggers.LabelSanitizationHash sanitizes the value the same way, but truncates it to make room for a '-'
+//     and the first 10 hex characters of the SHA-256 of the original value, so that distinct values keep
+//     distinct sanitized values.
+//   - triggers.LabelSanitizationReject returns an error.
+//
+// The sanitized value only depends on v and the strategy.
+func SanitizeLabelValue(v, strategy string) (string, error) {
+	msgs := validation.IsValidLabelValue(v)
+	if len(msgs) == 0 {
+		return v, nil
+	}
+	switch strategy {
+	case triggers.LabelSanitizationTruncate:
+		return truncateLabelValue(replaceLabelCharacters(v), validation.LabelValueMaxLength), nil
+	case triggers.LabelSanitizationHash, "":
+		sum := sha256.Sum256([]byte(v))
+		hash := hex.EncodeToString(sum[:])[:labelHashLength]
+		prefix := truncateLabelValue(replaceLabelCharacters(v), validation.LabelValueMaxLength-labelHashLength-1)
+		if prefix == "" {
+			return hash, nil
+		}
+		return prefix + "-" + hash, nil
+	case triggers.LabelSanitizationReject:
+		return "", fmt.Errorf("%q is not a valid label value: %s", v, strings.Join(msgs, ", "))
+	}
+	return "", fmt.Errorf("unknown label sanitization strategy %q", strategy)
+}
+
+func replaceLabelCharacters(v string) string {
+	return strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+			return c
+		}
+		return '-'
+	}, v)
+}
+
+// truncateLabelValue truncates v to at most limit characters without leading or trailing non alphanumeric
+// characters.
+func truncateLabelValue(v string, limit int) string {
+	notAlphanumeric := func(c rune) bool {
+		return !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9')
+	}
+	v = strings.TrimLeftFunc(v, notAlphanumeric)
+	if len(v) > limit {
+		v = v[:limit]
+	}
+	return strings.TrimRightFunc(v, notAlphanumeric)
+}
+
+// labelSanitizationKey is the context key for the strategy set by WithLabelSanitization.
+type labelSanitizationKey struct{}
+
+// WithLabelSanitization returns a context in which Create sanitizes the label values of the resources it
+// creates that are not valid, e.g. the values of params or a long event ID, with the given strategy. By
+// default, they are sanitized with triggers.LabelSanitizationHash.
+func WithLabelSanitization(ctx context.Context, strategy string) context.Context {
+	return context.WithValue(ctx, labelSanitizationKey{}, strategy)
+}
+
+// sanitizeLabels sanitizes the label values of the resource with the strategy selected by ctx.
+func sanitizeLabels(ctx context.Context, us *unstructured.Unstructured) error {
+	labels := us.GetLabels()
+	if len(labels) == 0 {
+		return nil
+	}
+	strategy, _ := ctx.Value(labelSanitizationKey{}).(string)
+	for k, v := range labels {
+		sanitized, err := SanitizeLabelValue(v, strategy)
+		if err != nil {
+			return fmt.Errorf("invalid label %s: %w", k, err)
+		}
+		labels[k] = sanitized
+	}
+	us.SetLabels(labels)
+	return nil
+}
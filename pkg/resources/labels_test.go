@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestSanitizeLabelValue(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		value        string
+		wantTruncate string
+		wantHash     string
+	}{{
+		name:         "valid",
+		value:        "72d4a5a4-1a7e-11ed-861d-0242ac120002",
+		wantTruncate: "72d4a5a4-1a7e-11ed-861d-0242ac120002",
+		wantHash:     "72d4a5a4-1a7e-11ed-861d-0242ac120002",
+	}, {
+		name:         "invalid characters",
+		value:        "delivery/42:retry",
+		wantTruncate: "delivery-42-retry",
+		wantHash:     "delivery-42-retry-09403c7c64",
+	}, {
+		name:         "leading and trailing separators",
+		value:        "{42}",
+		wantTruncate: "42",
+		wantHash:     "42-0c6a880d59",
+	}, {
+		name:         "too long",
+		value:        strings.Repeat("a", 70),
+		wantTruncate: strings.Repeat("a", 63),
+		wantHash:     strings.Repeat("a", 52) + "-6bd5e50348",
+	}, {
+		name:         "truncated before a separator",
+		value:        strings.Repeat("x", 60) + "/y",
+		wantTruncate: strings.Repeat("x", 60) + "-y",
+		wantHash:     strings.Repeat("x", 52) + "-b5b4df49ed",
+	}, {
+		name:         "non ascii",
+		value:        "événement",
+		wantTruncate: "v-nement",
+		wantHash:     "v-nement-f5c40e7d9e",
+	}, {
+		name:     "nothing left",
+		value:    "--",
+		wantHash: "d8156bae0c",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			for strategy, want := range map[string]string{
+				triggers.LabelSanitizationTruncate: tc.wantTruncate,
+				triggers.LabelSanitizationHash:     tc.wantHash,
+				"":                                 tc.wantHash,
+			} {
+				got, err := SanitizeLabelValue(tc.value, strategy)
+				if err != nil {
+					t.Fatalf("SanitizeLabelValue(%q, %q) returned error: %v", tc.value, strategy, err)
+				}
+				if got != want {
+					t.Errorf("SanitizeLabelValue(%q, %q) = %q, want %q", tc.value, strategy, got, want)
+				}
+				if errs := validation.IsValidLabelValue(got); len(errs) != 0 {
+					t.Errorf("SanitizeLabelValue(%q, %q) = %q is not a valid label value: %v", tc.value, strategy, got, errs)
+				}
+			}
+
+			got, err := SanitizeLabelValue(tc.value, triggers.LabelSanitizationReject)
+			if valid := tc.value == tc.wantHash; (err == nil) != valid || valid && got != tc.value {
+				t.Errorf("SanitizeLabelValue(%q, reject) = %q, %v", tc.value, got, err)
+			}
+		})
+	}
+}
+
+func TestSanitizeLabelValue_UnknownStrategy(t *testing.T) {
+	if _, err := SanitizeLabelValue("a/b", "drop"); err == nil {
+		t.Error("SanitizeLabelValue() returned no error for an unknown strategy")
+	}
+}
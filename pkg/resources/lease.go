@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	"go.uber.org/zap"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// CreateLeaseLabel is the label set on the Leases held while creating the resources of the templates with a
+// CreateLeaseAnnotation.
+const CreateLeaseLabel = triggers.GroupName + "/create-lease"
+
+// leasesGVR is the resource of Leases.
+var leasesGVR = coordinationv1.SchemeGroupVersion.WithResource("leases")
+
+var (
+	// leasePollInterval is how often a Lease held by another holder is checked for release or expiry.
+	leasePollInterval = 500 * time.Millisecond
+	// leaseNow returns the current time, which is compared to the expiry of Leases.
+	leaseNow = time.Now
+)
+
+// createLease is a Lease held while creating a resource.
+type createLease struct {
+	name      string
+	namespace string
+	uid       types.UID
+	rv        string
+}
+
+// createLeaseName returns the name of the Lease of the resource of the given name, in namespace, of the
+// group and resource of gvr. The name is a hash of the key, which can be longer than a name.
+func createLeaseName(gvr schema.GroupVersionResource, namespace, name string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s/%s", gvr.Group, gvr.Resource, namespace, name)))
+	return "triggers-create-" + hex.EncodeToString(sum[:])
+}
+
+// acquireCreateLease acquires the Lease of the given name in namespace for duration, waiting for it to be
+// released or to expire while another holder has it, until ctx is done. An expired Lease is taken over.
+func acquireCreateLease(ctx context.Context, logger *zap.SugaredLogger, name, namespace, holder string, duration time.Duration, dc dynamic.Interface) (*createLease, error) {
+	leases := dc.Resource(leasesGVR).Namespace(namespace)
+	seconds := int32(math.Ceil(duration.Seconds()))
+	for {
+		acquired := metav1.NewMicroTime(leaseNow())
+		spec := coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &seconds,
+			AcquireTime:          &acquired,
+			RenewTime:            &acquired,
+		}
+		l, err := toUnstructuredLease(&coordinationv1.Lease{
+			TypeMeta: metav1.TypeMeta{APIVersion: coordinationv1.SchemeGroupVersion.String(), Kind: "Lease"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{CreateLeaseLabel: "true"},
+			},
+			Spec: spec,
+		})
+		if err != nil {
+			return nil, err
+		}
+		created, err := leases.Create(ctx, l, metav1.CreateOptions{})
+		if err == nil {
+			return &createLease{name: name, namespace: namespace, uid: created.GetUID(), rv: created.GetResourceVersion()}, nil
+		}
+		if !kerrors.IsAlreadyExists(err) {
+			return nil, leaseError("create", name, err)
+		}
+
+		existing, err := leases.Get(ctx, name, metav1.GetOptions{})
+		switch {
+		case kerrors.IsNotFound(err):
+			// Released since it was created, try again.
+			continue
+		case err != nil:
+			return nil, leaseError("get", name, err)
+		}
+		lease := &coordinationv1.Lease{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(existing.Object, lease); err != nil {
+			return nil, fmt.Errorf("couldn't read lease %s: %w", name, err)
+		}
+		if leaseExpired(lease, acquired.Time) {
+			// The update is made at the resourceVersion that was read, so only one of several concurrent
+			// holders takes over the expired Lease.
+			lease.Spec = spec
+			l, err := toUnstructuredLease(lease)
+			if err != nil {
+				return nil, err
+			}
+			updated, err := leases.Update(ctx, l, metav1.UpdateOptions{})
+			if err == nil {
+				logger.Infof("Took over expired lease %s", name)
+				return &createLease{name: name, namespace: namespace, uid: updated.GetUID(), rv: updated.GetResourceVersion()}, nil
+			}
+			if !kerrors.IsConflict(err) && !kerrors.IsNotFound(err) {
+				return nil, leaseError("update", name, err)
+			}
+			continue
+		}
+
+		holderIdentity := ""
+		if lease.Spec.HolderIdentity != nil {
+			holderIdentity = *lease.Spec.HolderIdentity
+		}
+		logger.Debugf("Lease %s is held by %s, waiting for it", name, holderIdentity)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("couldn't acquire lease %s held by %s: %w", name, holderIdentity, ctx.Err())
+		case <-time.After(leasePollInterval):
+		}
+	}
+}
+
+// release deletes the Lease unless it has changed since it was acquired, i.e. unless it expired and was taken
+// over. A Lease that can't be deleted is left to expire.
+func (l *createLease) release(ctx context.Context, logger *zap.SugaredLogger, dc dynamic.Interface) {
+	opts := metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &l.uid, ResourceVersion: &l.rv}}
+	err := dc.Resource(leasesGVR).Namespace(l.namespace).Delete(ctx, l.name, opts)
+	if err != nil && !kerrors.IsNotFound(err) && !kerrors.IsConflict(err) {
+		logger.Warnf("Couldn't release lease %s, leaving it to expire: %v", l.name, err)
+	}
+}
+
+// leaseHolder returns the identity of the holder of the Leases acquired for the event: the host name of the
+// replica, which is the name of its pod, and the event ID.
+func leaseHolder(eventID string) string {
+	host, err := os.Hostname()
+	if err != nil {
+		return eventID
+	}
+	return host + "/" + eventID
+}
+
+func leaseExpired(lease *coordinationv1.Lease, at time.Time) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	return !at.Before(expiry)
+}
+
+func toUnstructuredLease(lease *coordinationv1.Lease) (*unstructured.Unstructured, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(lease)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't convert lease %s: %w", lease.Name, err)
+	}
+	return &unstructured.Unstructured{Object: obj}, nil
+}
+
+func leaseError(verb, name string, err error) error {
+	if kerrors.IsUnauthorized(err) || kerrors.IsForbidden(err) {
+		return err
+	}
+	return fmt.Errorf("couldn't %s lease %s: %w", verb, name, err)
+}
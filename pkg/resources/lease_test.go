@@ -0,0 +1,185 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	dynamicclientset "github.com/tektoncd/triggers/pkg/client/dynamic/clientset"
+	"github.com/tektoncd/triggers/pkg/client/dynamic/clientset/tekton"
+	"github.com/tektoncd/triggers/test"
+	"go.uber.org/zap/zaptest"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+var pipelineResourcesGVR = schema.GroupVersionResource{Group: "tekton.dev", Version: "v1alpha1", Resource: "pipelineresources"}
+
+const leasedTemplate = `{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"pr-1234","annotations":{"triggers.tekton.dev/create-lease":"30s"}},"spec":{"type":"git"}}`
+
+// heldLease returns a Lease of the leased template held by another replica since acquired.
+func heldLease(t *testing.T, acquired time.Time) *coordinationv1.Lease {
+	t.Helper()
+	holder := "other-replica/67890"
+	seconds := int32(30)
+	at := metav1.NewMicroTime(acquired)
+	return &coordinationv1.Lease{
+		TypeMeta: metav1.TypeMeta{APIVersion: "coordination.k8s.io/v1", Kind: "Lease"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      createLeaseName(pipelineResourcesGVR, "bar", "pr-1234"),
+			Namespace: "bar",
+		},
+		Spec: coordinationv1.LeaseSpec{HolderIdentity: &holder, LeaseDurationSeconds: &seconds, AcquireTime: &at, RenewTime: &at},
+	}
+}
+
+func setLeasePollInterval(t *testing.T, d time.Duration) {
+	t.Helper()
+	interval := leasePollInterval
+	leasePollInterval = d
+	t.Cleanup(func() { leasePollInterval = interval })
+}
+
+func TestCreateResource_Lease(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	logger := zaptest.NewLogger(t).Sugar()
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+	dynamicSet := dynamicclientset.New(tekton.WithClient(dynamicClient))
+
+	created, err := Create(context.Background(), logger, json.RawMessage(leasedTemplate), triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+	if err != nil {
+		t.Fatalf("Create() returned error: %s", err)
+	}
+	if len(created.GetAnnotations()) != 0 {
+		t.Errorf("Create() created %v, want the resource without the annotation", created)
+	}
+	leaseName := createLeaseName(pipelineResourcesGVR, "bar", "pr-1234")
+	if _, err := dynamicSet.Resource(leasesGVR).Namespace("bar").Get(context.Background(), leaseName, metav1.GetOptions{}); !kerrors.IsNotFound(err) {
+		t.Errorf("Get() lease returned error %v, want the lease released", err)
+	}
+
+	// The resource of another event with the same key is returned rather than created again.
+	existing, err := Create(context.Background(), logger, json.RawMessage(leasedTemplate), triggerName, "67890", "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+	if err != nil {
+		t.Fatalf("Create() returned error for an existing resource: %s", err)
+	}
+	if existing.GetLabels()[eventIDLabel] != eventID {
+		t.Errorf("Create() returned %v, want the resource created for the first event", existing)
+	}
+}
+
+func TestCreateResource_LeaseHeld(t *testing.T) {
+	setLeasePollInterval(t, time.Millisecond)
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	logger := zaptest.NewLogger(t).Sugar()
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+	dynamicSet := dynamicclientset.New(tekton.WithClient(dynamicClient))
+
+	lease, err := toUnstructuredLease(heldLease(t, time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dynamicSet.Resource(leasesGVR).Namespace("bar").Create(context.Background(), lease, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Create() lease returned error: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = Create(ctx, logger, json.RawMessage(leasedTemplate), triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+	if err == nil || !strings.Contains(err.Error(), "held by other-replica/67890") {
+		t.Fatalf("Create() returned error %v, want an error waiting for the held lease", err)
+	}
+	if _, err := dynamicSet.Resource(pipelineResourcesGVR).Namespace("bar").Get(context.Background(), "pr-1234", metav1.GetOptions{}); err == nil {
+		t.Errorf("Create() created the resource while the lease was held by another replica")
+	}
+
+	// The other replica creates the resource and releases the lease while the event waits for it.
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		other, _, _ := prepare(context.Background(), json.RawMessage(leasedTemplate), triggerName, "67890", "foo-el")
+		_, _ = dynamicSet.Resource(pipelineResourcesGVR).Namespace("bar").Create(context.Background(), other, metav1.CreateOptions{})
+		_ = dynamicSet.Resource(leasesGVR).Namespace("bar").Delete(context.Background(), lease.GetName(), metav1.DeleteOptions{})
+	}()
+	got, err := Create(context.Background(), logger, json.RawMessage(leasedTemplate), triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+	if err != nil {
+		t.Fatalf("Create() returned error after the lease was released: %s", err)
+	}
+	if got.GetLabels()[eventIDLabel] != "67890" {
+		t.Errorf("Create() returned %v, want the resource created by the other replica", got)
+	}
+}
+
+func TestCreateResource_LeaseExpired(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	logger := zaptest.NewLogger(t).Sugar()
+	lease, err := toUnstructuredLease(heldLease(t, time.Now().Add(-time.Minute)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dynamicSet := dynamicclientset.New(tekton.WithClient(fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), lease)))
+
+	created, err := Create(context.Background(), logger, json.RawMessage(leasedTemplate), triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+	if err != nil {
+		t.Fatalf("Create() returned error for an expired lease: %s", err)
+	}
+	if created.GetLabels()[eventIDLabel] != eventID {
+		t.Errorf("Create() returned %v, want the resource created for the event", created)
+	}
+}
+
+func TestCreateResource_Lease_Error(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	logger := zaptest.NewLogger(t)
+	for _, tc := range []struct {
+		name    string
+		json    json.RawMessage
+		wantErr string
+	}{{
+		name:    "invalid duration",
+		json:    json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"pr-1234","annotations":{"triggers.tekton.dev/create-lease":"forever"}}}`),
+		wantErr: `invalid triggers.tekton.dev/create-lease annotation "forever"`,
+	}, {
+		name:    "negative duration",
+		json:    json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"name":"pr-1234","annotations":{"triggers.tekton.dev/create-lease":"-1s"}}}`),
+		wantErr: `invalid triggers.tekton.dev/create-lease annotation "-1s"`,
+	}, {
+		name:    "generated name",
+		json:    json.RawMessage(`{"kind":"PipelineResource","apiVersion":"tekton.dev/v1alpha1","metadata":{"generateName":"pr-","annotations":{"triggers.tekton.dev/create-lease":"30s"}}}`),
+		wantErr: "triggers.tekton.dev/create-lease annotation requires a name",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			dynamicSet := dynamicclientset.New(tekton.WithClient(fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())))
+			_, err := Create(context.Background(), logger.Sugar(), tc.json, triggerName, eventID, "foo-el", "bar", kubeClient.Discovery(), dynamicSet)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Create() returned error %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
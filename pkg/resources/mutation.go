@@ -0,0 +1,248 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// defaultMutationTimeout is the timeout of the calls to a mutation hook if no Timeout is configured.
+	defaultMutationTimeout = 10 * time.Second
+	// maxMutationResponseSize bounds the size of the resources returned by an HTTPMutationHook.
+	maxMutationResponseSize = 1024 * 1024
+)
+
+// ErrMutationHook is returned when the mutation hook of a resource fails and its failures aren't ignored.
+var ErrMutationHook = errors.New("mutation hook failed")
+
+// MutationHook patches the resources Triggers creates just before they are created, e.g. to inject
+// org-wide defaults such as a pod template or a security context without editing every template.
+//
+// The hook receives a copy of the resource as it would be created, with the metadata of the trigger
+// and the provenance labels, and returns the resource to create instead. It may be called more than once
+// for a resource, like an admission webhook, so it should be idempotent.
+type MutationHook interface {
+	Mutate(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error)
+}
+
+// MutationHookFunc adapts an ordinary function to a MutationHook, e.g. for an in-process plugin.
+type MutationHookFunc func(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error)
+
+// Mutate calls f.
+func (f MutationHookFunc) Mutate(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return f(ctx, obj)
+}
+
+// HTTPMutationHook is a MutationHook that POSTs the resource as JSON to an endpoint, which responds with
+// the resource to create and a 2xx status code.
+type HTTPMutationHook struct {
+	// URL is the http or https URL of the endpoint.
+	URL string
+	// Client sends the requests. Defaults to http.DefaultClient if nil.
+	Client *http.Client
+}
+
+var _ MutationHook = (*HTTPMutationHook)(nil)
+
+// Mutate sends obj to the endpoint and returns the resource it responds with.
+func (h *HTTPMutationHook) Mutate(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	body, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// Drain the body so that the connection can be reused.
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		return nil, fmt.Errorf("mutation hook %s responded with status code %d", h.URL, resp.StatusCode)
+	}
+	payload, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxMutationResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading the response of mutation hook %s: %w", h.URL, err)
+	}
+	if len(payload) > maxMutationResponseSize {
+		return nil, fmt.Errorf("mutation hook %s responded with more than %d bytes", h.URL, maxMutationResponseSize)
+	}
+	mutated := &unstructured.Unstructured{}
+	if err := mutated.UnmarshalJSON(payload); err != nil {
+		return nil, fmt.Errorf("mutation hook %s responded with an invalid resource: %w", h.URL, err)
+	}
+	return mutated, nil
+}
+
+// Mutation is the mutation hook that Create calls for the resources of a context, see WithMutation.
+type Mutation struct {
+	// Hook patches the resources.
+	Hook MutationHook
+	// Timeout bounds each call to the hook. Defaults to 10 seconds.
+	Timeout time.Duration
+	// FailOpen, if true, creates the resources unmodified when the hook fails instead of failing their creation.
+	FailOpen bool
+	// Logger logs the failures of the hook that are ignored. Defaults to a no-op logger.
+	Logger *zap.SugaredLogger
+}
+
+type mutationKey struct{}
+
+// WithMutation returns a copy of ctx that makes Create call the mutation hook m on each resource before
+// creating it.
+func WithMutation(ctx context.Context, m *Mutation) context.Context {
+	return context.WithValue(ctx, mutationKey{}, m)
+}
+
+type mutationMemoKey struct{}
+
+// mutationMemo remembers the last resource passed to the mutation hook and its result, so that a
+// resource prepared more than once for one creation, e.g. by a ValidatingCreator and then by the
+// Creator it wraps, is only sent to the hook once.
+type mutationMemo struct {
+	mu            sync.Mutex
+	data, mutated *unstructured.Unstructured
+}
+
+// withMutationMemo returns a copy of ctx in which the results of the mutation hook are remembered,
+// unless they already are.
+func withMutationMemo(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(mutationMemoKey{}).(*mutationMemo); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, mutationMemoKey{}, &mutationMemo{})
+}
+
+// get returns the result of the hook for data, if it is the last resource passed to the hook.
+func (m *mutationMemo) get(data *unstructured.Unstructured) (*unstructured.Unstructured, bool) {
+	if m == nil {
+		return nil, false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.data == nil || !reflect.DeepEqual(m.data.Object, data.Object) {
+		return nil, false
+	}
+	return m.mutated.DeepCopy(), true
+}
+
+func (m *mutationMemo) put(data, mutated *unstructured.Unstructured) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data, m.mutated = data.DeepCopy(), mutated.DeepCopy()
+}
+
+// applyMutation returns the resource data as patched by the mutation hook selected by ctx, if any.
+func applyMutation(ctx context.Context, data *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	m, ok := ctx.Value(mutationKey{}).(*Mutation)
+	if !ok || m == nil || m.Hook == nil {
+		return data, nil
+	}
+	memo, _ := ctx.Value(mutationMemoKey{}).(*mutationMemo)
+	if mutated, ok := memo.get(data); ok {
+		return mutated, nil
+	}
+	mutated, err := m.mutate(ctx, data)
+	if err == nil {
+		memo.put(data, mutated)
+		return mutated, nil
+	}
+	if m.FailOpen {
+		logger := m.Logger
+		if logger == nil {
+			logger = zap.NewNop().Sugar()
+		}
+		logger.Warnf("Creating %s %s unmodified: %v", data.GetKind(), resourceName(data), err)
+		memo.put(data, data)
+		return data, nil
+	}
+	return nil, err
+}
+
+// mutate calls the hook within its timeout, and checks that it kept the identity of the resource and its
+// provenance labels.
+func (m *Mutation) mutate(ctx context.Context, data *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	timeout := m.Timeout
+	if timeout <= 0 {
+		timeout = defaultMutationTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	mutated, err := m.Hook.Mutate(ctx, data.DeepCopy())
+	if err != nil {
+		return nil, fmt.Errorf("%w for %s %s: %v", ErrMutationHook, data.GetKind(), resourceName(data), err)
+	}
+	if mutated == nil {
+		return nil, fmt.Errorf("%w for %s %s: the hook returned no resource", ErrMutationHook, data.GetKind(), resourceName(data))
+	}
+	for _, f := range []struct {
+		name     string
+		was, got string
+	}{
+		{"apiVersion", data.GetAPIVersion(), mutated.GetAPIVersion()},
+		{"kind", data.GetKind(), mutated.GetKind()},
+		{"namespace", data.GetNamespace(), mutated.GetNamespace()},
+		{"name", data.GetName(), mutated.GetName()},
+		{"generateName", data.GetGenerateName(), mutated.GetGenerateName()},
+	} {
+		if f.was != f.got {
+			return nil, fmt.Errorf("%w for %s %s: the hook changed the %s from %q to %q", ErrMutationHook, data.GetKind(), resourceName(data), f.name, f.was, f.got)
+		}
+	}
+	labels := mutated.GetLabels()
+	for k, v := range data.GetLabels() {
+		if strings.HasPrefix(k, triggers.GroupName+"/") && labels[k] != v {
+			return nil, fmt.Errorf("%w for %s %s: the hook changed the %s label", ErrMutationHook, data.GetKind(), resourceName(data), k)
+		}
+	}
+	return mutated, nil
+}
+
+// resourceName returns the name of the resource, or its generateName if it has no name.
+func resourceName(data *unstructured.Unstructured) string {
+	if name := data.GetName(); name != "" {
+		return name
+	}
+	return data.GetGenerateName()
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const mutatedTemplate = `{"apiVersion":"tekton.dev/v1beta1","kind":"PipelineRun","metadata":{"generateName":"run-","annotations":{"triggers.tekton.dev/content-hash":"true"}},"spec":{"pipelineRef":{"name":"build"}}}`
+
+// withNodeSelector injects a default node selector into the pod template of PipelineRuns.
+func withNodeSelector(_ context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if err := unstructured.SetNestedStringMap(obj.Object, map[string]string{"pool": "ci"}, "spec", "podTemplate", "nodeSelector"); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func TestFakeCreator_Mutation(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		hook     MutationHookFunc
+		failOpen bool
+		timeout  time.Duration
+		// wantNodeSelector is whether the created resource has the node selector of withNodeSelector.
+		wantNodeSelector bool
+		wantErr          bool
+	}{{
+		name:             "patched",
+		hook:             withNodeSelector,
+		wantNodeSelector: true,
+	}, {
+		name: "failed, closed",
+		hook: func(context.Context, *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			return nil, errors.New("no defaults for this namespace")
+		},
+		wantErr: true,
+	}, {
+		name: "failed, open",
+		hook: func(context.Context, *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			return nil, errors.New("no defaults for this namespace")
+		},
+		failOpen: true,
+	}, {
+		name: "timed out",
+		hook: func(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		timeout: time.Millisecond,
+		wantErr: true,
+	}, {
+		name: "changed kind",
+		hook: func(_ context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			obj.SetKind("TaskRun")
+			return obj, nil
+		},
+		wantErr: true,
+	}, {
+		name: "removed provenance label",
+		hook: func(_ context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			obj.SetLabels(map[string]string{"team": "ci"})
+			return obj, nil
+		},
+		wantErr: true,
+	}, {
+		name: "returned nothing",
+		hook: func(context.Context, *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			return nil, nil
+		},
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := WithMutation(context.Background(), &Mutation{Hook: tc.hook, Timeout: tc.timeout, FailOpen: tc.failOpen})
+			creator := &FakeCreator{}
+			created, err := creator.Create(ctx, nil, json.RawMessage(mutatedTemplate), triggerName, eventID, "foo-el", "bar", nil, nil)
+			if tc.wantErr {
+				if !errors.Is(err, ErrMutationHook) {
+					t.Fatalf("Create() returned error %v, want %v", err, ErrMutationHook)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create() returned error: %v", err)
+			}
+			_, found, _ := unstructured.NestedStringMap(created.Object, "spec", "podTemplate", "nodeSelector")
+			if found != tc.wantNodeSelector {
+				t.Errorf("Create() created %v, want a node selector: %t", created.Object, tc.wantNodeSelector)
+			}
+			if got := created.GetLabels()[triggers.GroupName+triggers.TriggerLabelKey]; got != triggerName {
+				t.Errorf("Create() set the trigger label to %q, want %q", got, triggerName)
+			}
+			// The content hash covers the patches of the hook.
+			hash, err := ContentHash(created)
+			if err != nil {
+				t.Fatalf("ContentHash() returned error: %v", err)
+			}
+			if got := created.GetAnnotations()[ContentHashAnnotation]; got != hash {
+				t.Errorf("Create() set the %s annotation to %q, want the ContentHash %q of the created resource", ContentHashAnnotation, got, hash)
+			}
+		})
+	}
+}
+
+func TestHTTPMutationHook(t *testing.T) {
+	var received map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("error reading the request: %v", err)
+		}
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Errorf("the request isn't a JSON object: %v", err)
+		}
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		obj := (&unstructured.Unstructured{Object: received}).DeepCopy()
+		mutated, _ := withNodeSelector(r.Context(), obj)
+		b, _ := mutated.MarshalJSON()
+		_, _ = w.Write(b)
+	}))
+	defer ts.Close()
+
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON([]byte(mutatedTemplate)); err != nil {
+		t.Fatal(err)
+	}
+	hook := &HTTPMutationHook{URL: ts.URL + "/mutate"}
+	mutated, err := hook.Mutate(context.Background(), obj)
+	if err != nil {
+		t.Fatalf("Mutate() returned error: %v", err)
+	}
+	if diff := cmp.Diff(obj.Object, received); diff != "" {
+		t.Errorf("the hook received -want +got: %s", diff)
+	}
+	got, _, _ := unstructured.NestedStringMap(mutated.Object, "spec", "podTemplate", "nodeSelector")
+	if diff := cmp.Diff(map[string]string{"pool": "ci"}, got); diff != "" {
+		t.Errorf("Mutate() returned node selector -want +got: %s", diff)
+	}
+
+	hook = &HTTPMutationHook{URL: ts.URL + "/broken"}
+	if _, err := hook.Mutate(context.Background(), obj); err == nil {
+		t.Error("Mutate() with a failing endpoint returned no error")
+	}
+}
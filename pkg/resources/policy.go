@@ -0,0 +1,254 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	celext "github.com/google/cel-go/ext"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	discoveryclient "k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// policyCostLimit bounds the cost of evaluating a validation of a Policy, so that an expression iterating
+// over a large resource can't stall the creation of resources.
+const policyCostLimit = 1000000
+
+// Policy is a set of constraints on the resources an EventListener creates, in the style of a
+// ValidatingAdmissionPolicy: CEL expressions evaluated against the resource as the object variable, which
+// must all return true for the resource to be created.
+type Policy struct {
+	// Name identifies the policy in violations.
+	Name string `json:"name"`
+	// Match selects the resources the policy applies to. A policy without matches applies to all resources.
+	Match []PolicyMatch `json:"match,omitempty"`
+	// Validations are the constraints of the policy.
+	Validations []PolicyValidation `json:"validations"`
+}
+
+// PolicyMatch selects resources by kind, and optionally by apiVersion.
+type PolicyMatch struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind"`
+}
+
+// PolicyValidation is a constraint of a Policy.
+type PolicyValidation struct {
+	// Expression is a CEL expression returning true if the resource, the object variable, satisfies the
+	// constraint, e.g. has(object.spec.timeouts).
+	Expression string `json:"expression"`
+	// Message describes the constraint in violations. Defaults to "failed expression".
+	Message string `json:"message,omitempty"`
+}
+
+// PolicyViolation is a constraint that a resource doesn't satisfy.
+type PolicyViolation struct {
+	// Policy is the name of the policy.
+	Policy string
+	// Expression is the expression of the constraint.
+	Expression string
+	// Message describes the constraint, or why it couldn't be evaluated.
+	Message string
+}
+
+func (v PolicyViolation) String() string {
+	return fmt.Sprintf("policy %s: %s (expression: %s)", v.Policy, v.Message, v.Expression)
+}
+
+// PolicyViolationError is returned when a resource violates policies, and is not created.
+type PolicyViolationError struct {
+	// Kind and Name identify the resource. Name is its generateName if it has no name.
+	Kind string
+	Name string
+	// Violations are the violated constraints, in the order of the policies.
+	Violations []PolicyViolation
+}
+
+func (e *PolicyViolationError) Error() string {
+	violations := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		violations[i] = v.String()
+	}
+	return fmt.Sprintf("%s %s violates %s", e.Kind, e.Name, strings.Join(violations, "; "))
+}
+
+// PolicySet is a set of policies whose expressions are compiled. It is safe for concurrent use.
+type PolicySet struct {
+	policies []compiledPolicy
+}
+
+type compiledPolicy struct {
+	Policy
+	programs []cel.Program
+}
+
+// NewPolicySet compiles the expressions of the policies. It fails if a policy has no name or
+// validations, or if an expression doesn't compile or doesn't return a bool.
+func NewPolicySet(policies []Policy) (*PolicySet, error) {
+	env, err := cel.NewEnv(
+		celext.Strings(),
+		cel.Declarations(decls.NewVar("object", decls.NewMapType(decls.String, decls.Dyn))),
+	)
+	if err != nil {
+		return nil, err
+	}
+	set := &PolicySet{}
+	for _, p := range policies {
+		if p.Name == "" {
+			return nil, fmt.Errorf("policy has no name")
+		}
+		if len(p.Validations) == 0 {
+			return nil, fmt.Errorf("policy %s has no validations", p.Name)
+		}
+		for i, m := range p.Match {
+			if m.Kind == "" {
+				return nil, fmt.Errorf("policy %s match[%d] has no kind", p.Name, i)
+			}
+		}
+		cp := compiledPolicy{Policy: p}
+		for i, v := range p.Validations {
+			ast, issues := env.Compile(v.Expression)
+			if issues != nil && issues.Err() != nil {
+				return nil, fmt.Errorf("policy %s validations[%d] expression %q failed to compile: %w", p.Name, i, v.Expression, issues.Err())
+			}
+			if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+				return nil, fmt.Errorf("policy %s validations[%d] expression %q returns %s, expected bool", p.Name, i, v.Expression, ast.OutputType())
+			}
+			prg, err := env.Program(ast, cel.CostLimit(policyCostLimit))
+			if err != nil {
+				return nil, fmt.Errorf("policy %s validations[%d] expression %q: %w", p.Name, i, v.Expression, err)
+			}
+			cp.programs = append(cp.programs, prg)
+		}
+		set.policies = append(set.policies, cp)
+	}
+	return set, nil
+}
+
+// ParsePolicies parses the policies of a ConfigMap. Each key of its data is a policy in YAML or JSON,
+// whose name defaults to the name of the ConfigMap and the key, e.g. resource-policies/pipelinerun-timeout.
+// The policies are returned in the order of the keys.
+func ParsePolicies(cm *corev1.ConfigMap) ([]Policy, error) {
+	keys := make([]string, 0, len(cm.Data))
+	for k := range cm.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	policies := make([]Policy, 0, len(keys))
+	for _, k := range keys {
+		var p Policy
+		if err := yaml.UnmarshalStrict([]byte(cm.Data[k]), &p); err != nil {
+			return nil, fmt.Errorf("invalid policy %s in ConfigMap %s: %w", k, cm.Name, err)
+		}
+		if p.Name == "" {
+			p.Name = cm.Name + "/" + k
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+// Check returns the constraints of the policies matching obj that it violates. Expressions that fail to
+// evaluate, e.g. because of a missing field, or that don't return a bool are violations too.
+func (s *PolicySet) Check(obj *unstructured.Unstructured) []PolicyViolation {
+	var violations []PolicyViolation
+	activation := map[string]interface{}{"object": obj.Object}
+	for _, p := range s.policies {
+		if !p.matches(obj) {
+			continue
+		}
+		for i, prg := range p.programs {
+			v := p.Validations[i]
+			message := v.Message
+			if message == "" {
+				message = "failed expression"
+			}
+			out, _, err := prg.Eval(activation)
+			switch {
+			case err != nil:
+				message = fmt.Sprintf("failed to evaluate: %v", err)
+			case out.Value() == true:
+				continue
+			case out.Value() != false:
+				message = fmt.Sprintf("returned %v, expected a bool", out.Value())
+			}
+			violations = append(violations, PolicyViolation{Policy: p.Name, Expression: v.Expression, Message: message})
+		}
+	}
+	return violations
+}
+
+func (p compiledPolicy) matches(obj *unstructured.Unstructured) bool {
+	if len(p.Match) == 0 {
+		return true
+	}
+	for _, m := range p.Match {
+		if m.Kind == obj.GetKind() && (m.APIVersion == "" || m.APIVersion == obj.GetAPIVersion()) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatingCreator is a Creator that checks the resources against Policies before creating them, so
+// that the resources violating them fail without reaching the API server.
+type ValidatingCreator struct {
+	// Creator creates the resources. Defaults to DefaultCreator if nil.
+	Creator Creator
+	// Policies are the policies the resources must satisfy.
+	Policies *PolicySet
+}
+
+var _ Creator = (*ValidatingCreator)(nil)
+
+// Create checks the resource against the policies and creates it if it satisfies them, or returns a
+// *PolicyViolationError.
+func (v *ValidatingCreator) Create(ctx context.Context, logger *zap.SugaredLogger, rt json.RawMessage, triggerName, eventID, elName, elNamespace string, c discoveryclient.ServerResourcesInterface, dc dynamic.Interface) (*unstructured.Unstructured, error) {
+	// The resource is checked as it would be created, with the labels of the trigger and its namespace.
+	// The mutation hook is only called once: the Creator gets the resource it mutated for the check.
+	ctx = withMutationMemo(ctx)
+	data, _, err := prepare(ctx, rt, triggerName, eventID, elName)
+	if err != nil {
+		return nil, err
+	}
+	if data.GetNamespace() == "" {
+		data.SetNamespace(elNamespace)
+	}
+	if violations := v.Policies.Check(data); len(violations) > 0 {
+		name := data.GetName()
+		if name == "" {
+			name = data.GetGenerateName()
+		}
+		return nil, &PolicyViolationError{Kind: data.GetKind(), Name: name, Violations: violations}
+	}
+
+	creator := v.Creator
+	if creator == nil {
+		creator = DefaultCreator
+	}
+	return creator.Create(ctx, logger, rt, triggerName, eventID, elName, elNamespace, c, dc)
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap/zaptest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var timeoutPolicy = Policy{
+	Name:  "pipelinerun-timeout",
+	Match: []PolicyMatch{{APIVersion: "tekton.dev/v1beta1", Kind: "PipelineRun"}},
+	Validations: []PolicyValidation{{
+		Expression: "has(object.spec.timeouts)",
+		Message:    "PipelineRuns must set a timeout",
+	}},
+}
+
+func unstructuredFromJSON(t *testing.T, s string) *unstructured.Unstructured {
+	t.Helper()
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	return obj
+}
+
+func TestNewPolicySet_Error(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		policy  Policy
+		wantErr string
+	}{{
+		name:    "no name",
+		policy:  Policy{Validations: []PolicyValidation{{Expression: "true"}}},
+		wantErr: "policy has no name",
+	}, {
+		name:    "no validations",
+		policy:  Policy{Name: "empty"},
+		wantErr: "policy empty has no validations",
+	}, {
+		name:    "match without kind",
+		policy:  Policy{Name: "p", Match: []PolicyMatch{{APIVersion: "v1"}}, Validations: []PolicyValidation{{Expression: "true"}}},
+		wantErr: "policy p match[0] has no kind",
+	}, {
+		name:    "invalid expression",
+		policy:  Policy{Name: "p", Validations: []PolicyValidation{{Expression: "has(object.spec"}}},
+		wantErr: "failed to compile",
+	}, {
+		name:    "non bool expression",
+		policy:  Policy{Name: "p", Validations: []PolicyValidation{{Expression: "'timeout'"}}},
+		wantErr: "expected bool",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewPolicySet([]Policy{tc.policy})
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("NewPolicySet() returned error %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestParsePolicies(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "resource-policies"},
+		Data: map[string]string{
+			"pipelinerun-timeout": `
+match:
+- apiVersion: tekton.dev/v1beta1
+  kind: PipelineRun
+validations:
+- expression: has(object.spec.timeouts)
+  message: PipelineRuns must set a timeout
+`,
+			"named": `{"name": "service-account", "validations": [{"expression": "has(object.spec.serviceAccountName)"}]}`,
+		},
+	}
+	got, err := ParsePolicies(cm)
+	if err != nil {
+		t.Fatalf("ParsePolicies() returned error: %v", err)
+	}
+	want := []Policy{{
+		Name:        "service-account",
+		Validations: []PolicyValidation{{Expression: "has(object.spec.serviceAccountName)"}},
+	}, {
+		Name:        "resource-policies/pipelinerun-timeout",
+		Match:       timeoutPolicy.Match,
+		Validations: timeoutPolicy.Validations,
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParsePolicies() -want +got: %s", diff)
+	}
+
+	cm.Data = map[string]string{"typo": "validation:\n- expression: 'true'\n"}
+	if _, err := ParsePolicies(cm); err == nil || !strings.Contains(err.Error(), "invalid policy typo in ConfigMap resource-policies") {
+		t.Errorf("ParsePolicies() returned error %v for an unknown field", err)
+	}
+}
+
+func TestPolicySet_Check(t *testing.T) {
+	set, err := NewPolicySet([]Policy{timeoutPolicy, {
+		Name: "labels",
+		Validations: []PolicyValidation{{
+			Expression: "object.metadata.labels.team != ''",
+		}, {
+			Expression: "object.enabled",
+		}},
+	}})
+	if err != nil {
+		t.Fatalf("NewPolicySet() returned error: %v", err)
+	}
+	for _, tc := range []struct {
+		name string
+		obj  string
+		want []PolicyViolation
+	}{{
+		name: "satisfies the policies",
+		obj:  `{"apiVersion": "tekton.dev/v1beta1", "kind": "PipelineRun", "enabled": true, "metadata": {"labels": {"team": "ci"}}, "spec": {"timeouts": {"pipeline": "1h"}}}`,
+	}, {
+		name: "violates the matching policy",
+		obj:  `{"apiVersion": "tekton.dev/v1beta1", "kind": "PipelineRun", "enabled": true, "metadata": {"labels": {"team": "ci"}}, "spec": {}}`,
+		want: []PolicyViolation{{Policy: "pipelinerun-timeout", Expression: "has(object.spec.timeouts)", Message: "PipelineRuns must set a timeout"}},
+	}, {
+		name: "policies not matching",
+		obj:  `{"apiVersion": "tekton.dev/v1alpha1", "kind": "PipelineRun", "enabled": true, "metadata": {"labels": {"team": "ci"}}}`,
+	}, {
+		name: "evaluation errors and non bool results",
+		obj:  `{"apiVersion": "v1", "kind": "ConfigMap", "enabled": "yes", "metadata": {"name": "build"}}`,
+		want: []PolicyViolation{{
+			Policy:     "labels",
+			Expression: "object.metadata.labels.team != ''",
+			Message:    "failed to evaluate: no such key: labels",
+		}, {
+			Policy:     "labels",
+			Expression: "object.enabled",
+			Message:    "returned yes, expected a bool",
+		}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := set.Check(unstructuredFromJSON(t, tc.obj))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Check() -want +got: %s", diff)
+			}
+		})
+	}
+}
+
+func TestValidatingCreator(t *testing.T) {
+	set, err := NewPolicySet([]Policy{timeoutPolicy, {
+		Name:        "namespace",
+		Validations: []PolicyValidation{{Expression: "object.metadata.namespace == 'bar'"}},
+	}})
+	if err != nil {
+		t.Fatalf("NewPolicySet() returned error: %v", err)
+	}
+	fake := &FakeCreator{}
+	v := &ValidatingCreator{Creator: fake, Policies: set}
+
+	rt := json.RawMessage(`{"apiVersion": "tekton.dev/v1beta1", "kind": "PipelineRun", "metadata": {"generateName": "build-"}, "spec": {}}`)
+	_, err = v.Create(context.Background(), zaptest.NewLogger(t).Sugar(), rt, triggerName, eventID, "foo-el", "bar", nil, nil)
+	var violationErr *PolicyViolationError
+	if !errors.As(err, &violationErr) {
+		t.Fatalf("Create() returned error %v, want a *PolicyViolationError", err)
+	}
+	want := "PipelineRun build- violates policy pipelinerun-timeout: PipelineRuns must set a timeout (expression: has(object.spec.timeouts))"
+	if err.Error() != want {
+		t.Errorf("Create() returned error %q, want %q", err, want)
+	}
+	if len(fake.Created()) != 0 {
+		t.Error("Create() created a resource violating the policies")
+	}
+
+	rt = json.RawMessage(`{"apiVersion": "tekton.dev/v1beta1", "kind": "PipelineRun", "metadata": {"generateName": "build-"}, "spec": {"timeouts": {"pipeline": "1h"}}}`)
+	got, err := v.Create(context.Background(), zaptest.NewLogger(t).Sugar(), rt, triggerName, eventID, "foo-el", "bar", nil, nil)
+	if err != nil {
+		t.Fatalf("Create() returned error: %v", err)
+	}
+	if got.GetNamespace() != "bar" || len(fake.Created()) != 1 {
+		t.Errorf("Create() returned %v, want the created resource", got)
+	}
+}
+
+func TestValidatingCreator_Mutation(t *testing.T) {
+	set, err := NewPolicySet([]Policy{timeoutPolicy})
+	if err != nil {
+		t.Fatalf("NewPolicySet() returned error: %v", err)
+	}
+	fake := &FakeCreator{}
+	v := &ValidatingCreator{Creator: fake, Policies: set}
+
+	// The policies check the resource as mutated by the hook, which is called once per resource.
+	calls := 0
+	ctx := WithMutation(context.Background(), &Mutation{Hook: MutationHookFunc(func(_ context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+		calls++
+		if err := unstructured.SetNestedField(obj.Object, "1h", "spec", "timeouts", "pipeline"); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	})})
+	rt := json.RawMessage(`{"apiVersion": "tekton.dev/v1beta1", "kind": "PipelineRun", "metadata": {"generateName": "build-"}, "spec": {}}`)
+	got, err := v.Create(ctx, zaptest.NewLogger(t).Sugar(), rt, triggerName, eventID, "foo-el", "bar", nil, nil)
+	if err != nil {
+		t.Fatalf("Create() returned error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Create() called the mutation hook %d times, want 1", calls)
+	}
+	if timeout, _, _ := unstructured.NestedString(got.Object, "spec", "timeouts", "pipeline"); timeout != "1h" {
+		t.Errorf("Create() returned %v, want the mutated resource", got.Object)
+	}
+
+	// Each resource is mutated.
+	if _, err := v.Create(ctx, zaptest.NewLogger(t).Sugar(), rt, triggerName, eventID, "foo-el", "bar", nil, nil); err != nil {
+		t.Fatalf("Create() returned error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Create() called the mutation hook %d times for 2 resources, want 2", calls)
+	}
+}
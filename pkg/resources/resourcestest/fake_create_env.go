@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package resourcestest provides fakes to test the creation of resources by the resources package.
+package resourcestest
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/tektoncd/triggers/pkg/resources"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryclient "k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+// FakeCreateEnv runs resources.Create against a fake dynamic client, whose resources are served by a fake discovery client,
+// so that the whole creation of resources, including finding their API resources, can be tested without an API
+// server. It records the resources returned by Create. It is safe for concurrent use.
+type FakeCreateEnv struct {
+	// Discovery serves the API resources of the environment.
+	Discovery *fakediscovery.FakeDiscovery
+	// DynamicClient stores the created resources. Existing resources can be added to its Tracker, and reactors
+	// prepended to it, e.g. to fail some requests.
+	DynamicClient *fakedynamic.FakeDynamicClient
+
+	mu      sync.Mutex
+	created []*unstructured.Unstructured
+}
+
+// NewFakeCreateEnv returns an environment whose discovery client serves the API resources, which must have a
+// Version, a Kind and a plural Name, and a Group unless they are in the core API group, e.g.
+//
+//	NewFakeCreateEnv(
+//		metav1.APIResource{Group: "tekton.dev", Version: "v1beta1", Kind: "PipelineRun", Name: "pipelineruns", Namespaced: true},
+//		metav1.APIResource{Version: "v1", Kind: "ConfigMap", Name: "configmaps", Namespaced: true},
+//	)
+func NewFakeCreateEnv(apiResources ...metav1.APIResource) *FakeCreateEnv {
+	var lists []*metav1.APIResourceList
+	byGroupVersion := map[string]*metav1.APIResourceList{}
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, r := range apiResources {
+		gv := schema.GroupVersion{Group: r.Group, Version: r.Version}.String()
+		list, ok := byGroupVersion[gv]
+		if !ok {
+			list = &metav1.APIResourceList{GroupVersion: gv}
+			byGroupVersion[gv] = list
+			lists = append(lists, list)
+		}
+		list.APIResources = append(list.APIResources, r)
+		listKinds[schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Name}] = r.Kind + "List"
+	}
+	return &FakeCreateEnv{
+		Discovery:     &fakediscovery.FakeDiscovery{Fake: &ktesting.Fake{Resources: lists}},
+		DynamicClient: fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds),
+	}
+}
+
+// Create calls resources.Create with the clients of the environment, and records the resource it returns.
+func (e *FakeCreateEnv) Create(ctx context.Context, logger *zap.SugaredLogger, rt json.RawMessage, triggerName, eventID, elName, elNamespace string) (*unstructured.Unstructured, error) {
+	created, err := resources.Create(ctx, logger, rt, triggerName, eventID, elName, elNamespace, e.Discovery, e.DynamicClient)
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.created = append(e.created, created.DeepCopy())
+	return created, nil
+}
+
+// Creator returns a Creator that creates the resources in the environment, whatever the clients it is given,
+// e.g. to test the triggers of a Sink end to end.
+func (e *FakeCreateEnv) Creator() resources.Creator {
+	return resources.CreatorFunc(func(ctx context.Context, logger *zap.SugaredLogger, rt json.RawMessage, triggerName, eventID, elName, elNamespace string, _ discoveryclient.ServerResourcesInterface, _ dynamic.Interface) (*unstructured.Unstructured, error) {
+		return e.Create(ctx, logger, rt, triggerName, eventID, elName, elNamespace)
+	})
+}
+
+// Created returns the resources recorded so far, in the order Create returned them.
+func (e *FakeCreateEnv) Created() []*unstructured.Unstructured {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*unstructured.Unstructured{}, e.created...)
+}
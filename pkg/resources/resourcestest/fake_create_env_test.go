@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcestest
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	"go.uber.org/zap/zaptest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	triggerName = "trigger"
+	eventID     = "12345"
+)
+
+func TestFakeCreateEnv(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	env := NewFakeCreateEnv(
+		metav1.APIResource{Group: "tekton.dev", Version: "v1beta1", Kind: "PipelineRun", Name: "pipelineruns", Namespaced: true},
+		metav1.APIResource{Version: "v1", Kind: "ConfigMap", Name: "configmaps", Namespaced: true},
+	)
+
+	rts := []json.RawMessage{
+		json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"run"}}`),
+		json.RawMessage(`{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"cm","namespace":"other"}}`),
+	}
+	creator := env.Creator()
+	for _, rt := range rts {
+		if _, err := creator.Create(context.Background(), logger, rt, triggerName, eventID, "el", "el-ns", nil, nil); err != nil {
+			t.Fatalf("Create() returned error: %v", err)
+		}
+	}
+
+	created := env.Created()
+	if len(created) != 2 {
+		t.Fatalf("Created() returned %d resources, want 2", len(created))
+	}
+	if got := created[0].GetLabels()[triggers.GroupName+triggers.EventIDLabelKey]; got != eventID {
+		t.Errorf("event ID label = %q, want %q", got, eventID)
+	}
+	for _, tc := range []struct {
+		gvr       schema.GroupVersionResource
+		namespace string
+		name      string
+	}{
+		{gvr: schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}, namespace: "el-ns", name: "run"},
+		{gvr: schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, namespace: "other", name: "cm"},
+	} {
+		if _, err := env.DynamicClient.Resource(tc.gvr).Namespace(tc.namespace).Get(context.Background(), tc.name, metav1.GetOptions{}); err != nil {
+			t.Errorf("failed to get %s %s/%s: %v", tc.gvr.Resource, tc.namespace, tc.name, err)
+		}
+	}
+}
+
+func TestFakeCreateEnv_UnknownKind(t *testing.T) {
+	env := NewFakeCreateEnv(metav1.APIResource{Version: "v1", Kind: "ConfigMap", Name: "configmaps", Namespaced: true})
+	rt := json.RawMessage(`{"kind":"Secret","apiVersion":"v1","metadata":{"name":"secret"}}`)
+	if _, err := env.Create(context.Background(), zaptest.NewLogger(t).Sugar(), rt, triggerName, eventID, "el", "el-ns"); err == nil || !strings.Contains(err.Error(), "Secret") {
+		t.Errorf("Create() returned error %v, want an error about the Secret kind", err)
+	}
+	if got := env.Created(); len(got) != 0 {
+		t.Errorf("expected no resources to be recorded, got: %v", got)
+	}
+}
@@ -0,0 +1,272 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// AccessLog writes a structured entry for each request received by the sink, once the request is
+// handled and the triggers the event was dispatched to are processed.
+//
+// A nil *AccessLog writes nothing.
+type AccessLog struct {
+	// Logger writes the entries, separately from the application logs, e.g. from NewAccessLogger.
+	Logger *zap.Logger
+	// Fields are the fields of the entries. Defaults to triggers.DefaultAccessLogFields if empty.
+	Fields []string
+	// SampleRate is the fraction of the successfully handled requests that get an entry. Requests
+	// rejected with an error status are always logged.
+	SampleRate float64
+	// TrustedProxies are the proxies whose forwarding headers set the sourceIP of the requests they forward.
+	TrustedProxies TrustedProxies
+	// TrustedProxyHeader is the forwarding header that the TrustedProxies set, like the one of the sink.
+	TrustedProxyHeader string
+
+	now    func() time.Time
+	random func() float64
+}
+
+// NewAccessLogger returns a logger writing access log entries to w as JSON lines, without the level
+// and sampling of the application logs.
+func NewAccessLogger(w io.Writer) *zap.Logger {
+	return newJSONLinesLogger(w).Named("access")
+}
+
+// newJSONLinesLogger returns a logger writing its entries to w as JSON lines, with their message and the
+// name of the logger but without a level or a timestamp.
+func newJSONLinesLogger(w io.Writer) *zap.Logger {
+	encoder := zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		MessageKey:     "msg",
+		NameKey:        "logger",
+		EncodeDuration: zapcore.StringDurationEncoder,
+	})
+	return zap.New(zapcore.NewCore(encoder, zapcore.Lock(zapcore.AddSync(w)), zapcore.InfoLevel))
+}
+
+func (a *AccessLog) currentTime() time.Time {
+	if a.now == nil {
+		return time.Now()
+	}
+	return a.now()
+}
+
+func (a *AccessLog) sampled(status int) bool {
+	if status >= http.StatusBadRequest || a.SampleRate >= 1 {
+		return true
+	}
+	random := rand.Float64
+	if a.random != nil {
+		random = a.random
+	}
+	return random() < a.SampleRate
+}
+
+func (a *AccessLog) fields() []string {
+	if len(a.Fields) == 0 {
+		return triggers.DefaultAccessLogFields
+	}
+	return a.Fields
+}
+
+func (a *AccessLog) logsPayload() bool {
+	for _, f := range a.fields() {
+		if f == triggers.AccessLogPayloadField {
+			return true
+		}
+	}
+	return false
+}
+
+// accessRecord collects the fields of the access log entry of a request as it is handled.
+// It is safe for concurrent use by the goroutines processing the event.
+type accessRecord struct {
+	mu       sync.Mutex
+	eventID  string
+	triggers []string
+	groups   []string
+	fired    []string
+	// pending counts the parts of the handling of the request that are not complete: the response,
+	// and the processing of the triggers the event was dispatched to, if any.
+	pending int
+	emit    func(*accessRecord)
+}
+
+type accessRecordKey struct{}
+
+// accessRecordFrom returns the access record of the request with the given context, or nil if the
+// access log is disabled.
+func accessRecordFrom(ctx context.Context) *accessRecord {
+	rec, _ := ctx.Value(accessRecordKey{}).(*accessRecord)
+	return rec
+}
+
+// dispatched records the triggers and trigger groups the event was dispatched to. If there are any,
+// the entry is delayed until processed is called.
+func (a *accessRecord) dispatched(eventID string, triggers, groups []string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.eventID = eventID
+	a.triggers = append([]string{}, triggers...)
+	sort.Strings(a.triggers)
+	a.groups = append([]string{}, groups...)
+	sort.Strings(a.groups)
+	if len(triggers) > 0 || len(groups) > 0 {
+		a.pending++
+	}
+}
+
+// processed records the triggers that fired once all the triggers the event was dispatched to are processed.
+func (a *accessRecord) processed(fired []string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	a.fired = fired
+	a.mu.Unlock()
+	a.done()
+}
+
+// done completes a part of the handling of the request, and writes the entry once all parts are complete.
+func (a *accessRecord) done() {
+	a.mu.Lock()
+	a.pending--
+	complete := a.pending == 0
+	a.mu.Unlock()
+	if complete {
+		a.emit(a)
+	}
+}
+
+// statusRecorder records the status code written to a ResponseWriter.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+// WithAccessLog writes an access log entry for each request handled by eventHandler.
+func (r Sink) WithAccessLog(eventHandler http.Handler) http.Handler {
+	a := r.AccessLog
+	if a == nil || a.Logger == nil {
+		return eventHandler
+	}
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		start := a.currentTime()
+		var payload []byte
+		if a.logsPayload() {
+			// The payload is read before the other handlers, so that rejected payloads are logged too.
+			var err error
+			payload, err = ioutil.ReadAll(request.Body)
+			request.Body = ioutil.NopCloser(bytes.NewBuffer(payload))
+			if err != nil {
+				r.Logger.Errorf("Error reading event body: %s", err)
+			}
+		}
+
+		recorder := &statusRecorder{ResponseWriter: response}
+		rec := &accessRecord{pending: 1}
+		rec.emit = func(rec *accessRecord) {
+			status := recorder.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if !a.sampled(status) {
+				return
+			}
+			a.Logger.Info("request", a.entry(request, r.externalURL(request.URL).Path, payload, rec, status, start)...)
+		}
+		eventHandler.ServeHTTP(recorder, request.WithContext(context.WithValue(request.Context(), accessRecordKey{}, rec)))
+		rec.done()
+	})
+}
+
+// entry returns the selected fields of the access log entry, where path is the path of the request
+// including the base path of the sink.
+func (a *AccessLog) entry(request *http.Request, path string, payload []byte, rec *accessRecord, status int, start time.Time) []zap.Field {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	var fields []zap.Field
+	for _, name := range a.fields() {
+		switch name {
+		case "timestamp":
+			fields = append(fields, zap.String(name, start.UTC().Format(time.RFC3339Nano)))
+		case "sourceIP":
+			fields = append(fields, zap.String(name, a.TrustedProxies.ClientIP(request, a.TrustedProxyHeader)))
+		case "forwardedFor":
+			if v := request.Header.Get("X-Forwarded-For"); v != "" {
+				fields = append(fields, zap.String(name, v))
+			}
+		case "method":
+			fields = append(fields, zap.String(name, request.Method))
+		case "path":
+			fields = append(fields, zap.String(name, path))
+		case "userAgent":
+			fields = append(fields, zap.String(name, request.UserAgent()))
+		case "eventID":
+			if rec.eventID != "" {
+				fields = append(fields, zap.String(name, rec.eventID))
+			}
+		case "triggers":
+			fields = append(fields, zap.Strings(name, rec.triggers))
+		case "triggerGroups":
+			fields = append(fields, zap.Strings(name, rec.groups))
+		case "firedTriggers":
+			fields = append(fields, zap.Strings(name, rec.fired))
+		case "status":
+			fields = append(fields, zap.Int(name, status))
+		case "duration":
+			fields = append(fields, zap.Duration(name, a.currentTime().Sub(start)))
+		case triggers.AccessLogPayloadField:
+			if json.Valid(payload) {
+				fields = append(fields, zap.Reflect(name, json.RawMessage(payload)))
+			} else {
+				fields = append(fields, zap.ByteString(name, payload))
+			}
+		}
+	}
+	return fields
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	triggersv1beta1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/test"
+	"go.uber.org/zap/zaptest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// accessLogEntries decodes the JSON lines written by an access logger.
+func accessLogEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid access log entry %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestWithAccessLog(t *testing.T) {
+	start := time.Date(2022, 4, 15, 10, 0, 0, 0, time.UTC)
+	buf := &bytes.Buffer{}
+	now := start
+	r := Sink{
+		Logger: zaptest.NewLogger(t).Sugar(),
+		AccessLog: &AccessLog{
+			Logger:     NewAccessLogger(buf),
+			SampleRate: 1,
+			now:        func() time.Time { return now },
+		},
+	}
+	processed := make(chan []string)
+	done := make(chan struct{})
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rec := accessRecordFrom(req.Context())
+		rec.dispatched("event-1", []string{"b-trigger", "a-trigger"}, []string{"group"})
+		// The triggers are still processed after the response is written.
+		go func() {
+			rec.processed(<-processed)
+			close(done)
+		}()
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "https://el.example.com/hooks?env=prod", strings.NewReader(`{"secret": "value"}`))
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("User-Agent", "GitHub-Hookshot/abc")
+	req.Header.Set("X-Forwarded-For", "198.51.100.7")
+	r.WithAccessLog(next).ServeHTTP(httptest.NewRecorder(), req)
+	if buf.Len() != 0 {
+		t.Fatalf("expected no entry before the triggers are processed, got %s", buf)
+	}
+
+	now = start.Add(1500 * time.Millisecond)
+	processed <- []string{"a-trigger"}
+	<-done
+
+	want := []map[string]interface{}{{
+		"logger":        "access",
+		"msg":           "request",
+		"timestamp":     "2022-04-15T10:00:00Z",
+		"sourceIP":      "192.0.2.1",
+		"forwardedFor":  "198.51.100.7",
+		"method":        "POST",
+		"path":          "/hooks",
+		"userAgent":     "GitHub-Hookshot/abc",
+		"eventID":       "event-1",
+		"triggers":      []interface{}{"a-trigger", "b-trigger"},
+		"triggerGroups": []interface{}{"group"},
+		"firedTriggers": []interface{}{"a-trigger"},
+		"status":        float64(http.StatusAccepted),
+		"duration":      "1.5s",
+	}}
+	if diff := cmp.Diff(want, accessLogEntries(t, buf)); diff != "" {
+		t.Errorf("access log entries (-want +got): %s", diff)
+	}
+}
+
+func TestWithAccessLog_FieldsAndSampling(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		fields     []string
+		sampleRate float64
+		status     int
+		want       []map[string]interface{}
+	}{{
+		name:       "selected fields",
+		fields:     []string{"status", "method"},
+		sampleRate: 1,
+		status:     http.StatusAccepted,
+		want:       []map[string]interface{}{{"logger": "access", "msg": "request", "status": float64(http.StatusAccepted), "method": "POST"}},
+	}, {
+		name:       "JSON payload",
+		fields:     []string{"payload"},
+		sampleRate: 1,
+		status:     http.StatusAccepted,
+		want:       []map[string]interface{}{{"logger": "access", "msg": "request", "payload": map[string]interface{}{"secret": "value"}}},
+	}, {
+		name:       "successful request not sampled",
+		fields:     []string{"status"},
+		sampleRate: 0.25,
+		status:     http.StatusAccepted,
+	}, {
+		name:       "rejected requests are always logged",
+		fields:     []string{"status"},
+		sampleRate: 0,
+		status:     http.StatusUnsupportedMediaType,
+		want:       []map[string]interface{}{{"logger": "access", "msg": "request", "status": float64(http.StatusUnsupportedMediaType)}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			r := Sink{
+				Logger: zaptest.NewLogger(t).Sugar(),
+				AccessLog: &AccessLog{
+					Logger:     NewAccessLogger(buf),
+					Fields:     tc.fields,
+					SampleRate: tc.sampleRate,
+					random:     func() float64 { return 0.5 },
+				},
+			}
+			var gotPayload string
+			next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				// The payload is still available to the next handlers.
+				b := &bytes.Buffer{}
+				_, _ = b.ReadFrom(req.Body)
+				gotPayload = b.String()
+				w.WriteHeader(tc.status)
+			})
+			req := httptest.NewRequest(http.MethodPost, "https://el.example.com", strings.NewReader(`{"secret": "value"}`))
+			r.WithAccessLog(next).ServeHTTP(httptest.NewRecorder(), req)
+
+			if gotPayload != `{"secret": "value"}` {
+				t.Errorf("next handler got payload %q", gotPayload)
+			}
+			if diff := cmp.Diff(tc.want, accessLogEntries(t, buf)); diff != "" {
+				t.Errorf("access log entries (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestWithAccessLog_Disabled(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// Recording on a disabled access log doesn't panic.
+		rec := accessRecordFrom(req.Context())
+		rec.dispatched("event-1", []string{"trigger"}, nil)
+		rec.processed(nil)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	resp := httptest.NewRecorder()
+	Sink{}.WithAccessLog(next).ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "https://el.example.com", nil))
+	if resp.Code != http.StatusAccepted {
+		t.Errorf("got status %d, want %d", resp.Code, http.StatusAccepted)
+	}
+}
+
+func TestWithAccessLog_HandleEvent(t *testing.T) {
+	resources := test.Resources{
+		EventListeners: []*triggersv1beta1.EventListener{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-el",
+				Namespace: namespace,
+			},
+		}},
+	}
+	sink, _ := getSinkAssets(t, resources, "test-el", nil)
+	buf := &bytes.Buffer{}
+	sink.AccessLog = &AccessLog{
+		Logger:     NewAccessLogger(buf),
+		Fields:     []string{"eventID", "triggers", "status"},
+		SampleRate: 1,
+	}
+
+	resp := httptest.NewRecorder()
+	sink.WithAccessLog(http.HandlerFunc(sink.HandleEvent)).ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "https://el.example.com", strings.NewReader(`{}`)))
+	sink.WGProcessTriggers.Wait()
+
+	var body Response
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Error reading response body: %s", err)
+	}
+	want := []map[string]interface{}{{
+		"logger":   "access",
+		"msg":      "request",
+		"eventID":  body.EventID,
+		"triggers": []interface{}{},
+		"status":   float64(http.StatusAccepted),
+	}}
+	if diff := cmp.Diff(want, accessLogEntries(t, buf)); diff != "" {
+		t.Errorf("access log entries (-want +got): %s", diff)
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	triggersclientset "github.com/tektoncd/triggers/pkg/client/clientset/versioned"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/retry"
+)
+
+// MaxRecentActivity is the number of created resources kept in the recent activity of the
+// EventListener's status.
+const MaxRecentActivity = 10
+
+// Activity records the resources created by the sink in the RecentActivity of the EventListener's
+// status, and the triggers that exceeded the CreationLimit in its CreationLimited condition. To avoid
+// hammering the API server under high event rates, the status is updated at most once per Interval
+// with the activity since the previous update.
+//
+// A nil *Activity records nothing.
+type Activity struct {
+	// TriggersClient updates the status of the EventListener.
+	TriggersClient triggersclientset.Interface
+	// EventListenerName and EventListenerNamespace identify the EventListener to update.
+	EventListenerName      string
+	EventListenerNamespace string
+	// Interval is the minimum time between two updates of the status.
+	Interval time.Duration
+	// Replica is the name of the pod of the sink, reported in the CreationLimited condition.
+	Replica string
+	// CreationLimitsOnly, if true, only records the exceeded creation limits, not the created resources.
+	CreationLimitsOnly bool
+	Logger             *zap.SugaredLogger
+
+	mu         sync.Mutex
+	pending    []triggersv1.EventListenerActivity
+	limited    *limitedTrigger
+	scheduled  bool
+	lastUpdate time.Time
+	now        func() time.Time
+	afterFunc  func(time.Duration, func())
+}
+
+// limitedTrigger is the last trigger that exceeded the creation limit.
+type limitedTrigger struct {
+	trigger string
+	time    metav1.Time
+}
+
+func (a *Activity) currentTime() time.Time {
+	if a.now == nil {
+		return time.Now()
+	}
+	return a.now()
+}
+
+func (a *Activity) schedule(d time.Duration, f func()) {
+	if a.afterFunc == nil {
+		time.AfterFunc(d, f)
+		return
+	}
+	a.afterFunc(d, f)
+}
+
+// record records that the trigger created obj for the event, and schedules an update of the status
+// if none is pending.
+func (a *Activity) record(triggerName, eventID string, obj *unstructured.Unstructured) {
+	if a == nil || obj == nil || a.CreationLimitsOnly {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.currentTime()
+	a.pending = append(a.pending, triggersv1.EventListenerActivity{
+		Time:    metav1.NewTime(now),
+		Trigger: triggerName,
+		EventID: eventID,
+		Kind:    obj.GetKind(),
+		Name:    obj.GetName(),
+	})
+	if len(a.pending) > MaxRecentActivity {
+		a.pending = a.pending[len(a.pending)-MaxRecentActivity:]
+	}
+	a.scheduleLocked(now)
+}
+
+// limit records that the trigger skipped resource creation to stay within the creation limit, and
+// schedules an update of the status if none is pending.
+func (a *Activity) limit(triggerName string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.currentTime()
+	a.limited = &limitedTrigger{trigger: triggerName, time: metav1.NewTime(now)}
+	a.scheduleLocked(now)
+}
+
+// scheduleLocked schedules an update of the status once the Interval since the previous one has
+// passed, unless one is already scheduled. It must be called with a.mu held.
+func (a *Activity) scheduleLocked(now time.Time) {
+	if a.scheduled {
+		return
+	}
+	a.scheduled = true
+	delay := a.Interval - now.Sub(a.lastUpdate)
+	if delay < 0 {
+		delay = 0
+	}
+	a.schedule(delay, a.flush)
+}
+
+// flush adds the pending activity to the status of the EventListener.
+func (a *Activity) flush() {
+	a.mu.Lock()
+	pending, limited := a.pending, a.limited
+	a.pending, a.limited = nil, nil
+	a.scheduled = false
+	a.lastUpdate = a.currentTime()
+	a.mu.Unlock()
+
+	if len(pending) == 0 && limited == nil {
+		return
+	}
+	if err := a.update(context.Background(), pending, limited); err != nil {
+		a.Logger.Warnf("failed to record recent activity in the status of EventListener %s: %v", a.EventListenerName, err)
+	}
+}
+
+func (a *Activity) update(ctx context.Context, pending []triggersv1.EventListenerActivity, limited *limitedTrigger) error {
+	client := a.TriggersClient.TriggersV1beta1().EventListeners(a.EventListenerNamespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		el, err := client.Get(ctx, a.EventListenerName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			el.Status.RecentActivity = mergeActivity(pending, el.Status.RecentActivity)
+		}
+		if limited != nil {
+			el.Status.SetCreationLimitedCondition(limited.trigger, a.Replica, limited.time)
+		}
+		_, err = client.UpdateStatus(ctx, el, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// mergeActivity returns the newest MaxRecentActivity entries of pending, oldest first, and existing,
+// newest first, with the newest entry first.
+func mergeActivity(pending, existing []triggersv1.EventListenerActivity) []triggersv1.EventListenerActivity {
+	merged := make([]triggersv1.EventListenerActivity, 0, MaxRecentActivity)
+	for i := len(pending) - 1; i >= 0 && len(merged) < MaxRecentActivity; i-- {
+		merged = append(merged, pending[i])
+	}
+	for i := 0; i < len(existing) && len(merged) < MaxRecentActivity; i++ {
+		merged = append(merged, existing[i])
+	}
+	return merged
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	faketriggersclientset "github.com/tektoncd/triggers/pkg/client/clientset/versioned/fake"
+	"go.uber.org/zap/zaptest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"knative.dev/pkg/apis"
+)
+
+func createdObject(kind, name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetKind(kind)
+	u.SetName(name)
+	return u
+}
+
+func TestActivity(t *testing.T) {
+	el := &triggersv1.EventListener{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-el", Namespace: "ns"},
+	}
+	client := faketriggersclientset.NewSimpleClientset(el)
+	now := time.Unix(1650000000, 0)
+	var scheduled []time.Duration
+	var flush func()
+	a := &Activity{
+		TriggersClient:         client,
+		EventListenerName:      "my-el",
+		EventListenerNamespace: "ns",
+		Interval:               10 * time.Second,
+		Logger:                 zaptest.NewLogger(t).Sugar(),
+		now:                    func() time.Time { return now },
+		afterFunc: func(d time.Duration, f func()) {
+			scheduled = append(scheduled, d)
+			flush = f
+		},
+	}
+
+	// Resources created before the update are batched into a single update.
+	a.record("trigger-a", "event-1", createdObject("PipelineRun", "run-1"))
+	a.record("trigger-b", "event-1", createdObject("TaskRun", "run-2"))
+	if diff := cmp.Diff([]time.Duration{0}, scheduled); diff != "" {
+		t.Fatalf("unexpected updates scheduled (-want +got): %s", diff)
+	}
+	flush()
+
+	got, err := client.TriggersV1beta1().EventListeners("ns").Get(context.Background(), "my-el", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []triggersv1.EventListenerActivity{{
+		Time:    metav1.NewTime(now),
+		Trigger: "trigger-b",
+		EventID: "event-1",
+		Kind:    "TaskRun",
+		Name:    "run-2",
+	}, {
+		Time:    metav1.NewTime(now),
+		Trigger: "trigger-a",
+		EventID: "event-1",
+		Kind:    "PipelineRun",
+		Name:    "run-1",
+	}}
+	if diff := cmp.Diff(want, got.Status.RecentActivity); diff != "" {
+		t.Errorf("unexpected recent activity (-want +got): %s", diff)
+	}
+
+	// The next update is throttled until the interval since the previous one has passed.
+	now = now.Add(4 * time.Second)
+	a.record("trigger-a", "event-2", createdObject("PipelineRun", "run-3"))
+	if diff := cmp.Diff([]time.Duration{0, 6 * time.Second}, scheduled); diff != "" {
+		t.Fatalf("unexpected updates scheduled (-want +got): %s", diff)
+	}
+	flush()
+
+	got, err = client.TriggersV1beta1().EventListeners("ns").Get(context.Background(), "my-el", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Status.RecentActivity) != 3 || got.Status.RecentActivity[0].Name != "run-3" {
+		t.Errorf("expected run-3 to be the newest of 3 entries, got %v", got.Status.RecentActivity)
+	}
+
+	updates := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "update" && action.GetSubresource() == "status" {
+			updates++
+		}
+	}
+	if updates != 2 {
+		t.Errorf("got %d status updates, want 2", updates)
+	}
+}
+
+func TestActivity_CreationLimited(t *testing.T) {
+	el := &triggersv1.EventListener{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-el", Namespace: "ns"},
+	}
+	client := faketriggersclientset.NewSimpleClientset(el)
+	now := time.Unix(1650000000, 0)
+	var flush func()
+	a := &Activity{
+		TriggersClient:         client,
+		EventListenerName:      "my-el",
+		EventListenerNamespace: "ns",
+		Interval:               time.Minute,
+		Replica:                "el-my-el-1",
+		CreationLimitsOnly:     true,
+		Logger:                 zaptest.NewLogger(t).Sugar(),
+		now:                    func() time.Time { return now },
+		afterFunc:              func(_ time.Duration, f func()) { flush = f },
+	}
+
+	// Only the last trigger that exceeded the limit before the update is reported, and the created
+	// resources aren't recorded.
+	a.record("trigger-a", "event-1", createdObject("PipelineRun", "run-1"))
+	a.limit("trigger-a")
+	now = now.Add(time.Second)
+	a.limit("trigger-b")
+	flush()
+
+	got, err := client.TriggersV1beta1().EventListeners("ns").Get(context.Background(), "my-el", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Status.RecentActivity) != 0 {
+		t.Errorf("expected no recent activity, got %v", got.Status.RecentActivity)
+	}
+	want := &apis.Condition{
+		Type:               triggersv1.CreationLimited,
+		Status:             corev1.ConditionTrue,
+		Reason:             "CreationLimitExceeded",
+		Message:            "Replica el-my-el-1 skipped the creation of the resources of Trigger trigger-b to stay within its creation limit",
+		LastTransitionTime: apis.VolatileTime{Inner: metav1.NewTime(now)},
+	}
+	if diff := cmp.Diff(want, got.Status.GetCondition(triggersv1.CreationLimited)); diff != "" {
+		t.Errorf("unexpected CreationLimited condition (-want +got): %s", diff)
+	}
+}
+
+func TestActivity_Nil(t *testing.T) {
+	var a *Activity
+	// A nil Activity records nothing and doesn't panic.
+	a.record("trigger", "event", createdObject("PipelineRun", "run"))
+	a.limit("trigger")
+}
+
+func TestMergeActivity(t *testing.T) {
+	entries := func(prefix string, n int) []triggersv1.EventListenerActivity {
+		var out []triggersv1.EventListenerActivity
+		for i := 0; i < n; i++ {
+			out = append(out, triggersv1.EventListenerActivity{Name: fmt.Sprintf("%s-%d", prefix, i)})
+		}
+		return out
+	}
+	names := func(a []triggersv1.EventListenerActivity) []string {
+		var out []string
+		for _, e := range a {
+			out = append(out, e.Name)
+		}
+		return out
+	}
+
+	for _, tt := range []struct {
+		name     string
+		pending  []triggersv1.EventListenerActivity
+		existing []triggersv1.EventListenerActivity
+		want     []string
+	}{{
+		name:     "newest first",
+		pending:  entries("new", 2),
+		existing: entries("old", 2),
+		want:     []string{"new-1", "new-0", "old-0", "old-1"},
+	}, {
+		name:     "existing entries are dropped once full",
+		pending:  entries("new", 8),
+		existing: entries("old", 5),
+		want:     []string{"new-7", "new-6", "new-5", "new-4", "new-3", "new-2", "new-1", "new-0", "old-0", "old-1"},
+	}, {
+		name:    "only the newest pending entries are kept",
+		pending: entries("new", 12),
+		want:    []string{"new-11", "new-10", "new-9", "new-8", "new-7", "new-6", "new-5", "new-4", "new-3", "new-2"},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, names(mergeActivity(tt.pending, tt.existing))); diff != "" {
+				t.Errorf("mergeActivity() (-want +got): %s", diff)
+			}
+		})
+	}
+}
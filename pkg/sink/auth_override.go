@@ -43,6 +43,9 @@ type AuthOverride interface {
 }
 
 type DefaultAuthOverride struct {
+	// Config is the REST config the clients impersonating service accounts are created from.
+	// Defaults to the in cluster config.
+	Config *rest.Config
 }
 
 func (r DefaultAuthOverride) OverrideAuthentication(sa string,
@@ -54,8 +57,10 @@ func (r DefaultAuthOverride) OverrideAuthentication(sa string,
 	err error) {
 	dynamicClient = defaultDynamicClient
 	discoveryClient = defaultDiscoverClient
-	clusterConfig, err := rest.InClusterConfig()
-	if err != nil {
+	var clusterConfig *rest.Config
+	if r.Config != nil {
+		clusterConfig = rest.CopyConfig(r.Config)
+	} else if clusterConfig, err = rest.InClusterConfig(); err != nil {
 		log.Errorf("overrideAuthentication: problem getting in cluster config: %#v\n", err)
 		return
 	}
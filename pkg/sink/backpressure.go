@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// inFlightReason is the reason recorded when events are rejected because too many
+	// resource creations are in flight.
+	inFlightReason = "in-flight"
+	// throttledReason is the reason recorded when events are rejected because the API server
+	// has recently throttled resource creation.
+	throttledReason = "throttled"
+
+	// defaultRetryAfter is how long senders are asked to wait if no RetryAfter is configured.
+	defaultRetryAfter = 30 * time.Second
+)
+
+// Backpressure decides if the sink is overloaded and should ask senders to retry events later
+// instead of accepting them. The sink is considered overloaded when either:
+//   - the number of in-flight resource creations is at or above MaxInFlight, or
+//   - the API server responded to a resource creation with 429 Too Many Requests within the
+//     last RetryAfter.
+//
+// A nil *Backpressure never reports the sink as overloaded.
+type Backpressure struct {
+	// MaxInFlight is the number of concurrent resource creations above which events are rejected.
+	MaxInFlight int64
+	// RetryAfter is the duration senders are asked to wait via the Retry-After header.
+	RetryAfter time.Duration
+
+	inFlight       int64
+	throttledUntil int64
+	now            func() time.Time
+}
+
+func (b *Backpressure) retryAfter() time.Duration {
+	if b.RetryAfter <= 0 {
+		return defaultRetryAfter
+	}
+	return b.RetryAfter
+}
+
+func (b *Backpressure) currentTime() time.Time {
+	if b.now == nil {
+		return time.Now()
+	}
+	return b.now()
+}
+
+// overloaded returns true and the reason if new events should be rejected.
+func (b *Backpressure) overloaded() (bool, string) {
+	if b == nil {
+		return false, ""
+	}
+	if b.MaxInFlight > 0 && atomic.LoadInt64(&b.inFlight) >= b.MaxInFlight {
+		return true, inFlightReason
+	}
+	if b.currentTime().UnixNano() < atomic.LoadInt64(&b.throttledUntil) {
+		return true, throttledReason
+	}
+	return false, ""
+}
+
+// startCreate records the start of a resource creation.
+func (b *Backpressure) startCreate() {
+	if b == nil {
+		return
+	}
+	atomic.AddInt64(&b.inFlight, 1)
+}
+
+// finishCreate records the end of a resource creation along with its result.
+func (b *Backpressure) finishCreate(err error) {
+	if b == nil {
+		return
+	}
+	atomic.AddInt64(&b.inFlight, -1)
+	if apierrors.IsTooManyRequests(err) {
+		atomic.StoreInt64(&b.throttledUntil, b.currentTime().Add(b.retryAfter()).UnixNano())
+	}
+}
+
+// WithBackpressure rejects events with 503 Service Unavailable and a Retry-After header while
+// the sink is overloaded, and passes them on to eventHandler otherwise.
+func (r Sink) WithBackpressure(eventHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		if overloaded, reason := r.Backpressure.overloaded(); overloaded {
+			r.Logger.Warnf("rejecting event, EventListener is overloaded: %s", reason)
+			r.recordBackpressureMetrics(reason)
+			response.Header().Set("Retry-After", strconv.Itoa(int(r.Backpressure.retryAfter().Seconds())))
+			response.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		eventHandler.ServeHTTP(response, request)
+	})
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestBackpressure_Overloaded(t *testing.T) {
+	now := time.Unix(1000, 0)
+	for _, tc := range []struct {
+		name       string
+		bp         *Backpressure
+		prepare    func(b *Backpressure)
+		want       bool
+		wantReason string
+	}{{
+		name: "nil backpressure",
+		bp:   nil,
+	}, {
+		name: "below max in flight",
+		bp:   &Backpressure{MaxInFlight: 2},
+		prepare: func(b *Backpressure) {
+			b.startCreate()
+		},
+	}, {
+		name: "at max in flight",
+		bp:   &Backpressure{MaxInFlight: 2},
+		prepare: func(b *Backpressure) {
+			b.startCreate()
+			b.startCreate()
+		},
+		want:       true,
+		wantReason: inFlightReason,
+	}, {
+		name: "finished creates are not in flight",
+		bp:   &Backpressure{MaxInFlight: 1},
+		prepare: func(b *Backpressure) {
+			b.startCreate()
+			b.finishCreate(errors.New("not a throttling error"))
+		},
+	}, {
+		name: "throttled by api server",
+		bp:   &Backpressure{MaxInFlight: 5, RetryAfter: time.Minute},
+		prepare: func(b *Backpressure) {
+			b.startCreate()
+			b.finishCreate(apierrors.NewTooManyRequests("slow down", 1))
+		},
+		want:       true,
+		wantReason: throttledReason,
+	}, {
+		name: "throttling expires after retry after",
+		bp:   &Backpressure{MaxInFlight: 5, RetryAfter: time.Minute},
+		prepare: func(b *Backpressure) {
+			b.startCreate()
+			b.finishCreate(apierrors.NewTooManyRequests("slow down", 1))
+			now = now.Add(2 * time.Minute)
+		},
+	}, {
+		name: "other api errors do not throttle",
+		bp:   &Backpressure{MaxInFlight: 5},
+		prepare: func(b *Backpressure) {
+			b.startCreate()
+			b.finishCreate(apierrors.NewNotFound(schema.GroupResource{Resource: "pipelineruns"}, "foo"))
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			now = time.Unix(1000, 0)
+			if tc.bp != nil {
+				tc.bp.now = func() time.Time { return now }
+			}
+			if tc.prepare != nil {
+				tc.prepare(tc.bp)
+			}
+			got, reason := tc.bp.overloaded()
+			if got != tc.want || reason != tc.wantReason {
+				t.Errorf("overloaded() = %t, %q; want %t, %q", got, reason, tc.want, tc.wantReason)
+			}
+		})
+	}
+}
+
+func TestSink_WithBackpressure(t *testing.T) {
+	recorder, err := NewRecorder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	for _, tc := range []struct {
+		name           string
+		bp             *Backpressure
+		wantStatusCode int
+		wantRetryAfter string
+	}{{
+		name:           "backpressure disabled",
+		wantStatusCode: http.StatusAccepted,
+	}, {
+		name:           "not overloaded",
+		bp:             &Backpressure{MaxInFlight: 1},
+		wantStatusCode: http.StatusAccepted,
+	}, {
+		name:           "overloaded",
+		bp:             &Backpressure{MaxInFlight: 1, RetryAfter: 45 * time.Second, inFlight: 1},
+		wantStatusCode: http.StatusServiceUnavailable,
+		wantRetryAfter: "45",
+	}, {
+		name:           "overloaded with default retry after",
+		bp:             &Backpressure{MaxInFlight: 1, inFlight: 3},
+		wantStatusCode: http.StatusServiceUnavailable,
+		wantRetryAfter: "30",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := Sink{
+				Logger:       zaptest.NewLogger(t).Sugar(),
+				Recorder:     recorder,
+				Backpressure: tc.bp,
+			}
+			resp := httptest.NewRecorder()
+			r.WithBackpressure(next).ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/", nil))
+			if resp.Code != tc.wantStatusCode {
+				t.Errorf("unexpected status code: got %d, want %d", resp.Code, tc.wantStatusCode)
+			}
+			if got := resp.Header().Get("Retry-After"); got != tc.wantRetryAfter {
+				t.Errorf("unexpected Retry-After header: got %q, want %q", got, tc.wantRetryAfter)
+			}
+		})
+	}
+}
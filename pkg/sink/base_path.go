@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// WithBasePath serves handler under the base path the EventListener is exposed at by a reverse proxy.
+// Requests still carrying the base path have it stripped, so that handler sees the same paths whether
+// or not the proxy strips it. Other requests, such as probes sent to the Pod directly, are passed on as
+// they are.
+func (r Sink) WithBasePath(handler http.Handler) http.Handler {
+	if r.BasePath == "" {
+		return handler
+	}
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		p, ok := trimBasePath(request.URL.Path, r.BasePath)
+		if !ok {
+			handler.ServeHTTP(response, request)
+			return
+		}
+		stripped := new(http.Request)
+		*stripped = *request
+		stripped.URL = new(url.URL)
+		*stripped.URL = *request.URL
+		stripped.URL.Path = p
+		if request.URL.RawPath != "" {
+			stripped.URL.RawPath, _ = trimBasePath(request.URL.RawPath, r.BasePath)
+		}
+		handler.ServeHTTP(response, stripped)
+	})
+}
+
+// trimBasePath returns p relative to basePath, and whether p is under basePath at all.
+func trimBasePath(p, basePath string) (string, bool) {
+	rest := strings.TrimPrefix(p, basePath)
+	switch {
+	case rest == p:
+		return p, false
+	case rest == "":
+		return "/", true
+	case rest[0] != '/':
+		// e.g. /webhooks/tekton-other for the base path /webhooks/tekton
+		return p, false
+	}
+	return rest, true
+}
+
+// externalURL returns the URL of the request as sent to the reverse proxy, with the base path the
+// EventListener is exposed at.
+func (r Sink) externalURL(u *url.URL) *url.URL {
+	if r.BasePath == "" || u == nil {
+		return u
+	}
+	external := *u
+	external.Path = r.BasePath
+	if u.Path != "/" {
+		external.Path += u.Path
+	}
+	if u.RawPath != "" {
+		external.RawPath = r.BasePath + u.RawPath
+	}
+	return &external
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	triggersv1alpha1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	triggersv1beta1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/test"
+)
+
+func TestSink_WithBasePath(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		basePath string
+		target   string
+		wantPath string
+	}{{
+		name:     "no base path",
+		target:   "/webhooks/tekton/hooks",
+		wantPath: "/webhooks/tekton/hooks",
+	}, {
+		name:     "path under base path",
+		basePath: "/webhooks/tekton",
+		target:   "/webhooks/tekton/hooks?env=prod",
+		wantPath: "/hooks",
+	}, {
+		name:     "base path",
+		basePath: "/webhooks/tekton",
+		target:   "/webhooks/tekton",
+		wantPath: "/",
+	}, {
+		name:     "base path with trailing slash",
+		basePath: "/webhooks/tekton",
+		target:   "/webhooks/tekton/",
+		wantPath: "/",
+	}, {
+		name:     "base path stripped by the proxy",
+		basePath: "/webhooks/tekton",
+		target:   "/hooks",
+		wantPath: "/hooks",
+	}, {
+		name:     "probe",
+		basePath: "/webhooks/tekton",
+		target:   "/live",
+		wantPath: "/live",
+	}, {
+		name:     "sibling of base path",
+		basePath: "/webhooks/tekton",
+		target:   "/webhooks/tekton-other",
+		wantPath: "/webhooks/tekton-other",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotPath, gotQuery string
+			next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				gotPath = req.URL.Path
+				gotQuery = req.URL.RawQuery
+				w.WriteHeader(http.StatusAccepted)
+			})
+			req := httptest.NewRequest(http.MethodPost, tc.target, nil)
+			wantQuery := req.URL.RawQuery
+			Sink{BasePath: tc.basePath}.WithBasePath(next).ServeHTTP(httptest.NewRecorder(), req)
+
+			if gotPath != tc.wantPath {
+				t.Errorf("got path %q, want %q", gotPath, tc.wantPath)
+			}
+			if gotQuery != wantQuery {
+				t.Errorf("got query %q, want %q", gotQuery, wantQuery)
+			}
+		})
+	}
+}
+
+func TestSink_ExternalURL(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		basePath string
+		target   string
+		want     string
+	}{{
+		name:   "no base path",
+		target: "/hooks?env=prod",
+		want:   "/hooks?env=prod",
+	}, {
+		name:     "root",
+		basePath: "/webhooks/tekton",
+		target:   "/",
+		want:     "/webhooks/tekton",
+	}, {
+		name:     "path",
+		basePath: "/webhooks/tekton",
+		target:   "/hooks?env=prod",
+		want:     "/webhooks/tekton/hooks?env=prod",
+	}, {
+		name:     "escaped path",
+		basePath: "/webhooks/tekton",
+		target:   "/hooks/a%2Fb",
+		want:     "/webhooks/tekton/hooks/a%2Fb",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.target, nil)
+			if got := (Sink{BasePath: tc.basePath}).externalURL(req.URL).String(); got != tc.want {
+				t.Errorf("externalURL() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecuteInterceptor_BasePath(t *testing.T) {
+	resources := test.Resources{
+		ClusterInterceptors: []*triggersv1alpha1.ClusterInterceptor{cel},
+	}
+	s, _ := getSinkAssets(t, resources, "", nil)
+	s.BasePath = "/webhooks/tekton"
+
+	trigger := triggersv1beta1.Trigger{
+		Spec: triggersv1beta1.TriggerSpec{
+			Interceptors: []*triggersv1beta1.EventInterceptor{{
+				Ref: triggersv1beta1.InterceptorRef{Name: "cel", Kind: triggersv1beta1.ClusterInterceptorKind},
+				Params: []triggersv1beta1.InterceptorParams{{
+					Name:  "filter",
+					Value: test.ToV1JSON(t, "requestURL.parseURL().path == '/webhooks/tekton/hooks'"),
+				}},
+			}},
+		},
+	}
+
+	var req *http.Request
+	Sink{BasePath: s.BasePath}.WithBasePath(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		req = r
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/webhooks/tekton/hooks", nil))
+
+	_, _, iresp, err := s.ExecuteTriggerInterceptors(trigger, req, []byte(`{}`), s.Logger, eventID, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("executeInterceptors: %v", err)
+	}
+	if !iresp.Continue {
+		t.Errorf("Response.continue expected true but got false. Response: %v", iresp)
+	}
+}
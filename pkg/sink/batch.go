@@ -0,0 +1,172 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// BatchPath is the path of the batch endpoint.
+const BatchPath = "/batch"
+
+// BatchResponse is the JSON body of the responses of the batch endpoint.
+type BatchResponse struct {
+	EventListener string `json:"eventListener"`
+	Namespace     string `json:"namespace,omitempty"`
+	// Succeeded is the number of events that were processed with a 2xx status code other than 207
+	// Multi-Status, which the events whose triggers partially failed are responded to with.
+	Succeeded int `json:"succeeded"`
+	// Failed is the number of events that weren't.
+	Failed int `json:"failed"`
+	// Results are the results of the events, in the order of the batch.
+	Results []BatchResult `json:"results"`
+}
+
+// BatchResult is the result of processing one event of a batch.
+type BatchResult struct {
+	// Index is the index of the event in the batch.
+	Index int `json:"index"`
+	// StatusCode is the status code the sink would have responded to the event with on its own.
+	StatusCode int `json:"statusCode"`
+	// Response is the body the sink would have responded to the event with on its own, if any.
+	Response *Response `json:"response,omitempty"`
+}
+
+// HandleBatch processes the events of a request whose body is a JSON array of up to BatchSize events.
+// Each event is processed in order as if it were sent in a request of its own, with the headers of the
+// batch request, except for the event ID headers so that the events don't share an ID. It responds with
+// 200 OK if all the events were processed with a 2xx status code other than 207 Multi-Status, and 207
+// Multi-Status otherwise, with the result of each event.
+func (r Sink) HandleBatch(response http.ResponseWriter, request *http.Request) {
+	payload, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		r.recordCountMetrics(failTag)
+		r.Logger.Errorf("Error reading batch body: %s", err)
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	parsed, err := r.payloadParsers().Parse(payload, request.Header)
+	if err != nil {
+		r.rejectPayload(response, fmt.Sprintf("Invalid batch body: %s", err))
+		return
+	}
+	var events []json.RawMessage
+	if err := json.Unmarshal(parsed, &events); err != nil {
+		r.rejectPayload(response, fmt.Sprintf("Invalid batch body format, expected a JSON array of events: %s", err))
+		return
+	}
+	switch {
+	case len(events) == 0:
+		r.rejectPayload(response, "Invalid batch body: the batch has no events")
+		return
+	case len(events) > r.BatchSize:
+		r.recordCountMetrics(failTag)
+		r.Logger.Errorf("rejecting batch of %d events, more than the batch size of %d", len(events), r.BatchSize)
+		response.Header().Set("Content-Type", "application/json")
+		response.WriteHeader(http.StatusRequestEntityTooLarge)
+		if err := json.NewEncoder(response).Encode(Response{
+			EventListener: r.EventListenerName,
+			Namespace:     r.EventListenerNamespace,
+			ErrorMessage:  fmt.Sprintf("the batch has %d events, more than the batch size of %d", len(events), r.BatchSize),
+		}); err != nil {
+			r.Logger.Errorf("failed to write back batch response: %v", err)
+		}
+		return
+	}
+
+	eventHandler := r.IsValidPayload(http.HandlerFunc(r.HandleEvent))
+	body := BatchResponse{
+		EventListener: r.EventListenerName,
+		Namespace:     r.EventListenerNamespace,
+		Results:       make([]BatchResult, 0, len(events)),
+	}
+	for i, event := range events {
+		rec := &batchEventRecorder{header: http.Header{}}
+		eventHandler.ServeHTTP(rec, r.batchEventRequest(request, event))
+		result := BatchResult{Index: i, StatusCode: rec.statusCode()}
+		var resp Response
+		if err := json.Unmarshal(rec.body.Bytes(), &resp); err == nil {
+			result.Response = &resp
+		}
+		if result.StatusCode >= 200 && result.StatusCode < 300 && result.StatusCode != http.StatusMultiStatus {
+			body.Succeeded++
+		} else {
+			body.Failed++
+		}
+		body.Results = append(body.Results, result)
+	}
+	r.Logger.Infof("processed batch of %d events, %d succeeded and %d failed", len(events), body.Succeeded, body.Failed)
+
+	status := http.StatusOK
+	if body.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(status)
+	if err := json.NewEncoder(response).Encode(body); err != nil {
+		r.Logger.Errorf("failed to write back batch response: %v", err)
+	}
+}
+
+// batchEventRequest returns the request of one event of the batch request. The events are JSON, since
+// the batch is already decoded.
+func (r Sink) batchEventRequest(request *http.Request, event json.RawMessage) *http.Request {
+	req := request.Clone(request.Context())
+	req.Body = ioutil.NopCloser(bytes.NewReader(event))
+	req.ContentLength = int64(len(event))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Del("Content-Encoding")
+	if r.EventIDSource != nil {
+		for _, h := range r.EventIDSource.Headers {
+			req.Header.Del(h)
+		}
+	}
+	return req
+}
+
+// batchEventRecorder records the response to one event of a batch.
+type batchEventRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *batchEventRecorder) Header() http.Header {
+	return b.header
+}
+
+func (b *batchEventRecorder) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *batchEventRecorder) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(p)
+}
+
+func (b *batchEventRecorder) statusCode() int {
+	if b.status == 0 {
+		return http.StatusOK
+	}
+	return b.status
+}
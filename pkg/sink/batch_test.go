@@ -0,0 +1,193 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	triggersv1alpha1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	triggersv1beta1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/test"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/ptr"
+)
+
+func batchSink(t *testing.T) Sink {
+	t.Helper()
+	resources := test.Resources{
+		EventListeners: []*triggersv1beta1.EventListener{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-el",
+				Namespace: namespace,
+				UID:       types.UID(elUID),
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Name: "git-clone",
+					Interceptors: []*triggersv1beta1.TriggerInterceptor{{
+						Ref: triggersv1beta1.InterceptorRef{Name: "cel", Kind: triggersv1beta1.ClusterInterceptorKind},
+						Params: []triggersv1beta1.InterceptorParams{{
+							Name:  "filter",
+							Value: test.ToV1JSON(t, "has(body.head_commit)"),
+						}},
+					}},
+					Bindings: []*triggersv1beta1.EventListenerBinding{
+						{Name: "url", Value: ptr.String("$(body.repository.url)")},
+						{Name: "revision", Value: ptr.String("$(body.head_commit.id)")},
+					},
+					Template: &triggersv1beta1.EventListenerTemplate{Spec: makeGitCloneTTSpec(t, "git-clone-run")},
+				}},
+			},
+		}},
+		ClusterInterceptors: []*triggersv1alpha1.ClusterInterceptor{cel},
+	}
+	r, _ := getSinkAssets(t, resources, "test-el", nil)
+	r.Synchronous = true
+	r.BatchSize = 3
+	return r
+}
+
+func TestHandleBatch(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		body      string
+		wantCode  int
+		wantCodes []int
+	}{{
+		name:      "all succeeded",
+		body:      `[{"head_commit": {"id": "testrevision"}, "repository": {"url": "testurl"}}]`,
+		wantCode:  http.StatusOK,
+		wantCodes: []int{http.StatusCreated},
+	}, {
+		name:      "partial failure",
+		body:      `[{"head_commit": {"id": "testrevision"}, "repository": {"url": "testurl"}}, {"foo": "bar"}, ["not", "an", "object"]]`,
+		wantCode:  http.StatusMultiStatus,
+		wantCodes: []int{http.StatusCreated, http.StatusBadRequest, http.StatusBadRequest},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := batchSink(t)
+			req := httptest.NewRequest(http.MethodPost, BatchPath, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			r.HandleBatch(resp, req)
+
+			if resp.Code != tc.wantCode {
+				t.Fatalf("HandleBatch() status code = %d, want %d: %s", resp.Code, tc.wantCode, resp.Body.String())
+			}
+			var got BatchResponse
+			if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			var gotCodes []int
+			succeeded := 0
+			for i, res := range got.Results {
+				if res.Index != i {
+					t.Errorf("result %d has index %d", i, res.Index)
+				}
+				if res.Response == nil {
+					t.Errorf("result %d has no response", i)
+				}
+				if res.StatusCode < 300 {
+					succeeded++
+				}
+				gotCodes = append(gotCodes, res.StatusCode)
+			}
+			if diff := cmp.Diff(tc.wantCodes, gotCodes); diff != "" {
+				t.Errorf("HandleBatch() status codes -want +got: %s", diff)
+			}
+			if got.Succeeded != succeeded || got.Failed != len(tc.wantCodes)-succeeded {
+				t.Errorf("HandleBatch() got %d succeeded and %d failed, want %d and %d", got.Succeeded, got.Failed, succeeded, len(tc.wantCodes)-succeeded)
+			}
+			if res := got.Results[0].Response; res != nil && len(res.Resources) != 1 {
+				t.Errorf("HandleBatch() got resources %v for the first event, want the git-clone-run TaskRun", res.Resources)
+			}
+		})
+	}
+}
+
+func TestHandleBatch_Rejected(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		body     string
+		wantCode int
+		wantMsg  string
+	}{{
+		name:     "not an array",
+		body:     `{"head_commit": {"id": "testrevision"}}`,
+		wantCode: http.StatusBadRequest,
+		wantMsg:  "expected a JSON array of events",
+	}, {
+		name:     "no events",
+		body:     `[]`,
+		wantCode: http.StatusBadRequest,
+		wantMsg:  "the batch has no events",
+	}, {
+		name:     "too many events",
+		body:     `[{}, {}, {}, {}]`,
+		wantCode: http.StatusRequestEntityTooLarge,
+		wantMsg:  "the batch has 4 events, more than the batch size of 3",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := batchSink(t)
+			req := httptest.NewRequest(http.MethodPost, BatchPath, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			r.HandleBatch(resp, req)
+
+			if resp.Code != tc.wantCode {
+				t.Fatalf("HandleBatch() status code = %d, want %d", resp.Code, tc.wantCode)
+			}
+			var got Response
+			if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if !strings.Contains(got.ErrorMessage, tc.wantMsg) {
+				t.Errorf("HandleBatch() error message = %q, want it to contain %q", got.ErrorMessage, tc.wantMsg)
+			}
+		})
+	}
+}
+
+func TestBatchEventRequest_DropsEventIDHeaders(t *testing.T) {
+	r := Sink{EventIDSource: &EventIDSource{Headers: []string{"X-GitHub-Delivery"}}}
+	req := httptest.NewRequest(http.MethodPost, BatchPath, strings.NewReader(`[{}]`))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("X-GitHub-Delivery", "abc")
+	req.Header.Set("X-GitHub-Event", "push")
+
+	got := r.batchEventRequest(req, json.RawMessage(`{"a": "b"}`))
+	want := http.Header{
+		"Content-Type":   {"application/json"},
+		"X-Github-Event": {"push"},
+	}
+	if diff := cmp.Diff(want, got.Header); diff != "" {
+		t.Errorf("batchEventRequest() header -want +got: %s", diff)
+	}
+	if got.ContentLength != int64(len(`{"a": "b"}`)) {
+		t.Errorf("batchEventRequest() content length = %d", got.ContentLength)
+	}
+	if req.Header.Get("X-GitHub-Delivery") != "abc" {
+		t.Error("batchEventRequest() modified the headers of the batch request")
+	}
+}
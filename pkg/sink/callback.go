@@ -0,0 +1,263 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/pkg/template"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// CallbackSecretKey is the key of the token in the callback secret.
+	CallbackSecretKey = "token"
+
+	// defaultCallbackTimeout is the timeout of the attempts to send a notification if no Timeout is configured.
+	defaultCallbackTimeout = 10 * time.Second
+	// callbackInitialBackoff is the time before the first retry of a notification, doubled after each retry.
+	callbackInitialBackoff = time.Second
+	// callbackMaxBackoff bounds the time between two retries of a notification.
+	callbackMaxBackoff = 30 * time.Second
+	// maxCallbacksInFlight is the number of notifications that can be sent at a time. The others are
+	// dropped, so that a slow receiver can't pile up goroutines in the EventListener.
+	maxCallbacksInFlight = 100
+)
+
+const (
+	// callbackInvalidPayload is the reason of the notifications whose payload template couldn't be resolved.
+	callbackInvalidPayload = "invalid_payload"
+	// callbackQueueFull is the reason of the notifications dropped because too many were in flight.
+	callbackQueueFull = "queue_full"
+	// callbackRequestFailed is the reason of the notifications that still failed after the retries.
+	callbackRequestFailed = "request_failed"
+)
+
+// CallbackContext is what the payload template of a Callback can reference, e.g. $(resources[0].name).
+type CallbackContext struct {
+	// EventID is the ID of the event.
+	EventID string `json:"eventID"`
+	// EventListener is the name of the EventListener that received the event.
+	EventListener string `json:"eventListener"`
+	// Namespace is the namespace of the EventListener.
+	Namespace string `json:"namespace"`
+	// Trigger is the name of the trigger that created the resources.
+	Trigger string `json:"trigger"`
+	// Resources are the created resources, in creation order.
+	Resources []CreatedResource `json:"resources"`
+	// Body is the body of the event, as returned by the interceptors of the trigger. It is left out of the
+	// default payload.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// Callback notifies an external system, e.g. a ChatOps bot or a dashboard, each time a trigger creates
+// resources, by POSTing a JSON payload to URL. The notifications are sent in the background, so they
+// never delay or fail the events, and are retried with an exponential backoff when the request fails or
+// the receiver responds with 429 or a 5xx status code.
+//
+// A nil *Callback sends no notifications.
+type Callback struct {
+	// URL is the URL the notifications are POSTed to.
+	URL string
+	// Payload, if set, is the JSON template of the notifications, resolved with template.ResolvePayload
+	// against a CallbackContext. Defaults to the CallbackContext without the body of the event.
+	Payload json.RawMessage
+	// SecretName, if set, is the name of the secret in the namespace of the EventListener whose
+	// CallbackSecretKey is sent as the bearer token of the notifications.
+	SecretName string
+	// SecretGetter gets the secret.
+	SecretGetter interceptors.SecretGetter
+	// Timeout is the timeout of each attempt to send a notification. Defaults to 10s.
+	Timeout time.Duration
+	// Retries is the number of times a notification that failed is sent again.
+	Retries int
+	// Client sends the notifications. Defaults to http.DefaultClient.
+	Client *http.Client
+
+	mu       sync.Mutex
+	inFlight int
+	sleep    func(time.Duration)
+}
+
+// callbackStatusError is returned when the receiver of a notification responds with a non 2xx status code.
+type callbackStatusError struct {
+	code int
+}
+
+func (e *callbackStatusError) Error() string {
+	return fmt.Sprintf("callback responded with status code %d", e.code)
+}
+
+// payload returns the payload of the notification for cc.
+func (c *Callback) payload(cc CallbackContext) ([]byte, error) {
+	if len(c.Payload) == 0 {
+		cc.Body = nil
+		return json.Marshal(cc)
+	}
+	return template.ResolvePayload(c.Payload, cc)
+}
+
+func (c *Callback) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return defaultCallbackTimeout
+	}
+	return c.Timeout
+}
+
+func (c *Callback) client() *http.Client {
+	if c.Client == nil {
+		return http.DefaultClient
+	}
+	return c.Client
+}
+
+func (c *Callback) wait(d time.Duration) {
+	if c.sleep == nil {
+		time.Sleep(d)
+		return
+	}
+	c.sleep(d)
+}
+
+// acquire reserves a place for a notification in flight. It returns false if there is none left.
+func (c *Callback) acquire() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.inFlight >= maxCallbacksInFlight {
+		return false
+	}
+	c.inFlight++
+	return true
+}
+
+func (c *Callback) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inFlight--
+}
+
+// send POSTs the payload to the URL, retrying the failed attempts. namespace is the namespace of the
+// callback secret.
+func (c *Callback) send(ctx context.Context, namespace string, payload []byte) error {
+	var token string
+	if c.SecretName != "" {
+		secret, err := c.SecretGetter.Get(ctx, namespace, &triggersv1.SecretRef{
+			SecretName: c.SecretName,
+			SecretKey:  CallbackSecretKey,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get the callback secret %s: %w", c.SecretName, err)
+		}
+		token = string(secret)
+	}
+
+	backoff := callbackInitialBackoff
+	for attempt := 0; ; attempt++ {
+		err := c.post(ctx, payload, token)
+		if err == nil || !retryableCallbackError(err) || attempt >= c.Retries {
+			return err
+		}
+		c.wait(backoff)
+		if backoff *= 2; backoff > callbackMaxBackoff {
+			backoff = callbackMaxBackoff
+		}
+	}
+}
+
+func (c *Callback) post(ctx context.Context, payload []byte, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body so that the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &callbackStatusError{code: resp.StatusCode}
+	}
+	return nil
+}
+
+// retryableCallbackError returns whether a notification that failed with err may succeed if sent again.
+func retryableCallbackError(err error) bool {
+	var statusErr *callbackStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code == http.StatusTooManyRequests || statusErr.code >= 500
+	}
+	return true
+}
+
+// notifyCallback sends the notification that the trigger created resources for the event in the
+// background. Its failures are logged and counted, but never fail the event.
+func (r Sink) notifyCallback(triggerName, eventID string, body []byte, created []*unstructured.Unstructured, log *zap.SugaredLogger) {
+	if r.Callback == nil {
+		return
+	}
+	cc := CallbackContext{
+		EventID:       eventID,
+		EventListener: r.EventListenerName,
+		Namespace:     r.EventListenerNamespace,
+		Trigger:       triggerName,
+		Resources:     createdResources(triggerName, created),
+	}
+	if json.Valid(body) {
+		cc.Body = body
+	}
+
+	payload, err := r.Callback.payload(cc)
+	if err != nil {
+		log.Errorf("failed to resolve the callback payload: %v", err)
+		r.recordCallbackFailedMetrics(triggerName, callbackInvalidPayload)
+		return
+	}
+	if !r.Callback.acquire() {
+		log.Warnf("dropping the callback notification, %d notifications are already in flight", maxCallbacksInFlight)
+		r.recordCallbackFailedMetrics(triggerName, callbackQueueFull)
+		return
+	}
+	go func() {
+		defer r.Callback.release()
+		if err := r.Callback.send(context.Background(), r.EventListenerNamespace, payload); err != nil {
+			log.Errorf("failed to send the callback notification to %s: %v", r.Callback.URL, err)
+			r.recordCallbackFailedMetrics(triggerName, callbackRequestFailed)
+			return
+		}
+		log.Debugf("sent the callback notification to %s", r.Callback.URL)
+	}()
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	triggersv1beta1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"go.uber.org/zap/zaptest"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// secretGetterFunc is a SecretGetter returning the secrets of a function.
+type secretGetterFunc func(ns string, sr *triggersv1beta1.SecretRef) ([]byte, error)
+
+func (f secretGetterFunc) Get(_ context.Context, ns string, sr *triggersv1beta1.SecretRef) ([]byte, error) {
+	return f(ns, sr)
+}
+
+// callbackServer returns a server responding to the callbacks with the given status codes, then 200, along
+// with the requests it received and their bodies.
+func callbackServer(t *testing.T, codes ...int) (*httptest.Server, chan *http.Request, chan []byte) {
+	t.Helper()
+	var mu sync.Mutex
+	requests := make(chan *http.Request, 10)
+	bodies := make(chan []byte, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		b, _ := ioutil.ReadAll(req.Body)
+		requests <- req
+		bodies <- b
+		if len(codes) > 0 {
+			w.WriteHeader(codes[0])
+			codes = codes[1:]
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts, requests, bodies
+}
+
+func createdPipelineRun() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("tekton.dev/v1beta1")
+	obj.SetKind("PipelineRun")
+	obj.SetNamespace(namespace)
+	obj.SetName("build-xk2lp")
+	obj.SetUID(types.UID("7fa1b0c5"))
+	return obj
+}
+
+func TestCallback_Payload(t *testing.T) {
+	cc := CallbackContext{
+		EventID:       "1234",
+		EventListener: "my-el",
+		Namespace:     namespace,
+		Trigger:       "my-trigger",
+		Resources:     createdResources("my-trigger", []*unstructured.Unstructured{createdPipelineRun()}),
+		Body:          json.RawMessage(`{"repository": {"name": "triggers"}}`),
+	}
+	for _, tc := range []struct {
+		name    string
+		payload string
+		want    string
+	}{{
+		name: "default payload",
+		want: `{"eventID":"1234","eventListener":"my-el","namespace":"foo","trigger":"my-trigger","resources":[{"apiVersion":"tekton.dev/v1beta1","kind":"PipelineRun","namespace":"foo","name":"build-xk2lp","uid":"7fa1b0c5","trigger":"my-trigger"}]}`,
+	}, {
+		name:    "template",
+		payload: `{"text": "$(body.repository.name): $(trigger) created $(resources[0].kind) $(resources[0].name)", "id": "$(eventID)"}`,
+		want:    `{"id":"1234","text":"triggers: my-trigger created PipelineRun build-xk2lp"}`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Callback{Payload: json.RawMessage(tc.payload)}
+			got, err := c.payload(cc)
+			if err != nil {
+				t.Fatalf("payload() returned error: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("payload() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCallback_Send(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		codes        []int
+		retries      int
+		wantErr      bool
+		wantRequests int
+		wantSleeps   []time.Duration
+	}{{
+		name:         "succeeds",
+		retries:      3,
+		wantRequests: 1,
+	}, {
+		name:         "succeeds after retries",
+		codes:        []int{http.StatusServiceUnavailable, http.StatusTooManyRequests},
+		retries:      3,
+		wantRequests: 3,
+		wantSleeps:   []time.Duration{time.Second, 2 * time.Second},
+	}, {
+		name:         "fails after the retries",
+		codes:        []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable},
+		retries:      2,
+		wantErr:      true,
+		wantRequests: 3,
+		wantSleeps:   []time.Duration{time.Second, 2 * time.Second},
+	}, {
+		name:         "client errors are not retried",
+		codes:        []int{http.StatusBadRequest},
+		retries:      3,
+		wantErr:      true,
+		wantRequests: 1,
+	}, {
+		name:         "no retries",
+		codes:        []int{http.StatusServiceUnavailable},
+		wantErr:      true,
+		wantRequests: 1,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ts, requests, bodies := callbackServer(t, tc.codes...)
+			clock := &fakeClock{t: time.Now()}
+			c := &Callback{URL: ts.URL, Retries: tc.retries, sleep: clock.sleep}
+
+			err := c.send(context.Background(), namespace, []byte(`{"eventID":"1234"}`))
+			if (err != nil) != tc.wantErr {
+				t.Errorf("send() returned error %v, want error: %t", err, tc.wantErr)
+			}
+			if len(requests) != tc.wantRequests {
+				t.Errorf("send() sent %d requests, want %d", len(requests), tc.wantRequests)
+			}
+			if diff := cmp.Diff(tc.wantSleeps, clock.sleeps); diff != "" {
+				t.Errorf("send() backoff -want +got: %s", diff)
+			}
+			req, body := <-requests, <-bodies
+			if req.Method != http.MethodPost || req.Header.Get("Content-Type") != "application/json" || req.Header.Get("Authorization") != "" {
+				t.Errorf("send() sent a %s request with headers %v", req.Method, req.Header)
+			}
+			if string(body) != `{"eventID":"1234"}` {
+				t.Errorf("send() sent body %s", body)
+			}
+		})
+	}
+}
+
+func TestCallback_SendWithSecret(t *testing.T) {
+	ts, requests, _ := callbackServer(t)
+	var gotNS string
+	var gotRef *triggersv1beta1.SecretRef
+	c := &Callback{
+		URL:        ts.URL,
+		SecretName: "callback-token",
+		SecretGetter: secretGetterFunc(func(ns string, sr *triggersv1beta1.SecretRef) ([]byte, error) {
+			gotNS, gotRef = ns, sr
+			return []byte("s3cr3t"), nil
+		}),
+	}
+	if err := c.send(context.Background(), namespace, []byte(`{}`)); err != nil {
+		t.Fatalf("send() returned error: %v", err)
+	}
+	if gotNS != namespace || gotRef.SecretName != "callback-token" || gotRef.SecretKey != CallbackSecretKey {
+		t.Errorf("send() got secret %v in namespace %s", gotRef, gotNS)
+	}
+	if got := (<-requests).Header.Get("Authorization"); got != "Bearer s3cr3t" {
+		t.Errorf("send() sent Authorization header %q", got)
+	}
+
+	c.SecretGetter = secretGetterFunc(func(string, *triggersv1beta1.SecretRef) ([]byte, error) {
+		return nil, errors.New("secret not found")
+	})
+	if err := c.send(context.Background(), namespace, []byte(`{}`)); err == nil {
+		t.Error("send() returned no error for a missing secret")
+	}
+	if len(requests) != 0 {
+		t.Error("send() sent a request without the token of the secret")
+	}
+}
+
+func TestCallback_Timeout(t *testing.T) {
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-done:
+		}
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	c := &Callback{URL: ts.URL, Timeout: 10 * time.Millisecond}
+	if err := c.send(context.Background(), namespace, []byte(`{}`)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("send() returned error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestNotifyCallback(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	recorder, err := NewRecorder()
+	if err != nil {
+		t.Fatalf("NewRecorder() returned error: %v", err)
+	}
+	ts, requests, bodies := callbackServer(t)
+	r := Sink{
+		EventListenerName:      "my-el",
+		EventListenerNamespace: namespace,
+		Logger:                 logger,
+		Recorder:               recorder,
+		Callback:               &Callback{URL: ts.URL, Payload: json.RawMessage(`{"text": "$(trigger) created $(resources[0].name) for $(body.ref)"}`)},
+	}
+
+	r.notifyCallback("my-trigger", eventID, []byte(`{"ref": "main"}`), []*unstructured.Unstructured{createdPipelineRun()}, logger)
+	select {
+	case <-requests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("notifyCallback() sent no notification")
+	}
+	if got := string(<-bodies); got != `{"text":"my-trigger created build-xk2lp for main"}` {
+		t.Errorf("notifyCallback() sent body %s", got)
+	}
+
+	// Notifications whose payload can't be resolved or that exceed the notifications in flight are dropped.
+	r.Callback.Payload = json.RawMessage(`{"text": "$(body.missing)"}`)
+	r.notifyCallback("my-trigger", eventID, []byte(`{"ref": "main"}`), nil, logger)
+	r.Callback.Payload = nil
+	r.Callback.inFlight = maxCallbacksInFlight
+	r.notifyCallback("my-trigger", eventID, []byte(`{"ref": "main"}`), nil, logger)
+	select {
+	case <-requests:
+		t.Error("notifyCallback() sent a notification that should have been dropped")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
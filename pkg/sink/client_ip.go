@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+)
+
+// TrustedProxies are the networks of the proxies, e.g. load balancers, allowed to set the client IP of the
+// requests they forward with their forwarding header. The headers of the requests from other peers are ignored,
+// so that senders can't spoof their IP address.
+type TrustedProxies []*net.IPNet
+
+func (p TrustedProxies) trusted(ip net.IP) bool {
+	for _, n := range p {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the IP address of the sender of the request. It is the address of the peer of the
+// connection, unless the peer is a trusted proxy: then the addresses of header, the forwarding header that
+// the proxies set, X-Forwarded-For if empty or Forwarded, are walked from the closest to the farthest, and
+// the first one that is not a trusted proxy is returned. The walk stops at the farthest trusted address if
+// an address is missing or invalid, e.g. an obfuscated identifier of the Forwarded header. The other
+// forwarding header is ignored: the proxies don't set it, so it is the one of the sender.
+func (p TrustedProxies) ClientIP(request *http.Request, header string) string {
+	peer, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		peer = request.RemoteAddr
+	}
+	ip := net.ParseIP(peer)
+	if ip == nil || !p.trusted(ip) {
+		return peer
+	}
+
+	var hops []string
+	if header == triggers.ForwardedHeader {
+		hops = forwardedFor(request.Header.Values(triggers.ForwardedHeader))
+	} else {
+		hops = splitHeaderList(request.Header.Values(triggers.XForwardedForHeader))
+	}
+	for i := len(hops) - 1; i >= 0 && p.trusted(ip); i-- {
+		hop := parseHopIP(hops[i])
+		if hop == nil {
+			break
+		}
+		ip = hop
+	}
+	return ip.String()
+}
+
+// forwardedFor returns the for parameters of the elements of the Forwarded header values (RFC 7239), in
+// order. An element without a for parameter has an empty one.
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, element := range splitHeaderList(values) {
+		var hop string
+		for _, pair := range strings.Split(element, ";") {
+			kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+			if len(kv) == 2 && strings.EqualFold(kv[0], "for") {
+				hop = strings.Trim(kv[1], `"`)
+			}
+		}
+		hops = append(hops, hop)
+	}
+	return hops
+}
+
+func splitHeaderList(values []string) []string {
+	var list []string
+	for _, v := range values {
+		for _, s := range strings.Split(v, ",") {
+			list = append(list, strings.TrimSpace(s))
+		}
+	}
+	return list
+}
+
+// parseHopIP returns the IP address of a hop of a forwarding header, which may have a port and, for IPv6
+// addresses, brackets, e.g. "[2001:db8::17]:4711". It returns nil if the hop is not an IP address.
+func parseHopIP(hop string) net.IP {
+	if host, _, err := net.SplitHostPort(hop); err == nil {
+		hop = host
+	}
+	return net.ParseIP(strings.TrimSuffix(strings.TrimPrefix(hop, "["), "]"))
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+)
+
+func TestTrustedProxies_ClientIP(t *testing.T) {
+	proxies, err := triggers.ParseTrustedProxies("10.0.0.0/8, 2001:db8:1::/48")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name        string
+		proxies     TrustedProxies
+		remoteAddr  string
+		proxyHeader string
+		header      http.Header
+		want        string
+	}{{
+		name:       "no trusted proxies",
+		remoteAddr: "10.1.2.3:41234",
+		header:     http.Header{"X-Forwarded-For": []string{"203.0.113.7"}},
+		want:       "10.1.2.3",
+	}, {
+		name:       "untrusted peer",
+		proxies:    proxies,
+		remoteAddr: "198.51.100.1:41234",
+		header:     http.Header{"X-Forwarded-For": []string{"203.0.113.7"}},
+		want:       "198.51.100.1",
+	}, {
+		name:       "trusted peer without headers",
+		proxies:    proxies,
+		remoteAddr: "10.1.2.3:41234",
+		want:       "10.1.2.3",
+	}, {
+		name:       "X-Forwarded-For",
+		proxies:    proxies,
+		remoteAddr: "10.1.2.3:41234",
+		header:     http.Header{"X-Forwarded-For": []string{"203.0.113.7"}},
+		want:       "203.0.113.7",
+	}, {
+		name:       "spoofed X-Forwarded-For entries are skipped",
+		proxies:    proxies,
+		remoteAddr: "10.1.2.3:41234",
+		header:     http.Header{"X-Forwarded-For": []string{"192.0.2.66, 203.0.113.7", "10.4.5.6"}},
+		want:       "203.0.113.7",
+	}, {
+		name:       "all hops trusted",
+		proxies:    proxies,
+		remoteAddr: "10.1.2.3:41234",
+		header:     http.Header{"X-Forwarded-For": []string{"10.7.8.9, 10.4.5.6"}},
+		want:       "10.7.8.9",
+	}, {
+		name:       "invalid hop",
+		proxies:    proxies,
+		remoteAddr: "10.1.2.3:41234",
+		header:     http.Header{"X-Forwarded-For": []string{"203.0.113.7, garbage, 10.4.5.6"}},
+		want:       "10.4.5.6",
+	}, {
+		name:       "spoofed Forwarded is ignored",
+		proxies:    proxies,
+		remoteAddr: "10.1.2.3:41234",
+		header: http.Header{
+			"Forwarded":       []string{"for=192.0.2.66"},
+			"X-Forwarded-For": []string{"203.0.113.7"},
+		},
+		want: "203.0.113.7",
+	}, {
+		name:        "Forwarded",
+		proxies:     proxies,
+		remoteAddr:  "10.1.2.3:41234",
+		proxyHeader: "Forwarded",
+		header: http.Header{
+			"Forwarded":       []string{`for=192.0.2.60;proto=https, for="[2001:db8:cafe::17]:4711";by=10.4.5.6`},
+			"X-Forwarded-For": []string{"203.0.113.7"},
+		},
+		want: "2001:db8:cafe::17",
+	}, {
+		name:        "spoofed X-Forwarded-For is ignored",
+		proxies:     proxies,
+		remoteAddr:  "10.1.2.3:41234",
+		proxyHeader: "Forwarded",
+		header:      http.Header{"X-Forwarded-For": []string{"192.0.2.66"}},
+		want:        "10.1.2.3",
+	}, {
+		name:        "obfuscated Forwarded identifier",
+		proxies:     proxies,
+		remoteAddr:  "10.1.2.3:41234",
+		proxyHeader: "Forwarded",
+		header:      http.Header{"Forwarded": []string{"for=192.0.2.60, for=_hidden, For=10.4.5.6"}},
+		want:        "10.4.5.6",
+	}, {
+		name:       "IPv6 trusted peer",
+		proxies:    proxies,
+		remoteAddr: "[2001:db8:1::5]:41234",
+		header:     http.Header{"X-Forwarded-For": []string{"203.0.113.7"}},
+		want:       "203.0.113.7",
+	}, {
+		name:       "remote address without port",
+		proxies:    proxies,
+		remoteAddr: "198.51.100.1",
+		want:       "198.51.100.1",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for k, v := range tc.header {
+				req.Header[k] = v
+			}
+			if got := tc.proxies.ClientIP(req, tc.proxyHeader); got != tc.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
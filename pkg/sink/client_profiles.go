@@ -0,0 +1,200 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+
+	"github.com/tektoncd/triggers/pkg/apis/config"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	dynamicClientset "github.com/tektoncd/triggers/pkg/client/dynamic/clientset"
+	"github.com/tektoncd/triggers/pkg/client/dynamic/clientset/tekton"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"go.uber.org/zap"
+	discoveryclient "k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// ClientProfiles creates the clients of the triggers that reference a client profile. The clients are
+// kept and created again when the kubeconfig of their profile changes. The clients of a profile with
+// rate limits share one rate limiter, so that the limits apply across events and service accounts.
+type ClientProfiles struct {
+	// Profiles are the client profiles, by name.
+	Profiles map[string]config.ClientProfile
+	// Config is the REST config the clients of the profiles without a kubeconfig are created from.
+	// Defaults to the in cluster config.
+	Config *rest.Config
+	// SecretGetter gets the kubeconfigs of the profiles from the secrets in Namespace.
+	SecretGetter interceptors.SecretGetter
+	// Namespace is the namespace of the kubeconfig secrets, the namespace of the EventListener.
+	Namespace string
+
+	mu       sync.Mutex
+	clients  map[profileClientsKey]*profileClients
+	limiters map[string]flowcontrol.RateLimiter
+}
+
+// profileClientsKey identifies the clients of a profile impersonating a service account, if any.
+type profileClientsKey struct {
+	profile, serviceAccount, namespace string
+}
+
+// profileClients are the clients of a profile, created from the kubeconfig with the checksum.
+type profileClients struct {
+	kubeconfigChecksum [sha256.Size]byte
+	discoveryClient    discoveryclient.ServerResourcesInterface
+	dynamicClient      dynamic.Interface
+}
+
+// NewClientProfiles returns the ClientProfiles of the profiles.
+func NewClientProfiles(profiles *config.ClientProfiles, cfg *rest.Config, secretGetter interceptors.SecretGetter, namespace string) *ClientProfiles {
+	p := &ClientProfiles{Config: cfg, SecretGetter: secretGetter, Namespace: namespace}
+	if profiles != nil {
+		p.Profiles = profiles.Profiles
+	}
+	return p
+}
+
+// Clients returns the clients of the profile with the name for the triggers in namespace. If sa is set,
+// they impersonate the service account sa in namespace rather than the user of the profile.
+func (p *ClientProfiles) Clients(ctx context.Context, name, sa, namespace string) (discoveryclient.ServerResourcesInterface, dynamic.Interface, error) {
+	profile, ok := p.Profiles[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("client profile %q is not defined", name)
+	}
+	// The profile may have changed since the trigger was admitted.
+	if !profile.Allows(namespace) {
+		return nil, nil, fmt.Errorf("client profile %q is not allowed in namespace %s", name, namespace)
+	}
+	var kubeconfig []byte
+	if profile.Kubeconfig != nil {
+		var err error
+		kubeconfig, err = p.SecretGetter.Get(ctx, p.Namespace, &triggersv1.SecretRef{SecretName: profile.Kubeconfig.Name, SecretKey: profile.Kubeconfig.Key})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get the kubeconfig of client profile %q: %w", name, err)
+		}
+	}
+	checksum := sha256.Sum256(kubeconfig)
+
+	key := profileClientsKey{profile: name, serviceAccount: sa, namespace: namespace}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c, ok := p.clients[key]; ok && c.kubeconfigChecksum == checksum {
+		return c.discoveryClient, c.dynamicClient, nil
+	}
+
+	cfg, err := p.restConfig(name, profile, kubeconfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the REST config of client profile %q: %w", name, err)
+	}
+	if sa != "" {
+		cfg.Impersonate = rest.ImpersonationConfig{
+			UserName: fmt.Sprintf("system:serviceaccount:%s:%s", namespace, sa),
+		}
+	}
+	dc, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create the dynamic client of client profile %q: %w", name, err)
+	}
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create the kube client of client profile %q: %w", name, err)
+	}
+	c := &profileClients{
+		kubeconfigChecksum: checksum,
+		discoveryClient:    kubeClient.Discovery(),
+		dynamicClient:      dynamicClientset.New(tekton.WithClient(dc)),
+	}
+	if p.clients == nil {
+		p.clients = map[profileClientsKey]*profileClients{}
+	}
+	p.clients[key] = c
+	return c.discoveryClient, c.dynamicClient, nil
+}
+
+// restConfig returns the REST config of the profile with the name: its kubeconfig, or Config, impersonating the user of
+// the profile, with its rate limits.
+func (p *ClientProfiles) restConfig(name string, profile config.ClientProfile, kubeconfig []byte) (*rest.Config, error) {
+	var cfg *rest.Config
+	var err error
+	switch {
+	case profile.Kubeconfig != nil:
+		if cfg, err = clientcmd.RESTConfigFromKubeConfig(kubeconfig); err != nil {
+			return nil, err
+		}
+	case p.Config != nil:
+		cfg = rest.CopyConfig(p.Config)
+	default:
+		if cfg, err = rest.InClusterConfig(); err != nil {
+			return nil, err
+		}
+	}
+	if profile.Impersonate != nil {
+		cfg.Impersonate = rest.ImpersonationConfig{
+			UserName: profile.Impersonate.User,
+			Groups:   profile.Impersonate.Groups,
+		}
+	}
+	if profile.QPS > 0 {
+		cfg.QPS = profile.QPS
+		if profile.Burst > 0 {
+			cfg.Burst = profile.Burst
+		}
+		cfg.RateLimiter = p.rateLimiter(name, cfg)
+	}
+	return cfg, nil
+}
+
+// rateLimiter returns the rate limiter shared by the clients of the profile with the name, created with
+// the QPS and Burst of cfg. It must be called with p.mu held.
+func (p *ClientProfiles) rateLimiter(name string, cfg *rest.Config) flowcontrol.RateLimiter {
+	if l, ok := p.limiters[name]; ok {
+		return l
+	}
+	burst := cfg.Burst
+	if burst == 0 {
+		burst = rest.DefaultBurst
+	}
+	l := flowcontrol.NewTokenBucketRateLimiter(cfg.QPS, burst)
+	if p.limiters == nil {
+		p.limiters = map[string]flowcontrol.RateLimiter{}
+	}
+	p.limiters[name] = l
+	return l
+}
+
+// resourceClients returns the clients creating the resources of a trigger: those of its client profile if
+// it has one, or else the default clients, impersonating its service account in namespace if it has one.
+func (r Sink) resourceClients(ctx context.Context, profile, sa, namespace string, log *zap.SugaredLogger) (discoveryclient.ServerResourcesInterface, dynamic.Interface, error) {
+	switch {
+	case profile != "":
+		if r.ClientProfiles == nil {
+			return nil, nil, fmt.Errorf("client profile %q is not defined", profile)
+		}
+		return r.ClientProfiles.Clients(ctx, profile, sa, namespace)
+	case sa != "":
+		return r.Auth.OverrideAuthentication(sa, namespace, log, r.DiscoveryClient, r.DynamicClient)
+	}
+	return r.DiscoveryClient, r.DynamicClient, nil
+}
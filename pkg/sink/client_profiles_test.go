@@ -0,0 +1,167 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/triggers/pkg/apis/config"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func kubeconfig(server string) []byte {
+	return []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: remote
+  cluster:
+    server: %s
+users:
+- name: remote
+  user:
+    token: let-me-in
+contexts:
+- name: remote
+  context:
+    cluster: remote
+    user: remote
+current-context: remote
+`, server))
+}
+
+func TestClientProfiles_restConfig(t *testing.T) {
+	base := &rest.Config{Host: "https://local.example.com", QPS: 5, Burst: 10}
+	p := NewClientProfiles(nil, base, nil, namespace)
+	for _, tc := range []struct {
+		name       string
+		profile    config.ClientProfile
+		kubeconfig []byte
+		want       rest.Config
+	}{{
+		name:    "base config",
+		profile: config.ClientProfile{},
+		want:    rest.Config{Host: "https://local.example.com", QPS: 5, Burst: 10},
+	}, {
+		name:       "kubeconfig",
+		profile:    config.ClientProfile{Kubeconfig: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "remote-cluster"}, Key: "kubeconfig"}},
+		kubeconfig: kubeconfig("https://remote.example.com"),
+		want:       rest.Config{Host: "https://remote.example.com", BearerToken: "let-me-in"},
+	}, {
+		name:    "impersonation and rate limits",
+		profile: config.ClientProfile{Impersonate: &config.ClientImpersonation{User: "deployer", Groups: []string{"deployers"}}, QPS: 50, Burst: 100},
+		want: rest.Config{
+			Host:        "https://local.example.com",
+			Impersonate: rest.ImpersonationConfig{UserName: "deployer", Groups: []string{"deployers"}},
+			QPS:         50,
+			Burst:       100,
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := p.restConfig(tc.name, tc.profile, tc.kubeconfig)
+			if err != nil {
+				t.Fatalf("restConfig() = %v", err)
+			}
+			if got.Host != tc.want.Host || got.BearerToken != tc.want.BearerToken || got.QPS != tc.want.QPS || got.Burst != tc.want.Burst {
+				t.Errorf("restConfig() = host %s, token %q, qps %v, burst %d, want host %s, token %q, qps %v, burst %d",
+					got.Host, got.BearerToken, got.QPS, got.Burst, tc.want.Host, tc.want.BearerToken, tc.want.QPS, tc.want.Burst)
+			}
+			if d := cmp.Diff(tc.want.Impersonate, got.Impersonate); d != "" {
+				t.Errorf("restConfig() impersonation (-want, +got): %s", d)
+			}
+		})
+	}
+	if base.Impersonate.UserName != "" || base.QPS != 5 {
+		t.Errorf("restConfig() modified the base config: %+v", base)
+	}
+
+	// The clients of a profile with rate limits share one rate limiter, whatever their kubeconfig and
+	// service account.
+	limited := config.ClientProfile{QPS: 50, Burst: 100}
+	first, _ := p.restConfig("limited", limited, nil)
+	second, _ := p.restConfig("limited", limited, kubeconfig("https://remote.example.com"))
+	other, _ := p.restConfig("other", limited, nil)
+	if first.RateLimiter == nil || first.RateLimiter != second.RateLimiter {
+		t.Error("restConfig() didn't share the rate limiter of a profile")
+	}
+	if other.RateLimiter == first.RateLimiter {
+		t.Error("restConfig() shared the rate limiter of a profile with another profile")
+	}
+	if base.RateLimiter != nil {
+		t.Errorf("restConfig() set the rate limiter of the base config")
+	}
+}
+
+func TestClientProfiles_Clients(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "remote-cluster", Namespace: namespace},
+		Data:       map[string][]byte{"kubeconfig": kubeconfig("https://remote.example.com")},
+	}
+	kubeClient := fake.NewSimpleClientset(secret)
+	p := NewClientProfiles(&config.ClientProfiles{Profiles: map[string]config.ClientProfile{
+		"remote": {
+			AllowedNamespaces: []string{namespace},
+			Kubeconfig:        &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "remote-cluster"}, Key: "kubeconfig"},
+		},
+	}}, &rest.Config{Host: "https://local.example.com"}, interceptors.NewSecretGetter(kubeClient.CoreV1(), 0), namespace)
+	ctx := context.Background()
+
+	_, dynamicClient, err := p.Clients(ctx, "remote", "", namespace)
+	if err != nil {
+		t.Fatalf("Clients() = %v", err)
+	}
+	if _, again, _ := p.Clients(ctx, "remote", "", namespace); again != dynamicClient {
+		t.Error("Clients() created new clients for an unchanged kubeconfig")
+	}
+	if _, impersonating, _ := p.Clients(ctx, "remote", "sa", namespace); impersonating == dynamicClient {
+		t.Error("Clients() returned the same clients with and without a service account")
+	}
+
+	secret.Data["kubeconfig"] = kubeconfig("https://rotated.example.com")
+	if _, err := kubeClient.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, rotated, _ := p.Clients(ctx, "remote", "", namespace); rotated == dynamicClient {
+		t.Error("Clients() kept the clients of a changed kubeconfig")
+	}
+
+	if _, _, err := p.Clients(ctx, "missing", "", namespace); err == nil || !strings.Contains(err.Error(), `client profile "missing" is not defined`) {
+		t.Errorf("Clients() = %v, want an undefined profile error", err)
+	}
+	if _, _, err := p.Clients(ctx, "remote", "", "other"); err == nil || !strings.Contains(err.Error(), `client profile "remote" is not allowed in namespace other`) {
+		t.Errorf("Clients() = %v, want a not allowed profile error", err)
+	}
+}
+
+func TestResourceClients(t *testing.T) {
+	r := Sink{}
+	if _, _, err := r.resourceClients(context.Background(), "remote", "", namespace, zap.NewNop().Sugar()); err == nil {
+		t.Error("resourceClients() was expected to fail for a profile without client profiles")
+	}
+	discoveryClient, dynamicClient, err := r.resourceClients(context.Background(), "", "", namespace, zap.NewNop().Sugar())
+	if err != nil || discoveryClient != nil || dynamicClient != nil {
+		t.Errorf("resourceClients() = %v, %v, %v, want the default clients", discoveryClient, dynamicClient, err)
+	}
+}
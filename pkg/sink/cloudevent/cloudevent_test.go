@@ -70,3 +70,36 @@ func TestSendCloudEvents(t *testing.T) {
 	received := <-eventCh
 	testCE.AssertEventEquals(t, e, received)
 }
+
+func TestResourceEventSend(t *testing.T) {
+	client, eventCh := test.NewMockSenderClient(t, 1)
+
+	ctType := "application/json"
+	subject := "/apis/tekton.dev/v1beta1/namespaces/el-ns/PipelineRun/build-xk2lp"
+	e := event.Event{
+		Context: event.EventContextV1{
+			Type:            ResourceCreatedType,
+			Source:          *types.ParseURIRef("/triggers/github"),
+			ID:              "1234567-my-trigger-0",
+			DataContentType: &ctType,
+			Subject:         &subject,
+		}.AsV1(),
+		DataEncoded: []byte(`{"name":"build-xk2lp"}`),
+	}
+
+	resource := ResourceEvent{
+		ID:        "1234567-my-trigger-0",
+		Type:      ResourceCreatedType,
+		Source:    "/triggers/github",
+		Subject:   subject,
+		TargetURI: "http://localhost",
+		Client:    client,
+		Data:      map[string]string{"name": "build-xk2lp"},
+	}
+
+	if err := resource.Send(); err != nil {
+		t.Fatalf("Send() returned error: %v", err)
+	}
+	received := <-eventCh
+	testCE.AssertEventEquals(t, e, received)
+}
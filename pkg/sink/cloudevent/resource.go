@@ -0,0 +1,62 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudevent
+
+import (
+	"context"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+const (
+	// ResourceCreatedType is the type of the cloud events sent when a Trigger creates a resource.
+	ResourceCreatedType = "dev.tekton.triggers.resource.created"
+	// ResourceFailedType is the type of the cloud events sent when a Trigger fails to create a resource.
+	ResourceFailedType = "dev.tekton.triggers.resource.failed"
+)
+
+// ResourceEvent defines the parameters needed to send the cloud event of the outcome of the creation
+// of a resource.
+type ResourceEvent struct {
+	ID        string
+	Type      string
+	Source    string
+	Subject   string
+	TargetURI string
+	Client    CEClient
+	// Data is encoded to JSON.
+	Data interface{}
+}
+
+// Send sends the cloud event, retrying it with an exponential backoff like SendCloudEvents.
+func (r ResourceEvent) Send() error {
+	event := cloudevents.NewEvent()
+	event.SetID(r.ID)
+	event.SetType(r.Type)
+	event.SetSource(r.Source)
+	event.SetSubject(r.Subject)
+	if err := event.SetData(cloudevents.ApplicationJSON, r.Data); err != nil {
+		return err
+	}
+
+	result := r.Client.Send(cloudevents.ContextWithTarget(cloudevents.ContextWithRetriesExponentialBackoff(context.Background(), 10*time.Millisecond, 10), r.TargetURI), event)
+	if !cloudevents.IsACK(result) {
+		return result
+	}
+	return nil
+}
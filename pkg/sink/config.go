@@ -0,0 +1,317 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/pkg/template"
+)
+
+const (
+	// ConfigPath is the path of the config endpoint.
+	ConfigPath = "/config"
+	// ConfigSecretKey is the key of the token in the config endpoint secret.
+	ConfigSecretKey = "token"
+)
+
+// configSensitiveParts are the parts of the names of the params, headers and fields whose values are redacted
+// from the config, such as githubToken, Authorization or webhookSecret.
+var configSensitiveParts = []string{"token", "secret", "password", "passwd", "credential", "authorization", "apikey", "api-key", "api_key", "privatekey", "private-key", "private_key"}
+
+// configReferenceFields are the fields that name secrets, rather than hold their values, and are not redacted.
+var configReferenceFields = map[string]bool{"secretRef": true, "secretName": true, "secretKey": true}
+
+// ConfigEndpoint configures the config endpoint, which returns the configuration of the triggers of the
+// EventListener as the sink resolves it when it processes events.
+type ConfigEndpoint struct {
+	// SecretName is the name of the secret in the namespace of the EventListener whose ConfigSecretKey is the
+	// bearer token that requests to the endpoint must have.
+	SecretName string
+	// SecretGetter gets the secret.
+	SecretGetter interceptors.SecretGetter
+}
+
+// ConfigResponse is the JSON body of the responses of the config endpoint.
+type ConfigResponse struct {
+	EventListener string `json:"eventListener"`
+	Namespace     string `json:"namespace"`
+	// Generation and ResourceVersion are those of the EventListener the sink sees, to compare with the
+	// applied one.
+	Generation      int64  `json:"generation"`
+	ResourceVersion string `json:"resourceVersion"`
+	// ErrorMessage is why the triggers of the EventListener couldn't be listed.
+	ErrorMessage  string               `json:"errorMessage,omitempty"`
+	Triggers      []ConfigTrigger      `json:"triggers"`
+	TriggerGroups []ConfigTriggerGroup `json:"triggerGroups,omitempty"`
+}
+
+// ConfigTrigger is the resolved configuration of a trigger.
+type ConfigTrigger struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Spec is the spec of the trigger, or of the trigger defined by the EventListener.
+	Spec         triggersv1.TriggerSpec `json:"spec"`
+	Interceptors []ConfigInterceptor    `json:"interceptors,omitempty"`
+	// BindingParams are the params of the bindings of the trigger, with the referenced TriggerBindings and
+	// ClusterTriggerBindings merged in.
+	BindingParams []triggersv1.Param `json:"bindingParams"`
+	// TriggerTemplates are the TriggerTemplate of the trigger, followed by those of its routes, if any.
+	TriggerTemplates []ConfigTriggerTemplate `json:"triggerTemplates,omitempty"`
+	// ErrorMessage is why the bindings or the templates of the trigger couldn't be resolved.
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// ConfigTriggerTemplate is a TriggerTemplate of a trigger.
+type ConfigTriggerTemplate struct {
+	// Name is the name of the referenced TriggerTemplate, or empty if the trigger embeds its spec.
+	Name string `json:"name,omitempty"`
+	// Route is the value of the routing extension selecting the TriggerTemplate, or empty for the fallback.
+	Route string                         `json:"route,omitempty"`
+	Spec  triggersv1.TriggerTemplateSpec `json:"spec"`
+}
+
+// ConfigInterceptor is the resolved configuration of an interceptor.
+type ConfigInterceptor struct {
+	Name string `json:"name,omitempty"`
+	Kind string `json:"kind,omitempty"`
+	// URL is the address of the ClusterInterceptor or the Interceptor, if it could be resolved.
+	URL string `json:"url,omitempty"`
+	// Params are the params of the interceptor, with the default params of the interceptor applied.
+	Params map[string]interface{} `json:"params,omitempty"`
+	// When is the when expression of the interceptor, if any.
+	When    string                         `json:"when,omitempty"`
+	Webhook *triggersv1.WebhookInterceptor `json:"webhook,omitempty"`
+	// ErrorMessage is why the interceptor couldn't be resolved.
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// ConfigTriggerGroup is the resolved configuration of a trigger group and of the triggers it selects.
+type ConfigTriggerGroup struct {
+	Name         string              `json:"name"`
+	Interceptors []ConfigInterceptor `json:"interceptors,omitempty"`
+	Triggers     []ConfigTrigger     `json:"triggers"`
+	// ErrorMessage is why the triggers of the group couldn't be selected.
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// HandleConfig returns the resolved configuration of the triggers of the EventListener for requests with the
+// bearer token of the config endpoint secret. The values of the params, headers and fields whose names look
+// like they hold credentials are redacted.
+func (r Sink) HandleConfig(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		response.Header().Set("Allow", http.MethodGet)
+		response.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if r.ConfigEndpoint == nil {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err := r.authenticateBearer(request, r.ConfigEndpoint.SecretGetter, r.ConfigEndpoint.SecretName, ConfigSecretKey, "config endpoint"); err != nil {
+		r.Logger.Warnf("rejecting config request: %v", err)
+		response.Header().Set("WWW-Authenticate", "Bearer")
+		response.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	body, err := redactConfig(r.effectiveConfig())
+	if err != nil {
+		r.Logger.Errorf("failed to redact the config: %v", err)
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(response).Encode(body); err != nil {
+		r.Logger.Errorf("failed to write back config response: %v", err)
+	}
+}
+
+func (r Sink) effectiveConfig() ConfigResponse {
+	body := ConfigResponse{
+		EventListener: r.EventListenerName,
+		Namespace:     r.EventListenerNamespace,
+		Triggers:      []ConfigTrigger{},
+	}
+	el, err := r.EventListenerLister.EventListeners(r.EventListenerNamespace).Get(r.EventListenerName)
+	if err != nil {
+		body.ErrorMessage = fmt.Sprintf("error getting EventListener %s in Namespace %s: %v", r.EventListenerName, r.EventListenerNamespace, err)
+		return body
+	}
+	body.Generation, body.ResourceVersion = el.Generation, el.ResourceVersion
+
+	trItems, err := r.selectTriggers(el.Spec.NamespaceSelector, el.Spec.LabelSelector)
+	if err != nil {
+		body.ErrorMessage = fmt.Sprintf("unable to select triggers: %v", err)
+		return body
+	}
+	mergedTriggers, err := r.merge(el.Spec.Triggers, trItems)
+	if err != nil {
+		body.ErrorMessage = fmt.Sprintf("error merging triggers: %v", err)
+		return body
+	}
+	for _, t := range mergedTriggers {
+		body.Triggers = append(body.Triggers, r.triggerConfig(t))
+	}
+
+	for _, g := range el.Spec.TriggerGroups {
+		group := ConfigTriggerGroup{Name: g.Name, Interceptors: r.interceptorsConfig(g.Interceptors), Triggers: []ConfigTrigger{}}
+		grouped, err := r.selectTriggers(g.TriggerSelector.NamespaceSelector, g.TriggerSelector.LabelSelector)
+		if err != nil {
+			group.ErrorMessage = fmt.Sprintf("unable to select the triggers of group %s: %v", g.Name, err)
+		}
+		for _, t := range grouped {
+			group.Triggers = append(group.Triggers, r.triggerConfig(t))
+		}
+		body.TriggerGroups = append(body.TriggerGroups, group)
+	}
+	return body
+}
+
+// triggerConfig resolves the interceptors, bindings and templates of the trigger like processTrigger does.
+func (r Sink) triggerConfig(t *triggersv1.Trigger) ConfigTrigger {
+	config := ConfigTrigger{
+		Name:          t.Name,
+		Namespace:     t.Namespace,
+		Spec:          t.Spec,
+		Interceptors:  r.interceptorsConfig(t.Spec.Interceptors),
+		BindingParams: []triggersv1.Param{},
+	}
+
+	variants := []triggersv1.Trigger{*t}
+	routes := []string{""}
+	if routing := t.Spec.Template.Routing; routing != nil {
+		for _, route := range routing.Routes {
+			routed, _ := template.RouteTrigger(*t, map[string]interface{}{routing.Extension: route.Value})
+			variants = append(variants, routed)
+			routes = append(routes, route.Value)
+		}
+	}
+	for i, variant := range variants {
+		rt, err := template.ResolveTrigger(variant,
+			r.TriggerBindingLister.TriggerBindings(t.Namespace).Get,
+			r.ClusterTriggerBindingLister.Get,
+			r.TriggerTemplateLister.TriggerTemplates(t.Namespace).Get)
+		if err != nil {
+			config.ErrorMessage = err.Error()
+			return config
+		}
+		if i == 0 && rt.BindingParams != nil {
+			config.BindingParams = rt.BindingParams
+		}
+		tt := ConfigTriggerTemplate{Route: routes[i], Spec: rt.TriggerTemplate.Spec}
+		if ref := variant.Spec.Template.Ref; ref != nil {
+			tt.Name = *ref
+		}
+		config.TriggerTemplates = append(config.TriggerTemplates, tt)
+	}
+	return config
+}
+
+// interceptorsConfig resolves the interceptors like executeInterceptor does.
+func (r Sink) interceptorsConfig(chain []*triggersv1.TriggerInterceptor) []ConfigInterceptor {
+	var configs []ConfigInterceptor
+	for _, i := range chain {
+		if i == nil {
+			continue
+		}
+		config := ConfigInterceptor{Name: i.GetName(), When: i.When, Webhook: i.Webhook}
+		if i.Webhook == nil {
+			config.Kind = string(i.Ref.Kind)
+			url, defaults, err := r.resolveInterceptor(i)
+			if err != nil {
+				config.ErrorMessage = err.Error()
+			} else if url != nil {
+				config.URL = url.String()
+			}
+			config.Params = withDefaultParams(defaults, interceptors.GetInterceptorParams(i))
+		}
+		configs = append(configs, config)
+	}
+	return configs
+}
+
+// redactConfig returns the config as JSON values, whose credentials are redacted.
+func redactConfig(config ConfigResponse) (interface{}, error) {
+	b, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return redactConfigValues(v), nil
+}
+
+// redactConfigValues redacts the values of the sensitive fields of v, a value decoded from JSON, and of the
+// name and value pairs with a sensitive name, such as params and headers.
+func redactConfigValues(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if name, ok := v["name"].(string); ok && sensitiveConfigName(name) {
+			if value, ok := v["value"]; ok && value != nil {
+				v["value"] = redactConfigValue(value)
+			}
+		}
+		for k, e := range v {
+			if sensitiveConfigName(k) && e != nil {
+				if _, isObject := e.(map[string]interface{}); !isObject {
+					v[k] = redactConfigValue(e)
+					continue
+				}
+			}
+			v[k] = redactConfigValues(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = redactConfigValues(e)
+		}
+	}
+	return v
+}
+
+// redactConfigValue redacts a value, or each value of a list of values.
+func redactConfigValue(v interface{}) interface{} {
+	if values, ok := v.([]interface{}); ok {
+		redacted := make([]interface{}, len(values))
+		for i := range values {
+			redacted[i] = redactedValue
+		}
+		return redacted
+	}
+	return redactedValue
+}
+
+func sensitiveConfigName(name string) bool {
+	if configReferenceFields[name] {
+		return false
+	}
+	name = strings.ToLower(name)
+	for _, part := range configSensitiveParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
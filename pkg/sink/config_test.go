@@ -0,0 +1,178 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	triggersv1alpha1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	triggersv1beta1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/test"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/ptr"
+)
+
+func configSink(t *testing.T) Sink {
+	t.Helper()
+	res := test.Resources{
+		EventListeners: []*triggersv1beta1.EventListener{{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-el", Namespace: namespace, Generation: 3, ResourceVersion: "42"},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Name: "my-trigger",
+					Interceptors: []*triggersv1beta1.TriggerInterceptor{{
+						Ref: triggersv1beta1.InterceptorRef{Name: "github", Kind: triggersv1beta1.ClusterInterceptorKind},
+						Params: []triggersv1beta1.InterceptorParams{{
+							Name:  "secretRef",
+							Value: apiextensionsv1.JSON{Raw: []byte(`{"secretName": "github-secret", "secretKey": "token"}`)},
+						}, {
+							Name:  "apiToken",
+							Value: apiextensionsv1.JSON{Raw: []byte(`"hunter2"`)},
+						}},
+					}, {
+						Webhook: &triggersv1beta1.WebhookInterceptor{
+							Header: []pipelinev1beta1.Param{{
+								Name:  "Authorization",
+								Value: pipelinev1beta1.ArrayOrString{Type: pipelinev1beta1.ParamTypeString, StringVal: "Bearer hunter2"},
+							}, {
+								Name:  "X-Team",
+								Value: pipelinev1beta1.ArrayOrString{Type: pipelinev1beta1.ParamTypeString, StringVal: "platform"},
+							}},
+						},
+					}},
+					Bindings: []*triggersv1beta1.TriggerSpecBinding{{Ref: "my-binding"}, {Name: "registryPassword", Value: ptr.String("hunter2")}},
+					Template: &triggersv1beta1.EventListenerTemplate{Ref: ptr.String("tt")},
+				}},
+			},
+		}},
+		TriggerBindings: []*triggersv1beta1.TriggerBinding{{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-binding", Namespace: namespace},
+			Spec: triggersv1beta1.TriggerBindingSpec{
+				Params: []triggersv1beta1.Param{{Name: "sha", Value: "$(body.head_commit.id)"}},
+			},
+		}},
+		TriggerTemplates: []*triggersv1beta1.TriggerTemplate{{
+			ObjectMeta: metav1.ObjectMeta{Name: "tt", Namespace: namespace},
+			Spec: triggersv1beta1.TriggerTemplateSpec{
+				Params: []triggersv1beta1.ParamSpec{{Name: "sha"}},
+			},
+		}},
+		ClusterInterceptors: []*triggersv1alpha1.ClusterInterceptor{github},
+		Secrets: []*corev1.Secret{{
+			ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: namespace},
+			Data:       map[string][]byte{ConfigSecretKey: []byte("let-me-in")},
+		}},
+	}
+	r, _ := getSinkAssets(t, res, "my-el", nil)
+	r.ConfigEndpoint = &ConfigEndpoint{
+		SecretName:   "config",
+		SecretGetter: interceptors.DefaultSecretGetter(r.KubeClientSet.CoreV1()),
+	}
+	return r
+}
+
+func TestHandleConfig(t *testing.T) {
+	r := configSink(t)
+	req := httptest.NewRequest(http.MethodGet, ConfigPath, nil)
+	req.Header.Set("Authorization", "Bearer let-me-in")
+	resp := httptest.NewRecorder()
+	r.HandleConfig(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("HandleConfig() status code = %d, want %d: %s", resp.Code, http.StatusOK, resp.Body.String())
+	}
+	if strings.Contains(resp.Body.String(), "hunter2") {
+		t.Errorf("HandleConfig() response has an unredacted secret: %s", resp.Body.String())
+	}
+
+	var got ConfigResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+		t.Fatalf("HandleConfig() returned invalid JSON: %v", err)
+	}
+	if got.Generation != 3 || got.ResourceVersion != "42" || len(got.Triggers) != 1 {
+		t.Fatalf("HandleConfig() = %+v, want generation 3, resourceVersion 42 and one trigger", got)
+	}
+	trigger := got.Triggers[0]
+	wantParams := []triggersv1beta1.Param{{Name: "sha", Value: "$(body.head_commit.id)"}, {Name: "registryPassword", Value: redactedValue}}
+	if diff := cmp.Diff(wantParams, trigger.BindingParams); diff != "" {
+		t.Errorf("binding params -want +got: %s", diff)
+	}
+	if len(trigger.TriggerTemplates) != 1 || trigger.TriggerTemplates[0].Name != "tt" || len(trigger.TriggerTemplates[0].Spec.Params) != 1 {
+		t.Errorf("trigger templates = %+v, want the spec of tt", trigger.TriggerTemplates)
+	}
+	if len(trigger.Interceptors) != 2 {
+		t.Fatalf("interceptors = %+v, want 2", trigger.Interceptors)
+	}
+	wantGitHub := map[string]interface{}{
+		"secretRef": map[string]interface{}{"secretName": "github-secret", "secretKey": "token"},
+		"apiToken":  redactedValue,
+	}
+	if diff := cmp.Diff(wantGitHub, trigger.Interceptors[0].Params); diff != "" {
+		t.Errorf("github interceptor params -want +got: %s", diff)
+	}
+	if got, want := trigger.Interceptors[0].URL, "http://tekton-triggers-core-interceptors/github"; got != want {
+		t.Errorf("github interceptor URL = %q, want %q", got, want)
+	}
+	header := trigger.Interceptors[1].Webhook.Header
+	if len(header) != 2 || header[0].Value.StringVal != redactedValue || header[1].Value.StringVal != "platform" {
+		t.Errorf("webhook interceptor header = %+v, want Authorization redacted", header)
+	}
+}
+
+func TestHandleConfig_Rejected(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		method   string
+		token    string
+		wantCode int
+	}{{
+		name:     "no token",
+		method:   http.MethodGet,
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "wrong token",
+		method:   http.MethodGet,
+		token:    "guess",
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:     "wrong method",
+		method:   http.MethodPost,
+		token:    "let-me-in",
+		wantCode: http.StatusMethodNotAllowed,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := configSink(t)
+			req := httptest.NewRequest(tc.method, ConfigPath, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			resp := httptest.NewRecorder()
+			r.HandleConfig(resp, req)
+			if resp.Code != tc.wantCode {
+				t.Errorf("HandleConfig() status code = %d, want %d", resp.Code, tc.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// defaultCreationLimitWindow is the window used if no Window is configured.
+const defaultCreationLimitWindow = time.Minute
+
+// ErrCreationLimitExceeded is returned when creating the resources of a trigger would exceed the
+// EventListener's creation limit.
+var ErrCreationLimitExceeded = errors.New("resource creation limit exceeded")
+
+// CreationLimit bounds the number of resources the sink creates per time window, either across all
+// triggers or separately for each trigger. Unlike Backpressure it does not reject events; triggers
+// that would exceed the limit skip resource creation until the window ends.
+//
+// Each replica of the EventListener counts its own windows, so the EventListener creates up to Max
+// times its number of replicas resources per window. The skipped triggers are reported with the
+// throttled Kubernetes and cloud events, the creation_limited_count metric and, through the
+// Activity, the CreationLimited condition of the EventListener.
+//
+// A nil *CreationLimit never limits resource creation.
+type CreationLimit struct {
+	// Max is the number of resources that can be created per window by this replica.
+	Max int64
+	// Window is the length of the fixed window the limit applies to.
+	Window time.Duration
+	// PerTrigger applies the limit to each trigger separately instead of to all triggers combined.
+	PerTrigger bool
+
+	mu      sync.Mutex
+	windows map[string]*creationWindow
+	now     func() time.Time
+}
+
+// creationWindow counts the resources created in the window starting at start.
+type creationWindow struct {
+	start   time.Time
+	created int64
+}
+
+func (l *CreationLimit) window() time.Duration {
+	if l.Window <= 0 {
+		return defaultCreationLimitWindow
+	}
+	return l.Window
+}
+
+func (l *CreationLimit) currentTime() time.Time {
+	if l.now == nil {
+		return time.Now()
+	}
+	return l.now()
+}
+
+// reserve reserves n resource creations for the trigger. It returns false if that would exceed
+// the limit, in which case none are reserved.
+func (l *CreationLimit) reserve(triggerName string, n int) bool {
+	if l == nil || l.Max <= 0 {
+		return true
+	}
+	key := ""
+	if l.PerTrigger {
+		key = triggerName
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.currentTime()
+	if l.windows == nil {
+		l.windows = map[string]*creationWindow{}
+	}
+	w, found := l.windows[key]
+	if !found || now.Sub(w.start) >= l.window() {
+		w = &creationWindow{start: now}
+		l.windows[key] = w
+		l.expire(now)
+	}
+	if w.created+int64(n) > l.Max {
+		return false
+	}
+	w.created += int64(n)
+	return true
+}
+
+// expire removes the windows that ended before now, so that the windows of deleted triggers
+// don't accumulate. It must be called with l.mu held.
+func (l *CreationLimit) expire(now time.Time) {
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.window() {
+			delete(l.windows, key)
+		}
+	}
+}
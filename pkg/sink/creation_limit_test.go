@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/triggers/pkg/resources"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCreationLimit_Reserve(t *testing.T) {
+	type reservation struct {
+		trigger string
+		n       int
+		advance time.Duration
+		want    bool
+	}
+	for _, tc := range []struct {
+		name         string
+		limit        *CreationLimit
+		reservations []reservation
+	}{{
+		name:  "nil limit",
+		limit: nil,
+		reservations: []reservation{
+			{trigger: "a", n: 100, want: true},
+		},
+	}, {
+		name:  "across all triggers",
+		limit: &CreationLimit{Max: 3, Window: time.Minute},
+		reservations: []reservation{
+			{trigger: "a", n: 2, want: true},
+			{trigger: "b", n: 1, want: true},
+			{trigger: "b", n: 1, want: false},
+			{trigger: "a", n: 1, want: false},
+		},
+	}, {
+		name:  "all resources of a trigger or none",
+		limit: &CreationLimit{Max: 3, Window: time.Minute},
+		reservations: []reservation{
+			{trigger: "a", n: 2, want: true},
+			{trigger: "a", n: 2, want: false},
+			{trigger: "a", n: 1, want: true},
+		},
+	}, {
+		name:  "per trigger",
+		limit: &CreationLimit{Max: 2, Window: time.Minute, PerTrigger: true},
+		reservations: []reservation{
+			{trigger: "a", n: 2, want: true},
+			{trigger: "a", n: 1, want: false},
+			{trigger: "b", n: 2, want: true},
+		},
+	}, {
+		name:  "limit resets after the window",
+		limit: &CreationLimit{Max: 1, Window: time.Minute},
+		reservations: []reservation{
+			{trigger: "a", n: 1, want: true},
+			{trigger: "a", n: 1, advance: 30 * time.Second, want: false},
+			{trigger: "a", n: 1, advance: 30 * time.Second, want: true},
+		},
+	}, {
+		name:  "default window",
+		limit: &CreationLimit{Max: 1},
+		reservations: []reservation{
+			{trigger: "a", n: 1, want: true},
+			{trigger: "a", n: 1, advance: 59 * time.Second, want: false},
+			{trigger: "a", n: 1, advance: time.Second, want: true},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Unix(1000, 0)
+			if tc.limit != nil {
+				tc.limit.now = func() time.Time { return now }
+			}
+			for i, r := range tc.reservations {
+				now = now.Add(r.advance)
+				if got := tc.limit.reserve(r.trigger, r.n); got != r.want {
+					t.Errorf("reservation %d: reserve(%q, %d) = %t, want %t", i, r.trigger, r.n, got, r.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateResources_CreationLimit(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	recorder, err := NewRecorder()
+	if err != nil {
+		t.Fatalf("NewRecorder() returned error: %v", err)
+	}
+	creator := &resources.FakeCreator{}
+	r := Sink{
+		EventListenerName: "test-el",
+		Logger:            logger,
+		Recorder:          recorder,
+		Creator:           creator,
+		CreationLimit:     &CreationLimit{Max: 2, Window: time.Hour},
+	}
+
+	res := []json.RawMessage{
+		json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"first"}}`),
+		json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"second"}}`),
+	}
+	if err := r.CreateResources(namespace, "", "", res, "my-trigger", eventID, logger); err != nil {
+		t.Fatalf("CreateResources() returned error: %v", err)
+	}
+	if err := r.CreateResources(namespace, "", "", res, "my-trigger", eventID, logger); !errors.Is(err, ErrCreationLimitExceeded) {
+		t.Errorf("CreateResources() = %v, want %v", err, ErrCreationLimitExceeded)
+	}
+	if got := len(creator.Created()); got != 2 {
+		t.Errorf("created %d resources, want 2", got)
+	}
+}
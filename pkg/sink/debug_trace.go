@@ -0,0 +1,510 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// DebugTraceHeader is the header of the requests asking for a debug trace. Its value is the token of the
+	// debug trace secret.
+	DebugTraceHeader = "Tekton-Debug-Trace"
+	// DebugTraceSecretKey is the key of the token in the debug trace secret.
+	DebugTraceSecretKey = "token"
+
+	// debugTraceRequested and debugTraceSampled are the reasons an event is traced.
+	debugTraceRequested = "requested"
+	debugTraceSampled   = "sampled"
+
+	// redactedValue replaces the sensitive values in debug traces.
+	redactedValue = "[REDACTED]"
+)
+
+// sensitiveHeaderParts are the parts of the names of the headers whose values are redacted from debug traces,
+// such as Authorization, X-Hub-Signature-256 or X-Gitlab-Token.
+var sensitiveHeaderParts = []string{"auth", "cookie", "token", "secret", "signature", "password", "hmac", "key"}
+
+// DebugTrace writes a trace of the processing of an event, once the triggers it was dispatched to are processed,
+// for the requests that ask for one with the DebugTraceHeader and for a sample of the others. The trace has the
+// request, the requests and responses of the interceptors of each trigger and trigger group, the params resolved
+// for each trigger, its rendered resources and its outcome.
+//
+// The values of the headers that may hold credentials are redacted from the whole trace, including from the
+// payload, params and resources they were copied to.
+//
+// A nil *DebugTrace writes nothing.
+type DebugTrace struct {
+	// Logger writes the traces, separately from the application logs, e.g. from NewDebugTraceLogger.
+	Logger *zap.Logger
+	// SecretName is the name of the secret in the namespace of the EventListener whose DebugTraceSecretKey is
+	// the token that requests asking for a trace must have. Requests can't ask for a trace if empty.
+	SecretName string
+	// SecretGetter gets the secret.
+	SecretGetter interceptors.SecretGetter
+	// SampleRate is the fraction of the events that are traced without asking for it.
+	SampleRate float64
+
+	random func() float64
+}
+
+// NewDebugTraceLogger returns a logger writing debug traces to w as JSON lines, without the level and sampling
+// of the application logs.
+func NewDebugTraceLogger(w io.Writer) *zap.Logger {
+	return newJSONLinesLogger(w).Named("debug-trace")
+}
+
+func (d *DebugTrace) sampled() bool {
+	if d.SampleRate <= 0 {
+		return false
+	}
+	random := rand.Float64
+	if d.random != nil {
+		random = d.random
+	}
+	return random() < d.SampleRate
+}
+
+// authenticate checks that token is the token of the debug trace secret. The SHA-256 hashes of the tokens are
+// compared in constant time.
+func (d *DebugTrace) authenticate(ctx context.Context, namespace, token string) error {
+	if d.SecretName == "" {
+		return errors.New("no debug trace secret is configured")
+	}
+	secret, err := d.SecretGetter.Get(ctx, namespace, &triggersv1.SecretRef{
+		SecretName: d.SecretName,
+		SecretKey:  DebugTraceSecretKey,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get the debug trace secret: %w", err)
+	}
+	got := sha256.Sum256([]byte(token))
+	want := sha256.Sum256(secret)
+	if subtle.ConstantTimeCompare(got[:], want[:]) != 1 {
+		return fmt.Errorf("the %s header does not match the debug trace secret", DebugTraceHeader)
+	}
+	return nil
+}
+
+// debugTraceReason returns why the request is traced, or an empty string if it isn't.
+func (r Sink) debugTraceReason(request *http.Request) string {
+	if token := request.Header.Get(DebugTraceHeader); token != "" {
+		err := r.DebugTrace.authenticate(request.Context(), r.EventListenerNamespace, token)
+		if err == nil {
+			return debugTraceRequested
+		}
+		r.Logger.Warnf("not tracing request asking for a debug trace: %v", err)
+	}
+	if r.DebugTrace.sampled() {
+		return debugTraceSampled
+	}
+	return ""
+}
+
+// debugTrace is the trace of the processing of an event, as it is written.
+type debugTrace struct {
+	EventID       string              `json:"eventID,omitempty"`
+	Reason        string              `json:"reason"`
+	EventListener string              `json:"eventListener"`
+	Namespace     string              `json:"namespace"`
+	Status        int                 `json:"status"`
+	Request       debugTraceRequest   `json:"request"`
+	TriggerGroups []*debugTraceTarget `json:"triggerGroups,omitempty"`
+	Triggers      []*debugTraceTarget `json:"triggers,omitempty"`
+}
+
+type debugTraceRequest struct {
+	Method   string              `json:"method"`
+	Path     string              `json:"path"`
+	Query    map[string][]string `json:"query,omitempty"`
+	Header   map[string][]string `json:"header,omitempty"`
+	ClientIP string              `json:"clientIP"`
+	// Body is the payload passed to the interceptors, as JSON if it is valid JSON.
+	Body interface{} `json:"body,omitempty"`
+}
+
+// debugTraceTarget is the trace of the processing of an event by a trigger or trigger group.
+type debugTraceTarget struct {
+	Name         string                  `json:"name"`
+	Interceptors []debugTraceInterceptor `json:"interceptors,omitempty"`
+	Params       []triggersv1.Param      `json:"params,omitempty"`
+	Resources    []json.RawMessage       `json:"resources,omitempty"`
+	// Outcome is fired, rejected or failed, or empty if the trigger group passed the event to its triggers.
+	Outcome string             `json:"outcome,omitempty"`
+	Status  *triggersv1.Status `json:"status,omitempty"`
+	Error   string             `json:"error,omitempty"`
+}
+
+type debugTraceInterceptor struct {
+	Name       string                         `json:"name"`
+	Request    *triggersv1.InterceptorRequest `json:"request,omitempty"`
+	Continue   bool                           `json:"continue"`
+	Skipped    bool                           `json:"skipped,omitempty"`
+	Extensions map[string]interface{}         `json:"extensions,omitempty"`
+	Status     *triggersv1.Status             `json:"status,omitempty"`
+	Error      string                         `json:"error,omitempty"`
+	Duration   string                         `json:"duration"`
+}
+
+// debugTraceRecord collects the trace of a request as it is handled. It is safe for concurrent use by the
+// goroutines processing the event, each of which records the trace of its own trigger or trigger group.
+type debugTraceRecord struct {
+	mu    sync.Mutex
+	trace debugTrace
+	// pending counts the parts of the handling of the request that are not complete: the response, and the
+	// processing of the triggers the event was dispatched to, if any.
+	pending int
+	emit    func(*debugTraceRecord)
+}
+
+type debugTraceRecordKey struct{}
+
+// debugTraceFrom returns the debug trace record of the request with the given context, or nil if the request
+// isn't traced.
+func debugTraceFrom(ctx context.Context) *debugTraceRecord {
+	rec, _ := ctx.Value(debugTraceRecordKey{}).(*debugTraceRecord)
+	return rec
+}
+
+// received records the event ID and the payload of the event.
+func (d *debugTraceRecord) received(eventID string, body []byte) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.trace.EventID = eventID
+	if json.Valid(body) {
+		d.trace.Request.Body = json.RawMessage(body)
+	} else if len(body) > 0 {
+		d.trace.Request.Body = string(body)
+	}
+}
+
+// dispatched delays the trace until processed is called.
+func (d *debugTraceRecord) dispatched() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pending++
+}
+
+// processed records the outcomes of the triggers and trigger groups once they are all processed.
+func (d *debugTraceRecord) processed(outcomes *triggerOutcomes) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	for _, targets := range [][]*debugTraceTarget{d.trace.TriggerGroups, d.trace.Triggers} {
+		for _, t := range targets {
+			t.setOutcome(outcomes)
+		}
+	}
+	d.mu.Unlock()
+	d.done()
+}
+
+// done completes a part of the handling of the request, and writes the trace once all parts are complete.
+func (d *debugTraceRecord) done() {
+	d.mu.Lock()
+	d.pending--
+	complete := d.pending == 0
+	d.mu.Unlock()
+	if complete {
+		d.emit(d)
+	}
+}
+
+// trigger returns the trace of the processing of the event by a trigger, or nil if the event isn't traced.
+func (d *debugTraceRecord) trigger(name string) *debugTraceTarget {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t := &debugTraceTarget{Name: name}
+	d.trace.Triggers = append(d.trace.Triggers, t)
+	return t
+}
+
+// triggerGroup returns the trace of the processing of the event by a trigger group, or nil if the event isn't
+// traced.
+func (d *debugTraceRecord) triggerGroup(name string) *debugTraceTarget {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t := &debugTraceTarget{Name: name}
+	d.trace.TriggerGroups = append(d.trace.TriggerGroups, t)
+	return t
+}
+
+// interceptors records the requests and responses of the interceptors executed.
+func (t *debugTraceTarget) interceptors(result *InterceptorChainResult) {
+	if t == nil || result == nil {
+		return
+	}
+	for _, res := range result.Interceptors {
+		i := debugTraceInterceptor{
+			Name:       res.Name,
+			Request:    res.Request,
+			Continue:   res.Continue,
+			Skipped:    res.Skipped,
+			Extensions: res.Extensions,
+			Duration:   res.Duration.String(),
+		}
+		if res.Status.Code != codes.OK || res.Status.Message != "" {
+			status := res.Status
+			i.Status = &status
+		}
+		if res.Err != nil {
+			i.Error = res.Err.Error()
+		}
+		t.Interceptors = append(t.Interceptors, i)
+	}
+}
+
+// resolved records the params resolved for the trigger.
+func (t *debugTraceTarget) resolved(params []triggersv1.Param) {
+	if t == nil {
+		return
+	}
+	t.Params = params
+}
+
+// rendered records the resources rendered from the template of the trigger.
+func (t *debugTraceTarget) rendered(resources []json.RawMessage) {
+	if t == nil {
+		return
+	}
+	t.Resources = resources
+}
+
+// setOutcome records the outcome of the trigger or trigger group: failed takes precedence over rejected,
+// which takes precedence over fired, since the same trigger may be processed in several trigger groups.
+func (t *debugTraceTarget) setOutcome(outcomes *triggerOutcomes) {
+	outcomes.mu.Lock()
+	defer outcomes.mu.Unlock()
+	for _, f := range outcomes.failed {
+		if f.name == t.Name {
+			t.Outcome, t.Error = "failed", f.err.Error()
+			return
+		}
+	}
+	for _, r := range outcomes.rejected {
+		if r.name == t.Name {
+			status := r.status
+			t.Outcome, t.Status = "rejected", &status
+			return
+		}
+	}
+	for _, name := range outcomes.fired {
+		if name == t.Name {
+			t.Outcome = "fired"
+			return
+		}
+	}
+}
+
+// snapshotInterceptorRequest returns a copy of the request sent to an interceptor, which the chain modifies
+// for the next interceptors.
+func snapshotInterceptorRequest(request *triggersv1.InterceptorRequest) *triggersv1.InterceptorRequest {
+	extensions := make(map[string]interface{}, len(request.Extensions))
+	for k, v := range request.Extensions {
+		extensions[k] = v
+	}
+	var triggerContext *triggersv1.TriggerContext
+	if request.Context != nil {
+		c := *request.Context
+		triggerContext = &c
+	}
+	return &triggersv1.InterceptorRequest{
+		Body:       request.Body,
+		RawBody:    request.RawBody,
+		Header:     http.Header(request.Header).Clone(),
+		Extensions: extensions,
+		Context:    triggerContext,
+	}
+}
+
+// WithDebugTrace writes a debug trace of the processing of the events of the traced requests handled by
+// eventHandler.
+func (r Sink) WithDebugTrace(eventHandler http.Handler) http.Handler {
+	d := r.DebugTrace
+	if d == nil || d.Logger == nil {
+		return eventHandler
+	}
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		reason := r.debugTraceReason(request)
+		if reason == "" {
+			eventHandler.ServeHTTP(response, request)
+			return
+		}
+
+		recorder := &statusRecorder{ResponseWriter: response}
+		rec := &debugTraceRecord{pending: 1, trace: debugTrace{
+			Reason:        reason,
+			EventListener: r.EventListenerName,
+			Namespace:     r.EventListenerNamespace,
+			Request: debugTraceRequest{
+				Method:   request.Method,
+				Path:     r.externalURL(request.URL).Path,
+				Query:    request.URL.Query(),
+				Header:   request.Header.Clone(),
+				ClientIP: r.clientIP(request),
+			},
+		}}
+		rec.emit = func(rec *debugTraceRecord) {
+			rec.trace.Status = recorder.status
+			if rec.trace.Status == 0 {
+				rec.trace.Status = http.StatusOK
+			}
+			fields, err := redactDebugTrace(&rec.trace)
+			if err != nil {
+				r.Logger.Errorf("failed to write the debug trace of event %s: %v", rec.trace.EventID, err)
+				return
+			}
+			d.Logger.Info("debug trace", fields...)
+		}
+		eventHandler.ServeHTTP(recorder, request.WithContext(context.WithValue(request.Context(), debugTraceRecordKey{}, rec)))
+		rec.done()
+	})
+}
+
+// redactDebugTrace redacts the values of the sensitive headers of the trace from all of its values, and returns
+// its fields.
+func redactDebugTrace(trace *debugTrace) ([]zap.Field, error) {
+	secrets := map[string]bool{}
+	trace.Request.Header = redactHeader(trace.Request.Header, secrets)
+	for _, targets := range [][]*debugTraceTarget{trace.TriggerGroups, trace.Triggers} {
+		for _, t := range targets {
+			for _, i := range t.Interceptors {
+				if i.Request != nil {
+					i.Request.Header = redactHeader(i.Request.Header, secrets)
+				}
+			}
+		}
+	}
+	values := make([]string, 0, len(secrets))
+	for v := range secrets {
+		values = append(values, v)
+	}
+	// Longer values are redacted first, so that a value containing another one is redacted as a whole.
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+
+	b, err := json.Marshal(trace)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := make([]zap.Field, 0, len(keys))
+	for _, k := range keys {
+		fields = append(fields, zap.Reflect(k, redactValues(m[k], values)))
+	}
+	return fields, nil
+}
+
+// redactHeader returns a copy of header whose sensitive values are redacted, and adds these values to secrets,
+// along with the credentials of the values with an authentication scheme, e.g. "Bearer <token>".
+func redactHeader(header map[string][]string, secrets map[string]bool) map[string][]string {
+	if header == nil {
+		return nil
+	}
+	redacted := make(map[string][]string, len(header))
+	for k, values := range header {
+		if !sensitiveHeader(k) {
+			redacted[k] = values
+			continue
+		}
+		for _, v := range values {
+			if v == "" {
+				continue
+			}
+			secrets[v] = true
+			if parts := strings.SplitN(v, " ", 2); len(parts) == 2 && strings.TrimSpace(parts[1]) != "" {
+				secrets[strings.TrimSpace(parts[1])] = true
+			}
+			redacted[k] = append(redacted[k], redactedValue)
+		}
+	}
+	return redacted
+}
+
+func sensitiveHeader(name string) bool {
+	name = strings.ToLower(name)
+	if name == strings.ToLower(DebugTraceHeader) {
+		return true
+	}
+	for _, part := range sensitiveHeaderParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValues replaces the secrets in the strings of v, a value decoded from JSON.
+func redactValues(v interface{}, secrets []string) interface{} {
+	switch v := v.(type) {
+	case string:
+		for _, s := range secrets {
+			v = strings.ReplaceAll(v, s, redactedValue)
+		}
+		return v
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = redactValues(e, secrets)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = redactValues(e, secrets)
+		}
+	}
+	return v
+}
@@ -0,0 +1,323 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	triggersv1alpha1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	triggersv1beta1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/test"
+	"go.uber.org/zap/zaptest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/ptr"
+)
+
+const debugTraceToken = "s3cr3t-trace-token"
+
+func debugTraceSecretGetter() secretGetterFunc {
+	return func(ns string, sr *triggersv1beta1.SecretRef) ([]byte, error) {
+		if ns != namespace || sr.SecretName != "debug-trace" || sr.SecretKey != DebugTraceSecretKey {
+			return nil, errors.New("secret not found")
+		}
+		return []byte(debugTraceToken), nil
+	}
+}
+
+// debugTraces decodes the JSON lines written by a debug trace logger.
+func debugTraces(t *testing.T, buf *bytes.Buffer) []debugTraceEntry {
+	t.Helper()
+	var traces []debugTraceEntry
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var trace debugTraceEntry
+		if err := json.Unmarshal([]byte(line), &trace); err != nil {
+			t.Fatalf("invalid debug trace %q: %v", line, err)
+		}
+		traces = append(traces, trace)
+	}
+	return traces
+}
+
+// debugTraceEntry is a debug trace as it is written.
+type debugTraceEntry struct {
+	Logger string `json:"logger"`
+	Msg    string `json:"msg"`
+	debugTrace
+}
+
+func (e debugTraceEntry) trigger(t *testing.T, name string) *debugTraceTarget {
+	t.Helper()
+	for _, target := range e.Triggers {
+		if target.Name == name {
+			return target
+		}
+	}
+	t.Fatalf("no trace of trigger %s in %+v", name, e.Triggers)
+	return nil
+}
+
+func TestWithDebugTrace_HandleEvent(t *testing.T) {
+	filter := func(name, expression string) []*triggersv1beta1.TriggerInterceptor {
+		return []*triggersv1beta1.TriggerInterceptor{{
+			Name: ptr.String(name),
+			Ref:  triggersv1beta1.InterceptorRef{Name: "cel", Kind: triggersv1beta1.ClusterInterceptorKind},
+			Params: []triggersv1beta1.InterceptorParams{{
+				Name:  "filter",
+				Value: test.ToV1JSON(t, expression),
+			}},
+		}}
+	}
+	bindings := []*triggersv1beta1.TriggerSpecBinding{
+		{Name: "url", Value: ptr.String("$(body.repository.url)")},
+		{Name: "revision", Value: ptr.String("$(header.X-Api-Key)")},
+		{Name: "name", Value: ptr.String("traced-run")},
+	}
+	resources := test.Resources{
+		ClusterInterceptors: []*triggersv1alpha1.ClusterInterceptor{cel},
+		EventListeners: []*triggersv1beta1.EventListener{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-el",
+				Namespace: namespace,
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Name:         "fired",
+					Interceptors: filter("has-repository", "has(body.repository)"),
+					Bindings:     bindings,
+					Template:     &triggersv1beta1.EventListenerTemplate{Spec: makeGitCloneTTSpec(t, "traced-run")},
+				}, {
+					Name:         "rejected",
+					Interceptors: filter("is-tag", "has(body.tag)"),
+					Bindings:     bindings,
+					Template:     &triggersv1beta1.EventListenerTemplate{Spec: makeGitCloneTTSpec(t, "traced-run")},
+				}},
+			},
+		}},
+	}
+	sink, _ := getSinkAssets(t, resources, "test-el", nil)
+	buf := &bytes.Buffer{}
+	sink.DebugTrace = &DebugTrace{
+		Logger:       NewDebugTraceLogger(buf),
+		SecretName:   "debug-trace",
+		SecretGetter: debugTraceSecretGetter(),
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "https://el.example.com/?env=prod", strings.NewReader(`{"repository": {"url": "https://example.com/repo"}, "apiKey": "api-key-1234"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", "api-key-1234")
+	req.Header.Set("Authorization", "Bearer bearer-5678")
+	req.Header.Set("X-Source", "ci")
+	req.Header.Set(DebugTraceHeader, debugTraceToken)
+	resp := httptest.NewRecorder()
+	sink.WithDebugTrace(http.HandlerFunc(sink.HandleEvent)).ServeHTTP(resp, req)
+	sink.WGProcessTriggers.Wait()
+
+	for _, secret := range []string{debugTraceToken, "api-key-1234", "bearer-5678"} {
+		if strings.Contains(buf.String(), secret) {
+			t.Errorf("debug trace contains the secret %q: %s", secret, buf)
+		}
+	}
+	traces := debugTraces(t, buf)
+	if len(traces) != 1 {
+		t.Fatalf("got %d debug traces, want 1: %s", len(traces), buf)
+	}
+	trace := traces[0]
+	if trace.Logger != "debug-trace" || trace.Msg != "debug trace" || trace.Reason != debugTraceRequested ||
+		trace.EventID != eventID || trace.EventListener != "test-el" || trace.Status != http.StatusAccepted {
+		t.Errorf("unexpected debug trace %+v", trace)
+	}
+	wantRequest := debugTraceRequest{
+		Method: http.MethodPost,
+		Path:   "/",
+		Query:  map[string][]string{"env": {"prod"}},
+		Header: map[string][]string{
+			"Content-Type":   {"application/json"},
+			"X-Api-Key":      {redactedValue},
+			"Authorization":  {redactedValue},
+			"X-Source":       {"ci"},
+			DebugTraceHeader: {redactedValue},
+		},
+		ClientIP: "192.0.2.1",
+		Body: map[string]interface{}{
+			"repository": map[string]interface{}{"url": "https://example.com/repo"},
+			"apiKey":     redactedValue,
+		},
+	}
+	if diff := cmp.Diff(wantRequest, trace.Request); diff != "" {
+		t.Errorf("debug trace request (-want +got): %s", diff)
+	}
+
+	fired := trace.trigger(t, "fired")
+	if fired.Outcome != "fired" || len(fired.Interceptors) != 1 || len(fired.Resources) != 1 {
+		t.Fatalf("unexpected trace of the fired trigger %+v", fired)
+	}
+	interceptor := fired.Interceptors[0]
+	if interceptor.Name != "has-repository" || !interceptor.Continue || interceptor.Request == nil ||
+		interceptor.Request.InterceptorParams["filter"] != "has(body.repository)" ||
+		interceptor.Request.Header["X-Api-Key"][0] != redactedValue || interceptor.Request.Context.TriggerID != "namespaces/foo/triggers/fired" {
+		t.Errorf("unexpected trace of the interceptor %+v", interceptor)
+	}
+	wantParams := []triggersv1beta1.Param{
+		{Name: "app", Value: "triggers"},
+		{Name: "name", Value: "traced-run"},
+		{Name: "revision", Value: redactedValue},
+		{Name: "type", Value: "bar"},
+		{Name: "url", Value: "https://example.com/repo"},
+	}
+	sortParams := cmpopts.SortSlices(func(a, b triggersv1beta1.Param) bool { return a.Name < b.Name })
+	if diff := cmp.Diff(wantParams, fired.Params, sortParams); diff != "" {
+		t.Errorf("debug trace params (-want +got): %s", diff)
+	}
+	if !strings.Contains(string(fired.Resources[0]), `{"name":"git-revision","value":"`+redactedValue+`"}`) {
+		t.Errorf("expected the revision to be redacted from the rendered resource, got %s", fired.Resources[0])
+	}
+
+	rejected := trace.trigger(t, "rejected")
+	if rejected.Outcome != "rejected" || rejected.Status == nil || rejected.Params != nil || rejected.Resources != nil {
+		t.Errorf("unexpected trace of the rejected trigger %+v", rejected)
+	}
+	if len(rejected.Interceptors) != 1 || rejected.Interceptors[0].Continue || rejected.Interceptors[0].Status == nil {
+		t.Errorf("unexpected trace of the interceptors of the rejected trigger %+v", rejected.Interceptors)
+	}
+}
+
+func TestWithDebugTrace_Gating(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		secretName string
+		sampleRate float64
+		header     string
+		want       string
+	}{{
+		name:       "requested",
+		secretName: "debug-trace",
+		header:     debugTraceToken,
+		want:       debugTraceRequested,
+	}, {
+		name:       "not requested",
+		secretName: "debug-trace",
+	}, {
+		name:       "wrong token",
+		secretName: "debug-trace",
+		header:     "guess",
+	}, {
+		name:   "no secret",
+		header: debugTraceToken,
+	}, {
+		name:       "missing secret",
+		secretName: "missing",
+		header:     debugTraceToken,
+	}, {
+		name:       "sampled",
+		sampleRate: 0.5,
+		want:       debugTraceSampled,
+	}, {
+		name:       "not sampled",
+		sampleRate: 0.1,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			r := Sink{
+				EventListenerNamespace: namespace,
+				Logger:                 zaptest.NewLogger(t).Sugar(),
+				DebugTrace: &DebugTrace{
+					Logger:       NewDebugTraceLogger(buf),
+					SecretName:   tc.secretName,
+					SecretGetter: debugTraceSecretGetter(),
+					SampleRate:   tc.sampleRate,
+					random:       func() float64 { return 0.25 },
+				},
+			}
+			next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				debugTraceFrom(req.Context()).received("event-1", []byte(`{}`))
+				w.WriteHeader(http.StatusAccepted)
+			})
+			req := httptest.NewRequest(http.MethodPost, "https://el.example.com", nil)
+			if tc.header != "" {
+				req.Header.Set(DebugTraceHeader, tc.header)
+			}
+			r.WithDebugTrace(next).ServeHTTP(httptest.NewRecorder(), req)
+
+			traces := debugTraces(t, buf)
+			if tc.want == "" {
+				if len(traces) != 0 {
+					t.Errorf("expected no debug trace, got %s", buf)
+				}
+				return
+			}
+			if len(traces) != 1 || traces[0].Reason != tc.want || traces[0].EventID != "event-1" || traces[0].Status != http.StatusAccepted {
+				t.Errorf("expected a debug trace because the event was %s, got %s", tc.want, buf)
+			}
+		})
+	}
+}
+
+func TestWithDebugTrace_Disabled(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// Recording on a disabled debug trace doesn't panic.
+		trace := debugTraceFrom(req.Context())
+		trace.received("event-1", nil)
+		trace.dispatched()
+		trace.trigger("trigger").interceptors(&InterceptorChainResult{})
+		trace.triggerGroup("group").resolved(nil)
+		trace.processed(&triggerOutcomes{})
+		w.WriteHeader(http.StatusAccepted)
+	})
+	req := httptest.NewRequest(http.MethodPost, "https://el.example.com", nil)
+	req.Header.Set(DebugTraceHeader, debugTraceToken)
+	resp := httptest.NewRecorder()
+	Sink{}.WithDebugTrace(next).ServeHTTP(resp, req)
+	if resp.Code != http.StatusAccepted {
+		t.Errorf("got status %d, want %d", resp.Code, http.StatusAccepted)
+	}
+}
+
+func TestRedactHeader(t *testing.T) {
+	secrets := map[string]bool{}
+	got := redactHeader(map[string][]string{
+		"Authorization":       {"Basic dXNlcjpwYXNz"},
+		"X-Hub-Signature-256": {"sha256=abc"},
+		"X-Gitlab-Token":      {"gl-token", ""},
+		"Cookie":              {"session=1"},
+		"X-Github-Event":      {"push"},
+	}, secrets)
+	want := map[string][]string{
+		"Authorization":       {redactedValue},
+		"X-Hub-Signature-256": {redactedValue},
+		"X-Gitlab-Token":      {redactedValue},
+		"Cookie":              {redactedValue},
+		"X-Github-Event":      {"push"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("redactHeader() (-want +got): %s", diff)
+	}
+	wantSecrets := map[string]bool{"Basic dXNlcjpwYXNz": true, "dXNlcjpwYXNz": true, "sha256=abc": true, "gl-token": true, "session=1": true}
+	if diff := cmp.Diff(wantSecrets, secrets); diff != "" {
+		t.Errorf("redactHeader() secrets (-want +got): %s", diff)
+	}
+}
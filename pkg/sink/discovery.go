@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	discoveryclient "k8s.io/client-go/discovery"
+)
+
+// ReadinessPath is the path of the readiness probe of the sink.
+const ReadinessPath = "/ready"
+
+// maxDiscoveryBackoff caps the delay between two attempts to reach the discovery API.
+const maxDiscoveryBackoff = 30 * time.Second
+
+// DiscoveryReadiness tracks whether the discovery client of the sink can list the resources of the API
+// server, which the sink needs to create resources. The sink reports it is not ready while discovery is
+// unavailable, at startup or when the API server becomes unreachable, so that events are sent to the
+// other replicas in the meantime.
+type DiscoveryReadiness struct {
+	// Client is the discovery client whose API groups are listed to check that discovery is available.
+	Client discoveryclient.ServerGroupsInterface
+	// GracePeriod is how long discovery may be unavailable at startup before WaitForDiscovery fails.
+	GracePeriod time.Duration
+	// InitialBackoff is the delay before the first new attempt to reach discovery, doubled after each
+	// failed attempt up to 30 seconds. Defaults to one second.
+	InitialBackoff time.Duration
+	Logger         *zap.SugaredLogger
+
+	ready    int32
+	checking int32
+}
+
+// Ready returns whether discovery is available. A nil DiscoveryReadiness is always ready.
+func (d *DiscoveryReadiness) Ready() bool {
+	return d == nil || atomic.LoadInt32(&d.ready) == 1
+}
+
+// WaitForDiscovery retries reaching discovery with backoff until it succeeds, in which case the sink is
+// ready, or until the grace period elapses or ctx is done, in which case it returns an error.
+func (d *DiscoveryReadiness) WaitForDiscovery(ctx context.Context) error {
+	if d == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.GracePeriod)
+	defer cancel()
+	if err := d.retry(ctx); err != nil {
+		return fmt.Errorf("discovery not ready after %s: %w", d.GracePeriod, err)
+	}
+	d.Logger.Info("discovery is ready")
+	return nil
+}
+
+// unavailable marks discovery as unavailable after err, returned by a call using the discovery client,
+// and retries reaching it in the background until it succeeds.
+func (d *DiscoveryReadiness) unavailable(err error) {
+	if d == nil {
+		return
+	}
+	atomic.StoreInt32(&d.ready, 0)
+	if !atomic.CompareAndSwapInt32(&d.checking, 0, 1) {
+		return
+	}
+	d.Logger.Warnf("discovery is not ready, reporting the EventListener as not ready until it is: %v", err)
+	go func() {
+		defer atomic.StoreInt32(&d.checking, 0)
+		if err := d.retry(context.Background()); err == nil {
+			d.Logger.Info("discovery is ready again")
+		}
+	}()
+}
+
+// retry tries to reach discovery with backoff until it succeeds and marks it ready, or until ctx is
+// done, in which case it returns the last error.
+func (d *DiscoveryReadiness) retry(ctx context.Context) error {
+	backoff := d.InitialBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	for {
+		_, err := d.Client.ServerGroups()
+		if err == nil {
+			atomic.StoreInt32(&d.ready, 1)
+			return nil
+		}
+		d.Logger.Debugf("discovery is not ready, retrying in %s: %v", backoff, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxDiscoveryBackoff {
+			backoff = maxDiscoveryBackoff
+		}
+	}
+}
+
+// HandleReadiness is the readiness probe of the sink, which fails while discovery is unavailable.
+func (d *DiscoveryReadiness) HandleReadiness(w http.ResponseWriter, _ *http.Request) {
+	if !d.Ready() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, "discovery not ready")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "ok")
+}
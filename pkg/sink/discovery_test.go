@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// flakyDiscovery is a discovery client failing until it was called more than failures times.
+type flakyDiscovery struct {
+	failures int32
+	calls    int32
+}
+
+func (d *flakyDiscovery) ServerGroups() (*metav1.APIGroupList, error) {
+	if atomic.AddInt32(&d.calls, 1) <= atomic.LoadInt32(&d.failures) {
+		return nil, errors.New("connection refused")
+	}
+	return &metav1.APIGroupList{}, nil
+}
+
+func readiness(t *testing.T, d *DiscoveryReadiness) int {
+	t.Helper()
+	w := httptest.NewRecorder()
+	d.HandleReadiness(w, httptest.NewRequest(http.MethodGet, ReadinessPath, nil))
+	return w.Code
+}
+
+func TestDiscoveryReadiness_WaitForDiscovery(t *testing.T) {
+	client := &flakyDiscovery{failures: 3}
+	d := &DiscoveryReadiness{Client: client, GracePeriod: time.Minute, InitialBackoff: time.Millisecond, Logger: zaptest.NewLogger(t).Sugar()}
+	if code := readiness(t, d); code != http.StatusServiceUnavailable {
+		t.Errorf("readiness before discovery got %d, want %d", code, http.StatusServiceUnavailable)
+	}
+	if err := d.WaitForDiscovery(context.Background()); err != nil {
+		t.Fatalf("WaitForDiscovery() returned error: %v", err)
+	}
+	if client.calls != 4 {
+		t.Errorf("WaitForDiscovery() reached discovery %d times, want 4", client.calls)
+	}
+	if code := readiness(t, d); code != http.StatusOK {
+		t.Errorf("readiness after discovery got %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestDiscoveryReadiness_WaitForDiscovery_GracePeriod(t *testing.T) {
+	d := &DiscoveryReadiness{Client: &flakyDiscovery{failures: 1 << 30}, GracePeriod: 20 * time.Millisecond, InitialBackoff: time.Millisecond, Logger: zaptest.NewLogger(t).Sugar()}
+	if err := d.WaitForDiscovery(context.Background()); err == nil {
+		t.Fatal("WaitForDiscovery() expected an error once the grace period elapsed")
+	}
+	if d.Ready() {
+		t.Error("Ready() got true, want false")
+	}
+}
+
+func TestDiscoveryReadiness_Unavailable(t *testing.T) {
+	client := &flakyDiscovery{}
+	d := &DiscoveryReadiness{Client: client, GracePeriod: time.Minute, InitialBackoff: 50 * time.Millisecond, Logger: zaptest.NewLogger(t).Sugar()}
+	if err := d.WaitForDiscovery(context.Background()); err != nil {
+		t.Fatalf("WaitForDiscovery() returned error: %v", err)
+	}
+
+	atomic.StoreInt32(&client.failures, atomic.LoadInt32(&client.calls)+2)
+	d.unavailable(errors.New("connection refused"))
+	if d.Ready() {
+		t.Error("Ready() got true right after discovery became unavailable")
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for !d.Ready() {
+		if time.Now().After(deadline) {
+			t.Fatal("discovery wasn't ready again after it became available")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDiscoveryReadiness_Nil(t *testing.T) {
+	var d *DiscoveryReadiness
+	if err := d.WaitForDiscovery(context.Background()); err != nil {
+		t.Errorf("WaitForDiscovery() returned error: %v", err)
+	}
+	d.unavailable(errors.New("connection refused"))
+	if code := readiness(t, d); code != http.StatusOK {
+		t.Errorf("readiness got %d, want %d", code, http.StatusOK)
+	}
+}
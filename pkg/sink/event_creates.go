@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// DefaultMaxCreatesPerEvent is the number of resources that an EventListener can create for a single event
+// unless configured otherwise.
+const DefaultMaxCreatesPerEvent = 100
+
+// ErrEventCreateLimitExceeded is returned when creating the resources of a trigger would exceed the number
+// of resources the EventListener can create for a single event.
+var ErrEventCreateLimitExceeded = errors.New("resource creation limit of the event exceeded")
+
+// eventCreates counts the resources created for a single event by all of its triggers, so that a runaway
+// trigger, e.g. with many templates or matching many trigger groups, can't create an unbounded number of
+// resources from one event. Unlike CreationLimit it is a safety limit independent of the rate of events.
+//
+// A nil *eventCreates never limits resource creation.
+type eventCreates struct {
+	max int
+
+	mu       sync.Mutex
+	reserved int
+}
+
+type eventCreatesKey struct{}
+
+// newEventCreates returns the counter of the resources created for an event, or nil if the sink doesn't
+// limit them.
+func (r Sink) newEventCreates() *eventCreates {
+	if r.MaxCreatesPerEvent <= 0 {
+		return nil
+	}
+	return &eventCreates{max: r.MaxCreatesPerEvent}
+}
+
+// eventCreatesFrom returns the counter of the resources created for the event of the request with the
+// given context, or nil if there is none.
+func eventCreatesFrom(ctx context.Context) *eventCreates {
+	c, _ := ctx.Value(eventCreatesKey{}).(*eventCreates)
+	return c
+}
+
+// reserve returns whether n more resources can be created for the event, and if so counts them. The
+// resources of a trigger are reserved all at once, so that a trigger creates either all or none of them.
+func (c *eventCreates) reserve(n int) bool {
+	if c == nil {
+		return true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reserved+n > c.max {
+		return false
+	}
+	c.reserved += n
+	return true
+}
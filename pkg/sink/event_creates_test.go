@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	triggersv1beta1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/test"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestEventCreates_Reserve(t *testing.T) {
+	var unlimited *eventCreates
+	if !unlimited.reserve(1000) {
+		t.Error("reserve() of a nil counter = false, want true")
+	}
+
+	c := (Sink{MaxCreatesPerEvent: 3}).newEventCreates()
+	for _, tc := range []struct {
+		n    int
+		want bool
+	}{{n: 2, want: true}, {n: 2, want: false}, {n: 1, want: true}, {n: 1, want: false}} {
+		if got := c.reserve(tc.n); got != tc.want {
+			t.Errorf("reserve(%d) after %d reserved = %t, want %t", tc.n, c.reserved, got, tc.want)
+		}
+	}
+	if c := (Sink{}).newEventCreates(); c != nil {
+		t.Errorf("newEventCreates() without a limit = %v, want nil", c)
+	}
+}
+
+func TestHandleEvent_MaxCreatesPerEvent(t *testing.T) {
+	trigger := func(name string) triggersv1beta1.EventListenerTrigger {
+		return triggersv1beta1.EventListenerTrigger{
+			Name:     name,
+			Template: &triggersv1beta1.EventListenerTemplate{Spec: makeGitCloneTTSpec(t, name+"-run")},
+		}
+	}
+	resources := test.Resources{
+		EventListeners: []*triggersv1beta1.EventListener{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-el",
+				Namespace: namespace,
+				UID:       types.UID(elUID),
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{trigger("first"), trigger("second")},
+			},
+		}},
+	}
+	sink, _ := getSinkAssets(t, resources, "test-el", nil)
+	sink.Synchronous = true
+	sink.MaxCreatesPerEvent = 1
+
+	ts := httptest.NewServer(http.HandlerFunc(sink.HandleEvent))
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL, "application/json", bytes.NewReader([]byte(`{}`)))
+	if err != nil {
+		t.Fatalf("error making request to eventListener: %s", err)
+	}
+	defer resp.Body.Close()
+
+	// One of the triggers creates its resource, and the other one is stopped by the limit.
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("got response code %d, want %d", resp.StatusCode, http.StatusUnprocessableEntity)
+	}
+	var body Response
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Error reading response body: %s", err)
+	}
+	if len(body.Resources) != 1 {
+		t.Errorf("got created resources %v, want a single one", body.Resources)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"fmt"
+	"net/http"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	celinterceptor "github.com/tektoncd/triggers/pkg/interceptors/cel"
+	"github.com/tektoncd/triggers/pkg/resources"
+	"github.com/tektoncd/triggers/pkg/template"
+	"go.uber.org/zap"
+)
+
+// EventIDSource is where the sink takes the event IDs of requests from, such as the delivery ID set by
+// the provider, instead of generating them. Requests without one get a generated UUID.
+type EventIDSource struct {
+	// Headers are the headers holding the event ID. The first one present in a request is used.
+	Headers []string
+	// Expression is a CEL expression returning the event ID, evaluated against the body and headers of
+	// requests that have none of the Headers.
+	Expression string
+	// SecretGetter gets the secrets used by Expression.
+	SecretGetter interceptors.SecretGetter
+}
+
+// eventID returns the ID of the event sent by request with the given body. IDs taken from the request
+// are sanitized with the LabelSanitization strategy, since they are added as labels to the created
+// resources, and ignored if they are rejected.
+func (r Sink) eventID(request *http.Request, event []byte, log *zap.SugaredLogger) string {
+	s := r.EventIDSource
+	if s == nil {
+		return template.UUID()
+	}
+	for _, h := range s.Headers {
+		if id := r.labelSafeEventID(request.Header.Get(h), log); id != "" {
+			return id
+		}
+	}
+	if s.Expression != "" {
+		id, err := s.evaluate(request, event, r.externalURL(request.URL).String())
+		if err != nil {
+			log.Warnf("failed to get the event ID from expression %q, generating one: %v", s.Expression, err)
+		} else if id = r.labelSafeEventID(id, log); id != "" {
+			return id
+		}
+	}
+	return template.UUID()
+}
+
+func (s *EventIDSource) evaluate(request *http.Request, event []byte, eventURL string) (string, error) {
+	val, err := celinterceptor.Evaluate(request.Context(), s.SecretGetter, s.Expression, &triggersv1.InterceptorRequest{
+		Body:   string(event),
+		Header: request.Header,
+		Context: &triggersv1.TriggerContext{
+			EventURL: eventURL,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	id, ok := val.Value().(string)
+	if !ok {
+		return "", fmt.Errorf("expression returned %s, not a string", val.Type().TypeName())
+	}
+	return id, nil
+}
+
+// labelSafeEventID returns id as a valid label value, sanitized with the LabelSanitization strategy. It
+// returns an empty string if the strategy rejects it or if nothing is left.
+func (r Sink) labelSafeEventID(id string, log *zap.SugaredLogger) string {
+	safe, err := resources.SanitizeLabelValue(id, r.LabelSanitization)
+	if err != nil {
+		log.Warnf("ignoring event ID: %v", err)
+		return ""
+	}
+	return safe
+}
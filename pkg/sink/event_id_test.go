@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	triggersv1beta1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/test"
+	"go.uber.org/zap/zaptest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestSink_EventID(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		source   *EventIDSource
+		strategy string
+		header   http.Header
+		body     string
+		want     string
+	}{{
+		name:   "no source",
+		header: http.Header{"X-Github-Delivery": []string{"delivery-1"}},
+		want:   eventID,
+	}, {
+		name:   "header",
+		source: &EventIDSource{Headers: []string{"X-GitHub-Delivery"}},
+		header: http.Header{"X-Github-Delivery": []string{"delivery-1"}},
+		want:   "delivery-1",
+	}, {
+		name:   "first header present",
+		source: &EventIDSource{Headers: []string{"X-Gitlab-Event-UUID", "X-GitHub-Delivery"}},
+		header: http.Header{"X-Github-Delivery": []string{"delivery-1"}},
+		want:   "delivery-1",
+	}, {
+		name:   "sanitized header",
+		source: &EventIDSource{Headers: []string{"X-Request-Id"}},
+		header: http.Header{"X-Request-Id": []string{"req#1"}},
+		want:   "req-1-6b200af7d5",
+	}, {
+		name:     "truncated header",
+		source:   &EventIDSource{Headers: []string{"X-Request-Id"}},
+		strategy: triggers.LabelSanitizationTruncate,
+		header:   http.Header{"X-Request-Id": []string{"req#1"}},
+		want:     "req-1",
+	}, {
+		name:     "rejected header",
+		source:   &EventIDSource{Headers: []string{"X-Request-Id"}},
+		strategy: triggers.LabelSanitizationReject,
+		header:   http.Header{"X-Request-Id": []string{"req#1"}},
+		want:     eventID,
+	}, {
+		name:   "expression",
+		source: &EventIDSource{Headers: []string{"X-GitHub-Delivery"}, Expression: "'order-' + string(body.id)"},
+		body:   `{"id": 42}`,
+		want:   "order-42",
+	}, {
+		name:   "header before expression",
+		source: &EventIDSource{Headers: []string{"X-GitHub-Delivery"}, Expression: "'order-' + string(body.id)"},
+		header: http.Header{"X-Github-Delivery": []string{"delivery-1"}},
+		body:   `{"id": 42}`,
+		want:   "delivery-1",
+	}, {
+		name:   "expression not returning a string",
+		source: &EventIDSource{Expression: "body.id"},
+		body:   `{"id": 42}`,
+		want:   eventID,
+	}, {
+		name:   "failing expression",
+		source: &EventIDSource{Expression: "body.missing"},
+		body:   `{"id": 42}`,
+		want:   eventID,
+	}, {
+		name:   "empty header and expression result",
+		source: &EventIDSource{Headers: []string{"X-GitHub-Delivery"}, Expression: "''"},
+		header: http.Header{"X-Github-Delivery": []string{""}},
+		want:   eventID,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := Sink{EventIDSource: tc.source, LabelSanitization: tc.strategy}
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			for k, v := range tc.header {
+				req.Header[k] = v
+			}
+			if got := s.eventID(req, []byte(tc.body), zaptest.NewLogger(t).Sugar()); got != tc.want {
+				t.Errorf("eventID() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHandleEvent_EventIDFromHeader(t *testing.T) {
+	resources := test.Resources{
+		EventListeners: []*triggersv1beta1.EventListener{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-el",
+				Namespace: namespace,
+				UID:       types.UID(elUID),
+			},
+		}},
+	}
+	sink, _ := getSinkAssets(t, resources, "test-el", nil)
+	sink.EventIDSource = &EventIDSource{Headers: []string{"X-GitHub-Delivery"}}
+
+	ts := httptest.NewServer(http.HandlerFunc(sink.HandleEvent))
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL, bytes.NewReader([]byte(`{}`)))
+	if err != nil {
+		t.Fatalf("http.NewRequest: %v", err)
+	}
+	req.Header.Set("X-GitHub-Delivery", "72d4a5a4-1a7e-11ed-861d-0242ac120002")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("error making request to eventListener: %s", err)
+	}
+	defer resp.Body.Close()
+	sink.WGProcessTriggers.Wait()
+
+	var body Response
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Error reading response body: %s", err)
+	}
+	if body.EventID != "72d4a5a4-1a7e-11ed-861d-0242ac120002" {
+		t.Errorf("got event ID %q, want the X-GitHub-Delivery header", body.EventID)
+	}
+}
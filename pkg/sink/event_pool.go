@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+)
+
+const (
+	// eventPoolFullReason is the reason recorded when events are rejected because all the workers are busy
+	// and the queue is full.
+	eventPoolFullReason = "full"
+	// eventPoolCanceledReason is the reason recorded when queued events are rejected because their request
+	// was canceled before a worker was free.
+	eventPoolCanceledReason = "canceled"
+)
+
+// EventPool bounds the number of events processed at a time. An event holds a worker from the time it is
+// received until all of its triggers are processed, even if the sink responds before, and its body is
+// only read once it holds one. The events received while all the Workers are busy wait in a queue of
+// QueueSize events, and the events that don't fit in the queue are rejected with 429 Too Many Requests,
+// unless Overflow is triggers.EventOverflowWait, in which case they wait as well.
+//
+// A nil *EventPool processes any number of events at a time.
+type EventPool struct {
+	// Workers is the number of events processed at a time.
+	Workers int
+	// QueueSize is the number of events that wait for a worker before the next ones overflow.
+	QueueSize int
+	// Overflow is what happens to the events that don't fit in the queue: triggers.EventOverflowReject or
+	// triggers.EventOverflowWait. Defaults to reject.
+	Overflow string
+	// RetryAfter is the duration the senders of rejected events are asked to wait via the Retry-After header.
+	RetryAfter time.Duration
+
+	once   sync.Once
+	slots  chan struct{}
+	queued int64
+}
+
+func (p *EventPool) retryAfter() time.Duration {
+	if p.RetryAfter <= 0 {
+		return defaultRetryAfter
+	}
+	return p.RetryAfter
+}
+
+func (p *EventPool) init() {
+	p.once.Do(func() {
+		p.slots = make(chan struct{}, p.Workers)
+	})
+}
+
+// busy returns the number of workers processing events.
+func (p *EventPool) busy() int64 {
+	if p == nil {
+		return 0
+	}
+	p.init()
+	return int64(len(p.slots))
+}
+
+// waiting returns the number of events waiting for a worker.
+func (p *EventPool) waiting() int64 {
+	if p == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&p.queued)
+}
+
+// acquire takes a worker for an event, waiting for one to be free if the event fits in the queue or the
+// overflow is to wait, until ctx is done. It returns the function releasing the worker, or false and the reason
+// if the event is rejected.
+func (p *EventPool) acquire(ctx context.Context) (func(), bool, string) {
+	if p == nil {
+		return func() {}, true, ""
+	}
+	p.init()
+	release := func() { <-p.slots }
+	select {
+	case p.slots <- struct{}{}:
+		return release, true, ""
+	default:
+	}
+
+	if queued := atomic.AddInt64(&p.queued, 1); queued > int64(p.QueueSize) && p.Overflow != triggers.EventOverflowWait {
+		atomic.AddInt64(&p.queued, -1)
+		return nil, false, eventPoolFullReason
+	}
+	defer atomic.AddInt64(&p.queued, -1)
+	select {
+	case p.slots <- struct{}{}:
+		return release, true, ""
+	case <-ctx.Done():
+		return nil, false, eventPoolCanceledReason
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	triggersv1beta1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/test"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestEventPool_Acquire(t *testing.T) {
+	var unbounded *EventPool
+	if _, ok, _ := unbounded.acquire(context.Background()); !ok {
+		t.Error("acquire() of a nil pool = false, want true")
+	}
+
+	p := &EventPool{Workers: 1, QueueSize: 1}
+	release, ok, _ := p.acquire(context.Background())
+	if !ok {
+		t.Fatal("acquire() of a free worker = false, want true")
+	}
+
+	// The next event waits in the queue, and the one after overflows.
+	queued := make(chan bool)
+	go func() {
+		release, ok, _ := p.acquire(context.Background())
+		if ok {
+			release()
+		}
+		queued <- ok
+	}()
+	for p.waiting() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, ok, reason := p.acquire(context.Background()); ok || reason != eventPoolFullReason {
+		t.Errorf("acquire() with a full queue = %t, %q, want false, %q", ok, reason, eventPoolFullReason)
+	}
+	if got := p.busy(); got != 1 {
+		t.Errorf("busy() = %d, want 1", got)
+	}
+
+	release()
+	if ok := <-queued; !ok {
+		t.Error("acquire() of a queued event = false, want true")
+	}
+	if got, want := [2]int64{p.busy(), p.waiting()}, [2]int64{0, 0}; got != want {
+		t.Errorf("busy() and waiting() once released = %v, want %v", got, want)
+	}
+}
+
+func TestEventPool_AcquireOverflow(t *testing.T) {
+	p := &EventPool{Workers: 1, Overflow: triggers.EventOverflowWait}
+	release, _, _ := p.acquire(context.Background())
+	defer release()
+
+	// With a wait overflow, the events beyond the queue wait as well, until their request is canceled.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, ok, reason := p.acquire(ctx); ok || reason != eventPoolCanceledReason {
+		t.Errorf("acquire() of a canceled request = %t, %q, want false, %q", ok, reason, eventPoolCanceledReason)
+	}
+	if got := p.waiting(); got != 0 {
+		t.Errorf("waiting() once canceled = %d, want 0", got)
+	}
+}
+
+func TestHandleEvent_EventPool(t *testing.T) {
+	resources := test.Resources{
+		EventListeners: []*triggersv1beta1.EventListener{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-el",
+				Namespace: namespace,
+				UID:       types.UID(elUID),
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Name:     "git-clone",
+					Template: &triggersv1beta1.EventListenerTemplate{Spec: makeGitCloneTTSpec(t, "git-clone-run")},
+				}},
+			},
+		}},
+	}
+	sink, _ := getSinkAssets(t, resources, "test-el", nil)
+	sink.EventPool = &EventPool{Workers: 1, RetryAfter: 45 * time.Second}
+
+	ts := httptest.NewServer(http.HandlerFunc(sink.HandleEvent))
+	defer ts.Close()
+
+	// The event is rejected while another one holds the only worker.
+	release, _, _ := sink.EventPool.acquire(context.Background())
+	resp, err := http.Post(ts.URL, "application/json", bytes.NewReader([]byte(`{}`)))
+	if err != nil {
+		t.Fatalf("error making request to eventListener: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("got response code %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+	}
+	if got := resp.Header.Get("Retry-After"); got != "45" {
+		t.Errorf("got Retry-After header %q, want %q", got, "45")
+	}
+	// The body of a rejected event isn't read.
+	body := &readRecorder{Reader: bytes.NewReader([]byte(`{}`))}
+	rec := httptest.NewRecorder()
+	sink.HandleEvent(rec, httptest.NewRequest(http.MethodPost, "/", body))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("got response code %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if body.read {
+		t.Error("the body of a rejected event was read")
+	}
+
+	release()
+	resp, err = http.Post(ts.URL, "application/json", bytes.NewReader([]byte(`{}`)))
+	if err != nil {
+		t.Fatalf("error making request to eventListener: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("got response code %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+	// The worker is released once the triggers of the event are processed.
+	sink.WGProcessTriggers.Wait()
+	for deadline := time.Now().Add(5 * time.Second); sink.EventPool.busy() != 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if got := sink.EventPool.busy(); got != 0 {
+		t.Errorf("busy() once the event is processed = %d, want 0", got)
+	}
+}
+
+// readRecorder records whether its Reader was read.
+type readRecorder struct {
+	io.Reader
+	read bool
+}
+
+func (r *readRecorder) Read(p []byte) (int, error) {
+	r.read = true
+	return r.Reader.Read(p)
+}
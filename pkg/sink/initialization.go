@@ -18,12 +18,19 @@ package sink
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
+	"net"
+	"net/http"
+	"strings"
 	"time"
 
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
 	triggersclientset "github.com/tektoncd/triggers/pkg/client/clientset/versioned"
 	"github.com/tektoncd/triggers/pkg/sink/cloudevent"
+	"go.uber.org/zap/zapcore"
 	"golang.org/x/xerrors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
 	discoveryclient "k8s.io/client-go/discovery"
 	kubeclientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -47,6 +54,8 @@ var (
 		"The port for the EventListener sink to listen on.")
 	elReadTimeOut = flag.Int64("readtimeout", 5,
 		"The read timeout for EventListener Server.")
+	elReadHeaderTimeOut = flag.Int64("readheadertimeout", 5,
+		"The timeout for reading the request headers for EventListener Server.")
 	elWriteTimeOut = flag.Int64("writetimeout", 40,
 		"The write timeout for EventListener Server.")
 	elIdleTimeOut = flag.Int64("idletimeout", 30,
@@ -63,15 +72,151 @@ var (
 		"The HTTP Client read timeout for EventListener Server.")
 	elHTTPClientExpectContinueTimeout = flag.Int64("httpclient-expectcontinuetimeout", 1,
 		"The HTTP Client read timeout for EventListener Server.")
+	resourceClientQPS = flag.Float64("resource-client-qps", 50,
+		"The QPS of the dynamic and discovery clients creating resources. 0 uses the client-go default.")
+	resourceClientBurst = flag.Int("resource-client-burst", 100,
+		"The burst of the dynamic and discovery clients creating resources. 0 uses the client-go default.")
+	resourceClientMaxIdleConns = flag.Int("resource-client-max-idle-conns", 50,
+		"The maximum number of idle connections to the API server kept by the dynamic and discovery clients. 0 uses the client-go default.")
 	isMultiNSFlag = flag.Bool("is-multi-ns", false,
 		"Whether EventListener serve Multiple NS.")
 	tlsCertFlag = flag.String("tls-cert", "",
 		"The filename for the TLS certificate.")
 	tlsKeyFlag = flag.String("tls-key", "",
 		"The filename for the TLS key.")
+	tlsClientCAFlag = flag.String("tls-client-ca", "",
+		"The filename for the CA bundle used to verify client certificates.")
+	tlsMinVersion = flag.String("tls-min-version", "",
+		"The minimum TLS version, 1.2 or 1.3, of the server and of the clients of interceptors.")
+	tlsCipherSuites = flag.String("tls-cipher-suites", "",
+		"The comma separated TLS 1.2 cipher suites of the server and of the clients of interceptors.")
 	payloadValidation = flag.Bool("payload-validation", true,
 		"Whether to disable payload validation or not.")
-	cloudEventURI = flag.String("cloudevent-uri", "", "uri for cloudevent")
+	cloudEventURI           = flag.String("cloudevent-uri", "", "uri for cloudevent")
+	backpressureMaxInFlight = flag.Int64("backpressure-max-in-flight", 0,
+		"The number of in-flight resource creations above which events are rejected. 0 disables backpressure.")
+	backpressureRetryAfter = flag.Int64("backpressure-retry-after", 30,
+		"The number of seconds senders are asked to wait before retrying a rejected event.")
+	interceptorTimeout = flag.Duration("interceptor-timeout", 0,
+		"The total time budget for executing the interceptor chain of a trigger. 0 means no limit.")
+	allowedMethods = flag.String("allowed-methods", "",
+		"Comma separated list of HTTP methods accepted by the EventListener. Defaults to POST.")
+	allowedContentTypes = flag.String("allowed-content-types", "",
+		"Comma separated list of media types accepted by the EventListener when payload validation is enabled. Defaults to application/json and application/*+json.")
+	creationLimit = flag.Int64("creation-limit", 0,
+		"The number of resources that can be created by this replica per creation limit window. 0 disables the limit.")
+	creationLimitWindow = flag.Duration("creation-limit-window", time.Minute,
+		"The length of the window the creation limit applies to.")
+	creationLimitPerTrigger = flag.Bool("creation-limit-per-trigger", false,
+		"Whether the creation limit applies to each trigger separately instead of to all triggers combined.")
+	maxCreatesPerEvent = flag.Int("max-creates-per-event", DefaultMaxCreatesPerEvent,
+		"The number of resources that can be created for a single event, across all triggers.")
+	createTimeout = flag.Duration("create-timeout", 2*time.Minute,
+		"The time after which the creation of a resource is abandoned. 0 means no limit.")
+	quotaRetryWindow = flag.Duration("quota-retry-window", 0,
+		"How long the creates that exceed a resource quota are retried before being dropped. 0 disables the retries.")
+	quotaRetryMaxQueued = flag.Int("quota-retry-max-queued", 100,
+		"The number of creates that exceeded a resource quota that can be retried at a time.")
+	quotaRetryPersist = flag.Bool("quota-retry-persist", false,
+		"Whether to persist the creates that exceeded a resource quota in a Secret and replay them on restart.")
+	h2cFlag = flag.Bool("h2c", false,
+		"Whether to serve HTTP/2 over cleartext connections in addition to HTTP/1.1.")
+	activityInterval = flag.Duration("activity-interval", 0,
+		"The minimum time between two updates of the recent activity in the EventListener status. 0 disables recording recent activity.")
+	provenanceLabels = flag.String("provenance-labels", "",
+		"Comma separated list of the provenance labels added to created resources: eventlistener, eventid and trigger, or none. Defaults to all of them.")
+	accessLog = flag.Bool("access-log", false,
+		"Whether to write an access log entry for each incoming request.")
+	accessLogFields = flag.String("access-log-fields", "",
+		"Comma separated list of the fields of the access log entries. Defaults to all fields except the payload.")
+	accessLogSampleRate = flag.Float64("access-log-sample-rate", 1,
+		"The fraction of the successfully handled requests that get an access log entry. Rejected requests are always logged.")
+	basePath = flag.String("base-path", "",
+		"The path prefix under which a reverse proxy exposes the EventListener, e.g. /webhooks/tekton.")
+	trustedProxies = flag.String("trusted-proxies", "",
+		"Comma separated list of the CIDRs or IP addresses of the proxies whose forwarding headers are trusted for the client IP.")
+	trustedProxyHeader = flag.String("trusted-proxy-header", "",
+		"The forwarding header that the trusted proxies set, X-Forwarded-For or Forwarded. Defaults to X-Forwarded-For.")
+	proxyProtocolUpstreams = flag.String("proxy-protocol-upstreams", "",
+		"Comma separated list of the CIDRs or IP addresses of the load balancers that send a PROXY protocol header at the start of their connections.")
+	eventIDHeaders = flag.String("event-id-headers", "",
+		"Comma separated list of the headers holding the event ID of requests, used instead of a generated UUID.")
+	eventIDExpression = flag.String("event-id-expression", "",
+		"CEL expression returning the event ID of requests that have none of the event ID headers.")
+	fieldValidation = flag.String("field-validation", "Warn",
+		"How the API server handles unknown and duplicate fields in created resources: Ignore, Warn or Strict.")
+	labelSanitization = flag.String("label-sanitization", "hash",
+		"How the invalid label values of created resources are sanitized: truncate, hash or reject.")
+	synchronous = flag.Bool("synchronous", false,
+		"Whether to respond once the triggers of an event are processed, with a status code reflecting their outcome.")
+	multiStatus = flag.Bool("multi-status", false,
+		"Whether synchronous responses have the outcome of each trigger and resource, with 207 Multi-Status if they are mixed.")
+	rollbackOnFailure = flag.Bool("rollback-on-failure", false,
+		"Whether to delete the resources a trigger created for an event when it fails to create the next ones.")
+	createFailurePolicy = flag.String("create-failure-policy", triggers.CreateFailureFailFast,
+		"Whether a trigger stops at the first resource it fails to create, or still creates the next ones: fail-fast or best-effort.")
+	auditSink = flag.String("audit-sink", "",
+		"The file or s3 URL of the sink an audit record of each created resource is written to. Empty disables the audit records.")
+	auditMode = flag.String("audit-mode", triggers.AuditModeAlongside,
+		"Whether resources are created alongside their audit records, or only recorded instead: alongside or instead.")
+	auditFailurePolicy = flag.String("audit-failure-policy", triggers.AuditFailureFatal,
+		"Whether failing to write an audit record fails the creation of the resource: fatal or best-effort.")
+	payloadParsers = flag.String("payload-parsers", "",
+		"Comma separated list of the optional payload parsers applied to request bodies in addition to the JSON one: form and gzip.")
+	selfTestSecret = flag.String("self-test-secret", "",
+		"The name of the secret holding the bearer token of the self-test endpoint. Empty disables the endpoint.")
+	configSecret = flag.String("config-secret", "",
+		"The name of the secret holding the bearer token of the config endpoint. Empty disables the endpoint.")
+	batchSize = flag.Int("batch-size", 0,
+		"The maximum number of events of the requests to the batch endpoint. 0 disables the endpoint.")
+	resourcePolicies = flag.String("resource-policies", "",
+		"Comma separated list of the ConfigMaps holding the policies that created resources are checked against.")
+	mutationHookURL = flag.String("mutation-hook-url", "",
+		"The http or https URL of the hook that patches the resources before they are created. Empty disables the hook.")
+	mutationHookTimeout = flag.Duration("mutation-hook-timeout", 10*time.Second,
+		"The timeout of each call to the mutation hook.")
+	mutationHookFailurePolicy = flag.String("mutation-hook-failure-policy", triggers.MutationHookFailClosed,
+		"Whether a failed call to the mutation hook fails the creation of the resource: fail-closed or fail-open.")
+	callbackURL = flag.String("callback-url", "",
+		"The http or https URL notified each time a trigger creates resources. Empty disables the callbacks.")
+	callbackPayload = flag.String("callback-payload", "",
+		"The JSON template of the callback notifications. Defaults to the event ID, the trigger and the created resources.")
+	callbackSecret = flag.String("callback-secret", "",
+		"The name of the secret holding the bearer token of the callback notifications. Empty sends no token.")
+	callbackTimeout = flag.Duration("callback-timeout", 10*time.Second,
+		"The timeout of each attempt to send a callback notification.")
+	callbackRetries = flag.Int("callback-retries", 3,
+		"The number of times a callback notification that failed is sent again.")
+	resourceEvents = flag.String("resource-events", "",
+		"Comma separated list of the outcomes of resource creations, created and failed, for which a CloudEvent is sent. Empty sends none.")
+	resourceEventsURI = flag.String("resource-events-uri", "",
+		"The http or https URL of the sink of the CloudEvents of resources. Defaults to the cloudevent URI.")
+	resourceEventsSource = flag.String("resource-events-source", "",
+		"The source of the CloudEvents of resources. Defaults to the path of the EventListener.")
+	debugTraceSecret = flag.String("debug-trace-secret", "",
+		"The name of the secret holding the token of the Tekton-Debug-Trace header of the requests asking for a debug trace. Empty disables the header.")
+	debugTraceSampleRate = flag.Float64("debug-trace-sample-rate", 0,
+		"The fraction of the events that get a debug trace without asking for it.")
+	interceptorMetricsNames = flag.Int("interceptor-metrics-names", 20,
+		"The number of distinct interceptor names that tag the interceptor metrics. The other interceptors are tagged as other.")
+	resourceMetricsNamespaces = flag.Int("resource-metrics-namespaces", -1,
+		"The number of distinct namespaces that tag the metric of the created resources. The other namespaces are tagged as other. Negative tags all namespaces.")
+	noMatchPolicy = flag.String("no-match-policy", "ignore",
+		"How the events that match no triggers are responded to: ignore with 202 Accepted, or error with 422 Unprocessable Entity.")
+	noMatchLogLevel = flag.String("no-match-log-level", "info",
+		"The level at which the events that match no triggers are logged: debug, info, warn or error.")
+	discoveryGracePeriod = flag.Duration("discovery-grace-period", 2*time.Minute,
+		"How long the discovery API may be unavailable at startup before the EventListener exits.")
+	discoveryBackoff = flag.Duration("discovery-backoff", time.Second,
+		"The delay before trying to reach an unavailable discovery API again, doubled after each failed attempt up to 30s.")
+	eventWorkers = flag.Int("event-workers", 0,
+		"The number of events processed at a time. Zero processes any number of events at a time.")
+	eventQueueSize = flag.Int("event-queue-size", 0,
+		"The number of events that wait for a worker while all the event workers are busy.")
+	eventOverflow = flag.String("event-overflow", "reject",
+		"What happens to the events that don't fit in the queue of the event workers: reject with 429 Too Many Requests, or wait.")
+	logFormat = flag.String("log-format", "",
+		"The format of the logs: empty, for the format of the logging config, or json for JSON with stable correlation fields.")
 )
 
 // Args define the arguments for Sink.
@@ -84,6 +229,8 @@ type Args struct {
 	Port string
 	// ELReadTimeOut defines the read timeout for EventListener Server
 	ELReadTimeOut time.Duration
+	// ELReadHeaderTimeOut defines the timeout for reading the request headers for EventListener Server
+	ELReadHeaderTimeOut time.Duration
 	// ELWriteTimeOut defines the write timeout for EventListener Server
 	ELWriteTimeOut time.Duration
 	// ELIdleTimeOut defines the read timeout for EventListener Server
@@ -100,16 +247,158 @@ type Args struct {
 	ElHTTPClientResponseHeaderTimeout time.Duration
 	// ElExpectContinueTimeout defines the Expect timeout for HTTP Client
 	ElHTTPClientExpectContinueTimeout time.Duration
+	// ResourceClientQPS defines the QPS of the dynamic and discovery clients creating resources
+	ResourceClientQPS float32
+	// ResourceClientBurst defines the burst of the dynamic and discovery clients creating resources
+	ResourceClientBurst int
+	// ResourceClientMaxIdleConns defines the maximum number of idle connections to the API server kept by
+	// the dynamic and discovery clients creating resources
+	ResourceClientMaxIdleConns int
 	// IsMultiNS determines whether el functions as namespaced or clustered
 	IsMultiNS bool
 	// Key defines the filename for tls Key.
 	Key string
 	// Cert defines the filename for tls Cert.
 	Cert string
+	// ClientCA defines the filename for the CA bundle used to verify client certificates.
+	ClientCA string
+	// TLSMinVersion defines the minimum TLS version of the server and of the clients of interceptors, or 0
+	// for their defaults
+	TLSMinVersion uint16
+	// TLSCipherSuites defines the TLS 1.2 cipher suites of the server and of the clients of interceptors, or
+	// nil for the cipher suites of Go
+	TLSCipherSuites []uint16
 	// PayloadValidation defines whether to validate payload or not
 	PayloadValidation bool
 	// CloudEventURI refers to the location where cloudevent data need to be send
 	CloudEventURI string
+	// BackpressureMaxInFlight defines the number of in-flight resource creations above which events are rejected
+	BackpressureMaxInFlight int64
+	// BackpressureRetryAfter defines the number of seconds senders are asked to wait before retrying a rejected event
+	BackpressureRetryAfter int64
+	// InterceptorTimeout defines the total time budget for executing the interceptor chain of a trigger
+	InterceptorTimeout time.Duration
+	// AllowedMethods defines the HTTP methods accepted by the EventListener
+	AllowedMethods []string
+	// AllowedContentTypes defines the media types accepted by the EventListener
+	AllowedContentTypes []string
+	// CreationLimit defines the number of resources that can be created by this replica per CreationLimitWindow
+	CreationLimit int64
+	// CreationLimitWindow defines the length of the window the creation limit applies to
+	CreationLimitWindow time.Duration
+	// CreationLimitPerTrigger defines whether the creation limit applies to each trigger separately
+	CreationLimitPerTrigger bool
+	// MaxCreatesPerEvent defines the number of resources that can be created for a single event
+	MaxCreatesPerEvent int
+	// CreateTimeout defines the time after which the creation of a resource is abandoned
+	CreateTimeout time.Duration
+	// QuotaRetryWindow defines how long the creates that exceed a resource quota are retried
+	QuotaRetryWindow time.Duration
+	// QuotaRetryMaxQueued defines the number of creates that exceeded a resource quota that can be retried at a time
+	QuotaRetryMaxQueued int
+	// QuotaRetryPersist defines whether to persist the creates that exceeded a resource quota and replay them on restart
+	QuotaRetryPersist bool
+	// H2C defines whether to serve HTTP/2 over cleartext connections in addition to HTTP/1.1
+	H2C bool
+	// ActivityInterval defines the minimum time between two updates of the recent activity in the EventListener status
+	ActivityInterval time.Duration
+	// ProvenanceLabels defines the keys of the provenance labels added to created resources. All of them are added if nil
+	ProvenanceLabels []string
+	// AccessLog defines whether to write an access log entry for each incoming request
+	AccessLog bool
+	// AccessLogFields defines the fields of the access log entries
+	AccessLogFields []string
+	// AccessLogSampleRate defines the fraction of the successfully handled requests that get an access log entry
+	AccessLogSampleRate float64
+	// BasePath defines the path prefix under which a reverse proxy exposes the EventListener, without a trailing slash
+	BasePath string
+	// TrustedProxies defines the networks of the proxies whose forwarding headers are trusted for the client IP
+	TrustedProxies []*net.IPNet
+	// TrustedProxyHeader defines the forwarding header that the trusted proxies set, empty for X-Forwarded-For
+	TrustedProxyHeader string
+	// ProxyProtocolUpstreams defines the networks of the load balancers that send a PROXY protocol header
+	ProxyProtocolUpstreams []*net.IPNet
+	// EventIDHeaders defines the headers holding the event ID of requests
+	EventIDHeaders []string
+	// EventIDExpression defines the CEL expression returning the event ID of requests without EventIDHeaders
+	EventIDExpression string
+	// FieldValidation defines how the API server handles unknown and duplicate fields in created resources
+	FieldValidation string
+	// LabelSanitization defines how the invalid label values of created resources are sanitized
+	LabelSanitization string
+	// Synchronous defines whether to respond once the triggers of an event are processed
+	Synchronous bool
+	// MultiStatus defines whether synchronous responses have the outcome of each trigger and resource
+	MultiStatus bool
+	// RollbackOnFailure defines whether to delete the resources created by a trigger that fails to create all of them
+	RollbackOnFailure bool
+	// CreateBestEffort defines whether a trigger still creates the next resources after failing to create one
+	CreateBestEffort bool
+	// AuditSink defines the URL of the sink the audit records of created resources are written to
+	AuditSink string
+	// AuditOnly defines whether resources are only written to the audit sink instead of being created
+	AuditOnly bool
+	// AuditBestEffort defines whether failures to write audit records are only logged
+	AuditBestEffort bool
+	// PayloadParsers defines the names of the optional payload parsers applied to request bodies
+	PayloadParsers []string
+	// SelfTestSecret defines the name of the secret holding the bearer token of the self-test endpoint
+	SelfTestSecret string
+	// ConfigEndpointSecret defines the name of the secret holding the bearer token of the config endpoint
+	ConfigEndpointSecret string
+	// BatchSize defines the maximum number of events of the requests to the batch endpoint
+	BatchSize int
+	// ResourcePolicies defines the names of the ConfigMaps holding the policies created resources are checked against
+	ResourcePolicies []string
+	// MutationHookURL defines the URL of the hook that patches the resources before they are created
+	MutationHookURL string
+	// MutationHookTimeout defines the timeout of each call to the mutation hook
+	MutationHookTimeout time.Duration
+	// MutationHookFailOpen defines whether the resources are created unmodified when the mutation hook fails
+	MutationHookFailOpen bool
+	// CallbackURL defines the URL notified each time a trigger creates resources
+	CallbackURL string
+	// CallbackPayload defines the JSON template of the callback notifications
+	CallbackPayload string
+	// CallbackSecret defines the name of the secret holding the bearer token of the callback notifications
+	CallbackSecret string
+	// CallbackTimeout defines the timeout of each attempt to send a callback notification
+	CallbackTimeout time.Duration
+	// CallbackRetries defines the number of times a callback notification that failed is sent again
+	CallbackRetries int
+	// ResourceEventsCreated defines whether a CloudEvent is sent for each created resource
+	ResourceEventsCreated bool
+	// ResourceEventsFailed defines whether a CloudEvent is sent for each resource that could not be created
+	ResourceEventsFailed bool
+	// ResourceEventsURI defines the URI of the sink of the CloudEvents of resources
+	ResourceEventsURI string
+	// ResourceEventsSource defines the source of the CloudEvents of resources
+	ResourceEventsSource string
+	// DebugTraceSecret defines the name of the secret holding the token of the requests asking for a debug trace
+	DebugTraceSecret string
+	// DebugTraceSampleRate defines the fraction of the events that get a debug trace without asking for it
+	DebugTraceSampleRate float64
+	// InterceptorMetricsNames defines the number of distinct interceptor names that tag the interceptor metrics
+	InterceptorMetricsNames int
+	// ResourceMetricsNamespaces defines the number of distinct namespaces that tag the metric of the created resources,
+	// or all of them if negative
+	ResourceMetricsNamespaces int
+	// LogFormat defines the format of the logs, empty for the format of the logging config or json
+	LogFormat string
+	// NoMatchPolicy defines how the events that match no triggers are responded to, ignore or error
+	NoMatchPolicy string
+	// NoMatchLogLevel defines the level at which the events that match no triggers are logged
+	NoMatchLogLevel zapcore.Level
+	// DiscoveryGracePeriod defines how long the discovery API may be unavailable at startup
+	DiscoveryGracePeriod time.Duration
+	// DiscoveryBackoff defines the initial delay between the attempts to reach an unavailable discovery API
+	DiscoveryBackoff time.Duration
+	// EventWorkers defines the number of events processed at a time, unbounded if 0
+	EventWorkers int
+	// EventQueueSize defines the number of events that wait for a worker while all the event workers are busy
+	EventQueueSize int
+	// EventOverflow defines what happens to the events that don't fit in the queue, reject or wait
+	EventOverflow string
 }
 
 // Clients define the set of client dependencies Sink requires.
@@ -119,6 +408,9 @@ type Clients struct {
 	TriggersClient  triggersclientset.Interface
 	K8sClient       *kubeclientset.Clientset
 	CEClient        cloudevent.CEClient
+	// ResourceConfig is the REST config of the clients creating resources, if it differs from the
+	// cluster config.
+	ResourceConfig *rest.Config
 }
 
 // GetArgs returns the flagged Args
@@ -133,6 +425,145 @@ func GetArgs() (Args, error) {
 	if *portFlag == "" {
 		return Args{}, xerrors.Errorf("-%s arg not found", port)
 	}
+	var labels []string
+	if *provenanceLabels != "" {
+		var err error
+		if labels, err = triggers.ParseProvenanceLabels(*provenanceLabels); err != nil {
+			return Args{}, xerrors.Errorf("invalid -provenance-labels arg: %w", err)
+		}
+	}
+	fields := triggers.DefaultAccessLogFields
+	if *accessLogFields != "" {
+		var err error
+		if fields, err = triggers.ParseAccessLogFields(*accessLogFields); err != nil {
+			return Args{}, xerrors.Errorf("invalid -access-log-fields arg: %w", err)
+		}
+	}
+	if *accessLogSampleRate < 0 || *accessLogSampleRate > 1 {
+		return Args{}, xerrors.Errorf("invalid -access-log-sample-rate arg %v: must be between 0 and 1", *accessLogSampleRate)
+	}
+	if *debugTraceSampleRate < 0 || *debugTraceSampleRate > 1 {
+		return Args{}, xerrors.Errorf("invalid -debug-trace-sample-rate arg %v: must be between 0 and 1", *debugTraceSampleRate)
+	}
+	if *basePath != "" {
+		if err := triggers.ValidateBasePath(*basePath); err != nil {
+			return Args{}, xerrors.Errorf("invalid -base-path arg: %w", err)
+		}
+	}
+	if *resourceClientQPS < 0 || *resourceClientBurst < 0 || *resourceClientMaxIdleConns < 0 {
+		return Args{}, xerrors.Errorf("invalid -resource-client-qps, -resource-client-burst or -resource-client-max-idle-conns arg: must not be negative")
+	}
+	if *resourceClientQPS > 0 && *resourceClientBurst == 0 {
+		return Args{}, xerrors.Errorf("invalid -resource-client-burst arg: must be set when -resource-client-qps is")
+	}
+	if err := triggers.ValidateFieldValidation(*fieldValidation); err != nil {
+		return Args{}, xerrors.Errorf("invalid -field-validation arg %q: %w", *fieldValidation, err)
+	}
+	if err := triggers.ValidateLabelSanitization(*labelSanitization); err != nil {
+		return Args{}, xerrors.Errorf("invalid -label-sanitization arg %q: %w", *labelSanitization, err)
+	}
+	if *auditSink != "" {
+		if err := triggers.ValidateAuditSink(*auditSink); err != nil {
+			return Args{}, xerrors.Errorf("invalid -audit-sink arg: %w", err)
+		}
+	}
+	if err := triggers.ValidateAuditMode(*auditMode); err != nil {
+		return Args{}, xerrors.Errorf("invalid -audit-mode arg %q: %w", *auditMode, err)
+	}
+	if *eventWorkers < 0 || *eventQueueSize < 0 {
+		return Args{}, xerrors.Errorf("invalid -event-workers or -event-queue-size arg: must not be negative")
+	}
+	if err := triggers.ValidateEventOverflow(*eventOverflow); err != nil {
+		return Args{}, xerrors.Errorf("invalid -event-overflow arg %q: %w", *eventOverflow, err)
+	}
+	if err := triggers.ValidateCreateFailurePolicy(*createFailurePolicy); err != nil {
+		return Args{}, xerrors.Errorf("invalid -create-failure-policy arg %q: %w", *createFailurePolicy, err)
+	}
+	if err := triggers.ValidateAuditFailurePolicy(*auditFailurePolicy); err != nil {
+		return Args{}, xerrors.Errorf("invalid -audit-failure-policy arg %q: %w", *auditFailurePolicy, err)
+	}
+	if *mutationHookURL != "" {
+		if err := triggers.ValidateMutationHookURL(*mutationHookURL); err != nil {
+			return Args{}, xerrors.Errorf("invalid -mutation-hook-url arg: %w", err)
+		}
+	}
+	if err := triggers.ValidateMutationHookFailurePolicy(*mutationHookFailurePolicy); err != nil {
+		return Args{}, xerrors.Errorf("invalid -mutation-hook-failure-policy arg %q: %w", *mutationHookFailurePolicy, err)
+	}
+	if *callbackURL != "" {
+		if err := triggers.ValidateCallbackURL(*callbackURL); err != nil {
+			return Args{}, xerrors.Errorf("invalid -callback-url arg: %w", err)
+		}
+	}
+	if *callbackPayload != "" && !json.Valid([]byte(*callbackPayload)) {
+		return Args{}, xerrors.Errorf("invalid -callback-payload arg: not a JSON document")
+	}
+	var resourceEventsCreated, resourceEventsFailed bool
+	if *resourceEvents != "" {
+		var err error
+		if resourceEventsCreated, resourceEventsFailed, err = triggers.ParseResourceEvents(*resourceEvents); err != nil {
+			return Args{}, xerrors.Errorf("invalid -resource-events arg: %w", err)
+		}
+	}
+	if *resourceEventsURI != "" {
+		if err := triggers.ValidateResourceEventsURI(*resourceEventsURI); err != nil {
+			return Args{}, xerrors.Errorf("invalid -resource-events-uri arg: %w", err)
+		}
+	}
+	var parsers []string
+	if *payloadParsers != "" {
+		var err error
+		if parsers, err = triggers.ParsePayloadParsers(*payloadParsers); err != nil {
+			return Args{}, xerrors.Errorf("invalid -payload-parsers arg: %w", err)
+		}
+	}
+	var minVersion uint16
+	if *tlsMinVersion != "" {
+		var err error
+		if minVersion, err = triggers.ParseTLSVersion(*tlsMinVersion); err != nil {
+			return Args{}, xerrors.Errorf("invalid -tls-min-version arg: %w", err)
+		}
+	}
+	var proxies []*net.IPNet
+	if *trustedProxies != "" {
+		var err error
+		if proxies, err = triggers.ParseTrustedProxies(*trustedProxies); err != nil {
+			return Args{}, xerrors.Errorf("invalid -trusted-proxies arg: %w", err)
+		}
+	}
+	var upstreams []*net.IPNet
+	if *proxyProtocolUpstreams != "" {
+		var err error
+		if upstreams, err = triggers.ParseTrustedProxies(*proxyProtocolUpstreams); err != nil {
+			return Args{}, xerrors.Errorf("invalid -proxy-protocol-upstreams arg: %w", err)
+		}
+	}
+	if *interceptorMetricsNames < 0 {
+		return Args{}, xerrors.Errorf("invalid -interceptor-metrics-names arg: must not be negative")
+	}
+	if err := triggers.ValidateTrustedProxyHeader(*trustedProxyHeader); err != nil {
+		return Args{}, xerrors.Errorf("invalid -trusted-proxy-header arg: %w", err)
+	}
+	if err := triggers.ValidateLogFormat(*logFormat); err != nil {
+		return Args{}, xerrors.Errorf("invalid -log-format arg: %w", err)
+	}
+	if err := triggers.ValidateNoMatchPolicy(*noMatchPolicy); err != nil {
+		return Args{}, xerrors.Errorf("invalid -no-match-policy arg %q: %w", *noMatchPolicy, err)
+	}
+	var noMatchLevel zapcore.Level
+	if err := triggers.ValidateNoMatchLogLevel(*noMatchLogLevel); err != nil {
+		return Args{}, xerrors.Errorf("invalid -no-match-log-level arg %q: %w", *noMatchLogLevel, err)
+	}
+	if err := noMatchLevel.UnmarshalText([]byte(*noMatchLogLevel)); err != nil {
+		return Args{}, xerrors.Errorf("invalid -no-match-log-level arg %q: %w", *noMatchLogLevel, err)
+	}
+	var cipherSuites []uint16
+	if *tlsCipherSuites != "" {
+		var err error
+		if cipherSuites, err = triggers.ParseTLSCipherSuites(*tlsCipherSuites); err != nil {
+			return Args{}, xerrors.Errorf("invalid -tls-cipher-suites arg: %w", err)
+		}
+	}
 
 	return Args{
 		ElName:                            *nameFlag,
@@ -141,6 +572,7 @@ func GetArgs() (Args, error) {
 		IsMultiNS:                         *isMultiNSFlag,
 		PayloadValidation:                 *payloadValidation,
 		ELReadTimeOut:                     time.Duration(*elReadTimeOut),
+		ELReadHeaderTimeOut:               time.Duration(*elReadHeaderTimeOut),
 		ELWriteTimeOut:                    time.Duration(*elWriteTimeOut),
 		ELIdleTimeOut:                     time.Duration(*elIdleTimeOut),
 		ELTimeOutHandler:                  time.Duration(*elTimeOutHandler),
@@ -149,12 +581,92 @@ func GetArgs() (Args, error) {
 		ElHTTPClientTLSHandshakeTimeout:   time.Duration(*elHTTPClientTLSHandshakeTimeout),
 		ElHTTPClientResponseHeaderTimeout: time.Duration(*elHTTPClientResponseHeaderTimeout),
 		ElHTTPClientExpectContinueTimeout: time.Duration(*elHTTPClientExpectContinueTimeout),
+		ResourceClientQPS:                 float32(*resourceClientQPS),
+		ResourceClientBurst:               *resourceClientBurst,
+		ResourceClientMaxIdleConns:        *resourceClientMaxIdleConns,
 		Cert:                              *tlsCertFlag,
 		Key:                               *tlsKeyFlag,
+		ClientCA:                          *tlsClientCAFlag,
+		TLSMinVersion:                     minVersion,
+		TLSCipherSuites:                   cipherSuites,
 		CloudEventURI:                     *cloudEventURI,
+		BackpressureMaxInFlight:           *backpressureMaxInFlight,
+		BackpressureRetryAfter:            *backpressureRetryAfter,
+		InterceptorTimeout:                *interceptorTimeout,
+		AllowedMethods:                    splitList(*allowedMethods),
+		AllowedContentTypes:               splitList(*allowedContentTypes),
+		CreationLimit:                     *creationLimit,
+		CreationLimitWindow:               *creationLimitWindow,
+		CreationLimitPerTrigger:           *creationLimitPerTrigger,
+		MaxCreatesPerEvent:                *maxCreatesPerEvent,
+		CreateTimeout:                     *createTimeout,
+		QuotaRetryWindow:                  *quotaRetryWindow,
+		QuotaRetryMaxQueued:               *quotaRetryMaxQueued,
+		QuotaRetryPersist:                 *quotaRetryPersist,
+		H2C:                               *h2cFlag,
+		ActivityInterval:                  *activityInterval,
+		ProvenanceLabels:                  labels,
+		AccessLog:                         *accessLog,
+		AccessLogFields:                   fields,
+		AccessLogSampleRate:               *accessLogSampleRate,
+		BasePath:                          strings.TrimSuffix(*basePath, "/"),
+		TrustedProxies:                    proxies,
+		TrustedProxyHeader:                *trustedProxyHeader,
+		ProxyProtocolUpstreams:            upstreams,
+		EventIDHeaders:                    splitList(*eventIDHeaders),
+		EventIDExpression:                 strings.TrimSpace(*eventIDExpression),
+		FieldValidation:                   *fieldValidation,
+		LabelSanitization:                 *labelSanitization,
+		Synchronous:                       *synchronous,
+		MultiStatus:                       *multiStatus,
+		RollbackOnFailure:                 *rollbackOnFailure,
+		CreateBestEffort:                  *createFailurePolicy == triggers.CreateFailureBestEffort,
+		AuditSink:                         *auditSink,
+		AuditOnly:                         *auditMode == triggers.AuditModeInstead,
+		AuditBestEffort:                   *auditFailurePolicy == triggers.AuditFailureBestEffort,
+		PayloadParsers:                    parsers,
+		SelfTestSecret:                    *selfTestSecret,
+		ConfigEndpointSecret:              *configSecret,
+		BatchSize:                         *batchSize,
+		ResourcePolicies:                  splitList(*resourcePolicies),
+		MutationHookURL:                   *mutationHookURL,
+		MutationHookTimeout:               *mutationHookTimeout,
+		MutationHookFailOpen:              *mutationHookFailurePolicy == triggers.MutationHookFailOpen,
+		CallbackURL:                       *callbackURL,
+		CallbackPayload:                   *callbackPayload,
+		CallbackSecret:                    *callbackSecret,
+		CallbackTimeout:                   *callbackTimeout,
+		CallbackRetries:                   *callbackRetries,
+		ResourceEventsCreated:             resourceEventsCreated,
+		ResourceEventsFailed:              resourceEventsFailed,
+		ResourceEventsURI:                 *resourceEventsURI,
+		ResourceEventsSource:              *resourceEventsSource,
+		DebugTraceSecret:                  *debugTraceSecret,
+		DebugTraceSampleRate:              *debugTraceSampleRate,
+		InterceptorMetricsNames:           *interceptorMetricsNames,
+		ResourceMetricsNamespaces:         *resourceMetricsNamespaces,
+		LogFormat:                         *logFormat,
+		NoMatchPolicy:                     *noMatchPolicy,
+		NoMatchLogLevel:                   noMatchLevel,
+		DiscoveryGracePeriod:              *discoveryGracePeriod,
+		DiscoveryBackoff:                  *discoveryBackoff,
+		EventWorkers:                      *eventWorkers,
+		EventQueueSize:                    *eventQueueSize,
+		EventOverflow:                     *eventOverflow,
 	}, nil
 }
 
+// splitList splits a comma separated flag value, ignoring empty entries.
+func splitList(value string) []string {
+	var out []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 // ConfigureClients returns the kubernetes and triggers clientsets
 func ConfigureClients(ctx context.Context, clusterConfig *rest.Config) (Clients, error) {
 	kubeClient, err := kubeclientset.NewForConfig(clusterConfig)
@@ -174,3 +686,42 @@ func ConfigureClients(ctx context.Context, clusterConfig *rest.Config) (Clients,
 		CEClient:        ceClient,
 	}, nil
 }
+
+// ResourceClientConfig returns a copy of clusterConfig for the dynamic and discovery clients creating
+// resources, with the QPS, burst and connection pool of args. The copies share a single transport,
+// so that the clients impersonating the service accounts of triggers reuse its connections.
+func ResourceClientConfig(clusterConfig *rest.Config, args Args) (*rest.Config, error) {
+	config := rest.CopyConfig(clusterConfig)
+	config.QPS = args.ResourceClientQPS
+	config.Burst = args.ResourceClientBurst
+	if args.ResourceClientMaxIdleConns == 0 || config.Transport != nil {
+		return config, nil
+	}
+	// client-go doesn't expose the size of the connection pool of the transports it creates, so the
+	// transport is created here, from the TLS options of the config, which can't be set with it.
+	tlsConfig, err := rest.TLSConfigFor(config)
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to get the TLS config of the resource clients: %s", err)
+	}
+	dial := config.Dial
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+	proxy := config.Proxy
+	if proxy == nil {
+		proxy = http.ProxyFromEnvironment
+	}
+	config.Transport = utilnet.SetTransportDefaults(&http.Transport{
+		Proxy:               proxy,
+		TLSHandshakeTimeout: 10 * time.Second,
+		TLSClientConfig:     tlsConfig,
+		MaxIdleConns:        args.ResourceClientMaxIdleConns,
+		MaxIdleConnsPerHost: args.ResourceClientMaxIdleConns,
+		DialContext:         dial,
+		DisableCompression:  config.DisableCompression,
+	})
+	config.TLSClientConfig = rest.TLSClientConfig{}
+	config.Dial = nil
+	config.Proxy = nil
+	return config, nil
+}
@@ -18,8 +18,13 @@ package sink
 
 import (
 	"flag"
+	"net/http"
 	"strconv"
 	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"k8s.io/client-go/rest"
 )
 
 func Test_GetArgs(t *testing.T) {
@@ -55,6 +60,111 @@ func Test_GetArgs(t *testing.T) {
 	if sinkArgs.PayloadValidation != true {
 		t.Errorf("Error EL PayloadValidation want true, got %t", sinkArgs.PayloadValidation)
 	}
+	if sinkArgs.ResourceClientQPS != 50 || sinkArgs.ResourceClientBurst != 100 || sinkArgs.ResourceClientMaxIdleConns != 50 {
+		t.Errorf("Error resource client settings want 50 QPS, 100 burst and 50 idle connections, got %v, %d and %d",
+			sinkArgs.ResourceClientQPS, sinkArgs.ResourceClientBurst, sinkArgs.ResourceClientMaxIdleConns)
+	}
+	if sinkArgs.AuditSink != "" || sinkArgs.AuditOnly || sinkArgs.AuditBestEffort {
+		t.Errorf("Error audit settings want no sink, alongside and fatal, got %q, %t and %t", sinkArgs.AuditSink, sinkArgs.AuditOnly, sinkArgs.AuditBestEffort)
+	}
+	if sinkArgs.PayloadParsers != nil {
+		t.Errorf("Error payload parsers want none, got %v", sinkArgs.PayloadParsers)
+	}
+	if sinkArgs.SelfTestSecret != "" {
+		t.Errorf("Error self-test secret want none, got %q", sinkArgs.SelfTestSecret)
+	}
+	if sinkArgs.MaxCreatesPerEvent != DefaultMaxCreatesPerEvent {
+		t.Errorf("Error max creates per event want %d, got %d", DefaultMaxCreatesPerEvent, sinkArgs.MaxCreatesPerEvent)
+	}
+	if sinkArgs.QuotaRetryWindow != 0 || sinkArgs.QuotaRetryMaxQueued != 100 || sinkArgs.QuotaRetryPersist {
+		t.Errorf("Error quota retry settings want no window, 100 queued creates and no persistence, got %s, %d and %t", sinkArgs.QuotaRetryWindow, sinkArgs.QuotaRetryMaxQueued, sinkArgs.QuotaRetryPersist)
+	}
+	if sinkArgs.BatchSize != 0 {
+		t.Errorf("Error batch size want 0, got %d", sinkArgs.BatchSize)
+	}
+	if sinkArgs.ProxyProtocolUpstreams != nil {
+		t.Errorf("Error proxy protocol upstreams want none, got %v", sinkArgs.ProxyProtocolUpstreams)
+	}
+	if sinkArgs.TrustedProxies != nil {
+		t.Errorf("Error trusted proxies want none, got %v", sinkArgs.TrustedProxies)
+	}
+	if sinkArgs.CreateBestEffort {
+		t.Error("Error create failure policy want fail-fast, got best-effort")
+	}
+	if sinkArgs.LabelSanitization != "hash" {
+		t.Errorf("Error label sanitization want hash, got %q", sinkArgs.LabelSanitization)
+	}
+	if sinkArgs.ResourcePolicies != nil {
+		t.Errorf("Error resource policies want none, got %v", sinkArgs.ResourcePolicies)
+	}
+	if sinkArgs.MutationHookURL != "" || sinkArgs.MutationHookTimeout != 10*time.Second || sinkArgs.MutationHookFailOpen {
+		t.Errorf("Error mutation hook settings want no URL, a 10s timeout and fail-closed, got %q, %s and %t", sinkArgs.MutationHookURL, sinkArgs.MutationHookTimeout, sinkArgs.MutationHookFailOpen)
+	}
+	if sinkArgs.CallbackURL != "" || sinkArgs.CallbackTimeout != 10*time.Second || sinkArgs.CallbackRetries != 3 {
+		t.Errorf("Error callback settings want no URL, a 10s timeout and 3 retries, got %q, %s and %d", sinkArgs.CallbackURL, sinkArgs.CallbackTimeout, sinkArgs.CallbackRetries)
+	}
+	if sinkArgs.ResourceEventsCreated || sinkArgs.ResourceEventsFailed || sinkArgs.ResourceEventsURI != "" {
+		t.Errorf("Error resource events settings want no events and no URI, got %t, %t and %q", sinkArgs.ResourceEventsCreated, sinkArgs.ResourceEventsFailed, sinkArgs.ResourceEventsURI)
+	}
+	if sinkArgs.DebugTraceSecret != "" || sinkArgs.DebugTraceSampleRate != 0 {
+		t.Errorf("Error debug trace settings want no secret and a 0 sample rate, got %q and %v", sinkArgs.DebugTraceSecret, sinkArgs.DebugTraceSampleRate)
+	}
+	if sinkArgs.InterceptorMetricsNames != 20 {
+		t.Errorf("Error interceptor metrics names want 20, got %d", sinkArgs.InterceptorMetricsNames)
+	}
+	if sinkArgs.ResourceMetricsNamespaces != -1 {
+		t.Errorf("Error resource metrics namespaces want -1, got %d", sinkArgs.ResourceMetricsNamespaces)
+	}
+	if sinkArgs.LogFormat != "" {
+		t.Errorf("Error log format want the logging config format, got %q", sinkArgs.LogFormat)
+	}
+	if sinkArgs.NoMatchPolicy != "ignore" || sinkArgs.NoMatchLogLevel != zapcore.InfoLevel {
+		t.Errorf("Error no match settings want ignore at info level, got %q at %s level", sinkArgs.NoMatchPolicy, sinkArgs.NoMatchLogLevel)
+	}
+	if sinkArgs.DiscoveryGracePeriod != 2*time.Minute || sinkArgs.DiscoveryBackoff != time.Second {
+		t.Errorf("Error discovery retries want a 2m grace period and 1s backoff, got %s and %s", sinkArgs.DiscoveryGracePeriod, sinkArgs.DiscoveryBackoff)
+	}
+	if sinkArgs.TLSMinVersion != 0 || sinkArgs.TLSCipherSuites != nil {
+		t.Errorf("Error TLS settings want the defaults, got version %x and cipher suites %v", sinkArgs.TLSMinVersion, sinkArgs.TLSCipherSuites)
+	}
+}
+
+func TestResourceClientConfig(t *testing.T) {
+	clusterConfig := &rest.Config{
+		Host:            "https://kubernetes.default.svc",
+		BearerToken:     "token",
+		TLSClientConfig: rest.TLSClientConfig{Insecure: true},
+	}
+
+	config, err := ResourceClientConfig(clusterConfig, Args{ResourceClientQPS: 50, ResourceClientBurst: 100, ResourceClientMaxIdleConns: 20})
+	if err != nil {
+		t.Fatalf("ResourceClientConfig() returned unexpected error: %s", err)
+	}
+	if config.QPS != 50 || config.Burst != 100 {
+		t.Errorf("ResourceClientConfig() got QPS %v and burst %d, want 50 and 100", config.QPS, config.Burst)
+	}
+	transport, ok := config.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("ResourceClientConfig() got transport %T, want an *http.Transport", config.Transport)
+	}
+	if transport.MaxIdleConnsPerHost != 20 || !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Errorf("ResourceClientConfig() got %d idle connections per host and InsecureSkipVerify %t, want 20 and true",
+			transport.MaxIdleConnsPerHost, transport.TLSClientConfig.InsecureSkipVerify)
+	}
+	if _, err := rest.HTTPClientFor(config); err != nil {
+		t.Errorf("ResourceClientConfig() got a config that clients can't be created from: %s", err)
+	}
+	if clusterConfig.Transport != nil || clusterConfig.QPS != 0 {
+		t.Error("ResourceClientConfig() modified the cluster config")
+	}
+
+	config, err = ResourceClientConfig(clusterConfig, Args{})
+	if err != nil {
+		t.Fatalf("ResourceClientConfig() returned unexpected error: %s", err)
+	}
+	if config.Transport != nil || !config.Insecure {
+		t.Error("ResourceClientConfig() without idle connections got a custom transport")
+	}
 }
 
 func Test_GetArgs_error(t *testing.T) {
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"net/http"
+	"time"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"go.uber.org/zap"
+)
+
+// webhookInterceptorName is the name of old style webhook interceptors in interceptor results.
+const webhookInterceptorName = "webhook"
+
+// The types of interceptors in interceptor results and metrics.
+const (
+	// coreInterceptorType is a ClusterInterceptor served by the core interceptors of Triggers.
+	coreInterceptorType = "core"
+	// clusterInterceptorType is any other ClusterInterceptor.
+	clusterInterceptorType = "cluster"
+	// namespacedInterceptorType is an Interceptor in the namespace of the EventListener.
+	namespacedInterceptorType = "namespaced"
+	// webhookInterceptorType is an old style webhook interceptor.
+	webhookInterceptorType = "webhook"
+)
+
+// InterceptorResult is the result of the execution of a single interceptor of a chain.
+type InterceptorResult struct {
+	// Name identifies the interceptor in the chain: its name if set, otherwise the name of the
+	// referenced interceptor, or "webhook" for old style webhook interceptors.
+	Name string
+	// Continue is true if the interceptor accepted the event, or was skipped.
+	Continue bool
+	// Skipped is true if the when expression of the interceptor evaluated to false, so that it wasn't executed.
+	Skipped bool
+	// Type is the type of the interceptor: core, cluster, namespaced or webhook. ClusterInterceptors that
+	// could not be resolved or were skipped are of the cluster type.
+	Type string
+	// Duration is the time taken to execute the interceptor, including the resolution of its address.
+	Duration time.Duration
+	// RequestDuration is the part of Duration spent on the HTTP request to the interceptor.
+	RequestDuration time.Duration
+	// Extensions are the extensions added by the interceptor.
+	Extensions map[string]interface{}
+	// Status is the status returned by the interceptor, with the reason the event was rejected if it was.
+	Status triggersv1.Status
+	// Err is the error that prevented the interceptor from processing the event, if any.
+	Err error
+	// Request is the request sent to the interceptor. It is only recorded for the events with a debug trace.
+	Request *triggersv1.InterceptorRequest
+}
+
+// InterceptorChainResult is the result of the execution of a chain of interceptors.
+type InterceptorChainResult struct {
+	// Body and Header are the event as modified by the interceptors. They are nil if the event was rejected.
+	Body   []byte
+	Header http.Header
+	// Response is the response of the interceptor that rejected the event, or a response accepting it with
+	// the extensions of all the interceptors. It is nil if the chain is empty.
+	Response *triggersv1.InterceptorResponse
+	// Interceptors are the results of the interceptors executed or skipped, in order. The interceptors
+	// following one that rejected the event or failed are not executed.
+	Interceptors []InterceptorResult
+}
+
+func interceptorName(i *triggersv1.TriggerInterceptor) string {
+	switch {
+	case i.Name != nil && *i.Name != "":
+		return *i.Name
+	case i.Webhook != nil:
+		return webhookInterceptorName
+	}
+	return i.GetName()
+}
+
+// interceptorType returns the type of i, without resolving the ClusterInterceptors served by the core interceptors.
+func interceptorType(i *triggersv1.TriggerInterceptor) string {
+	if i.Webhook != nil {
+		return webhookInterceptorType
+	}
+	switch i.Ref.Kind {
+	case triggersv1.ClusterInterceptorKind:
+		return clusterInterceptorType
+	case triggersv1.NamespacedInterceptorKind:
+		return namespacedInterceptorType
+	}
+	return ""
+}
+
+// logInterceptorResults logs the result of each interceptor executed at the debug level.
+func logInterceptorResults(log *zap.SugaredLogger, result *InterceptorChainResult) {
+	if result == nil {
+		return
+	}
+	for i, res := range result.Interceptors {
+		fields := []interface{}{zap.Int("index", i), zap.String("interceptor", res.Name), zap.Duration("duration", res.Duration), zap.Duration("requestDuration", res.RequestDuration)}
+		switch {
+		case res.Err != nil:
+			log.Debugw("interceptor failed", append(fields, zap.Error(res.Err))...)
+		case !res.Continue:
+			log.Debugw("interceptor rejected the event", append(fields, zap.String("reason", res.Status.Message))...)
+		case res.Skipped:
+			log.Debugw("interceptor skipped by its when expression", fields...)
+		default:
+			log.Debugw("interceptor accepted the event", fields...)
+		}
+	}
+}
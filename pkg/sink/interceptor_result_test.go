@@ -0,0 +1,201 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	triggersv1alpha1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	triggersv1beta1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/test"
+	"google.golang.org/grpc/codes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestExecuteInterceptorChain_Results(t *testing.T) {
+	resources := test.Resources{
+		ClusterInterceptors: []*triggersv1alpha1.ClusterInterceptor{cel},
+	}
+	s, _ := getSinkAssets(t, resources, "", &echoInterceptor{})
+
+	overlay := &triggersv1beta1.TriggerInterceptor{
+		Name: pointer.String("truncate"),
+		Ref:  triggersv1beta1.InterceptorRef{Name: "cel", Kind: triggersv1beta1.ClusterInterceptorKind},
+		Params: []triggersv1beta1.InterceptorParams{{
+			Name: "overlays",
+			Value: test.ToV1JSON(t, []triggersv1beta1.CELOverlay{{
+				Key:        "truncated_sha",
+				Expression: "body.sha.truncate(5)",
+			}}),
+		}},
+	}
+	webhook := &triggersv1beta1.TriggerInterceptor{
+		Webhook: &triggersv1beta1.WebhookInterceptor{
+			ObjectRef: &corev1.ObjectReference{
+				APIVersion: "v1",
+				Kind:       "Service",
+				Name:       "foo",
+			},
+		},
+	}
+	filter := func(expr string) *triggersv1beta1.TriggerInterceptor {
+		return &triggersv1beta1.TriggerInterceptor{
+			Ref: triggersv1beta1.InterceptorRef{Name: "cel", Kind: triggersv1beta1.ClusterInterceptorKind},
+			Params: []triggersv1beta1.InterceptorParams{{
+				Name:  "filter",
+				Value: test.ToV1JSON(t, expr),
+			}},
+		}
+	}
+	missing := &triggersv1beta1.TriggerInterceptor{
+		Ref: triggersv1beta1.InterceptorRef{Name: "missing", Kind: triggersv1beta1.ClusterInterceptorKind},
+	}
+	guarded := func(when string, i *triggersv1beta1.TriggerInterceptor) *triggersv1beta1.TriggerInterceptor {
+		i = i.DeepCopy()
+		i.When = when
+		return i
+	}
+
+	for _, tc := range []struct {
+		name         string
+		interceptors []*triggersv1beta1.TriggerInterceptor
+		want         []InterceptorResult
+		wantBody     string
+		wantContinue bool
+		wantErr      bool
+	}{{
+		name:         "accepted",
+		interceptors: []*triggersv1beta1.TriggerInterceptor{overlay, webhook, filter("extensions.truncated_sha == 'abcde'")},
+		want: []InterceptorResult{{
+			Name:       "truncate",
+			Type:       coreInterceptorType,
+			Continue:   true,
+			Extensions: map[string]interface{}{"truncated_sha": "abcde"},
+		}, {
+			Name:     "webhook",
+			Type:     webhookInterceptorType,
+			Continue: true,
+		}, {
+			Name:     "cel",
+			Type:     coreInterceptorType,
+			Continue: true,
+		}},
+		wantBody:     `{"extensions":{"truncated_sha":"abcde"},"sha":"abcdefghi"}`,
+		wantContinue: true,
+	}, {
+		name:         "rejected",
+		interceptors: []*triggersv1beta1.TriggerInterceptor{overlay, filter("body.sha == 'other'"), webhook},
+		want: []InterceptorResult{{
+			Name:       "truncate",
+			Type:       coreInterceptorType,
+			Continue:   true,
+			Extensions: map[string]interface{}{"truncated_sha": "abcde"},
+		}, {
+			Name: "cel",
+			Type: coreInterceptorType,
+			Status: triggersv1beta1.Status{
+				Code:    codes.FailedPrecondition,
+				Message: "expression body.sha == 'other' did not return true",
+			},
+		}},
+	}, {
+		name:         "failed",
+		interceptors: []*triggersv1beta1.TriggerInterceptor{overlay, missing, webhook},
+		want: []InterceptorResult{{
+			Name:       "truncate",
+			Type:       coreInterceptorType,
+			Continue:   true,
+			Extensions: map[string]interface{}{"truncated_sha": "abcde"},
+		}, {
+			Name: "missing",
+			Type: clusterInterceptorType,
+		}},
+		wantErr: true,
+	}, {
+		name: "skipped",
+		interceptors: []*triggersv1beta1.TriggerInterceptor{
+			overlay,
+			guarded("extensions.truncated_sha == 'other'", filter("body.sha == 'other'")),
+			guarded("extensions.truncated_sha == 'abcde'", webhook),
+		},
+		want: []InterceptorResult{{
+			Name:       "truncate",
+			Type:       coreInterceptorType,
+			Continue:   true,
+			Extensions: map[string]interface{}{"truncated_sha": "abcde"},
+		}, {
+			Name:     "cel",
+			Type:     clusterInterceptorType,
+			Continue: true,
+			Skipped:  true,
+		}, {
+			Name:     "webhook",
+			Type:     webhookInterceptorType,
+			Continue: true,
+		}},
+		wantBody:     `{"extensions":{"truncated_sha":"abcde"},"sha":"abcdefghi"}`,
+		wantContinue: true,
+	}, {
+		name:         "when expression that isn't a bool",
+		interceptors: []*triggersv1beta1.TriggerInterceptor{guarded("body.sha", webhook), overlay},
+		want: []InterceptorResult{{
+			Name: "webhook",
+			Type: webhookInterceptorType,
+		}},
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/", nil)
+			if err != nil {
+				t.Fatalf("http.NewRequest: %v", err)
+			}
+			result, err := s.ExecuteInterceptorChain(tc.interceptors, req, []byte(`{"sha": "abcdefghi"}`), s.Logger, eventID, "namespaces/default/triggers/test", "default", map[string]interface{}{})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ExecuteInterceptorChain() error = %v, wantErr %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, result.Interceptors, cmpopts.IgnoreFields(InterceptorResult{}, "Duration", "RequestDuration", "Err")); diff != "" {
+				t.Errorf("ExecuteInterceptorChain() interceptor results (-want +got): %s", diff)
+			}
+			for _, res := range result.Interceptors {
+				if res.Duration <= 0 {
+					t.Errorf("interceptor %s has no duration", res.Name)
+				}
+				if res.Err == nil && !res.Skipped && (res.RequestDuration <= 0 || res.RequestDuration > res.Duration) {
+					t.Errorf("interceptor %s has request duration %s, want a positive part of its duration %s", res.Name, res.RequestDuration, res.Duration)
+				}
+			}
+			if last := result.Interceptors[len(result.Interceptors)-1]; (last.Err != nil) != tc.wantErr {
+				t.Errorf("last interceptor error = %v, wantErr %t", last.Err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if result.Response.Continue != tc.wantContinue {
+				t.Errorf("ExecuteInterceptorChain() continue = %t, want %t", result.Response.Continue, tc.wantContinue)
+			}
+			// The webhook interceptor response ends with a newline.
+			if got := strings.TrimSpace(string(result.Body)); got != tc.wantBody {
+				t.Errorf("ExecuteInterceptorChain() body = %s, want %s", got, tc.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"io"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// The outcomes of the triggers and of the resources they create, in the outcome field of the logs.
+const (
+	firedOutcome    = "fired"
+	rejectedOutcome = "rejected"
+	failedOutcome   = "failed"
+	createdOutcome  = "created"
+)
+
+// logKeys are the keys of the fields correlating the logs of the sink with the events.
+type logKeys struct {
+	eventListener string
+	namespace     string
+	eventID       string
+	triggerGroup  string
+	trigger       string
+	kind          string
+	outcome       string
+}
+
+// defaultLogKeys are the keys of the logs in the format of the logging config, unchanged so that the
+// existing queries of the logs keep working.
+var defaultLogKeys = logKeys{
+	eventListener: "eventlistener",
+	namespace:     "namespace",
+	eventID:       triggers.EventIDLabelKey,
+	triggerGroup:  triggers.TriggerGroupLabelKey,
+	trigger:       triggers.TriggerLabelKey,
+	kind:          "kind",
+	outcome:       "outcome",
+}
+
+// jsonLogKeys are the stable keys of the JSON logs.
+var jsonLogKeys = logKeys{
+	eventListener: "eventListener",
+	namespace:     "namespace",
+	eventID:       "eventID",
+	triggerGroup:  "triggerGroup",
+	trigger:       "trigger",
+	kind:          "kind",
+	outcome:       "outcome",
+}
+
+func (r Sink) logKeys() logKeys {
+	if r.LogFormat == triggers.JSONLogFormat {
+		return jsonLogKeys
+	}
+	return defaultLogKeys
+}
+
+// NewJSONLogger returns a logger writing the entries to w as JSON lines with stable keys, whatever the
+// encoding of the logging config. It logs at the levels enabled by logger, so that the level can still
+// be changed in the logging config.
+func NewJSONLogger(logger *zap.SugaredLogger, w io.Writer) *zap.SugaredLogger {
+	encoder := zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		TimeKey:        "timestamp",
+		LevelKey:       "level",
+		NameKey:        "logger",
+		CallerKey:      "caller",
+		MessageKey:     "message",
+		StacktraceKey:  "stacktrace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	})
+	enabled := zap.LevelEnablerFunc(logger.Desugar().Core().Enabled)
+	core := zapcore.NewCore(encoder, zapcore.Lock(zapcore.AddSync(w)), enabled)
+	return zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel)).Sugar()
+}
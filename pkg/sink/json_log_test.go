@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestNewJSONLogger(t *testing.T) {
+	var out bytes.Buffer
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	logger := NewJSONLogger(zaptest.NewLogger(t, zaptest.Level(level)).Sugar(), &out)
+
+	logger.Debug("not logged")
+	logger.With(jsonLogKeys.eventID, "abc", jsonLogKeys.outcome, firedOutcome).Info("trigger fired")
+	level.SetLevel(zapcore.DebugLevel)
+	logger.Debug("logged once the level changes")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("NewJSONLogger() wrote %d lines, want 2: %s", len(lines), out.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("NewJSONLogger() wrote invalid JSON %s: %v", lines[0], err)
+	}
+	for _, key := range []string{"timestamp", "caller"} {
+		if _, ok := entry[key]; !ok {
+			t.Errorf("NewJSONLogger() entry has no %s key: %s", key, lines[0])
+		}
+		delete(entry, key)
+	}
+	want := map[string]interface{}{
+		"level":   "info",
+		"message": "trigger fired",
+		"eventID": "abc",
+		"outcome": "fired",
+	}
+	if diff := cmp.Diff(want, entry); diff != "" {
+		t.Errorf("NewJSONLogger() entry -want +got: %s", diff)
+	}
+	if !strings.Contains(lines[1], `"message":"logged once the level changes"`) {
+		t.Errorf("NewJSONLogger() did not follow the level of the logger: %s", lines[1])
+	}
+}
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"sync"
 	"time"
 
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"knative.dev/pkg/metrics"
 )
 
@@ -23,12 +25,76 @@ var (
 	eventCount     = stats.Float64("event_count",
 		"number of events received by sink",
 		stats.UnitDimensionless)
-	triggeredResources = stats.Int64("triggered_resources", "Count of the number of triggered eventlistener resources", stats.UnitDimensionless)
+	triggeredResources   = stats.Int64("triggered_resources", "Count of the number of triggered eventlistener resources", stats.UnitDimensionless)
+	backpressureRejected = stats.Int64("backpressure_rejected_count",
+		"number of events rejected by sink because it was overloaded",
+		stats.UnitDimensionless)
+	eventPoolBusy = stats.Int64("event_workers_busy",
+		"number of event workers processing events",
+		stats.UnitDimensionless)
+	eventPoolQueued = stats.Int64("event_workers_queued",
+		"number of events waiting for an event worker",
+		stats.UnitDimensionless)
+	eventPoolRejected = stats.Int64("event_workers_rejected_count",
+		"number of events rejected by sink because all the event workers were busy",
+		stats.UnitDimensionless)
+	interceptorTimeouts = stats.Int64("interceptor_timeout_count",
+		"number of interceptor chains that did not complete within the interceptor timeout",
+		stats.UnitDimensionless)
+	creationLimited = stats.Int64("creation_limited_count",
+		"number of triggers that skipped resource creation because the creation limit was exceeded",
+		stats.UnitDimensionless)
+	eventCreateLimited = stats.Int64("event_create_limited_count",
+		"number of triggers that skipped resource creation because the event exceeded the number of resources it can create",
+		stats.UnitDimensionless)
+	createTimeouts = stats.Int64("create_timeout_count",
+		"number of resource creations abandoned because they did not complete within the create timeout",
+		stats.UnitDimensionless)
+	quotaRetryQueued = stats.Int64("quota_retry_queued_count",
+		"number of resource creations queued to be retried because they exceeded a resource quota",
+		stats.UnitDimensionless)
+	quotaRetryDropped = stats.Int64("quota_retry_dropped_count",
+		"number of resource creations that exceeded a resource quota and were dropped without being created",
+		stats.UnitDimensionless)
+	createdResourceCount = stats.Int64("created_resources_count",
+		"number of resources created, by group, version, kind and namespace",
+		stats.UnitDimensionless)
+	kindNotAllowed = stats.Int64("resource_kind_not_allowed_count",
+		"number of resources not created because the resourceKinds of the EventListener don't allow their kind",
+		stats.UnitDimensionless)
+	resourceCreates = stats.Int64("resource_create_count",
+		"number of creations of the resources of the templates of triggers, by kind and outcome",
+		stats.UnitDimensionless)
+	callbackFailed = stats.Int64("callback_failed_count",
+		"number of callback notifications of created resources that could not be sent",
+		stats.UnitDimensionless)
+	interceptorCount = stats.Int64("interceptor_count",
+		"number of interceptor executions, by outcome",
+		stats.UnitDimensionless)
+	interceptorDuration = stats.Float64("interceptor_duration_seconds",
+		"The duration of interceptor executions, including the resolution of the interceptor address",
+		stats.UnitDimensionless)
+	interceptorRequestDuration = stats.Float64("interceptor_request_duration_seconds",
+		"The duration of the HTTP requests to interceptors",
+		stats.UnitDimensionless)
+	noTriggersMatched = stats.Int64("no_triggers_matched_count",
+		"number of events that matched no triggers",
+		stats.UnitDimensionless)
+	interceptorDistribution = view.Distribution(metrics.BucketsNBy10(0.001, 5)...)
+	eventPoolGauge          = view.LastValue()
 )
 
 const (
 	failTag    = "failed"
 	successTag = "succeeded"
+
+	// The statuses of interceptor executions.
+	acceptedTag = "accepted"
+	rejectedTag = "rejected"
+	skippedTag  = "skipped"
+
+	// otherMetricName replaces the names beyond the limit of MetricNames.
+	otherMetricName = "other"
 )
 
 // NewRecorder creates a new metrics recorder instance
@@ -51,6 +117,41 @@ func NewRecorder() (*Recorder, error) {
 		return nil, err
 	}
 	r.kind = kind
+	reason, err := tag.NewKey("reason")
+	if err != nil {
+		return nil, err
+	}
+	r.reason = reason
+	trigger, err := tag.NewKey("trigger")
+	if err != nil {
+		return nil, err
+	}
+	r.trigger = trigger
+	interceptor, err := tag.NewKey("interceptor")
+	if err != nil {
+		return nil, err
+	}
+	r.interceptor = interceptor
+	interceptorType, err := tag.NewKey("type")
+	if err != nil {
+		return nil, err
+	}
+	r.interceptorType = interceptorType
+	group, err := tag.NewKey("group")
+	if err != nil {
+		return nil, err
+	}
+	r.group = group
+	version, err := tag.NewKey("version")
+	if err != nil {
+		return nil, err
+	}
+	r.version = version
+	namespace, err := tag.NewKey("namespace")
+	if err != nil {
+		return nil, err
+	}
+	r.namespace = namespace
 
 	err = view.Register(
 		&view.View{
@@ -70,6 +171,110 @@ func NewRecorder() (*Recorder, error) {
 			Aggregation: view.Count(),
 			TagKeys:     []tag.Key{r.status},
 		},
+		&view.View{
+			Description: backpressureRejected.Description(),
+			Measure:     backpressureRejected,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{r.reason},
+		},
+		&view.View{
+			Description: eventPoolBusy.Description(),
+			Measure:     eventPoolBusy,
+			Aggregation: eventPoolGauge,
+		},
+		&view.View{
+			Description: eventPoolQueued.Description(),
+			Measure:     eventPoolQueued,
+			Aggregation: eventPoolGauge,
+		},
+		&view.View{
+			Description: eventPoolRejected.Description(),
+			Measure:     eventPoolRejected,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{r.reason},
+		},
+		&view.View{
+			Description: interceptorTimeouts.Description(),
+			Measure:     interceptorTimeouts,
+			Aggregation: view.Count(),
+		},
+		&view.View{
+			Description: creationLimited.Description(),
+			Measure:     creationLimited,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{r.trigger},
+		},
+		&view.View{
+			Description: eventCreateLimited.Description(),
+			Measure:     eventCreateLimited,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{r.trigger},
+		},
+		&view.View{
+			Description: createTimeouts.Description(),
+			Measure:     createTimeouts,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{r.trigger},
+		},
+		&view.View{
+			Description: quotaRetryQueued.Description(),
+			Measure:     quotaRetryQueued,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{r.trigger},
+		},
+		&view.View{
+			Description: quotaRetryDropped.Description(),
+			Measure:     quotaRetryDropped,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{r.trigger, r.reason},
+		},
+		&view.View{
+			Description: createdResourceCount.Description(),
+			Measure:     createdResourceCount,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{r.group, r.version, r.kind, r.namespace},
+		},
+		&view.View{
+			Description: kindNotAllowed.Description(),
+			Measure:     kindNotAllowed,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{r.trigger, r.group, r.version, r.kind},
+		},
+		&view.View{
+			Description: resourceCreates.Description(),
+			Measure:     resourceCreates,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{r.trigger, r.kind, r.status},
+		},
+		&view.View{
+			Description: callbackFailed.Description(),
+			Measure:     callbackFailed,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{r.trigger, r.reason},
+		},
+		&view.View{
+			Description: noTriggersMatched.Description(),
+			Measure:     noTriggersMatched,
+			Aggregation: view.Count(),
+		},
+		&view.View{
+			Description: interceptorCount.Description(),
+			Measure:     interceptorCount,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{r.interceptor, r.interceptorType, r.status},
+		},
+		&view.View{
+			Description: interceptorDuration.Description(),
+			Measure:     interceptorDuration,
+			Aggregation: interceptorDistribution,
+			TagKeys:     []tag.Key{r.interceptor, r.interceptorType},
+		},
+		&view.View{
+			Description: interceptorRequestDuration.Description(),
+			Measure:     interceptorRequestDuration,
+			Aggregation: interceptorDistribution,
+			TagKeys:     []tag.Key{r.interceptor, r.interceptorType},
+		},
 	)
 	if err != nil {
 		log.Fatalf("unable to register eventlistener metrics: %s", err)
@@ -126,6 +331,276 @@ func (s *Sink) recordCountMetrics(status string) {
 	metrics.Record(ctx, eventCount.M(1))
 }
 
+func (s *Sink) recordBackpressureMetrics(reason string) {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(s.Recorder.reason, reason),
+	)
+
+	if err != nil {
+		s.Logger.Warnf("failed to create tag for metric backpressure_rejected_count: %w", err)
+		return
+	}
+
+	metrics.Record(ctx, backpressureRejected.M(1))
+}
+
+// recordEventPoolMetrics records the saturation of the event workers, if they are bounded.
+func (s *Sink) recordEventPoolMetrics() {
+	if s.EventPool == nil {
+		return
+	}
+	metrics.Record(context.Background(), eventPoolBusy.M(s.EventPool.busy()))
+	metrics.Record(context.Background(), eventPoolQueued.M(s.EventPool.waiting()))
+}
+
+func (s *Sink) recordEventPoolRejectedMetrics(reason string) {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(s.Recorder.reason, reason),
+	)
+
+	if err != nil {
+		s.Logger.Warnf("failed to create tag for metric event_workers_rejected_count: %w", err)
+		return
+	}
+
+	metrics.Record(ctx, eventPoolRejected.M(1))
+}
+
+func (s *Sink) recordInterceptorTimeoutMetrics() {
+	metrics.Record(context.Background(), interceptorTimeouts.M(1))
+}
+
+func (s *Sink) recordNoTriggersMatchedMetrics() {
+	metrics.Record(context.Background(), noTriggersMatched.M(1))
+}
+
+func (s *Sink) recordCreationLimitMetrics(triggerName string) {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(s.Recorder.trigger, triggerName),
+	)
+
+	if err != nil {
+		s.Logger.Warnf("failed to create tag for metric creation_limited_count: %w", err)
+		return
+	}
+
+	metrics.Record(ctx, creationLimited.M(1))
+}
+
+func (s *Sink) recordEventCreateLimitMetrics(triggerName string) {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(s.Recorder.trigger, triggerName),
+	)
+
+	if err != nil {
+		s.Logger.Warnf("failed to create tag for metric event_create_limited_count: %w", err)
+		return
+	}
+
+	metrics.Record(ctx, eventCreateLimited.M(1))
+}
+
+func (s *Sink) recordCreateTimeoutMetrics(triggerName string) {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(s.Recorder.trigger, triggerName),
+	)
+
+	if err != nil {
+		s.Logger.Warnf("failed to create tag for metric create_timeout_count: %w", err)
+		return
+	}
+
+	metrics.Record(ctx, createTimeouts.M(1))
+}
+
+func (s *Sink) recordQuotaRetryQueuedMetrics(triggerName string) {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(s.Recorder.trigger, triggerName),
+	)
+
+	if err != nil {
+		s.Logger.Warnf("failed to create tag for metric quota_retry_queued_count: %w", err)
+		return
+	}
+
+	metrics.Record(ctx, quotaRetryQueued.M(1))
+}
+
+func (s *Sink) recordQuotaRetryDroppedMetrics(triggerName, reason string) {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(s.Recorder.trigger, triggerName),
+		tag.Insert(s.Recorder.reason, reason),
+	)
+
+	if err != nil {
+		s.Logger.Warnf("failed to create tag for metric quota_retry_dropped_count: %w", err)
+		return
+	}
+
+	metrics.Record(ctx, quotaRetryDropped.M(1))
+}
+
+// recordKindNotAllowedMetrics records a resource of the trigger that wasn't created because the resourceKinds
+// of the EventListener don't allow its kind.
+func (s *Sink) recordKindNotAllowedMetrics(triggerName string, gvk schema.GroupVersionKind) {
+	if s.Recorder == nil {
+		return
+	}
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(s.Recorder.trigger, triggerName),
+		tag.Insert(s.Recorder.group, gvk.Group),
+		tag.Insert(s.Recorder.version, gvk.Version),
+		tag.Insert(s.Recorder.kind, gvk.Kind),
+	)
+
+	if err != nil {
+		s.Logger.Warnf("failed to create tag for metric resource_kind_not_allowed_count: %w", err)
+		return
+	}
+
+	metrics.Record(ctx, kindNotAllowed.M(1))
+}
+
+// recordResourceCreateMetrics records the creation of the resource of a template of the trigger, which
+// failed with err if it isn't nil.
+func (s *Sink) recordResourceCreateMetrics(triggerName, kind string, err error) {
+	if s.Recorder == nil {
+		return
+	}
+	status := successTag
+	if err != nil {
+		status = failTag
+	}
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(s.Recorder.trigger, triggerName),
+		tag.Insert(s.Recorder.kind, kind),
+		tag.Insert(s.Recorder.status, status),
+	)
+
+	if err != nil {
+		s.Logger.Warnf("failed to create tag for metric resource_create_count: %w", err)
+		return
+	}
+
+	metrics.Record(ctx, resourceCreates.M(1))
+}
+
+// recordCreatedResourceMetrics records a resource created by resources.Create, tagged with its group, version,
+// kind and namespace, bounded by the ResourceMetricNamespaces. Cluster-scoped resources have no namespace.
+func (s *Sink) recordCreatedResourceMetrics(obj *unstructured.Unstructured) {
+	gvk := obj.GroupVersionKind()
+	namespace := obj.GetNamespace()
+	if namespace != "" {
+		namespace = s.ResourceMetricNamespaces.tag(namespace)
+	}
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(s.Recorder.group, gvk.Group),
+		tag.Insert(s.Recorder.version, gvk.Version),
+		tag.Insert(s.Recorder.kind, gvk.Kind),
+		tag.Insert(s.Recorder.namespace, namespace),
+	)
+
+	if err != nil {
+		s.Logger.Warnf("failed to create tag for metric created_resources_count: %w", err)
+		return
+	}
+
+	metrics.Record(ctx, createdResourceCount.M(1))
+}
+
+func (s *Sink) recordCallbackFailedMetrics(triggerName, reason string) {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(s.Recorder.trigger, triggerName),
+		tag.Insert(s.Recorder.reason, reason),
+	)
+
+	if err != nil {
+		s.Logger.Warnf("failed to create tag for metric callback_failed_count: %w", err)
+		return
+	}
+
+	metrics.Record(ctx, callbackFailed.M(1))
+}
+
+// recordInterceptorMetrics records the execution of an interceptor, tagged with its name, bounded by the
+// InterceptorMetricNames, its type and its outcome. Interceptors that failed before sending a request only
+// get a count and a duration.
+func (s *Sink) recordInterceptorMetrics(res InterceptorResult) {
+	if s.Recorder == nil {
+		return
+	}
+	status := acceptedTag
+	switch {
+	case res.Err != nil:
+		status = failTag
+	case !res.Continue:
+		status = rejectedTag
+	case res.Skipped:
+		status = skippedTag
+	}
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(s.Recorder.interceptor, s.InterceptorMetricNames.tag(res.Name)),
+		tag.Insert(s.Recorder.interceptorType, res.Type),
+	)
+	if err != nil {
+		s.Logger.Warnf("failed to create tag for interceptor metrics: %w", err)
+		return
+	}
+	metrics.Record(ctx, interceptorDuration.M(res.Duration.Seconds()))
+	if res.RequestDuration > 0 {
+		metrics.Record(ctx, interceptorRequestDuration.M(res.RequestDuration.Seconds()))
+	}
+
+	ctx, err = tag.New(ctx, tag.Insert(s.Recorder.status, status))
+	if err != nil {
+		s.Logger.Warnf("failed to create tag for metric interceptor_count: %w", err)
+		return
+	}
+	metrics.Record(ctx, interceptorCount.M(1))
+}
+
+// MetricNames bounds the number of distinct names that tag a metric, and so its cardinality: the first
+// names seen are kept, and the ones seen once the limit is reached are replaced with "other".
+type MetricNames struct {
+	max   int
+	mu    sync.Mutex
+	names map[string]bool
+}
+
+// NewMetricNames returns MetricNames keeping at most max names.
+func NewMetricNames(max int) *MetricNames {
+	return &MetricNames{max: max, names: map[string]bool{}}
+}
+
+// tag returns name if it is one of the names kept, or "other". All the names are kept if n is nil.
+func (n *MetricNames) tag(name string) string {
+	if n == nil {
+		return name
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.names[name] {
+		return name
+	}
+	if len(n.names) >= n.max {
+		return otherMetricName
+	}
+	n.names[name] = true
+	return name
+}
+
 func (s *Sink) recordResourceCreation(resources []json.RawMessage) {
 	for _, rt := range resources {
 		// Assume the TriggerResourceTemplate is valid (it has an apiVersion and Kind)
@@ -147,8 +622,17 @@ func (s *Sink) recordResourceCreation(resources []json.RawMessage) {
 type Recorder struct {
 	initialized bool
 
-	status tag.Key
-	kind   tag.Key
+	status  tag.Key
+	kind    tag.Key
+	reason  tag.Key
+	trigger tag.Key
+
+	interceptor     tag.Key
+	interceptorType tag.Key
+
+	group     tag.Key
+	version   tag.Key
+	namespace tag.Key
 
 	ReportingPeriod time.Duration
 }
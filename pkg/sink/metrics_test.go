@@ -3,12 +3,16 @@ package sink
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"go.opencensus.io/stats/view"
 	"go.uber.org/zap/zaptest"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"knative.dev/pkg/metrics"
 	"knative.dev/pkg/metrics/metricstest"
 )
@@ -169,3 +173,163 @@ func TestRecordRecordCountMetrics(t *testing.T) {
 		})
 	}
 }
+
+func TestRecordInterceptorMetrics(t *testing.T) {
+	defer metricstest.Unregister("interceptor_count", "interceptor_duration_seconds", "interceptor_request_duration_seconds")
+	logger := zaptest.NewLogger(t).Sugar()
+	metrics.FlushExporter()
+	err := metrics.UpdateExporter(context.TODO(), metrics.ExporterOptions{
+		Domain:    "tekton.dev/triggers",
+		Component: "triggers",
+		ConfigMap: map[string]string{},
+	}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := NewRecorder()
+	s := &Sink{
+		Recorder:               r,
+		Logger:                 logger,
+		InterceptorMetricNames: NewMetricNames(2),
+	}
+	s.recordInterceptorMetrics(InterceptorResult{Name: "github", Type: coreInterceptorType, Continue: true, Duration: 3 * time.Millisecond, RequestDuration: 2 * time.Millisecond})
+	s.recordInterceptorMetrics(InterceptorResult{Name: "github", Type: coreInterceptorType, Duration: 3 * time.Millisecond, RequestDuration: 2 * time.Millisecond})
+	s.recordInterceptorMetrics(InterceptorResult{Name: "policy", Type: namespacedInterceptorType, Err: errors.New("connection refused"), Duration: time.Millisecond})
+	s.recordInterceptorMetrics(InterceptorResult{Name: "cel", Type: coreInterceptorType, Continue: true, Duration: time.Millisecond, RequestDuration: time.Millisecond})
+	s.recordInterceptorMetrics(InterceptorResult{Name: "github", Type: clusterInterceptorType, Continue: true, Skipped: true, Duration: time.Millisecond})
+
+	if diff := cmp.Diff(map[string]int64{
+		"github accepted core":     1,
+		"github rejected core":     1,
+		"policy failed namespaced": 1,
+		"other accepted core":      1,
+		"github skipped cluster":   1,
+	}, metricCounts(t, "interceptor_count")); diff != "" {
+		t.Errorf("interceptor_count (-want +got): %s", diff)
+	}
+	if diff := cmp.Diff(map[string]int64{
+		"github core":       2,
+		"policy namespaced": 1,
+		"other core":        1,
+		"github cluster":    1,
+	}, metricCounts(t, "interceptor_duration_seconds")); diff != "" {
+		t.Errorf("interceptor_duration_seconds (-want +got): %s", diff)
+	}
+	// The interceptors that failed before sending a request or were skipped have no request duration.
+	if diff := cmp.Diff(map[string]int64{
+		"github core": 2,
+		"other core":  1,
+	}, metricCounts(t, "interceptor_request_duration_seconds")); diff != "" {
+		t.Errorf("interceptor_request_duration_seconds (-want +got): %s", diff)
+	}
+}
+
+func TestRecordResourceCreateMetrics(t *testing.T) {
+	defer metricstest.Unregister("resource_create_count")
+	logger := zaptest.NewLogger(t).Sugar()
+	metrics.FlushExporter()
+	err := metrics.UpdateExporter(context.TODO(), metrics.ExporterOptions{
+		Domain:    "tekton.dev/triggers",
+		Component: "triggers",
+		ConfigMap: map[string]string{},
+	}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := NewRecorder()
+	s := &Sink{Recorder: r, Logger: logger}
+	s.recordResourceCreateMetrics("build", "PersistentVolumeClaim", nil)
+	s.recordResourceCreateMetrics("build", "PipelineRun", errors.New("boom"))
+	s.recordResourceCreateMetrics("build", "PipelineRun", nil)
+	s.recordResourceCreateMetrics("deploy", "PipelineRun", nil)
+
+	if diff := cmp.Diff(map[string]int64{
+		"PersistentVolumeClaim succeeded build": 1,
+		"PipelineRun failed build":              1,
+		"PipelineRun succeeded build":           1,
+		"PipelineRun succeeded deploy":          1,
+	}, metricCounts(t, "resource_create_count")); diff != "" {
+		t.Errorf("resource_create_count (-want +got): %s", diff)
+	}
+}
+
+func TestRecordCreatedResourceMetrics(t *testing.T) {
+	defer metricstest.Unregister("created_resources_count")
+	logger := zaptest.NewLogger(t).Sugar()
+	metrics.FlushExporter()
+	err := metrics.UpdateExporter(context.TODO(), metrics.ExporterOptions{
+		Domain:    "tekton.dev/triggers",
+		Component: "triggers",
+		ConfigMap: map[string]string{},
+	}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := NewRecorder()
+	s := &Sink{Recorder: r, Logger: logger, ResourceMetricNamespaces: NewMetricNames(1)}
+	resource := func(apiVersion, kind, namespace string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetNamespace(namespace)
+		return obj
+	}
+	s.recordCreatedResourceMetrics(resource("tekton.dev/v1beta1", "PipelineRun", "team-a"))
+	s.recordCreatedResourceMetrics(resource("tekton.dev/v1beta1", "PipelineRun", "team-a"))
+	s.recordCreatedResourceMetrics(resource("tekton.dev/v1beta1", "TaskRun", "team-b"))
+	s.recordCreatedResourceMetrics(resource("v1", "Namespace", ""))
+
+	// The tags are in the order of their keys, and the empty group and namespace tags are left out.
+	if diff := cmp.Diff(map[string]int64{
+		"tekton.dev PipelineRun team-a v1beta1": 2,
+		"tekton.dev TaskRun other v1beta1":      1,
+		"Namespace v1":                          1,
+	}, metricCounts(t, "created_resources_count")); diff != "" {
+		t.Errorf("created_resources_count (-want +got): %s", diff)
+	}
+}
+
+// metricCounts returns the number of measurements of the count or distribution metric, by the values of their tags
+// joined with spaces, in the order of the tag keys.
+func metricCounts(t *testing.T, name string) map[string]int64 {
+	t.Helper()
+	rows, err := view.RetrieveData(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int64{}
+	for _, row := range rows {
+		values := make([]string, len(row.Tags))
+		for i, tag := range row.Tags {
+			values[i] = tag.Value
+		}
+		switch data := row.Data.(type) {
+		case *view.CountData:
+			counts[strings.Join(values, " ")] = data.Value
+		case *view.DistributionData:
+			counts[strings.Join(values, " ")] = data.Count
+		}
+	}
+	return counts
+}
+
+func TestMetricNames(t *testing.T) {
+	n := NewMetricNames(2)
+	for _, tc := range []struct {
+		name string
+		want string
+	}{
+		{name: "github", want: "github"},
+		{name: "cel", want: "cel"},
+		{name: "policy", want: "other"},
+		{name: "github", want: "github"},
+	} {
+		if got := n.tag(tc.name); got != tc.want {
+			t.Errorf("tag(%q) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+	var unbounded *MetricNames
+	if got := unbounded.tag("policy"); got != "policy" {
+		t.Errorf("tag() of nil MetricNames = %q, want the name", got)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/tektoncd/triggers/pkg/resources"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TriggerResult is the outcome of a trigger or trigger group for an event, in the multi-status responses
+// of synchronous sinks.
+type TriggerResult struct {
+	// Trigger is the name of the trigger or trigger group.
+	Trigger string `json:"trigger"`
+	// Outcome is fired, rejected or failed.
+	Outcome string `json:"outcome"`
+	// StatusCode is the status code the sink would have responded with if the outcome were the only one.
+	StatusCode int `json:"statusCode"`
+	// Reason is why the trigger failed, see failureReason, or the status code returned by the interceptor
+	// that rejected the event, e.g. FailedPrecondition.
+	Reason string `json:"reason,omitempty"`
+	// Message is the message of the interceptor that rejected the event.
+	Message string `json:"message,omitempty"`
+	// Resources are the outcomes of the resources of the trigger: the created ones, in creation order,
+	// then the resource templates that failed.
+	Resources []ResourceResult `json:"resources,omitempty"`
+}
+
+// ResourceResult is the outcome of one resource of a trigger, in the multi-status responses of synchronous
+// sinks.
+type ResourceResult struct {
+	// Outcome is created or failed.
+	Outcome string `json:"outcome"`
+	// Resource identifies the created resource.
+	Resource *CreatedResource `json:"resource,omitempty"`
+	// TemplateIndex is the index of the resource template that failed, in the resources of the trigger.
+	TemplateIndex *int `json:"templateIndex,omitempty"`
+	// Kind is the kind of the resource template that failed.
+	Kind string `json:"kind,omitempty"`
+	// StatusCode is the status code matching the failure, like the one of the trigger.
+	StatusCode int `json:"statusCode,omitempty"`
+	// Reason is why the resource couldn't be created, see failureReason.
+	Reason string `json:"reason,omitempty"`
+}
+
+// failureReason returns why a trigger or the creation of one of its resources failed with err, without the
+// details of the error, which may contain details of the cluster that the sender shouldn't see: the error
+// of the sink, e.g. CreateTimeout, or else the reason of the API server, e.g. Forbidden or AlreadyExists,
+// or else InternalError.
+func failureReason(err error) string {
+	switch {
+	case errors.Is(err, ErrCreationLimitExceeded):
+		return "CreationLimitExceeded"
+	case errors.Is(err, ErrEventCreateLimitExceeded):
+		return "EventCreateLimitExceeded"
+	case errors.Is(err, ErrCreateTimeout):
+		return "CreateTimeout"
+	case errors.Is(err, ErrInterceptorChainTimeout):
+		return "InterceptorChainTimeout"
+	case errors.Is(err, ErrQuotaRetryDropped):
+		return "QuotaRetryDropped"
+	case errors.Is(err, resources.ErrDiscoveryNotReady):
+		return "DiscoveryNotReady"
+	}
+	if reason := kerrors.ReasonForError(err); reason != metav1.StatusReasonUnknown {
+		return string(reason)
+	}
+	return string(metav1.StatusReasonInternalError)
+}
+
+// multiStatus returns the status code of the response of a synchronous sink that responds with the outcome
+// of each trigger, with an error message like status: 207 Multi-Status if the outcomes are mixed, i.e. some
+// triggers failed while resources were created, and else the status code of status. Rejections don't make
+// the outcomes mixed, since interceptors usually reject the events their trigger doesn't handle.
+func (o *triggerOutcomes) multiStatus() (int, string) {
+	o.mu.Lock()
+	mixed := len(o.failed) > 0 && len(o.resources) > 0
+	o.mu.Unlock()
+	if mixed {
+		return http.StatusMultiStatus, ""
+	}
+	return o.status()
+}
+
+// results returns the outcomes of the triggers and trigger groups, sorted by name.
+func (o *triggerOutcomes) results() []TriggerResult {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	resourcesOf := map[string][]ResourceResult{}
+	for i := range o.resources {
+		created := o.resources[i]
+		resourcesOf[created.Trigger] = append(resourcesOf[created.Trigger], ResourceResult{Outcome: createdOutcome, Resource: &created})
+	}
+
+	results := []TriggerResult{}
+	for _, name := range o.fired {
+		results = append(results, TriggerResult{
+			Trigger:    name,
+			Outcome:    firedOutcome,
+			StatusCode: http.StatusCreated,
+			Resources:  resourcesOf[name],
+		})
+	}
+	for _, r := range o.rejected {
+		results = append(results, TriggerResult{
+			Trigger:    r.name,
+			Outcome:    rejectedOutcome,
+			StatusCode: httpStatus(r.status.Code),
+			Reason:     r.status.Code.String(),
+			Message:    r.status.Message,
+		})
+	}
+	for _, f := range o.failed {
+		results = append(results, TriggerResult{
+			Trigger:    f.name,
+			Outcome:    failedOutcome,
+			StatusCode: failureStatus(f.err),
+			Reason:     failureReason(f.err),
+			Resources:  append(resourcesOf[f.name], failedResources(f.err)...),
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Trigger < results[j].Trigger })
+	return results
+}
+
+// failedResources returns the outcomes of the resource templates that failed with err, if any.
+func failedResources(err error) []ResourceResult {
+	var failed ResourceTemplateErrors
+	if !errors.As(err, &failed) {
+		var one *ResourceTemplateError
+		if !errors.As(err, &one) {
+			return nil
+		}
+		failed = ResourceTemplateErrors{one}
+	}
+	results := make([]ResourceResult, 0, len(failed))
+	for _, f := range failed {
+		index := f.Index
+		results = append(results, ResourceResult{
+			Outcome:       failedOutcome,
+			TemplateIndex: &index,
+			Kind:          f.Kind,
+			StatusCode:    failureStatus(f.Err),
+			Reason:        failureReason(f.Err),
+		})
+	}
+	return results
+}
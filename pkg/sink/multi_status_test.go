@@ -0,0 +1,245 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	triggersv1alpha1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	triggersv1beta1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/test"
+	"google.golang.org/grpc/codes"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/ptr"
+)
+
+func TestTriggerOutcomes_MultiStatus(t *testing.T) {
+	taskRun := &unstructured.Unstructured{}
+	taskRun.SetAPIVersion("tekton.dev/v1beta1")
+	taskRun.SetKind("TaskRun")
+	taskRun.SetNamespace(namespace)
+	taskRun.SetName("run")
+
+	for _, tc := range []struct {
+		name     string
+		record   func(o *triggerOutcomes)
+		wantCode int
+		wantMsg  string
+	}{{
+		name: "created resources",
+		record: func(o *triggerOutcomes) {
+			o.fire("fires", []*unstructured.Unstructured{taskRun})
+			o.reject("filtered", triggersv1beta1.Status{Code: codes.FailedPrecondition, Message: "expression was false"})
+		},
+		wantCode: http.StatusCreated,
+	}, {
+		name: "failed after another trigger created resources",
+		record: func(o *triggerOutcomes) {
+			o.fire("fires", []*unstructured.Unstructured{taskRun})
+			o.fail("broken", nil, errors.New("couldn't create resource"))
+		},
+		wantCode: http.StatusMultiStatus,
+	}, {
+		name: "failed after creating resources",
+		record: func(o *triggerOutcomes) {
+			o.fail("broken", []*unstructured.Unstructured{taskRun}, errors.New("couldn't create resource"))
+		},
+		wantCode: http.StatusMultiStatus,
+	}, {
+		name: "failed without creating resources",
+		record: func(o *triggerOutcomes) {
+			o.fail("limited", nil, fmt.Errorf("trigger limited: %w", ErrCreationLimitExceeded))
+		},
+		wantCode: http.StatusTooManyRequests,
+		wantMsg:  "failed to process the event for limited",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &triggerOutcomes{}
+			tc.record(o)
+			code, msg := o.multiStatus()
+			if code != tc.wantCode {
+				t.Errorf("multiStatus() got code %d, want %d", code, tc.wantCode)
+			}
+			if msg != tc.wantMsg {
+				t.Errorf("multiStatus() got message %q, want %q", msg, tc.wantMsg)
+			}
+		})
+	}
+}
+
+func templateIndex(i int) *int {
+	return &i
+}
+
+func TestTriggerOutcomes_Results(t *testing.T) {
+	taskRun := &unstructured.Unstructured{}
+	taskRun.SetAPIVersion("tekton.dev/v1beta1")
+	taskRun.SetKind("TaskRun")
+	taskRun.SetNamespace(namespace)
+	taskRun.SetName("run")
+	forbidden := kerrors.NewForbidden(schema.GroupResource{Group: "tekton.dev", Resource: "pipelineruns"}, "", errors.New("cannot create"))
+
+	o := &triggerOutcomes{}
+	o.fire("fires", []*unstructured.Unstructured{taskRun})
+	o.reject("filtered", triggersv1beta1.Status{Code: codes.FailedPrecondition, Message: "expression was false"})
+	o.fail("best-effort", []*unstructured.Unstructured{taskRun}, ResourceTemplateErrors{
+		{Index: 1, Kind: "PipelineRun", Err: forbidden},
+		{Index: 2, Kind: "TaskRun", Err: fmt.Errorf("creating: %w", ErrCreateTimeout)},
+	})
+	o.fail("fail-fast", nil, &ResourceTemplateError{Index: 0, Kind: "PipelineRun", Err: errors.New("connection refused")})
+	o.fail("limited", nil, fmt.Errorf("trigger limited: %w", ErrCreationLimitExceeded))
+
+	created := &CreatedResource{APIVersion: "tekton.dev/v1beta1", Kind: "TaskRun", Namespace: namespace, Name: "run"}
+	createdBy := func(trigger string) *CreatedResource {
+		c := *created
+		c.Trigger = trigger
+		return &c
+	}
+	want := []TriggerResult{{
+		Trigger:    "best-effort",
+		Outcome:    failedOutcome,
+		StatusCode: http.StatusGatewayTimeout,
+		Reason:     "CreateTimeout",
+		Resources: []ResourceResult{
+			{Outcome: createdOutcome, Resource: createdBy("best-effort")},
+			{Outcome: failedOutcome, TemplateIndex: templateIndex(1), Kind: "PipelineRun", StatusCode: http.StatusInternalServerError, Reason: "Forbidden"},
+			{Outcome: failedOutcome, TemplateIndex: templateIndex(2), Kind: "TaskRun", StatusCode: http.StatusGatewayTimeout, Reason: "CreateTimeout"},
+		},
+	}, {
+		Trigger:    "fail-fast",
+		Outcome:    failedOutcome,
+		StatusCode: http.StatusInternalServerError,
+		Reason:     "InternalError",
+		Resources: []ResourceResult{
+			{Outcome: failedOutcome, TemplateIndex: templateIndex(0), Kind: "PipelineRun", StatusCode: http.StatusInternalServerError, Reason: "InternalError"},
+		},
+	}, {
+		Trigger:    "filtered",
+		Outcome:    rejectedOutcome,
+		StatusCode: http.StatusBadRequest,
+		Reason:     "FailedPrecondition",
+		Message:    "expression was false",
+	}, {
+		Trigger:    "fires",
+		Outcome:    firedOutcome,
+		StatusCode: http.StatusCreated,
+		Resources:  []ResourceResult{{Outcome: createdOutcome, Resource: createdBy("fires")}},
+	}, {
+		Trigger:    "limited",
+		Outcome:    failedOutcome,
+		StatusCode: http.StatusTooManyRequests,
+		Reason:     "CreationLimitExceeded",
+	}}
+	if diff := cmp.Diff(want, o.results()); diff != "" {
+		t.Errorf("results() -want +got: %s", diff)
+	}
+}
+
+func TestFailureReason(t *testing.T) {
+	alreadyExists := kerrors.NewAlreadyExists(schema.GroupResource{Group: "tekton.dev", Resource: "taskruns"}, "run")
+	for _, tc := range []struct {
+		err  error
+		want string
+	}{
+		{err: fmt.Errorf("couldn't create resource: %w", alreadyExists), want: "AlreadyExists"},
+		{err: fmt.Errorf("trigger limited: %w", ErrCreationLimitExceeded), want: "CreationLimitExceeded"},
+		{err: errors.New("connection refused"), want: "InternalError"},
+	} {
+		if got := failureReason(tc.err); got != tc.want {
+			t.Errorf("failureReason(%v) = %s, want %s", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestHandleEvent_SynchronousMultiStatus(t *testing.T) {
+	eventBody := json.RawMessage(`{"head_commit": {"id": "testrevision"}, "repository": {"url": "testurl"}, "foo": "bar"}`)
+	trigger := func(name string, filter string) triggersv1beta1.EventListenerTrigger {
+		return triggersv1beta1.EventListenerTrigger{
+			Name: name,
+			Interceptors: []*triggersv1beta1.TriggerInterceptor{{
+				Ref: triggersv1beta1.InterceptorRef{Name: "cel", Kind: triggersv1beta1.ClusterInterceptorKind},
+				Params: []triggersv1beta1.InterceptorParams{{
+					Name:  "filter",
+					Value: test.ToV1JSON(t, filter),
+				}},
+			}},
+			Bindings: []*triggersv1beta1.EventListenerBinding{
+				{Name: "url", Value: ptr.String("$(body.repository.url)")},
+				{Name: "revision", Value: ptr.String("$(body.head_commit.id)")},
+			},
+			Template: &triggersv1beta1.EventListenerTemplate{Spec: makeGitCloneTTSpec(t, name+"-run")},
+		}
+	}
+	resources := test.Resources{
+		EventListeners: []*triggersv1beta1.EventListener{{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-el", Namespace: namespace, UID: types.UID(elUID)},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{trigger("fires", "has(body.head_commit)"), trigger("filtered", "has(body.missing)")},
+			},
+		}},
+		ClusterInterceptors: []*triggersv1alpha1.ClusterInterceptor{cel},
+	}
+	sink, _ := getSinkAssets(t, resources, "test-el", nil)
+	sink.Synchronous = true
+	sink.MultiStatus = true
+
+	ts := httptest.NewServer(http.HandlerFunc(sink.HandleEvent))
+	defer ts.Close()
+	resp, err := http.Post(ts.URL, "application/json", bytes.NewReader(eventBody))
+	if err != nil {
+		t.Fatalf("error making request to eventListener: %s", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("got response code %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+
+	var got Response
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("Error reading response body: %s", err)
+	}
+	created := CreatedResource{APIVersion: "tekton.dev/v1beta1", Kind: "TaskRun", Namespace: namespace, Name: "fires-run", Trigger: "fires"}
+	want := []TriggerResult{{
+		Trigger:    "filtered",
+		Outcome:    rejectedOutcome,
+		StatusCode: http.StatusBadRequest,
+		Reason:     "FailedPrecondition",
+		Message:    "expression has(body.missing) did not return true",
+	}, {
+		Trigger:    "fires",
+		Outcome:    firedOutcome,
+		StatusCode: http.StatusCreated,
+		Resources:  []ResourceResult{{Outcome: createdOutcome, Resource: &created}},
+	}}
+	if diff := cmp.Diff(want, got.Results); diff != "" {
+		t.Errorf("results -want +got: %s", diff)
+	}
+	if diff := cmp.Diff([]CreatedResource{created}, got.Resources); diff != "" {
+		t.Errorf("resources -want +got: %s", diff)
+	}
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+)
+
+// maxDecodedPayloadSize bounds the size of decompressed payloads, so that a small compressed request
+// can't exhaust the memory of the EventListener.
+const maxDecodedPayloadSize = 10 << 20
+
+// PayloadParser converts the body of a request into the payload passed to the interceptors and bindings
+// of the triggers, which is expected to be JSON.
+type PayloadParser interface {
+	Parse(body []byte, header http.Header) ([]byte, error)
+}
+
+// PayloadParserFunc is a PayloadParser function.
+type PayloadParserFunc func(body []byte, header http.Header) ([]byte, error)
+
+// Parse calls f.
+func (f PayloadParserFunc) Parse(body []byte, header http.Header) ([]byte, error) {
+	return f(body, header)
+}
+
+// PayloadParsers is a registry of the PayloadParsers applied to request bodies, keyed by media type for
+// the Content-Type header and by content coding for the Content-Encoding header. A body is first decoded
+// by the parsers of its content codings, and then converted by the parser of its media type. Bodies
+// without a parser for their media type are passed on as is.
+type PayloadParsers struct {
+	mediaTypes map[string]PayloadParser
+	encodings  map[string]PayloadParser
+}
+
+// NewPayloadParsers returns an empty registry.
+func NewPayloadParsers() *PayloadParsers {
+	return &PayloadParsers{
+		mediaTypes: map[string]PayloadParser{},
+		encodings:  map[string]PayloadParser{},
+	}
+}
+
+// DefaultPayloadParsers returns a registry with the JSON parser.
+func DefaultPayloadParsers() *PayloadParsers {
+	p := NewPayloadParsers()
+	p.Register("application/json", JSONParser)
+	p.Register("application/*+json", JSONParser)
+	return p
+}
+
+// PayloadParsersFor returns the default registry with the optional parsers of the given names, as
+// accepted by the triggers.PayloadParsersAnnotation, registered.
+func PayloadParsersFor(names []string) (*PayloadParsers, error) {
+	p := DefaultPayloadParsers()
+	for _, name := range names {
+		switch name {
+		case triggers.PayloadParserForm:
+			p.Register("application/x-www-form-urlencoded", FormParser)
+		case triggers.PayloadParserGzip:
+			p.RegisterEncoding("gzip", GzipParser)
+			p.RegisterEncoding("x-gzip", GzipParser)
+		case triggers.PayloadParserProtobuf:
+			p.Register("application/protobuf", ProtobufParser)
+			p.Register("application/x-protobuf", ProtobufParser)
+		default:
+			return nil, fmt.Errorf("unknown payload parser %q", name)
+		}
+	}
+	return p, nil
+}
+
+// Register registers the parser of the bodies of the given media type, which may contain wildcards,
+// e.g. application/*+json.
+func (p *PayloadParsers) Register(mediaType string, parser PayloadParser) {
+	p.mediaTypes[strings.ToLower(mediaType)] = parser
+}
+
+// RegisterEncoding registers the parser decoding the bodies of the given content coding, e.g. gzip.
+func (p *PayloadParsers) RegisterEncoding(coding string, parser PayloadParser) {
+	p.encodings[strings.ToLower(coding)] = parser
+}
+
+// MediaTypes returns the sorted media types with a registered parser.
+func (p *PayloadParsers) MediaTypes() []string {
+	mediaTypes := make([]string, 0, len(p.mediaTypes))
+	for mt := range p.mediaTypes {
+		mediaTypes = append(mediaTypes, mt)
+	}
+	sort.Strings(mediaTypes)
+	return mediaTypes
+}
+
+// Parse decodes the body and converts it, see Decode and Convert.
+func (p *PayloadParsers) Parse(body []byte, header http.Header) ([]byte, error) {
+	decoded, err := p.Decode(body, header)
+	if err != nil {
+		return nil, err
+	}
+	return p.Convert(decoded, header)
+}
+
+// Decode decodes the body with the parsers of its content codings, in the order they were applied. The
+// Content-Encoding header is removed from header once the body is decoded. Bodies with a content coding
+// without a parser are left as is.
+func (p *PayloadParsers) Decode(body []byte, header http.Header) ([]byte, error) {
+	codings := contentCodings(header)
+	if len(codings) == 0 {
+		return body, nil
+	}
+	for _, c := range codings {
+		if _, ok := p.encodings[c]; !ok && c != "identity" {
+			return body, nil
+		}
+	}
+	for i := len(codings) - 1; i >= 0; i-- {
+		parser, ok := p.encodings[codings[i]]
+		if !ok {
+			continue
+		}
+		var err error
+		if body, err = parser.Parse(body, header); err != nil {
+			return nil, fmt.Errorf("failed to decode %s body: %w", codings[i], err)
+		}
+	}
+	header.Del("Content-Encoding")
+	return body, nil
+}
+
+// Convert converts the decoded body with the parser of its media type. The Content-Type header is set to
+// application/json if the parser changed the body, since the payload is then the JSON it converted the
+// body into.
+func (p *PayloadParsers) Convert(body []byte, header http.Header) ([]byte, error) {
+	parser, ok := p.parserFor(header.Get("Content-Type"))
+	if !ok {
+		return body, nil
+	}
+	converted, err := parser.Parse(body, header)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(converted, body) {
+		header.Set("Content-Type", "application/json")
+	}
+	return converted, nil
+}
+
+// parserFor returns the parser of the media type of contentType, preferring an exact match to a
+// wildcard one.
+func (p *PayloadParsers) parserFor(contentType string) (PayloadParser, bool) {
+	if contentType == "" {
+		return nil, false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	if parser, ok := p.mediaTypes[mediaType]; ok {
+		return parser, true
+	}
+	for _, pattern := range p.MediaTypes() {
+		if ok, _ := path.Match(pattern, mediaType); ok {
+			return p.mediaTypes[pattern], true
+		}
+	}
+	return nil, false
+}
+
+// contentCodings returns the lower case content codings of the Content-Encoding header, in the order
+// they were applied.
+func contentCodings(header http.Header) []string {
+	var codings []string
+	for _, value := range header.Values("Content-Encoding") {
+		for _, c := range strings.Split(value, ",") {
+			if c = strings.ToLower(strings.TrimSpace(c)); c != "" {
+				codings = append(codings, c)
+			}
+		}
+	}
+	return codings
+}
+
+// JSONParser passes JSON bodies on as is. They are validated separately, when payload validation is
+// enabled.
+var JSONParser = PayloadParserFunc(func(body []byte, _ http.Header) ([]byte, error) {
+	return body, nil
+})
+
+// FormParser converts URL encoded form bodies into a JSON object with a string for each field, or an
+// array of strings for the fields that are set several times.
+var FormParser = PayloadParserFunc(func(body []byte, _ http.Header) ([]byte, error) {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid form body: %w", err)
+	}
+	form := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if len(v) == 1 {
+			form[k] = v[0]
+		} else {
+			form[k] = v
+		}
+	}
+	return json.Marshal(form)
+})
+
+// ProtobufParser converts binary protobuf bodies into a JSON string holding their standard base64
+// encoding, so that they reach the interceptors intact. They are then decoded by the protobuf
+// interceptor, which knows their message type.
+var ProtobufParser = PayloadParserFunc(func(body []byte, _ http.Header) ([]byte, error) {
+	return json.Marshal(base64.StdEncoding.EncodeToString(body))
+})
+
+// GzipParser decompresses gzip bodies of up to 10 MiB.
+var GzipParser = PayloadParserFunc(func(body []byte, _ http.Header) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	decoded, err := io.ReadAll(io.LimitReader(zr, maxDecodedPayloadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) > maxDecodedPayloadSize {
+		return nil, fmt.Errorf("decompressed body is larger than %d bytes", maxDecodedPayloadSize)
+	}
+	return decoded, nil
+})
@@ -0,0 +1,241 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap/zaptest"
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestPayloadParsers_Parse(t *testing.T) {
+	all, err := PayloadParsersFor([]string{"form", "gzip", "protobuf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name       string
+		parsers    *PayloadParsers
+		body       []byte
+		header     http.Header
+		want       string
+		wantHeader http.Header
+	}{{
+		name:       "json",
+		parsers:    DefaultPayloadParsers(),
+		body:       []byte(`{"a":"b"}`),
+		header:     http.Header{"Content-Type": {"application/json"}},
+		want:       `{"a":"b"}`,
+		wantHeader: http.Header{"Content-Type": {"application/json"}},
+	}, {
+		name:       "form is not parsed by default",
+		parsers:    DefaultPayloadParsers(),
+		body:       []byte(`a=b`),
+		header:     http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+		want:       `a=b`,
+		wantHeader: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+	}, {
+		name:       "form",
+		parsers:    all,
+		body:       []byte(`name=build&tag=a&tag=b`),
+		header:     http.Header{"Content-Type": {"application/x-www-form-urlencoded; charset=utf-8"}},
+		want:       `{"name":"build","tag":["a","b"]}`,
+		wantHeader: http.Header{"Content-Type": {"application/json"}},
+	}, {
+		name:       "gzip json",
+		parsers:    all,
+		body:       gzipped(t, `{"a":"b"}`),
+		header:     http.Header{"Content-Type": {"application/json"}, "Content-Encoding": {"gzip"}},
+		want:       `{"a":"b"}`,
+		wantHeader: http.Header{"Content-Type": {"application/json"}},
+	}, {
+		name:       "gzip form",
+		parsers:    all,
+		body:       gzipped(t, `a=b`),
+		header:     http.Header{"Content-Type": {"application/x-www-form-urlencoded"}, "Content-Encoding": {"GZIP"}},
+		want:       `{"a":"b"}`,
+		wantHeader: http.Header{"Content-Type": {"application/json"}},
+	}, {
+		name:       "protobuf",
+		parsers:    all,
+		body:       []byte{0x0a, 0x04, 'm', 'a', 'i', 'n', 0xff},
+		header:     http.Header{"Content-Type": {"application/x-protobuf"}},
+		want:       `"CgRtYWlu/w=="`,
+		wantHeader: http.Header{"Content-Type": {"application/json"}},
+	}, {
+		name:       "unregistered content coding",
+		parsers:    all,
+		body:       []byte(`compressed`),
+		header:     http.Header{"Content-Type": {"application/json"}, "Content-Encoding": {"br"}},
+		want:       `compressed`,
+		wantHeader: http.Header{"Content-Type": {"application/json"}, "Content-Encoding": {"br"}},
+	}, {
+		name:       "registered parser",
+		parsers:    registry("text/*", upperParser),
+		body:       []byte(`hi`),
+		header:     http.Header{"Content-Type": {"text/plain"}},
+		want:       `"HI"`,
+		wantHeader: http.Header{"Content-Type": {"application/json"}},
+	}, {
+		name:       "no content type",
+		parsers:    all,
+		body:       []byte(`a=b`),
+		header:     http.Header{},
+		want:       `a=b`,
+		wantHeader: http.Header{},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.parsers.Parse(tc.body, tc.header)
+			if err != nil {
+				t.Fatalf("Parse() returned error: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("Parse() = %s, want %s", got, tc.want)
+			}
+			if diff := cmp.Diff(tc.wantHeader, tc.header); diff != "" {
+				t.Errorf("header -want +got: %s", diff)
+			}
+		})
+	}
+}
+
+func registry(mediaType string, parser PayloadParser) *PayloadParsers {
+	p := NewPayloadParsers()
+	p.Register(mediaType, parser)
+	return p
+}
+
+var upperParser = PayloadParserFunc(func(body []byte, _ http.Header) ([]byte, error) {
+	return []byte(`"` + strings.ToUpper(string(body)) + `"`), nil
+})
+
+func TestPayloadParsers_Parse_Error(t *testing.T) {
+	all, err := PayloadParsersFor([]string{"form", "gzip"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name   string
+		body   []byte
+		header http.Header
+	}{{
+		name:   "invalid form",
+		body:   []byte(`a=%zz`),
+		header: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+	}, {
+		name:   "invalid gzip",
+		body:   []byte(`{}`),
+		header: http.Header{"Content-Type": {"application/json"}, "Content-Encoding": {"gzip"}},
+	}, {
+		name:   "gzip bomb",
+		body:   gzipped(t, strings.Repeat("a", maxDecodedPayloadSize+1)),
+		header: http.Header{"Content-Encoding": {"gzip"}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, err := all.Parse(tc.body, tc.header); err == nil {
+				t.Errorf("Parse() = %s, want error", got)
+			}
+		})
+	}
+}
+
+func TestPayloadParsersFor_Unknown(t *testing.T) {
+	if _, err := PayloadParsersFor([]string{"xml"}); err == nil {
+		t.Error("PayloadParsersFor() accepted an unknown parser")
+	}
+}
+
+func TestSink_IsValidPayload_PayloadParsers(t *testing.T) {
+	recorder, err := NewRecorder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsers, err := PayloadParsersFor([]string{"form"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Sink{
+		Logger:            zaptest.NewLogger(t).Sugar(),
+		Recorder:          recorder,
+		PayloadValidation: true,
+		PayloadParsers:    parsers,
+	}
+	var got, gotRaw []byte
+	var gotContentType string
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got, _ = io.ReadAll(req.Body)
+		gotRaw = rawPayloadFrom(req.Context())
+		gotContentType = req.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`ref=main`))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp := httptest.NewRecorder()
+	r.FilterRequests(r.IsValidPayload(next)).ServeHTTP(resp, req)
+	if resp.Code != http.StatusAccepted {
+		t.Fatalf("unexpected status code: got %d, want %d", resp.Code, http.StatusAccepted)
+	}
+	if string(got) != `{"ref":"main"}` {
+		t.Errorf("got payload %s, want the form as JSON", got)
+	}
+	if string(gotRaw) != `ref=main` {
+		t.Errorf("got raw payload %q, want the form as it was sent", gotRaw)
+	}
+	if gotContentType != "application/json" {
+		t.Errorf("got Content-Type %q, want application/json", gotContentType)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"ref":"main"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp = httptest.NewRecorder()
+	r.IsValidPayload(next).ServeHTTP(resp, req)
+	if resp.Code != http.StatusAccepted {
+		t.Fatalf("unexpected status code: got %d, want %d", resp.Code, http.StatusAccepted)
+	}
+	if gotRaw != nil {
+		t.Errorf("got raw payload %q for an unconverted payload, want none", gotRaw)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`ref=%zz`))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp = httptest.NewRecorder()
+	r.IsValidPayload(next).ServeHTTP(resp, req)
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("unexpected status code: got %d, want %d", resp.Code, http.StatusBadRequest)
+	}
+}
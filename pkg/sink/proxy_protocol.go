@@ -0,0 +1,215 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// proxyProtocolV1MaxLength is the maximum length of a PROXY protocol v1 header, including its CRLF.
+	proxyProtocolV1MaxLength = 107
+	// proxyProtocolV2HeaderLength is the length of the fixed part of a PROXY protocol v2 header.
+	proxyProtocolV2HeaderLength = 16
+)
+
+// proxyProtocolV2Signature starts the binary PROXY protocol v2 headers.
+var proxyProtocolV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+var errNoProxyHeader = errors.New("missing PROXY protocol header")
+
+// ProxyProtocolListener is a net.Listener that reads the PROXY protocol (v1 or v2) header at the start of the
+// connections from its Upstreams, e.g. L4 load balancers, which can't add forwarding headers to the requests
+// they pass on. The remote address of these connections is the client address of their header, so that it is
+// the client IP of their requests. The connections from other peers are served as they are, so that only the
+// upstreams can set the client address.
+//
+// The connections from the upstreams whose header is missing or invalid fail. The header is read by the
+// goroutine serving the connection, on its first read or call to RemoteAddr, so that slow peers don't hold up
+// Accept.
+type ProxyProtocolListener struct {
+	net.Listener
+	// Upstreams are the networks of the peers that send a PROXY protocol header.
+	Upstreams TrustedProxies
+	// HeaderTimeout bounds the time to read the header. There is no timeout if 0.
+	HeaderTimeout time.Duration
+	// Logger, if set, logs the connections that fail because of their header.
+	Logger *zap.SugaredLogger
+}
+
+// Accept returns the next connection, which reads the PROXY protocol header if its peer is an upstream.
+func (l *ProxyProtocolListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if addr, ok := c.RemoteAddr().(*net.TCPAddr); !ok || !l.Upstreams.trusted(addr.IP) {
+		return c, nil
+	}
+	return &proxyProtocolConn{Conn: c, listener: l, reader: bufio.NewReader(c)}, nil
+}
+
+// proxyProtocolConn is a connection from an upstream, whose data starts with a PROXY protocol header.
+type proxyProtocolConn struct {
+	net.Conn
+	listener *ProxyProtocolListener
+	reader   *bufio.Reader
+
+	once sync.Once
+	// remoteAddr is the client address of the header, or nil if the header has none, e.g. for the health
+	// checks of the upstream.
+	remoteAddr net.Addr
+	err        error
+}
+
+func (c *proxyProtocolConn) readHeader() {
+	c.once.Do(func() {
+		if d := c.listener.HeaderTimeout; d > 0 {
+			_ = c.Conn.SetReadDeadline(time.Now().Add(d))
+			defer func() { _ = c.Conn.SetReadDeadline(time.Time{}) }()
+		}
+		c.remoteAddr, c.err = readProxyHeader(c.reader)
+		if c.err != nil && c.listener.Logger != nil {
+			c.listener.Logger.Warnf("invalid PROXY protocol header from %s: %v", c.Conn.RemoteAddr(), c.err)
+		}
+	})
+}
+
+// Read reads the data that follows the header.
+func (c *proxyProtocolConn) Read(b []byte) (int, error) {
+	c.readHeader()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.reader.Read(b)
+}
+
+// RemoteAddr returns the client address of the header, or else the address of the upstream.
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	c.readHeader()
+	if c.remoteAddr == nil {
+		return c.Conn.RemoteAddr()
+	}
+	return c.remoteAddr
+}
+
+// readProxyHeader reads a PROXY protocol v1 or v2 header from r, and returns its client address, or nil if
+// it has none.
+func readProxyHeader(r *bufio.Reader) (net.Addr, error) {
+	b, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	switch b[0] {
+	case 'P':
+		return readProxyHeaderV1(r)
+	case proxyProtocolV2Signature[0]:
+		return readProxyHeaderV2(r)
+	}
+	return nil, errNoProxyHeader
+}
+
+// readProxyHeaderV1 reads a human-readable header, e.g. "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n".
+func readProxyHeaderV1(r *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for len(line) < proxyProtocolV1MaxLength && !bytes.HasSuffix(line, []byte("\n")) {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, fmt.Errorf("the v1 header doesn't end with CRLF within %d bytes", proxyProtocolV1MaxLength)
+	}
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if fields[0] != "PROXY" {
+		return nil, errNoProxyHeader
+	}
+	if len(fields) > 1 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("invalid v1 header %q", line)
+	}
+	ip := net.ParseIP(fields[2])
+	if ip == nil {
+		return nil, fmt.Errorf("invalid source address %q in the v1 header", fields[2])
+	}
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source port %q in the v1 header", fields[4])
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// readProxyHeaderV2 reads a binary header. Only the addresses of TCP over IPv4 and IPv6 are used, and the
+// TLVs that follow them are skipped.
+func readProxyHeaderV2(r *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, proxyProtocolV2HeaderLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:len(proxyProtocolV2Signature)], proxyProtocolV2Signature) {
+		return nil, errNoProxyHeader
+	}
+	if version := header[12] >> 4; version != 2 {
+		return nil, fmt.Errorf("unsupported version %d in the v2 header", version)
+	}
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	switch command := header[12] & 0x0f; command {
+	case 0x0:
+		// LOCAL connections, e.g. the health checks of the upstream, have no client.
+		return nil, nil
+	case 0x1:
+	default:
+		return nil, fmt.Errorf("unsupported command %d in the v2 header", command)
+	}
+
+	var ipLen int
+	switch header[13] {
+	case 0x11:
+		ipLen = net.IPv4len
+	case 0x21:
+		ipLen = net.IPv6len
+	default:
+		// The other families and protocols have no client address the EventListener can use.
+		return nil, nil
+	}
+	// The source and destination addresses are followed by the source and destination ports.
+	if len(payload) < 2*ipLen+4 {
+		return nil, fmt.Errorf("the addresses of the v2 header are truncated")
+	}
+	ip := make(net.IP, ipLen)
+	copy(ip, payload[:ipLen])
+	return &net.TCPAddr{IP: ip, Port: int(binary.BigEndian.Uint16(payload[2*ipLen:]))}, nil
+}
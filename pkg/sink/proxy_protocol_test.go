@@ -0,0 +1,206 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	"go.uber.org/zap/zaptest"
+)
+
+// proxyHeaderV2 returns a v2 header with the command, the family and protocol, and the addresses.
+func proxyHeaderV2(command, family byte, addresses []byte) []byte {
+	b := append([]byte{}, proxyProtocolV2Signature...)
+	b = append(b, 0x20|command, family, 0, 0)
+	binary.BigEndian.PutUint16(b[14:], uint16(len(addresses)))
+	return append(b, addresses...)
+}
+
+func TestReadProxyHeader(t *testing.T) {
+	ipv4 := []byte{192, 0, 2, 1, 198, 51, 100, 1, 0xdc, 0x04, 0x01, 0xbb}
+	ipv6 := append(append(net.ParseIP("2001:db8::1").To16(), net.ParseIP("2001:db8::2").To16()...), 0xdc, 0x04, 0x01, 0xbb)
+	// A TLV of the AWS VPC endpoint ID follows the addresses.
+	tlvs := append(append([]byte{}, ipv4...), 0xea, 0x00, 0x04, 0x01, 'v', 'p', 'c')
+	for _, tc := range []struct {
+		name    string
+		header  []byte
+		want    string
+		wantErr string
+	}{{
+		name:   "v1 TCP4",
+		header: []byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n"),
+		want:   "192.0.2.1:56324",
+	}, {
+		name:   "v1 TCP6",
+		header: []byte("PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\n"),
+		want:   "[2001:db8::1]:56324",
+	}, {
+		name:   "v1 UNKNOWN",
+		header: []byte("PROXY UNKNOWN\r\n"),
+	}, {
+		name:   "v2 TCP over IPv4",
+		header: proxyHeaderV2(0x1, 0x11, ipv4),
+		want:   "192.0.2.1:56324",
+	}, {
+		name:   "v2 TCP over IPv6",
+		header: proxyHeaderV2(0x1, 0x21, ipv6),
+		want:   "[2001:db8::1]:56324",
+	}, {
+		name:   "v2 with TLVs",
+		header: proxyHeaderV2(0x1, 0x11, tlvs),
+		want:   "192.0.2.1:56324",
+	}, {
+		name:   "v2 LOCAL",
+		header: proxyHeaderV2(0x0, 0x00, nil),
+	}, {
+		name:   "v2 UDP",
+		header: proxyHeaderV2(0x1, 0x12, ipv4),
+	}, {
+		name:    "no header",
+		header:  []byte("GET / HTTP/1.1\r\n"),
+		wantErr: "missing PROXY protocol header",
+	}, {
+		name:    "v1 without CRLF",
+		header:  []byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443" + strings.Repeat(" ", 100)),
+		wantErr: "doesn't end with CRLF",
+	}, {
+		name:    "v1 invalid address",
+		header:  []byte("PROXY TCP4 192.0.2 198.51.100.1 56324 443\r\n"),
+		wantErr: "invalid source address",
+	}, {
+		name:    "v1 invalid port",
+		header:  []byte("PROXY TCP4 192.0.2.1 198.51.100.1 65536 443\r\n"),
+		wantErr: "invalid source port",
+	}, {
+		name:    "v1 unsupported protocol",
+		header:  []byte("PROXY UDP4 192.0.2.1 198.51.100.1 56324 443\r\n"),
+		wantErr: "invalid v1 header",
+	}, {
+		name:    "v2 truncated addresses",
+		header:  proxyHeaderV2(0x1, 0x21, ipv4),
+		wantErr: "truncated",
+	}, {
+		name:    "v2 unsupported command",
+		header:  proxyHeaderV2(0x2, 0x11, ipv4),
+		wantErr: "unsupported command",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := bufio.NewReader(io.MultiReader(bytes.NewReader(tc.header), strings.NewReader("GET /")))
+			addr, err := readProxyHeader(r)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("readProxyHeader() returned error %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readProxyHeader() returned error: %v", err)
+			}
+			got := ""
+			if addr != nil {
+				got = addr.String()
+			}
+			if got != tc.want {
+				t.Errorf("readProxyHeader() = %q, want %q", got, tc.want)
+			}
+			// The data after the header is left to be read.
+			if rest, _ := io.ReadAll(r); string(rest) != "GET /" {
+				t.Errorf("data after the header = %q, want %q", rest, "GET /")
+			}
+		})
+	}
+}
+
+func TestProxyProtocolListener(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		upstreams string
+		header    string
+		want      string
+	}{{
+		name:      "upstream",
+		upstreams: "127.0.0.0/8",
+		header:    "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n",
+		want:      "192.0.2.1:56324",
+	}, {
+		name:      "health check of the upstream",
+		upstreams: "127.0.0.0/8",
+		header:    "PROXY UNKNOWN\r\n",
+		want:      "127.0.0.1:",
+	}, {
+		name:      "other peer",
+		upstreams: "10.0.0.0/8",
+		want:      "127.0.0.1:",
+	}, {
+		name:      "upstream without header",
+		upstreams: "127.0.0.0/8",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			upstreams, err := triggers.ParseTrustedProxies(tc.upstreams)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv := &http.Server{
+				ReadHeaderTimeout: 5 * time.Second,
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, r.RemoteAddr)
+				}),
+			}
+			go srv.Serve(&ProxyProtocolListener{Listener: ln, Upstreams: upstreams, HeaderTimeout: 5 * time.Second, Logger: zaptest.NewLogger(t).Sugar()})
+			defer srv.Close()
+
+			conn, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			if _, err := fmt.Fprintf(conn, "%sGET / HTTP/1.1\r\nHost: el\r\nConnection: close\r\n\r\n", tc.header); err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+			if tc.want == "" {
+				// The server fails to read the request, and responds without calling the handler.
+				if err != nil || resp.StatusCode != http.StatusBadRequest {
+					t.Fatalf("got response %v, %v, want a %d response", resp, err, http.StatusBadRequest)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to read the response: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if !strings.HasPrefix(string(body), tc.want) {
+				t.Errorf("remote address of the request = %q, want %q", body, tc.want)
+			}
+		})
+	}
+}
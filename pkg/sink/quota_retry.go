@@ -0,0 +1,268 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
+)
+
+// ErrQuotaRetryDropped is returned when a create that exceeded a ResourceQuota is not retried because
+// the retry queue is full or shut down, or is still rejected at the end of the retry window.
+var ErrQuotaRetryDropped = errors.New("resource quota exceeded, create dropped")
+
+const (
+	// defaultQuotaRetryMaxQueued is the number of queued creates used if no MaxQueued is configured.
+	defaultQuotaRetryMaxQueued = 100
+	// quotaRetryInitialBackoff is the time before the first retry of a create, doubled after each retry.
+	quotaRetryInitialBackoff = time.Second
+	// quotaRetryMaxBackoff bounds the time between two retries of a create.
+	quotaRetryMaxBackoff = 30 * time.Second
+)
+
+const (
+	// quotaRetryQueueFull is the reason of the creates dropped because the queue was full.
+	quotaRetryQueueFull = "queue_full"
+	// quotaRetryWindowExceeded is the reason of the creates dropped because they were still rejected at
+	// the end of the retry window.
+	quotaRetryWindowExceeded = "window_exceeded"
+	// quotaRetryShutdown is the reason of the creates dropped because the queue shut down before they
+	// were due.
+	quotaRetryShutdown = "shutdown"
+)
+
+// QuotaRetry retries the creates that the API server rejected because they exceeded a ResourceQuota,
+// until they succeed, fail for another reason, or the window ends. This smooths over transient quota
+// pressure, e.g. while the runs counted in a quota complete. The creates are keys in a rate limited work
+// queue, with an exponential backoff per create, retried one at a time by the worker of Run in the order
+// they are due, while their callers wait for the outcome. At most MaxQueued creates are queued at a
+// time; the others fail without being retried.
+//
+// A nil *QuotaRetry never retries creates.
+type QuotaRetry struct {
+	// Window is how long a create is retried for.
+	Window time.Duration
+	// MaxQueued is the number of creates that can be retried at a time.
+	MaxQueued int
+	// Store, if set, persists the queued creates, so that the creates still queued when the
+	// EventListener stops are replayed by ReplayQuotaRetries when it starts again.
+	Store QuotaRetryStore
+
+	once    sync.Once
+	queue   workqueue.RateLimitingInterface
+	limiter workqueue.RateLimiter
+
+	mu      sync.Mutex
+	creates map[string]*queuedRetry
+	lastKey uint64
+	stopped bool
+
+	clock   clock.WithTicker
+	delayed workqueue.DelayingInterface
+}
+
+// queuedRetry is a create in the queue, under its key.
+type queuedRetry struct {
+	create   func() (*unstructured.Unstructured, error)
+	deadline time.Time
+	// err is the error of the last call to create.
+	err  error
+	done chan quotaRetryResult
+}
+
+// quotaRetryResult is the outcome of a queued create: the result of its last call, along with the reason
+// it was dropped if it was still rejected because of a quota.
+type quotaRetryResult struct {
+	created *unstructured.Unstructured
+	dropped string
+	err     error
+}
+
+// isQuotaExceeded returns whether the API server rejected a create because it exceeded a ResourceQuota.
+func isQuotaExceeded(err error) bool {
+	return apierrors.IsForbidden(err) && strings.Contains(err.Error(), "exceeded quota")
+}
+
+func (q *QuotaRetry) maxQueued() int {
+	if q.MaxQueued <= 0 {
+		return defaultQuotaRetryMaxQueued
+	}
+	return q.MaxQueued
+}
+
+func (q *QuotaRetry) currentTime() time.Time {
+	if q.clock == nil {
+		return time.Now()
+	}
+	return q.clock.Now()
+}
+
+// start creates the queue.
+func (q *QuotaRetry) start() {
+	q.once.Do(func() {
+		if q.clock == nil {
+			q.clock = clock.RealClock{}
+		}
+		if q.delayed == nil {
+			q.delayed = workqueue.NewDelayingQueueWithCustomClock(q.clock, "")
+		}
+		q.limiter = workqueue.NewItemExponentialFailureRateLimiter(quotaRetryInitialBackoff, quotaRetryMaxBackoff)
+		q.queue = workqueue.NewRateLimitingQueueWithDelayingInterface(q.delayed, q.limiter)
+		q.creates = map[string]*queuedRetry{}
+	})
+}
+
+// Run retries the queued creates until ctx is done. It then drains the queue: the creates that are due
+// are retried a last time, and the others are dropped, staying persisted to be replayed when the
+// EventListener starts again.
+func (q *QuotaRetry) Run(ctx context.Context) {
+	q.start()
+	go func() {
+		<-ctx.Done()
+		q.queue.ShutDownWithDrain()
+		// ShutDownWithDrain doesn't stop the delaying queue.
+		q.queue.ShutDown()
+	}()
+	for q.processNextItem() {
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.stopped = true
+	for key, c := range q.creates {
+		q.finishLocked(key, quotaRetryResult{dropped: quotaRetryShutdown, err: c.err})
+	}
+}
+
+// processNextItem retries the next create that is due, and queues it again with a backoff if it still
+// exceeds a quota before the end of its window. It returns false once the queue is shut down.
+func (q *QuotaRetry) processNextItem() bool {
+	item, shutdown := q.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer q.queue.Done(item)
+	key := item.(string)
+	q.mu.Lock()
+	c, ok := q.creates[key]
+	q.mu.Unlock()
+	if !ok {
+		q.queue.Forget(key)
+		return true
+	}
+
+	created, err := c.create()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	c.err = err
+	switch {
+	case !isQuotaExceeded(err):
+		q.finishLocked(key, quotaRetryResult{created: created, err: err})
+	case !c.deadline.After(q.currentTime()):
+		q.finishLocked(key, quotaRetryResult{dropped: quotaRetryWindowExceeded, err: err})
+	default:
+		q.requeueLocked(key, c.deadline)
+	}
+	return true
+}
+
+// requeueLocked adds the create with the key to the queue after its backoff, or at the deadline if it
+// comes first. The backoff is taken from the rate limiter rather than with AddRateLimited, so that it
+// is bounded by the deadline.
+func (q *QuotaRetry) requeueLocked(key string, deadline time.Time) {
+	backoff := q.limiter.When(key)
+	if remaining := deadline.Sub(q.currentTime()); backoff > remaining {
+		backoff = remaining
+	}
+	q.queue.AddAfter(key, backoff)
+}
+
+// finishLocked removes the create with the key from the queue and hands its outcome to its caller.
+func (q *QuotaRetry) finishLocked(key string, result quotaRetryResult) {
+	c := q.creates[key]
+	delete(q.creates, key)
+	q.queue.Forget(key)
+	c.done <- result
+}
+
+// enqueue adds the create to the queue, to be retried until the deadline once it is scheduled. It
+// returns its key and the channel of its outcome, or the reason it was dropped without being queued.
+func (q *QuotaRetry) enqueue(deadline time.Time, create func() (*unstructured.Unstructured, error), err error) (string, <-chan quotaRetryResult, string) {
+	q.start()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	switch {
+	case q.stopped:
+		return "", nil, quotaRetryShutdown
+	case len(q.creates) >= q.maxQueued():
+		return "", nil, quotaRetryQueueFull
+	case !deadline.After(q.currentTime()):
+		return "", nil, quotaRetryWindowExceeded
+	}
+	q.lastKey++
+	key := strconv.FormatUint(q.lastKey, 10)
+	c := &queuedRetry{create: create, deadline: deadline, err: err, done: make(chan quotaRetryResult, 1)}
+	q.creates[key] = c
+	return key, c.done, ""
+}
+
+// schedule adds the queued create with the key to the queue after its first backoff, unless it was
+// dropped in the meantime.
+func (q *QuotaRetry) schedule(key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if c, ok := q.creates[key]; ok {
+		q.requeueLocked(key, c.deadline)
+	}
+}
+
+// retry queues a create that failed because of a quota to be called again until the window ends. err
+// is the error of the first call, and queued is called once the create is queued. It returns the
+// result of the last call, along with the reason the create was dropped if it was still rejected
+// because of a quota.
+func (q *QuotaRetry) retry(err error, create func() (*unstructured.Unstructured, error), queued func()) (*unstructured.Unstructured, string, error) {
+	return q.retryUntil(err, time.Time{}, create, queued)
+}
+
+// retryUntil retries the create like retry, until the deadline if it is not zero, e.g. the end of the
+// window of a replayed create, instead of the end of a window starting now.
+func (q *QuotaRetry) retryUntil(err error, deadline time.Time, create func() (*unstructured.Unstructured, error), queued func()) (*unstructured.Unstructured, string, error) {
+	if q == nil || !isQuotaExceeded(err) {
+		return nil, "", err
+	}
+	if deadline.IsZero() {
+		deadline = q.currentTime().Add(q.Window)
+	}
+	key, done, dropped := q.enqueue(deadline, create, err)
+	if dropped != "" {
+		return nil, dropped, err
+	}
+	// The create is only retried once queued returns, e.g. once it is persisted.
+	queued()
+	q.schedule(key)
+	result := <-done
+	return result.created, result.dropped, result.err
+}
@@ -0,0 +1,523 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tektoncd/triggers/pkg/resources"
+	"go.uber.org/zap"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	coordinationclientv1 "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// QuotaRetryQueueSuffix is the suffix of the name of the EventListener in the names of the Secrets and
+	// Leases, in its namespace, holding the persisted quota retry queues of its replicas.
+	QuotaRetryQueueSuffix = "-quota-retry-queue"
+	// QuotaRetryQueueLabel is the label, set to the name of the EventListener, of the Secrets and Leases
+	// holding the persisted quota retry queues of its replicas.
+	QuotaRetryQueueLabel = "triggers.tekton.dev/quota-retry-queue"
+
+	// maxQuotaRetryStoreSize bounds the size of the persisted creates of a SecretQuotaRetryStore, well
+	// below the 1MiB limit of Secrets.
+	maxQuotaRetryStoreSize = 768 * 1024
+	// quotaRetryStoreTimeout bounds the calls to the quota retry store.
+	quotaRetryStoreTimeout = 10 * time.Second
+	// quotaRetryLeaseDuration is how long after its last renewal the Lease of the quota retry queue of a
+	// replica expires, and its creates can be claimed by the other replicas.
+	quotaRetryLeaseDuration = time.Minute
+)
+
+// ErrQuotaRetryStoreFull is returned when a create is not persisted because the store is full. The
+// create is still retried, but isn't replayed if the EventListener stops.
+var ErrQuotaRetryStoreFull = errors.New("quota retry store is full")
+
+// QueuedCreate is a create queued to be retried because it exceeded a quota, as persisted to be replayed.
+type QueuedCreate struct {
+	// Key identifies the create: the event ID, the trigger and the index of the resource template.
+	Key string `json:"key"`
+	// EventID is the ID of the event of the create.
+	EventID string `json:"eventID"`
+	// Trigger is the name of the trigger of the create.
+	Trigger string `json:"trigger"`
+	// TriggerNamespace is the namespace of the trigger.
+	TriggerNamespace string `json:"triggerNamespace"`
+	// Namespace is the namespace of the resource if its template doesn't specify one.
+	Namespace string `json:"namespace"`
+	// ServiceAccountName is the service account the resource is created with, if any.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// ClientProfile is the client profile the resource is created with, if any.
+	ClientProfile string `json:"clientProfile,omitempty"`
+	// Finalizer, Labels, Annotations, DefaultAPIVersion and DefaultKind are the metadata the trigger
+	// adds to the resource.
+	Finalizer         string            `json:"finalizer,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Annotations       map[string]string `json:"annotations,omitempty"`
+	DefaultAPIVersion string            `json:"defaultAPIVersion,omitempty"`
+	DefaultKind       string            `json:"defaultKind,omitempty"`
+	// TTL and TTLAnnotation are the time to live the trigger annotates the resource with, if positive.
+	TTL           time.Duration `json:"ttl,omitempty"`
+	TTLAnnotation string        `json:"ttlAnnotation,omitempty"`
+	// Template is the resource template, with the params of the event replaced.
+	Template json.RawMessage `json:"template"`
+	// QueuedAt is when the create was queued.
+	QueuedAt metav1.Time `json:"queuedAt"`
+	// Deadline is the end of the retry window of the create.
+	Deadline metav1.Time `json:"deadline"`
+
+	// persisted is whether the create is in the store.
+	persisted bool
+	// replayed is whether the create was read from the store when the EventListener started.
+	replayed bool
+	// interrupted is whether the queue shut down while the create was queued, so that it stays in the
+	// store to be replayed.
+	interrupted bool
+}
+
+// newQueuedCreate returns the create of the resource template rr of the trigger to persist if it is queued.
+func newQueuedCreate(rr json.RawMessage, index int, triggerNS, defaultNS, sa string, meta resourceMetadata, triggerName, eventID string) *QueuedCreate {
+	return &QueuedCreate{
+		Key:                fmt.Sprintf("%s/%s/%d", eventID, triggerName, index),
+		EventID:            eventID,
+		Trigger:            triggerName,
+		TriggerNamespace:   triggerNS,
+		Namespace:          defaultNS,
+		ServiceAccountName: sa,
+		Finalizer:          meta.finalizer,
+		Labels:             meta.labels,
+		Annotations:        meta.annotations,
+		DefaultAPIVersion:  meta.defaultType.APIVersion,
+		DefaultKind:        meta.defaultType.Kind,
+		TTL:                meta.ttl,
+		TTLAnnotation:      meta.ttlAnnotation,
+		ClientProfile:      meta.clientProfile,
+		Template:           rr,
+	}
+}
+
+// metadata returns the metadata the trigger adds to the resource.
+func (c *QueuedCreate) metadata() resourceMetadata {
+	return resourceMetadata{
+		finalizer:     c.Finalizer,
+		labels:        c.Labels,
+		annotations:   c.Annotations,
+		ttl:           c.TTL,
+		ttlAnnotation: c.TTLAnnotation,
+		defaultType:   metav1.TypeMeta{APIVersion: c.DefaultAPIVersion, Kind: c.DefaultKind},
+		clientProfile: c.ClientProfile,
+	}
+}
+
+// QuotaRetryStore persists the creates queued by a QuotaRetry.
+type QuotaRetryStore interface {
+	// Add persists the create, or returns ErrQuotaRetryStoreFull if there is no room for it.
+	Add(ctx context.Context, c *QueuedCreate) error
+	// Remove removes the create with the key, if it was persisted.
+	Remove(ctx context.Context, key string) error
+	// List returns the persisted creates.
+	List(ctx context.Context) ([]*QueuedCreate, error)
+}
+
+// persistent returns whether the queued creates are persisted.
+func (q *QuotaRetry) persistent() bool {
+	return q != nil && q.Store != nil
+}
+
+// persist adds the create to the store, with its retry window starting now, unless it already is.
+func (q *QuotaRetry) persist(c *QueuedCreate, log *zap.SugaredLogger) {
+	if c.persisted {
+		return
+	}
+	now := q.currentTime()
+	c.QueuedAt = metav1.NewTime(now)
+	c.Deadline = metav1.NewTime(now.Add(q.Window))
+	ctx, cancel := context.WithTimeout(context.Background(), quotaRetryStoreTimeout)
+	defer cancel()
+	if err := q.Store.Add(ctx, c); err != nil {
+		log.Warnf("failed to persist create %s that exceeded a quota, it won't be replayed if the EventListener stops: %v", c.Key, err)
+		return
+	}
+	c.persisted = true
+}
+
+// forget removes the create from the store once it is no longer queued, if it was persisted and not
+// interrupted.
+func (q *QuotaRetry) forget(c *QueuedCreate, log *zap.SugaredLogger) {
+	if !c.persisted || c.interrupted {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), quotaRetryStoreTimeout)
+	defer cancel()
+	if err := q.Store.Remove(ctx, c.Key); err != nil {
+		log.Warnf("failed to remove create %s from the quota retry store, it will be replayed if the EventListener restarts: %v", c.Key, err)
+		return
+	}
+	c.persisted = false
+}
+
+// ReplayQuotaRetries replays the creates queued to be retried because they exceeded a quota that were
+// persisted when the replica, or another replica of the EventListener, last stopped. They are started in the order they were queued, and
+// retried until the end of their original window, or tried once if it ended while the EventListener was
+// stopped. The resources are named after the keys of the creates, so that a create that succeeded before
+// the EventListener stopped returns the existing resource rather than creating it again.
+func (r Sink) ReplayQuotaRetries() {
+	if !r.QuotaRetry.persistent() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), quotaRetryStoreTimeout)
+	defer cancel()
+	queued, err := r.QuotaRetry.Store.List(ctx)
+	if err != nil {
+		r.Logger.Errorf("failed to list the persisted creates of the quota retry queue: %v", err)
+		return
+	}
+	sortQueuedCreates(queued)
+	if len(queued) > 0 {
+		r.Logger.Infof("replaying %d creates of the quota retry queue", len(queued))
+	}
+	for _, c := range queued {
+		c.persisted, c.replayed = true, true
+		r.WGProcessTriggers.Add(1)
+		go func(c *QueuedCreate) {
+			defer r.WGProcessTriggers.Done()
+			r.replayCreate(c)
+		}(c)
+	}
+}
+
+// sortQueuedCreates sorts the creates in the order they were queued, and by key if they were queued at the
+// same time, e.g. the templates of a trigger.
+func sortQueuedCreates(queued []*QueuedCreate) {
+	sort.Slice(queued, func(i, j int) bool {
+		if !queued[i].QueuedAt.Equal(&queued[j].QueuedAt) {
+			return queued[i].QueuedAt.Before(&queued[j].QueuedAt)
+		}
+		return queued[i].Key < queued[j].Key
+	})
+}
+
+// replayCreate creates the resource of a persisted create with the credentials of its trigger.
+func (r Sink) replayCreate(c *QueuedCreate) {
+	keys := r.logKeys()
+	log := r.Logger.With(
+		zap.String(keys.eventListener, r.EventListenerName),
+		zap.String(keys.namespace, r.EventListenerNamespace),
+		zap.String(keys.eventID, c.EventID),
+		zap.String(keys.trigger, c.Trigger),
+	)
+	discoveryClient, dynamicClient, err := r.resourceClients(context.Background(), c.ClientProfile, c.ServiceAccountName, c.TriggerNamespace, log)
+	if err != nil {
+		log.Errorf("problem cloning rest config to replay create %s: %#v", c.Key, err)
+		r.QuotaRetry.forget(c, log)
+		return
+	}
+	creator := r.Creator
+	if creator == nil {
+		creator = resources.DefaultCreator
+	}
+	meta := c.metadata()
+	if r.EventListenerLister != nil {
+		// The replayed create is held to the current resource kinds and create strategies of the
+		// EventListener, which may have changed since it was queued.
+		el, err := r.EventListenerLister.EventListeners(r.EventListenerNamespace).Get(r.EventListenerName)
+		if err != nil {
+			log.Errorf("problem getting the EventListener to replay create %s: %v", c.Key, err)
+			r.QuotaRetry.forget(c, log)
+			return
+		}
+		meta.kindPolicy = el.Spec.ResourceKinds
+		meta.createStrategies = el.Spec.CreateStrategies
+	}
+	log.Infof("replaying create %s queued at %s", c.Key, c.QueuedAt.Format(time.RFC3339))
+	if _, err := r.createResource(creator, c.Template, c.Trigger, c.EventID, c.Namespace, meta, c, discoveryClient, dynamicClient, log); err != nil {
+		log.Errorf("failed to replay create %s: %v", c.Key, err)
+	}
+}
+
+// SecretQuotaRetryStore persists the queued creates of a replica of the EventListener in a Secret of its
+// own, one per data key, since the creates hold the params of events. The replica holds a Lease of the
+// same name while it runs, and the other replicas only claim its creates once the Lease expired, i.e. once
+// it stopped, so that a create is replayed by a single replica and never removed while it is queued.
+type SecretQuotaRetryStore struct {
+	// Secrets is the client of the Secrets of the namespace of the EventListener.
+	Secrets corev1client.SecretInterface
+	// Leases is the client of the Leases of the namespace of the EventListener.
+	Leases coordinationclientv1.LeaseInterface
+	// EventListener is the name of the EventListener.
+	EventListener string
+	// Replica identifies the replica, e.g. the name of its pod.
+	Replica string
+
+	now func() time.Time
+}
+
+// NewSecretQuotaRetryStore returns the SecretQuotaRetryStore of the replica of the EventListener.
+func NewSecretQuotaRetryStore(kubeClient kubernetes.Interface, namespace, eventListener, replica string) *SecretQuotaRetryStore {
+	return &SecretQuotaRetryStore{
+		Secrets:       kubeClient.CoreV1().Secrets(namespace),
+		Leases:        kubeClient.CoordinationV1().Leases(namespace),
+		EventListener: eventListener,
+		Replica:       replica,
+		now:           time.Now,
+	}
+}
+
+// name returns the name of the Secret and Lease of the replica.
+func (s *SecretQuotaRetryStore) name() string {
+	return s.EventListener + QuotaRetryQueueSuffix + "-" + s.Replica
+}
+
+// storeKey returns the data key of the create with the key, which may not be a valid Secret key.
+func storeKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
+
+// leaseSpec returns the spec of a Lease held by the replica from now on.
+func (s *SecretQuotaRetryStore) leaseSpec() coordinationv1.LeaseSpec {
+	now := metav1.NewMicroTime(s.now())
+	seconds := int32(quotaRetryLeaseDuration / time.Second)
+	return coordinationv1.LeaseSpec{
+		HolderIdentity:       &s.Replica,
+		LeaseDurationSeconds: &seconds,
+		AcquireTime:          &now,
+		RenewTime:            &now,
+	}
+}
+
+// leaseExpired returns whether the holder of the lease stopped renewing it.
+func leaseExpired(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	return !now.Before(expiry)
+}
+
+// acquire creates or takes over the Lease with the name for the replica, or renews it if the replica holds
+// it. It returns false if another replica holds it. The update is made at the resourceVersion that was
+// read, so only one of several replicas taking over an expired Lease succeeds.
+func (s *SecretQuotaRetryStore) acquire(ctx context.Context, name string) (bool, error) {
+	lease, err := s.Leases.Get(ctx, name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		_, err = s.Leases.Create(ctx, &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{QuotaRetryQueueLabel: s.EventListener},
+			},
+			Spec: s.leaseSpec(),
+		}, metav1.CreateOptions{})
+		if kerrors.IsAlreadyExists(err) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	if err != nil {
+		return false, err
+	}
+	held := lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity == s.Replica
+	if !held && !leaseExpired(lease, s.now()) {
+		return false, nil
+	}
+	spec := s.leaseSpec()
+	if held && lease.Spec.AcquireTime != nil {
+		spec.AcquireTime = lease.Spec.AcquireTime
+	}
+	lease.Spec = spec
+	if _, err := s.Leases.Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		if kerrors.IsConflict(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// renew renews the Lease of the replica.
+func (s *SecretQuotaRetryStore) renew(ctx context.Context) error {
+	held, err := s.acquire(ctx, s.name())
+	if err != nil {
+		return fmt.Errorf("failed to renew lease %s: %w", s.name(), err)
+	}
+	if !held {
+		return fmt.Errorf("lease %s is held by another replica", s.name())
+	}
+	return nil
+}
+
+// Hold renews the Lease of the replica until ctx is done, so that the other replicas don't claim its
+// creates while it runs.
+func (s *SecretQuotaRetryStore) Hold(ctx context.Context, log *zap.SugaredLogger) {
+	ticker := time.NewTicker(quotaRetryLeaseDuration / 3)
+	defer ticker.Stop()
+	for {
+		if err := s.renew(ctx); err != nil {
+			log.Warnf("failed to hold the quota retry queue of the replica, other replicas may replay its creates: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Add implements QuotaRetryStore.
+func (s *SecretQuotaRetryStore) Add(ctx context.Context, c *QueuedCreate) error {
+	value, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, err := s.Secrets.Get(ctx, s.name(), metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			if len(value) > maxQuotaRetryStoreSize {
+				return ErrQuotaRetryStoreFull
+			}
+			// The Lease is held before the Secret exists, so that no other replica claims it.
+			if err := s.renew(ctx); err != nil {
+				return err
+			}
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   s.name(),
+					Labels: map[string]string{QuotaRetryQueueLabel: s.EventListener},
+				},
+				Data: map[string][]byte{storeKey(c.Key): value},
+			}
+			_, err = s.Secrets.Create(ctx, secret, metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		size := len(value)
+		for _, v := range secret.Data {
+			size += len(v)
+		}
+		if size > maxQuotaRetryStoreSize {
+			return ErrQuotaRetryStoreFull
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[storeKey(c.Key)] = value
+		_, err = s.Secrets.Update(ctx, secret, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// Remove implements QuotaRetryStore.
+func (s *SecretQuotaRetryStore) Remove(ctx context.Context, key string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, err := s.Secrets.Get(ctx, s.name(), metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, ok := secret.Data[storeKey(key)]; !ok {
+			return nil
+		}
+		delete(secret.Data, storeKey(key))
+		_, err = s.Secrets.Update(ctx, secret, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// List implements QuotaRetryStore. It returns the creates of the replica, persisted before it restarted,
+// and claims those of the replicas of the EventListener that stopped: they are moved to the Secret of the
+// replica, and the Secrets and Leases of the stopped replicas are deleted. Entries that can't be parsed are
+// skipped.
+func (s *SecretQuotaRetryStore) List(ctx context.Context) ([]*QueuedCreate, error) {
+	secrets, err := s.Secrets.List(ctx, metav1.ListOptions{LabelSelector: QuotaRetryQueueLabel + "=" + s.EventListener})
+	if err != nil {
+		return nil, err
+	}
+	var queued []*QueuedCreate
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if secret.Name == s.name() {
+			queued = append(queued, queuedCreates(secret)...)
+			continue
+		}
+		claimed, err := s.claim(ctx, secret.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim the quota retry queue %s: %w", secret.Name, err)
+		}
+		queued = append(queued, claimed...)
+	}
+	return queued, nil
+}
+
+// claim claims the creates persisted in the Secret with the name if the replica that persisted them
+// stopped, and moves them to the Secret of the replica. A create that can't be moved is still returned.
+func (s *SecretQuotaRetryStore) claim(ctx context.Context, name string) ([]*QueuedCreate, error) {
+	acquired, err := s.acquire(ctx, name)
+	if err != nil || !acquired {
+		return nil, err
+	}
+	// The Secret is read again, as its replica may have updated it before it stopped.
+	secret, err := s.Secrets.Get(ctx, name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, s.Leases.Delete(ctx, name, metav1.DeleteOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+	claimed := queuedCreates(secret)
+	for _, c := range claimed {
+		if err := s.Add(ctx, c); err != nil {
+			break
+		}
+	}
+	if err := s.Secrets.Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !kerrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err := s.Leases.Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !kerrors.IsNotFound(err) {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// queuedCreates returns the creates persisted in the secret.
+func queuedCreates(secret *corev1.Secret) []*QueuedCreate {
+	queued := make([]*QueuedCreate, 0, len(secret.Data))
+	for _, v := range secret.Data {
+		c := &QueuedCreate{}
+		if err := json.Unmarshal(v, c); err != nil || c.Key == "" {
+			continue
+		}
+		queued = append(queued, c)
+	}
+	return queued
+}
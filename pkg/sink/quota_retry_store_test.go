@@ -0,0 +1,325 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/triggers/pkg/resources"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	discoveryclient "k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestQuotaRetryStore() *SecretQuotaRetryStore {
+	return NewSecretQuotaRetryStore(fake.NewSimpleClientset(), namespace, "test-el", "test-el-replica-1")
+}
+
+func listKeys(t *testing.T, store QuotaRetryStore) []string {
+	t.Helper()
+	queued, err := store.List(context.Background())
+	if err != nil {
+		t.Fatalf("List() returned error: %v", err)
+	}
+	sortQueuedCreates(queued)
+	keys := []string{}
+	for _, c := range queued {
+		keys = append(keys, c.Key)
+	}
+	return keys
+}
+
+func TestSecretQuotaRetryStore(t *testing.T) {
+	ctx := context.Background()
+	store := newTestQuotaRetryStore()
+	if got := listKeys(t, store); len(got) != 0 {
+		t.Errorf("List() of a missing Secret = %v, want none", got)
+	}
+
+	queuedAt := metav1.NewTime(time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC))
+	for _, c := range []*QueuedCreate{
+		{Key: "event-2/my-trigger/0", QueuedAt: metav1.NewTime(queuedAt.Add(time.Second)), Template: json.RawMessage(`{}`)},
+		{Key: "event-1/my-trigger/1", QueuedAt: queuedAt, Template: json.RawMessage(`{}`)},
+		{Key: "event-1/my-trigger/0", QueuedAt: queuedAt, Template: json.RawMessage(`{}`)},
+	} {
+		if err := store.Add(ctx, c); err != nil {
+			t.Fatalf("Add(%s) returned error: %v", c.Key, err)
+		}
+	}
+	want := []string{"event-1/my-trigger/0", "event-1/my-trigger/1", "event-2/my-trigger/0"}
+	if diff := cmp.Diff(want, listKeys(t, store)); diff != "" {
+		t.Errorf("List() -want +got: %s", diff)
+	}
+
+	if err := store.Remove(ctx, "event-1/my-trigger/0"); err != nil {
+		t.Fatalf("Remove() returned error: %v", err)
+	}
+	if err := store.Remove(ctx, "missing"); err != nil {
+		t.Fatalf("Remove() of a missing key returned error: %v", err)
+	}
+	want = []string{"event-1/my-trigger/1", "event-2/my-trigger/0"}
+	if diff := cmp.Diff(want, listKeys(t, store)); diff != "" {
+		t.Errorf("List() after Remove() -want +got: %s", diff)
+	}
+
+	// The store doesn't grow past its bound.
+	large := &QueuedCreate{Key: "event-3/my-trigger/0", Template: json.RawMessage(`"` + strings.Repeat("x", maxQuotaRetryStoreSize) + `"`)}
+	if err := store.Add(ctx, large); !errors.Is(err, ErrQuotaRetryStoreFull) {
+		t.Errorf("Add() of a create larger than the store = %v, want %v", err, ErrQuotaRetryStoreFull)
+	}
+
+	// The replica holds the Lease of its Secret.
+	lease, err := store.Leases.Get(ctx, store.name(), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get the lease of the store: %v", err)
+	}
+	if holder := *lease.Spec.HolderIdentity; holder != store.Replica {
+		t.Errorf("lease of the store is held by %s, want %s", holder, store.Replica)
+	}
+}
+
+func TestSecretQuotaRetryStore_Claim(t *testing.T) {
+	ctx := context.Background()
+	kubeClient := fake.NewSimpleClientset()
+	clock := &fakeClock{t: time.Now()}
+	newStore := func(replica string) *SecretQuotaRetryStore {
+		store := NewSecretQuotaRetryStore(kubeClient, namespace, "test-el", replica)
+		store.now = clock.now
+		return store
+	}
+	running, stopped, starting := newStore("replica-1"), newStore("replica-2"), newStore("replica-3")
+	other := NewSecretQuotaRetryStore(kubeClient, namespace, "other-el", "replica-4")
+	for store, key := range map[*SecretQuotaRetryStore]string{
+		running:  "event-1/my-trigger/0",
+		stopped:  "event-2/my-trigger/0",
+		starting: "event-3/my-trigger/0",
+		other:    "event-4/my-trigger/0",
+	} {
+		if err := store.Add(ctx, &QueuedCreate{Key: key, Template: json.RawMessage(`{}`)}); err != nil {
+			t.Fatalf("Add(%s) returned error: %v", key, err)
+		}
+	}
+
+	// The starting replica restarted and replays its own creates, while those of the other replicas are
+	// claimed once they stopped renewing their Leases.
+	clock.sleep(quotaRetryLeaseDuration / 2)
+	if err := running.renew(ctx); err != nil {
+		t.Fatalf("renew() returned error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"event-3/my-trigger/0"}, listKeys(t, starting)); diff != "" {
+		t.Errorf("List() with live replicas -want +got: %s", diff)
+	}
+	clock.sleep(quotaRetryLeaseDuration / 2)
+	want := []string{"event-2/my-trigger/0", "event-3/my-trigger/0"}
+	if diff := cmp.Diff(want, listKeys(t, starting)); diff != "" {
+		t.Errorf("List() with a stopped replica -want +got: %s", diff)
+	}
+
+	// The claimed creates are moved to the Secret of the replica.
+	if _, err := kubeClient.CoreV1().Secrets(namespace).Get(ctx, stopped.name(), metav1.GetOptions{}); !kerrors.IsNotFound(err) {
+		t.Errorf("Secret of the stopped replica = %v, want it deleted", err)
+	}
+	if _, err := kubeClient.CoordinationV1().Leases(namespace).Get(ctx, stopped.name(), metav1.GetOptions{}); !kerrors.IsNotFound(err) {
+		t.Errorf("Lease of the stopped replica = %v, want it deleted", err)
+	}
+	if diff := cmp.Diff(want, listKeys(t, starting)); diff != "" {
+		t.Errorf("List() after the claim -want +got: %s", diff)
+	}
+	if err := starting.Remove(ctx, "event-2/my-trigger/0"); err != nil {
+		t.Fatalf("Remove() returned error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"event-3/my-trigger/0"}, listKeys(t, starting)); diff != "" {
+		t.Errorf("List() after removing a claimed create -want +got: %s", diff)
+	}
+	// The running replica doesn't claim the creates of the replica that started, which holds its Lease.
+	if err := starting.renew(ctx); err != nil {
+		t.Fatalf("renew() returned error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"event-1/my-trigger/0"}, listKeys(t, running)); diff != "" {
+		t.Errorf("List() of the running replica -want +got: %s", diff)
+	}
+}
+
+// quotaLimitedCreator fails with errQuotaExceeded the given number of times, then creates the resources
+// with the FakeCreator.
+type quotaLimitedCreator struct {
+	resources.FakeCreator
+	mu       sync.Mutex
+	failures int
+	// onFailure, if set, is called after each failure.
+	onFailure func()
+}
+
+func (c *quotaLimitedCreator) Create(ctx context.Context, log *zap.SugaredLogger, rt json.RawMessage, triggerName, eventID, elName, elNamespace string, dc discoveryclient.ServerResourcesInterface, dyn dynamic.Interface) (*unstructured.Unstructured, error) {
+	c.mu.Lock()
+	fail := c.failures > 0
+	c.failures--
+	c.mu.Unlock()
+	if fail {
+		if c.onFailure != nil {
+			c.onFailure()
+		}
+		return nil, errQuotaExceeded
+	}
+	return c.FakeCreator.Create(ctx, log, rt, triggerName, eventID, elName, elNamespace, dc, dyn)
+}
+
+func TestCreateResources_QuotaRetryPersisted(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	recorder, err := NewRecorder()
+	if err != nil {
+		t.Fatalf("NewRecorder() returned error: %v", err)
+	}
+	store := newTestQuotaRetryStore()
+	res := []json.RawMessage{
+		json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"generateName":"build-"}}`),
+	}
+	var persisted [][]string
+	creator := &quotaLimitedCreator{failures: 2}
+	creator.onFailure = func() {
+		persisted = append(persisted, listKeys(t, store))
+	}
+	q := &QuotaRetry{Window: time.Minute, Store: store}
+	retryInstantly(q)
+	runQuotaRetry(t, q)
+	r := Sink{
+		EventListenerName: "test-el",
+		Logger:            logger,
+		Recorder:          recorder,
+		Creator:           creator,
+		QuotaRetry:        q,
+	}
+	if err := r.CreateResources(namespace, "", "", res, "my-trigger", eventID, logger); err != nil {
+		t.Fatalf("CreateResources() returned error: %v", err)
+	}
+
+	// The create is persisted once it is queued, and removed once it succeeds.
+	want := [][]string{{}, {eventID + "/my-trigger/0"}}
+	if diff := cmp.Diff(want, persisted); diff != "" {
+		t.Errorf("persisted creates at each failure -want +got: %s", diff)
+	}
+	if got := listKeys(t, store); len(got) != 0 {
+		t.Errorf("persisted creates after the create succeeded = %v, want none", got)
+	}
+	created := creator.Created()
+	if len(created) != 1 || created[0].GetGenerateName() != "" || !strings.HasPrefix(created[0].GetName(), "build-") {
+		t.Errorf("created %v, want a single resource named after the key of the create", created)
+	}
+}
+
+func TestReplayQuotaRetries(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	recorder, err := NewRecorder()
+	if err != nil {
+		t.Fatalf("NewRecorder() returned error: %v", err)
+	}
+	ctx := context.Background()
+	now := time.Now()
+	template := json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"generateName":"build-"}}`)
+	replayed := func(key string, deadline time.Time) *QueuedCreate {
+		return &QueuedCreate{
+			Key:              key,
+			EventID:          strings.Split(key, "/")[0],
+			Trigger:          "my-trigger",
+			TriggerNamespace: namespace,
+			Namespace:        namespace,
+			Labels:           map[string]string{"team": "ci"},
+			Template:         template,
+			QueuedAt:         metav1.NewTime(now),
+			Deadline:         metav1.NewTime(deadline),
+		}
+	}
+
+	for _, tc := range []struct {
+		name        string
+		failures    int
+		deadline    time.Time
+		wantCreated int
+	}{{
+		name:        "within the window",
+		failures:    2,
+		deadline:    now.Add(time.Minute),
+		wantCreated: 1,
+	}, {
+		name:     "window ended while stopped",
+		failures: 1,
+		deadline: now.Add(-time.Minute),
+	}, {
+		name:        "window ended while stopped, tried once",
+		deadline:    now.Add(-time.Minute),
+		wantCreated: 1,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newTestQuotaRetryStore()
+			if err := store.Add(ctx, replayed(eventID+"/my-trigger/0", tc.deadline)); err != nil {
+				t.Fatalf("Add() returned error: %v", err)
+			}
+			creator := &quotaLimitedCreator{failures: tc.failures}
+			q := &QuotaRetry{Window: time.Minute, Store: store}
+			retryInstantly(q)
+			runQuotaRetry(t, q)
+			r := Sink{
+				EventListenerName:      "test-el",
+				EventListenerNamespace: namespace,
+				Logger:                 logger,
+				Recorder:               recorder,
+				Creator:                creator,
+				WGProcessTriggers:      &sync.WaitGroup{},
+				QuotaRetry:             q,
+			}
+			r.ReplayQuotaRetries()
+			r.WGProcessTriggers.Wait()
+
+			created := creator.Created()
+			if len(created) != tc.wantCreated {
+				t.Fatalf("replay created %d resources, want %d", len(created), tc.wantCreated)
+			}
+			if got := listKeys(t, store); len(got) != 0 {
+				t.Errorf("persisted creates after the replay = %v, want none", got)
+			}
+			if tc.wantCreated == 0 {
+				return
+			}
+			if got := created[0].GetLabels()["team"]; got != "ci" {
+				t.Errorf("replayed resource has team label %q, want the label of the trigger", got)
+			}
+
+			// Replaying the same create again creates a resource with the same name, which the API server
+			// rejects as a duplicate.
+			if err := store.Add(ctx, replayed(eventID+"/my-trigger/0", tc.deadline)); err != nil {
+				t.Fatalf("Add() returned error: %v", err)
+			}
+			r.ReplayQuotaRetries()
+			r.WGProcessTriggers.Wait()
+			if again := creator.Created(); len(again) != 2 || again[1].GetName() != created[0].GetName() {
+				t.Errorf("replaying the create again created %v, want a resource named %s", again, created[0].GetName())
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/triggers/pkg/resources"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryclient "k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/workqueue"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+var errQuotaExceeded = apierrors.NewForbidden(schema.GroupResource{Group: "tekton.dev", Resource: "pipelineruns"}, "first",
+	errors.New("exceeded quota: pipelineruns, requested: count/pipelineruns.tekton.dev=1, used: count/pipelineruns.tekton.dev=10, limited: count/pipelineruns.tekton.dev=10"))
+
+// fakeClock is a clock whose sleeps advance its time, recording their durations.
+type fakeClock struct {
+	t      time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func (c *fakeClock) sleep(d time.Duration) {
+	c.sleeps = append(c.sleeps, d)
+	c.t = c.t.Add(d)
+}
+
+// failingCreate returns a create that fails with the given errors before succeeding.
+func failingCreate(errs ...error) (func() (*unstructured.Unstructured, error), *int) {
+	calls := 0
+	return func() (*unstructured.Unstructured, error) {
+		calls++
+		if calls <= len(errs) {
+			return nil, errs[calls-1]
+		}
+		return &unstructured.Unstructured{}, nil
+	}, &calls
+}
+
+func TestIsQuotaExceeded(t *testing.T) {
+	if !isQuotaExceeded(errQuotaExceeded) {
+		t.Errorf("isQuotaExceeded(%v) = false, want true", errQuotaExceeded)
+	}
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "pipelineruns"}, "first", errors.New("cannot create resource"))
+	for _, err := range []error{nil, errors.New("exceeded quota"), forbidden} {
+		if isQuotaExceeded(err) {
+			t.Errorf("isQuotaExceeded(%v) = true, want false", err)
+		}
+	}
+}
+
+// instantQueue is a delaying queue that adds the creates right away, advancing its clock by their
+// delays, which it records.
+type instantQueue struct {
+	workqueue.Interface
+	clock  *clocktesting.FakeClock
+	mu     sync.Mutex
+	delays []time.Duration
+}
+
+func (q *instantQueue) AddAfter(item interface{}, d time.Duration) {
+	q.mu.Lock()
+	q.delays = append(q.delays, d)
+	q.mu.Unlock()
+	q.clock.Step(d)
+	q.Add(item)
+}
+
+// retryInstantly makes q retry its creates from an instantQueue, which it returns.
+func retryInstantly(q *QuotaRetry) *instantQueue {
+	iq := &instantQueue{Interface: workqueue.New(), clock: clocktesting.NewFakeClock(time.Now())}
+	q.clock, q.delayed = iq.clock, iq
+	q.start()
+	return iq
+}
+
+// runQuotaRetry runs q until the test ends.
+func runQuotaRetry(t *testing.T, q *QuotaRetry) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(stopped)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-stopped
+	})
+}
+
+func TestQuotaRetry_Retry(t *testing.T) {
+	otherErr := errors.New("boom")
+	for _, tc := range []struct {
+		name        string
+		window      time.Duration
+		errs        []error
+		wantErr     error
+		wantDropped string
+		wantCalls   int
+		wantDelays  []time.Duration
+	}{{
+		name:       "succeeds after retries",
+		window:     time.Minute,
+		errs:       []error{errQuotaExceeded, errQuotaExceeded, errQuotaExceeded},
+		wantCalls:  4,
+		wantDelays: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+	}, {
+		name:       "fails for another reason",
+		window:     time.Minute,
+		errs:       []error{errQuotaExceeded, otherErr},
+		wantErr:    otherErr,
+		wantCalls:  2,
+		wantDelays: []time.Duration{time.Second},
+	}, {
+		name:        "window exceeded",
+		window:      2 * time.Minute,
+		errs:        []error{errQuotaExceeded, errQuotaExceeded, errQuotaExceeded, errQuotaExceeded, errQuotaExceeded, errQuotaExceeded, errQuotaExceeded, errQuotaExceeded, errQuotaExceeded, errQuotaExceeded},
+		wantErr:     errQuotaExceeded,
+		wantDropped: quotaRetryWindowExceeded,
+		wantCalls:   9,
+		wantDelays:  []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second, 29 * time.Second},
+	}, {
+		name:      "not a quota error",
+		window:    time.Minute,
+		errs:      []error{otherErr},
+		wantErr:   otherErr,
+		wantCalls: 1,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			q := &QuotaRetry{Window: tc.window}
+			iq := retryInstantly(q)
+			runQuotaRetry(t, q)
+			create, calls := failingCreate(tc.errs...)
+			queued := false
+
+			_, err := create()
+			obj, dropped, err := q.retry(err, create, func() { queued = true })
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("retry() returned error %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && obj == nil {
+				t.Error("retry() returned no object")
+			}
+			if dropped != tc.wantDropped {
+				t.Errorf("retry() dropped the create because %q, want %q", dropped, tc.wantDropped)
+			}
+			if *calls != tc.wantCalls {
+				t.Errorf("create was called %d times, want %d", *calls, tc.wantCalls)
+			}
+			if diff := cmp.Diff(tc.wantDelays, iq.delays); diff != "" {
+				t.Errorf("retry() backoff -want +got: %s", diff)
+			}
+			if queued != (tc.wantCalls > 1) {
+				t.Errorf("retry() queued the create: %t", queued)
+			}
+			if len(q.creates) != 0 {
+				t.Errorf("retry() left %d creates in the queue", len(q.creates))
+			}
+		})
+	}
+}
+
+func TestQuotaRetry_QueueFull(t *testing.T) {
+	q := &QuotaRetry{Window: time.Minute, MaxQueued: 1}
+	iq := retryInstantly(q)
+	first, _ := failingCreate(errQuotaExceeded)
+	key, done, dropped := q.enqueue(iq.clock.Now().Add(time.Minute), first, errQuotaExceeded)
+	if dropped != "" {
+		t.Fatalf("enqueue() dropped the create of an empty queue because %q", dropped)
+	}
+	create, calls := failingCreate(errQuotaExceeded)
+	_, err := create()
+	_, dropped, err = q.retry(err, create, func() { t.Error("retry() queued the create") })
+	if !errors.Is(err, errQuotaExceeded) || dropped != quotaRetryQueueFull {
+		t.Errorf("retry() = %q, %v, want %q, %v", dropped, err, quotaRetryQueueFull, errQuotaExceeded)
+	}
+	if *calls != 1 {
+		t.Errorf("create was called %d times, want 1", *calls)
+	}
+
+	q.schedule(key)
+	runQuotaRetry(t, q)
+	if result := <-done; result.err != nil {
+		t.Fatalf("queued create returned error %v", result.err)
+	}
+	if _, _, err := q.retry(errQuotaExceeded, create, func() {}); err != nil {
+		t.Errorf("retry() returned error %v once the queue was emptied", err)
+	}
+}
+
+func TestQuotaRetry_Shutdown(t *testing.T) {
+	// The clock never advances, so that the create is still waiting for its first retry.
+	q := &QuotaRetry{Window: time.Minute, clock: clocktesting.NewFakeClock(time.Now())}
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(stopped)
+	}()
+	create, calls := failingCreate(errQuotaExceeded)
+	queued := make(chan struct{})
+	dropped := make(chan string, 1)
+	go func() {
+		_, reason, _ := q.retry(errQuotaExceeded, create, func() { close(queued) })
+		dropped <- reason
+	}()
+	<-queued
+	cancel()
+	<-stopped
+
+	if reason := <-dropped; reason != quotaRetryShutdown {
+		t.Errorf("retry() dropped the create because %q, want %q", reason, quotaRetryShutdown)
+	}
+	if *calls != 0 {
+		t.Errorf("create was called %d times, want 0", *calls)
+	}
+	if _, reason, _ := q.retry(errQuotaExceeded, create, func() { t.Error("retry() queued the create") }); reason != quotaRetryShutdown {
+		t.Errorf("retry() after the shutdown dropped the create because %q, want %q", reason, quotaRetryShutdown)
+	}
+}
+
+func TestCreateResources_QuotaRetry(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	recorder, err := NewRecorder()
+	if err != nil {
+		t.Fatalf("NewRecorder() returned error: %v", err)
+	}
+	res := []json.RawMessage{
+		json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"first"}}`),
+	}
+	quotaCreator := func(failures int) resources.Creator {
+		calls := 0
+		return resources.CreatorFunc(func(context.Context, *zap.SugaredLogger, json.RawMessage, string, string, string, string, discoveryclient.ServerResourcesInterface, dynamic.Interface) (*unstructured.Unstructured, error) {
+			calls++
+			if calls <= failures {
+				return nil, errQuotaExceeded
+			}
+			return &unstructured.Unstructured{}, nil
+		})
+	}
+
+	q := &QuotaRetry{Window: time.Minute}
+	retryInstantly(q)
+	runQuotaRetry(t, q)
+	r := Sink{
+		EventListenerName: "test-el",
+		Logger:            logger,
+		Recorder:          recorder,
+		Creator:           quotaCreator(2),
+		QuotaRetry:        q,
+	}
+	if err := r.CreateResources(namespace, "", "", res, "my-trigger", eventID, logger); err != nil {
+		t.Errorf("CreateResources() returned error: %v", err)
+	}
+
+	r.Creator = quotaCreator(100)
+	if err := r.CreateResources(namespace, "", "", res, "my-trigger", eventID, logger); !errors.Is(err, ErrQuotaRetryDropped) {
+		t.Errorf("CreateResources() = %v, want %v", err, ErrQuotaRetryDropped)
+	}
+
+	// Without a QuotaRetry, the creates are not retried.
+	r.QuotaRetry = nil
+	r.Creator = quotaCreator(1)
+	if err := r.CreateResources(namespace, "", "", res, "my-trigger", eventID, logger); !apierrors.IsForbidden(err) || errors.Is(err, ErrQuotaRetryDropped) {
+		t.Errorf("CreateResources() = %v, want %v", err, errQuotaExceeded)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+var (
+	// DefaultAllowedMethods are the HTTP methods accepted by the sink if AllowedMethods is not set.
+	DefaultAllowedMethods = []string{http.MethodPost}
+	// DefaultAllowedContentTypes are the media types accepted by the sink, along with the media types of its
+	// payload parsers, if AllowedContentTypes is not set. They are only enforced when payload validation is enabled, i.e. when the sink expects JSON.
+	DefaultAllowedContentTypes = []string{"application/json", "application/*+json"}
+)
+
+// FilterRequests rejects requests with a method that is not allowed with 405 Method Not Allowed, and
+// requests with a Content-Type that is not allowed with 415 Unsupported Media Type, before their body
+// is read. Requests without a Content-Type header are not rejected.
+func (r Sink) FilterRequests(eventHandler http.Handler) http.Handler {
+	methods := r.AllowedMethods
+	if len(methods) == 0 {
+		methods = DefaultAllowedMethods
+	}
+	contentTypes := r.AllowedContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = append(append([]string{}, DefaultAllowedContentTypes...), r.payloadParsers().MediaTypes()...)
+	}
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		if !methodAllowed(request.Method, methods) {
+			r.Logger.Debugf("rejecting request with method %s", request.Method)
+			r.recordCountMetrics(failTag)
+			response.Header().Set("Allow", strings.Join(methods, ", "))
+			response.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if r.PayloadValidation {
+			if ct := request.Header.Get("Content-Type"); ct != "" && !contentTypeAllowed(ct, contentTypes) {
+				r.Logger.Debugf("rejecting request with Content-Type %s", ct)
+				r.recordCountMetrics(failTag)
+				response.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+		}
+		eventHandler.ServeHTTP(response, request)
+	})
+}
+
+func methodAllowed(method string, allowed []string) bool {
+	for _, m := range allowed {
+		if strings.EqualFold(method, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// contentTypeAllowed returns true if the media type of contentType matches one of the allowed patterns.
+// Patterns may contain wildcards, e.g. application/*+json.
+func contentTypeAllowed(contentType string, allowed []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, pattern := range allowed {
+		if ok, _ := path.Match(strings.ToLower(pattern), mediaType); ok {
+			return true
+		}
+	}
+	return false
+}
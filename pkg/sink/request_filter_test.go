@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSink_FilterRequests(t *testing.T) {
+	recorder, err := NewRecorder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	for _, tc := range []struct {
+		name                string
+		method              string
+		contentType         string
+		payloadValidation   bool
+		allowedMethods      []string
+		allowedContentTypes []string
+		wantStatusCode      int
+		wantAllow           string
+	}{{
+		name:              "json post",
+		method:            http.MethodPost,
+		contentType:       "application/json",
+		payloadValidation: true,
+		wantStatusCode:    http.StatusAccepted,
+	}, {
+		name:              "json post with charset",
+		method:            http.MethodPost,
+		contentType:       "application/json; charset=utf-8",
+		payloadValidation: true,
+		wantStatusCode:    http.StatusAccepted,
+	}, {
+		name:              "structured cloud event",
+		method:            http.MethodPost,
+		contentType:       "application/cloudevents+json",
+		payloadValidation: true,
+		wantStatusCode:    http.StatusAccepted,
+	}, {
+		name:              "no content type",
+		method:            http.MethodPost,
+		payloadValidation: true,
+		wantStatusCode:    http.StatusAccepted,
+	}, {
+		name:              "get is not allowed",
+		method:            http.MethodGet,
+		payloadValidation: true,
+		wantStatusCode:    http.StatusMethodNotAllowed,
+		wantAllow:         "POST",
+	}, {
+		name:              "unexpected content type",
+		method:            http.MethodPost,
+		contentType:       "text/html",
+		payloadValidation: true,
+		wantStatusCode:    http.StatusUnsupportedMediaType,
+	}, {
+		name:              "malformed content type",
+		method:            http.MethodPost,
+		contentType:       "application/json; =",
+		payloadValidation: true,
+		wantStatusCode:    http.StatusUnsupportedMediaType,
+	}, {
+		name:              "content type is not checked without payload validation",
+		method:            http.MethodPost,
+		contentType:       "application/x-www-form-urlencoded",
+		payloadValidation: false,
+		wantStatusCode:    http.StatusAccepted,
+	}, {
+		name:              "overridden methods",
+		method:            http.MethodPut,
+		contentType:       "application/json",
+		payloadValidation: true,
+		allowedMethods:    []string{"POST", "PUT"},
+		wantStatusCode:    http.StatusAccepted,
+	}, {
+		name:              "overridden methods rejects post",
+		method:            http.MethodPost,
+		payloadValidation: true,
+		allowedMethods:    []string{"PUT", "PATCH"},
+		wantStatusCode:    http.StatusMethodNotAllowed,
+		wantAllow:         "PUT, PATCH",
+	}, {
+		name:                "overridden content types",
+		method:              http.MethodPost,
+		contentType:         "text/plain",
+		payloadValidation:   true,
+		allowedContentTypes: []string{"text/*"},
+		wantStatusCode:      http.StatusAccepted,
+	}, {
+		name:                "overridden content types rejects json",
+		method:              http.MethodPost,
+		contentType:         "application/json",
+		payloadValidation:   true,
+		allowedContentTypes: []string{"text/plain"},
+		wantStatusCode:      http.StatusUnsupportedMediaType,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := Sink{
+				Logger:              zaptest.NewLogger(t).Sugar(),
+				Recorder:            recorder,
+				PayloadValidation:   tc.payloadValidation,
+				AllowedMethods:      tc.allowedMethods,
+				AllowedContentTypes: tc.allowedContentTypes,
+			}
+			req := httptest.NewRequest(tc.method, "/", strings.NewReader(`{}`))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			resp := httptest.NewRecorder()
+			r.FilterRequests(next).ServeHTTP(resp, req)
+			if resp.Code != tc.wantStatusCode {
+				t.Errorf("unexpected status code: got %d, want %d", resp.Code, tc.wantStatusCode)
+			}
+			if got := resp.Header().Get("Allow"); got != tc.wantAllow {
+				t.Errorf("unexpected Allow header: got %q, want %q", got, tc.wantAllow)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/tektoncd/triggers/pkg/sink/cloudevent"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ResourceEvents sends a cloud event with the outcome of the creation of each resource of the
+// triggers to TargetURI, so that other systems can react to what the EventListener did, e.g. by
+// chaining more events. The events are sent in the background, so they never delay or fail the events
+// of the EventListener.
+//
+// A nil *ResourceEvents sends no cloud events.
+type ResourceEvents struct {
+	// TargetURI is the URI of the sink the cloud events are sent to.
+	TargetURI string
+	// Source is the source of the cloud events. Defaults to the path of the EventListener,
+	// /apis/triggers.tekton.dev/v1beta1/namespaces/<namespace>/eventlisteners/<name>.
+	Source string
+	// Created, if true, sends a cloudevent.ResourceCreatedType cloud event for each created resource.
+	Created bool
+	// Failed, if true, sends a cloudevent.ResourceFailedType cloud event for each resource that could
+	// not be created.
+	Failed bool
+}
+
+// ResourceEventData is the data of the cloud events sent by ResourceEvents.
+type ResourceEventData struct {
+	// EventID is the ID of the event the resource was created for.
+	EventID string `json:"eventID"`
+	// EventListener is the name of the EventListener that received the event.
+	EventListener string `json:"eventListener"`
+	// Namespace is the namespace of the EventListener.
+	Namespace string `json:"namespace"`
+	// Trigger is the name of the trigger of the resource.
+	Trigger string `json:"trigger"`
+	// Resource is the created resource, or the resource of the template that could not be created,
+	// without a UID, and named after its generateName if it has no name.
+	Resource CreatedResource `json:"resource"`
+	// Error is why the resource could not be created.
+	Error string `json:"error,omitempty"`
+}
+
+// resourceEventSubject returns the subject of the cloud events of the resource: its namespace and name.
+// Its API version and kind are in the data of the events, and its resource, which the path of an API
+// would be made of, isn't known for the templates that couldn't be created.
+func resourceEventSubject(res CreatedResource) string {
+	return res.Namespace + "/" + res.Name
+}
+
+// templateResource returns the identity of the resource of the template rr, which couldn't be created.
+func templateResource(rr json.RawMessage, defaultNS string, defaultType metav1.TypeMeta, triggerName string) CreatedResource {
+	obj := &unstructured.Unstructured{}
+	// The template may not even be a valid resource, which is still reported with what is known of it.
+	_ = obj.UnmarshalJSON(rr)
+	res := CreatedResource{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		Trigger:    triggerName,
+	}
+	if res.APIVersion == "" && res.Kind == "" {
+		res.APIVersion, res.Kind = defaultType.APIVersion, defaultType.Kind
+	}
+	if res.Namespace == "" {
+		res.Namespace = defaultNS
+	}
+	if res.Name == "" {
+		res.Name = obj.GetGenerateName()
+	}
+	return res
+}
+
+// sendResourceEvent sends the cloud event of the outcome of creating the resource of the template at
+// index i of the trigger for the event: created, or else the error err.
+func (r Sink) sendResourceEvent(triggerName, eventID string, i int, rr json.RawMessage, defaultNS string, meta resourceMetadata, created *unstructured.Unstructured, err error, log *zap.SugaredLogger) {
+	if r.ResourceEvents == nil || r.CEClient == nil {
+		return
+	}
+	data := ResourceEventData{
+		EventID:       eventID,
+		EventListener: r.EventListenerName,
+		Namespace:     r.EventListenerNamespace,
+		Trigger:       triggerName,
+	}
+	eventType := cloudevent.ResourceCreatedType
+	switch {
+	case err != nil:
+		if !r.ResourceEvents.Failed {
+			return
+		}
+		eventType = cloudevent.ResourceFailedType
+		data.Resource = templateResource(rr, defaultNS, meta.defaultType, triggerName)
+		data.Error = err.Error()
+	case created != nil:
+		if !r.ResourceEvents.Created {
+			return
+		}
+		data.Resource = createdResources(triggerName, []*unstructured.Unstructured{created})[0]
+	default:
+		return
+	}
+
+	source := r.ResourceEvents.Source
+	if source == "" {
+		source = fmt.Sprintf("/apis/triggers.tekton.dev/v1beta1/namespaces/%s/eventlisteners/%s", r.EventListenerNamespace, r.EventListenerName)
+	}
+	event := cloudevent.ResourceEvent{
+		// The ID is unique for each resource template of each trigger of the event, and the same for
+		// its created and failed events, which are never both sent.
+		ID:        fmt.Sprintf("%s-%s-%d", eventID, triggerName, i),
+		Type:      eventType,
+		Source:    source,
+		Subject:   resourceEventSubject(data.Resource),
+		TargetURI: r.ResourceEvents.TargetURI,
+		Client:    r.CEClient,
+		Data:      data,
+	}
+	go func() {
+		if err := event.Send(); err != nil {
+			log.Errorf("failed to send the %s cloud event to %s: %v", eventType, event.TargetURI, err)
+		}
+	}()
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	cloudeventstest "github.com/cloudevents/sdk-go/v2/client/test"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/triggers/pkg/sink/cloudevent"
+	"go.uber.org/zap/zaptest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSendResourceEvent(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	pipelineRun := json.RawMessage(`{"apiVersion": "tekton.dev/v1beta1", "kind": "PipelineRun", "metadata": {"generateName": "build-"}}`)
+	for _, tc := range []struct {
+		name        string
+		events      ResourceEvents
+		template    json.RawMessage
+		meta        resourceMetadata
+		err         error
+		wantType    string
+		wantSource  string
+		wantSubject string
+		wantData    ResourceEventData
+	}{{
+		name:        "created",
+		events:      ResourceEvents{Created: true},
+		template:    pipelineRun,
+		wantType:    cloudevent.ResourceCreatedType,
+		wantSource:  "/apis/triggers.tekton.dev/v1beta1/namespaces/foo/eventlisteners/my-el",
+		wantSubject: "foo/build-xk2lp",
+		wantData: ResourceEventData{
+			EventID:       eventID,
+			EventListener: "my-el",
+			Namespace:     namespace,
+			Trigger:       "my-trigger",
+			Resource:      createdResources("my-trigger", []*unstructured.Unstructured{createdPipelineRun()})[0],
+		},
+	}, {
+		name:        "failed",
+		events:      ResourceEvents{Failed: true, Source: "/triggers/github"},
+		template:    pipelineRun,
+		err:         errors.New("pipelineruns.tekton.dev is forbidden"),
+		wantType:    cloudevent.ResourceFailedType,
+		wantSource:  "/triggers/github",
+		wantSubject: "builds/build-",
+		wantData: ResourceEventData{
+			EventID:       eventID,
+			EventListener: "my-el",
+			Namespace:     namespace,
+			Trigger:       "my-trigger",
+			Resource:      CreatedResource{APIVersion: "tekton.dev/v1beta1", Kind: "PipelineRun", Namespace: "builds", Name: "build-", Trigger: "my-trigger"},
+			Error:         "pipelineruns.tekton.dev is forbidden",
+		},
+	}, {
+		name:        "failed with the default type",
+		events:      ResourceEvents{Failed: true},
+		template:    json.RawMessage(`{"metadata": {"name": "build", "namespace": "ci"}}`),
+		meta:        resourceMetadata{defaultType: metav1.TypeMeta{APIVersion: "tekton.dev/v1beta1", Kind: "PipelineRun"}},
+		err:         errors.New("timed out"),
+		wantType:    cloudevent.ResourceFailedType,
+		wantSource:  "/apis/triggers.tekton.dev/v1beta1/namespaces/foo/eventlisteners/my-el",
+		wantSubject: "ci/build",
+		wantData: ResourceEventData{
+			EventID:       eventID,
+			EventListener: "my-el",
+			Namespace:     namespace,
+			Trigger:       "my-trigger",
+			Resource:      CreatedResource{APIVersion: "tekton.dev/v1beta1", Kind: "PipelineRun", Namespace: "ci", Name: "build", Trigger: "my-trigger"},
+			Error:         "timed out",
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			client, events := cloudeventstest.NewMockSenderClient(t, 1)
+			tc.events.TargetURI = "http://broker.example.com"
+			r := Sink{
+				EventListenerName:      "my-el",
+				EventListenerNamespace: namespace,
+				CEClient:               client,
+				ResourceEvents:         &tc.events,
+			}
+			var created *unstructured.Unstructured
+			if tc.err == nil {
+				created = createdPipelineRun()
+			}
+			r.sendResourceEvent("my-trigger", eventID, 1, tc.template, "builds", tc.meta, created, tc.err, logger)
+
+			var got event.Event
+			select {
+			case got = <-events:
+			case <-time.After(5 * time.Second):
+				t.Fatal("sendResourceEvent() sent no cloud event")
+			}
+			if got.ID() != eventID+"-my-trigger-1" || got.Type() != tc.wantType || got.Source() != tc.wantSource || got.Subject() != tc.wantSubject {
+				t.Errorf("sendResourceEvent() sent cloud event with ID %q, type %q, source %q and subject %q, want %q, %q, %q and %q",
+					got.ID(), got.Type(), got.Source(), got.Subject(), eventID+"-my-trigger-1", tc.wantType, tc.wantSource, tc.wantSubject)
+			}
+			var data ResourceEventData
+			if err := got.DataAs(&data); err != nil {
+				t.Fatalf("failed to decode the data of the cloud event: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantData, data); diff != "" {
+				t.Errorf("sendResourceEvent() sent data -want +got: %s", diff)
+			}
+		})
+	}
+}
+
+func TestSendResourceEvent_NotSelected(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	client, events := cloudeventstest.NewMockSenderClient(t, 1)
+	r := Sink{
+		EventListenerName:      "my-el",
+		EventListenerNamespace: namespace,
+		CEClient:               client,
+		ResourceEvents:         &ResourceEvents{TargetURI: "http://broker.example.com", Failed: true},
+	}
+
+	r.sendResourceEvent("my-trigger", eventID, 0, json.RawMessage(`{}`), namespace, resourceMetadata{}, createdPipelineRun(), nil, logger)
+	r.ResourceEvents = &ResourceEvents{TargetURI: "http://broker.example.com", Created: true}
+	r.sendResourceEvent("my-trigger", eventID, 0, json.RawMessage(`{}`), namespace, resourceMetadata{}, nil, errors.New("forbidden"), logger)
+	r.ResourceEvents = nil
+	r.sendResourceEvent("my-trigger", eventID, 0, json.RawMessage(`{}`), namespace, resourceMetadata{}, createdPipelineRun(), nil, logger)
+	select {
+	case e := <-events:
+		t.Errorf("sendResourceEvent() sent a cloud event of type %s that wasn't selected", e.Type())
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/http/httpguts"
+)
+
+const (
+	// EventIDHeader is the header of the responses of the sink with the ID of the event.
+	EventIDHeader = "X-Tekton-Event-ID"
+	// TriggerHeader is the header of the responses of the sink with the comma-separated names of the
+	// triggers that the event was dispatched to.
+	TriggerHeader = "X-Tekton-Trigger"
+
+	// responseHeaderPrefix is the prefix of the names of the response headers that interceptors can set, so
+	// that they can't clobber headers such as Content-Type, Location or Set-Cookie.
+	responseHeaderPrefix = "X-"
+	// reservedResponseHeaderPrefix is the prefix of the names of the response headers set by the sink itself.
+	reservedResponseHeaderPrefix = "X-Tekton-"
+)
+
+// mergeResponseHeaders returns the response headers set by an interceptor added to the ones set by the previous
+// interceptors of the chain, which they replace for the same names.
+func mergeResponseHeaders(headers, set map[string]string) map[string]string {
+	if len(set) == 0 {
+		return headers
+	}
+	merged := make(map[string]string, len(headers)+len(set))
+	for k, v := range headers {
+		merged[k] = v
+	}
+	for k, v := range set {
+		merged[k] = v
+	}
+	return merged
+}
+
+// validateResponseHeader returns an error if interceptors can't set the response header name to value.
+func validateResponseHeader(name, value string) error {
+	if !httpguts.ValidHeaderFieldName(name) {
+		return fmt.Errorf("%q is not a valid header name", name)
+	}
+	canonical := http.CanonicalHeaderKey(name)
+	if !strings.HasPrefix(canonical, responseHeaderPrefix) {
+		return fmt.Errorf("header %s doesn't start with %s", canonical, responseHeaderPrefix)
+	}
+	if strings.HasPrefix(canonical, reservedResponseHeaderPrefix) {
+		return fmt.Errorf("header %s starts with the reserved %s prefix", canonical, reservedResponseHeaderPrefix)
+	}
+	if !httpguts.ValidHeaderFieldValue(value) {
+		return fmt.Errorf("the value of header %s is not valid", canonical)
+	}
+	return nil
+}
+
+// setHeaders records the response headers set by the interceptors of the trigger or trigger group.
+func (o *triggerOutcomes) setHeaders(name string, headers map[string]string) {
+	if len(headers) == 0 {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.headers == nil {
+		o.headers = map[string]map[string]string{}
+	}
+	o.headers[name] = headers
+}
+
+// responseHeaders returns the response headers set by the interceptors of the triggers and trigger groups of the
+// event. If several of them set the same header, the one whose name sorts first wins. The headers that
+// interceptors can't set are logged and left out.
+func (o *triggerOutcomes) responseHeaders(log *zap.SugaredLogger) http.Header {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	names := make([]string, 0, len(o.headers))
+	for name := range o.headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := http.Header{}
+	for _, name := range names {
+		for k, v := range o.headers[name] {
+			if err := validateResponseHeader(k, v); err != nil {
+				log.Warnf("ignoring response header set by the interceptors of %s: %v", name, err)
+				continue
+			}
+			if _, ok := out[http.CanonicalHeaderKey(k)]; !ok {
+				out.Set(k, v)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	triggersv1alpha1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	triggersv1beta1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/test"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc/codes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+)
+
+func TestValidateResponseHeader(t *testing.T) {
+	for _, tc := range []struct {
+		name, value string
+		wantErr     bool
+	}{
+		{name: "X-RateLimit-Remaining", value: "41"},
+		{name: "x-correlation-id", value: "abc-123"},
+		{name: "Content-Type", value: "text/html", wantErr: true},
+		{name: "Set-Cookie", value: "session=1", wantErr: true},
+		{name: "X-Tekton-Event-ID", value: "spoofed", wantErr: true},
+		{name: "x-tekton-trigger", value: "spoofed", wantErr: true},
+		{name: "X-Invalid Name", value: "1", wantErr: true},
+		{name: "X-Injected", value: "1\r\nSet-Cookie: session=1", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateResponseHeader(tc.name, tc.value); (err != nil) != tc.wantErr {
+				t.Errorf("validateResponseHeader(%q, %q) = %v, want error %t", tc.name, tc.value, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestTriggerOutcomes_ResponseHeaders(t *testing.T) {
+	o := &triggerOutcomes{}
+	o.setHeaders("b-trigger", map[string]string{"X-Correlation-ID": "from-b", "X-Only-B": "b"})
+	o.setHeaders("a-trigger", map[string]string{"x-correlation-id": "from-a", "Location": "/elsewhere"})
+	o.setHeaders("c-trigger", nil)
+
+	got := o.responseHeaders(zaptest.NewLogger(t).Sugar())
+	want := http.Header{"X-Correlation-Id": {"from-a"}, "X-Only-B": {"b"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("responseHeaders() -want +got: %s", diff)
+	}
+}
+
+// headersInterceptor is an interceptor that sets response headers and rejects the events.
+type headersInterceptor struct {
+	headers map[string]string
+}
+
+func (h headersInterceptor) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	_ = json.NewEncoder(w).Encode(triggersv1beta1.InterceptorResponse{
+		Continue:        false,
+		Status:          triggersv1beta1.Status{Code: codes.ResourceExhausted, Message: "slow down"},
+		ResponseHeaders: h.headers,
+	})
+}
+
+func TestHandleEvent_ResponseHeaders(t *testing.T) {
+	limiter := &triggersv1alpha1.Interceptor{
+		ObjectMeta: metav1.ObjectMeta{Name: "limiter", Namespace: namespace},
+		Spec: triggersv1alpha1.InterceptorSpec{
+			ClientConfig: triggersv1alpha1.ClientConfig{
+				URL: &apis.URL{Scheme: "http", Host: "limiter.example.com", Path: "/"},
+			},
+		},
+	}
+	interceptor := headersInterceptor{headers: map[string]string{
+		"X-RateLimit-Remaining": "0",
+		"Content-Type":          "text/html",
+		"X-Tekton-Event-ID":     "spoofed",
+	}}
+
+	for _, tc := range []struct {
+		name        string
+		synchronous bool
+		want        http.Header
+	}{{
+		name:        "synchronous",
+		synchronous: true,
+		want:        http.Header{EventIDHeader: {eventID}, TriggerHeader: {"limited"}, "X-Ratelimit-Remaining": {"0"}},
+	}, {
+		name: "asynchronous",
+		want: http.Header{EventIDHeader: {eventID}, TriggerHeader: {"limited"}, "X-Ratelimit-Remaining": nil},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			resources := test.Resources{
+				EventListeners: []*triggersv1beta1.EventListener{{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-el", Namespace: namespace, UID: types.UID(elUID)},
+					Spec: triggersv1beta1.EventListenerSpec{
+						Triggers: []triggersv1beta1.EventListenerTrigger{{
+							Name: "limited",
+							Interceptors: []*triggersv1beta1.TriggerInterceptor{{
+								Ref: triggersv1beta1.InterceptorRef{Name: "limiter", Kind: triggersv1beta1.NamespacedInterceptorKind},
+							}},
+							Template: &triggersv1beta1.EventListenerTemplate{Spec: makeGitCloneTTSpec(t, "limited-run")},
+						}},
+					},
+				}},
+				Interceptors: []*triggersv1alpha1.Interceptor{limiter},
+			}
+			sink, _ := getSinkAssets(t, resources, "test-el", interceptor)
+			sink.Synchronous = tc.synchronous
+
+			ts := httptest.NewServer(http.HandlerFunc(sink.HandleEvent))
+			defer ts.Close()
+			resp, err := http.Post(ts.URL, "application/json", bytes.NewReader([]byte(`{"foo": "bar"}`)))
+			if err != nil {
+				t.Fatalf("error making request to eventListener: %s", err)
+			}
+			defer resp.Body.Close()
+			sink.WGProcessTriggers.Wait()
+
+			for k, want := range tc.want {
+				if diff := cmp.Diff(want, resp.Header.Values(k)); diff != "" {
+					t.Errorf("header %s -want +got: %s", k, diff)
+				}
+			}
+			if got := resp.Header.Get("Content-Type"); got == "text/html" {
+				t.Errorf("got Content-Type %q set by the interceptor", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/pkg/resources"
+	"github.com/tektoncd/triggers/pkg/template"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// SelfTestPath is the path of the self-test endpoint.
+	SelfTestPath = "/self-test"
+	// SelfTestSecretKey is the key of the token in the self-test secret.
+	SelfTestSecretKey = "token"
+
+	// selfTestGenerateName is the generateName of the objects created with a dry run.
+	selfTestGenerateName = "triggers-self-test-"
+)
+
+// SelfTest configures the self-test endpoint, which checks that the EventListener can create the kinds of
+// resources of its triggers without a real event.
+type SelfTest struct {
+	// SecretName is the name of the secret in the namespace of the EventListener whose SelfTestSecretKey is
+	// the bearer token that requests to the endpoint must have.
+	SecretName string
+	// SecretGetter gets the secret.
+	SecretGetter interceptors.SecretGetter
+}
+
+// SelfTestResponse is the JSON body of the responses of the self-test endpoint.
+type SelfTestResponse struct {
+	EventListener string `json:"eventListener"`
+	Namespace     string `json:"namespace"`
+	// OK is whether all the checks passed.
+	OK bool `json:"ok"`
+	// ErrorMessage is why the kinds of resources of the triggers couldn't be listed.
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	// Resources are the results of the checks for each kind of resource, namespace and service account.
+	Resources []SelfTestResult `json:"resources"`
+}
+
+// SelfTestResult is the result of the checks for one kind of resource created by triggers in a namespace
+// with a service account.
+type SelfTestResult struct {
+	APIVersion         string `json:"apiVersion"`
+	Kind               string `json:"kind"`
+	Namespace          string `json:"namespace,omitempty"`
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	ClientProfile      string `json:"clientProfile,omitempty"`
+	// Triggers are the names of the triggers creating the resources.
+	Triggers []string `json:"triggers"`
+	OK       bool     `json:"ok"`
+	// Discovery is "ok" if the API resource was found, or why it wasn't.
+	Discovery string `json:"discovery"`
+	// DryRunCreate is "ok" if a minimal object was created with a dry run, or why it couldn't be created,
+	// e.g. the message of the API server rejecting it as invalid. It is empty if the discovery failed.
+	DryRunCreate string `json:"dryRunCreate,omitempty"`
+}
+
+// selfTestTarget is a kind of resource that triggers in triggerNamespace create in a namespace with a
+// service account and a client profile.
+type selfTestTarget struct {
+	apiVersion, kind, namespace, triggerNamespace, serviceAccountName, clientProfile string
+}
+
+// HandleSelfTest runs the self-test for requests with the bearer token of the self-test secret. It finds the
+// API resource of each kind of resource of the triggers of the EventListener and creates a minimal object of
+// that kind with a dry run, with the credentials of the service account of the triggers. It responds with
+// 200 OK if all the checks passed and 503 Service Unavailable otherwise.
+func (r Sink) HandleSelfTest(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		response.Header().Set("Allow", http.MethodGet)
+		response.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.authenticateSelfTest(request); err != nil {
+		r.Logger.Warnf("rejecting self-test request: %v", err)
+		response.Header().Set("WWW-Authenticate", "Bearer")
+		response.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	body := r.runSelfTest(request.Context())
+	status := http.StatusOK
+	if !body.OK {
+		status = http.StatusServiceUnavailable
+	}
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(status)
+	if err := json.NewEncoder(response).Encode(body); err != nil {
+		r.Logger.Errorf("failed to write back self-test response: %v", err)
+	}
+}
+
+// authenticateSelfTest checks that the request has the bearer token of the self-test secret.
+func (r Sink) authenticateSelfTest(request *http.Request) error {
+	if r.SelfTest == nil {
+		return errors.New("the self-test is not enabled")
+	}
+	return r.authenticateBearer(request, r.SelfTest.SecretGetter, r.SelfTest.SecretName, SelfTestSecretKey, "self-test")
+}
+
+// authenticateBearer checks that the request has the bearer token of the key of the secret in the namespace
+// of the EventListener, the secret of the endpoint named in the errors. The SHA-256 hashes of the tokens are
+// compared in constant time.
+func (r Sink) authenticateBearer(request *http.Request, getter interceptors.SecretGetter, secretName, secretKey, endpoint string) error {
+	token := strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer ")
+	if token == "" || token == request.Header.Get("Authorization") {
+		return errors.New("no bearer token")
+	}
+	secret, err := getter.Get(request.Context(), r.EventListenerNamespace, &triggersv1.SecretRef{
+		SecretName: secretName,
+		SecretKey:  secretKey,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get the %s secret: %w", endpoint, err)
+	}
+	got := sha256.Sum256([]byte(token))
+	want := sha256.Sum256(secret)
+	if subtle.ConstantTimeCompare(got[:], want[:]) != 1 {
+		return fmt.Errorf("the bearer token does not match the %s secret", endpoint)
+	}
+	return nil
+}
+
+func (r Sink) runSelfTest(ctx context.Context) SelfTestResponse {
+	body := SelfTestResponse{
+		EventListener: r.EventListenerName,
+		Namespace:     r.EventListenerNamespace,
+		Resources:     []SelfTestResult{},
+	}
+	targets, err := r.selfTestTargets()
+	if err != nil {
+		body.ErrorMessage = err.Error()
+		return body
+	}
+
+	body.OK = true
+	for _, target := range sortedSelfTestTargets(targets) {
+		result := r.checkSelfTestTarget(ctx, target)
+		result.Triggers = targets[target]
+		body.OK = body.OK && result.OK
+		body.Resources = append(body.Resources, result)
+	}
+	return body
+}
+
+// selfTestTargets returns the kinds of resources the triggers of the EventListener create, with the names of
+// the triggers creating them.
+func (r Sink) selfTestTargets() (map[selfTestTarget][]string, error) {
+	el, err := r.EventListenerLister.EventListeners(r.EventListenerNamespace).Get(r.EventListenerName)
+	if err != nil {
+		return nil, fmt.Errorf("error getting EventListener %s in Namespace %s: %w", r.EventListenerName, r.EventListenerNamespace, err)
+	}
+	trItems, err := r.selectTriggers(el.Spec.NamespaceSelector, el.Spec.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("unable to select triggers: %w", err)
+	}
+	mergedTriggers, err := r.merge(el.Spec.Triggers, trItems)
+	if err != nil {
+		return nil, fmt.Errorf("error merging triggers: %w", err)
+	}
+	for _, g := range el.Spec.TriggerGroups {
+		grouped, err := r.selectTriggers(g.TriggerSelector.NamespaceSelector, g.TriggerSelector.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("unable to select the triggers of group %s: %w", g.Name, err)
+		}
+		mergedTriggers = append(mergedTriggers, grouped...)
+	}
+
+	targets := map[selfTestTarget][]string{}
+	for _, t := range mergedTriggers {
+		// The TriggerTemplates of the routes are checked along with the fallback template.
+		variants := []triggersv1.Trigger{*t}
+		if routing := t.Spec.Template.Routing; routing != nil {
+			for _, route := range routing.Routes {
+				routed, _ := template.RouteTrigger(*t, map[string]interface{}{routing.Extension: route.Value})
+				variants = append(variants, routed)
+			}
+		}
+		for _, variant := range variants {
+			rt, err := template.ResolveTrigger(variant,
+				r.TriggerBindingLister.TriggerBindings(t.Namespace).Get,
+				r.ClusterTriggerBindingLister.Get,
+				r.TriggerTemplateLister.TriggerTemplates(t.Namespace).Get)
+			if err != nil {
+				return nil, fmt.Errorf("trigger %s: %w", t.Name, err)
+			}
+			namespace := t.Spec.DefaultNamespace
+			if namespace == "" {
+				namespace = t.Namespace
+			}
+			for i, resourceTemplate := range rt.TriggerTemplate.Spec.ResourceTemplates {
+				docs, err := resourceTemplate.Documents()
+				if err != nil {
+					return nil, fmt.Errorf("resource template %d of trigger %s: %w", i, t.Name, err)
+				}
+				for _, doc := range docs {
+					var obj struct {
+						APIVersion string `json:"apiVersion"`
+						Kind       string `json:"kind"`
+						Metadata   struct {
+							Namespace string `json:"namespace"`
+						} `json:"metadata"`
+					}
+					if err := json.Unmarshal(doc, &obj); err != nil {
+						return nil, fmt.Errorf("resource template %d of trigger %s: %w", i, t.Name, err)
+					}
+					if obj.APIVersion == "" && obj.Kind == "" {
+						obj.APIVersion, obj.Kind = t.Spec.DefaultAPIVersion, t.Spec.DefaultKind
+					}
+					target := selfTestTarget{apiVersion: obj.APIVersion, kind: obj.Kind, namespace: namespace, triggerNamespace: t.Namespace, serviceAccountName: t.Spec.ServiceAccountName, clientProfile: t.Spec.ClientProfile}
+					// Namespaces taken from params are only known once an event is received.
+					if obj.Metadata.Namespace != "" && !strings.Contains(obj.Metadata.Namespace, "$(") {
+						target.namespace = obj.Metadata.Namespace
+					}
+					if !containsString(targets[target], t.Name) {
+						targets[target] = append(targets[target], t.Name)
+					}
+				}
+			}
+		}
+	}
+	return targets, nil
+}
+
+// checkSelfTestTarget finds the API resource of the target and creates a minimal object of its kind with a
+// dry run.
+func (r Sink) checkSelfTestTarget(ctx context.Context, target selfTestTarget) SelfTestResult {
+	result := SelfTestResult{
+		APIVersion:         target.apiVersion,
+		Kind:               target.kind,
+		Namespace:          target.namespace,
+		ServiceAccountName: target.serviceAccountName,
+		ClientProfile:      target.clientProfile,
+	}
+	discoveryClient, dynamicClient, err := r.resourceClients(ctx, target.clientProfile, target.serviceAccountName, target.triggerNamespace, r.Logger)
+	if err != nil {
+		switch {
+		case target.clientProfile != "":
+			result.Discovery = fmt.Sprintf("failed to use the clients of client profile %s: %v", target.clientProfile, err)
+		default:
+			result.Discovery = fmt.Sprintf("failed to use the credentials of service account %s: %v", target.serviceAccountName, err)
+		}
+		return result
+	}
+
+	apiResource, err := resources.FindAPIResource(target.apiVersion, target.kind, discoveryClient)
+	if err != nil {
+		result.Discovery = err.Error()
+		return result
+	}
+	result.Discovery = "ok"
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(target.apiVersion)
+	obj.SetKind(target.kind)
+	obj.SetGenerateName(selfTestGenerateName)
+	gvr := schema.GroupVersionResource{Group: apiResource.Group, Version: apiResource.Version, Resource: apiResource.Name}
+	resourceClient := dynamicClient.Resource(gvr)
+	opts := metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}, FieldValidation: metav1.FieldValidationIgnore}
+	if apiResource.Namespaced {
+		obj.SetNamespace(target.namespace)
+		_, err = resourceClient.Namespace(target.namespace).Create(ctx, obj, opts)
+	} else {
+		result.Namespace = ""
+		_, err = resourceClient.Create(ctx, obj, opts)
+	}
+	switch {
+	case err == nil:
+		result.DryRunCreate = "ok"
+		result.OK = true
+	default:
+		result.DryRunCreate = err.Error()
+	}
+	return result
+}
+
+func sortedSelfTestTargets(targets map[selfTestTarget][]string) []selfTestTarget {
+	sorted := make([]selfTestTarget, 0, len(targets))
+	for t := range targets {
+		sorted = append(sorted, t)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.apiVersion != b.apiVersion {
+			return a.apiVersion < b.apiVersion
+		}
+		if a.kind != b.kind {
+			return a.kind < b.kind
+		}
+		if a.namespace != b.namespace {
+			return a.namespace < b.namespace
+		}
+		if a.serviceAccountName != b.serviceAccountName {
+			return a.serviceAccountName < b.serviceAccountName
+		}
+		return a.clientProfile < b.clientProfile
+	})
+	return sorted
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
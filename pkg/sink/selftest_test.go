@@ -0,0 +1,215 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	triggersv1beta1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors"
+	"github.com/tektoncd/triggers/test"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	ktesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/ptr"
+)
+
+func selfTestResources(t *testing.T, templates ...runtime.RawExtension) test.Resources {
+	t.Helper()
+	tt := &triggersv1beta1.TriggerTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "tt", Namespace: namespace},
+	}
+	for _, raw := range templates {
+		tt.Spec.ResourceTemplates = append(tt.Spec.ResourceTemplates, triggersv1beta1.TriggerResourceTemplate{RawExtension: raw})
+	}
+	return test.Resources{
+		EventListeners: []*triggersv1beta1.EventListener{{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-el", Namespace: namespace},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Name:     "my-trigger",
+					Template: &triggersv1beta1.EventListenerTemplate{Ref: ptr.String("tt")},
+				}},
+			},
+		}},
+		TriggerTemplates: []*triggersv1beta1.TriggerTemplate{tt},
+		Secrets: []*corev1.Secret{{
+			ObjectMeta: metav1.ObjectMeta{Name: "self-test", Namespace: namespace},
+			Data:       map[string][]byte{SelfTestSecretKey: []byte("let-me-in")},
+		}},
+	}
+}
+
+func selfTestSink(t *testing.T, res test.Resources) (Sink, *fakedynamic.FakeDynamicClient) {
+	t.Helper()
+	r, dynamicClient := getSinkAssets(t, res, "my-el", nil)
+	r.SelfTest = &SelfTest{
+		SecretName:   "self-test",
+		SecretGetter: interceptors.DefaultSecretGetter(r.KubeClientSet.CoreV1()),
+	}
+	return r, dynamicClient
+}
+
+func TestHandleSelfTest(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		templates []runtime.RawExtension
+		createErr error
+		want      SelfTestResponse
+		wantCode  int
+	}{{
+		name:      "known kind",
+		templates: []runtime.RawExtension{trResourceTemplate(t)},
+		wantCode:  http.StatusOK,
+		want: SelfTestResponse{
+			EventListener: "my-el",
+			Namespace:     namespace,
+			OK:            true,
+			Resources: []SelfTestResult{{
+				APIVersion:   "tekton.dev/v1beta1",
+				Kind:         "TaskRun",
+				Namespace:    namespace,
+				Triggers:     []string{"my-trigger"},
+				OK:           true,
+				Discovery:    "ok",
+				DryRunCreate: "ok",
+			}},
+		},
+	}, {
+		name:      "invalid object",
+		templates: []runtime.RawExtension{trResourceTemplate(t)},
+		createErr: kerrors.NewInvalid(schema.GroupKind{Group: "tekton.dev", Kind: "TaskRun"}, "self-test-", field.ErrorList{field.Required(field.NewPath("spec"), "")}),
+		wantCode:  http.StatusServiceUnavailable,
+		want: SelfTestResponse{
+			EventListener: "my-el",
+			Namespace:     namespace,
+			Resources: []SelfTestResult{{
+				APIVersion:   "tekton.dev/v1beta1",
+				Kind:         "TaskRun",
+				Namespace:    namespace,
+				Triggers:     []string{"my-trigger"},
+				Discovery:    "ok",
+				DryRunCreate: `TaskRun.tekton.dev "self-test-" is invalid: spec: Required value`,
+			}},
+		},
+	}, {
+		name: "unknown kind",
+		templates: []runtime.RawExtension{
+			trResourceTemplate(t),
+			test.RawExtension(t, map[string]interface{}{
+				"apiVersion": "example.dev/v1",
+				"kind":       "Widget",
+				"metadata":   map[string]interface{}{"name": "$(tt.params.name)", "namespace": "$(tt.params.ns)"},
+			}),
+		},
+		wantCode: http.StatusServiceUnavailable,
+		want: SelfTestResponse{
+			EventListener: "my-el",
+			Namespace:     namespace,
+			Resources: []SelfTestResult{{
+				APIVersion: "example.dev/v1",
+				Kind:       "Widget",
+				Namespace:  namespace,
+				Triggers:   []string{"my-trigger"},
+				Discovery:  `error getting kubernetes server resources for apiVersion example.dev/v1: the server could not find the requested resource, GroupVersion "example.dev/v1" not found`,
+			}, {
+				APIVersion:   "tekton.dev/v1beta1",
+				Kind:         "TaskRun",
+				Namespace:    namespace,
+				Triggers:     []string{"my-trigger"},
+				OK:           true,
+				Discovery:    "ok",
+				DryRunCreate: "ok",
+			}},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, dynamicClient := selfTestSink(t, selfTestResources(t, tc.templates...))
+			if tc.createErr != nil {
+				dynamicClient.PrependReactor("create", "*", func(ktesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tc.createErr
+				})
+			}
+			req := httptest.NewRequest(http.MethodGet, SelfTestPath, nil)
+			req.Header.Set("Authorization", "Bearer let-me-in")
+			resp := httptest.NewRecorder()
+			r.HandleSelfTest(resp, req)
+
+			if resp.Code != tc.wantCode {
+				t.Fatalf("HandleSelfTest() status code = %d, want %d: %s", resp.Code, tc.wantCode, resp.Body.String())
+			}
+			var got SelfTestResponse
+			if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("HandleSelfTest() response -want +got: %s", diff)
+			}
+		})
+	}
+}
+
+func TestHandleSelfTest_Rejected(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		method        string
+		authorization string
+		wantCode      int
+	}{{
+		name:     "no token",
+		method:   http.MethodGet,
+		wantCode: http.StatusUnauthorized,
+	}, {
+		name:          "not a bearer token",
+		method:        http.MethodGet,
+		authorization: "let-me-in",
+		wantCode:      http.StatusUnauthorized,
+	}, {
+		name:          "wrong token",
+		method:        http.MethodGet,
+		authorization: "Bearer guess",
+		wantCode:      http.StatusUnauthorized,
+	}, {
+		name:          "post",
+		method:        http.MethodPost,
+		authorization: "Bearer let-me-in",
+		wantCode:      http.StatusMethodNotAllowed,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, _ := selfTestSink(t, selfTestResources(t, trResourceTemplate(t)))
+			req := httptest.NewRequest(tc.method, SelfTestPath, nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			resp := httptest.NewRecorder()
+			r.HandleSelfTest(resp, req)
+
+			if resp.Code != tc.wantCode {
+				t.Errorf("HandleSelfTest() status code = %d, want %d", resp.Code, tc.wantCode)
+			}
+		})
+	}
+}
@@ -25,18 +25,23 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/cloudevents/sdk-go/v2/binding"
 	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
 	"github.com/google/uuid"
 	"github.com/tektoncd/triggers/pkg/apis/triggers"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
 	triggersclientset "github.com/tektoncd/triggers/pkg/client/clientset/versioned"
 	listersv1alpha1 "github.com/tektoncd/triggers/pkg/client/listers/triggers/v1alpha1"
 	listers "github.com/tektoncd/triggers/pkg/client/listers/triggers/v1beta1"
 	"github.com/tektoncd/triggers/pkg/interceptors"
+	celinterceptor "github.com/tektoncd/triggers/pkg/interceptors/cel"
 	"github.com/tektoncd/triggers/pkg/interceptors/webhook"
 	"github.com/tektoncd/triggers/pkg/reconciler/events"
 	"github.com/tektoncd/triggers/pkg/resources"
@@ -44,9 +49,13 @@ import (
 	"github.com/tektoncd/triggers/pkg/template"
 	"github.com/tidwall/sjson"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 	discoveryclient "k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -71,6 +80,97 @@ type Sink struct {
 	Auth                   AuthOverride
 	PayloadValidation      bool
 	CloudEventURI          string
+	// Backpressure, if set, is used to reject events while the EventListener is overloaded
+	Backpressure *Backpressure
+	// EventPool, if set, bounds the number of events processed at a time
+	EventPool *EventPool
+	// InterceptorTimeout, if set, is the total time budget for executing the interceptors of a trigger
+	InterceptorTimeout time.Duration
+	// GuardSecretGetter gets the secrets used by the when expressions of interceptors
+	GuardSecretGetter interceptors.SecretGetter
+	// CreationLimit, if set, bounds the number of resources created per time window
+	CreationLimit *CreationLimit
+	// MaxCreatesPerEvent, if set, bounds the number of resources created for a single event across all triggers
+	MaxCreatesPerEvent int
+	// QuotaRetry, if set, retries the creates rejected because they exceeded a ResourceQuota
+	QuotaRetry *QuotaRetry
+	// CreateTimeout, if set, is the time after which the creation of a resource is abandoned
+	CreateTimeout time.Duration
+	// Creator creates the resources of fired triggers. Defaults to resources.DefaultCreator if nil.
+	Creator resources.Creator
+	// Activity, if set, records the created resources in the status of the EventListener
+	Activity *Activity
+	// AccessLog, if set, writes an access log entry for each request
+	AccessLog *AccessLog
+	// DebugTrace, if set, writes a debug trace of the processing of the events that ask for one or are sampled
+	DebugTrace *DebugTrace
+	// InterceptorMetricNames, if set, bounds the number of interceptor names that tag the interceptor metrics
+	InterceptorMetricNames *MetricNames
+	// ResourceMetricNamespaces, if set, bounds the number of namespaces that tag the metric of the created resources
+	ResourceMetricNamespaces *MetricNames
+	// BasePath, if set, is the path prefix under which a reverse proxy exposes the sink, without a trailing slash
+	BasePath string
+	// EventIDSource, if set, is where the event IDs are taken from instead of being generated
+	EventIDSource *EventIDSource
+	// SelfTest, if set, enables the self-test endpoint
+	SelfTest *SelfTest
+	// ConfigEndpoint, if set, enables the config endpoint
+	ConfigEndpoint *ConfigEndpoint
+	// BatchSize, if set, enables the batch endpoint and is the maximum number of events of its requests
+	BatchSize int
+	// Callback, if set, notifies an external system each time a trigger creates resources
+	Callback *Callback
+	// ResourceEvents, if set, sends a cloud event with the outcome of the creation of each resource
+	ResourceEvents *ResourceEvents
+	// Synchronous, if true, makes the sink respond once all the triggers of an event are processed, with
+	// a status code reflecting their outcome, rather than with 202 Accepted once they are dispatched
+	Synchronous bool
+	// MultiStatus, if true, makes a synchronous sink respond with the outcome of each trigger and of each of
+	// its resources, with 207 Multi-Status if some triggers failed while resources were created
+	MultiStatus bool
+	// RollbackOnFailure, if true, deletes the resources a trigger created for an event, most recent first,
+	// when it fails to create the next ones. Resources whose templates may patch existing resources are kept.
+	RollbackOnFailure bool
+	// CreateBestEffort, if true, makes a trigger that fails to create a resource still create the resources
+	// of its next templates, and reports the failures of each template. Nothing is rolled back.
+	CreateBestEffort bool
+	// Mutation, if set, is the mutation hook that patches the resources before they are created.
+	Mutation *resources.Mutation
+	// ProvenanceLabels, if not nil, are the keys of the provenance labels added to created resources.
+	// All of them are added if nil.
+	ProvenanceLabels []string
+	// FieldValidation, if set, is how the API server handles unknown and duplicate fields in created
+	// resources. Defaults to Warn.
+	FieldValidation string
+	// TrustedProxies are the proxies whose forwarding headers set the client IP of the requests they forward.
+	TrustedProxies TrustedProxies
+	// TrustedProxyHeader is the forwarding header that the TrustedProxies set, X-Forwarded-For or Forwarded.
+	// Defaults to X-Forwarded-For.
+	TrustedProxyHeader string
+	// LabelSanitization, if set, is how the invalid label values of created resources, including the event
+	// IDs taken from requests, are sanitized. Defaults to hash.
+	LabelSanitization string
+	// LogFormat, if json, gives the fields correlating the logs with the events their stable JSON keys,
+	// e.g. eventID rather than /triggers-eventid.
+	LogFormat string
+	// NoMatchPolicy, if error, makes the sink respond to the events that match no triggers with 422
+	// Unprocessable Entity rather than 202 Accepted.
+	NoMatchPolicy string
+	// Discovery tracks whether the discovery client can reach the API server, for the readiness probe.
+	// The sink is always ready if nil.
+	Discovery *DiscoveryReadiness
+	// NoMatchLogLevel is the level at which the events that match no triggers are logged. Defaults to info.
+	NoMatchLogLevel zapcore.Level
+	// AllowedMethods are the HTTP methods accepted by the sink. Defaults to DefaultAllowedMethods if empty.
+	AllowedMethods []string
+	// AllowedContentTypes are the media types accepted by the sink when payload validation is enabled.
+	// Defaults to DefaultAllowedContentTypes and the media types of PayloadParsers if empty.
+	AllowedContentTypes []string
+	// PayloadParsers converts the request bodies into the payloads passed to interceptors and bindings.
+	// Defaults to DefaultPayloadParsers if nil.
+	PayloadParsers *PayloadParsers
+	// ClientProfiles, if set, creates the clients of the triggers that reference a client profile.
+	ClientProfiles *ClientProfiles
 	// WGProcessTriggers keeps track of triggers or triggerGroups currently being processed
 	// Currently only used in tests to wait for all triggers to finish processing
 	WGProcessTriggers *sync.WaitGroup
@@ -100,8 +200,84 @@ type Response struct {
 	EventID string `json:"eventID,omitempty"`
 	// ErrorMessage gives message about Error which occurs during event processing
 	ErrorMessage string `json:"errorMessage,omitempty"`
+	// Triggers are the names of the triggers that the event was dispatched to
+	Triggers []string `json:"triggers,omitempty"`
+	// TriggerGroups are the names of the trigger groups that the event was dispatched to
+	TriggerGroups []string `json:"triggerGroups,omitempty"`
+	// Message gives additional information about how the event was handled
+	Message string `json:"message,omitempty"`
+	// Resources are the resources created for the event, if the sink is synchronous
+	Resources []CreatedResource `json:"resources,omitempty"`
+	// Results are the outcomes of the triggers and trigger groups, if the sink is synchronous and
+	// responds with multi-status
+	Results []TriggerResult `json:"results,omitempty"`
 }
 
+// ErrInterceptorChainTimeout is returned when the interceptors of a trigger do not complete within the
+// EventListener's interceptor timeout.
+var ErrInterceptorChainTimeout = errors.New("interceptor chain timed out")
+
+// ErrCreateTimeout is returned when the API server does not complete the creation of a resource within the
+// EventListener's create timeout.
+var ErrCreateTimeout = errors.New("resource creation timed out")
+
+// ResourceTemplateError is the failure to create the resource of one of the templates of a trigger.
+type ResourceTemplateError struct {
+	// Index is the index of the template in the resources of the trigger.
+	Index int
+	// Kind is the kind of the resource.
+	Kind string
+	// Err is the error creating the resource.
+	Err error
+}
+
+func (e *ResourceTemplateError) Error() string {
+	return fmt.Sprintf("resource template %d (%s): %v", e.Index, e.Kind, e.Err)
+}
+
+// Unwrap returns the error creating the resource.
+func (e *ResourceTemplateError) Unwrap() error {
+	return e.Err
+}
+
+// ResourceTemplateErrors are the failures to create the resources of the templates of a trigger that
+// creates them on a best-effort basis. errors.Is and errors.As match any of them.
+type ResourceTemplateErrors []*ResourceTemplateError
+
+func (e ResourceTemplateErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("failed to create %d resources: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Is returns whether the error of one of the templates is target.
+func (e ResourceTemplateErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error of the templates that matches target.
+func (e ResourceTemplateErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// noTriggersMatchedMessage is the Response message when an event is not dispatched to any trigger
+const noTriggersMatchedMessage = "no triggers matched"
+
+// goTemplateConfigMapTimeout bounds the requests for the ConfigMaps of Go templates.
+const goTemplateConfigMapTimeout = 10 * time.Second
+
 func (r Sink) emitEvents(recorder record.EventRecorder, el *triggersv1.EventListener, eventType string, err error) {
 	if os.Getenv("EL_EVENT") == "enable" {
 		events.Emit(recorder, eventType, el, err)
@@ -110,12 +286,41 @@ func (r Sink) emitEvents(recorder record.EventRecorder, el *triggersv1.EventList
 
 // HandleEvent processes an incoming HTTP event for the event listener.
 func (r Sink) HandleEvent(response http.ResponseWriter, request *http.Request) {
+	keys := r.logKeys()
 	log := r.Logger.With(
-		zap.String("eventlistener", r.EventListenerName),
-		zap.String("namespace", r.EventListenerNamespace),
+		zap.String(keys.eventListener, r.EventListenerName),
+		zap.String(keys.namespace, r.EventListenerNamespace),
 	)
-	eventID := template.UUID()
-	log = log.With(zap.String(triggers.EventIDLabelKey, eventID))
+	// The body is only read once the event holds a worker, so that the queued and rejected events don't
+	// buffer their payloads.
+	release, accepted, reason := r.EventPool.acquire(request.Context())
+	r.recordEventPoolMetrics()
+	if !accepted {
+		log.Warnf("rejecting event, all the event workers are busy: %s", reason)
+		r.recordEventPoolRejectedMetrics(reason)
+		response.Header().Set("Retry-After", strconv.Itoa(int(r.EventPool.retryAfter().Seconds())))
+		response.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+	// The worker is held until all the triggers of the event are processed, even if the response is sent
+	// before.
+	eventWG := &sync.WaitGroup{}
+	defer func() {
+		go func() {
+			eventWG.Wait()
+			release()
+			r.recordEventPoolMetrics()
+		}()
+	}()
+
+	event, readErr := ioutil.ReadAll(request.Body)
+	var eventID string
+	if readErr != nil {
+		eventID = template.UUID()
+	} else {
+		eventID = r.eventID(request, event, log)
+	}
+	log = log.With(zap.String(keys.eventID, eventID))
 
 	elTemp := triggersv1.EventListener{
 		TypeMeta: metav1.TypeMeta{
@@ -150,12 +355,11 @@ func (r Sink) HandleEvent(response http.ResponseWriter, request *http.Request) {
 	r.emitEvents(r.EventRecorder, &elTemp, events.TriggerProcessingStartedV1, nil)
 	r.sendCloudEvents(request.Header, elTemp, eventID, events.TriggerProcessingStartedV1)
 
-	event, err := ioutil.ReadAll(request.Body)
-	if err != nil {
-		log.Errorf("Error reading event body: %s", err)
+	if readErr != nil {
+		log.Errorf("Error reading event body: %s", readErr)
 		r.recordCountMetrics(failTag)
 		response.WriteHeader(http.StatusInternalServerError)
-		r.emitEvents(r.EventRecorder, &elTemp, events.TriggerProcessingFailedV1, err)
+		r.emitEvents(r.EventRecorder, &elTemp, events.TriggerProcessingFailedV1, readErr)
 		r.sendCloudEvents(request.Header, elTemp, eventID, events.TriggerProcessingFailedV1)
 		return
 	}
@@ -172,8 +376,9 @@ func (r Sink) HandleEvent(response http.ResponseWriter, request *http.Request) {
 
 	elUID := string(el.GetUID())
 	log = log.With(zap.String("eventlistenerUID", elUID))
+	trace := debugTraceFrom(request.Context())
+	trace.received(eventID, event)
 
-	log = log.With(zap.String(triggers.EventIDLabelKey, eventID))
 	log.Debugf("handling event with path %s, payload: %s and header: %v", request.URL.Path, string(event), request.Header)
 	trItems, err := r.selectTriggers(el.Spec.NamespaceSelector, el.Spec.LabelSelector)
 	if err != nil {
@@ -193,38 +398,103 @@ func (r Sink) HandleEvent(response http.ResponseWriter, request *http.Request) {
 		r.sendCloudEvents(nil, *el, eventID, events.TriggerProcessingFailedV1)
 		return
 	}
+
+	body := Response{
+		EventListener:    r.EventListenerName,
+		EventListenerUID: elUID,
+		Namespace:        r.EventListenerNamespace,
+		EventID:          eventID,
+	}
+
+	// The triggers of the event share the count of the resources created for it.
+	ctx := context.WithValue(request.Context(), eventCreatesKey{}, r.newEventCreates())
+	request = request.WithContext(context.WithValue(ctx, jsonNumbersKey{}, el.Spec.JSONNumbers))
+	outcomes := &triggerOutcomes{}
 	r.WGProcessTriggers.Add(len(mergedTriggers))
+	eventWG.Add(len(mergedTriggers))
 	for _, t := range mergedTriggers {
+		body.Triggers = append(body.Triggers, t.Name)
 		go func(t triggersv1.Trigger) {
 			defer r.WGProcessTriggers.Done()
+			defer eventWG.Done()
 			localRequest := request.Clone(request.Context())
 			emptyExtensions := make(map[string]interface{})
-			r.processTrigger(t, el, localRequest, event, eventID, log, emptyExtensions)
+			r.processTrigger(t, el, localRequest, event, eventID, log, emptyExtensions, outcomes)
 		}(*t)
 	}
 
 	// Process grouped triggers
 	for _, group := range el.Spec.TriggerGroups {
+		body.TriggerGroups = append(body.TriggerGroups, group.Name)
 		r.WGProcessTriggers.Add(1)
+		eventWG.Add(1)
 		go func(g triggersv1.EventListenerTriggerGroup) {
 			defer r.WGProcessTriggers.Done()
+			defer eventWG.Done()
 			localRequest := request.Clone(request.Context())
-			r.processTriggerGroups(g, el, localRequest, event, eventID, log, r.WGProcessTriggers)
+			r.processTriggerGroups(g, el, localRequest, event, eventID, log, r.WGProcessTriggers, outcomes)
 		}(group)
 	}
 
-	r.recordCountMetrics(successTag)
+	rec := accessRecordFrom(request.Context())
+	rec.dispatched(eventID, body.Triggers, body.TriggerGroups)
+	noMatch := len(body.Triggers) == 0 && len(body.TriggerGroups) == 0
+	if noMatch {
+		body.Message = noTriggersMatchedMessage
+		log.Desugar().Log(r.NoMatchLogLevel, noTriggersMatchedMessage+" for event")
+		r.recordNoTriggersMatchedMetrics()
+	} else {
+		// Log a summary of the event's fate once all of its triggers are processed
+		trace.dispatched()
+		r.WGProcessTriggers.Add(1)
+		go func() {
+			defer r.WGProcessTriggers.Done()
+			eventWG.Wait()
+			names := outcomes.list()
+			rec.processed(names)
+			trace.processed(outcomes)
+			if len(names) > 0 {
+				log.Infof("event processing completed, fired triggers: %s", strings.Join(names, ", "))
+			} else {
+				log.Info("event processing completed, no triggers fired")
+			}
+		}()
+	}
 
-	body := Response{
-		EventListener:    r.EventListenerName,
-		EventListenerUID: elUID,
-		Namespace:        r.EventListenerNamespace,
-		EventID:          eventID,
+	response.Header().Set(EventIDHeader, eventID)
+	if len(body.Triggers) > 0 {
+		response.Header().Set(TriggerHeader, strings.Join(body.Triggers, ","))
 	}
+	status := http.StatusAccepted
+	if r.Synchronous {
+		eventWG.Wait()
+		for k, v := range outcomes.responseHeaders(log) {
+			response.Header()[k] = v
+		}
+		if r.MultiStatus {
+			status, body.ErrorMessage = outcomes.multiStatus()
+			body.Results = outcomes.results()
+		} else {
+			status, body.ErrorMessage = outcomes.status()
+		}
+		body.Resources = outcomes.createdResources()
+		if status == http.StatusCreated {
+			if p, err := resources.APIPath(body.Resources[0].object(), r.DiscoveryClient); err != nil {
+				log.Warnf("failed to get the API path of the created %s %s: %v", body.Resources[0].Kind, body.Resources[0].Name, err)
+			} else {
+				response.Header().Set("Location", p)
+			}
+		}
+	}
+	if noMatch && r.NoMatchPolicy == triggers.NoMatchError {
+		status, body.ErrorMessage = http.StatusUnprocessableEntity, noTriggersMatchedMessage
+	}
+
+	r.recordCountMetrics(successTag)
 
 	msg := cehttp.NewMessageFromHttpRequest(request)
 	if encoding := msg.ReadEncoding(); encoding == binding.EncodingUnknown {
-		response.WriteHeader(http.StatusAccepted)
+		response.WriteHeader(status)
 		response.Header().Set("Content-Type", "application/json")
 		if err := json.NewEncoder(response).Encode(body); err != nil {
 			log.Errorf("failed to write back sink response: %v", err)
@@ -246,7 +516,7 @@ func (r Sink) HandleEvent(response http.ResponseWriter, request *http.Request) {
 			}
 		}()
 
-		if err := cehttp.WriteResponseWriter(request.Context(), eventResponse, http.StatusAccepted, response); err != nil {
+		if err := cehttp.WriteResponseWriter(request.Context(), eventResponse, status, response); err != nil {
 			log.Errorf("failed to write back cloud event sink response: %v", err)
 			r.emitEvents(r.EventRecorder, el, events.TriggerProcessingFailedV1, err)
 			r.sendCloudEvents(nil, *el, eventID, events.TriggerProcessingFailedV1)
@@ -298,10 +568,21 @@ func (r Sink) merge(et []triggersv1.EventListenerTrigger, trItems []*triggersv1.
 					Name:      t.Name,
 					Namespace: r.EventListenerNamespace},
 				Spec: triggersv1.TriggerSpec{
-					ServiceAccountName: t.ServiceAccountName,
-					Bindings:           t.Bindings,
-					Template:           *t.Template,
-					Interceptors:       t.Interceptors,
+					ServiceAccountName:  t.ServiceAccountName,
+					Finalizer:           t.Finalizer,
+					PromotedExtensions:  t.PromotedExtensions,
+					DefaultNamespace:    t.DefaultNamespace,
+					NamespaceParam:      t.NamespaceParam,
+					DefaultAPIVersion:   t.DefaultAPIVersion,
+					DefaultKind:         t.DefaultKind,
+					ResourceLabels:      t.ResourceLabels,
+					ResourceAnnotations: t.ResourceAnnotations,
+					Overlays:            t.Overlays,
+					ResourceTTL:         t.ResourceTTL,
+					ClientProfile:       t.ClientProfile,
+					Bindings:            t.Bindings,
+					Template:            *t.Template,
+					Interceptors:        t.Interceptors,
 				},
 			})
 		default:
@@ -311,29 +592,38 @@ func (r Sink) merge(et []triggersv1.EventListenerTrigger, trItems []*triggersv1.
 	return triggers, nil
 }
 
-func (r Sink) processTriggerGroups(g triggersv1.EventListenerTriggerGroup, el *triggersv1.EventListener, request *http.Request, event []byte, eventID string, eventLog *zap.SugaredLogger, wg *sync.WaitGroup) {
-	log := eventLog.With(zap.String(triggers.TriggerGroupLabelKey, g.Name))
+func (r Sink) processTriggerGroups(g triggersv1.EventListenerTriggerGroup, el *triggersv1.EventListener, request *http.Request, event []byte, eventID string, eventLog *zap.SugaredLogger, wg *sync.WaitGroup, outcomes *triggerOutcomes) {
+	keys := r.logKeys()
+	log := eventLog.With(zap.String(keys.triggerGroup, g.Name))
 
 	extensions := map[string]interface{}{}
-	payload, header, resp, err := r.ExecuteInterceptors(g.Interceptors, request, event, log, eventID, fmt.Sprintf("namespaces/%s/triggerGroups/%s", r.EventListenerNamespace, g.Name), r.EventListenerNamespace, extensions)
+	result, err := r.ExecuteInterceptorChain(g.Interceptors, request, event, log, eventID, fmt.Sprintf("namespaces/%s/triggerGroups/%s", r.EventListenerNamespace, g.Name), r.EventListenerNamespace, extensions)
+	logInterceptorResults(log, result)
+	debugTraceFrom(request.Context()).triggerGroup(g.Name).interceptors(result)
 	if err != nil {
-		log.Error(err)
+		log.With(keys.outcome, failedOutcome).Error(err)
+		outcomes.fail(g.Name, nil, err)
 		return
 	}
+	payload, header, resp := result.Body, result.Header, result.Response
 	if resp != nil {
+		outcomes.setHeaders(g.Name, resp.ResponseHeaders)
 		if resp.Extensions != nil {
 			for k, v := range resp.Extensions {
 				extensions[k] = v
 			}
 		}
 		if !resp.Continue {
-			eventLog.Infof("interceptor stopped trigger processing: %v", resp.Status.Err())
+			log.With(keys.outcome, rejectedOutcome).Infof("interceptor stopped trigger processing: %v", resp.Status.Err())
+			outcomes.reject(g.Name, resp.Status)
 			return
 		}
 	}
 
 	trItems, err := r.selectTriggers(g.TriggerSelector.NamespaceSelector, g.TriggerSelector.LabelSelector)
 	if err != nil {
+		log.With(keys.outcome, failedOutcome).Error(err)
+		outcomes.fail(g.Name, nil, err)
 		return
 	}
 
@@ -343,16 +633,30 @@ func (r Sink) processTriggerGroups(g triggersv1.EventListenerTriggerGroup, el *t
 	triggerReq.Header = header
 	triggerReq.Body = ioutil.NopCloser(bytes.NewBuffer(payload))
 
+	// groupWG tracks the triggers of this group so that the caller knows when the group is fully processed
+	groupWG := &sync.WaitGroup{}
 	wg.Add(len(trItems))
+	groupWG.Add(len(trItems))
 	for _, t := range trItems {
 		go func(t triggersv1.Trigger) {
 			defer wg.Done()
+			defer groupWG.Done()
 			// TODO(dibyom): We might be able to get away with only cloning if necessary
 			// i.e. if there are interceptors and iff those interceptors will modify the body/header (i.e. webhook)
 			localRequest := triggerReq.Clone(triggerReq.Context())
-			r.processTrigger(t, el, localRequest, event, eventID, log, extensions)
+			r.processTrigger(t, el, localRequest, event, eventID, log, extensions, outcomes)
 		}(*t)
 	}
+	groupWG.Wait()
+}
+
+type jsonNumbersKey struct{}
+
+// jsonNumbersFrom returns how the EventListener of the request with the given context decodes the numbers
+// of the events, which the interceptors are told.
+func jsonNumbersFrom(ctx context.Context) triggersv1.JSONNumbers {
+	n, _ := ctx.Value(jsonNumbersKey{}).(triggersv1.JSONNumbers)
+	return n
 }
 
 func (r Sink) selectTriggers(namespaceSelector triggersv1.NamespaceSelector, labelSelector *metav1.LabelSelector) ([]*triggersv1.Trigger, error) {
@@ -404,146 +708,287 @@ func (r Sink) selectTriggers(namespaceSelector triggersv1.NamespaceSelector, lab
 	return trItems, nil
 }
 
-func (r Sink) processTrigger(t triggersv1.Trigger, el *triggersv1.EventListener, request *http.Request, event []byte, eventID string, eventLog *zap.SugaredLogger, extensions map[string]interface{}) {
-	log := eventLog.With(zap.String(triggers.TriggerLabelKey, t.Name))
+// processTrigger runs the interceptors of the trigger and creates its resources, recording whether the
+// trigger fired, i.e. its resources were created, in outcomes.
+func (r Sink) processTrigger(t triggersv1.Trigger, el *triggersv1.EventListener, request *http.Request, event []byte, eventID string, eventLog *zap.SugaredLogger, extensions map[string]interface{}, outcomes *triggerOutcomes) {
+	keys := r.logKeys()
+	log := eventLog.With(zap.String(keys.trigger, t.Name))
+	trace := debugTraceFrom(request.Context()).trigger(t.Name)
 
-	finalPayload, header, iresp, err := r.ExecuteTriggerInterceptors(t, request, event, log, eventID, extensions)
+	result, err := r.ExecuteTriggerInterceptorChain(t, request, event, log, eventID, extensions)
+	logInterceptorResults(log, result)
+	trace.interceptors(result)
 	if err != nil {
-		log.Error(err)
+		log.With(keys.outcome, failedOutcome).Error(err)
+		outcomes.fail(t.Name, nil, err)
 		return
 	}
+	finalPayload, header, iresp := result.Body, result.Header, result.Response
 
 	if iresp != nil {
+		outcomes.setHeaders(t.Name, iresp.ResponseHeaders)
 		if !iresp.Continue {
-			log.Infof("interceptor stopped trigger processing: %v", iresp.Status.Err())
+			log.With(keys.outcome, rejectedOutcome).Infof("interceptor stopped trigger processing: %v", iresp.Status.Err())
+			outcomes.reject(t.Name, iresp.Status)
 			return
 		}
 	}
 
-	rt, err := template.ResolveTrigger(t,
+	if iresp != nil && iresp.Extensions != nil {
+		extensions = iresp.Extensions
+	}
+	routed, ttName := template.RouteTrigger(t, extensions)
+	if ttName != "" {
+		log = log.With(zap.String("triggerTemplate", ttName))
+		log.Infof("routed to TriggerTemplate %s", ttName)
+	}
+	rt, err := template.ResolveTrigger(routed,
 		r.TriggerBindingLister.TriggerBindings(t.Namespace).Get,
 		r.ClusterTriggerBindingLister.Get,
 		r.TriggerTemplateLister.TriggerTemplates(t.Namespace).Get)
 	if err != nil {
-		log.Error(err)
+		log.With(keys.outcome, failedOutcome).Error(err)
+		outcomes.fail(t.Name, nil, err)
 		return
 	}
-	if iresp != nil && iresp.Extensions != nil {
-		extensions = iresp.Extensions
-	}
-	params, err := template.ResolveParams(rt, finalPayload, header, extensions, template.NewTriggerContext(eventID))
+	triggerContext := template.NewTriggerContext(eventID).WithEventListener(el)
+	params, err := template.ResolveParams(rt, finalPayload, header, request.URL.Query(), extensions, triggerContext)
 	if err != nil {
-		log.Error(err)
+		log.With(keys.outcome, failedOutcome).Error(err)
+		outcomes.fail(t.Name, nil, err)
 		return
 	}
 
 	log.Infof("ResolvedParams : %+v", params)
-	resources := template.ResolveResources(rt.TriggerTemplate, params)
+	trace.resolved(params)
+	selected, err := template.SelectResourceTemplate(rt.TriggerTemplate, params)
+	if err != nil {
+		log.With(keys.outcome, failedOutcome).Error(err)
+		outcomes.fail(t.Name, nil, err)
+		return
+	}
+	labels := t.Spec.ResourceLabels
+	if selected >= 0 {
+		log = log.With(zap.Int("resourceTemplate", selected))
+		log.Infof("selected resource template %d", selected)
+		labels = withLabel(labels, triggers.GroupName+triggers.ResourceTemplateLabelKey, strconv.Itoa(selected))
+	}
+	resources, err := template.ResolveSelectedResources(rt.TriggerTemplate, params, selected)
+	if err != nil {
+		log.With(keys.outcome, failedOutcome).Error(err)
+		outcomes.fail(t.Name, nil, err)
+		return
+	}
+	rendered, err := template.RenderGoTemplates(rt.TriggerTemplate, params, finalPayload, header, request.URL.Query(), extensions, triggerContext,
+		r.goTemplateConfigMaps(t.Namespace))
+	if err != nil {
+		log.With(keys.outcome, failedOutcome).Error(err)
+		outcomes.fail(t.Name, nil, err)
+		return
+	}
+	resources = append(resources, rendered...)
+	resources, err = template.ApplyOverlays(resources, t.Spec.Overlays, params, rt.TriggerTemplate.Spec.Params)
+	if err != nil {
+		log.With(keys.outcome, failedOutcome).Error(err)
+		outcomes.fail(t.Name, nil, err)
+		return
+	}
+	trace.rendered(resources)
 
-	if err := r.CreateResources(t.Namespace, t.Spec.ServiceAccountName, resources, t.Name, eventID, log); err != nil {
-		log.Error(err)
+	namespace, err := defaultNamespace(t, params)
+	if err != nil {
+		log.With(keys.outcome, failedOutcome).Error(err)
+		outcomes.fail(t.Name, nil, err)
+		return
+	}
+	ttl, ttlAnnotation, err := resourceTTL(t, params)
+	if err != nil {
+		log.With(keys.outcome, failedOutcome).Error(err)
+		outcomes.fail(t.Name, nil, err)
+		return
+	}
+	meta := resourceMetadata{
+		finalizer:        t.Spec.Finalizer,
+		labels:           labels,
+		annotations:      t.Spec.ResourceAnnotations,
+		ttl:              ttl,
+		ttlAnnotation:    ttlAnnotation,
+		defaultType:      metav1.TypeMeta{APIVersion: t.Spec.DefaultAPIVersion, Kind: t.Spec.DefaultKind},
+		kindPolicy:       el.Spec.ResourceKinds,
+		createStrategies: el.Spec.CreateStrategies,
+		clientProfile:    t.Spec.ClientProfile,
+	}
+	if creates := eventCreatesFrom(request.Context()); !creates.reserve(len(resources)) {
+		err := fmt.Errorf("skipping creation of %d resources for trigger %s, the event can't create more than %d resources: %w", len(resources), t.Name, creates.max, ErrEventCreateLimitExceeded)
+		log.With(keys.outcome, failedOutcome).Error(err)
+		r.recordEventCreateLimitMetrics(t.Name)
+		r.emitEvents(r.EventRecorder, el, events.TriggerProcessingFailedV1, err)
+		r.sendCloudEvents(request.Header, *el, eventID, events.TriggerProcessingFailedV1)
+		outcomes.fail(t.Name, nil, err)
+		return
+	}
+	created, err := r.createResources(t.Namespace, namespace, t.Spec.ServiceAccountName, meta, resources, t.Name, eventID, log)
+	if err != nil {
+		log.With(keys.outcome, failedOutcome).Error(err)
+		if errors.Is(err, ErrCreationLimitExceeded) {
+			r.emitEvents(r.EventRecorder, el, events.TriggerProcessingThrottledV1, err)
+			r.sendCloudEvents(request.Header, *el, eventID, events.TriggerProcessingThrottledV1)
+		}
+		outcomes.fail(t.Name, created, err)
 		return
 	}
 	go r.recordResourceCreation(resources)
 	r.emitEvents(r.EventRecorder, el, events.TriggerProcessingSuccessfulV1, nil)
 	r.sendCloudEvents(request.Header, *el, eventID, events.TriggerProcessingSuccessfulV1)
-
+	log.With(keys.outcome, firedOutcome).Infof("trigger %s fired", t.Name)
+	r.notifyCallback(t.Name, eventID, finalPayload, created, log)
+	outcomes.fire(t.Name, created)
 }
 
 func (r Sink) ExecuteTriggerInterceptors(t triggersv1.Trigger, in *http.Request, event []byte, log *zap.SugaredLogger, eventID string, extensions map[string]interface{}) ([]byte, http.Header, *triggersv1.InterceptorResponse, error) {
 	return r.ExecuteInterceptors(t.Spec.Interceptors, in, event, log, eventID, fmt.Sprintf("namespaces/%s/triggers/%s", t.Namespace, t.Name), t.Namespace, extensions)
 }
 
+// ExecuteTriggerInterceptorChain executes the interceptors of the Trigger like ExecuteInterceptorChain.
+func (r Sink) ExecuteTriggerInterceptorChain(t triggersv1.Trigger, in *http.Request, event []byte, log *zap.SugaredLogger, eventID string, extensions map[string]interface{}) (*InterceptorChainResult, error) {
+	return r.ExecuteInterceptorChain(t.Spec.Interceptors, in, event, log, eventID, fmt.Sprintf("namespaces/%s/triggers/%s", t.Namespace, t.Name), t.Namespace, extensions)
+}
+
+// clientCertificate returns the identity of the verified certificate that the sender of the
+// request presented, or nil if it did not present one or the sink does not verify client certificates.
+func clientCertificate(in *http.Request) *triggersv1.ClientCertificate {
+	if in.TLS == nil || len(in.TLS.VerifiedChains) == 0 || len(in.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	cert := in.TLS.VerifiedChains[0][0]
+	var uris []string
+	for _, u := range cert.URIs {
+		uris = append(uris, u.String())
+	}
+	return &triggersv1.ClientCertificate{
+		Subject:        cert.Subject.String(),
+		CommonName:     cert.Subject.CommonName,
+		DNSNames:       cert.DNSNames,
+		URIs:           uris,
+		EmailAddresses: cert.EmailAddresses,
+	}
+}
+
+// clientIP returns the IP address of the sender of the request, taken from the TrustedProxyHeader of the
+// TrustedProxies.
+func (r Sink) clientIP(in *http.Request) string {
+	return r.TrustedProxies.ClientIP(in, r.TrustedProxyHeader)
+}
+
 // ExecuteInterceptor executes all interceptors for the Trigger and returns back the body, header, and InterceptorResponse to use.
 // When TEP-0022 is fully implemented, this function will only return the InterceptorResponse and error.
 func (r Sink) ExecuteInterceptors(trInt []*triggersv1.TriggerInterceptor, in *http.Request, event []byte, log *zap.SugaredLogger, eventID string, triggerID string, namespace string, extensions map[string]interface{}) ([]byte, http.Header, *triggersv1.InterceptorResponse, error) {
+	result, err := r.ExecuteInterceptorChain(trInt, in, event, log, eventID, triggerID, namespace, extensions)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return result.Body, result.Header, result.Response, nil
+}
+
+// ExecuteInterceptorChain executes all interceptors for the Trigger and returns the result of the chain along with the
+// result of each interceptor executed. If an interceptor fails, the result of the interceptors executed so far is
+// returned along with the error.
+func (r Sink) ExecuteInterceptorChain(trInt []*triggersv1.TriggerInterceptor, in *http.Request, event []byte, log *zap.SugaredLogger, eventID string, triggerID string, namespace string, extensions map[string]interface{}) (*InterceptorChainResult, error) {
 	if len(trInt) == 0 {
-		return event, in.Header, nil, nil
+		return &InterceptorChainResult{Body: event, Header: in.Header}, nil
+	}
+
+	ctx := context.Background()
+	if r.InterceptorTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.InterceptorTimeout)
+		defer cancel()
+	}
+	// chainErr reports errors caused by the chain exceeding its time budget as ErrInterceptorChainTimeout
+	chainErr := func(err error) error {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			r.recordInterceptorTimeoutMetrics()
+			return fmt.Errorf("%w after %s: %v", ErrInterceptorChainTimeout, r.InterceptorTimeout, err)
+		}
+		return err
 	}
 
 	// request is the request sent to the interceptors in the chain. Each interceptor can set the InterceptorParams field
 	// or add to the Extensions
 	request := triggersv1.InterceptorRequest{
 		Body:       string(event),
+		RawBody:    string(rawPayloadFrom(in.Context())),
 		Header:     in.Header.Clone(),
 		Extensions: extensions,
 		Context: &triggersv1.TriggerContext{
-			EventURL: in.URL.String(),
+			EventURL: r.externalURL(in.URL).String(),
 			EventID:  eventID,
 			// t.Name might not be fully accurate until we get rid of triggers inlined within EventListener
-			TriggerID: triggerID,
+			TriggerID:   triggerID,
+			ClientCert:  clientCertificate(in),
+			ClientIP:    r.clientIP(in),
+			JSONNumbers: jsonNumbersFrom(in.Context()),
 		},
 	}
 
+	traced := debugTraceFrom(in.Context()) != nil
+	result := &InterceptorChainResult{}
+	// responseHeaders are the response headers set by the interceptors executed so far, the later ones taking
+	// precedence.
+	var responseHeaders map[string]string
 	for _, i := range trInt {
-		if i.Webhook != nil { // Old style interceptor
-			body, err := extendBodyWithExtensions([]byte(request.Body), request.Extensions)
-			if err != nil {
-				return nil, nil, nil, fmt.Errorf("could not merge extensions with body: %w", err)
-			}
-			req := &http.Request{
-				Method: http.MethodPost,
-				Header: request.Header,
-				URL:    in.URL,
-				Body:   ioutil.NopCloser(bytes.NewBuffer(body)),
-			}
-			interceptor := webhook.NewInterceptor(i.Webhook, r.HTTPClient, namespace, log)
-			res, err := interceptor.ExecuteTrigger(req)
-			if err != nil {
-				return nil, nil, nil, err
-			}
-
-			payload, err := ioutil.ReadAll(res.Body)
-			if err != nil {
-				return nil, nil, nil, fmt.Errorf("error reading webhook interceptor response body: %w", err)
-			}
-			defer res.Body.Close()
-			// Set the next request to be the output of the last response to enable
-			// request chaining.
-			request.Header = res.Header.Clone()
-			request.Body = string(payload)
-			continue
+		if err := ctx.Err(); err != nil {
+			return result, chainErr(err)
 		}
-		request.InterceptorParams = interceptors.GetInterceptorParams(i)
-
-		var url *apis.URL
-		if i.Ref.Kind == triggersv1.ClusterInterceptorKind {
-			ic, err := r.ClusterInterceptorLister.Get(i.GetName())
-			if err != nil {
-				return nil, nil, nil, fmt.Errorf("url resolution failed for interceptor %s with: %w", i.GetName(), err)
-			}
-			if ic.Status.Address != nil && ic.Status.Address.URL != nil {
-				url = ic.Status.Address.URL
-			} else if url, err = ic.ResolveAddress(); err != nil {
-				return nil, nil, nil, fmt.Errorf("url resolution failed for interceptor %s with: %w", i.GetName(), err)
-			}
-			if err != nil {
-				return nil, nil, nil, fmt.Errorf("could not resolve clusterinterceptor URL: %w", err)
-			}
-		} else if i.Ref.Kind == triggersv1.NamespacedInterceptorKind {
-			if r.InterceptorLister == nil {
-				r.Logger.Debugf("nil lister")
-			}
-			ic, err := r.InterceptorLister.Interceptors(r.EventListenerNamespace).Get(i.GetName())
-			if err != nil {
-				return nil, nil, nil, fmt.Errorf("url resolution failed for interceptor %s with: %w", i.GetName(), err)
-			}
-			if addr := ic.Status.Address; addr != nil && addr.URL != nil {
-				url = addr.URL
-			} else if url, err = ic.ResolveAddress(); err != nil {
-				return nil, nil, nil, fmt.Errorf("url resolution failed for interceptor %s with: %w", i.GetName(), err)
-			}
-			if err != nil {
-				return nil, nil, nil, fmt.Errorf("could not resolve clusterinterceptor URL: %w", err)
+		var sent *triggersv1.InterceptorRequest
+		if traced {
+			sent = snapshotInterceptorRequest(&request)
+		}
+		stage := InterceptorResult{
+			Name:    interceptorName(i),
+			Request: sent,
+		}
+		start := time.Now()
+		if i.When != "" {
+			run, err := r.evaluateWhen(ctx, i.When, &request)
+			if err != nil || !run {
+				stage.Type = interceptorType(i)
+				stage.Duration = time.Since(start)
+				if err != nil {
+					stage.Err = chainErr(fmt.Errorf("error evaluating the when expression of interceptor %s: %w", stage.Name, err))
+				} else {
+					stage.Continue = true
+					stage.Skipped = true
+				}
+				result.Interceptors = append(result.Interceptors, stage)
+				r.recordInterceptorMetrics(stage)
+				if stage.Err != nil {
+					return result, stage.Err
+				}
+				continue
 			}
 		}
-
-		interceptorResponse, err := interceptors.Execute(context.Background(), r.HTTPClient, &request, url.String())
+		interceptorResponse, err := r.executeInterceptor(ctx, i, &request, in, namespace, log, &stage)
+		stage.Duration = time.Since(start)
+		if sent != nil {
+			sent.InterceptorParams = request.InterceptorParams
+		}
 		if err != nil {
-			return nil, nil, nil, err
+			stage.Err = chainErr(err)
+			result.Interceptors = append(result.Interceptors, stage)
+			r.recordInterceptorMetrics(stage)
+			return result, stage.Err
 		}
+		stage.Continue = interceptorResponse.Continue
+		stage.Extensions = interceptorResponse.Extensions
+		stage.Status = interceptorResponse.Status
+		result.Interceptors = append(result.Interceptors, stage)
+		r.recordInterceptorMetrics(stage)
+		responseHeaders = mergeResponseHeaders(responseHeaders, interceptorResponse.ResponseHeaders)
 		if !interceptorResponse.Continue {
-			return nil, nil, interceptorResponse, nil
+			interceptorResponse.ResponseHeaders = responseHeaders
+			result.Response = interceptorResponse
+			return result, nil
 		}
 
 		if interceptorResponse.Extensions != nil {
@@ -555,36 +1000,469 @@ func (r Sink) ExecuteInterceptors(trInt []*triggersv1.TriggerInterceptor, in *ht
 		// Clear interceptorParams for the next interceptor in chain
 		request.InterceptorParams = map[string]interface{}{}
 	}
-	return []byte(request.Body), request.Header, &triggersv1.InterceptorResponse{
-		Continue:   true,
-		Extensions: request.Extensions,
-	}, nil
+	result.Body = []byte(request.Body)
+	result.Header = request.Header
+	result.Response = &triggersv1.InterceptorResponse{
+		Continue:        true,
+		Extensions:      request.Extensions,
+		ResponseHeaders: responseHeaders,
+	}
+	return result, nil
+}
+
+// executeInterceptor executes a single interceptor of a chain with request, recording its type and the duration
+// of the request to it in stage. Old style webhook interceptors replace the body and header of request with their
+// response instead of returning extensions.
+func (r Sink) executeInterceptor(ctx context.Context, i *triggersv1.TriggerInterceptor, request *triggersv1.InterceptorRequest, in *http.Request, namespace string, log *zap.SugaredLogger, stage *InterceptorResult) (*triggersv1.InterceptorResponse, error) {
+	if i.Webhook != nil { // Old style interceptor
+		stage.Type = interceptorType(i)
+		body, err := extendBodyWithExtensions([]byte(request.Body), request.Extensions)
+		if err != nil {
+			return nil, fmt.Errorf("could not merge extensions with body: %w", err)
+		}
+		req := (&http.Request{
+			Method: http.MethodPost,
+			Header: request.Header,
+			URL:    r.externalURL(in.URL),
+			Body:   ioutil.NopCloser(bytes.NewBuffer(body)),
+		}).WithContext(ctx)
+		interceptor := webhook.NewInterceptor(i.Webhook, r.HTTPClient, namespace, log)
+		start := time.Now()
+		res, err := interceptor.ExecuteTrigger(req)
+		stage.RequestDuration = time.Since(start)
+		if err != nil {
+			return nil, err
+		}
+
+		payload, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return nil, fmt.Errorf("error reading webhook interceptor response body: %w", err)
+		}
+		defer res.Body.Close()
+		// Set the next request to be the output of the last response to enable
+		// request chaining.
+		request.Header = res.Header.Clone()
+		request.Body = string(payload)
+		return &triggersv1.InterceptorResponse{Continue: true}, nil
+	}
+	request.InterceptorParams = interceptors.GetInterceptorParams(i)
+
+	stage.Type = interceptorType(i)
+	url, defaults, err := r.resolveInterceptor(i)
+	if err != nil {
+		return nil, err
+	}
+	request.InterceptorParams = withDefaultParams(defaults, request.InterceptorParams)
+	if stage.Type == clusterInterceptorType && isCoreInterceptor(url) {
+		stage.Type = coreInterceptorType
+	}
+
+	start := time.Now()
+	defer func() { stage.RequestDuration = time.Since(start) }()
+	return interceptors.Execute(ctx, r.HTTPClient, request, url.String())
 }
 
-func (r Sink) CreateResources(triggerNS, sa string, res []json.RawMessage, triggerName, eventID string, log *zap.SugaredLogger) error {
-	discoveryClient := r.DiscoveryClient
-	dynamicClient := r.DynamicClient
+// evaluateWhen evaluates the when expression of an interceptor against the request the interceptor would be sent,
+// and returns whether the interceptor is executed.
+func (r Sink) evaluateWhen(ctx context.Context, expr string, request *triggersv1.InterceptorRequest) (bool, error) {
+	val, err := celinterceptor.Evaluate(ctx, r.GuardSecretGetter, expr, request)
+	if err != nil {
+		return false, err
+	}
+	run, ok := val.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("when expression returned %s, not a bool", val.Type().TypeName())
+	}
+	return run, nil
+}
+
+// resolveInterceptor returns the URL of the ClusterInterceptor or the Interceptor that i refers to, and its
+// default params.
+func (r Sink) resolveInterceptor(i *triggersv1.TriggerInterceptor) (*apis.URL, []v1alpha1.InterceptorParams, error) {
+	var url *apis.URL
+	var defaults []v1alpha1.InterceptorParams
 	var err error
-	if len(sa) > 0 {
-		// So at start up the discovery and dynamic clients are created using the in cluster config
-		// of this pod (i.e. using the credentials of the serviceaccount associated with the EventListener)
+	switch i.Ref.Kind {
+	case triggersv1.ClusterInterceptorKind:
+		ic, getErr := r.ClusterInterceptorLister.Get(i.GetName())
+		if getErr != nil {
+			return nil, nil, fmt.Errorf("url resolution failed for interceptor %s with: %w", i.GetName(), getErr)
+		}
+		defaults = ic.Spec.Params
+		if ic.Status.Address != nil && ic.Status.Address.URL != nil {
+			url = ic.Status.Address.URL
+		} else {
+			url, err = ic.ResolveAddress()
+		}
+	case triggersv1.NamespacedInterceptorKind:
+		if r.InterceptorLister == nil {
+			r.Logger.Debugf("nil lister")
+		}
+		ic, getErr := r.InterceptorLister.Interceptors(r.EventListenerNamespace).Get(i.GetName())
+		if getErr != nil {
+			return nil, nil, fmt.Errorf("url resolution failed for interceptor %s with: %w", i.GetName(), getErr)
+		}
+		defaults = ic.Spec.Params
+		if addr := ic.Status.Address; addr != nil && addr.URL != nil {
+			url = addr.URL
+		} else {
+			url, err = ic.ResolveAddress()
+		}
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("url resolution failed for interceptor %s with: %w", i.GetName(), err)
+	}
+	return url, defaults, nil
+}
+
+// isCoreInterceptor returns whether the interceptor at u is one of the core interceptors of Triggers.
+func isCoreInterceptor(u *apis.URL) bool {
+	host := u.URL().Hostname()
+	return host == interceptors.CoreInterceptorsHost || strings.HasPrefix(host, interceptors.CoreInterceptorsHost+".")
+}
+
+// withDefaultParams adds the default params of an interceptor to the params set by the Trigger,
+// which take precedence.
+func withDefaultParams(defaults []v1alpha1.InterceptorParams, params map[string]interface{}) map[string]interface{} {
+	for _, p := range defaults {
+		if _, ok := params[p.Name]; !ok {
+			params[p.Name] = p.Value
+		}
+	}
+	return params
+}
+
+func (r Sink) CreateResources(triggerNS, sa, finalizer string, res []json.RawMessage, triggerName, eventID string, log *zap.SugaredLogger) error {
+	_, err := r.createResources(triggerNS, triggerNS, sa, resourceMetadata{finalizer: finalizer}, res, triggerName, eventID, log)
+	return err
+}
+
+// resourceMetadata is the metadata a trigger adds to the resources it creates, in addition to the
+// metadata specified in their templates.
+type resourceMetadata struct {
+	finalizer   string
+	labels      map[string]string
+	annotations map[string]string
+	// ttl is the time to live of the resources after they complete, annotated with ttlAnnotation, if positive.
+	ttl           time.Duration
+	ttlAnnotation string
+	// defaultType is the apiVersion and kind of the resources whose templates specify neither.
+	defaultType metav1.TypeMeta
+	// kindPolicy is the policy of the EventListener for the kinds of resources it can create.
+	kindPolicy *triggersv1.ResourceKindPolicy
+	// createStrategies are the create strategies of the EventListener for the kinds of resources.
+	createStrategies []triggersv1.ResourceCreateStrategy
+	// clientProfile is the client profile of the clients creating the resources, if any.
+	clientProfile string
+}
+
+// withLabel returns a copy of labels with the label key set to value.
+func withLabel(labels map[string]string, key, value string) map[string]string {
+	out := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		out[k] = v
+	}
+	out[key] = value
+	return out
+}
+
+// createResources creates the resources like CreateResources, in defaultNS if their templates don't
+// specify a namespace, with the metadata of the trigger, and returns the ones created, including those
+// created before an error, which is the ResourceTemplateError of the template that failed. With
+// CreateBestEffort, the resources of all the templates are created, and the error is the
+// ResourceTemplateErrors of those that failed.
+func (r Sink) createResources(triggerNS, defaultNS, sa string, meta resourceMetadata, res []json.RawMessage, triggerName, eventID string, log *zap.SugaredLogger) ([]*unstructured.Unstructured, error) {
+	// So at start up the discovery and dynamic clients are created using the in cluster config
+	// of this pod (i.e. using the credentials of the serviceaccount associated with the EventListener)
 
-		// However, we also have a ServiceAccountName reference with each EventListenerTrigger to allow
-		// for more fine grained authorization control around the resources we create below.
-		discoveryClient, dynamicClient, err = r.Auth.OverrideAuthentication(sa, triggerNS, log, r.DiscoveryClient, r.DynamicClient)
+	// However, we also have a ServiceAccountName and a ClientProfile reference with each EventListenerTrigger
+	// to allow for more fine grained authorization control around the resources we create below.
+	discoveryClient, dynamicClient, err := r.resourceClients(context.Background(), meta.clientProfile, sa, triggerNS, log)
+	if err != nil {
+		log.Errorf("problem cloning rest config: %#v", err)
+		return nil, err
+	}
+
+	if !r.CreationLimit.reserve(triggerName, len(res)) {
+		r.recordCreationLimitMetrics(triggerName)
+		r.Activity.limit(triggerName)
+		return nil, fmt.Errorf("skipping creation of %d resources for trigger %s: %w", len(res), triggerName, ErrCreationLimitExceeded)
+	}
+
+	creator := r.Creator
+	if creator == nil {
+		creator = resources.DefaultCreator
+	}
+	// The resources are created in the order of their templates, so that a resource is only created once
+	// the resources it depends on, such as the PersistentVolumeClaim a PipelineRun mounts, were created.
+	var created []*unstructured.Unstructured
+	var mayPatch []bool
+	var failed ResourceTemplateErrors
+	for i, rr := range res {
+		var pending *QueuedCreate
+		if r.QuotaRetry.persistent() {
+			pending = newQueuedCreate(rr, i, triggerNS, defaultNS, sa, meta, triggerName, eventID)
+		}
+		obj, err := r.createResource(creator, rr, triggerName, eventID, defaultNS, meta, pending, discoveryClient, dynamicClient, log)
+		r.recordResourceCreateMetrics(triggerName, resourceKind(rr, meta.defaultType), err)
+		r.sendResourceEvent(triggerName, eventID, i, rr, defaultNS, meta, obj, err, log)
 		if err != nil {
-			log.Errorf("problem cloning rest config: %#v", err)
-			return err
+			templateErr := &ResourceTemplateError{Index: i, Kind: resourceKind(rr, meta.defaultType), Err: err}
+			if r.CreateBestEffort {
+				failed = append(failed, templateErr)
+				continue
+			}
+			if r.RollbackOnFailure {
+				created = r.rollback(created, mayPatch, triggerName, discoveryClient, dynamicClient, log)
+			}
+			return created, templateErr
+		}
+		created = append(created, obj)
+		mayPatch = append(mayPatch, resources.MayPatch(rr) || triggersv1.CreateStrategyFor(meta.createStrategies, obj.GroupVersionKind()) != triggersv1.CreateStrategyCreate)
+	}
+	if len(failed) > 0 {
+		return created, failed
+	}
+	return created, nil
+}
+
+// resourceKind returns the kind of the resource template rr, or the default kind if it specifies neither an
+// apiVersion nor a kind, or else an empty string if it has none.
+func resourceKind(rr json.RawMessage, defaultType metav1.TypeMeta) string {
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(rr, &typeMeta); err != nil {
+		return ""
+	}
+	if typeMeta.APIVersion == "" && typeMeta.Kind == "" {
+		return defaultType.Kind
+	}
+	return typeMeta.Kind
+}
+
+// rollback deletes the resources created for a trigger that failed to create the next ones, most recent
+// first, and returns the resources that are left: those that may have been patched rather than created,
+// since they may predate the event, and those that couldn't be deleted.
+func (r Sink) rollback(created []*unstructured.Unstructured, mayPatch []bool, triggerName string, discoveryClient discoveryclient.ServerResourcesInterface, dynamicClient dynamic.Interface, log *zap.SugaredLogger) []*unstructured.Unstructured {
+	var left []*unstructured.Unstructured
+	for i := len(created) - 1; i >= 0; i-- {
+		obj := created[i]
+		if mayPatch[i] {
+			log.Infof("not rolling back %s %s of trigger %s, which may have existed before the event", obj.GetKind(), obj.GetName(), triggerName)
+			left = append([]*unstructured.Unstructured{obj}, left...)
+			continue
 		}
+		ctx, cancel := context.Background(), func() {}
+		if r.CreateTimeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, r.CreateTimeout)
+		}
+		err := resources.Delete(ctx, obj, discoveryClient, dynamicClient)
+		cancel()
+		if err != nil && !kerrors.IsNotFound(err) {
+			log.Errorf("problem rolling back %s %s of trigger %s: %v", obj.GetKind(), obj.GetName(), triggerName, err)
+			left = append([]*unstructured.Unstructured{obj}, left...)
+			continue
+		}
+		log.Infof("rolled back %s %s of trigger %s", obj.GetKind(), obj.GetName(), triggerName)
 	}
+	return left
+}
+
+// defaultNamespace returns the namespace of the resources of the trigger whose templates don't specify
+// one: the default namespace of the trigger, or else the value of its namespace param, or else the
+// namespace of the trigger itself. The resources are still created with the credentials of the
+// trigger, whose service account needs the permission to create them in that namespace.
+func defaultNamespace(t triggersv1.Trigger, params []triggersv1.Param) (string, error) {
+	if t.Spec.DefaultNamespace != "" {
+		return t.Spec.DefaultNamespace, nil
+	}
+	if t.Spec.NamespaceParam != "" {
+		for _, p := range params {
+			if p.Name != t.Spec.NamespaceParam || p.Value == "" {
+				continue
+			}
+			if msgs := validation.IsDNS1123Label(p.Value); len(msgs) > 0 {
+				return "", fmt.Errorf("value %q of namespace param %s is not a valid namespace name: %s", p.Value, p.Name, strings.Join(msgs, ", "))
+			}
+			return p.Value, nil
+		}
+	}
+	return t.Namespace, nil
+}
 
-	for _, rr := range res {
-		if err := resources.Create(r.Logger, rr, triggerName, eventID, r.EventListenerName, triggerNS, discoveryClient, dynamicClient); err != nil {
-			log.Errorf("problem creating obj: %#v", err)
-			return err
+// resourceTTL returns the time to live of the resources of the trigger after they complete and the key of the
+// annotation to set it with: the value of the param of its resource TTL, or else its duration, or else 0 if the
+// trigger has no resource TTL.
+func resourceTTL(t triggersv1.Trigger, params []triggersv1.Param) (time.Duration, string, error) {
+	ttl := t.Spec.ResourceTTL
+	if ttl == nil {
+		return 0, "", nil
+	}
+	if ttl.Param != "" {
+		for _, p := range params {
+			if p.Name != ttl.Param || p.Value == "" {
+				continue
+			}
+			d, err := time.ParseDuration(p.Value)
+			if err != nil || d <= 0 {
+				return 0, "", fmt.Errorf("value %q of resource TTL param %s is not a positive duration", p.Value, p.Name)
+			}
+			return d, ttl.Annotation, nil
 		}
 	}
-	return nil
+	if ttl.After != nil {
+		return ttl.After.Duration, ttl.Annotation, nil
+	}
+	return 0, "", nil
+}
+
+// createResource creates a single resource, abandoning the creation if it does not complete within the
+// create timeout of its template, or else of the sink. pending is the create to persist if it is queued
+// because it exceeded a quota, or the replayed create, if the quota retry queue is persisted.
+func (r Sink) createResource(creator resources.Creator, rr json.RawMessage, triggerName, eventID, defaultNS string, meta resourceMetadata, pending *QueuedCreate, discoveryClient discoveryclient.ServerResourcesInterface, dynamicClient dynamic.Interface, log *zap.SugaredLogger) (*unstructured.Unstructured, error) {
+	keys := r.logKeys()
+	if kind := resourceKind(rr, meta.defaultType); kind != "" {
+		log = log.With(zap.String(keys.kind, kind))
+	}
+	timeout := r.CreateTimeout
+	if d := resources.TemplateCreateTimeout(rr); d > 0 {
+		timeout = d
+	}
+	ctx := context.Background()
+	if r.ProvenanceLabels != nil {
+		ctx = resources.WithProvenanceLabels(ctx, r.ProvenanceLabels)
+	}
+	if meta.finalizer != "" {
+		ctx = resources.WithFinalizer(ctx, meta.finalizer)
+	}
+	if meta.ttl > 0 {
+		ctx = resources.WithTTL(ctx, meta.ttlAnnotation, meta.ttl)
+	}
+	if len(meta.labels) > 0 || len(meta.annotations) > 0 {
+		ctx = resources.WithTriggerMetadata(ctx, meta.labels, meta.annotations)
+	}
+	if meta.defaultType.Kind != "" {
+		ctx = resources.WithDefaultType(ctx, meta.defaultType.APIVersion, meta.defaultType.Kind)
+	}
+	if meta.kindPolicy != nil {
+		ctx = resources.WithKindPolicy(ctx, meta.kindPolicy)
+	}
+	if len(meta.createStrategies) > 0 {
+		ctx = resources.WithCreateStrategies(ctx, meta.createStrategies)
+	}
+	if r.FieldValidation != "" {
+		ctx = resources.WithFieldValidation(ctx, r.FieldValidation)
+	}
+	if r.LabelSanitization != "" {
+		ctx = resources.WithLabelSanitization(ctx, r.LabelSanitization)
+	}
+	if r.Mutation != nil {
+		ctx = resources.WithMutation(ctx, r.Mutation)
+	}
+	if r.Recorder != nil {
+		ctx = resources.WithCreatedObserver(ctx, r.recordCreatedResourceMetrics)
+	}
+
+	// Each attempt has its own create timeout, so that the creates retried because of a quota aren't
+	// abandoned while they wait.
+	attempt := func(ctx context.Context) (*unstructured.Unstructured, error) {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		r.Backpressure.startCreate()
+		created, err := creator.Create(ctx, log, rr, triggerName, eventID, r.EventListenerName, defaultNS, discoveryClient, dynamicClient)
+		r.Backpressure.finishCreate(err)
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			log.With(keys.outcome, failedOutcome).Errorf("abandoned creating obj after %s: %v", timeout, err)
+			r.recordCreateTimeoutMetrics(triggerName)
+			return nil, fmt.Errorf("%w after %s: %v", ErrCreateTimeout, timeout, err)
+		}
+		return created, err
+	}
+	create := func() (*unstructured.Unstructured, error) {
+		return attempt(ctx)
+	}
+	if pending != nil {
+		// The retries of a persisted create are named after its key, so that replaying it doesn't create
+		// the resource again if a retry succeeded before the EventListener stopped.
+		keyed := resources.WithIdempotencyKey(ctx, pending.Key)
+		create = func() (*unstructured.Unstructured, error) {
+			return attempt(keyed)
+		}
+		defer r.QuotaRetry.forget(pending, log)
+	}
+
+	var created *unstructured.Unstructured
+	var err error
+	if pending != nil && pending.replayed {
+		created, err = create()
+	} else {
+		created, err = attempt(ctx)
+	}
+	if err != nil && r.QuotaRetry != nil && isQuotaExceeded(err) {
+		created, err = r.retryQuotaExceeded(err, create, triggerName, pending, log)
+	}
+	if err != nil {
+		if !errors.Is(err, ErrCreateTimeout) {
+			log.With(keys.outcome, failedOutcome).Errorf("problem creating obj: %#v", err)
+		}
+		if errors.Is(err, resources.ErrDiscoveryNotReady) {
+			r.Discovery.unavailable(err)
+		}
+		var kindErr *resources.KindNotAllowedError
+		if errors.As(err, &kindErr) {
+			r.recordKindNotAllowedMetrics(triggerName, kindErr.GroupVersionKind)
+		}
+		return nil, err
+	}
+	if created != nil {
+		log.With(keys.outcome, createdOutcome).Infof("created %s %s", created.GetKind(), created.GetName())
+	}
+	r.Activity.record(triggerName, eventID, created)
+	return created, nil
+}
+
+// retryQuotaExceeded queues a create that exceeded a quota to be retried, and records whether it was
+// queued or dropped in the metrics. The error of dropped creates wraps ErrQuotaRetryDropped. If pending
+// is set, the queued create is persisted, and stays persisted if the queue shuts down before it is
+// done, and a replayed create is retried until the end of its original window.
+func (r Sink) retryQuotaExceeded(err error, create func() (*unstructured.Unstructured, error), triggerName string, pending *QueuedCreate, log *zap.SugaredLogger) (*unstructured.Unstructured, error) {
+	var deadline time.Time
+	if pending != nil && pending.replayed {
+		deadline = pending.Deadline.Time
+	}
+	created, dropped, err := r.QuotaRetry.retryUntil(err, deadline, create, func() {
+		if pending != nil && pending.replayed {
+			log.Infof("replayed create of trigger %s exceeded a quota, retrying it until %s", triggerName, deadline.Format(time.RFC3339))
+			return
+		}
+		log.Infof("create of trigger %s exceeded a quota, retrying it for up to %s", triggerName, r.QuotaRetry.Window)
+		r.recordQuotaRetryQueuedMetrics(triggerName)
+		if pending != nil {
+			r.QuotaRetry.persist(pending, log)
+		}
+	})
+	if dropped != "" {
+		if dropped == quotaRetryShutdown && pending != nil {
+			pending.interrupted = true
+		}
+		log.Warnf("dropping create of trigger %s that exceeded a quota: %s", triggerName, dropped)
+		r.recordQuotaRetryDroppedMetrics(triggerName, dropped)
+		return nil, fmt.Errorf("%w: %v", ErrQuotaRetryDropped, err)
+	}
+	return created, err
+}
+
+// goTemplateConfigMaps returns the getter of the ConfigMaps holding the Go templates of the triggers in
+// namespace. The ConfigMaps aren't requested with the context of the request of the event, which is
+// cancelled once asynchronous sinks have responded, while the triggers are still processed.
+func (r Sink) goTemplateConfigMaps(namespace string) func(name string) (*corev1.ConfigMap, error) {
+	return func(name string) (*corev1.ConfigMap, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), goTemplateConfigMapTimeout)
+		defer cancel()
+		return r.KubeClientSet.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
 }
 
 // extendBodyWithExtensions merges the extensions into the given body.
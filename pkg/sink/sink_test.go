@@ -19,7 +19,11 @@ package sink
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -29,6 +33,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	cloudeventstest "github.com/cloudevents/sdk-go/v2/client/test"
@@ -50,6 +55,7 @@ import (
 	triggertemplateinformer "github.com/tektoncd/triggers/pkg/client/injection/informers/triggers/v1beta1/triggertemplate"
 	"github.com/tektoncd/triggers/pkg/interceptors"
 	"github.com/tektoncd/triggers/pkg/interceptors/server"
+	"github.com/tektoncd/triggers/pkg/resources"
 	"github.com/tektoncd/triggers/pkg/template"
 	"github.com/tektoncd/triggers/test"
 	"go.uber.org/zap"
@@ -60,13 +66,19 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	discoveryclient "k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	fakedynamic "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	ktesting "k8s.io/client-go/testing"
 	"knative.dev/pkg/apis"
 	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/metrics/metricstest"
 	"knative.dev/pkg/ptr"
 )
 
@@ -236,7 +248,8 @@ func toTaskRun(t *testing.T, actions []ktesting.Action) []pipelinev1.TaskRun {
 }
 
 // checkSinkResponse checks that the sink response status code is 202 and that
-// the body returns the EventListener, namespace, and eventID.
+// the body returns the EventListener, namespace, and eventID. The dispatched
+// triggers are checked separately by TestHandleEvent_MatchedTriggers.
 func checkSinkResponse(t *testing.T, resp *http.Response, elName string) {
 	t.Helper()
 	if resp.StatusCode != http.StatusAccepted {
@@ -252,7 +265,7 @@ func checkSinkResponse(t *testing.T, resp *http.Response, elName string) {
 		Namespace:        namespace,
 		EventID:          eventID,
 	}
-	if diff := cmp.Diff(wantBody, gotBody); diff != "" {
+	if diff := cmp.Diff(wantBody, gotBody, cmpopts.IgnoreFields(Response{}, "Triggers", "TriggerGroups", "Message")); diff != "" {
 		t.Errorf("did not get expected response back -want,+got: %s", diff)
 	}
 }
@@ -338,8 +351,8 @@ func TestHandleEvent(t *testing.T) {
 				Name:      "git-clone-run",
 				Namespace: namespace,
 				Labels: map[string]string{
-					"app":                                  "bar\t\r\nbaz昨",
-					"type":                                 "application/json",
+					"app":                                  "bar---baz-8d4d24b9ba",
+					"type":                                 "application-json-bacb769b46",
 					"triggers.tekton.dev/eventlistener":    eventListenerName,
 					"triggers.tekton.dev/trigger":          "git-clone-trigger",
 					"triggers.tekton.dev/triggers-eventid": "12345",
@@ -1220,6 +1233,568 @@ func TestHandleEvent(t *testing.T) {
 	}
 }
 
+func TestHandleEvent_MatchedTriggers(t *testing.T) {
+	eventBody := json.RawMessage(`{"head_commit": {"id": "testrevision"}, "repository": {"url": "testurl"}, "foo": "bar"}`)
+	gitCloneTrigger := func(name string, filter string) triggersv1beta1.EventListenerTrigger {
+		return triggersv1beta1.EventListenerTrigger{
+			Name: name,
+			Interceptors: []*triggersv1beta1.TriggerInterceptor{{
+				Ref: triggersv1beta1.InterceptorRef{Name: "cel", Kind: triggersv1beta1.ClusterInterceptorKind},
+				Params: []triggersv1beta1.InterceptorParams{{
+					Name:  "filter",
+					Value: test.ToV1JSON(t, filter),
+				}},
+			}},
+			Bindings: []*triggersv1beta1.EventListenerBinding{
+				{Name: "url", Value: ptr.String("$(body.repository.url)")},
+				{Name: "revision", Value: ptr.String("$(body.head_commit.id)")},
+				{Name: "app", Value: ptr.String("$(body.foo)")},
+				{Name: "type", Value: ptr.String("$(header.Content-Type)")},
+			},
+			Template: &triggersv1beta1.EventListenerTemplate{Spec: makeGitCloneTTSpec(t, name+"-run")},
+		}
+	}
+
+	for _, tc := range []struct {
+		name        string
+		triggers    []triggersv1beta1.EventListenerTrigger
+		wantBody    Response
+		wantSummary string
+	}{{
+		name:     "matching and non matching triggers",
+		triggers: []triggersv1beta1.EventListenerTrigger{gitCloneTrigger("fires", "has(body.head_commit)"), gitCloneTrigger("filtered", "has(body.missing)")},
+		wantBody: Response{
+			EventListener:    "test-el",
+			EventListenerUID: elUID,
+			Namespace:        namespace,
+			EventID:          eventID,
+			Triggers:         []string{"fires", "filtered"},
+		},
+		wantSummary: "event processing completed, fired triggers: fires",
+	}, {
+		name:     "single trigger",
+		triggers: []triggersv1beta1.EventListenerTrigger{gitCloneTrigger("fires", "has(body.head_commit)")},
+		wantBody: Response{
+			EventListener:    "test-el",
+			EventListenerUID: elUID,
+			Namespace:        namespace,
+			EventID:          eventID,
+			Triggers:         []string{"fires"},
+		},
+		wantSummary: "event processing completed, fired triggers: fires",
+	}, {
+		name:     "single non matching trigger",
+		triggers: []triggersv1beta1.EventListenerTrigger{gitCloneTrigger("filtered", "has(body.missing)")},
+		wantBody: Response{
+			EventListener:    "test-el",
+			EventListenerUID: elUID,
+			Namespace:        namespace,
+			EventID:          eventID,
+			Triggers:         []string{"filtered"},
+		},
+		wantSummary: "event processing completed, no triggers fired",
+	}, {
+		name: "no triggers",
+		wantBody: Response{
+			EventListener:    "test-el",
+			EventListenerUID: elUID,
+			Namespace:        namespace,
+			EventID:          eventID,
+			Message:          noTriggersMatchedMessage,
+		},
+		wantSummary: "no triggers matched for event",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			resources := test.Resources{
+				EventListeners: []*triggersv1beta1.EventListener{{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-el",
+						Namespace: namespace,
+						UID:       types.UID(elUID),
+					},
+					Spec: triggersv1beta1.EventListenerSpec{
+						Triggers: tc.triggers,
+					},
+				}},
+				ClusterInterceptors: []*triggersv1alpha1.ClusterInterceptor{cel},
+			}
+			sink, _ := getSinkAssets(t, resources, "test-el", nil)
+			core, logs := observer.New(zapcore.InfoLevel)
+			sink.Logger = zaptest.NewLogger(t, zaptest.WrapOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core { return core }))).Sugar()
+
+			ts := httptest.NewServer(http.HandlerFunc(sink.HandleEvent))
+			defer ts.Close()
+
+			resp, err := http.Post(ts.URL, "application/json", bytes.NewReader(eventBody))
+			if err != nil {
+				t.Fatalf("error making request to eventListener: %s", err)
+			}
+			sink.WGProcessTriggers.Wait()
+
+			var gotBody Response
+			if err := json.NewDecoder(resp.Body).Decode(&gotBody); err != nil {
+				t.Fatalf("Error reading response body: %s", err)
+			}
+			if diff := cmp.Diff(tc.wantBody, gotBody); diff != "" {
+				t.Errorf("did not get expected response back -want,+got: %s", diff)
+			}
+			if logs.FilterMessage(tc.wantSummary).Len() == 0 {
+				t.Errorf("did not find log entry: %s.\n Logs are: %v", tc.wantSummary, logs.All())
+			}
+		})
+	}
+}
+
+func TestHandleEvent_ResourceTemplateSelection(t *testing.T) {
+	eventBody := json.RawMessage(`{"head_commit": {"id": "testrevision"}, "repository": {"url": "testurl"}, "foo": "bar"}`)
+	spec := makeGitCloneTTSpec(t, "variant-a")
+	variantB := bytes.ReplaceAll(spec.ResourceTemplates[0].Raw, []byte("$(tt.params.name)"), []byte("variant-b"))
+	spec.ResourceTemplates = append(spec.ResourceTemplates, triggersv1beta1.TriggerResourceTemplate{RawExtension: runtime.RawExtension{Raw: variantB}})
+	spec.Selection = &triggersv1beta1.ResourceTemplateSelection{Weights: []int32{0, 1}, Key: "url"}
+	resources := test.Resources{
+		EventListeners: []*triggersv1beta1.EventListener{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-el",
+				Namespace: namespace,
+				UID:       types.UID(elUID),
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Name: "split",
+					Bindings: []*triggersv1beta1.EventListenerBinding{
+						{Name: "url", Value: ptr.String("$(body.repository.url)")},
+						{Name: "revision", Value: ptr.String("$(body.head_commit.id)")},
+					},
+					Template: &triggersv1beta1.EventListenerTemplate{Spec: spec},
+				}},
+			},
+		}},
+	}
+	sink, dynamicClient := getSinkAssets(t, resources, "test-el", nil)
+	core, logs := observer.New(zapcore.InfoLevel)
+	sink.Logger = zaptest.NewLogger(t, zaptest.WrapOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core { return core }))).Sugar()
+
+	ts := httptest.NewServer(http.HandlerFunc(sink.HandleEvent))
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL, "application/json", bytes.NewReader(eventBody))
+	if err != nil {
+		t.Fatalf("error making request to eventListener: %s", err)
+	}
+	resp.Body.Close()
+	sink.WGProcessTriggers.Wait()
+
+	got := toTaskRun(t, dynamicClient.Actions())
+	if len(got) != 1 {
+		t.Fatalf("created %d TaskRuns, want 1", len(got))
+	}
+	if got[0].Name != "variant-b" {
+		t.Errorf("created TaskRun %s, want the TaskRun of the second resource template", got[0].Name)
+	}
+	if v := got[0].Labels["triggers.tekton.dev/resource-template"]; v != "1" {
+		t.Errorf("created TaskRun with resource template label %q, want %q", v, "1")
+	}
+	selected := logs.FilterMessage("selected resource template 1").All()
+	if len(selected) != 1 || selected[0].ContextMap()["resourceTemplate"] != int64(1) {
+		t.Errorf("did not find log entry of the selected resource template.\n Logs are: %v", logs.All())
+	}
+}
+
+func TestHandleEvent_TemplateRouting(t *testing.T) {
+	routedTT := func(name string) *triggersv1beta1.TriggerTemplate {
+		return &triggersv1beta1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       *makeGitCloneTTSpec(t, name+"-run"),
+		}
+	}
+	routing := &triggersv1beta1.TriggerTemplateRouting{
+		Extension: "type",
+		Routes: []triggersv1beta1.TriggerTemplateRoute{
+			{Value: "deploy", Ref: "deploy"},
+			{Value: "test", Ref: "test"},
+		},
+	}
+	for _, tc := range []struct {
+		name string
+		body string
+		want string
+	}{{
+		name: "routed",
+		body: `{"head_commit": {"id": "testrevision"}, "repository": {"url": "testurl"}, "kind": "test"}`,
+		want: "test-run",
+	}, {
+		name: "fallback",
+		body: `{"head_commit": {"id": "testrevision"}, "repository": {"url": "testurl"}, "kind": "lint"}`,
+		want: "fallback-run",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			resources := test.Resources{
+				ClusterInterceptors: []*triggersv1alpha1.ClusterInterceptor{cel},
+				TriggerTemplates:    []*triggersv1beta1.TriggerTemplate{routedTT("deploy"), routedTT("test")},
+				EventListeners: []*triggersv1beta1.EventListener{{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-el",
+						Namespace: namespace,
+						UID:       types.UID(elUID),
+					},
+					Spec: triggersv1beta1.EventListenerSpec{
+						Triggers: []triggersv1beta1.EventListenerTrigger{{
+							Name: "classify",
+							Interceptors: []*triggersv1beta1.TriggerInterceptor{{
+								Ref: triggersv1beta1.InterceptorRef{Name: "cel", Kind: triggersv1beta1.ClusterInterceptorKind},
+								Params: []triggersv1beta1.InterceptorParams{{
+									Name: "overlays",
+									Value: test.ToV1JSON(t, []triggersv1beta1.CELOverlay{{
+										Key:        "type",
+										Expression: "body.kind",
+									}}),
+								}},
+							}},
+							Bindings: []*triggersv1beta1.EventListenerBinding{
+								{Name: "url", Value: ptr.String("$(body.repository.url)")},
+								{Name: "revision", Value: ptr.String("$(body.head_commit.id)")},
+							},
+							Template: &triggersv1beta1.EventListenerTemplate{
+								Spec:    makeGitCloneTTSpec(t, "fallback-run"),
+								Routing: routing,
+							},
+						}},
+					},
+				}},
+			}
+			sink, dynamicClient := getSinkAssets(t, resources, "test-el", nil)
+
+			ts := httptest.NewServer(http.HandlerFunc(sink.HandleEvent))
+			defer ts.Close()
+
+			resp, err := http.Post(ts.URL, "application/json", strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatalf("error making request to eventListener: %s", err)
+			}
+			resp.Body.Close()
+			sink.WGProcessTriggers.Wait()
+
+			got := toTaskRun(t, dynamicClient.Actions())
+			if len(got) != 1 {
+				t.Fatalf("created %d TaskRuns, want 1", len(got))
+			}
+			if got[0].Name != tc.want {
+				t.Errorf("created TaskRun %s, want %s", got[0].Name, tc.want)
+			}
+		})
+	}
+}
+
+func TestHandleEvent_GoTemplates(t *testing.T) {
+	eventBody := json.RawMessage(`{"repository": {"url": "testurl"}, "targets": ["dev", "prod"]}`)
+	resources := test.Resources{
+		EventListeners: []*triggersv1beta1.EventListener{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-el",
+				Namespace: namespace,
+				UID:       types.UID(elUID),
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Name: "rendered",
+					Bindings: []*triggersv1beta1.EventListenerBinding{
+						{Name: "url", Value: ptr.String("$(body.repository.url)")},
+					},
+					Template: &triggersv1beta1.EventListenerTemplate{Spec: &triggersv1beta1.TriggerTemplateSpec{
+						Params:      []triggersv1beta1.ParamSpec{{Name: "url"}},
+						GoTemplates: []triggersv1beta1.GoTemplateRef{{ConfigMapName: "pipelines", Key: "deploy.yaml"}},
+					}},
+				}},
+			},
+		}},
+	}
+	for _, tc := range []struct {
+		name string
+		// cancelled cancels the request context before the triggers are processed, as net/http does once
+		// asynchronous sinks have responded.
+		cancelled bool
+	}{{
+		name: "request in flight",
+	}, {
+		name:      "request context cancelled",
+		cancelled: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			sink, dynamicClient := getSinkAssets(t, resources, "test-el", nil)
+			if _, err := sink.KubeClientSet.CoreV1().ConfigMaps(namespace).Create(context.Background(), &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "pipelines", Namespace: namespace},
+				Data: map[string]string{"deploy.yaml": `{{- range .body.targets }}
+---
+apiVersion: tekton.dev/v1beta1
+kind: TaskRun
+metadata:
+  name: deploy-{{ . }}
+  annotations:
+    url: {{ $.params.url | quote }}
+spec:
+  taskRef:
+    name: deploy
+{{- end }}
+`},
+			}, metav1.CreateOptions{}); err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.cancelled {
+				sink.KubeClientSet = contextCheckingClientset{sink.KubeClientSet}
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			if tc.cancelled {
+				cancel()
+			}
+			defer cancel()
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(eventBody)).WithContext(ctx)
+			req.Header.Set("Content-Type", "application/json")
+			sink.HandleEvent(httptest.NewRecorder(), req)
+			sink.WGProcessTriggers.Wait()
+
+			got := toTaskRun(t, dynamicClient.Actions())
+			if len(got) != 2 {
+				t.Fatalf("created %d TaskRuns, want 2", len(got))
+			}
+			for i, name := range []string{"deploy-dev", "deploy-prod"} {
+				if got[i].Name != name || got[i].Annotations["url"] != "testurl" {
+					t.Errorf("created TaskRun %s with annotations %v, want %s with the url annotation", got[i].Name, got[i].Annotations, name)
+				}
+			}
+		})
+	}
+}
+
+// contextCheckingClientset fails to get ConfigMaps with a done context, like the clients of an API server.
+type contextCheckingClientset struct {
+	kubernetes.Interface
+}
+
+func (c contextCheckingClientset) CoreV1() typedcorev1.CoreV1Interface {
+	return contextCheckingCoreV1{c.Interface.CoreV1()}
+}
+
+type contextCheckingCoreV1 struct {
+	typedcorev1.CoreV1Interface
+}
+
+func (c contextCheckingCoreV1) ConfigMaps(namespace string) typedcorev1.ConfigMapInterface {
+	return contextCheckingConfigMaps{c.CoreV1Interface.ConfigMaps(namespace)}
+}
+
+type contextCheckingConfigMaps struct {
+	typedcorev1.ConfigMapInterface
+}
+
+func (c contextCheckingConfigMaps) Get(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.ConfigMap, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.ConfigMapInterface.Get(ctx, name, opts)
+}
+
+func TestHandleEvent_Overlays(t *testing.T) {
+	eventBody := json.RawMessage(`{"head_commit": {"id": "testrevision"}, "repository": {"url": "testurl"}, "host_network": true}`)
+	spec := makeGitCloneTTSpec(t, "overlaid")
+	spec.Params = append(spec.Params,
+		triggersv1beta1.ParamSpec{Name: "host-network"},
+		triggersv1beta1.ParamSpec{Name: "pod-template", Default: ptr.String(`{"schedulerName": "batch"}`)})
+	resources := test.Resources{
+		EventListeners: []*triggersv1beta1.EventListener{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-el",
+				Namespace: namespace,
+				UID:       types.UID(elUID),
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Name: "overlaid",
+					Bindings: []*triggersv1beta1.EventListenerBinding{
+						{Name: "url", Value: ptr.String("$(body.repository.url)")},
+						{Name: "revision", Value: ptr.String("$(body.head_commit.id)")},
+						{Name: "host-network", Value: ptr.String("$(body.host_network)")},
+					},
+					Template: &triggersv1beta1.EventListenerTemplate{Spec: spec},
+					// The pod template is added first, so that the next overlay can set one of its fields.
+					Overlays: []triggersv1beta1.TriggerOverlay{{
+						Path:  "/spec/podTemplate",
+						Param: "pod-template",
+						Type:  triggersv1beta1.OverlayTypeObject,
+					}, {
+						Path:  "/spec/podTemplate/hostNetwork",
+						Param: "host-network",
+						Type:  triggersv1beta1.OverlayTypeBoolean,
+					}},
+				}},
+			},
+		}},
+	}
+	sink, dynamicClient := getSinkAssets(t, resources, "test-el", nil)
+
+	ts := httptest.NewServer(http.HandlerFunc(sink.HandleEvent))
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL, "application/json", bytes.NewReader(eventBody))
+	if err != nil {
+		t.Fatalf("error making request to eventListener: %s", err)
+	}
+	resp.Body.Close()
+	sink.WGProcessTriggers.Wait()
+
+	got := toTaskRun(t, dynamicClient.Actions())
+	if len(got) != 1 {
+		t.Fatalf("created %d TaskRuns, want 1", len(got))
+	}
+	if pt := got[0].Spec.PodTemplate; pt == nil || !pt.HostNetwork || pt.SchedulerName != "batch" {
+		t.Errorf("created TaskRun with pod template %+v, want the overlaid host network and scheduler name", pt)
+	}
+}
+
+func TestHandleEvent_JSONLogFormat(t *testing.T) {
+	eventBody := json.RawMessage(`{"head_commit": {"id": "testrevision"}, "repository": {"url": "testurl"}, "foo": "bar"}`)
+	resources := test.Resources{
+		EventListeners: []*triggersv1beta1.EventListener{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-el",
+				Namespace: namespace,
+				UID:       types.UID(elUID),
+			},
+			Spec: triggersv1beta1.EventListenerSpec{
+				Triggers: []triggersv1beta1.EventListenerTrigger{{
+					Name: "git-clone",
+					Bindings: []*triggersv1beta1.EventListenerBinding{
+						{Name: "url", Value: ptr.String("$(body.repository.url)")},
+						{Name: "revision", Value: ptr.String("$(body.head_commit.id)")},
+					},
+					Template: &triggersv1beta1.EventListenerTemplate{Spec: makeGitCloneTTSpec(t, "git-clone-run")},
+				}},
+			},
+		}},
+	}
+	sink, _ := getSinkAssets(t, resources, "test-el", nil)
+	sink.LogFormat = "json"
+	core, logs := observer.New(zapcore.InfoLevel)
+	sink.Logger = zaptest.NewLogger(t, zaptest.WrapOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core { return core }))).Sugar()
+
+	ts := httptest.NewServer(http.HandlerFunc(sink.HandleEvent))
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL, "application/json", bytes.NewReader(eventBody))
+	if err != nil {
+		t.Fatalf("error making request to eventListener: %s", err)
+	}
+	resp.Body.Close()
+	sink.WGProcessTriggers.Wait()
+
+	for msg, want := range map[string]map[string]interface{}{
+		"trigger git-clone fired": {
+			"eventListener": "test-el",
+			"namespace":     namespace,
+			"trigger":       "git-clone",
+			"outcome":       "fired",
+		},
+		"created TaskRun git-clone-run": {
+			"eventListener": "test-el",
+			"namespace":     namespace,
+			"trigger":       "git-clone",
+			"kind":          "TaskRun",
+			"outcome":       "created",
+		},
+	} {
+		entries := logs.FilterMessage(msg).All()
+		if len(entries) != 1 {
+			t.Fatalf("found %d log entries %q, want 1.\n Logs are: %v", len(entries), msg, logs.All())
+		}
+		fields := entries[0].ContextMap()
+		if fields["eventID"] == "" || fields["eventID"] == nil {
+			t.Errorf("log entry %q has no eventID field: %v", msg, fields)
+		}
+		delete(fields, "eventID")
+		delete(fields, "eventlistenerUID")
+		if diff := cmp.Diff(want, fields); diff != "" {
+			t.Errorf("log entry %q fields -want +got: %s", msg, diff)
+		}
+	}
+}
+
+func TestHandleEvent_NoMatchPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		policy     string
+		level      zapcore.Level
+		wantStatus int
+		wantBody   Response
+	}{{
+		name:       "default policy",
+		wantStatus: http.StatusAccepted,
+		wantBody: Response{
+			EventListener:    "test-el",
+			EventListenerUID: elUID,
+			Namespace:        namespace,
+			EventID:          eventID,
+			Message:          noTriggersMatchedMessage,
+		},
+	}, {
+		name:       "error policy",
+		policy:     "error",
+		level:      zapcore.WarnLevel,
+		wantStatus: http.StatusUnprocessableEntity,
+		wantBody: Response{
+			EventListener:    "test-el",
+			EventListenerUID: elUID,
+			Namespace:        namespace,
+			EventID:          eventID,
+			Message:          noTriggersMatchedMessage,
+			ErrorMessage:     noTriggersMatchedMessage,
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			resources := test.Resources{
+				EventListeners: []*triggersv1beta1.EventListener{{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-el",
+						Namespace: namespace,
+						UID:       types.UID(elUID),
+					},
+				}},
+			}
+			sink, _ := getSinkAssets(t, resources, "test-el", nil)
+			sink.NoMatchPolicy = tc.policy
+			sink.NoMatchLogLevel = tc.level
+			core, logs := observer.New(zapcore.DebugLevel)
+			sink.Logger = zaptest.NewLogger(t, zaptest.WrapOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core { return core }))).Sugar()
+
+			ts := httptest.NewServer(http.HandlerFunc(sink.HandleEvent))
+			defer ts.Close()
+
+			resp, err := http.Post(ts.URL, "application/json", bytes.NewReader([]byte(`{}`)))
+			if err != nil {
+				t.Fatalf("error making request to eventListener: %s", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.wantStatus {
+				t.Errorf("got status code %d, want %d", resp.StatusCode, tc.wantStatus)
+			}
+			var gotBody Response
+			if err := json.NewDecoder(resp.Body).Decode(&gotBody); err != nil {
+				t.Fatalf("Error reading response body: %s", err)
+			}
+			if diff := cmp.Diff(tc.wantBody, gotBody); diff != "" {
+				t.Errorf("did not get expected response back -want,+got: %s", diff)
+			}
+			entries := logs.FilterMessage("no triggers matched for event").All()
+			if len(entries) != 1 {
+				t.Fatalf("found %d no match log entries, want 1.\n Logs are: %v", len(entries), logs.All())
+			}
+			if entries[0].Level != tc.level {
+				t.Errorf("no match logged at level %v, want %v", entries[0].Level, tc.level)
+			}
+		})
+	}
+}
+
 func TestHandleEvent_Error(t *testing.T) {
 	var eventBody = json.RawMessage(`{"head_commit": {"id": "testrevision"}, "repository": {"url": "testurl"}}`)
 	const defaultELName = "test-el"
@@ -1458,6 +2033,76 @@ func TestExecuteInterceptor_error(t *testing.T) {
 	}
 }
 
+// slowInterceptor is a HTTP server that delays each response of the wrapped interceptor.
+type slowInterceptor struct {
+	delay time.Duration
+	next  http.Handler
+}
+
+func (s *slowInterceptor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(s.delay)
+	s.next.ServeHTTP(w, r)
+}
+
+func TestExecuteInterceptor_ChainTimeout(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ctx, _ := test.SetupFakeContext(t)
+	httpClient := setupInterceptors(t, fakekubeclient.Get(ctx), logger.Sugar(), &slowInterceptor{
+		delay: 50 * time.Millisecond,
+		next:  &sequentialInterceptor{},
+	})
+
+	a := &triggersv1beta1.EventInterceptor{
+		Webhook: &triggersv1beta1.WebhookInterceptor{
+			ObjectRef: &corev1.ObjectReference{
+				APIVersion: "v1",
+				Kind:       "Service",
+				Name:       "foo",
+			},
+		},
+	}
+	trigger := triggersv1beta1.Trigger{
+		Spec: triggersv1beta1.TriggerSpec{
+			Interceptors: []*triggersv1beta1.EventInterceptor{a, a, a}},
+	}
+
+	for _, tc := range []struct {
+		name    string
+		timeout time.Duration
+		wantErr bool
+	}{{
+		name: "no timeout",
+	}, {
+		name:    "chain completes within timeout",
+		timeout: 5 * time.Second,
+	}, {
+		// Each interceptor completes within the timeout but the chain as a whole does not
+		name:    "chain exceeds timeout",
+		timeout: 120 * time.Millisecond,
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := Sink{
+				HTTPClient:         httpClient,
+				Logger:             logger.Sugar(),
+				InterceptorTimeout: tc.timeout,
+			}
+			req, err := http.NewRequest(http.MethodPost, "/", nil)
+			if err != nil {
+				t.Fatalf("http.NewRequest: %v", err)
+			}
+			_, _, _, err = r.ExecuteTriggerInterceptors(trigger, req, []byte(`{}`), logger.Sugar(), eventID, map[string]interface{}{})
+			if tc.wantErr {
+				if !errors.Is(err, ErrInterceptorChainTimeout) {
+					t.Fatalf("expected ErrInterceptorChainTimeout, got: %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("executeInterceptors: %v", err)
+			}
+		})
+	}
+}
+
 func TestExecuteInterceptor_NotContinue(t *testing.T) {
 	resources := test.Resources{
 		ClusterInterceptors: []*triggersv1alpha1.ClusterInterceptor{cel},
@@ -1591,6 +2236,558 @@ func TestExecuteInterceptor_ExtensionChaining(t *testing.T) {
 	}
 }
 
+func TestExecuteInterceptor_DefaultParams(t *testing.T) {
+	celWithDefaults := cel.DeepCopy()
+	celWithDefaults.Spec.Params = []triggersv1alpha1.InterceptorParams{{
+		Name:  "filter",
+		Value: test.ToV1JSON(t, "body.action == 'opened'"),
+	}}
+	resources := test.Resources{
+		ClusterInterceptors: []*triggersv1alpha1.ClusterInterceptor{celWithDefaults},
+	}
+	s, _ := getSinkAssets(t, resources, "", nil)
+
+	for _, tc := range []struct {
+		name         string
+		params       []triggersv1beta1.InterceptorParams
+		wantContinue bool
+	}{{
+		name:         "default params are used",
+		wantContinue: false,
+	}, {
+		name: "default params are merged with the params of the trigger",
+		params: []triggersv1beta1.InterceptorParams{{
+			Name: "overlays",
+			Value: test.ToV1JSON(t, []triggersv1beta1.CELOverlay{{
+				Key:        "short_action",
+				Expression: "body.action.truncate(2)",
+			}}),
+		}},
+		wantContinue: false,
+	}, {
+		name: "params of the trigger override default params",
+		params: []triggersv1beta1.InterceptorParams{{
+			Name:  "filter",
+			Value: test.ToV1JSON(t, "body.action == 'closed'"),
+		}},
+		wantContinue: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			trigger := triggersv1beta1.Trigger{
+				Spec: triggersv1beta1.TriggerSpec{
+					Interceptors: []*triggersv1beta1.EventInterceptor{{
+						Ref:    triggersv1beta1.InterceptorRef{Name: "cel", Kind: triggersv1beta1.ClusterInterceptorKind},
+						Params: tc.params,
+					}},
+				},
+			}
+			req, err := http.NewRequest("POST", "/", nil)
+			if err != nil {
+				t.Fatalf("http.NewRequest: %v", err)
+			}
+			_, _, iresp, err := s.ExecuteTriggerInterceptors(trigger, req, []byte(`{"action": "closed"}`), s.Logger, eventID, map[string]interface{}{})
+			if err != nil {
+				t.Fatalf("executeInterceptors: %v", err)
+			}
+			if iresp.Continue != tc.wantContinue {
+				t.Errorf("Response.continue got %t, want %t. Response: %v", iresp.Continue, tc.wantContinue, iresp)
+			}
+		})
+	}
+}
+
+func TestClientCertificate(t *testing.T) {
+	spiffe, _ := url.Parse("spiffe://example.com/ci")
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "ci-prod", Organization: []string{"Example"}},
+		DNSNames:       []string{"ci.example.com"},
+		URIs:           []*url.URL{spiffe},
+		EmailAddresses: []string{"ci@example.com"},
+	}
+	for _, tt := range []struct {
+		name string
+		tls  *tls.ConnectionState
+		want *triggersv1beta1.ClientCertificate
+	}{{
+		name: "plain HTTP",
+	}, {
+		name: "no client certificate",
+		tls:  &tls.ConnectionState{},
+	}, {
+		name: "unverified client certificate",
+		tls:  &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+	}, {
+		name: "verified client certificate",
+		tls: &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{cert},
+			VerifiedChains:   [][]*x509.Certificate{{cert}},
+		},
+		want: &triggersv1beta1.ClientCertificate{
+			Subject:        "CN=ci-prod,O=Example",
+			CommonName:     "ci-prod",
+			DNSNames:       []string{"ci.example.com"},
+			URIs:           []string{"spiffe://example.com/ci"},
+			EmailAddresses: []string{"ci@example.com"},
+		},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "https://example.com", nil)
+			req.TLS = tt.tls
+			if diff := cmp.Diff(tt.want, clientCertificate(req)); diff != "" {
+				t.Errorf("clientCertificate() -want/+got: %s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateResources_Creator(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	creator := &resources.FakeCreator{}
+	r := Sink{
+		EventListenerName: "test-el",
+		Logger:            logger,
+		Creator:           creator,
+	}
+
+	res := []json.RawMessage{
+		json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"first"}}`),
+		json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"second"}}`),
+	}
+	if err := r.CreateResources(namespace, "", "", res, "my-trigger", eventID, logger); err != nil {
+		t.Fatalf("CreateResources() returned error: %v", err)
+	}
+	var got []string
+	for _, u := range creator.Created() {
+		got = append(got, u.GetNamespace()+"/"+u.GetName())
+	}
+	if diff := cmp.Diff([]string{namespace + "/first", namespace + "/second"}, got); diff != "" {
+		t.Errorf("created resources: -want +got: %s", diff)
+	}
+
+	creator.Err = errors.New("boom")
+	if err := r.CreateResources(namespace, "", "", res, "my-trigger", eventID, logger); err == nil {
+		t.Error("expected CreateResources() to return the Creator's error")
+	}
+}
+
+func TestCreateResources_Finalizer(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	creator := &resources.FakeCreator{}
+	r := Sink{
+		EventListenerName: "test-el",
+		Logger:            logger,
+		Creator:           creator,
+	}
+
+	res := []json.RawMessage{
+		json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"first","finalizers":["example.com/other"]}}`),
+	}
+	if err := r.CreateResources(namespace, "", "example.com/cleanup", res, "my-trigger", eventID, logger); err != nil {
+		t.Fatalf("CreateResources() returned error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"example.com/other", "example.com/cleanup"}, creator.Created()[0].GetFinalizers()); diff != "" {
+		t.Errorf("finalizers: -want +got: %s", diff)
+	}
+}
+
+func TestCreateResources_TriggerMetadata(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	creator := &resources.FakeCreator{}
+	r := Sink{
+		EventListenerName: "test-el",
+		Logger:            logger,
+		Creator:           creator,
+	}
+
+	res := []json.RawMessage{
+		json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"first","labels":{"team":"template"}}}`),
+	}
+	meta := resourceMetadata{
+		labels:      map[string]string{"team": "trigger", "cost-center": "cc-1234"},
+		annotations: map[string]string{"example.com/notify": "#builds"},
+	}
+	if _, err := r.createResources(namespace, namespace, "", meta, res, "my-trigger", eventID, logger); err != nil {
+		t.Fatalf("createResources() returned error: %v", err)
+	}
+	created := creator.Created()[0]
+	if got := created.GetLabels(); got["team"] != "template" || got["cost-center"] != "cc-1234" {
+		t.Errorf("unexpected labels %v", got)
+	}
+	if diff := cmp.Diff(map[string]string{"example.com/notify": "#builds"}, created.GetAnnotations()); diff != "" {
+		t.Errorf("annotations: -want +got: %s", diff)
+	}
+}
+
+func TestCreateResources_DefaultNamespace(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	creator := &resources.FakeCreator{}
+	r := Sink{
+		EventListenerName: "test-el",
+		Logger:            logger,
+		Creator:           creator,
+	}
+
+	res := []json.RawMessage{
+		json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"defaulted"}}`),
+		json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"explicit","namespace":"templated"}}`),
+	}
+	if _, err := r.createResources(namespace, "team-a", "", resourceMetadata{}, res, "my-trigger", eventID, logger); err != nil {
+		t.Fatalf("createResources() returned error: %v", err)
+	}
+	var got []string
+	for _, u := range creator.Created() {
+		got = append(got, u.GetNamespace()+"/"+u.GetName())
+	}
+	if diff := cmp.Diff([]string{"team-a/defaulted", "templated/explicit"}, got); diff != "" {
+		t.Errorf("created resources: -want +got: %s", diff)
+	}
+}
+
+func TestCreateResources_DefaultType(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	creator := &resources.FakeCreator{}
+	r := Sink{
+		EventListenerName: "test-el",
+		Logger:            logger,
+		Creator:           creator,
+	}
+
+	res := []json.RawMessage{
+		json.RawMessage(`{"metadata":{"name":"defaulted"},"spec":{"pipelineRef":{"name":"build"}}}`),
+		json.RawMessage(`{"kind":"TaskRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"explicit"}}`),
+	}
+	meta := resourceMetadata{defaultType: metav1.TypeMeta{APIVersion: "tekton.dev/v1beta1", Kind: "PipelineRun"}}
+	if _, err := r.createResources(namespace, namespace, "", meta, res, "my-trigger", eventID, logger); err != nil {
+		t.Fatalf("createResources() returned error: %v", err)
+	}
+	var got []string
+	for _, u := range creator.Created() {
+		got = append(got, u.GetKind()+"/"+u.GetName())
+	}
+	if diff := cmp.Diff([]string{"PipelineRun/defaulted", "TaskRun/explicit"}, got); diff != "" {
+		t.Errorf("created resources: -want +got: %s", diff)
+	}
+}
+
+func TestCreateResources_KindPolicy(t *testing.T) {
+	defer metricstest.Unregister("resource_kind_not_allowed_count")
+	logger := zaptest.NewLogger(t).Sugar()
+	metrics.FlushExporter()
+	if err := metrics.UpdateExporter(context.TODO(), metrics.ExporterOptions{
+		Domain:    "tekton.dev/triggers",
+		Component: "triggers",
+		ConfigMap: map[string]string{},
+	}, logger); err != nil {
+		t.Fatal(err)
+	}
+	recorder, err := NewRecorder()
+	if err != nil {
+		t.Fatalf("NewRecorder() returned error: %v", err)
+	}
+	creator := &resources.FakeCreator{}
+	r := Sink{
+		EventListenerName: "test-el",
+		Logger:            logger,
+		Recorder:          recorder,
+		Creator:           creator,
+		CreateBestEffort:  true,
+	}
+
+	res := []json.RawMessage{
+		json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"allowed"}}`),
+		json.RawMessage(`{"kind":"Secret","apiVersion":"v1","metadata":{"name":"denied"}}`),
+	}
+	meta := resourceMetadata{kindPolicy: &triggersv1beta1.ResourceKindPolicy{
+		Allowed: []triggersv1beta1.ResourceKind{{Group: "tekton.dev", Kind: "PipelineRun"}},
+	}}
+	_, err = r.createResources(namespace, namespace, "", meta, res, "my-trigger", eventID, logger)
+	var kindErr *resources.KindNotAllowedError
+	if !errors.As(err, &kindErr) || kindErr.Name != "denied" {
+		t.Fatalf("createResources() returned error %v, want a KindNotAllowedError for the Secret", err)
+	}
+	var got []string
+	for _, u := range creator.Created() {
+		got = append(got, u.GetKind()+"/"+u.GetName())
+	}
+	if diff := cmp.Diff([]string{"PipelineRun/allowed"}, got); diff != "" {
+		t.Errorf("created resources: -want +got: %s", diff)
+	}
+	if diff := cmp.Diff(map[string]int64{"Secret my-trigger v1": 1}, metricCounts(t, "resource_kind_not_allowed_count")); diff != "" {
+		t.Errorf("resource_kind_not_allowed_count (-want +got): %s", diff)
+	}
+}
+
+func TestDefaultNamespace(t *testing.T) {
+	params := []triggersv1beta1.Param{{Name: "empty", Value: ""}, {Name: "team", Value: "team-b"}, {Name: "invalid", Value: "Team B"}}
+	for _, tc := range []struct {
+		name    string
+		spec    triggersv1beta1.TriggerSpec
+		want    string
+		wantErr bool
+	}{{
+		name: "namespace of the trigger",
+		want: namespace,
+	}, {
+		name: "default namespace",
+		spec: triggersv1beta1.TriggerSpec{DefaultNamespace: "team-a", NamespaceParam: "team"},
+		want: "team-a",
+	}, {
+		name: "namespace param",
+		spec: triggersv1beta1.TriggerSpec{NamespaceParam: "team"},
+		want: "team-b",
+	}, {
+		name: "empty namespace param",
+		spec: triggersv1beta1.TriggerSpec{NamespaceParam: "empty"},
+		want: namespace,
+	}, {
+		name: "missing namespace param",
+		spec: triggersv1beta1.TriggerSpec{NamespaceParam: "missing"},
+		want: namespace,
+	}, {
+		name:    "invalid namespace param",
+		spec:    triggersv1beta1.TriggerSpec{NamespaceParam: "invalid"},
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := triggersv1beta1.Trigger{ObjectMeta: metav1.ObjectMeta{Name: "my-trigger", Namespace: namespace}, Spec: tc.spec}
+			got, err := defaultNamespace(tr, params)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("defaultNamespace() error = %v, wantErr %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("defaultNamespace() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResourceTTL(t *testing.T) {
+	params := []triggersv1beta1.Param{{Name: "empty", Value: ""}, {Name: "ttl", Value: "2h"}, {Name: "invalid", Value: "forever"}}
+	day := &metav1.Duration{Duration: 24 * time.Hour}
+	for _, tc := range []struct {
+		name    string
+		ttl     *triggersv1beta1.ResourceTTL
+		want    time.Duration
+		wantErr bool
+	}{{
+		name: "no resource TTL",
+	}, {
+		name: "duration",
+		ttl:  &triggersv1beta1.ResourceTTL{After: day},
+		want: 24 * time.Hour,
+	}, {
+		name: "param",
+		ttl:  &triggersv1beta1.ResourceTTL{After: day, Param: "ttl"},
+		want: 2 * time.Hour,
+	}, {
+		name: "empty param",
+		ttl:  &triggersv1beta1.ResourceTTL{After: day, Param: "empty"},
+		want: 24 * time.Hour,
+	}, {
+		name: "missing param without duration",
+		ttl:  &triggersv1beta1.ResourceTTL{Param: "missing"},
+	}, {
+		name:    "invalid param",
+		ttl:     &triggersv1beta1.ResourceTTL{After: day, Param: "invalid"},
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := triggersv1beta1.Trigger{ObjectMeta: metav1.ObjectMeta{Name: "my-trigger", Namespace: namespace}, Spec: triggersv1beta1.TriggerSpec{ResourceTTL: tc.ttl}}
+			got, _, err := resourceTTL(tr, params)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("resourceTTL() error = %v, wantErr %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("resourceTTL() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCreateResources_RollbackOnFailure(t *testing.T) {
+	res := []json.RawMessage{
+		json.RawMessage(`{"kind":"TaskRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"first"}}`),
+		json.RawMessage(`{"kind":"TaskRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"patched","annotations":{"triggers.tekton.dev/patch-strategy":"merge"}}}`),
+		json.RawMessage(`{"kind":"TaskRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"second"}}`),
+		json.RawMessage(`{"kind":"Unknown","apiVersion":"example.com/v1","metadata":{"name":"failing"}}`),
+		json.RawMessage(`{"kind":"TaskRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"never"}}`),
+	}
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+
+	for _, tc := range []struct {
+		name        string
+		rollback    bool
+		meta        resourceMetadata
+		wantCreated []string
+		wantLeft    []string
+	}{{
+		name:        "without rollback",
+		wantCreated: []string{"first", "patched", "second"},
+		wantLeft:    []string{"first", "patched", "second"},
+	}, {
+		name:        "with rollback",
+		rollback:    true,
+		wantCreated: []string{"patched"},
+		wantLeft:    []string{"patched"},
+	}, {
+		name:     "with rollback of resources that may have been updated",
+		rollback: true,
+		meta: resourceMetadata{createStrategies: []triggersv1beta1.ResourceCreateStrategy{
+			{ResourceKind: triggersv1beta1.ResourceKind{Group: "tekton.dev", Kind: "TaskRun"}, Strategy: triggersv1beta1.CreateStrategyCreateOrUpdate},
+		}},
+		wantCreated: []string{"first", "patched", "second"},
+		wantLeft:    []string{"first", "patched", "second"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, dynamicClient := getSinkAssets(t, test.Resources{}, "test-el", nil)
+			r.RollbackOnFailure = tc.rollback
+
+			created, err := r.createResources(namespace, namespace, "", tc.meta, res, "my-trigger", eventID, r.Logger)
+			if err == nil {
+				t.Fatal("expected createResources() to return an error")
+			}
+			var gotCreated []string
+			for _, u := range created {
+				gotCreated = append(gotCreated, u.GetName())
+			}
+			if diff := cmp.Diff(tc.wantCreated, gotCreated); diff != "" {
+				t.Errorf("created resources: -want +got: %s", diff)
+			}
+
+			var gotLeft []string
+			for _, name := range []string{"first", "patched", "second", "never"} {
+				if _, err := dynamicClient.Resource(gvr).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{}); err == nil {
+					gotLeft = append(gotLeft, name)
+				}
+			}
+			if diff := cmp.Diff(tc.wantLeft, gotLeft); diff != "" {
+				t.Errorf("resources left: -want +got: %s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateResources_BestEffort(t *testing.T) {
+	res := []json.RawMessage{
+		json.RawMessage(`{"kind":"TaskRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"first"}}`),
+		json.RawMessage(`{"kind":"Unknown","apiVersion":"example.com/v1","metadata":{"name":"failing"}}`),
+		json.RawMessage(`{"kind":"TaskRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"second"}}`),
+		json.RawMessage(`{"kind":"Other","apiVersion":"example.com/v1","metadata":{"name":"failing-too"}}`),
+	}
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+
+	r, dynamicClient := getSinkAssets(t, test.Resources{}, "test-el", nil)
+	r.CreateBestEffort = true
+	// The resources created aren't rolled back when the failures are isolated.
+	r.RollbackOnFailure = true
+
+	created, err := r.createResources(namespace, namespace, "", resourceMetadata{}, res, "my-trigger", eventID, r.Logger)
+	var failed ResourceTemplateErrors
+	if !errors.As(err, &failed) {
+		t.Fatalf("createResources() returned error %v, want ResourceTemplateErrors", err)
+	}
+	var gotFailed []string
+	for _, f := range failed {
+		gotFailed = append(gotFailed, fmt.Sprintf("%d %s", f.Index, f.Kind))
+	}
+	if diff := cmp.Diff([]string{"1 Unknown", "3 Other"}, gotFailed); diff != "" {
+		t.Errorf("failed templates: -want +got: %s", diff)
+	}
+	if !strings.HasPrefix(err.Error(), "failed to create 2 resources: resource template 1 (Unknown): ") {
+		t.Errorf("createResources() returned error %q, want the errors of the templates", err)
+	}
+
+	var gotCreated []string
+	for _, u := range created {
+		gotCreated = append(gotCreated, u.GetName())
+	}
+	if diff := cmp.Diff([]string{"first", "second"}, gotCreated); diff != "" {
+		t.Errorf("created resources: -want +got: %s", diff)
+	}
+	for _, name := range gotCreated {
+		if _, err := dynamicClient.Resource(gvr).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{}); err != nil {
+			t.Errorf("Get() %s returned error: %v", name, err)
+		}
+	}
+}
+
+func TestCreateResources_CreateTimeout(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	recorder, err := NewRecorder()
+	if err != nil {
+		t.Fatalf("NewRecorder() returned error: %v", err)
+	}
+	wantErr := errors.New("boom")
+	r := Sink{
+		EventListenerName: "test-el",
+		Logger:            logger,
+		Recorder:          recorder,
+		CreateTimeout:     10 * time.Millisecond,
+	}
+	res := []json.RawMessage{
+		json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"first"}}`),
+	}
+
+	// A hung API server only returns once the context is done.
+	r.Creator = resources.CreatorFunc(func(ctx context.Context, _ *zap.SugaredLogger, _ json.RawMessage, _, _, _, _ string, _ discoveryclient.ServerResourcesInterface, _ dynamic.Interface) (*unstructured.Unstructured, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err := r.CreateResources(namespace, "", "", res, "my-trigger", eventID, logger); !errors.Is(err, ErrCreateTimeout) {
+		t.Errorf("CreateResources() = %v, want %v", err, ErrCreateTimeout)
+	}
+
+	// Errors returned before the deadline are not timeouts.
+	r.Creator = resources.CreatorFunc(func(context.Context, *zap.SugaredLogger, json.RawMessage, string, string, string, string, discoveryclient.ServerResourcesInterface, dynamic.Interface) (*unstructured.Unstructured, error) {
+		return nil, wantErr
+	})
+	if err := r.CreateResources(namespace, "", "", res, "my-trigger", eventID, logger); !errors.Is(err, wantErr) || errors.Is(err, ErrCreateTimeout) {
+		t.Errorf("CreateResources() = %v, want %v", err, wantErr)
+	}
+}
+
+func TestCreateResources_TemplateCreateTimeout(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	recorder, err := NewRecorder()
+	if err != nil {
+		t.Fatalf("NewRecorder() returned error: %v", err)
+	}
+	r := Sink{
+		EventListenerName: "test-el",
+		Logger:            logger,
+		Recorder:          recorder,
+		CreateTimeout:     time.Hour,
+		CreateBestEffort:  true,
+	}
+	res := []json.RawMessage{
+		json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"slow","annotations":{"triggers.tekton.dev/create-timeout":"10ms"}}}`),
+		json.RawMessage(`{"kind":"PipelineRun","apiVersion":"tekton.dev/v1beta1","metadata":{"name":"fast"}}`),
+	}
+
+	// The API server hangs on the first resource, which is abandoned after the timeout of its template rather
+	// than of the EventListener, and the next one is still created.
+	var names []string
+	r.Creator = resources.CreatorFunc(func(ctx context.Context, _ *zap.SugaredLogger, rt json.RawMessage, _, _, _, _ string, _ discoveryclient.ServerResourcesInterface, _ dynamic.Interface) (*unstructured.Unstructured, error) {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(rt); err != nil {
+			return nil, err
+		}
+		if obj.GetName() == "slow" {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		names = append(names, obj.GetName())
+		return obj, nil
+	})
+	err = r.CreateResources(namespace, "", "", res, "my-trigger", eventID, logger)
+	if !errors.Is(err, ErrCreateTimeout) || !strings.Contains(err.Error(), "resource template 0 (PipelineRun): resource creation timed out after 10ms") {
+		t.Errorf("CreateResources() = %v, want the timeout of the first template", err)
+	}
+	if diff := cmp.Diff([]string{"fast"}, names); diff != "" {
+		t.Errorf("created resources: -want +got: %s", diff)
+	}
+}
+
 func TestExtendBodyWithExtensions(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -1752,6 +2949,7 @@ func TestCloudEventHandling(t *testing.T) {
 		"eventListener":    "test-el",
 		"eventListenerUID": "el-uid",
 		"namespace":        "foo",
+		"triggers":         []interface{}{"git-clone-trigger"},
 	}
 	if diff := cmp.Diff(wantEvent, decoded); diff != "" {
 		t.Errorf("CloudEvent: -want +got: %s", diff)
@@ -1766,8 +2964,8 @@ func TestCloudEventHandling(t *testing.T) {
 			Name:      "git-clone-run",
 			Namespace: namespace,
 			Labels: map[string]string{
-				"app":                                  "bar\t\r\nbaz昨",
-				"type":                                 "application/json",
+				"app":                                  "bar---baz-8d4d24b9ba",
+				"type":                                 "application-json-bacb769b46",
 				"triggers.tekton.dev/eventlistener":    elName,
 				"triggers.tekton.dev/trigger":          "git-clone-trigger",
 				"triggers.tekton.dev/triggers-eventid": "12345",
@@ -0,0 +1,214 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/resources"
+	"google.golang.org/grpc/codes"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// CreatedResource identifies a resource created for an event, in the responses of synchronous sinks.
+type CreatedResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	UID        string `json:"uid,omitempty"`
+	// Trigger is the name of the trigger that created the resource
+	Trigger string `json:"trigger"`
+}
+
+// object returns the identity of the created resource as an object.
+func (c CreatedResource) object() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(c.APIVersion)
+	obj.SetKind(c.Kind)
+	obj.SetNamespace(c.Namespace)
+	obj.SetName(c.Name)
+	return obj
+}
+
+// triggerOutcomes records the outcome of processing the triggers and trigger groups of an event.
+// It is safe for concurrent use by the goroutines processing the event.
+type triggerOutcomes struct {
+	mu        sync.Mutex
+	fired     []string
+	resources []CreatedResource
+	rejected  []triggerRejection
+	failed    []triggerFailure
+	// headers are the response headers set by the interceptors of each trigger and trigger group.
+	headers map[string]map[string]string
+}
+
+// triggerRejection is a trigger or trigger group whose interceptors stopped processing the event.
+type triggerRejection struct {
+	name   string
+	status triggersv1.Status
+}
+
+// triggerFailure is a trigger or trigger group that failed to process the event.
+type triggerFailure struct {
+	name string
+	err  error
+}
+
+// fire records that the trigger created resources for the event.
+func (o *triggerOutcomes) fire(name string, created []*unstructured.Unstructured) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.fired = append(o.fired, name)
+	o.addResources(name, created)
+}
+
+// reject records that the interceptors of the trigger or trigger group stopped processing the event.
+func (o *triggerOutcomes) reject(name string, status triggersv1.Status) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.rejected = append(o.rejected, triggerRejection{name: name, status: status})
+}
+
+// fail records that the trigger or trigger group failed to process the event, after creating the
+// given resources, if any.
+func (o *triggerOutcomes) fail(name string, created []*unstructured.Unstructured, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.failed = append(o.failed, triggerFailure{name: name, err: err})
+	o.addResources(name, created)
+}
+
+func (o *triggerOutcomes) addResources(trigger string, created []*unstructured.Unstructured) {
+	o.resources = append(o.resources, createdResources(trigger, created)...)
+}
+
+// createdResources returns the identities of the resources created by the trigger.
+func createdResources(trigger string, created []*unstructured.Unstructured) []CreatedResource {
+	resources := []CreatedResource{}
+	for _, obj := range created {
+		if obj == nil {
+			continue
+		}
+		resources = append(resources, CreatedResource{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+			UID:        string(obj.GetUID()),
+			Trigger:    trigger,
+		})
+	}
+	return resources
+}
+
+// list returns a sorted copy of the names of the triggers that fired.
+func (o *triggerOutcomes) list() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	names := append([]string{}, o.fired...)
+	sort.Strings(names)
+	return names
+}
+
+// createdResources returns the created resources, sorted by trigger and in creation order.
+func (o *triggerOutcomes) createdResources() []CreatedResource {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	created := append([]CreatedResource{}, o.resources...)
+	sort.SliceStable(created, func(i, j int) bool { return created[i].Trigger < created[j].Trigger })
+	return created
+}
+
+// status returns the HTTP status code of the response of a synchronous sink once all the triggers of
+// the event are processed, with an error message for the sender if the status is not a success:
+//
+// - a 5xx code if a trigger failed, even if others created resources, so that the sender can retry;
+// - 422 Unprocessable Entity if a trigger failed because the event exceeded the resources it can create;
+// - 201 Created if resources were created;
+// - the code matching the status returned by the interceptors if they rejected the event, usually 4xx;
+// - 200 OK if the event was processed without creating anything, e.g. if no triggers matched.
+func (o *triggerOutcomes) status() (int, string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.failed) > 0 {
+		failed := append([]triggerFailure{}, o.failed...)
+		sort.Slice(failed, func(i, j int) bool { return failed[i].name < failed[j].name })
+		// The errors are logged, and may contain details of the cluster that the sender shouldn't see.
+		return failureStatus(failed[0].err), fmt.Sprintf("failed to process the event for %s", failed[0].name)
+	}
+	if len(o.resources) > 0 {
+		return http.StatusCreated, ""
+	}
+	if len(o.rejected) > 0 {
+		rejected := append([]triggerRejection{}, o.rejected...)
+		sort.Slice(rejected, func(i, j int) bool { return rejected[i].name < rejected[j].name })
+		return httpStatus(rejected[0].status.Code), fmt.Sprintf("%s rejected the event: %s", rejected[0].name, rejected[0].status.Message)
+	}
+	return http.StatusOK, ""
+}
+
+// failureStatus returns the HTTP status code of the response of a synchronous sink to an event that a
+// trigger failed to process with err.
+func failureStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrCreationLimitExceeded):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrEventCreateLimitExceeded):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, ErrCreateTimeout), errors.Is(err, ErrInterceptorChainTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, ErrQuotaRetryDropped), errors.Is(err, resources.ErrDiscoveryNotReady):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// httpStatus returns the HTTP status code matching the status code returned by an interceptor that
+// rejected an event.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK, codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		// Interceptors stop handshakes, such as SNS subscription confirmations, with an OK status. The
+		// event was still rejected: no trigger processed it.
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Canceled:
+		return 499
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	triggersv1alpha1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	triggersv1beta1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/resources"
+	"github.com/tektoncd/triggers/test"
+	"google.golang.org/grpc/codes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/ptr"
+)
+
+func TestTriggerOutcomes_Status(t *testing.T) {
+	taskRun := &unstructured.Unstructured{}
+	taskRun.SetAPIVersion("tekton.dev/v1beta1")
+	taskRun.SetKind("TaskRun")
+	taskRun.SetNamespace(namespace)
+	taskRun.SetName("run")
+
+	for _, tc := range []struct {
+		name     string
+		record   func(o *triggerOutcomes)
+		wantCode int
+		wantMsg  string
+	}{{
+		name:     "nothing processed",
+		record:   func(o *triggerOutcomes) {},
+		wantCode: http.StatusOK,
+	}, {
+		name: "fired without resources",
+		record: func(o *triggerOutcomes) {
+			o.fire("fires", nil)
+		},
+		wantCode: http.StatusOK,
+	}, {
+		name: "created resources",
+		record: func(o *triggerOutcomes) {
+			o.fire("fires", []*unstructured.Unstructured{taskRun})
+			o.reject("filtered", triggersv1beta1.Status{Code: codes.FailedPrecondition, Message: "expression was false"})
+		},
+		wantCode: http.StatusCreated,
+	}, {
+		name: "rejected",
+		record: func(o *triggerOutcomes) {
+			o.reject("unauthenticated", triggersv1beta1.Status{Code: codes.Unauthenticated, Message: "no token"})
+			o.reject("filtered", triggersv1beta1.Status{Code: codes.FailedPrecondition, Message: "expression was false"})
+		},
+		wantCode: http.StatusBadRequest,
+		wantMsg:  "filtered rejected the event: expression was false",
+	}, {
+		name: "stopped with an OK status",
+		record: func(o *triggerOutcomes) {
+			o.reject("subscribe", triggersv1beta1.Status{Code: codes.OK, Message: "confirmed the subscription"})
+		},
+		wantCode: http.StatusBadRequest,
+		wantMsg:  "subscribe rejected the event: confirmed the subscription",
+	}, {
+		name: "failed after creating resources",
+		record: func(o *triggerOutcomes) {
+			o.fire("fires", []*unstructured.Unstructured{taskRun})
+			o.fail("broken", []*unstructured.Unstructured{taskRun}, errors.New("couldn't create resource"))
+		},
+		wantCode: http.StatusInternalServerError,
+		wantMsg:  "failed to process the event for broken",
+	}, {
+		name: "creation limit exceeded",
+		record: func(o *triggerOutcomes) {
+			o.fail("limited", nil, fmt.Errorf("trigger limited: %w", ErrCreationLimitExceeded))
+		},
+		wantCode: http.StatusTooManyRequests,
+		wantMsg:  "failed to process the event for limited",
+	}, {
+		name: "create timeout",
+		record: func(o *triggerOutcomes) {
+			o.fail("slow", nil, fmt.Errorf("trigger slow: %w", ErrCreateTimeout))
+		},
+		wantCode: http.StatusGatewayTimeout,
+		wantMsg:  "failed to process the event for slow",
+	}, {
+		name: "quota exceeded",
+		record: func(o *triggerOutcomes) {
+			o.fail("quota", nil, fmt.Errorf("%w: exceeded quota", ErrQuotaRetryDropped))
+		},
+		wantCode: http.StatusServiceUnavailable,
+		wantMsg:  "failed to process the event for quota",
+	}, {
+		name: "discovery not ready",
+		record: func(o *triggerOutcomes) {
+			o.fail("starting", nil, fmt.Errorf("couldn't find API resource for json: %w", resources.ErrDiscoveryNotReady))
+		},
+		wantCode: http.StatusServiceUnavailable,
+		wantMsg:  "failed to process the event for starting",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &triggerOutcomes{}
+			tc.record(o)
+			code, msg := o.status()
+			if code != tc.wantCode {
+				t.Errorf("status() got code %d, want %d", code, tc.wantCode)
+			}
+			if msg != tc.wantMsg {
+				t.Errorf("status() got message %q, want %q", msg, tc.wantMsg)
+			}
+		})
+	}
+}
+
+func TestHandleEvent_Synchronous(t *testing.T) {
+	eventBody := json.RawMessage(`{"head_commit": {"id": "testrevision"}, "repository": {"url": "testurl"}, "foo": "bar"}`)
+	gitCloneTrigger := func(name string, filter string) triggersv1beta1.EventListenerTrigger {
+		return triggersv1beta1.EventListenerTrigger{
+			Name: name,
+			Interceptors: []*triggersv1beta1.TriggerInterceptor{{
+				Ref: triggersv1beta1.InterceptorRef{Name: "cel", Kind: triggersv1beta1.ClusterInterceptorKind},
+				Params: []triggersv1beta1.InterceptorParams{{
+					Name:  "filter",
+					Value: test.ToV1JSON(t, filter),
+				}},
+			}},
+			Bindings: []*triggersv1beta1.EventListenerBinding{
+				{Name: "url", Value: ptr.String("$(body.repository.url)")},
+				{Name: "revision", Value: ptr.String("$(body.head_commit.id)")},
+			},
+			Template: &triggersv1beta1.EventListenerTemplate{Spec: makeGitCloneTTSpec(t, name+"-run")},
+		}
+	}
+
+	for _, tc := range []struct {
+		name         string
+		triggers     []triggersv1beta1.EventListenerTrigger
+		wantCode     int
+		wantLocation string
+		wantBody     Response
+	}{{
+		name:         "created resources",
+		triggers:     []triggersv1beta1.EventListenerTrigger{gitCloneTrigger("fires", "has(body.head_commit)"), gitCloneTrigger("filtered", "has(body.missing)")},
+		wantCode:     http.StatusCreated,
+		wantLocation: "/apis/tekton.dev/v1beta1/namespaces/" + namespace + "/taskruns/fires-run",
+		wantBody: Response{
+			EventListener:    "test-el",
+			EventListenerUID: elUID,
+			Namespace:        namespace,
+			EventID:          eventID,
+			Triggers:         []string{"fires", "filtered"},
+			Resources: []CreatedResource{{
+				APIVersion: "tekton.dev/v1beta1",
+				Kind:       "TaskRun",
+				Namespace:  namespace,
+				Name:       "fires-run",
+				Trigger:    "fires",
+			}},
+		},
+	}, {
+		name:     "rejected",
+		triggers: []triggersv1beta1.EventListenerTrigger{gitCloneTrigger("filtered", "has(body.missing)")},
+		wantCode: http.StatusBadRequest,
+		wantBody: Response{
+			EventListener:    "test-el",
+			EventListenerUID: elUID,
+			Namespace:        namespace,
+			EventID:          eventID,
+			Triggers:         []string{"filtered"},
+			ErrorMessage:     "filtered rejected the event: expression has(body.missing) did not return true",
+		},
+	}, {
+		name:     "no triggers",
+		wantCode: http.StatusOK,
+		wantBody: Response{
+			EventListener:    "test-el",
+			EventListenerUID: elUID,
+			Namespace:        namespace,
+			EventID:          eventID,
+			Message:          noTriggersMatchedMessage,
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			resources := test.Resources{
+				EventListeners: []*triggersv1beta1.EventListener{{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-el",
+						Namespace: namespace,
+						UID:       types.UID(elUID),
+					},
+					Spec: triggersv1beta1.EventListenerSpec{
+						Triggers: tc.triggers,
+					},
+				}},
+				ClusterInterceptors: []*triggersv1alpha1.ClusterInterceptor{cel},
+			}
+			sink, _ := getSinkAssets(t, resources, "test-el", nil)
+			sink.Synchronous = true
+
+			ts := httptest.NewServer(http.HandlerFunc(sink.HandleEvent))
+			defer ts.Close()
+
+			resp, err := http.Post(ts.URL, "application/json", bytes.NewReader(eventBody))
+			if err != nil {
+				t.Fatalf("error making request to eventListener: %s", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.wantCode {
+				t.Errorf("got response code %d, want %d", resp.StatusCode, tc.wantCode)
+			}
+			if got := resp.Header.Get("Location"); got != tc.wantLocation {
+				t.Errorf("got Location header %q, want %q", got, tc.wantLocation)
+			}
+			var gotBody Response
+			if err := json.NewDecoder(resp.Body).Decode(&gotBody); err != nil {
+				t.Fatalf("Error reading response body: %s", err)
+			}
+			if diff := cmp.Diff(tc.wantBody, gotBody); diff != "" {
+				t.Errorf("did not get expected response back -want,+got: %s", diff)
+			}
+		})
+	}
+}
@@ -18,12 +18,37 @@ package sink
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 )
 
+// defaultPayloadParsers are the payload parsers of the sinks without PayloadParsers.
+var defaultPayloadParsers = DefaultPayloadParsers()
+
+func (r Sink) payloadParsers() *PayloadParsers {
+	if r.PayloadParsers != nil {
+		return r.PayloadParsers
+	}
+	return defaultPayloadParsers
+}
+
+// rawPayloadKey is the context key of the body of a request as it was sent, before its payload parser
+// converted it.
+type rawPayloadKey struct{}
+
+// rawPayloadFrom returns the body of the request with the given context as it was sent, once decoded, if
+// its payload parser converted it into another payload, and nil otherwise.
+func rawPayloadFrom(ctx context.Context) []byte {
+	raw, _ := ctx.Value(rawPayloadKey{}).([]byte)
+	return raw
+}
+
+// IsValidPayload replaces the body of requests with the payload its payload parsers convert it into, and,
+// if payload validation is enabled, rejects the requests whose payload is not a JSON object. The body as
+// it was sent is kept for the interceptors verifying its signature.
 func (r Sink) IsValidPayload(eventHandler http.Handler) http.Handler {
 	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
 		payload, err := ioutil.ReadAll(request.Body)
@@ -34,25 +59,44 @@ func (r Sink) IsValidPayload(eventHandler http.Handler) http.Handler {
 			response.WriteHeader(http.StatusInternalServerError)
 			return
 		}
+		decoded, err := r.payloadParsers().Decode(payload, request.Header)
+		if err != nil {
+			r.rejectPayload(response, fmt.Sprintf("Invalid event body: %s", err))
+			return
+		}
+		parsed, err := r.payloadParsers().Convert(decoded, request.Header)
+		if err != nil {
+			r.rejectPayload(response, fmt.Sprintf("Invalid event body: %s", err))
+			return
+		}
+		if !bytes.Equal(parsed, decoded) {
+			request = request.WithContext(context.WithValue(request.Context(), rawPayloadKey{}, decoded))
+		}
+		request.Body = ioutil.NopCloser(bytes.NewBuffer(parsed))
+		request.ContentLength = int64(len(parsed))
 		if r.PayloadValidation {
 			var event map[string]interface{}
-			if err := json.Unmarshal(payload, &event); err != nil {
-				errMsg := fmt.Sprintf("Invalid event body format : %s", err)
-				r.recordCountMetrics(failTag)
-				r.Logger.Error(errMsg)
-				response.WriteHeader(http.StatusBadRequest)
-				response.Header().Set("Content-Type", "application/json")
-				body := Response{
-					EventListener: r.EventListenerName,
-					Namespace:     r.EventListenerNamespace,
-					ErrorMessage:  errMsg,
-				}
-				if err := json.NewEncoder(response).Encode(body); err != nil {
-					r.Logger.Errorf("failed to write back sink response: %v", err)
-				}
+			if err := json.Unmarshal(parsed, &event); err != nil {
+				r.rejectPayload(response, fmt.Sprintf("Invalid event body format : %s", err))
 				return
 			}
 		}
 		eventHandler.ServeHTTP(response, request)
 	})
 }
+
+// rejectPayload responds to a request with an invalid payload with 400 Bad Request.
+func (r Sink) rejectPayload(response http.ResponseWriter, errMsg string) {
+	r.recordCountMetrics(failTag)
+	r.Logger.Error(errMsg)
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(http.StatusBadRequest)
+	body := Response{
+		EventListener: r.EventListenerName,
+		Namespace:     r.EventListenerNamespace,
+		ErrorMessage:  errMsg,
+	}
+	if err := json.NewEncoder(response).Encode(body); err != nil {
+		r.Logger.Errorf("failed to write back sink response: %v", err)
+	}
+}
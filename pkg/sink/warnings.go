@@ -0,0 +1,41 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"go.uber.org/zap"
+	"k8s.io/client-go/rest"
+)
+
+// warningLogger logs the warnings returned by the API server, such as the unknown fields of the
+// created resources when their field validation is Warn.
+type warningLogger struct {
+	logger *zap.SugaredLogger
+}
+
+// NewWarningLogger returns a rest.WarningHandler writing the API server warnings to logger.
+func NewWarningLogger(logger *zap.SugaredLogger) rest.WarningHandler {
+	return warningLogger{logger: logger}
+}
+
+// HandleWarningHeader logs the warnings sent with the 299 code, the only one used by the API server.
+func (w warningLogger) HandleWarningHeader(code int, agent string, message string) {
+	if code != 299 || message == "" {
+		return
+	}
+	w.logger.Warnw("API server warning: "+message, "agent", agent)
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWarningLogger(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	w := NewWarningLogger(zap.New(core).Sugar())
+
+	w.HandleWarningHeader(299, "-", `unknown field "spec.pipelineRef.nmae"`)
+	w.HandleWarningHeader(199, "-", "miscellaneous warning")
+	w.HandleWarningHeader(299, "-", "")
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d log entries, want 1: %v", len(entries), entries)
+	}
+	if want := `API server warning: unknown field "spec.pipelineRef.nmae"`; entries[0].Message != want {
+		t.Errorf("got log message %q, want %q", entries[0].Message, want)
+	}
+}
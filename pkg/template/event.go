@@ -18,8 +18,12 @@ package template
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -36,23 +40,45 @@ const (
 	OldEscapeAnnotation = "triggers.tekton.dev/old-escape-quotes"
 )
 
+// TriggerContext holds the values of the context field of events, e.g. $(context.eventID) and
+// $(context.eventListener.name).
 type TriggerContext struct {
-	EventID string `json:"eventID"`
+	EventID       string               `json:"eventID"`
+	EventListener EventListenerContext `json:"eventListener"`
+
+	// jsonNumbers is how the numbers of the body are decoded.
+	jsonNumbers triggersv1.JSONNumbers
+}
+
+// EventListenerContext identifies the EventListener that processes an event.
+type EventListenerContext struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	UID       string `json:"uid"`
 }
 
 func NewTriggerContext(eventID string) TriggerContext {
 	return TriggerContext{EventID: eventID}
 }
 
+// WithEventListener returns a copy of the context with the EventListener el.
+func (c TriggerContext) WithEventListener(el *triggersv1.EventListener) TriggerContext {
+	if el != nil {
+		c.EventListener = EventListenerContext{Name: el.Name, Namespace: el.Namespace, UID: string(el.UID)}
+		c.jsonNumbers = el.Spec.JSONNumbers
+	}
+	return c
+}
+
 // ResolveParams takes given triggerbindings and produces the resulting
 // resource params.
-func ResolveParams(rt ResolvedTrigger, body []byte, header http.Header, extensions map[string]interface{}, triggerContext TriggerContext) ([]triggersv1.Param, error) {
+func ResolveParams(rt ResolvedTrigger, body []byte, header http.Header, query url.Values, extensions map[string]interface{}, triggerContext TriggerContext) ([]triggersv1.Param, error) {
 	var ttParams []triggersv1.ParamSpec
 	if rt.TriggerTemplate != nil {
 		ttParams = rt.TriggerTemplate.Spec.Params
 	}
 
-	out, err := applyEventValuesToParams(rt.BindingParams, body, header, extensions, ttParams, triggerContext)
+	out, err := applyEventValuesToParams(rt.BindingParams, body, header, query, extensions, rt.PromotedExtensions, ttParams, triggerContext)
 	if err != nil {
 		return nil, fmt.Errorf("failed to ApplyEventValuesToParams: %w", err)
 	}
@@ -60,74 +86,241 @@ func ResolveParams(rt ResolvedTrigger, body []byte, header http.Header, extensio
 	return out, nil
 }
 
-// ResolveResources resolves a templated resource by replacing params with their values.
-func ResolveResources(template *triggersv1.TriggerTemplate, params []triggersv1.Param) []json.RawMessage {
-	resources := make([]json.RawMessage, len(template.Spec.ResourceTemplates))
+// ResolveResources resolves a templated resource by replacing params with their values. Multi-document
+// resource templates are split into their documents before the params are replaced, so that the values
+// of params can't change the structure of the documents. If the template has a selection, only the
+// resources of the selected resource template are resolved.
+func ResolveResources(template *triggersv1.TriggerTemplate, params []triggersv1.Param) ([]json.RawMessage, error) {
+	selected, err := SelectResourceTemplate(template, params)
+	if err != nil {
+		return nil, err
+	}
+	return ResolveSelectedResources(template, params, selected)
+}
+
+// ResolveSelectedResources resolves the resources of the resource template at index selected like
+// ResolveResources, or the resources of all the resource templates if selected is negative.
+func ResolveSelectedResources(template *triggersv1.TriggerTemplate, params []triggersv1.Param, selected int) ([]json.RawMessage, error) {
+	resources := make([]json.RawMessage, 0, len(template.Spec.ResourceTemplates))
 	uid := UUID()
 
 	oldEscape := metav1.HasAnnotation(template.ObjectMeta, OldEscapeAnnotation)
 
-	for i := range template.Spec.ResourceTemplates {
-		resources[i] = applyParamsToResourceTemplate(params, template.Spec.ResourceTemplates[i].RawExtension.Raw, oldEscape)
-		resources[i] = applyUIDToResourceTemplate(resources[i], uid)
+	for i, trt := range template.Spec.ResourceTemplates {
+		if selected >= 0 && i != selected {
+			continue
+		}
+		docs, err := trt.Documents()
+		if err != nil {
+			return nil, fmt.Errorf("resource template %d of TriggerTemplate %s: %w", i, template.Name, err)
+		}
+		for _, doc := range docs {
+			rt := applyParamsToResourceTemplate(params, doc, oldEscape)
+			resources = append(resources, applyUIDToResourceTemplate(rt, uid))
+		}
+	}
+	return resources, nil
+}
+
+// randInt63n returns a random number in [0, n), which selects the resource templates of selections
+// without a key.
+var randInt63n = rand.Int63n
+
+// SelectResourceTemplate returns the index of the resource template chosen by the selection of the
+// TriggerTemplate, or -1 if it has no selection and the resources of all its templates are created. The
+// templates are chosen in proportion to their weights, by chance, or by the hash of the value of the key
+// param if the selection has one.
+func SelectResourceTemplate(template *triggersv1.TriggerTemplate, params []triggersv1.Param) (int, error) {
+	s := template.Spec.Selection
+	if s == nil {
+		return -1, nil
+	}
+	if len(s.Weights) != len(template.Spec.ResourceTemplates) {
+		return 0, fmt.Errorf("TriggerTemplate %s has %d selection weights for %d resource templates", template.Name, len(s.Weights), len(template.Spec.ResourceTemplates))
+	}
+	var total int64
+	for _, w := range s.Weights {
+		if w > 0 {
+			total += int64(w)
+		}
+	}
+	if total == 0 {
+		return 0, fmt.Errorf("TriggerTemplate %s has no positive selection weights", template.Name)
+	}
+
+	var n int64
+	if s.Key == "" {
+		n = randInt63n(total)
+	} else {
+		value, ok := paramValue(params, s.Key)
+		if !ok {
+			return 0, fmt.Errorf("selection key param %s of TriggerTemplate %s has no value", s.Key, template.Name)
+		}
+		h := fnv.New64a()
+		h.Write([]byte(value))
+		n = int64(h.Sum64() % uint64(total))
+	}
+	for i, w := range s.Weights {
+		if w <= 0 {
+			continue
+		}
+		if n < int64(w) {
+			return i, nil
+		}
+		n -= int64(w)
+	}
+	return 0, fmt.Errorf("TriggerTemplate %s has no selected resource template", template.Name)
+}
+
+func paramValue(params []triggersv1.Param, name string) (string, bool) {
+	for _, p := range params {
+		if p.Name == name {
+			return p.Value, true
+		}
 	}
-	return resources
+	return "", false
 }
 
 // event represents a HTTP event that Triggers processes
 type event struct {
-	Header     map[string]string      `json:"header"`
-	Body       interface{}            `json:"body"`
-	Extensions map[string]interface{} `json:"extensions"`
-	Context    TriggerContext         `json:"context"`
+	Header     map[string]joinedValues `json:"header"`
+	Query      map[string]joinedValues `json:"query"`
+	Cookie     map[string]string       `json:"cookie"`
+	Body       interface{}             `json:"body"`
+	Extensions map[string]interface{}  `json:"extensions"`
+	Context    TriggerContext          `json:"context"`
 }
 
-// newEvent returns a new Event from HTTP headers and body
-func newEvent(body []byte, headers http.Header, extensions map[string]interface{}, triggerContext TriggerContext) (*event, error) {
+// joinedValues holds the values of a header or a URL query parameter. They are
+// joined with commas unless a single value is selected by its index, or all of
+// them are bound to an array param.
+type joinedValues []string
+
+func (v joinedValues) String() string {
+	return strings.Join(v, ",")
+}
+
+// MarshalJSON marshals the joined values.
+func (v joinedValues) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.String())
+}
+
+// newEvent returns a new Event from HTTP headers, query parameters and body
+func newEvent(body []byte, headers http.Header, query url.Values, extensions map[string]interface{}, triggerContext TriggerContext) (*event, error) {
 	var data interface{}
 	if len(body) > 0 {
-		if err := json.Unmarshal(body, &data); err != nil {
+		if err := triggerContext.jsonNumbers.Unmarshal(body, &data); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal request body: %w", err)
 		}
 	}
-	joinedHeaders := make(map[string]string, len(headers))
+	headerValues := make(map[string]joinedValues, len(headers))
 	for k, v := range headers {
-		joinedHeaders[k] = strings.Join(v, ",")
+		headerValues[k] = v
+	}
+	queryParams := make(map[string]joinedValues, len(query))
+	for k, v := range query {
+		queryParams[k] = v
 	}
 
 	return &event{
-		Header:     joinedHeaders,
+		Header:     headerValues,
+		Query:      queryParams,
+		Cookie:     cookies(headers),
 		Body:       data,
 		Extensions: extensions,
 		Context:    triggerContext,
 	}, nil
 }
 
+// cookies returns the values of the cookies of the Cookie headers by name, parsed like net/http does.
+// If a cookie is sent more than once, the first value is kept, since browsers send the cookies with the
+// most specific paths first.
+func cookies(headers http.Header) map[string]string {
+	values := headers.Values("Cookie")
+	if len(values) == 0 {
+		return map[string]string{}
+	}
+	parsed := (&http.Request{Header: http.Header{"Cookie": values}}).Cookies()
+	m := make(map[string]string, len(parsed))
+	for _, c := range parsed {
+		if _, ok := m[c.Name]; !ok {
+			m[c.Name] = c.Value
+		}
+	}
+	return m
+}
+
+// withPromotedExtensions returns the event as a map in which the extensions
+// with the given keys are also top-level fields, next to the body and headers.
+// Validation keeps the keys from colliding with the fields of the event.
+func (e *event) withPromotedExtensions(keys []string) map[string]interface{} {
+	m := map[string]interface{}{
+		"header":     e.Header,
+		"query":      e.Query,
+		"cookie":     e.Cookie,
+		"body":       e.Body,
+		"extensions": e.Extensions,
+		"context":    e.Context,
+	}
+	for _, k := range keys {
+		if v, ok := e.Extensions[k]; ok {
+			if _, reserved := m[k]; !reserved {
+				m[k] = v
+			}
+		}
+	}
+	return m
+}
+
 // applyEventValuesToParams returns a slice of Params with the JSONPath variables replaced
-// with values from the event body, headers, and extensions.
-func applyEventValuesToParams(params []triggersv1.Param, body []byte, header http.Header, extensions map[string]interface{},
+// with values from the event body, headers, query parameters, and extensions, including
+// the promoted extensions.
+func applyEventValuesToParams(params []triggersv1.Param, body []byte, header http.Header, query url.Values, extensions map[string]interface{},
+	promotedExtensions []string,
 	defaults []triggersv1.ParamSpec,
 	triggerContext TriggerContext) ([]triggersv1.Param, error) {
-	event, err := newEvent(body, header, extensions, triggerContext)
+	e, err := newEvent(body, header, query, extensions, triggerContext)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal event: %w", err)
 	}
+	var event interface{} = e
+	if len(promotedExtensions) > 0 {
+		event = e.withPromotedExtensions(promotedExtensions)
+	}
 
 	allParamsMap := map[string]string{}
+	arrayParams := map[string]bool{}
 	for _, paramSpec := range defaults {
 		if paramSpec.Default != nil {
 			allParamsMap[paramSpec.Name] = *paramSpec.Default
 		}
+		if paramSpec.Type == triggersv1.ParamTypeArray {
+			arrayParams[paramSpec.Name] = true
+		}
 	}
 
 	for _, p := range params {
 		pValue := p.Value
+		if arrayParams[p.Name] {
+			// The value of an array param bound to a single expression collects all the matches.
+			if expressions, originals := findTektonExpressions(pValue); len(expressions) == 1 && originals[0] == pValue {
+				def, hasDefault := allParamsMap[p.Name]
+				val, err := arrayParamValue(event, expressions[0], def, hasDefault)
+				if err != nil {
+					return nil, fmt.Errorf("failed to replace JSONPath value for array param %s: %s: %w", p.Name, p.Value, err)
+				}
+				allParamsMap[p.Name] = val
+				continue
+			}
+		}
 		// Find all expressions wrapped in $() from the value
 		expressions, originals := findTektonExpressions(pValue)
 		for i, expr := range expressions {
-			val, err := parseJSONPath(event, expr)
-			if defaults != nil && err != nil {
-				// if the header or body was not supplied or was malformed, go with a default if it exists
+			path, transforms := splitTransforms(strings.TrimSuffix(strings.TrimPrefix(expr, "$("), ")"))
+			val, err := parseJSONPath(event, "$("+path+")")
+			if defaults != nil && errors.Is(err, errPathNotFound) {
+				// if the header, query parameter or field of the body was not supplied, go with a default if it exists.
+				// Malformed expressions fail even if there is a default.
 				v, ok := allParamsMap[p.Name]
 				if ok {
 					val = v
@@ -137,9 +330,37 @@ func applyEventValuesToParams(params []triggersv1.Param, body []byte, header htt
 			if err != nil {
 				return nil, fmt.Errorf("failed to replace JSONPath value for param %s: %s: %w", p.Name, p.Value, err)
 			}
+			// Transforms are applied to defaults too, and unknown transforms never fall back to them.
+			if val, err = applyTransforms(val, transforms); err != nil {
+				return nil, fmt.Errorf("failed to transform value for param %s: %s: %w", p.Name, p.Value, err)
+			}
 			pValue = strings.ReplaceAll(pValue, originals[i], val)
 		}
 		allParamsMap[p.Name] = pValue
 	}
 	return convertParamMapToArray(allParamsMap), nil
 }
+
+// arrayParamValue returns the JSON array of the nodes matched by the expression expr wrapped in $(), with
+// the transforms of the expression applied to its strings. If the expression matches no nodes, the array
+// is the default value of the param, if it has one, or an empty array.
+func arrayParamValue(event interface{}, expr string, def string, hasDefault bool) (string, error) {
+	path, transforms := splitTransforms(strings.TrimSuffix(strings.TrimPrefix(expr, "$("), ")"))
+	values, err := findArrayResults(event, "$("+path+")")
+	if err != nil {
+		return "", err
+	}
+	if len(values) == 0 && hasDefault {
+		if err := json.Unmarshal([]byte(def), &values); err != nil {
+			return "", fmt.Errorf("default value %s is not a JSON array: %w", def, err)
+		}
+	}
+	if err := applyTransformsToArray(values, transforms); err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
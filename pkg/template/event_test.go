@@ -19,7 +19,9 @@ package template
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"testing"
 
@@ -119,6 +121,22 @@ func TestApplyEventValuesMergeInDefaultParams(t *testing.T) {
 			},
 			want: []triggersv1.Param{oneParam},
 		},
+		{
+			name: "missing query param uses default",
+			args: args{
+				params:     []triggersv1.Param{{Name: "oneid", Value: "$(query.missing)"}},
+				paramSpecs: []triggersv1.ParamSpec{oneParamSpec},
+			},
+			want: []triggersv1.Param{wantDefaultOneParam},
+		},
+		{
+			name: "missing cookie uses default",
+			args: args{
+				params:     []triggersv1.Param{{Name: "oneid", Value: "$(cookie.missing)"}},
+				paramSpecs: []triggersv1.ParamSpec{oneParamSpec},
+			},
+			want: []triggersv1.Param{wantDefaultOneParam},
+		},
 		{
 			name: "add no default params",
 			args: args{
@@ -130,7 +148,7 @@ func TestApplyEventValuesMergeInDefaultParams(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := applyEventValuesToParams(tt.args.params, nil, nil, nil, tt.args.paramSpecs, context)
+			got, err := applyEventValuesToParams(tt.args.params, nil, nil, nil, nil, nil, tt.args.paramSpecs, context)
 			if err != nil {
 				t.Errorf("applyEventValuesToParams(): unexpected error: %s", err.Error())
 			}
@@ -153,6 +171,7 @@ func TestApplyEventValuesToParams(t *testing.T) {
 		// TODO: Change body to interface{} and call JSON.Marshall in t.Run to make the tests less brittle
 		body       []byte
 		header     http.Header
+		query      url.Values
 		want       []triggersv1.Param
 		extensions map[string]interface{}
 	}{{
@@ -190,6 +209,46 @@ func TestApplyEventValuesToParams(t *testing.T) {
 			"Header-One": {"val1", "val2"},
 		},
 		want: []triggersv1.Param{{Name: "foo", Value: `{"Header-One":"val1,val2"}`}},
+	}, {
+		name:   "query param",
+		params: []triggersv1.Param{{Name: "foo", Value: "$(query.env)"}},
+		query:  url.Values{"env": {"staging"}},
+		want:   []triggersv1.Param{{Name: "foo", Value: "staging"}},
+	}, {
+		name:   "query param - multiple values joined by comma",
+		params: []triggersv1.Param{{Name: "foo", Value: "$(query.env)"}},
+		query:  url.Values{"env": {"staging", "prod"}},
+		want:   []triggersv1.Param{{Name: "foo", Value: "staging,prod"}},
+	}, {
+		name:   "query param - indexed value",
+		params: []triggersv1.Param{{Name: "foo", Value: "$(query.env[1])"}},
+		query:  url.Values{"env": {"staging", "prod"}},
+		want:   []triggersv1.Param{{Name: "foo", Value: "prod"}},
+	}, {
+		name:   "query values",
+		params: []triggersv1.Param{{Name: "foo", Value: "$(query)"}},
+		query:  url.Values{"env": {"staging", "prod"}},
+		want:   []triggersv1.Param{{Name: "foo", Value: `{"env":"staging,prod"}`}},
+	}, {
+		name:   "cookie",
+		params: []triggersv1.Param{{Name: "foo", Value: "$(cookie.session)"}},
+		header: map[string][]string{"Cookie": {"theme=dark; session=abc123"}},
+		want:   []triggersv1.Param{{Name: "foo", Value: "abc123"}},
+	}, {
+		name:   "cookie - quoted value",
+		params: []triggersv1.Param{{Name: "foo", Value: "$(cookie.portal_user)"}},
+		header: map[string][]string{"Cookie": {`portal_user="jane"`}},
+		want:   []triggersv1.Param{{Name: "foo", Value: "jane"}},
+	}, {
+		name:   "cookie - first value of a repeated cookie across headers",
+		params: []triggersv1.Param{{Name: "foo", Value: "$(cookie.session)"}},
+		header: map[string][]string{"Cookie": {"session=first", "session=second; theme=dark"}},
+		want:   []triggersv1.Param{{Name: "foo", Value: "first"}},
+	}, {
+		name:   "cookies",
+		params: []triggersv1.Param{{Name: "foo", Value: "$(cookie)"}},
+		header: map[string][]string{"Cookie": {"theme=dark; session=abc123"}},
+		want:   []triggersv1.Param{{Name: "foo", Value: `{"session":"abc123","theme":"dark"}`}},
 	}, {
 		name:   "no body",
 		params: []triggersv1.Param{{Name: "foo", Value: "$(body)"}},
@@ -302,11 +361,33 @@ func TestApplyEventValuesToParams(t *testing.T) {
 		},
 		params: []triggersv1.Param{{Name: "a", Value: "$(extensions.foo)"}},
 		want:   []triggersv1.Param{{Name: "a", Value: `[{"a":"1"},{"b":"2"}]`}},
+	}, {
+		name:   "transforms",
+		params: []triggersv1.Param{{Name: "foo", Value: "$(body.branch | trim | lower)"}},
+		body:   json.RawMessage(`{"branch": " Feature/Login "}`),
+		want:   []triggersv1.Param{{Name: "foo", Value: "feature/login"}},
+	}, {
+		name:   "transforms of JSON escaped values",
+		params: []triggersv1.Param{{Name: "foo", Value: "$(body.title|upper)"}},
+		body:   json.RawMessage(`{"title": "say \"hi\""}`),
+		want:   []triggersv1.Param{{Name: "foo", Value: `SAY \"HI\"`}},
+	}, {
+		name:   "transforms of headers",
+		params: []triggersv1.Param{{Name: "foo", Value: "pr-$(header.x-repo | dnslabel)"}},
+		header: map[string][]string{
+			"X-Repo": {"Tekton_CD/Triggers"},
+		},
+		want: []triggersv1.Param{{Name: "foo", Value: "pr-tekton-cd-triggers"}},
+	}, {
+		name:   "transforms after filters",
+		params: []triggersv1.Param{{Name: "foo", Value: "$(body.labels[?(@.name == 'a|b')].value | labelvalue)"}},
+		body:   json.RawMessage(`{"labels": [{"name": "a|b", "value": "release 1.0!"}]}`),
+		want:   []triggersv1.Param{{Name: "foo", Value: "release_1.0"}},
 	}}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := applyEventValuesToParams(tt.params, tt.body, tt.header, tt.extensions, nil, context)
+			got, err := applyEventValuesToParams(tt.params, tt.body, tt.header, tt.query, tt.extensions, nil, nil, context)
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
 			}
@@ -326,6 +407,7 @@ func TestApplyEventValuesToParams_Error(t *testing.T) {
 		params     []triggersv1.Param
 		body       []byte
 		header     http.Header
+		query      url.Values
 		extensions map[string]interface{}
 	}{{
 		name:   "missing key",
@@ -346,11 +428,27 @@ func TestApplyEventValuesToParams_Error(t *testing.T) {
 		extensions: map[string]interface{}{
 			"foo": "bar",
 		},
+	}, {
+		name:   "missing query param",
+		params: []triggersv1.Param{{Name: "foo", Value: "$(query.missing)"}},
+		query:  url.Values{"env": {"staging"}},
+	}, {
+		name:   "query param index out of range",
+		params: []triggersv1.Param{{Name: "foo", Value: "$(query.env[1])"}},
+		query:  url.Values{"env": {"staging"}},
+	}, {
+		name:   "missing cookie",
+		params: []triggersv1.Param{{Name: "foo", Value: "$(cookie.missing)"}},
+		header: map[string][]string{"Cookie": {"session=abc123"}},
+	}, {
+		name:   "unknown transform",
+		params: []triggersv1.Param{{Name: "foo", Value: "$(body.a | base64)"}},
+		body:   json.RawMessage(`{"a": "b"}`),
 	}}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := applyEventValuesToParams(tt.params, tt.body, tt.header, tt.extensions, nil, context)
+			got, err := applyEventValuesToParams(tt.params, tt.body, tt.header, tt.query, tt.extensions, nil, nil, context)
 			if err == nil {
 				t.Errorf("did not get expected error - got: %v", got)
 			}
@@ -366,6 +464,7 @@ func TestResolveParams(t *testing.T) {
 		bindingParams []triggersv1.Param
 		body          []byte
 		extensions    map[string]interface{}
+		el            *triggersv1.EventListener
 		template      *triggersv1.TriggerTemplate
 		want          []triggersv1.Param
 	}{{
@@ -408,6 +507,60 @@ func TestResolveParams(t *testing.T) {
 			{Name: "p2", Value: "defaultVal"},
 			{Name: "p1", Value: "val1"},
 		},
+	}, {
+		name: "add default values if no array element matches the filter",
+		body: json.RawMessage(`{"labels": [{"name": "ci", "value": "true"}]}`),
+		bindingParams: []triggersv1.Param{
+			{Name: "p1", Value: "$(body.labels[?(@.name == 'ci')].value)"},
+			{Name: "p2", Value: "$(body.labels[?(@.name == 'deploy')].value)"},
+		},
+		template: &triggersv1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt-name",
+				Namespace: ns,
+			},
+			Spec: triggersv1.TriggerTemplateSpec{
+				Params: []triggersv1.ParamSpec{{
+					Name:    "p2",
+					Default: ptr.String("defaultVal"),
+				}},
+			},
+		},
+		want: []triggersv1.Param{
+			{Name: "p2", Value: "defaultVal"},
+			{Name: "p1", Value: "true"},
+		},
+	}, {
+		name: "add default values if an intermediate object is absent or null",
+		body: json.RawMessage(`{"pull_request": null, "repository": {}}`),
+		bindingParams: []triggersv1.Param{
+			{Name: "p1", Value: "$(body.pull_request.head.sha)"},
+			{Name: "p2", Value: "$(body.repository.owner.login)"},
+			{Name: "p3", Value: "$(body.pull_request.labels[0].name)"},
+		},
+		template: &triggersv1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt-name",
+				Namespace: ns,
+			},
+			Spec: triggersv1.TriggerTemplateSpec{
+				Params: []triggersv1.ParamSpec{{
+					Name:    "p1",
+					Default: ptr.String("main"),
+				}, {
+					Name:    "p2",
+					Default: ptr.String("tektoncd"),
+				}, {
+					Name:    "p3",
+					Default: ptr.String("none"),
+				}},
+			},
+		},
+		want: []triggersv1.Param{
+			{Name: "p1", Value: "main"},
+			{Name: "p2", Value: "tektoncd"},
+			{Name: "p3", Value: "none"},
+		},
 	}, {
 		name:          "default values do not override event values",
 		bindingParams: []triggersv1.Param{{Name: "p1", Value: "val1"}},
@@ -468,6 +621,27 @@ func TestResolveParams(t *testing.T) {
 			{Name: "param2", Value: "bar\\r\\nbaz"},
 			{Name: "event1", Value: "1234567"},
 		},
+	}, {
+		name: "eventlistener context",
+		template: &triggersv1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt-name",
+				Namespace: ns,
+			},
+		},
+		el: &triggersv1.EventListener{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-el", Namespace: ns, UID: "el-uid"},
+		},
+		bindingParams: []triggersv1.Param{
+			{Name: "el", Value: "$(context.eventListener.namespace)/$(context.eventListener.name)"},
+			{Name: "uid", Value: "$(context.eventListener.uid)"},
+			{Name: "event", Value: "$(context.eventID)"},
+		},
+		want: []triggersv1.Param{
+			{Name: "el", Value: ns + "/my-el"},
+			{Name: "uid", Value: "el-uid"},
+			{Name: "event", Value: "1234567"},
+		},
 	}}
 
 	for _, tt := range tests {
@@ -477,7 +651,7 @@ func TestResolveParams(t *testing.T) {
 				TriggerTemplate: tt.template,
 			}
 
-			params, err := ResolveParams(rt, tt.body, map[string][]string{}, tt.extensions, NewTriggerContext(eventID))
+			params, err := ResolveParams(rt, tt.body, map[string][]string{}, nil, tt.extensions, NewTriggerContext(eventID).WithEventListener(tt.el))
 			if err != nil {
 				t.Fatalf("ResolveParams() returned unexpected error: %s", err)
 			}
@@ -488,6 +662,254 @@ func TestResolveParams(t *testing.T) {
 	}
 }
 
+func TestResolveParams_ArrayParams(t *testing.T) {
+	template := &triggersv1.TriggerTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "tt-name"},
+		Spec: triggersv1.TriggerTemplateSpec{
+			Params: []triggersv1.ParamSpec{{
+				Name: "shas",
+				Type: triggersv1.ParamTypeArray,
+			}, {
+				Name:    "paths",
+				Type:    triggersv1.ParamTypeArray,
+				Default: ptr.String(`["."]`),
+			}, {
+				Name: "sha",
+			}},
+		},
+	}
+	body := json.RawMessage(`{"commits": [{"id": "abc", "modified": ["a.go"]}, {"id": "DEF", "modified": ["b.go", "c.go"]}]}`)
+	single := json.RawMessage(`{"commits": [{"id": "abc", "modified": ["a.go"]}]}`)
+
+	tests := []struct {
+		name          string
+		body          []byte
+		header        http.Header
+		bindingParams []triggersv1.Param
+		want          []triggersv1.Param
+	}{{
+		name:          "multiple matches",
+		body:          body,
+		bindingParams: []triggersv1.Param{{Name: "shas", Value: "$(body.commits[*].id)"}},
+		want:          []triggersv1.Param{{Name: "shas", Value: `["abc","DEF"]`}, {Name: "paths", Value: `["."]`}},
+	}, {
+		name:          "single match",
+		body:          single,
+		bindingParams: []triggersv1.Param{{Name: "shas", Value: "$(body.commits[*].id)"}, {Name: "sha", Value: "$(body.commits[*].id)"}},
+		want:          []triggersv1.Param{{Name: "shas", Value: `["abc"]`}, {Name: "sha", Value: "abc"}, {Name: "paths", Value: `["."]`}},
+	}, {
+		name:          "matched array",
+		body:          single,
+		bindingParams: []triggersv1.Param{{Name: "paths", Value: "$(body.commits[0].modified)"}},
+		want:          []triggersv1.Param{{Name: "paths", Value: `["a.go"]`}},
+	}, {
+		name:          "nested matches",
+		body:          body,
+		bindingParams: []triggersv1.Param{{Name: "paths", Value: "$(body.commits[*].modified[*])"}},
+		want:          []triggersv1.Param{{Name: "paths", Value: `["a.go","b.go","c.go"]`}},
+	}, {
+		name:          "multi-level nested matches",
+		body:          json.RawMessage(`{"pushes": [{"commits": [{"modified": ["a.go"]}, {"modified": ["b.go"]}]}, {"commits": [{"modified": ["c.go", "d.go"]}]}]}`),
+		bindingParams: []triggersv1.Param{{Name: "paths", Value: "$(body.pushes[*].commits[*].modified[*])"}},
+		want:          []triggersv1.Param{{Name: "paths", Value: `["a.go","b.go","c.go","d.go"]`}},
+	}, {
+		name:          "nested matches skipping missing, null and other values",
+		body:          json.RawMessage(`{"commits": [{"modified": ["a.go"]}, {}, {"modified": null}, null, {"modified": "b.go"}, {"modified": ["c.go"]}]}`),
+		bindingParams: []triggersv1.Param{{Name: "paths", Value: "$(body.commits[*].modified[*])"}},
+		want:          []triggersv1.Param{{Name: "paths", Value: `["a.go","c.go"]`}},
+	}, {
+		name:          "nested matches after a filter",
+		body:          json.RawMessage(`{"commits": [{"id": "abc", "modified": ["a.go"]}, {"id": "abc"}, {"id": "DEF", "modified": ["b.go"]}]}`),
+		bindingParams: []triggersv1.Param{{Name: "paths", Value: "$(body.commits[?(@.id == 'abc')].modified[*])"}},
+		want:          []triggersv1.Param{{Name: "paths", Value: `["a.go"]`}},
+	}, {
+		name:          "no nested matches without a default",
+		body:          json.RawMessage(`{"commits": [{"id": "abc"}, {"id": "DEF", "modified": null}]}`),
+		bindingParams: []triggersv1.Param{{Name: "shas", Value: "$(body.commits[*].modified[*])"}},
+		want:          []triggersv1.Param{{Name: "shas", Value: `[]`}, {Name: "paths", Value: `["."]`}},
+	}, {
+		name:          "no nested matches with a default",
+		body:          json.RawMessage(`{"commits": [{"id": "abc"}, {"id": "DEF", "modified": null}]}`),
+		bindingParams: []triggersv1.Param{{Name: "paths", Value: "$(body.commits[*].modified[*])"}},
+		want:          []triggersv1.Param{{Name: "paths", Value: `["."]`}},
+	}, {
+		name:          "filter matches",
+		body:          body,
+		bindingParams: []triggersv1.Param{{Name: "shas", Value: "$(body.commits[?(@.id == 'DEF')].id)"}},
+		want:          []triggersv1.Param{{Name: "shas", Value: `["DEF"]`}, {Name: "paths", Value: `["."]`}},
+	}, {
+		name:          "transformed matches",
+		body:          body,
+		bindingParams: []triggersv1.Param{{Name: "shas", Value: "$(body.commits[*].id | lower)"}},
+		want:          []triggersv1.Param{{Name: "shas", Value: `["abc","def"]`}, {Name: "paths", Value: `["."]`}},
+	}, {
+		name:          "no matches without a default",
+		body:          json.RawMessage(`{"commits": []}`),
+		bindingParams: []triggersv1.Param{{Name: "shas", Value: "$(body.commits[*].id)"}},
+		want:          []triggersv1.Param{{Name: "shas", Value: `[]`}, {Name: "paths", Value: `["."]`}},
+	}, {
+		name:          "missing key without a default",
+		body:          json.RawMessage(`{}`),
+		bindingParams: []triggersv1.Param{{Name: "shas", Value: "$(body.commits[*].id)"}},
+		want:          []triggersv1.Param{{Name: "shas", Value: `[]`}, {Name: "paths", Value: `["."]`}},
+	}, {
+		name:          "no matches with a default",
+		body:          json.RawMessage(`{"commits": []}`),
+		bindingParams: []triggersv1.Param{{Name: "paths", Value: "$(body.commits[*].modified[*])"}},
+		want:          []triggersv1.Param{{Name: "paths", Value: `["."]`}},
+	}, {
+		name:          "value that is not a single expression",
+		body:          single,
+		bindingParams: []triggersv1.Param{{Name: "shas", Value: "sha-$(body.commits[0].id)"}},
+		want:          []triggersv1.Param{{Name: "shas", Value: "sha-abc"}, {Name: "paths", Value: `["."]`}},
+	}, {
+		name:          "header values",
+		header:        http.Header{"X-Forwarded-For": {"203.0.113.1", "198.51.100.1"}},
+		bindingParams: []triggersv1.Param{{Name: "paths", Value: "$(header.x-forwarded-for)"}, {Name: "sha", Value: "$(header.x-forwarded-for)"}},
+		want:          []triggersv1.Param{{Name: "paths", Value: `["203.0.113.1","198.51.100.1"]`}, {Name: "sha", Value: "203.0.113.1,198.51.100.1"}},
+	}, {
+		name:          "single header value",
+		header:        http.Header{"X-Tenant": {"Team-A"}},
+		bindingParams: []triggersv1.Param{{Name: "paths", Value: "$(header.X-Tenant | lower)"}, {Name: "sha", Value: "$(header.X-Tenant)"}},
+		want:          []triggersv1.Param{{Name: "paths", Value: `["team-a"]`}, {Name: "sha", Value: "Team-A"}},
+	}, {
+		name:          "indexed header value",
+		header:        http.Header{"X-Forwarded-For": {"203.0.113.1", "198.51.100.1"}},
+		bindingParams: []triggersv1.Param{{Name: "sha", Value: "$(header.X-Forwarded-For[1])"}},
+		want:          []triggersv1.Param{{Name: "sha", Value: "198.51.100.1"}, {Name: "paths", Value: `["."]`}},
+	}, {
+		name:          "missing header with a default",
+		header:        http.Header{},
+		bindingParams: []triggersv1.Param{{Name: "paths", Value: "$(header.X-Tenant)"}},
+		want:          []triggersv1.Param{{Name: "paths", Value: `["."]`}},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := ResolvedTrigger{
+				BindingParams:   tt.bindingParams,
+				TriggerTemplate: template,
+			}
+			header := tt.header
+			if header == nil {
+				header = map[string][]string{}
+			}
+			params, err := ResolveParams(rt, tt.body, header, nil, nil, NewTriggerContext("1234567"))
+			if err != nil {
+				t.Fatalf("ResolveParams() returned unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(tt.want, params, cmpopts.SortSlices(test.CompareParams)); diff != "" {
+				t.Errorf("didn't get expected params -want + got: %s", diff)
+			}
+		})
+	}
+
+	for _, value := range []string{"$(body.commits[)", "$(body.commits[*].id | reverse)"} {
+		rt := ResolvedTrigger{
+			BindingParams:   []triggersv1.Param{{Name: "shas", Value: value}},
+			TriggerTemplate: template,
+		}
+		if params, err := ResolveParams(rt, body, map[string][]string{}, nil, nil, NewTriggerContext("1234567")); err == nil {
+			t.Errorf("ResolveParams() for %s did not return an error, got: %v", value, params)
+		}
+	}
+}
+
+func TestResolveParams_PromotedExtensions(t *testing.T) {
+	rt := ResolvedTrigger{
+		BindingParams: []triggersv1.Param{
+			{Name: "number", Value: "$(pr.number)"},
+			{Name: "sha", Value: "$(sha)"},
+			{Name: "same-sha", Value: "$(extensions.sha)"},
+			{Name: "foo", Value: "$(body.foo)"},
+			{Name: "second", Value: "$(query.q[1])"},
+			{Name: "missing", Value: "$(missing)"},
+		},
+		TriggerTemplate: &triggersv1.TriggerTemplate{
+			Spec: triggersv1.TriggerTemplateSpec{
+				Params: []triggersv1.ParamSpec{{
+					Name:    "missing",
+					Default: ptr.String("defaultVal"),
+				}},
+			},
+		},
+		PromotedExtensions: []string{"pr", "sha", "missing"},
+	}
+	extensions := map[string]interface{}{
+		"pr":  map[string]interface{}{"number": 42},
+		"sha": "abcdef",
+	}
+	query := url.Values{"q": []string{"first", "second"}}
+
+	params, err := ResolveParams(rt, json.RawMessage(`{"foo": "bar"}`), map[string][]string{}, query, extensions, NewTriggerContext("1234567"))
+	if err != nil {
+		t.Fatalf("ResolveParams() returned unexpected error: %s", err)
+	}
+	want := []triggersv1.Param{
+		{Name: "number", Value: "42"},
+		{Name: "sha", Value: "abcdef"},
+		{Name: "same-sha", Value: "abcdef"},
+		{Name: "foo", Value: "bar"},
+		{Name: "second", Value: "second"},
+		{Name: "missing", Value: "defaultVal"},
+	}
+	if diff := cmp.Diff(want, params, cmpopts.SortSlices(test.CompareParams)); diff != "" {
+		t.Errorf("didn't get expected params -want + got: %s", diff)
+	}
+
+	// Extensions that aren't promoted can't be read as top-level fields.
+	rt = ResolvedTrigger{BindingParams: []triggersv1.Param{{Name: "sha", Value: "$(sha)"}}}
+	if params, err := ResolveParams(rt, nil, map[string][]string{}, nil, extensions, NewTriggerContext("1234567")); err == nil {
+		t.Errorf("did not get expected error - got: %v", params)
+	}
+}
+
+func TestResolveParams_JSONNumbers(t *testing.T) {
+	rt := ResolvedTrigger{
+		BindingParams: []triggersv1.Param{
+			{Name: "id", Value: "$(body.id)"},
+			{Name: "ratio", Value: "$(body.ratio)"},
+			{Name: "ids", Value: "$(body.items[*].id)"},
+			{Name: "large", Value: "$(body.items[?(@.id > 9007199254740993)].name)"},
+			{Name: "exact", Value: "$(body.items[?(@.id == 9007199254740993)].name)"},
+		},
+	}
+	body := json.RawMessage(`{"id": 1234567890123456789, "ratio": 1.50, "items": [{"id": 9007199254740993, "name": "a"}, {"id": 9007199254740995, "name": "b"}]}`)
+	for _, tc := range []struct {
+		numbers triggersv1.JSONNumbers
+		want    []triggersv1.Param
+	}{{
+		numbers: triggersv1.JSONNumbersFloat,
+		want: []triggersv1.Param{
+			{Name: "id", Value: "1234567890123456800"},
+			{Name: "ratio", Value: "1.5"},
+			{Name: "ids", Value: "[9007199254740992,9007199254740996]"},
+			{Name: "large", Value: "b"},
+			{Name: "exact", Value: "a"},
+		},
+	}, {
+		numbers: triggersv1.JSONNumbersExact,
+		want: []triggersv1.Param{
+			{Name: "id", Value: "1234567890123456789"},
+			{Name: "ratio", Value: "1.50"},
+			{Name: "ids", Value: "[9007199254740993,9007199254740995]"},
+			{Name: "large", Value: "b"},
+			{Name: "exact", Value: "a"},
+		},
+	}} {
+		t.Run(string(tc.numbers), func(t *testing.T) {
+			el := &triggersv1.EventListener{Spec: triggersv1.EventListenerSpec{JSONNumbers: tc.numbers}}
+			params, err := ResolveParams(rt, body, map[string][]string{}, nil, nil, NewTriggerContext("1234567").WithEventListener(el))
+			if err != nil {
+				t.Fatalf("ResolveParams() returned unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(tc.want, params, cmpopts.SortSlices(test.CompareParams)); diff != "" {
+				t.Errorf("didn't get expected params -want + got: %s", diff)
+			}
+		})
+	}
+}
+
 func TestResolveParams_Error(t *testing.T) {
 	eventID := "1234567"
 
@@ -496,6 +918,7 @@ func TestResolveParams_Error(t *testing.T) {
 		body          []byte
 		extensions    map[string]interface{}
 		bindingParams []triggersv1.Param
+		template      *triggersv1.TriggerTemplate
 	}{{
 		name: "invalid body",
 		bindingParams: []triggersv1.Param{
@@ -507,11 +930,21 @@ func TestResolveParams_Error(t *testing.T) {
 		bindingParams: []triggersv1.Param{
 			{Name: "p1", Value: "$(header.[)"},
 		},
+	}, {
+		name: "invalid expression of a param with a default",
+		bindingParams: []triggersv1.Param{
+			{Name: "p1", Value: "$(body.a.[)"},
+		},
+		template: &triggersv1.TriggerTemplate{
+			Spec: triggersv1.TriggerTemplateSpec{
+				Params: []triggersv1.ParamSpec{{Name: "p1", Default: ptr.String("defaultVal")}},
+			},
+		},
 	}}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			params, err := ResolveParams(ResolvedTrigger{BindingParams: tt.bindingParams}, tt.body, map[string][]string{}, tt.extensions, NewTriggerContext(eventID))
+			params, err := ResolveParams(ResolvedTrigger{BindingParams: tt.bindingParams, TriggerTemplate: tt.template}, tt.body, map[string][]string{}, nil, tt.extensions, NewTriggerContext(eventID))
 			if err == nil {
 				t.Errorf("did not get expected error - got: %v", params)
 			}
@@ -526,6 +959,23 @@ func addOldEscape(t *triggersv1.TriggerTemplate) *triggersv1.TriggerTemplate {
 	return t
 }
 
+func TestResolveResources_Error(t *testing.T) {
+	tt := &triggersv1.TriggerTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "tt", Namespace: ns},
+		Spec: triggersv1.TriggerTemplateSpec{
+			ResourceTemplates: []triggersv1.TriggerResourceTemplate{{
+				RawExtension: runtime.RawExtension{Raw: []byte(`{"rt1": "value"}`)},
+			}, {
+				RawExtension: runtime.RawExtension{Raw: []byte(`"kind: ConfigMap\n---\n- kind: Secret\n"`)},
+			}},
+		},
+	}
+	_, err := ResolveResources(tt, nil)
+	if want := "resource template 1 of TriggerTemplate tt: document 1 is not an object"; err == nil || err.Error() != want {
+		t.Errorf("ResolveResources() returned error %v, want %q", err, want)
+	}
+}
+
 func TestResolveResources(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -642,6 +1092,32 @@ func TestResolveResources(t *testing.T) {
 			json.RawMessage(`{"rt1": "31313131-3131-4131-b131-313131313131"}`),
 			json.RawMessage(`{"rt2": "31313131-3131-4131-b131-313131313131"}`),
 		},
+	}, {
+		name: "multi-document templates are split before replacing params",
+		template: &triggersv1.TriggerTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tt",
+				Namespace: ns,
+			},
+			Spec: triggersv1.TriggerTemplateSpec{
+				Params: []triggersv1.ParamSpec{{
+					Name: "p1",
+				}},
+				ResourceTemplates: []triggersv1.TriggerResourceTemplate{{
+					RawExtension: runtime.RawExtension{Raw: []byte(`"kind: ConfigMap\nmetadata:\n  name: $(tt.params.p1)\n---\nkind: PipelineRun\nmetadata:\n  name: run-$(uid)\n"`)},
+				}, {
+					RawExtension: runtime.RawExtension{Raw: []byte(`{"rt3": "$(tt.params.p1)"}`)},
+				}},
+			},
+		},
+		params: []triggersv1.Param{
+			{Name: "p1", Value: `cm\nkind: Secret`},
+		},
+		want: []json.RawMessage{
+			json.RawMessage(`{"kind":"ConfigMap","metadata":{"name":"cm\nkind: Secret"}}`),
+			json.RawMessage(`{"kind":"PipelineRun","metadata":{"name":"run-31313131-3131-4131-b131-313131313131"}}`),
+			json.RawMessage(`{"rt3": "cm\nkind: Secret"}`),
+		},
 	}}
 
 	for _, tt := range tests {
@@ -650,7 +1126,10 @@ func TestResolveResources(t *testing.T) {
 			reader := bytes.NewReader([]byte("1111111111111111"))
 			uuid.SetRand(reader)
 			uuid.SetClockSequence(1)
-			got := ResolveResources(addOldEscape(tt.template), tt.params)
+			got, err := ResolveResources(addOldEscape(tt.template), tt.params)
+			if err != nil {
+				t.Fatalf("ResolveResources() returned error: %v", err)
+			}
 			// Use toString so that it is easy to compare the json.RawMessage diffs
 			if diff := cmp.Diff(toString(tt.want), toString(got)); diff != "" {
 				t.Errorf("didn't get expected resource template -want + got: %s", diff)
@@ -658,3 +1137,160 @@ func TestResolveResources(t *testing.T) {
 		})
 	}
 }
+
+func selectionTemplate(selection *triggersv1.ResourceTemplateSelection) *triggersv1.TriggerTemplate {
+	return &triggersv1.TriggerTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "tt", Namespace: ns},
+		Spec: triggersv1.TriggerTemplateSpec{
+			Params: []triggersv1.ParamSpec{{Name: "pr"}},
+			ResourceTemplates: []triggersv1.TriggerResourceTemplate{{
+				RawExtension: runtime.RawExtension{Raw: []byte(`{"rt1": "$(tt.params.pr)"}`)},
+			}, {
+				RawExtension: runtime.RawExtension{Raw: []byte(`{"rt2": "$(tt.params.pr)"}`)},
+			}, {
+				RawExtension: runtime.RawExtension{Raw: []byte(`"rt3: a\n---\nrt3: b\n"`)},
+			}},
+			Selection: selection,
+		},
+	}
+}
+
+func TestSelectResourceTemplate(t *testing.T) {
+	defer func(r func(int64) int64) { randInt63n = r }(randInt63n)
+	for _, tc := range []struct {
+		name      string
+		selection *triggersv1.ResourceTemplateSelection
+		random    int64
+		want      int
+	}{{
+		name: "no selection",
+		want: -1,
+	}, {
+		name:      "first template",
+		selection: &triggersv1.ResourceTemplateSelection{Weights: []int32{1, 0, 3}},
+		random:    0,
+		want:      0,
+	}, {
+		name:      "templates with a weight of 0 are skipped",
+		selection: &triggersv1.ResourceTemplateSelection{Weights: []int32{1, 0, 3}},
+		random:    1,
+		want:      2,
+	}, {
+		name:      "last template",
+		selection: &triggersv1.ResourceTemplateSelection{Weights: []int32{1, 0, 3}},
+		random:    3,
+		want:      2,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			randInt63n = func(n int64) int64 {
+				if n != 4 {
+					t.Errorf("randInt63n() called with %d, want the total weight 4", n)
+				}
+				return tc.random
+			}
+			got, err := SelectResourceTemplate(selectionTemplate(tc.selection), nil)
+			if err != nil {
+				t.Fatalf("SelectResourceTemplate() returned error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("SelectResourceTemplate() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelectResourceTemplate_Key(t *testing.T) {
+	defer func(r func(int64) int64) { randInt63n = r }(randInt63n)
+	randInt63n = func(int64) int64 {
+		t.Fatal("randInt63n() called for a selection with a key")
+		return 0
+	}
+	tt := selectionTemplate(&triggersv1.ResourceTemplateSelection{Weights: []int32{1, 1, 1}, Key: "pr"})
+	counts := make([]int, 3)
+	for i := 0; i < 300; i++ {
+		params := []triggersv1.Param{{Name: "pr", Value: fmt.Sprintf("https://github.com/tektoncd/triggers/pull/%d", i)}}
+		got, err := SelectResourceTemplate(tt, params)
+		if err != nil {
+			t.Fatalf("SelectResourceTemplate() returned error: %v", err)
+		}
+		again, _ := SelectResourceTemplate(tt, params)
+		if again != got {
+			t.Fatalf("SelectResourceTemplate() = %d then %d for the same key", got, again)
+		}
+		counts[got]++
+	}
+	for i, c := range counts {
+		if c < 50 {
+			t.Errorf("SelectResourceTemplate() selected template %d for %d of 300 keys, want about 100", i, c)
+		}
+	}
+
+	tt.Spec.Selection.Weights = []int32{0, 1, 0}
+	if got, _ := SelectResourceTemplate(tt, []triggersv1.Param{{Name: "pr", Value: "42"}}); got != 1 {
+		t.Errorf("SelectResourceTemplate() = %d, want the only template with a positive weight", got)
+	}
+}
+
+func TestSelectResourceTemplate_Error(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		selection *triggersv1.ResourceTemplateSelection
+		want      string
+	}{{
+		name:      "weights mismatch",
+		selection: &triggersv1.ResourceTemplateSelection{Weights: []int32{1, 1}},
+		want:      "TriggerTemplate tt has 2 selection weights for 3 resource templates",
+	}, {
+		name:      "no positive weights",
+		selection: &triggersv1.ResourceTemplateSelection{Weights: []int32{0, -1, 0}},
+		want:      "TriggerTemplate tt has no positive selection weights",
+	}, {
+		name:      "key without value",
+		selection: &triggersv1.ResourceTemplateSelection{Weights: []int32{1, 1, 1}, Key: "pr"},
+		want:      "selection key param pr of TriggerTemplate tt has no value",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := SelectResourceTemplate(selectionTemplate(tc.selection), nil)
+			if err == nil || err.Error() != tc.want {
+				t.Errorf("SelectResourceTemplate() returned error %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveSelectedResources(t *testing.T) {
+	params := []triggersv1.Param{{Name: "pr", Value: "42"}}
+	for _, tc := range []struct {
+		name     string
+		selected int
+		want     []json.RawMessage
+	}{{
+		name:     "all templates",
+		selected: -1,
+		want: []json.RawMessage{
+			json.RawMessage(`{"rt1": "42"}`),
+			json.RawMessage(`{"rt2": "42"}`),
+			json.RawMessage(`{"rt3":"a"}`),
+			json.RawMessage(`{"rt3":"b"}`),
+		},
+	}, {
+		name:     "selected template",
+		selected: 1,
+		want:     []json.RawMessage{json.RawMessage(`{"rt2": "42"}`)},
+	}, {
+		name:     "all the documents of the selected template",
+		selected: 2,
+		want:     []json.RawMessage{json.RawMessage(`{"rt3":"a"}`), json.RawMessage(`{"rt3":"b"}`)},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			uuid.SetRand(bytes.NewReader([]byte("1111111111111111")))
+			got, err := ResolveSelectedResources(selectionTemplate(nil), params, tc.selected)
+			if err != nil {
+				t.Fatalf("ResolveSelectedResources() returned error: %v", err)
+			}
+			if diff := cmp.Diff(toString(tc.want), toString(got)); diff != "" {
+				t.Errorf("didn't get expected resource template -want + got: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// funcMatch captures template function calls on a param within the resource template e.g.
+// $(upper(tt.params.branch)) or $(trimprefix(tt.params.ref, 'refs/heads/')).
+// The groups are the function name, the param name and the optional single quoted argument.
+var funcMatch = regexp.MustCompile(`\$\(([a-z]+)\(tt\.params\.([_a-zA-Z][_a-zA-Z0-9.-]*)(?:\s*,\s*'([^']*)')?\)\)`)
+
+// templateFunc is a function that can be applied to a param value in a resource template.
+type templateFunc struct {
+	// hasArg is true if the function takes a single quoted argument after the param.
+	hasArg bool
+	apply  func(value, arg string) string
+}
+
+// templateFuncs is the set of functions available in resource templates. It is deliberately
+// limited to simple string transformations so that templates remain predictable.
+var templateFuncs = map[string]templateFunc{
+	"upper": {apply: func(v, _ string) string { return strings.ToUpper(v) }},
+	"lower": {apply: func(v, _ string) string { return strings.ToLower(v) }},
+	"trim":  {apply: func(v, _ string) string { return strings.TrimSpace(v) }},
+	"trimprefix": {hasArg: true, apply: func(v, prefix string) string {
+		return strings.TrimPrefix(v, prefix)
+	}},
+	"trimsuffix": {hasArg: true, apply: func(v, suffix string) string {
+		return strings.TrimSuffix(v, suffix)
+	}},
+	"default": {hasArg: true, apply: func(v, def string) string {
+		if v == "" {
+			return def
+		}
+		return v
+	}},
+}
+
+// applyFuncsToParamValue replaces all template function calls on the given param with the
+// result of applying the function to the param value. Calls to unknown functions, or calls
+// with the wrong number of arguments are left untouched.
+func applyFuncsToParamValue(rt json.RawMessage, name, value string, oldEscape bool) json.RawMessage {
+	return funcMatch.ReplaceAllFunc(rt, func(match []byte) []byte {
+		groups := funcMatch.FindSubmatch(match)
+		if string(groups[2]) != name {
+			return match
+		}
+		f, ok := templateFuncs[string(groups[1])]
+		if !ok {
+			return match
+		}
+		hasArg := strings.Contains(string(match), ",")
+		if hasArg != f.hasArg {
+			return match
+		}
+		out := applyToJSONStringFragment(value, func(v string) string {
+			return f.apply(v, string(groups[3]))
+		})
+		if oldEscape {
+			out = strings.ReplaceAll(out, `"`, `\"`)
+		}
+		return []byte(out)
+	})
+}
+
+// applyToJSONStringFragment applies fn to a param value. Param values extracted from
+// string fields are JSON escaped without the surrounding quotes, so the value is unescaped
+// before fn is applied and escaped again afterwards. Values that are not JSON string
+// fragments (e.g. static binding values) are passed to fn as is.
+func applyToJSONStringFragment(value string, fn func(string) string) string {
+	var unescaped string
+	if err := json.Unmarshal([]byte(`"`+value+`"`), &unescaped); err != nil {
+		return fn(value)
+	}
+	b, err := json.Marshal(fn(unescaped))
+	if err != nil {
+		return fn(value)
+	}
+	return string(b[1 : len(b)-1])
+}
+
+// bindingTransforms is the set of transforms that can follow the JSONPath expression of a binding
+// value, e.g. $(body.branch | lower | trim). Like templateFuncs, it is deliberately limited to
+// simple transformations that make values safe to use in resources.
+var bindingTransforms = map[string]func(string) string{
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"trim":       strings.TrimSpace,
+	"dnslabel":   dnsLabel,
+	"labelvalue": labelValue,
+}
+
+// maxLabelLength is the maximum length of a DNS label and of a label value.
+const maxLabelLength = 63
+
+// dnsLabel turns v into an RFC 1123 label, e.g. for the name of a resource: it is lowercased, the
+// characters other than letters, digits and dashes are replaced with dashes, and the result is
+// truncated to 63 characters without leading or trailing dashes.
+func dnsLabel(v string) string {
+	label := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(v))
+	return truncateLabel(label, func(r rune) bool { return r == '-' })
+}
+
+// labelValue turns v into a valid Kubernetes label value: the characters other than letters, digits,
+// dashes, underscores and dots are replaced with underscores, and the result is truncated to 63
+// characters without leading or trailing non-alphanumeric characters.
+func labelValue(v string) string {
+	value := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' || r == '_' {
+			return r
+		}
+		return '_'
+	}, v)
+	return truncateLabel(value, func(r rune) bool { return r == '-' || r == '.' || r == '_' })
+}
+
+func truncateLabel(s string, trimmed func(rune) bool) string {
+	s = strings.TrimFunc(s, trimmed)
+	if len(s) > maxLabelLength {
+		s = strings.TrimRightFunc(s[:maxLabelLength], trimmed)
+	}
+	return s
+}
+
+// splitTransforms splits the unwrapped binding expression expr at the pipes that are not quoted or
+// within brackets, and returns the JSONPath expression and the names of the transforms that follow it.
+func splitTransforms(expr string) (string, []string) {
+	var parts []string
+	var quote rune
+	depth, start := 0, 0
+	for i, ch := range expr {
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '[' || ch == '(':
+			depth++
+		case ch == ']' || ch == ')':
+			depth--
+		case ch == '|' && depth == 0:
+			parts = append(parts, strings.TrimSpace(expr[start:i]))
+			start = i + 1
+		}
+	}
+	if parts == nil {
+		return expr, nil
+	}
+	return parts[0], append(parts[1:], strings.TrimSpace(expr[start:]))
+}
+
+// applyTransforms applies the named binding transforms to the value extracted by a JSONPath
+// expression, in order.
+func applyTransforms(value string, transforms []string) (string, error) {
+	for _, name := range transforms {
+		fn, ok := bindingTransforms[name]
+		if !ok {
+			return "", unknownTransformError(name)
+		}
+		value = applyToJSONStringFragment(value, fn)
+	}
+	return value, nil
+}
+
+// applyTransformsToArray applies the named binding transforms to the strings of the value of an array
+// param, in order. The other values of the array are left as is.
+func applyTransformsToArray(values []interface{}, transforms []string) error {
+	for _, name := range transforms {
+		fn, ok := bindingTransforms[name]
+		if !ok {
+			return unknownTransformError(name)
+		}
+		for i, v := range values {
+			if s, ok := v.(string); ok {
+				values[i] = fn(s)
+			}
+		}
+	}
+	return nil
+}
+
+func unknownTransformError(name string) error {
+	return fmt.Errorf("unknown transform %q, expected one of %s", name, strings.Join(transformNames(), ", "))
+}
+
+func transformNames() []string {
+	names := make([]string, 0, len(bindingTransforms))
+	for name := range bindingTransforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
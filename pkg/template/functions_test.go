@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+)
+
+func Test_applyParamToResourceTemplate_Functions(t *testing.T) {
+	tests := []struct {
+		name      string
+		param     triggersv1.Param
+		rt        json.RawMessage
+		oldEscape bool
+		want      json.RawMessage
+	}{{
+		name:  "upper",
+		param: triggersv1.Param{Name: "branch", Value: "main"},
+		rt:    json.RawMessage(`{"foo": "$(upper(tt.params.branch))"}`),
+		want:  json.RawMessage(`{"foo": "MAIN"}`),
+	}, {
+		name:  "lower",
+		param: triggersv1.Param{Name: "branch", Value: "Feature-X"},
+		rt:    json.RawMessage(`{"foo": "$(lower(tt.params.branch))"}`),
+		want:  json.RawMessage(`{"foo": "feature-x"}`),
+	}, {
+		name:  "trim",
+		param: triggersv1.Param{Name: "branch", Value: "  main  "},
+		rt:    json.RawMessage(`{"foo": "$(trim(tt.params.branch))"}`),
+		want:  json.RawMessage(`{"foo": "main"}`),
+	}, {
+		name:  "trimprefix",
+		param: triggersv1.Param{Name: "ref", Value: "refs/heads/main"},
+		rt:    json.RawMessage(`{"foo": "$(trimprefix(tt.params.ref, 'refs/heads/'))"}`),
+		want:  json.RawMessage(`{"foo": "main"}`),
+	}, {
+		name:  "trimsuffix",
+		param: triggersv1.Param{Name: "repo", Value: "triggers.git"},
+		rt:    json.RawMessage(`{"foo": "$(trimsuffix(tt.params.repo, '.git'))"}`),
+		want:  json.RawMessage(`{"foo": "triggers"}`),
+	}, {
+		name:  "default with empty value",
+		param: triggersv1.Param{Name: "branch", Value: ""},
+		rt:    json.RawMessage(`{"foo": "$(default(tt.params.branch, 'main'))"}`),
+		want:  json.RawMessage(`{"foo": "main"}`),
+	}, {
+		name:  "default with value",
+		param: triggersv1.Param{Name: "branch", Value: "dev"},
+		rt:    json.RawMessage(`{"foo": "$(default(tt.params.branch, 'main'))"}`),
+		want:  json.RawMessage(`{"foo": "dev"}`),
+	}, {
+		name:  "function mixed with plain param",
+		param: triggersv1.Param{Name: "branch", Value: "main"},
+		rt:    json.RawMessage(`{"foo": "$(tt.params.branch)-$(upper(tt.params.branch))"}`),
+		want:  json.RawMessage(`{"foo": "main-MAIN"}`),
+	}, {
+		name:  "escaped value",
+		param: triggersv1.Param{Name: "msg", Value: `say \"hi\"`},
+		rt:    json.RawMessage(`{"foo": "$(upper(tt.params.msg))"}`),
+		want:  json.RawMessage(`{"foo": "SAY \"HI\""}`),
+	}, {
+		name:      "old escape",
+		param:     triggersv1.Param{Name: "msg", Value: `{"a":"b"}`},
+		rt:        json.RawMessage(`{"foo": "$(upper(tt.params.msg))"}`),
+		oldEscape: true,
+		want:      json.RawMessage(`{"foo": "{\"A\":\"B\"}"}`),
+	}, {
+		name:  "other param is untouched",
+		param: triggersv1.Param{Name: "branch", Value: "main"},
+		rt:    json.RawMessage(`{"foo": "$(upper(tt.params.other))"}`),
+		want:  json.RawMessage(`{"foo": "$(upper(tt.params.other))"}`),
+	}, {
+		name:  "unknown function is untouched",
+		param: triggersv1.Param{Name: "branch", Value: "main"},
+		rt:    json.RawMessage(`{"foo": "$(reverse(tt.params.branch))"}`),
+		want:  json.RawMessage(`{"foo": "$(reverse(tt.params.branch))"}`),
+	}, {
+		name:  "missing argument is untouched",
+		param: triggersv1.Param{Name: "ref", Value: "refs/heads/main"},
+		rt:    json.RawMessage(`{"foo": "$(trimprefix(tt.params.ref))"}`),
+		want:  json.RawMessage(`{"foo": "$(trimprefix(tt.params.ref))"}`),
+	}, {
+		name:  "unexpected argument is untouched",
+		param: triggersv1.Param{Name: "branch", Value: "main"},
+		rt:    json.RawMessage(`{"foo": "$(upper(tt.params.branch, 'x'))"}`),
+		want:  json.RawMessage(`{"foo": "$(upper(tt.params.branch, 'x'))"}`),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := applyParamToResourceTemplate(tt.param, tt.rt, tt.oldEscape)
+			if diff := cmp.Diff(string(tt.want), string(got)); diff != "" {
+				t.Errorf("applyParamToResourceTemplate(): -want +got: %s", diff)
+			}
+		})
+	}
+}
+
+func Test_bindingTransforms(t *testing.T) {
+	long := strings.Repeat("a", 62) + "-b"
+	for _, tc := range []struct {
+		transform string
+		in        string
+		want      string
+	}{
+		{transform: "dnslabel", in: "Feature/Login_Page", want: "feature-login-page"},
+		{transform: "dnslabel", in: "--refs/heads/main.", want: "refs-heads-main"},
+		{transform: "dnslabel", in: long, want: strings.Repeat("a", 62)},
+		{transform: "dnslabel", in: "ünïcode", want: "n-code"},
+		{transform: "labelvalue", in: "Release 1.0!", want: "Release_1.0"},
+		{transform: "labelvalue", in: "_v1.2.3_", want: "v1.2.3"},
+		{transform: "labelvalue", in: strings.Repeat("A", 70), want: strings.Repeat("A", 63)},
+	} {
+		if got := bindingTransforms[tc.transform](tc.in); got != tc.want {
+			t.Errorf("%s(%q) = %q, want %q", tc.transform, tc.in, got, tc.want)
+		}
+	}
+}
+
+func Test_splitTransforms(t *testing.T) {
+	for _, tc := range []struct {
+		in             string
+		wantPath       string
+		wantTransforms []string
+	}{
+		{in: "body.a", wantPath: "body.a"},
+		{in: "body.a | lower | trim", wantPath: "body.a", wantTransforms: []string{"lower", "trim"}},
+		{in: "body.a|upper", wantPath: "body.a", wantTransforms: []string{"upper"}},
+		{in: "body.l[?(@.n == 'a|b')].v", wantPath: "body.l[?(@.n == 'a|b')].v"},
+		{in: `body.l[?(@.n == "|")].v | lower`, wantPath: `body.l[?(@.n == "|")].v`, wantTransforms: []string{"lower"}},
+		{in: "body.a |", wantPath: "body.a", wantTransforms: []string{""}},
+	} {
+		path, transforms := splitTransforms(tc.in)
+		if path != tc.wantPath {
+			t.Errorf("splitTransforms(%q) returned path %q, want %q", tc.in, path, tc.wantPath)
+		}
+		if diff := cmp.Diff(tc.wantTransforms, transforms); diff != "" {
+			t.Errorf("splitTransforms(%q) transforms -want +got: %s", tc.in, diff)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	gotemplate "text/template"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// maxGoTemplateOutputSize bounds the size of the documents rendered by a Go template.
+const maxGoTemplateOutputSize = 1024 * 1024
+
+var errGoTemplateOutputTooLarge = fmt.Errorf("the rendered documents are larger than %d bytes", maxGoTemplateOutputSize)
+
+type getConfigMap func(name string) (*corev1.ConfigMap, error)
+
+// goTemplateFuncs are the functions of Go templates besides the builtins of text/template. They are a
+// safe subset of the functions of Helm: none of them reads the environment or files, or makes requests.
+// Like in Helm, the value a function applies to is its last argument, so that it can be piped.
+var goTemplateFuncs = gotemplate.FuncMap{
+	"default":    defaultValue,
+	"toJson":     toJSON,
+	"quote":      quote,
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+	"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+	"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+	"join":       join,
+	"indent":     indent,
+	"nindent":    func(n int, s string) string { return "\n" + indent(n, s) },
+}
+
+// RenderGoTemplates renders the Go templates of the TriggerTemplate, read from the ConfigMaps returned by
+// getCM, and returns the resources of the documents they render, in order. The templates are rendered with
+// the values of the params as .params, and the event as .body, .header, .query, .cookie, .extensions and
+// .context, named like in the JSONPath expressions of TriggerBindings. Unlike resource templates, the
+// rendered documents are created as they are, without replacing $(tt.params) or $(uid).
+func RenderGoTemplates(tt *triggersv1.TriggerTemplate, params []triggersv1.Param, body []byte, header http.Header, query url.Values, extensions map[string]interface{}, triggerContext TriggerContext, getCM getConfigMap) ([]json.RawMessage, error) {
+	if len(tt.Spec.GoTemplates) == 0 {
+		return nil, nil
+	}
+	data, err := goTemplateData(params, body, header, query, extensions, triggerContext)
+	if err != nil {
+		return nil, err
+	}
+	var resources []json.RawMessage
+	for _, ref := range tt.Spec.GoTemplates {
+		docs, err := renderGoTemplate(ref, data, getCM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render Go template %s/%s of TriggerTemplate %s: %w", ref.ConfigMapName, ref.Key, tt.Name, err)
+		}
+		resources = append(resources, docs...)
+	}
+	return resources, nil
+}
+
+// goTemplateData returns the values the Go templates are rendered with.
+func goTemplateData(params []triggersv1.Param, body []byte, header http.Header, query url.Values, extensions map[string]interface{}, triggerContext TriggerContext) (map[string]interface{}, error) {
+	ev, err := newEvent(body, header, query, extensions, triggerContext)
+	if err != nil {
+		return nil, err
+	}
+	// The event is converted to plain maps, so that the templates access its fields with the names of
+	// their JSON encoding.
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]interface{}
+	if err := triggerContext.jsonNumbers.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{}, len(params))
+	for _, p := range params {
+		values[p.Name] = p.Value
+	}
+	data["params"] = values
+	return data, nil
+}
+
+// renderGoTemplate renders the template of ref and returns its documents. The name of the template is
+// <ConfigMap>/<key>, so that the parse and execution errors of text/template, which start with the name
+// and the line of the template, say which template failed.
+func renderGoTemplate(ref triggersv1.GoTemplateRef, data map[string]interface{}, getCM getConfigMap) ([]json.RawMessage, error) {
+	cm, err := getCM(ref.ConfigMapName)
+	if err != nil {
+		return nil, err
+	}
+	text, ok := cm.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("ConfigMap %s has no key %s", ref.ConfigMapName, ref.Key)
+	}
+	t, err := gotemplate.New(ref.ConfigMapName + "/" + ref.Key).Option("missingkey=error").Funcs(goTemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	out := &limitedBuffer{max: maxGoTemplateOutputSize}
+	if err := t.Execute(out, data); err != nil {
+		if errors.Is(err, errGoTemplateOutputTooLarge) {
+			return nil, errGoTemplateOutputTooLarge
+		}
+		return nil, err
+	}
+	block, err := json.Marshal(out.String())
+	if err != nil {
+		return nil, err
+	}
+	return triggersv1.TriggerResourceTemplate{RawExtension: runtime.RawExtension{Raw: block}}.Documents()
+}
+
+// limitedBuffer is a bytes.Buffer that fails the writes beyond max bytes.
+type limitedBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.max {
+		return 0, errGoTemplateOutputTooLarge
+	}
+	return b.Buffer.Write(p)
+}
+
+// defaultValue returns v, or d if v is empty.
+func defaultValue(d, v interface{}) interface{} {
+	switch x := v.(type) {
+	case nil:
+		return d
+	case string:
+		if x == "" {
+			return d
+		}
+	case bool:
+		if !x {
+			return d
+		}
+	case []interface{}:
+		if len(x) == 0 {
+			return d
+		}
+	case map[string]interface{}:
+		if len(x) == 0 {
+			return d
+		}
+	}
+	return v
+}
+
+// toJSON returns the JSON encoding of v, e.g. to insert a value of the event into a document as a JSON or
+// YAML value that can't change the structure of the document.
+func toJSON(v interface{}) (string, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// quote returns the string value of v as a double quoted JSON string, which is also a valid YAML string.
+func quote(v interface{}) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		s = fmt.Sprint(v)
+	}
+	return toJSON(s)
+}
+
+// join joins the elements of a list, converted to strings, with sep.
+func join(sep string, list interface{}) (string, error) {
+	switch l := list.(type) {
+	case []string:
+		return strings.Join(l, sep), nil
+	case []interface{}:
+		elems := make([]string, len(l))
+		for i, e := range l {
+			elems[i] = fmt.Sprint(e)
+		}
+		return strings.Join(elems, sep), nil
+	}
+	return "", fmt.Errorf("join expects a list, got %T", list)
+}
+
+// indent prefixes each line of s with n spaces.
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
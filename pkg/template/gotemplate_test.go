@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const pipelineRunGoTemplate = `apiVersion: tekton.dev/v1beta1
+kind: PipelineRun
+metadata:
+  generateName: {{ .params.name | lower }}-
+  labels:
+    event: {{ .context.eventID }}
+spec:
+  pipelineRef:
+    name: build
+  params:
+    - name: message
+      value: {{ .body.message | toJson }}
+    - name: branch
+      value: {{ index .body "branch" | default "main" | quote }}
+{{- range .body.targets }}
+---
+apiVersion: tekton.dev/v1beta1
+kind: TaskRun
+metadata:
+  generateName: deploy-{{ . }}-
+spec:
+  taskRef:
+    name: deploy
+{{- end }}
+`
+
+func goTemplateConfigMaps(data map[string]string) getConfigMap {
+	return func(name string) (*corev1.ConfigMap, error) {
+		if name != "pipelines" {
+			return nil, fmt.Errorf("configmap %q not found", name)
+		}
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name}, Data: data}, nil
+	}
+}
+
+func goTemplateTriggerTemplate(refs ...triggersv1.GoTemplateRef) *triggersv1.TriggerTemplate {
+	return &triggersv1.TriggerTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "tt"},
+		Spec:       triggersv1.TriggerTemplateSpec{GoTemplates: refs},
+	}
+}
+
+func TestRenderGoTemplates(t *testing.T) {
+	tt := goTemplateTriggerTemplate(triggersv1.GoTemplateRef{ConfigMapName: "pipelines", Key: "build.yaml"})
+	params := []triggersv1.Param{{Name: "name", Value: "Triggers"}}
+	body := []byte(`{"message": "fix: \"quotes\"\nand: newlines", "targets": ["dev", "prod"]}`)
+
+	got, err := RenderGoTemplates(tt, params, body, http.Header{}, nil, nil, NewTriggerContext("abcde"),
+		goTemplateConfigMaps(map[string]string{"build.yaml": pipelineRunGoTemplate}))
+	if err != nil {
+		t.Fatalf("RenderGoTemplates() returned error: %v", err)
+	}
+	want := []string{
+		`{"apiVersion":"tekton.dev/v1beta1","kind":"PipelineRun","metadata":{"generateName":"triggers-","labels":{"event":"abcde"}},"spec":{"params":[{"name":"message","value":"fix: \"quotes\"\nand: newlines"},{"name":"branch","value":"main"}],"pipelineRef":{"name":"build"}}}`,
+		`{"apiVersion":"tekton.dev/v1beta1","kind":"TaskRun","metadata":{"generateName":"deploy-dev-"},"spec":{"taskRef":{"name":"deploy"}}}`,
+		`{"apiVersion":"tekton.dev/v1beta1","kind":"TaskRun","metadata":{"generateName":"deploy-prod-"},"spec":{"taskRef":{"name":"deploy"}}}`,
+	}
+	var gotDocs []string
+	for _, doc := range got {
+		// Compact the documents into a canonical form, with sorted keys.
+		var v interface{}
+		if err := json.Unmarshal(doc, &v); err != nil {
+			t.Fatalf("RenderGoTemplates() returned invalid JSON %s: %v", doc, err)
+		}
+		b, _ := json.Marshal(v)
+		gotDocs = append(gotDocs, string(b))
+	}
+	if diff := cmp.Diff(want, gotDocs); diff != "" {
+		t.Errorf("RenderGoTemplates() -want +got: %s", diff)
+	}
+}
+
+func TestRenderGoTemplates_NoGoTemplates(t *testing.T) {
+	got, err := RenderGoTemplates(goTemplateTriggerTemplate(), nil, nil, nil, nil, nil, NewTriggerContext("abcde"), func(string) (*corev1.ConfigMap, error) {
+		t.Fatal("unexpected ConfigMap lookup")
+		return nil, nil
+	})
+	if err != nil || got != nil {
+		t.Errorf("RenderGoTemplates() = %v, %v, want no resources", got, err)
+	}
+}
+
+func TestRenderGoTemplates_Error(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		ref  triggersv1.GoTemplateRef
+		text string
+		want string
+	}{{
+		name: "missing ConfigMap",
+		ref:  triggersv1.GoTemplateRef{ConfigMapName: "missing", Key: "build.yaml"},
+		want: `failed to render Go template missing/build.yaml of TriggerTemplate tt: configmap "missing" not found`,
+	}, {
+		name: "missing key",
+		ref:  triggersv1.GoTemplateRef{ConfigMapName: "pipelines", Key: "deploy.yaml"},
+		want: "ConfigMap pipelines has no key deploy.yaml",
+	}, {
+		name: "parse error",
+		ref:  triggersv1.GoTemplateRef{ConfigMapName: "pipelines", Key: "build.yaml"},
+		text: "kind: PipelineRun\nmetadata:\n  name: {{ .params.name \n",
+		want: "template: pipelines/build.yaml:4: unclosed action started at pipelines/build.yaml:3",
+	}, {
+		name: "missing value",
+		ref:  triggersv1.GoTemplateRef{ConfigMapName: "pipelines", Key: "build.yaml"},
+		text: "kind: PipelineRun\nmetadata:\n  name: {{ .params.missing }}\n",
+		want: `template: pipelines/build.yaml:3:18: executing "pipelines/build.yaml" at <.params.missing>: map has no entry for key "missing"`,
+	}, {
+		name: "unsafe function",
+		ref:  triggersv1.GoTemplateRef{ConfigMapName: "pipelines", Key: "build.yaml"},
+		text: `kind: {{ env "HOME" }}`,
+		want: `template: pipelines/build.yaml:1: function "env" not defined`,
+	}, {
+		name: "invalid document",
+		ref:  triggersv1.GoTemplateRef{ConfigMapName: "pipelines", Key: "build.yaml"},
+		text: "- not an object",
+		want: "document 0 is not an object",
+	}, {
+		name: "too large",
+		ref:  triggersv1.GoTemplateRef{ConfigMapName: "pipelines", Key: "build.yaml"},
+		text: `{{ range .body.items }}` + strings.Repeat("x", 1024*1024) + `{{ end }}`,
+		want: "the rendered documents are larger than 1048576 bytes",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := RenderGoTemplates(goTemplateTriggerTemplate(tc.ref), nil, []byte(`{"items": [1, 2]}`), http.Header{}, nil, nil, NewTriggerContext("abcde"),
+				goTemplateConfigMaps(map[string]string{"build.yaml": tc.text}))
+			if err == nil {
+				t.Fatal("RenderGoTemplates() returned no error")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("RenderGoTemplates() returned error %q, want it to contain %q", err, tc.want)
+			}
+		})
+	}
+}
@@ -22,11 +22,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"net/textproto"
 	"reflect"
 	"regexp"
 	"strings"
 
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
 	"k8s.io/client-go/util/jsonpath"
 )
 
@@ -38,10 +40,32 @@ var (
 	// with or without the enclosing {} and the leading . inside the curly
 	// braces e.g.  'a.b' or '.a.b' or '{a.b}' or '{.a.b}'
 	jsonRegexp = regexp.MustCompile(`^\{\.?([^{}]+)\}$|^\.?([^{}]+)$`)
+
+	// filterRegexp matches the predicates supported in array filters e.g.
+	// "@.name == 'deploy'" or "@.distinct", and captures the field, the
+	// operator and the literal.
+	filterRegexp = regexp.MustCompile(`^@((?:\.[\w-]+)*)\s*(?:(==|!=|<=|>=|<|>)\s*('[^']*'|"[^"]*"|-?\d+(?:\.\d+)?|true|false|null))?$`)
 )
 
+// errPathNotFound is wrapped by the errors of the valid expressions that
+// select nothing in the input, e.g. because a key of the path is absent, an
+// intermediate value is null or of another type, or an array filter matches
+// no elements. The default value of the param is used instead of their
+// value. The errors of malformed expressions don't wrap it.
+var errPathNotFound = errors.New("path not found")
+
+// pathNotFoundError wraps errPathNotFound without changing the message of err.
+type pathNotFoundError struct {
+	err error
+}
+
+func (e *pathNotFoundError) Error() string { return e.err.Error() }
+
+func (e *pathNotFoundError) Unwrap() error { return errPathNotFound }
+
 // parseJSONPath extracts a subset of the given JSON input
-// using the provided JSONPath expression.
+// using the provided JSONPath expression. The errors of expressions that
+// select nothing in the input wrap errPathNotFound.
 func parseJSONPath(input interface{}, expr string) (string, error) {
 	j := jsonpath.New("").AllowMissingKeys(false)
 	buf := new(bytes.Buffer)
@@ -52,13 +76,40 @@ func parseJSONPath(input interface{}, expr string) (string, error) {
 		return "", err
 	}
 
-	if err := j.Parse(expr); err != nil {
-		return "", err
+	path := strings.TrimSuffix(strings.TrimPrefix(expr, "{"), "}")
+	if !strings.Contains(path, "[?(") {
+		if err := j.Parse(expr); err != nil {
+			return "", err
+		}
+	}
+
+	// Array filters and wildcards are evaluated by findResults so that
+	// elements missing the rest of the path are skipped instead of failing the
+	// expression.
+	if strings.Contains(path, "[?(") || strings.Contains(path, "[*]") {
+		results, err := findResults(input, path)
+		if err != nil {
+			return "", err
+		}
+		values := make([]reflect.Value, len(results))
+		for i := range results {
+			values[i] = reflect.ValueOf(&results[i]).Elem()
+		}
+		if err := printResults(buf, values); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
 	}
 
 	fullResults, err := j.FindResults(input)
 	if err != nil {
-		return "", err
+		return "", &pathNotFoundError{err: err}
+	}
+
+	// A path that selects a single node selects none when it indexes a null
+	// value, which is absent like a missing key.
+	if selectsSingleNode(path) && countResults(fullResults) == 0 {
+		return "", &pathNotFoundError{err: fmt.Errorf("%s has a null value on its path", path)}
 	}
 
 	for _, r := range fullResults {
@@ -70,6 +121,289 @@ func parseJSONPath(input interface{}, expr string) (string, error) {
 	return buf.String(), nil
 }
 
+// selectsSingleNode returns whether the JSONPath path selects at most one
+// node, i.e. it has no wildcards, recursive descents, slices or unions.
+func selectsSingleNode(path string) bool {
+	return !strings.ContainsAny(path, "*:,") && !strings.Contains(path, "..")
+}
+
+func countResults(results [][]reflect.Value) int {
+	n := 0
+	for _, r := range results {
+		n += len(r)
+	}
+	return n
+}
+
+// findResults returns the values matched by the JSONPath path, which is not
+// wrapped in curly braces. The errors of paths that can't be resolved against
+// input wrap errPathNotFound.
+func findResults(input interface{}, path string) ([]interface{}, error) {
+	if strings.Contains(path, "[?(") {
+		return filterResults(input, path)
+	}
+	if strings.Contains(path, "[*]") {
+		return wildcardResults(input, path)
+	}
+	if path == "" {
+		return []interface{}{input}, nil
+	}
+	j := jsonpath.New("").AllowMissingKeys(false)
+	if err := j.Parse("{" + path + "}"); err != nil {
+		return nil, err
+	}
+	fullResults, err := j.FindResults(input)
+	if err != nil {
+		return nil, &pathNotFoundError{err: err}
+	}
+	var results []interface{}
+	for _, r := range fullResults {
+		for _, v := range r {
+			results = append(results, v.Interface())
+		}
+	}
+	return results, nil
+}
+
+// findArrayResults returns the nodes matched by the expression expr wrapped in $(), for array params. A
+// single matched node that is an array is returned as is, and the values of query parameters are returned
+// separately. Expressions that can't be resolved against input, e.g. because of a missing key, match no
+// nodes, but malformed expressions, e.g. with an unsupported filter, return an error.
+func findArrayResults(input interface{}, expr string) ([]interface{}, error) {
+	expr, err := tektonJSONPathExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	path := strings.TrimSuffix(strings.TrimPrefix(expr, "{"), "}")
+	if !strings.Contains(path, "[?(") {
+		if err := jsonpath.New("").Parse(expr); err != nil {
+			return nil, err
+		}
+	}
+	results, err := findResults(input, path)
+	if errors.Is(err, errPathNotFound) {
+		return []interface{}{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 1 {
+		if a, ok := results[0].([]interface{}); ok {
+			return a, nil
+		}
+	}
+	values := []interface{}{}
+	for _, r := range results {
+		if q, ok := r.(joinedValues); ok {
+			for _, v := range q {
+				values = append(values, v)
+			}
+			continue
+		}
+		values = append(values, r)
+	}
+	return values, nil
+}
+
+// filterResults evaluates the first array filter in path, and the rest of the
+// path against each of the matching elements. It returns an error wrapping
+// errPathNotFound if no element matches, so that the default value of the
+// param is used.
+func filterResults(input interface{}, path string) ([]interface{}, error) {
+	start := strings.Index(path, "[?(")
+	end := closingFilter(path, start+len("[?("))
+	if end < 0 {
+		return nil, fmt.Errorf("unterminated filter in %s", path)
+	}
+	predicate := strings.TrimSpace(path[start+len("[?(") : end])
+	f, err := parseFilter(predicate)
+	if err != nil {
+		return nil, err
+	}
+
+	arrays, err := findResults(input, path[:start])
+	if err != nil {
+		return nil, err
+	}
+	rest := path[end+len(")]"):]
+	var results []interface{}
+	for _, a := range arrays {
+		elements, ok := a.([]interface{})
+		if !ok {
+			return nil, &pathNotFoundError{err: fmt.Errorf("%s is not an array", path[:start])}
+		}
+		for _, e := range elements {
+			if !f.matches(e) {
+				continue
+			}
+			r, err := findResults(e, rest)
+			if errors.Is(err, errPathNotFound) {
+				// Like a missing field, a missing rest of the path only skips the element.
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, r...)
+		}
+	}
+	if len(results) == 0 {
+		return nil, &pathNotFoundError{err: fmt.Errorf("no element of %s matches the filter %s", path[:start], predicate)}
+	}
+	return results, nil
+}
+
+// wildcardResults evaluates the first [*] wildcard in path, and the rest of the
+// path against each of the elements, so that the nodes matched by several
+// levels of wildcards are flattened in a single list. The elements that don't
+// have the rest of the path, e.g. because it has a missing key or a null value,
+// are skipped. It returns an error wrapping errPathNotFound only if there were
+// elements, and they all were skipped.
+func wildcardResults(input interface{}, path string) ([]interface{}, error) {
+	start := strings.Index(path, "[*]")
+	arrays, err := findResults(input, path[:start])
+	if err != nil {
+		return nil, err
+	}
+	rest := path[start+len("[*]"):]
+	results := []interface{}{}
+	skipped := false
+	for _, a := range arrays {
+		elements, ok := a.([]interface{})
+		if !ok {
+			skipped = true
+			continue
+		}
+		for _, e := range elements {
+			r, err := findResults(e, rest)
+			if errors.Is(err, errPathNotFound) {
+				skipped = true
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, r...)
+		}
+	}
+	if len(results) == 0 && skipped {
+		return nil, &pathNotFoundError{err: fmt.Errorf("no element of %s has %s", path[:start], rest)}
+	}
+	return results, nil
+}
+
+// closingFilter returns the index of the ")]" closing the filter that starts at
+// index i of path, ignoring quoted literals, or -1.
+func closingFilter(path string, i int) int {
+	var quote rune
+	for j, ch := range path[i:] {
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == ')' && strings.HasPrefix(path[i+j:], ")]"):
+			return i + j
+		}
+	}
+	return -1
+}
+
+// filter is an array filter predicate that compares a field of each element to
+// a literal value. Without an operator, it matches the elements that have the
+// field.
+type filter struct {
+	field    []string
+	operator string
+	value    interface{}
+}
+
+func parseFilter(predicate string) (*filter, error) {
+	m := filterRegexp.FindStringSubmatch(predicate)
+	if m == nil {
+		return nil, fmt.Errorf("unsupported filter %s, expected a field of @ compared to a string, number, boolean or null literal", predicate)
+	}
+	f := &filter{operator: m[2]}
+	if m[1] != "" {
+		f.field = strings.Split(m[1][1:], ".")
+	}
+	switch literal := m[3]; {
+	case strings.HasPrefix(literal, "'"):
+		f.value = strings.Trim(literal, "'")
+	case literal != "":
+		// Number literals are kept exact, to compare them to large integers.
+		if err := triggersv1.JSONNumbersExact.Unmarshal([]byte(literal), &f.value); err != nil {
+			return nil, fmt.Errorf("invalid literal in filter %s: %w", predicate, err)
+		}
+	}
+	if _, ok := f.value.(json.Number); !ok && f.operator != "" && f.operator != "==" && f.operator != "!=" {
+		return nil, fmt.Errorf("operator %s in filter %s can only compare numbers", f.operator, predicate)
+	}
+	return f, nil
+}
+
+// matches returns true if element satisfies the filter. Elements that do not
+// have the field never match.
+func (f *filter) matches(element interface{}) bool {
+	v := element
+	for _, k := range f.field {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if v, ok = m[k]; !ok {
+			return false
+		}
+	}
+	x, isNumber := number(v)
+	y, _ := number(f.value)
+	if n, ok := f.value.(json.Number); ok {
+		if _, ok := v.(float64); ok {
+			// Numbers decoded to float64 are compared to the literal rounded in the same way.
+			lit, _ := n.Float64()
+			y = big.NewFloat(lit)
+		}
+	}
+	switch f.operator {
+	case "":
+		return true
+	case "==", "!=":
+		equal := reflect.DeepEqual(v, f.value)
+		if isNumber && y != nil {
+			equal = x.Cmp(y) == 0
+		}
+		return equal == (f.operator == "==")
+	}
+	if !isNumber {
+		return false
+	}
+	switch c := x.Cmp(y); f.operator {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+// number returns the value of v if it is a number decoded from JSON, either
+// to a float64 or exactly to a json.Number.
+func number(v interface{}) (*big.Float, bool) {
+	switch n := v.(type) {
+	case float64:
+		return big.NewFloat(n), true
+	case json.Number:
+		f, _, err := big.ParseFloat(string(n), 10, 256, big.ToNearestEven)
+		return f, err == nil
+	}
+	return nil, false
+}
+
 // PrintResults writes the results into writer
 func printResults(wr io.Writer, values []reflect.Value) error {
 	results, err := getResults(values)
@@ -90,6 +424,11 @@ func getResults(values []reflect.Value) ([]byte, error) {
 		switch {
 		case t == nil:
 			return []byte("null"), nil
+		case t == reflect.TypeOf(joinedValues{}):
+			return []byte(v.Interface().(joinedValues).String()), nil
+		case t == reflect.TypeOf(json.Number("")):
+			// Exactly decoded numbers are written as they were in the event.
+			return []byte(v.Interface().(json.Number).String()), nil
 		case t.Kind() == reflect.String:
 			b, err := json.Marshal(v.Interface())
 			if err != nil {
@@ -166,7 +505,8 @@ func isTektonExpr(expr string) bool {
 
 // findTektonExpressions searches for and returns a slice of
 // all substrings that are wrapped in $()
-// substring with "header." is converted with CanonicalMIMEHeaderKey in the first array
+// substring with "header." is converted with CanonicalMIMEHeaderKey in the first array, leaving
+// the transforms that follow the header name alone
 // the second array has the original substrings
 func findTektonExpressions(in string) ([]string, []string) {
 	results := []string{}
@@ -191,7 +531,11 @@ func findTektonExpressions(in string) ([]string, []string) {
 					raw := e[:i]
 					originals = append(originals, fmt.Sprintf("$(%s)", raw))
 					if strings.Index(raw, "header.") == 0 {
-						raw = "header." + textproto.CanonicalMIMEHeaderKey(raw[len("header."):])
+						path, transforms := splitTransforms(raw)
+						raw = "header." + textproto.CanonicalMIMEHeaderKey(path[len("header."):])
+						if len(transforms) > 0 {
+							raw += " | " + strings.Join(transforms, " | ")
+						}
 					}
 					results = append(results, fmt.Sprintf("$(%s)", raw))
 				}
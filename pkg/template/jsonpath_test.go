@@ -18,6 +18,7 @@ package template
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -104,6 +105,71 @@ func TestParseJSONPath(t *testing.T) {
 		in:   `{"body":{"child":[{"a": "b", "w": "1"}, {"a": "c", "w": "2"}, {"a": "d", "w": "3"}]}}`,
 		expr: "$(body.child[?(@.a == 'd')].w)",
 		want: "3",
+	}, {
+		name: "array filter skips elements without the field",
+		in:   `{"body":{"labels":[{"value": "x"}, {"name": "deploy", "value": "prod"}]}}`,
+		expr: "$(body.labels[?(@.name=='deploy')].value)",
+		want: "prod",
+	}, {
+		name: "array filter with multiple matches",
+		in:   `{"body":{"labels":[{"name": "deploy", "value": "staging"}, {"name": "ci"}, {"name": "deploy", "value": "prod"}]}}`,
+		expr: `$(body.labels[?(@.name=="deploy")].value)`,
+		want: `["staging","prod"]`,
+	}, {
+		name: "array filter on booleans",
+		in:   `{"body":{"commits":[{"id": "a", "distinct": false}, {"id": "b", "distinct": true}]}}`,
+		expr: "$(body.commits[?(@.distinct == true)].id)",
+		want: "b",
+	}, {
+		name: "array filter not equal",
+		in:   `{"body":{"commits":[{"id": "a"}, {"id": "b"}]}}`,
+		expr: "$(body.commits[?(@.id != 'a')].id)",
+		want: "b",
+	}, {
+		name: "array filter on numbers",
+		in:   `{"body":{"checks":[{"name": "lint", "failures": 0}, {"name": "test", "failures": 3}]}}`,
+		expr: "$(body.checks[?(@.failures > 0)].name)",
+		want: "test",
+	}, {
+		name: "array filter on integer equality",
+		in:   `{"body":{"checks":[{"name": "lint", "failures": 0}, {"name": "test", "failures": 3}]}}`,
+		expr: "$(body.checks[?(@.failures == 3)].name)",
+		want: "test",
+	}, {
+		name: "array filter on nested fields",
+		in:   `{"body":{"commits":[{"id": "a", "author": {"name": "bot"}}, {"id": "b", "author": {"name": "jane"}}]}}`,
+		expr: "$(body.commits[?(@.author.name == 'jane')].id)",
+		want: "b",
+	}, {
+		name: "array filter on field existence",
+		in:   `{"body":{"commits":[{"id": "a"}, {"id": "b", "merged": true}]}}`,
+		expr: "$(body.commits[?(@.merged)].id)",
+		want: "b",
+	}, {
+		name: "array filter on values",
+		in:   `{"body":{"tags":["v1", "latest"]}}`,
+		expr: "$(body.tags[?(@ == 'latest')])",
+		want: "latest",
+	}, {
+		name: "array filter returning the element",
+		in:   `{"body":{"labels":[{"name": "ci"}, {"name": "deploy", "value": "prod"}]}}`,
+		expr: "$(body.labels[?(@.name == 'deploy')])",
+		want: `{"name":"deploy","value":"prod"}`,
+	}, {
+		name: "nested array filters",
+		in:   `{"body":{"repos":[{"name": "a", "labels": [{"name": "deploy", "value": "1"}]}, {"name": "b", "labels": [{"name": "deploy", "value": "2"}]}]}}`,
+		expr: "$(body.repos[?(@.name == 'b')].labels[?(@.name == 'deploy')].value)",
+		want: "2",
+	}, {
+		name: "array filter with an index",
+		in:   `{"body":{"commits":[{"id": "a", "files": ["x", "y"]}, {"id": "b", "files": ["z"]}]}}`,
+		expr: "$(body.commits[?(@.id == 'a')].files[1])",
+		want: "y",
+	}, {
+		name: "array filter with a bracket in the literal",
+		in:   `{"body":{"labels":[{"name": "a]b", "value": "1"}]}}`,
+		expr: "$(body.labels[?(@.name == 'a]b')].value)",
+		want: "1",
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -124,7 +190,7 @@ func TestParseJSONPath(t *testing.T) {
 }
 
 func TestParseJSONPath_Error(t *testing.T) {
-	testJSON := `{"body": {"key": "val"}}`
+	testJSON := `{"body": {"key": "val", "list": [{"name": "a", "value": "b"}]}}`
 	invalidExprs := []string{
 		"$({.hello)",
 		"$(+12.3.0)",
@@ -134,6 +200,15 @@ func TestParseJSONPath_Error(t *testing.T) {
 		"body",
 		"$(body.missing)",
 		"$(body.key[0])",
+		"$(body[?(@.key == 'val')])",
+		"$(body.missing[?(@.key == 'val')])",
+		"$(body.key[?(@ == 'val')])",
+		"$(body.list[?(@.name == 'none')].value)",
+		"$(body.list[?(@.name == 'a')].missing)",
+		"$(body.list[?(@.name =~ 'a')])",
+		"$(body.list[?(@.name == 'a' && @.value == 'b')])",
+		"$(body.list[?(@.name < 'b')])",
+		"$(body.list[?(@.name == 'a')",
 	}
 	var data interface{}
 	err := json.Unmarshal([]byte(testJSON), &data)
@@ -151,6 +226,49 @@ func TestParseJSONPath_Error(t *testing.T) {
 	}
 }
 
+func TestParseJSONPath_PathNotFound(t *testing.T) {
+	testJSON := `{"body": {"a": null, "b": {"c": null}, "s": "str", "list": [{"name": "a"}], "empty": []}}`
+	var data interface{}
+	if err := json.Unmarshal([]byte(testJSON), &data); err != nil {
+		t.Fatalf("Could not unmarshall body : %q", err)
+	}
+	for _, tc := range []struct {
+		expr         string
+		wantNotFound bool
+	}{
+		{expr: "$(body.missing.b.c)", wantNotFound: true},
+		{expr: "$(body.a.b.c)", wantNotFound: true},
+		{expr: "$(body.a[0].b)", wantNotFound: true},
+		{expr: "$(body.b.c[0])", wantNotFound: true},
+		{expr: "$(body.b.c.d)", wantNotFound: true},
+		{expr: "$(body.s.b)", wantNotFound: true},
+		{expr: "$(body.s[0])", wantNotFound: true},
+		{expr: "$(body.list[?(@.name == 'b')].value)", wantNotFound: true},
+		{expr: "$(body.s[?(@.name == 'b')])", wantNotFound: true},
+		{expr: "$(body.b.[)"},
+		{expr: "$(body.list[?(@.name =~ 'a')])"},
+		{expr: "$(body.list[?(@.name == 'a')"},
+		{expr: "body.a"},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			_, err := parseJSONPath(data, tc.expr)
+			if err == nil {
+				t.Fatal("parseJSONPath() did not return an error")
+			}
+			if got := errors.Is(err, errPathNotFound); got != tc.wantNotFound {
+				t.Errorf("parseJSONPath() returned error %v, wrapping errPathNotFound = %t, want %t", err, got, tc.wantNotFound)
+			}
+		})
+	}
+
+	// The values selected by paths with wildcards can be empty, and null leaves are values.
+	for expr, want := range map[string]string{"$(body.empty[*])": "[]", "$(body.b.c)": "null"} {
+		if got, err := parseJSONPath(data, expr); err != nil || got != want {
+			t.Errorf("parseJSONPath(%s) = %q, %v, want %q", expr, got, err, want)
+		}
+	}
+}
+
 func TestTektonJSONPathExpression(t *testing.T) {
 	tests := []struct {
 		expr string
@@ -260,6 +378,10 @@ func TestFindTektonExpressions(t *testing.T) {
 		in:       "start:$(body.blah)//middle//$(header.ONE-TWO)-end",
 		want:     []string{"$(body.blah)", "$(header.One-Two)"},
 		original: []string{"$(body.blah)", "$(header.ONE-TWO)"},
+	}, {
+		in:       "$(header.x-repo | lower)",
+		want:     []string{"$(header.X-Repo | lower)"},
+		original: []string{"$(header.x-repo | lower)"},
 	}, {
 		in:       "start:$(body.[?(@.a == 'd')])-$(body.another-one)",
 		want:     []string{"$(body.[?(@.a == 'd')])", "$(body.another-one)"},
@@ -0,0 +1,275 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/resources"
+	discoveryclient "k8s.io/client-go/discovery"
+)
+
+// Severity is how serious a Finding is.
+type Severity string
+
+const (
+	// SeverityError is for mistakes that make the creation of the resource fail, or create it with
+	// placeholders left in.
+	SeverityError Severity = "error"
+	// SeverityWarning is for templates that likely don't do what their author intended.
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a mistake found in a resource template by LintResourceTemplate.
+type Finding struct {
+	Severity Severity `json:"severity"`
+	// Path is the path of the field the finding is about, e.g. metadata.name.
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+func (f Finding) String() string {
+	if f.Path == "" {
+		return fmt.Sprintf("%s: %s", f.Severity, f.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", f.Severity, f.Path, f.Message)
+}
+
+var (
+	// paramVariableMatch matches the expression of a param variable, e.g. tt.params.branch.
+	paramVariableMatch = regexp.MustCompile(`^tt\.params\.([_a-zA-Z][_a-zA-Z0-9.-]*)$`)
+	// funcCallMatch matches the expression of a template function call on a param, e.g.
+	// trimprefix(tt.params.ref, 'refs/heads/'). The groups are the function name, the param name and
+	// the optional argument.
+	funcCallMatch = regexp.MustCompile(`^([a-z]+)\(tt\.params\.([_a-zA-Z][_a-zA-Z0-9.-]*)(\s*,\s*'[^']*')?\)$`)
+)
+
+// LintTriggerTemplate lints the resource templates of tt, including each document of multi-document
+// templates, like LintResourceTemplate, and warns about declared params that none of them use.
+func LintTriggerTemplate(tt *triggersv1.TriggerTemplate, c discoveryclient.ServerResourcesInterface) []Finding {
+	var findings []Finding
+	used := map[string]bool{}
+	for i, rt := range tt.Spec.ResourceTemplates {
+		docs, err := rt.Documents()
+		if err != nil {
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Path:     fmt.Sprintf("spec.resourcetemplates[%d]", i),
+				Message:  err.Error(),
+			})
+			continue
+		}
+		for j, doc := range docs {
+			path := fmt.Sprintf("spec.resourcetemplates[%d]", i)
+			if len(docs) > 1 {
+				path = fmt.Sprintf("spec.resourcetemplates[%d][%d]", i, j)
+			}
+			l := newLinter(tt.Spec.Params, c)
+			l.lint(doc)
+			for _, f := range l.findings {
+				f.Path = strings.TrimSuffix(path+"."+f.Path, ".")
+				findings = append(findings, f)
+			}
+			for name := range l.used {
+				used[name] = true
+			}
+		}
+	}
+	for i, p := range tt.Spec.Params {
+		if !used[p.Name] {
+			findings = append(findings, Finding{
+				Severity: SeverityWarning,
+				Path:     fmt.Sprintf("spec.params[%d]", i),
+				Message:  fmt.Sprintf("param %s is not used by any resource template", p.Name),
+			})
+		}
+	}
+	return findings
+}
+
+// LintResourceTemplate checks the resource template rt for common mistakes: unbalanced $( in
+// variables, references to params missing from params, unknown template functions, both a name and a
+// generateName or neither of them, and, if c is not nil, an apiVersion and kind that don't resolve to
+// an API resource with the discovery client c. Variables that are not TriggerTemplate variables, such
+// as those of Tekton Pipelines, are left alone.
+func LintResourceTemplate(rt json.RawMessage, params []triggersv1.ParamSpec, c discoveryclient.ServerResourcesInterface) []Finding {
+	l := newLinter(params, c)
+	l.lint(rt)
+	return l.findings
+}
+
+type linter struct {
+	declared map[string]bool
+	c        discoveryclient.ServerResourcesInterface
+	used     map[string]bool
+	findings []Finding
+}
+
+func newLinter(params []triggersv1.ParamSpec, c discoveryclient.ServerResourcesInterface) *linter {
+	declared := make(map[string]bool, len(params))
+	for _, p := range params {
+		declared[p.Name] = true
+	}
+	return &linter{declared: declared, c: c, used: map[string]bool{}}
+}
+
+func (l *linter) report(severity Severity, path, format string, args ...interface{}) {
+	l.findings = append(l.findings, Finding{Severity: severity, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) lint(rt json.RawMessage) {
+	var obj map[string]interface{}
+	if err := json.Unmarshal(rt, &obj); err != nil {
+		l.report(SeverityError, "", "resource template is not a JSON object: %v", err)
+		return
+	}
+	l.lintValue("", obj)
+	l.lintIdentity(obj)
+}
+
+// lintValue lints the variables of the strings in v, including its keys.
+func (l *linter) lintValue(path string, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			l.lintString(p, k)
+			l.lintValue(p, v[k])
+		}
+	case []interface{}:
+		for i, e := range v {
+			l.lintValue(fmt.Sprintf("%s[%d]", path, i), e)
+		}
+	case string:
+		l.lintString(path, v)
+	}
+}
+
+// lintString lints the $(...) variables of s.
+func (l *linter) lintString(path, s string) {
+	for {
+		start := strings.Index(s, "$(")
+		if start < 0 {
+			return
+		}
+		end := closingParen(s[start+2:])
+		if end < 0 {
+			l.report(SeverityError, path, "unbalanced $( in %q", s)
+			return
+		}
+		l.lintVariable(path, s[start+2:start+2+end])
+		s = s[start+2+end+1:]
+	}
+}
+
+// closingParen returns the index in s of the parenthesis closing a variable whose opening parenthesis
+// precedes s, skipping those in single quoted arguments, or -1 if there is none.
+func closingParen(s string) int {
+	depth := 0
+	quoted := false
+	for i, r := range s {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth++
+		case r == ')':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// lintVariable lints the expression of a $(...) variable.
+func (l *linter) lintVariable(path, expr string) {
+	if expr == "uid" {
+		return
+	}
+	if m := paramVariableMatch.FindStringSubmatch(expr); m != nil {
+		l.useParam(path, m[1])
+		return
+	}
+	if m := funcCallMatch.FindStringSubmatch(expr); m != nil {
+		f, ok := templateFuncs[m[1]]
+		switch {
+		case !ok:
+			l.report(SeverityError, path, "unknown function %s in $(%s)", m[1], expr)
+		case f.hasArg != (m[3] != ""):
+			want := "no argument"
+			if f.hasArg {
+				want = "a single quoted argument"
+			}
+			l.report(SeverityError, path, "function %s takes the param and %s in $(%s)", m[1], want, expr)
+		}
+		l.useParam(path, m[2])
+		return
+	}
+	if strings.HasPrefix(expr, "tt.") || strings.Contains(expr, "tt.params.") {
+		l.report(SeverityError, path, "invalid TriggerTemplate variable $(%s)", expr)
+	}
+}
+
+func (l *linter) useParam(path, name string) {
+	l.used[name] = true
+	if !l.declared[name] {
+		l.report(SeverityError, path, "undeclared param $(tt.params.%s)", name)
+	}
+}
+
+// lintIdentity checks the apiVersion, kind and name of the resource.
+func (l *linter) lintIdentity(obj map[string]interface{}) {
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	if apiVersion == "" {
+		l.report(SeverityError, "apiVersion", "missing apiVersion")
+	}
+	if kind == "" {
+		l.report(SeverityError, "kind", "missing kind")
+	}
+	if l.c != nil && apiVersion != "" && kind != "" && !strings.Contains(apiVersion+kind, "$(") {
+		if _, err := resources.FindAPIResource(apiVersion, kind, l.c); err != nil {
+			l.report(SeverityError, "kind", "%s %s doesn't resolve to an API resource: %v", apiVersion, kind, err)
+		}
+	}
+
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	generateName, _ := metadata["generateName"].(string)
+	switch {
+	case name != "" && generateName != "":
+		l.report(SeverityWarning, "metadata.generateName", "generateName is ignored since name is set")
+	case name == "" && generateName == "":
+		l.report(SeverityError, "metadata", "missing name or generateName")
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/test"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLintResourceTemplate(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	test.AddTektonResources(kubeClient)
+	params := []triggersv1.ParamSpec{{Name: "revision"}, {Name: "ref"}}
+
+	for _, tc := range []struct {
+		name string
+		rt   string
+		want []Finding
+	}{{
+		name: "valid",
+		rt: `{"apiVersion": "tekton.dev/v1beta1", "kind": "TaskRun", "metadata": {"generateName": "run-$(uid)-"},
+			"spec": {"params": [{"name": "revision", "value": "$(tt.params.revision)"}, {"name": "branch", "value": "$(trimprefix(tt.params.ref, 'refs/heads/(main)'))"}],
+			"taskSpec": {"steps": [{"name": "echo", "script": "echo $(params.revision) $(context.taskRun.name)"}]}}}`,
+	}, {
+		name: "unbalanced variable",
+		rt:   `{"apiVersion": "tekton.dev/v1beta1", "kind": "TaskRun", "metadata": {"name": "run"}, "spec": {"params": [{"name": "revision", "value": "$(tt.params.revision"}]}}`,
+		want: []Finding{{Severity: SeverityError, Path: "spec.params[0].value", Message: `unbalanced $( in "$(tt.params.revision"`}},
+	}, {
+		name: "undeclared param",
+		rt:   `{"apiVersion": "tekton.dev/v1beta1", "kind": "TaskRun", "metadata": {"name": "run-$(tt.params.rev)", "labels": {"$(upper(tt.params.app))": "true"}}}`,
+		want: []Finding{
+			{Severity: SeverityError, Path: "metadata.labels.$(upper(tt.params.app))", Message: "undeclared param $(tt.params.app)"},
+			{Severity: SeverityError, Path: "metadata.name", Message: "undeclared param $(tt.params.rev)"},
+		},
+	}, {
+		name: "invalid variables",
+		rt: `{"apiVersion": "tekton.dev/v1beta1", "kind": "TaskRun", "metadata": {"name": "run"},
+			"spec": {"params": [{"name": "a", "value": "$(capitalize(tt.params.ref))"}, {"name": "b", "value": "$(upper(tt.params.ref, 'x'))"}, {"name": "c", "value": "$(tt.param.ref)"}]}}`,
+		want: []Finding{
+			{Severity: SeverityError, Path: "spec.params[0].value", Message: "unknown function capitalize in $(capitalize(tt.params.ref))"},
+			{Severity: SeverityError, Path: "spec.params[1].value", Message: "function upper takes the param and no argument in $(upper(tt.params.ref, 'x'))"},
+			{Severity: SeverityError, Path: "spec.params[2].value", Message: "invalid TriggerTemplate variable $(tt.param.ref)"},
+		},
+	}, {
+		name: "unknown kind",
+		rt:   `{"apiVersion": "tekton.dev/v1beta1", "kind": "TaskRunz", "metadata": {"name": "run"}}`,
+		want: []Finding{{Severity: SeverityError, Path: "kind", Message: "tekton.dev/v1beta1 TaskRunz doesn't resolve to an API resource: error could not find resource with apiVersion tekton.dev/v1beta1 and kind TaskRunz"}},
+	}, {
+		name: "missing apiVersion and kind",
+		rt:   `{"metadata": {"name": "run"}}`,
+		want: []Finding{
+			{Severity: SeverityError, Path: "apiVersion", Message: "missing apiVersion"},
+			{Severity: SeverityError, Path: "kind", Message: "missing kind"},
+		},
+	}, {
+		name: "name and generateName",
+		rt:   `{"apiVersion": "tekton.dev/v1beta1", "kind": "TaskRun", "metadata": {"name": "run", "generateName": "run-"}}`,
+		want: []Finding{{Severity: SeverityWarning, Path: "metadata.generateName", Message: "generateName is ignored since name is set"}},
+	}, {
+		name: "no name",
+		rt:   `{"apiVersion": "tekton.dev/v1beta1", "kind": "TaskRun", "metadata": {}}`,
+		want: []Finding{{Severity: SeverityError, Path: "metadata", Message: "missing name or generateName"}},
+	}, {
+		name: "not an object",
+		rt:   `["apiVersion"]`,
+		want: []Finding{{Severity: SeverityError, Message: "resource template is not a JSON object: json: cannot unmarshal array into Go value of type map[string]interface {}"}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := LintResourceTemplate(json.RawMessage(tc.rt), params, kubeClient.Discovery())
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("LintResourceTemplate() -want +got: %s", diff)
+			}
+		})
+	}
+
+	// The apiVersion and kind are only resolved with a discovery client.
+	rt := json.RawMessage(`{"apiVersion": "example.com/v1", "kind": "Unknown", "metadata": {"name": "run"}}`)
+	if got := LintResourceTemplate(rt, params, nil); len(got) != 0 {
+		t.Errorf("LintResourceTemplate() without discovery client = %v, want no findings", got)
+	}
+}
+
+func TestLintTriggerTemplate(t *testing.T) {
+	tt := &triggersv1.TriggerTemplate{
+		Spec: triggersv1.TriggerTemplateSpec{
+			Params: []triggersv1.ParamSpec{{Name: "revision"}, {Name: "unused"}},
+			ResourceTemplates: []triggersv1.TriggerResourceTemplate{{
+				RawExtension: runtime.RawExtension{Raw: []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "cm"}, "data": {"revision": "$(tt.params.revision)"}}`)},
+			}, {
+				RawExtension: runtime.RawExtension{Raw: []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {}}`)},
+			}},
+		},
+	}
+	want := []Finding{
+		{Severity: SeverityError, Path: "spec.resourcetemplates[1].metadata", Message: "missing name or generateName"},
+		{Severity: SeverityWarning, Path: "spec.params[1]", Message: "param unused is not used by any resource template"},
+	}
+	if diff := cmp.Diff(want, LintTriggerTemplate(tt, nil)); diff != "" {
+		t.Errorf("LintTriggerTemplate() -want +got: %s", diff)
+	}
+	if got, want := want[0].String(), "error: spec.resourcetemplates[1].metadata: missing name or generateName"; got != want {
+		t.Errorf("Finding.String() = %q, want %q", got, want)
+	}
+}
+
+func TestLintTriggerTemplate_MultiDocument(t *testing.T) {
+	tt := &triggersv1.TriggerTemplate{
+		Spec: triggersv1.TriggerTemplateSpec{
+			Params: []triggersv1.ParamSpec{{Name: "revision"}},
+			ResourceTemplates: []triggersv1.TriggerResourceTemplate{{
+				RawExtension: runtime.RawExtension{Raw: []byte(`"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\ndata:\n  revision: $(tt.params.revision)\n---\napiVersion: v1\nkind: ConfigMap\nmetadata: {}\n"`)},
+			}, {
+				RawExtension: runtime.RawExtension{Raw: []byte(`"kind: [ConfigMap"`)},
+			}},
+		},
+	}
+	want := []Finding{
+		{Severity: SeverityError, Path: "spec.resourcetemplates[0][1].metadata", Message: "missing name or generateName"},
+		{Severity: SeverityError, Path: "spec.resourcetemplates[1]", Message: "failed to parse document 0: error converting YAML to JSON: yaml: line 1: did not find expected ',' or ']'"},
+	}
+	if diff := cmp.Diff(want, LintTriggerTemplate(tt, nil)); diff != "" {
+		t.Errorf("LintTriggerTemplate() -want +got: %s", diff)
+	}
+}
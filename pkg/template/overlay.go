@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+)
+
+// ApplyOverlays sets the fields of the resolved resources at the paths of the overlays to the values of
+// their params, converted to JSON values of their types. The type of an overlay defaults to the type of its
+// param in specs. Each overlay is applied as a JSON patch add operation, so the parent of its path must
+// exist in the resources.
+func ApplyOverlays(resources []json.RawMessage, overlays []triggersv1.TriggerOverlay, params []triggersv1.Param, specs []triggersv1.ParamSpec) ([]json.RawMessage, error) {
+	if len(overlays) == 0 {
+		return resources, nil
+	}
+	patched := make([]json.RawMessage, len(resources))
+	copy(patched, resources)
+	for i, o := range overlays {
+		value, ok := paramValue(params, o.Param)
+		if !ok {
+			return nil, fmt.Errorf("overlay %d (%s): param %s has no value", i, o.Path, o.Param)
+		}
+		typ := o.Type
+		if typ == "" {
+			typ = overlayParamType(specs, o.Param)
+		}
+		v, err := overlayValue(value, typ)
+		if err != nil {
+			return nil, fmt.Errorf("overlay %d (%s): value of param %s %w", i, o.Path, o.Param, err)
+		}
+		op, err := json.Marshal([]map[string]interface{}{{"op": "add", "path": o.Path, "value": v}})
+		if err != nil {
+			return nil, fmt.Errorf("overlay %d (%s): %w", i, o.Path, err)
+		}
+		patch, err := jsonpatch.DecodePatch(op)
+		if err != nil {
+			return nil, fmt.Errorf("overlay %d (%s): %w", i, o.Path, err)
+		}
+		if o.ResourceIndex != nil && *o.ResourceIndex >= len(patched) {
+			return nil, fmt.Errorf("overlay %d (%s): no resource %d in the %d resources created", i, o.Path, *o.ResourceIndex, len(patched))
+		}
+		for j := range patched {
+			if o.ResourceIndex != nil && *o.ResourceIndex != j {
+				continue
+			}
+			if patched[j], err = patch.Apply(patched[j]); err != nil {
+				return nil, fmt.Errorf("overlay %d (%s) of resource %d: %w", i, o.Path, j, err)
+			}
+		}
+	}
+	return patched, nil
+}
+
+// overlayParamType returns the overlay type matching the type of the param of the given name.
+func overlayParamType(specs []triggersv1.ParamSpec, name string) triggersv1.OverlayType {
+	for _, s := range specs {
+		if s.Name == name && s.Type == triggersv1.ParamTypeArray {
+			return triggersv1.OverlayTypeArray
+		}
+	}
+	return triggersv1.OverlayTypeString
+}
+
+// overlayValue converts the value of a param to a JSON value of the given type.
+func overlayValue(value string, typ triggersv1.OverlayType) (interface{}, error) {
+	switch typ {
+	case triggersv1.OverlayTypeString:
+		return value, nil
+	case triggersv1.OverlayTypeNumber:
+		var f float64
+		if err := json.Unmarshal([]byte(value), &f); err != nil {
+			return nil, fmt.Errorf("%q is not a number", value)
+		}
+		return json.RawMessage(value), nil
+	case triggersv1.OverlayTypeBoolean:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", value)
+		}
+		return b, nil
+	case triggersv1.OverlayTypeArray:
+		var a []interface{}
+		if err := json.Unmarshal([]byte(value), &a); err != nil {
+			return nil, fmt.Errorf("%q is not a JSON array", value)
+		}
+		return a, nil
+	case triggersv1.OverlayTypeObject:
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(value), &m); err != nil || m == nil {
+			return nil, fmt.Errorf("%q is not a JSON object", value)
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("has unknown overlay type %q", typ)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+)
+
+func TestApplyOverlays(t *testing.T) {
+	one := 1
+	params := []triggersv1.Param{
+		{Name: "name", Value: "run"},
+		{Name: "replicas", Value: "3"},
+		{Name: "enabled", Value: "true"},
+		{Name: "tags", Value: `["a","b"]`},
+		{Name: "resources", Value: `{"cpu":"1"}`},
+	}
+	specs := []triggersv1.ParamSpec{{Name: "tags", Type: triggersv1.ParamTypeArray}}
+	resources := []json.RawMessage{
+		json.RawMessage(`{"metadata":{"name":"a"},"spec":{}}`),
+		json.RawMessage(`{"metadata":{"name":"b"},"spec":{"params":[]}}`),
+	}
+	for _, tc := range []struct {
+		name     string
+		overlays []triggersv1.TriggerOverlay
+		want     []string
+	}{{
+		name: "no overlays",
+		want: []string{`{"metadata":{"name":"a"},"spec":{}}`, `{"metadata":{"name":"b"},"spec":{"params":[]}}`},
+	}, {
+		name: "typed values",
+		overlays: []triggersv1.TriggerOverlay{
+			{Path: "/spec/replicas", Param: "replicas", Type: triggersv1.OverlayTypeNumber},
+			{Path: "/spec/enabled", Param: "enabled", Type: triggersv1.OverlayTypeBoolean},
+			{Path: "/spec/resources", Param: "resources", Type: triggersv1.OverlayTypeObject},
+		},
+		want: []string{
+			`{"metadata":{"name":"a"},"spec":{"enabled":true,"replicas":3,"resources":{"cpu":"1"}}}`,
+			`{"metadata":{"name":"b"},"spec":{"enabled":true,"params":[],"replicas":3,"resources":{"cpu":"1"}}}`,
+		},
+	}, {
+		name: "types of the params by default",
+		overlays: []triggersv1.TriggerOverlay{
+			{Path: "/metadata/name", Param: "name"},
+			{Path: "/spec/tags", Param: "tags"},
+			{Path: "/spec/replicas", Param: "replicas"},
+		},
+		want: []string{
+			`{"metadata":{"name":"run"},"spec":{"replicas":"3","tags":["a","b"]}}`,
+			`{"metadata":{"name":"run"},"spec":{"params":[],"replicas":"3","tags":["a","b"]}}`,
+		},
+	}, {
+		name: "selected resource",
+		overlays: []triggersv1.TriggerOverlay{
+			{Path: "/spec/params/-", Param: "name", ResourceIndex: &one},
+		},
+		want: []string{`{"metadata":{"name":"a"},"spec":{}}`, `{"metadata":{"name":"b"},"spec":{"params":["run"]}}`},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ApplyOverlays(resources, tc.overlays, params, specs)
+			if err != nil {
+				t.Fatalf("ApplyOverlays() returned error: %v", err)
+			}
+			var gotJSON []string
+			for _, r := range got {
+				var v interface{}
+				if err := json.Unmarshal(r, &v); err != nil {
+					t.Fatalf("ApplyOverlays() returned invalid JSON %s: %v", r, err)
+				}
+				b, _ := json.Marshal(v)
+				gotJSON = append(gotJSON, string(b))
+			}
+			if diff := cmp.Diff(tc.want, gotJSON); diff != "" {
+				t.Errorf("ApplyOverlays() -want +got: %s", diff)
+			}
+		})
+	}
+	if string(resources[0]) != `{"metadata":{"name":"a"},"spec":{}}` {
+		t.Errorf("ApplyOverlays() modified the resources: %s", resources[0])
+	}
+}
+
+func TestApplyOverlays_Error(t *testing.T) {
+	five := 5
+	params := []triggersv1.Param{{Name: "name", Value: "run"}, {Name: "count", Value: "many"}}
+	resources := []json.RawMessage{json.RawMessage(`{"spec":{}}`)}
+	for _, tc := range []struct {
+		name    string
+		overlay triggersv1.TriggerOverlay
+		want    string
+	}{{
+		name:    "param without value",
+		overlay: triggersv1.TriggerOverlay{Path: "/spec/name", Param: "missing"},
+		want:    "overlay 0 (/spec/name): param missing has no value",
+	}, {
+		name:    "value not of the type",
+		overlay: triggersv1.TriggerOverlay{Path: "/spec/count", Param: "count", Type: triggersv1.OverlayTypeNumber},
+		want:    `overlay 0 (/spec/count): value of param count "many" is not a number`,
+	}, {
+		name:    "missing parent",
+		overlay: triggersv1.TriggerOverlay{Path: "/status/name", Param: "name"},
+		want:    "overlay 0 (/status/name) of resource 0:",
+	}, {
+		name:    "no such resource",
+		overlay: triggersv1.TriggerOverlay{Path: "/spec/name", Param: "name", ResourceIndex: &five},
+		want:    "overlay 0 (/spec/name): no resource 5 in the 1 resources created",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ApplyOverlays(resources, []triggersv1.TriggerOverlay{tc.overlay}, params, nil)
+			if err == nil || !strings.HasPrefix(err.Error(), tc.want) {
+				t.Errorf("ApplyOverlays() got error %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ResolvePayload returns tmpl, a JSON document, with the JSONPath expressions wrapped in $() in its strings
+// replaced with the values they match in input, e.g. $(eventID) or $(resources[0].name). A string that is a
+// single expression is replaced with the matched value, keeping its JSON type, so that $(resources) is an
+// array, or with the array of the matched values if it matches several. Expressions embedded in longer
+// strings are replaced with the string form of the matched values. Object keys are left as they are.
+func ResolvePayload(tmpl json.RawMessage, input interface{}) (json.RawMessage, error) {
+	// Round trip input through JSON so that the expressions see the JSON names of its fields.
+	b, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	var in interface{}
+	if err := json.Unmarshal(b, &in); err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(tmpl, &doc); err != nil {
+		return nil, fmt.Errorf("payload template is not valid JSON: %w", err)
+	}
+	resolved, err := resolvePayloadValue(doc, in)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(resolved)
+}
+
+func resolvePayloadValue(v interface{}, input interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			r, err := resolvePayloadValue(e, input)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = r
+		}
+		return v, nil
+	case []interface{}:
+		for i, e := range v {
+			r, err := resolvePayloadValue(e, input)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = r
+		}
+		return v, nil
+	case string:
+		return resolvePayloadString(v, input)
+	}
+	return v, nil
+}
+
+func resolvePayloadString(s string, input interface{}) (interface{}, error) {
+	expressions, originals := findTektonExpressions(s)
+	if len(expressions) == 1 && originals[0] == s {
+		expr, err := tektonJSONPathExpression(expressions[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression %s: %w", s, err)
+		}
+		results, err := findResults(input, strings.TrimSuffix(strings.TrimPrefix(expr, "{"), "}"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", s, err)
+		}
+		if len(results) == 1 {
+			return results[0], nil
+		}
+		return results, nil
+	}
+	for i, expr := range expressions {
+		val, err := parseJSONPath(input, expr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", originals[i], err)
+		}
+		s = strings.ReplaceAll(s, originals[i], val)
+	}
+	return s, nil
+}
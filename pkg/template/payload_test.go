@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestResolvePayload(t *testing.T) {
+	input := struct {
+		EventID   string                   `json:"eventID"`
+		Resources []map[string]interface{} `json:"resources"`
+		Body      json.RawMessage          `json:"body"`
+	}{
+		EventID:   "1234",
+		Resources: []map[string]interface{}{{"kind": "PipelineRun", "name": "build-xk2lp"}},
+		Body:      json.RawMessage(`{"repository": {"name": "triggers"}, "count": 2}`),
+	}
+	for _, tc := range []struct {
+		name string
+		tmpl string
+		want string
+	}{{
+		name: "single expressions keep their type",
+		tmpl: `{"id": "$(eventID)", "resources": "$(resources)", "count": "$(body.count)", "repo": "$(body.repository)"}`,
+		want: `{"count":2,"id":"1234","repo":{"name":"triggers"},"resources":[{"kind":"PipelineRun","name":"build-xk2lp"}]}`,
+	}, {
+		name: "embedded expressions",
+		tmpl: `{"text": "$(body.repository.name): created $(resources[0].kind) $(resources[0].name) for $(eventID)"}`,
+		want: `{"text":"triggers: created PipelineRun build-xk2lp for 1234"}`,
+	}, {
+		name: "nested values and literals",
+		tmpl: `{"blocks": [{"type": "section", "fields": ["$(eventID)", 1, true, null]}], "$(eventID)": "key"}`,
+		want: `{"$(eventID)":"key","blocks":[{"fields":["1234",1,true,null],"type":"section"}]}`,
+	}, {
+		name: "array of matches",
+		tmpl: `{"names": "$(resources[*].name)"}`,
+		want: `{"names":"build-xk2lp"}`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ResolvePayload(json.RawMessage(tc.tmpl), input)
+			if err != nil {
+				t.Fatalf("ResolvePayload() returned error: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("ResolvePayload() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolvePayload_Error(t *testing.T) {
+	input := map[string]interface{}{"eventID": "1234"}
+	for _, tc := range []struct {
+		name    string
+		tmpl    string
+		wantErr string
+	}{{
+		name:    "invalid JSON",
+		tmpl:    `{"id": $(eventID)}`,
+		wantErr: "payload template is not valid JSON",
+	}, {
+		name:    "missing key",
+		tmpl:    `{"id": "$(trigger)"}`,
+		wantErr: "failed to resolve $(trigger)",
+	}, {
+		name:    "embedded missing key",
+		tmpl:    `{"text": "fired $(trigger)"}`,
+		wantErr: "failed to resolve $(trigger)",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ResolvePayload(json.RawMessage(tc.tmpl), input)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("ResolvePayload() returned error %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
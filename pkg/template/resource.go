@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
@@ -37,6 +38,8 @@ type ResolvedTrigger struct {
 	ClusterTriggerBindings []*triggersv1.ClusterTriggerBinding
 	TriggerTemplate        *triggersv1.TriggerTemplate
 	BindingParams          []triggersv1.Param
+	// PromotedExtensions are the keys of the extensions that the bindings can read as top-level fields of the event.
+	PromotedExtensions []string
 }
 
 type getTriggerBinding func(name string) (*triggersv1.TriggerBinding, error)
@@ -68,47 +71,132 @@ func ResolveTrigger(trigger triggersv1.Trigger, getTB getTriggerBinding, getCTB
 		}
 	}
 
-	return ResolvedTrigger{TriggerTemplate: resolvedTT, BindingParams: bp}, nil
+	return ResolvedTrigger{TriggerTemplate: resolvedTT, BindingParams: bp, PromotedExtensions: trigger.Spec.PromotedExtensions}, nil
 }
 
-// resolveBindingsToParams takes in both embedded bindings and references and returns a list of resolved Param values.ResolveBindingsToParams
+// RouteTrigger returns the trigger with the TriggerTemplate that the routing of its template selects for the
+// extensions added by its interceptors, i.e. the template of the first route whose value is the value of the
+// routing extension, and the name of that TriggerTemplate. The trigger is returned as it is, with an empty
+// name, if it has no routing or no route matches, so that its own template is the fallback.
+func RouteTrigger(trigger triggersv1.Trigger, extensions map[string]interface{}) (triggersv1.Trigger, string) {
+	routing := trigger.Spec.Template.Routing
+	if routing == nil {
+		return trigger, ""
+	}
+	value, ok := routeValue(extensions[routing.Extension])
+	if !ok {
+		return trigger, ""
+	}
+	for _, route := range routing.Routes {
+		if route.Value == value {
+			ref := route.Ref
+			trigger.Spec.Template = triggersv1.TriggerSpecTemplate{Ref: &ref, APIVersion: trigger.Spec.Template.APIVersion}
+			return trigger, ref
+		}
+	}
+	return trigger, ""
+}
+
+// routeValue returns the string form of the value of a routing extension, or false if it is not a string, a
+// number or a boolean.
+func routeValue(v interface{}) (string, bool) {
+	switch x := v.(type) {
+	case string:
+		return x, true
+	case bool:
+		return strconv.FormatBool(x), true
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), true
+	case json.Number:
+		return x.String(), true
+	}
+	return "", false
+}
+
+// resolveBindingsToParams takes in both embedded bindings and references and returns a list of resolved Param values.
+// The params are merged by MergeBindingParams.
 func resolveBindingsToParams(bindings []*triggersv1.TriggerSpecBinding, getTB getTriggerBinding, getCTB getClusterTriggerBinding) ([]triggersv1.Param, error) {
-	bindingParams := []triggersv1.Param{}
+	resolved := make([]BindingParams, 0, len(bindings))
 	for _, b := range bindings {
 		switch {
 		case b.Name != "" && b.Value != nil:
-			bindingParams = append(bindingParams, triggersv1.Param{
-				Name:  b.Name,
-				Value: *b.Value,
+			resolved = append(resolved, BindingParams{
+				Source: fmt.Sprintf("binding %s", b.Name),
+				Params: []triggersv1.Param{{
+					Name:  b.Name,
+					Value: *b.Value,
+				}},
+				Override: b.Override,
 			})
 
 		case b.Ref != "" && b.Kind == triggersv1.ClusterTriggerBindingKind:
 			ctb, err := getCTB(b.Ref)
 			if err != nil {
-				return nil, fmt.Errorf("error getting ClusterTriggerBinding %s: %w", b.Name, err)
+				return nil, fmt.Errorf("error getting ClusterTriggerBinding %s: %w", b.Ref, err)
 			}
-			bindingParams = append(bindingParams, ctb.Spec.Params...)
+			resolved = append(resolved, BindingParams{
+				Source:   fmt.Sprintf("ClusterTriggerBinding %s", b.Ref),
+				Params:   ctb.Spec.Params,
+				Override: b.Override,
+			})
 
 		case b.Ref != "": // if no kind is set, assume NamespacedTriggerBinding
 			tb, err := getTB(b.Ref)
 			if err != nil {
-				return nil, fmt.Errorf("error getting TriggerBinding %s: %w", b.Name, err)
+				return nil, fmt.Errorf("error getting TriggerBinding %s: %w", b.Ref, err)
 			}
-			bindingParams = append(bindingParams, tb.Spec.Params...)
+			resolved = append(resolved, BindingParams{
+				Source:   fmt.Sprintf("TriggerBinding %s", b.Ref),
+				Params:   tb.Spec.Params,
+				Override: b.Override,
+			})
 		default:
 			return nil, fmt.Errorf("invalid binding: %v", b)
 		}
 	}
+	return MergeBindingParams(resolved)
+}
+
+// BindingParams are the params of one of the bindings of a trigger.
+type BindingParams struct {
+	// Source names the binding in errors, e.g. ClusterTriggerBinding my-ctb.
+	Source string
+	Params []triggersv1.Param
+	// Override allows the params to replace the params of the same name of the earlier bindings.
+	Override bool
+}
 
-	// Check for duplicate params
-	seen := make(map[string]bool, len(bindingParams))
-	for _, p := range bindingParams {
-		if seen[p.Name] {
-			return nil, fmt.Errorf("duplicate param name: %s", p.Name)
+// MergeBindingParams merges the params of bindings, whether they are embedded, TriggerBindings or
+// ClusterTriggerBindings, with a precedence that only depends on their order:
+//
+//   - The params are returned in the order of the bindings, and of the params within each binding.
+//   - A param whose name is already declared by an earlier binding is an error, unless its binding
+//     sets Override. It then replaces the value of the earlier param, which keeps its position, so
+//     that the last overriding binding wins.
+//   - A param whose name is declared twice by the same binding is always an error.
+func MergeBindingParams(bindings []BindingParams) ([]triggersv1.Param, error) {
+	params := []triggersv1.Param{}
+	// declared maps the names of the params to their index in params and the index of their binding.
+	type declaration struct{ param, binding int }
+	declared := map[string]declaration{}
+	for i, b := range bindings {
+		for _, p := range b.Params {
+			d, ok := declared[p.Name]
+			switch {
+			case !ok:
+				declared[p.Name] = declaration{param: len(params), binding: i}
+				params = append(params, p)
+			case d.binding == i:
+				return nil, fmt.Errorf("duplicate param name: %s in %s", p.Name, b.Source)
+			case !b.Override:
+				return nil, fmt.Errorf("duplicate param name: %s in %s and %s, set override on the later binding to replace it", p.Name, bindings[d.binding].Source, b.Source)
+			default:
+				params[d.param].Value = p.Value
+				declared[p.Name] = declaration{param: d.param, binding: i}
+			}
 		}
-		seen[p.Name] = true
 	}
-	return bindingParams, nil
+	return params, nil
 }
 
 // applyParamsToResourceTemplate returns the TriggerResourceTemplate with the
@@ -125,6 +213,7 @@ func applyParamsToResourceTemplate(params []triggersv1.Param, rt json.RawMessage
 // param value substituted for all matching param variables in the template
 func applyParamToResourceTemplate(param triggersv1.Param, rt json.RawMessage, oldEscape bool) json.RawMessage {
 	// Assume the param is valid
+	rt = applyFuncsToParamValue(rt, param.Name, param.Value, oldEscape)
 	paramVariable := fmt.Sprintf("$(tt.params.%s)", param.Name)
 	// Escape quotes so that that JSON strings can be appended to regular strings.
 	// See #257 for discussion on this behavior.
@@ -154,19 +243,12 @@ func convertParamMapToArray(paramMap map[string]string) []triggersv1.Param {
 
 // mergeBindingParams merges params across multiple bindings.
 func mergeBindingParams(bindings []*triggersv1.TriggerBinding, clusterbindings []*triggersv1.ClusterTriggerBinding) ([]triggersv1.Param, error) {
-	params := []triggersv1.Param{}
+	resolved := make([]BindingParams, 0, len(bindings)+len(clusterbindings))
 	for _, b := range bindings {
-		params = append(params, b.Spec.Params...)
+		resolved = append(resolved, BindingParams{Source: fmt.Sprintf("TriggerBinding %s", b.Name), Params: b.Spec.Params})
 	}
 	for _, cb := range clusterbindings {
-		params = append(params, cb.Spec.Params...)
+		resolved = append(resolved, BindingParams{Source: fmt.Sprintf("ClusterTriggerBinding %s", cb.Name), Params: cb.Spec.Params})
 	}
-	seen := make(map[string]bool, len(params))
-	for _, p := range params {
-		if seen[p.Name] {
-			return nil, fmt.Errorf("duplicate param name: %s", p.Name)
-		}
-		seen[p.Name] = true
-	}
-	return params, nil
+	return MergeBindingParams(resolved)
 }
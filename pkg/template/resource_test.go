@@ -19,6 +19,7 @@ package template
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -441,6 +442,38 @@ func Test_ResolveTrigger(t *testing.T) {
 				}},
 			},
 		},
+		{
+			name: "embedded binding overrides TriggerBinding",
+			trigger: triggersv1.Trigger{
+				Spec: triggersv1.TriggerSpec{
+					Bindings: []*triggersv1.EventListenerBinding{{
+						Ref:  "tb-params",
+						Kind: triggersv1.NamespacedTriggerBindingKind,
+					}, {
+						Ref:  "ctb-params",
+						Kind: triggersv1.ClusterTriggerBindingKind,
+					}, {
+						Name:     "foo",
+						Value:    ptr.String("baz"),
+						Override: true,
+					}},
+					Template: triggersv1.EventListenerTemplate{
+						Ref:        ptr.String("my-triggertemplate"),
+						APIVersion: "v1alpha1",
+					},
+				},
+			},
+			want: ResolvedTrigger{
+				BindingParams: []triggersv1.Param{{
+					Name:  "foo",
+					Value: "baz",
+				}, {
+					Name:  "foo-ctb",
+					Value: "bar-ctb",
+				}},
+				TriggerTemplate: &tt,
+			},
+		},
 		{
 			name: "missing kind implies namespacedTriggerBinding",
 			trigger: triggersv1.Trigger{
@@ -648,6 +681,73 @@ func Test_ResolveTrigger_error(t *testing.T) {
 	}
 }
 
+func TestRouteTrigger(t *testing.T) {
+	trigger := triggersv1.Trigger{
+		ObjectMeta: metav1.ObjectMeta{Name: "classify"},
+		Spec: triggersv1.TriggerSpec{
+			Template: triggersv1.TriggerSpecTemplate{
+				Ref:        ptr.String("fallback"),
+				APIVersion: "v1beta1",
+				Routing: &triggersv1.TriggerTemplateRouting{
+					Extension: "type",
+					Routes: []triggersv1.TriggerTemplateRoute{
+						{Value: "deploy", Ref: "deploy-template"},
+						{Value: "test", Ref: "test-template"},
+						{Value: "1", Ref: "one-template"},
+						{Value: "true", Ref: "true-template"},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range []struct {
+		name       string
+		extensions map[string]interface{}
+		want       string
+	}{{
+		name:       "matching value",
+		extensions: map[string]interface{}{"type": "test"},
+		want:       "test-template",
+	}, {
+		name:       "number",
+		extensions: map[string]interface{}{"type": float64(1)},
+		want:       "one-template",
+	}, {
+		name:       "boolean",
+		extensions: map[string]interface{}{"type": true},
+		want:       "true-template",
+	}, {
+		name:       "no matching value",
+		extensions: map[string]interface{}{"type": "lint"},
+	}, {
+		name:       "object",
+		extensions: map[string]interface{}{"type": map[string]interface{}{"name": "deploy"}},
+	}, {
+		name: "missing extension",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, name := RouteTrigger(trigger, tc.extensions)
+			if name != tc.want {
+				t.Errorf("RouteTrigger() routed to %q, want %q", name, tc.want)
+			}
+			wantRef := tc.want
+			if wantRef == "" {
+				wantRef = "fallback"
+			}
+			if got.Spec.Template.Ref == nil || *got.Spec.Template.Ref != wantRef {
+				t.Errorf("RouteTrigger() returned template %+v, want a ref to %s", got.Spec.Template, wantRef)
+			}
+			if got.Spec.Template.APIVersion != "v1beta1" {
+				t.Errorf("RouteTrigger() returned template apiVersion %q, want v1beta1", got.Spec.Template.APIVersion)
+			}
+		})
+	}
+	// The trigger itself is left alone.
+	if *trigger.Spec.Template.Ref != "fallback" {
+		t.Errorf("RouteTrigger() changed the template of the trigger to %s", *trigger.Spec.Template.Ref)
+	}
+}
+
 func Test_ApplyUIDToResourceTemplate(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -885,3 +985,85 @@ func TestMergeBindingParams(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeBindingParams_Precedence(t *testing.T) {
+	ctb := func(override bool, params ...triggersv1.Param) BindingParams {
+		return BindingParams{Source: "ClusterTriggerBinding ctb", Params: params, Override: override}
+	}
+	tb := func(override bool, params ...triggersv1.Param) BindingParams {
+		return BindingParams{Source: "TriggerBinding tb", Params: params, Override: override}
+	}
+	embedded := func(override bool, name, value string) BindingParams {
+		return BindingParams{Source: "binding " + name, Params: []triggersv1.Param{{Name: name, Value: value}}, Override: override}
+	}
+	p := func(name, value string) triggersv1.Param {
+		return triggersv1.Param{Name: name, Value: value}
+	}
+	tests := []struct {
+		name     string
+		bindings []BindingParams
+		want     []triggersv1.Param
+		wantErr  string
+	}{{
+		name: "no bindings",
+		want: []triggersv1.Param{},
+	}, {
+		name:     "params keep the order of the bindings",
+		bindings: []BindingParams{tb(false, p("b", "1"), p("a", "2")), ctb(false, p("d", "3")), embedded(false, "c", "4")},
+		want:     []triggersv1.Param{p("b", "1"), p("a", "2"), p("d", "3"), p("c", "4")},
+	}, {
+		name:     "duplicate without override is an error",
+		bindings: []BindingParams{ctb(false, p("env", "prod")), tb(false, p("env", "staging"))},
+		wantErr:  "duplicate param name: env in ClusterTriggerBinding ctb and TriggerBinding tb, set override on the later binding to replace it",
+	}, {
+		name:     "duplicate with the same value is an error",
+		bindings: []BindingParams{ctb(false, p("env", "prod")), tb(false, p("env", "prod"))},
+		wantErr:  "duplicate param name: env in ClusterTriggerBinding ctb and TriggerBinding tb",
+	}, {
+		name:     "override on the earlier binding is not enough",
+		bindings: []BindingParams{ctb(true, p("env", "prod")), tb(false, p("env", "staging"))},
+		wantErr:  "duplicate param name: env in ClusterTriggerBinding ctb and TriggerBinding tb",
+	}, {
+		name:     "later binding overrides and keeps the position",
+		bindings: []BindingParams{ctb(false, p("env", "prod"), p("region", "eu")), tb(true, p("env", "staging"), p("team", "a"))},
+		want:     []triggersv1.Param{p("env", "staging"), p("region", "eu"), p("team", "a")},
+	}, {
+		name:     "TriggerBinding can be overridden by a ClusterTriggerBinding",
+		bindings: []BindingParams{tb(false, p("env", "staging")), ctb(true, p("env", "prod"))},
+		want:     []triggersv1.Param{p("env", "prod")},
+	}, {
+		name:     "last override wins",
+		bindings: []BindingParams{ctb(false, p("env", "prod")), tb(true, p("env", "staging")), embedded(true, "env", "dev")},
+		want:     []triggersv1.Param{p("env", "dev")},
+	}, {
+		name:     "an override must be overridden too",
+		bindings: []BindingParams{ctb(false, p("env", "prod")), tb(true, p("env", "staging")), embedded(false, "env", "dev")},
+		wantErr:  "duplicate param name: env in TriggerBinding tb and binding env",
+	}, {
+		name:     "duplicate within a binding is an error",
+		bindings: []BindingParams{tb(false, p("env", "prod"), p("env", "staging"))},
+		wantErr:  "duplicate param name: env in TriggerBinding tb",
+	}, {
+		name:     "duplicate within an overriding binding is an error",
+		bindings: []BindingParams{ctb(false, p("env", "prod")), tb(true, p("env", "staging"), p("env", "dev"))},
+		wantErr:  "duplicate param name: env in TriggerBinding tb",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergeBindingParams(tt.bindings)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("MergeBindingParams() got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MergeBindingParams() returned unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("MergeBindingParams(): -want +got: %s", diff)
+			}
+		})
+	}
+}
@@ -67,6 +67,8 @@ import (
 
 	// Import for creating fake filtered factory in the test
 	_ "knative.dev/pkg/client/injection/kube/informers/factory/filtered/fake"
+	// Import for creating the fake informer of the metadata of secrets in the test
+	fakesecretmetadatainformer "github.com/tektoncd/triggers/pkg/client/injection/metadata/informers/core/v1/secret/fake"
 )
 
 // Resources represents the desired state of the system (i.e. existing resources)
@@ -148,6 +150,7 @@ func SeedResources(t *testing.T, ctx context.Context, r Resources) Clients {
 	deployInformer := fakefiltereddeployinformer.Get(ctx, labels.FormatLabels(resources.DefaultStaticResourceLabels))
 	serviceInformer := fakefilteredserviceinformer.Get(ctx, labels.FormatLabels(resources.DefaultStaticResourceLabels))
 	secretInformer := fakesecretinformer.Get(ctx)
+	secretMetadataInformer := fakesecretmetadatainformer.Get(ctx)
 	saInformer := fakeserviceaccountinformer.Get(ctx)
 	podInformer := fakepodinformer.Get(ctx)
 	duckInformerFactory := duckinformerfake.Get(ctx)
@@ -236,6 +239,12 @@ func SeedResources(t *testing.T, ctx context.Context, r Resources) Clients {
 		if err := secretInformer.Informer().GetIndexer().Add(s); err != nil {
 			t.Fatal(err)
 		}
+		if err := secretMetadataInformer.Informer().GetIndexer().Add(&metav1.PartialObjectMetadata{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: s.ObjectMeta,
+		}); err != nil {
+			t.Fatal(err)
+		}
 		if _, err := c.Kube.CoreV1().Secrets(s.Namespace).Create(context.Background(), s, metav1.CreateOptions{}); err != nil {
 			t.Fatal(err)
 		}